@@ -12,6 +12,7 @@ import (
 	"runtime"
 	"runtime/pprof"
 	"syscall"
+	_ "time/tzdata" // 内置时区数据，运行环境缺少 zoneinfo 时规则生效计划仍可使用命名时区
 
 	config "github.com/HUAHUAI23/RuiQi/coraza-spoa/config"
 	"github.com/HUAHUAI23/RuiQi/coraza-spoa/internal"
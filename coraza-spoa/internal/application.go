@@ -46,6 +46,7 @@ type ApplicationOptions struct {
 	GeoIPConfig          *GeoIP2Options        // GeoIP配置，用于IP地理位置处理
 	RuleEngineDbConfig   *MongoDBConfig        // 规则引擎数据库配置
 	FlowControllerConfig *FlowControllerConfig // 流量控制器配置
+	RuleStatsConfig      *RuleStatsConfig      // 规则命中统计配置
 }
 
 // FlowControllerConfig 流量控制器配置
@@ -54,6 +55,13 @@ type FlowControllerConfig struct {
 	Database string        // 数据库名称
 }
 
+// RuleStatsConfig 规则命中统计配置
+type RuleStatsConfig struct {
+	Client        *mongo.Client // MongoDB客户端
+	Database      string        // 数据库名称
+	FlushInterval time.Duration // 刷新间隔，为0时使用默认值
+}
+
 type Application struct {
 	waf            coraza.WAF
 	cache          cache.ExpiringCache
@@ -62,6 +70,7 @@ type Application struct {
 	ruleEngine     *RuleEngine
 	flowController *flowcontroller.FlowController
 	ipRecorder     flowcontroller.IPRecorder
+	ruleStats      *RuleStatsCollector
 
 	AppConfig
 }
@@ -71,6 +80,7 @@ type transaction struct {
 	tx      types.Transaction
 	m       sync.Mutex
 	request *applicationRequest // 存储请求信息
+	login   *loginAttempt       // 受保护的登录请求，响应阶段据此统计登录失败
 }
 
 type applicationRequest struct {
@@ -193,6 +203,35 @@ func (a *Application) HandleRequest(ctx context.Context, writer *encoding.Action
 	}
 
 	host := getHostFromRequest(&req)
+	url := buildURLFromBytes(req.Path, req.Query)
+
+	// 先匹配微规则，被白名单放行的请求不检查蜜罐陷阱，拦截判断在流控检查之后进行
+	var (
+		shouldBlock bool
+		ruleType    model.RuleType
+		rule        *Rule
+		matchErr    error
+	)
+	if a.ruleEngine != nil {
+		shouldBlock, ruleType, rule, matchErr = a.ruleEngine.MatchRequest(host, realIP, url, string(req.Path))
+
+		// 蜜罐陷阱路径检查，命中后立即封禁来源IP
+		if err := a.checkTrapPath(host, realIP, &req, matchErr == nil && ruleType == model.WhitelistRule); err != nil {
+			return err
+		}
+	}
+
+	// 登录保护检查，失败次数达到阈值时按配置处置
+	var login *loginAttempt
+	if a.ruleEngine != nil {
+		if protection := a.ruleEngine.MatchLoginProtection(host, req.Method, string(req.Path)); protection != nil {
+			login = a.newLoginAttempt(protection, &req, realIP)
+			if err := a.checkLoginAttempt(login, &req); err != nil {
+				return err
+			}
+		}
+	}
+
 	// 进行高频访问检查
 	if a.flowController != nil {
 		allowed, err := a.flowController.CheckVisit(realIP, buildFullURL(host, req.Path, req.Query))
@@ -211,16 +250,8 @@ func (a *Application) HandleRequest(ctx context.Context, writer *encoding.Action
 
 	// micro engine detection
 	if a.ruleEngine != nil {
-		realIP := getRealClientIP(&req)
-		// 获取路径部分
-		path := string(req.Path)
-
-		url := buildURLFromBytes(req.Path, req.Query)
-
-		shouldBlock, _, rule, err := a.ruleEngine.MatchRequest(realIP, url, path)
-
-		if err != nil {
-			a.Logger.Error().Err(err).
+		if matchErr != nil {
+			a.Logger.Error().Err(matchErr).
 				Str("url", url).
 				Str("clientIP", realIP).
 				Msg("failed to match request")
@@ -231,9 +262,10 @@ func (a *Application) HandleRequest(ctx context.Context, writer *encoding.Action
 		if rule != nil {
 			ruleName = rule.Name
 			ruleId = rule.ID.String()
+			a.ruleStats.RecordMicroRule(rule, shouldBlock && matchErr == nil)
 		}
 
-		if shouldBlock && err == nil {
+		if shouldBlock && matchErr == nil {
 			// 记录攻击
 			if a.flowController != nil {
 				_, _ = a.flowController.RecordAttack(realIP, buildFullURL(host, req.Path, req.Query))
@@ -272,6 +304,7 @@ func (a *Application) HandleRequest(ctx context.Context, writer *encoding.Action
 			txCache := &transaction{
 				tx:      tx,
 				request: &req, // 存储请求信息
+				login:   login,
 			}
 			a.cache.SetWithExpiration(tx.ID(), txCache, a.TransactionTTL)
 			return
@@ -293,6 +326,7 @@ func (a *Application) HandleRequest(ctx context.Context, writer *encoding.Action
 			}
 		}
 
+		a.ruleStats.RecordCorazaRules(tx.MatchedRules(), tx.Interruption())
 		tx.ProcessLogging()
 		if err := tx.Close(); err != nil {
 			a.Logger.Error().Str("tx", tx.ID()).Err(err).Msg("failed to close transaction")
@@ -514,6 +548,10 @@ func (a *Application) HandleResponse(ctx context.Context, writer *encoding.Actio
 	// 获取真实客户端IP
 	realIP := getRealClientIP(t.request)
 	host := getHostFromRequest(t.request)
+	if t.login != nil {
+		a.recordLoginResult(t.login, int(res.Status), res.Body)
+	}
+
 	if res.Status >= 400 {
 		// 检查错误响应并记录
 		// 记录错误
@@ -539,6 +577,7 @@ func (a *Application) HandleResponse(ctx context.Context, writer *encoding.Actio
 			}
 		}
 
+		a.ruleStats.RecordCorazaRules(tx.MatchedRules(), tx.Interruption())
 		tx.ProcessLogging()
 		if err := tx.Close(); err != nil {
 			a.Logger.Error().Str("tx", tx.ID()).Err(err).Msg("failed to close transaction")
@@ -677,6 +716,125 @@ func (a *Application) saveMicroEngineLog(rule *Rule, req *applicationRequest, he
 	return a.logStore.Store(firewallLog)
 }
 
+// checkTrapPath 检查请求是否命中蜜罐陷阱路径，被微规则白名单放行的请求不检查，避免封禁受信任的IP
+func (a *Application) checkTrapPath(host, realIP string, req *applicationRequest, whitelisted bool) error {
+	if whitelisted {
+		return nil
+	}
+	if trap := a.ruleEngine.MatchTrapPath(host, string(req.Path)); trap != nil {
+		return a.handleTrapPath(trap, realIP, req)
+	}
+	return nil
+}
+
+// handleTrapPath 处理命中蜜罐陷阱路径的请求：封禁来源IP、记录日志，并返回诱饵响应或 403
+func (a *Application) handleTrapPath(trap *model.TrapPath, realIP string, req *applicationRequest) error {
+	url := buildURLFromBytes(req.Path, req.Query)
+	duration := trap.BlockDurationOrDefault()
+
+	if a.ipRecorder != nil {
+		if err := a.ipRecorder.RecordBlockedIP(realIP, model.BlockReasonHoneypotTrap, url, duration); err != nil {
+			a.Logger.Error().Err(err).
+				Str("ip", realIP).
+				Str("trapName", trap.Name).
+				Msg("failed to record honeypot trap block")
+		}
+	}
+
+	a.Logger.Info().
+		Str("trapName", trap.Name).
+		Str("trapId", trap.ID.Hex()).
+		Str("url", url).
+		Str("clientIP", realIP).
+		Dur("blockDuration", duration).
+		Msg("request blocked by honeypot trap")
+
+	if a.logStore != nil {
+		if err := a.saveTrapPathLog(trap, req); err != nil {
+			a.Logger.Error().Err(err).
+				Str("trapName", trap.Name).
+				Str("url", url).
+				Str("clientIP", realIP).
+				Msg("failed to save honeypot trap log")
+		}
+	}
+
+	if trap.Decoy != nil {
+		return ErrInterrupted{
+			Interruption: &types.Interruption{
+				Action: "decoy",
+				Status: trap.Decoy.StatusCode,
+				Data:   trap.Decoy.Body,
+			},
+		}
+	}
+
+	return ErrInterrupted{
+		Interruption: &types.Interruption{
+			Action: "deny",
+			Status: 403,
+		},
+	}
+}
+
+// saveTrapPathLog 记录蜜罐陷阱命中日志，包含陷阱信息、封禁时长和请求特征
+func (a *Application) saveTrapPathLog(trap *model.TrapPath, req *applicationRequest) error {
+	logMessage := fmt.Sprintf("request blocked by honeypot trap, trapId: %s, trapName: %s, trapPath: %s, matchType: %s, blockDuration: %s",
+		trap.ID.Hex(), trap.Name, trap.Path, trap.MatchType, trap.BlockDurationOrDefault())
+	if trap.Decoy != nil {
+		logMessage += fmt.Sprintf(", decoyStatus: %d", trap.Decoy.StatusCode)
+	}
+
+	return a.saveSecurityEventLog(req, model.BlockReasonHoneypotTrap, logMessage, string(req.Path))
+}
+
+// saveSecurityEventLog 记录非 Coraza 规则触发的安全事件日志，附带来源IP地理位置和 User-Agent
+func (a *Application) saveSecurityEventLog(req *applicationRequest, secMark, message, payload string) error {
+	realIP := getRealClientIP(req)
+	if userAgent, err := getHeaderValue(req.Headers, "User-Agent"); err == nil && userAgent != "" {
+		message += ", userAgent: " + userAgent
+	}
+
+	logs := []model.Log{
+		{
+			Message: message,
+			Payload: payload,
+			SecMark: secMark,
+			LogRaw:  message,
+		},
+	}
+
+	now := time.Now()
+	firewallLog := model.WAFLog{
+		CreatedAt:    now,
+		Request:      buildRequestString(req, req.Headers),
+		Response:     "", // 暂时不处理响应
+		Domain:       getHostFromRequest(req),
+		SrcIP:        realIP,
+		DstIP:        req.DstIp.String(),
+		SrcPort:      int(req.SrcPort),
+		DstPort:      int(req.DstPort),
+		RequestID:    req.ID,
+		SecMark:      secMark,
+		Message:      message,
+		Payload:      payload,
+		URI:          buildURLFromBytes(req.Path, req.Query),
+		Logs:         logs,
+		Date:         now.Format("2006-01-02"),
+		Hour:         now.Hour(),
+		HourGroupSix: now.Hour() / 6,
+		Minute:       now.Minute(),
+	}
+
+	if a.ipProcessor != nil && realIP != "" {
+		if srcIPInfo := a.ipProcessor.GetIPInfo(realIP); srcIPInfo != nil {
+			firewallLog.SrcIPInfo = srcIPInfo
+		}
+	}
+
+	return a.logStore.Store(firewallLog)
+}
+
 func (a *Application) saveFirewallLog(matchedRules []types.MatchedRule, interruption *types.Interruption, req *applicationRequest, headers []byte) error {
 	// 构建日志条目
 	logs := make([]model.Log, 0)
@@ -795,10 +953,15 @@ func (a AppConfig) NewApplicationWithContext(ctx context.Context, options Applic
 
 	// 根据规则引擎数据库配置初始化规则引擎
 	if options.RuleEngineDbConfig != nil && options.RuleEngineDbConfig.MongoClient != nil {
-		ruleEngine := NewRuleEngine()
+		ruleEngine := NewRuleEngine(a.Logger)
 		ruleEngine.InitMongoConfig(options.RuleEngineDbConfig)
-		ruleEngine.LoadAllFromMongoDB()
+		if err := ruleEngine.LoadAllFromMongoDB(); err != nil {
+			a.Logger.Error().Err(err).Msg("加载微规则失败")
+		}
 		app.ruleEngine = ruleEngine
+		if ruleEngine.HasLoginProtection() && !a.ResponseCheck {
+			a.Logger.Warn().Msg("站点已启用登录保护，但未开启响应检测，无法统计登录失败次数")
+		}
 	}
 
 	// 根据GeoIP配置初始化IP处理器
@@ -848,6 +1011,18 @@ func (a AppConfig) NewApplicationWithContext(ctx context.Context, options Applic
 		}
 	}
 
+	// 初始化规则命中统计
+	if options.RuleStatsConfig != nil && options.RuleStatsConfig.Client != nil {
+		ruleStats := NewRuleStatsCollector(
+			options.RuleStatsConfig.Client,
+			options.RuleStatsConfig.Database,
+			options.RuleStatsConfig.FlushInterval,
+			a.Logger,
+		)
+		ruleStats.Start()
+		app.ruleStats = ruleStats
+	}
+
 	debugLogger := debuglog.Default().
 		WithLevel(debuglog.LevelDebug).
 		WithOutput(os.Stdout)
@@ -892,6 +1067,9 @@ func (a AppConfig) NewApplicationWithContext(ctx context.Context, options Applic
 		// 超时回调只负责清理资源，不再检查中断和记录日志
 		// 因为如果事务中断，应该在请求或响应处理阶段就已经记录了日志
 
+		// 未收到响应的事务在回收时统计其请求阶段的规则命中
+		app.ruleStats.RecordCorazaRules(t.tx.MatchedRules(), t.tx.Interruption())
+
 		// Process Logging won't do anything if TX was already logged.
 		t.tx.ProcessLogging()
 		if err := t.tx.Close(); err != nil {
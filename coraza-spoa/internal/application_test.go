@@ -3,8 +3,24 @@ package internal
 import (
 	"bufio"
 	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
 	"strings"
+	"sync"
 	"testing"
+	"time"
+
+	flowcontroller "github.com/HUAHUAI23/RuiQi/coraza-spoa/internal/flow-controller"
+	"github.com/HUAHUAI23/RuiQi/pkg/microrule"
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/corazawaf/coraza/v3/types"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
 // 原始的bufio.Scanner实现（用于对比验证）
@@ -208,3 +224,700 @@ Forwarded: for=192.168.1.100;proto=https;by=proxy`),
 		})
 	}
 }
+
+// TestHostScopeMatchesRequestHost 测试从 Host 头部去掉端口后按站点作用域匹配，不区分大小写
+func TestHostScopeMatchesRequestHost(t *testing.T) {
+	scope := hostScope{scoped: true, patterns: []string{"*.example.com", "shop.test"}}
+
+	tests := []struct {
+		name    string
+		headers []byte
+		want    bool
+	}{
+		{"通配符子域名带端口", []byte("Host: WWW.Example.com:8443"), true},
+		{"精确主机名带端口", []byte("Host: shop.test:80"), true},
+		{"精确主机名大写", []byte("Host: SHOP.TEST"), true},
+		{"通配符不匹配根域名", []byte("Host: example.com:8443"), false},
+		{"其他站点", []byte("Host: other.test"), false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			host := getHostFromRequest(&applicationRequest{Headers: tt.headers})
+			if got := scope.matches(host); got != tt.want {
+				t.Errorf("matches(%q) = %v, want %v", host, got, tt.want)
+			}
+		})
+	}
+	if !(hostScope{}).matches("any.test") {
+		t.Error("unscoped rule should match every host")
+	}
+}
+
+// newTestRule 创建匹配单个简单条件的已启用规则
+func newTestRule(name string, ruleType model.RuleType, target microrule.TargetType, matchType microrule.MatchType, value string) Rule {
+	condition, err := bson.Marshal(microrule.SimpleCondition{Type: microrule.SimpleConditionType, Target: target, MatchType: matchType, MatchValue: value})
+	if err != nil {
+		panic(err)
+	}
+	return Rule{MicroRule: model.MicroRule{ID: bson.NewObjectID(), Name: name, Type: ruleType, Status: model.RuleEnabled, Condition: condition}}
+}
+
+// TestPrepareRulesSkipsInvalidSchedule 测试生效计划无效的规则被跳过，视同禁用，不影响其他规则的加载和白名单兜底拦截
+func TestPrepareRulesSkipsInvalidSchedule(t *testing.T) {
+	badTimezone := newTestRule("bad-timezone", model.WhitelistRule, microrule.SourceIP, microrule.MatchEqual, "10.0.0.1")
+	badTimezone.Schedule = &model.RuleSchedule{Timezone: "Mars/Olympus", Windows: []model.WeeklyWindow{{Start: "09:00", End: "18:00"}}}
+	scheduled := newTestRule("scheduled", model.BlacklistRule, microrule.TargetPath, microrule.MatchPrefixKeyword, "/admin")
+	scheduled.Schedule = &model.RuleSchedule{Timezone: "Asia/Shanghai", Windows: []model.WeeklyWindow{{Start: "00:00", End: "24:00"}}}
+
+	engine := NewRuleEngine(zerolog.Nop())
+	rules, err := engine.prepareRules([]Rule{badTimezone, scheduled})
+	if err != nil {
+		t.Fatalf("prepareRules() error = %v", err)
+	}
+	if len(rules) != 1 || rules[0].Name != "scheduled" {
+		t.Fatalf("prepareRules() = %v, want only scheduled rule", rules)
+	}
+	engine.Rules = rules
+
+	block, _, rule, err := engine.MatchRequest("example.com", "10.0.0.2", "/admin/users", "/admin/users")
+	if err != nil || !block || rule == nil || rule.Name != "scheduled" {
+		t.Errorf("MatchRequest(/admin/users) = %v, %v, %v, want blocked by scheduled", block, rule, err)
+	}
+	// 跳过的白名单规则不触发白名单兜底拦截
+	if block, _, _, err := engine.MatchRequest("example.com", "10.0.0.2", "/", "/"); err != nil || block {
+		t.Errorf("MatchRequest(/) = %v, %v, want allowed", block, err)
+	}
+
+	unparsable := newTestRule("unparsable", model.BlacklistRule, microrule.SourceIP, microrule.MatchEqual, "10.0.0.1")
+	unparsable.Condition, _ = bson.Marshal(bson.D{{Key: "type", Value: "unknown"}})
+	if _, err := engine.prepareRules([]Rule{scheduled, unparsable}); err == nil {
+		t.Error("prepareRules() should fail for unparsable condition")
+	}
+}
+
+// TestSortRulesMatchOrder 测试规则按优先级降序排列，优先级相同时按ID即创建顺序，与读取顺序无关，没有ID时保持读取顺序
+func TestSortRulesMatchOrder(t *testing.T) {
+	ids := []bson.ObjectID{bson.NewObjectID(), bson.NewObjectID(), bson.NewObjectID(), bson.NewObjectID()}
+	rules := []Rule{
+		{MicroRule: model.MicroRule{ID: ids[3], Name: "low", Priority: 1}},
+		{MicroRule: model.MicroRule{ID: ids[2], Name: "newer", Priority: 5}},
+		{MicroRule: model.MicroRule{ID: ids[0], Name: "high", Priority: 9}},
+		{MicroRule: model.MicroRule{ID: ids[1], Name: "older", Priority: 5}},
+		{MicroRule: model.MicroRule{Name: "no-id-b", Priority: 1}},
+		{MicroRule: model.MicroRule{Name: "no-id-a", Priority: 1}},
+	}
+	sortRules(rules)
+
+	var names []string
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	want := []string{"high", "older", "newer", "no-id-b", "no-id-a", "low"}
+	if strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("sortRules() = %v, want %v", names, want)
+	}
+}
+
+// TestMatchTrapPath 测试陷阱路径的精确和前缀匹配（不区分大小写）、站点作用域以及禁用的陷阱
+func TestMatchTrapPath(t *testing.T) {
+	engine := NewRuleEngine(zerolog.Nop())
+	engine.siteDomains["shop"] = []string{"shop.test"}
+	for _, trap := range []*model.TrapPath{
+		{Name: "dotenv", Path: "/.env", MatchType: model.TrapMatchExact, Enabled: true},
+		{Name: "disabled", Path: "/backup.zip", MatchType: model.TrapMatchExact},
+		{Name: "wp-admin", Path: "/wp-admin", MatchType: model.TrapMatchPrefix, Scope: &model.SiteScope{Hosts: []string{"*.example.com"}}, Enabled: true},
+		{Name: "git", Path: "/.git", MatchType: model.TrapMatchPrefix, Scope: &model.SiteScope{SiteIDs: []string{"shop", "deleted"}}, Enabled: true},
+		{Name: "deleted-site", Path: "/.svn", MatchType: model.TrapMatchPrefix, Scope: &model.SiteScope{SiteIDs: []string{"deleted"}}, Enabled: true},
+		{Name: "env-prefix", Path: "/.env", MatchType: model.TrapMatchPrefix, Enabled: true},
+	} {
+		engine.AddTrapPath(trap)
+	}
+
+	tests := []struct {
+		name string
+		host string
+		path string
+		want string
+	}{
+		{"精确匹配", "any.test", "/.env", "dotenv"},
+		{"精确匹配不区分大小写", "any.test", "/.ENV", "dotenv"},
+		{"精确匹配未命中时继续匹配后续陷阱", "any.test", "/.env.bak", "env-prefix"},
+		{"禁用的陷阱", "any.test", "/backup.zip", ""},
+		{"前缀匹配", "www.example.com", "/WP-Admin/install.php", "wp-admin"},
+		{"路径短于前缀", "www.example.com", "/wp-", ""},
+		{"主机名不在作用域内", "other.test", "/wp-admin/install.php", ""},
+		{"通配符不匹配根域名", "example.com", "/wp-admin", ""},
+		{"站点作用域", "shop.test", "/.git/config", "git"},
+		{"站点作用域的其他站点", "www.example.com", "/.git/config", ""},
+		{"站点已删除", "shop.test", "/.svn/entries", ""},
+		{"未命中", "shop.test", "/index.html", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if trap := engine.MatchTrapPath(tt.host, tt.path); trap != nil {
+				got = trap.Name
+			}
+			if got != tt.want {
+				t.Errorf("MatchTrapPath(%q, %q) = %q, want %q", tt.host, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeIPRecorder 记录被封禁的IP
+type fakeIPRecorder struct {
+	flowcontroller.IPRecorder
+	blocked []fakeBlockedIP
+}
+
+type fakeBlockedIP struct {
+	ip       string
+	reason   string
+	uri      string
+	duration time.Duration
+}
+
+func (r *fakeIPRecorder) RecordBlockedIP(ip string, reason string, requestUri string, duration time.Duration) error {
+	r.blocked = append(r.blocked, fakeBlockedIP{ip: ip, reason: reason, uri: requestUri, duration: duration})
+	return nil
+}
+
+// fakeLogStore 记录写入的日志
+type fakeLogStore struct {
+	LogStore
+	logs []model.WAFLog
+}
+
+func (s *fakeLogStore) Store(log model.WAFLog) error {
+	s.logs = append(s.logs, log)
+	return nil
+}
+
+// TestCheckTrapPath 测试命中陷阱时封禁来源IP、记录日志并返回诱饵响应或 403，白名单放行的请求不触发陷阱
+func TestCheckTrapPath(t *testing.T) {
+	engine := NewRuleEngine(zerolog.Nop())
+	engine.AddTrapPath(&model.TrapPath{Name: "dotenv", Path: "/.env", MatchType: model.TrapMatchExact, Enabled: true,
+		Decoy: &model.TrapDecoy{StatusCode: model.TrapDecoyStatusOK, Body: "APP_KEY=changeme"}})
+	engine.AddTrapPath(&model.TrapPath{Name: "wp-admin", Path: "/wp-admin", MatchType: model.TrapMatchPrefix, BlockDuration: 600, Enabled: true})
+
+	tests := []struct {
+		name         string
+		path         string
+		whitelisted  bool
+		wantAction   string
+		wantStatus   int
+		wantData     string
+		wantDuration time.Duration
+	}{
+		{"诱饵响应", "/.env", false, "decoy", model.TrapDecoyStatusOK, "APP_KEY=changeme", model.DefaultTrapBlockDuration * time.Second},
+		{"没有诱饵响应时返回 403", "/wp-admin/install.php", false, "deny", 403, "", 10 * time.Minute},
+		{"白名单放行的请求", "/.env", true, "", 0, "", 0},
+		{"未命中陷阱", "/index.html", false, "", 0, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &fakeIPRecorder{}
+			logStore := &fakeLogStore{}
+			a := &Application{ruleEngine: engine, ipRecorder: recorder, logStore: logStore, AppConfig: AppConfig{Logger: zerolog.Nop()}}
+			req := &applicationRequest{SrcIp: netip.MustParseAddr("203.0.113.7"), Path: []byte(tt.path), Query: []byte("x=1")}
+
+			err := a.checkTrapPath("example.com", "203.0.113.7", req, tt.whitelisted)
+			if tt.wantAction == "" {
+				if err != nil || len(recorder.blocked) != 0 || len(logStore.logs) != 0 {
+					t.Errorf("checkTrapPath() = %v, blocked = %v, logs = %d, want no action", err, recorder.blocked, len(logStore.logs))
+				}
+				return
+			}
+
+			var interrupted ErrInterrupted
+			if !errors.As(err, &interrupted) {
+				t.Fatalf("checkTrapPath() = %v, want ErrInterrupted", err)
+			}
+			if got := interrupted.Interruption; got.Action != tt.wantAction || got.Status != tt.wantStatus || got.Data != tt.wantData {
+				t.Errorf("interruption = %+v, want %s %d %q", got, tt.wantAction, tt.wantStatus, tt.wantData)
+			}
+			want := fakeBlockedIP{ip: "203.0.113.7", reason: model.BlockReasonHoneypotTrap, uri: tt.path + "?x=1", duration: tt.wantDuration}
+			if len(recorder.blocked) != 1 || recorder.blocked[0] != want {
+				t.Errorf("blocked = %+v, want %+v", recorder.blocked, want)
+			}
+			if len(logStore.logs) != 1 || logStore.logs[0].SecMark != model.BlockReasonHoneypotTrap || logStore.logs[0].SrcIP != "203.0.113.7" {
+				t.Errorf("logs = %+v, want one honeypot trap log", logStore.logs)
+			}
+		})
+	}
+}
+
+// TestMatchLoginProtection 测试按主机名、请求方法和路径匹配登录保护，完全匹配的主机名优先于通配符域名
+func TestMatchLoginProtection(t *testing.T) {
+	engine := NewRuleEngine(zerolog.Nop())
+	sites := []struct {
+		id        string
+		hostnames []string
+		config    *model.LoginProtection
+	}{
+		{"main", []string{"Example.com", "*.example.com"}, &model.LoginProtection{Enabled: true, Path: "/api/login", Method: "POST"}},
+		{"admin", []string{"admin.example.com"}, &model.LoginProtection{Enabled: true, Path: "/login", Method: "POST"}},
+		{"disabled", []string{"off.test"}, &model.LoginProtection{Path: "/login", Method: "POST"}},
+		{"none", []string{"none.test"}, nil},
+	}
+	for _, site := range sites {
+		if err := engine.addLoginProtection(site.id, site.hostnames, site.config); err != nil {
+			t.Fatalf("addLoginProtection(%s) error = %v", site.id, err)
+		}
+	}
+	if err := engine.addLoginProtection("bad", []string{"bad.test"}, &model.LoginProtection{Enabled: true, FailurePattern: "("}); err == nil {
+		t.Error("addLoginProtection() should fail for invalid failure pattern")
+	}
+
+	tests := []struct {
+		name   string
+		host   string
+		method string
+		path   string
+		want   string
+	}{
+		{"完全匹配", "example.com", "POST", "/api/login", "main"},
+		{"主机名和方法不区分大小写", "EXAMPLE.com", "post", "/api/login", "main"},
+		{"通配符域名", "www.example.com", "POST", "/api/login", "main"},
+		{"请求方法不同", "example.com", "GET", "/api/login", ""},
+		{"路径不完全相同", "example.com", "POST", "/api/login/", ""},
+		{"完全匹配的主机名优先", "admin.example.com", "POST", "/login", "admin"},
+		{"完全匹配的主机名不回退到通配符域名", "admin.example.com", "POST", "/api/login", ""},
+		{"未启用", "off.test", "POST", "/login", ""},
+		{"未配置", "none.test", "POST", "/login", ""},
+		{"其他站点", "other.test", "POST", "/api/login", ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got string
+			if protection := engine.MatchLoginProtection(tt.host, tt.method, tt.path); protection != nil {
+				got = protection.siteID
+			}
+			if got != tt.want {
+				t.Errorf("MatchLoginProtection(%q, %q, %q) = %q, want %q", tt.host, tt.method, tt.path, got, tt.want)
+			}
+		})
+	}
+}
+
+// fakeIPProcessor 返回固定ASN的IP信息
+type fakeIPProcessor struct {
+	IPProcessor
+	asn uint
+}
+
+func (p *fakeIPProcessor) GetIPInfo(ipStr string) *model.IPInfo {
+	info := &model.IPInfo{}
+	info.ASN.Number = p.asn
+	return info
+}
+
+// TestNewLoginAttempt 测试从表单和 JSON 请求体中提取小写的用户名，并记录来源IP和ASN
+func TestNewLoginAttempt(t *testing.T) {
+	tests := []struct {
+		name         string
+		field        string
+		headers      string
+		body         string
+		ipProcessor  IPProcessor
+		wantUsername string
+		wantASN      uint
+	}{
+		{"表单", "username", "Content-Type: application/x-www-form-urlencoded", "username=%20Alice%20&password=x", nil, "alice", 0},
+		{"没有 Content-Type 按表单解析", "user", "", "user=bob", &fakeIPProcessor{asn: 4134}, "bob", 4134},
+		{"JSON 嵌套字段", "user.name", "Content-Type: application/json; charset=utf-8", `{"user":{"name":"Carol"}}`, nil, "carol", 0},
+		{"JSON 字段不是字符串", "user.name", "Content-Type: application/json", `{"user":{"name":1}}`, nil, "", 0},
+		{"无效的 JSON", "username", "Content-Type: application/json", `{"username":`, nil, "", 0},
+		{"缺少用户名字段", "username", "Content-Type: application/x-www-form-urlencoded", "password=x", nil, "", 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			a := &Application{ipProcessor: tt.ipProcessor}
+			protection := &loginProtection{LoginProtection: &model.LoginProtection{UsernameField: tt.field}, siteID: "site"}
+			req := &applicationRequest{Headers: []byte(tt.headers), Body: []byte(tt.body)}
+
+			attempt := a.newLoginAttempt(protection, req, "203.0.113.7")
+			if attempt.protection != protection || attempt.username != tt.wantUsername || attempt.ip != "203.0.113.7" || attempt.asn != tt.wantASN {
+				t.Errorf("newLoginAttempt() = %+v, want username %q and asn %d", attempt, tt.wantUsername, tt.wantASN)
+			}
+		})
+	}
+}
+
+// TestLoginGuardThreshold 测试任一维度的失败次数达到阈值即超限，各站点分别统计，登录成功只清除用户名的统计
+func TestLoginGuardThreshold(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	protection := &loginProtection{
+		LoginProtection: &model.LoginProtection{Window: 60, UsernameThreshold: 3, IPThreshold: 5},
+		siteID:          "site",
+	}
+	attempt := func(username, ip string) *loginAttempt {
+		return &loginAttempt{protection: protection, username: username, ip: ip, asn: 4134}
+	}
+	guard := newLoginGuard()
+
+	check := func(a *loginAttempt, wantDimension string, wantCount int64) {
+		t.Helper()
+		if dimension, count := guard.exceeded(a, now); dimension != wantDimension || count != wantCount {
+			t.Errorf("exceeded(%s, %s) = %q, %d, want %q, %d", a.username, a.ip, dimension, count, wantDimension, wantCount)
+		}
+	}
+
+	guard.recordFailure(attempt("alice", "10.0.0.1"), now)
+	guard.recordFailure(attempt("alice", "10.0.0.1"), now)
+	check(attempt("alice", "10.0.0.1"), "", 0)
+	guard.recordFailure(attempt("alice", "10.0.0.2"), now)
+	check(attempt("alice", "10.0.0.9"), loginDimensionUsername, 3)
+
+	// 同一来源IP尝试不同的用户名
+	guard.recordFailure(attempt("bob", "10.0.0.1"), now)
+	guard.recordFailure(attempt("bob", "10.0.0.1"), now)
+	check(attempt("carol", "10.0.0.1"), "", 0)
+	guard.recordFailure(attempt("dave", "10.0.0.1"), now)
+	check(attempt("carol", "10.0.0.1"), loginDimensionIP, 5)
+
+	// 阈值为 0 的维度不统计，ASN 维度未配置阈值
+	check(&loginAttempt{protection: protection, asn: 4134}, "", 0)
+
+	// 其他站点的同一用户名不受影响
+	other := &loginProtection{LoginProtection: protection.LoginProtection, siteID: "other"}
+	check(&loginAttempt{protection: other, username: "alice", ip: "10.0.0.1"}, "", 0)
+
+	// 登录成功后用户名的统计清除，来源IP的统计保留
+	guard.resetUsername(attempt("alice", "10.0.0.1"))
+	check(attempt("alice", "10.0.0.9"), "", 0)
+	check(attempt("alice", "10.0.0.1"), loginDimensionIP, 5)
+}
+
+// TestLoginGuardWindow 测试滑动窗口内的失败次数随时间衰减，超过两个窗口后清零，修改窗口时长后重新统计
+func TestLoginGuardWindow(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	// 阈值为 1 时 exceeded 返回窗口内的失败次数
+	config := &model.LoginProtection{Window: 60, UsernameThreshold: 1}
+	protection := &loginProtection{LoginProtection: config, siteID: "site"}
+	alice := &loginAttempt{protection: protection, username: "alice"}
+	guard := newLoginGuard()
+	guard.lastSweep = start
+	for range 4 {
+		guard.recordFailure(alice, start)
+	}
+
+	tests := []struct {
+		name    string
+		elapsed time.Duration
+		want    int64
+	}{
+		{"当前窗口内", 59 * time.Second, 4},
+		{"下一个窗口开始", 60 * time.Second, 4},
+		{"下一个窗口过半", 90 * time.Second, 2},
+		{"两个窗口之后", 120 * time.Second, 0},
+	}
+	for _, tt := range tests {
+		if _, count := guard.exceeded(alice, start.Add(tt.elapsed)); count != tt.want {
+			t.Errorf("%s: count = %d, want %d", tt.name, count, tt.want)
+		}
+	}
+
+	// 修改窗口时长后重新开始统计
+	bob := &loginAttempt{protection: protection, username: "bob"}
+	guard.recordFailure(bob, start)
+	config.Window = 30
+	guard.recordFailure(bob, start.Add(10*time.Second))
+	if _, count := guard.exceeded(bob, start.Add(10*time.Second)); count != 1 {
+		t.Errorf("count after window change = %d, want 1", count)
+	}
+
+	// 两个窗口内没有失败记录的计数器在清理时删除
+	guard.recordFailure(&loginAttempt{protection: protection, username: "carol"}, start.Add(time.Minute))
+	if _, ok := guard.windows["site|username|alice"]; !ok {
+		t.Error("counter within two windows should be kept")
+	}
+	// 读取计数时窗口已推进到两个窗口之后
+	guard.recordFailure(&loginAttempt{protection: protection, username: "carol"}, start.Add(4*time.Minute))
+	if _, ok := guard.windows["site|username|alice"]; ok {
+		t.Error("expired counter should be swept")
+	}
+	if _, ok := guard.windows["site|username|carol"]; !ok {
+		t.Error("active counter should be kept")
+	}
+}
+
+// TestCheckLoginAttempt 测试失败次数达到阈值前放行，达到后按配置的动作处置：限流、封禁来源IP或返回挑战页，通过挑战后放行
+func TestCheckLoginAttempt(t *testing.T) {
+	const ip = "203.0.113.7"
+	tests := []struct {
+		action     model.LoginProtectionAction
+		cookie     string
+		wantAction string
+		wantStatus int
+		wantData   string
+		wantBlock  bool
+	}{
+		{action: model.LoginActionThrottle, wantAction: "deny", wantStatus: 429, wantData: "Too many failed login attempts"},
+		{action: "", wantAction: "deny", wantStatus: 429, wantData: "Too many failed login attempts"},
+		{action: model.LoginActionBlock, wantAction: "deny", wantStatus: 403, wantBlock: true},
+		{action: model.LoginActionChallenge, wantAction: "challenge", wantStatus: 403, wantData: loginChallengeCookie + "="},
+		{action: model.LoginActionChallenge, cookie: newLoginChallenge("198.51.100.1", time.Now()), wantAction: "challenge", wantStatus: 403, wantData: loginChallengeCookie + "="},
+		{action: model.LoginActionChallenge, cookie: newLoginChallenge(ip, time.Now())},
+	}
+	for i, tt := range tests {
+		t.Run(fmt.Sprintf("%d_%s", i, tt.action), func(t *testing.T) {
+			// 每个用例使用独立的站点ID，避免共享的计数器互相影响
+			protection := &loginProtection{
+				LoginProtection: &model.LoginProtection{Window: 600, IPThreshold: 3, Action: tt.action, BlockDuration: 120},
+				siteID:          fmt.Sprintf("check-login-attempt-%d", i),
+			}
+			attempt := &loginAttempt{protection: protection, username: "alice", ip: ip}
+			recorder := &fakeIPRecorder{}
+			logStore := &fakeLogStore{}
+			a := &Application{ipRecorder: recorder, logStore: logStore, AppConfig: AppConfig{Logger: zerolog.Nop()}}
+			headers := "Content-Type: application/x-www-form-urlencoded"
+			if tt.cookie != "" {
+				headers += "\r\nCookie: other=1; " + loginChallengeCookie + "=" + tt.cookie
+			}
+			req := &applicationRequest{Path: []byte("/login"), Headers: []byte(headers)}
+
+			for range 2 {
+				defaultLoginGuard.recordFailure(attempt, time.Now())
+			}
+			if err := a.checkLoginAttempt(attempt, req); err != nil {
+				t.Fatalf("checkLoginAttempt() below threshold = %v, want nil", err)
+			}
+
+			defaultLoginGuard.recordFailure(attempt, time.Now())
+			err := a.checkLoginAttempt(attempt, req)
+			if tt.wantAction == "" {
+				if err != nil || len(logStore.logs) != 0 {
+					t.Errorf("checkLoginAttempt() = %v, logs = %d, want passed challenge", err, len(logStore.logs))
+				}
+				return
+			}
+
+			var interrupted ErrInterrupted
+			if !errors.As(err, &interrupted) {
+				t.Fatalf("checkLoginAttempt() = %v, want ErrInterrupted", err)
+			}
+			if got := interrupted.Interruption; got.Action != tt.wantAction || got.Status != tt.wantStatus || !strings.Contains(got.Data, tt.wantData) {
+				t.Errorf("interruption = %+v, want %s %d containing %q", got, tt.wantAction, tt.wantStatus, tt.wantData)
+			}
+			if tt.wantBlock {
+				want := fakeBlockedIP{ip: ip, reason: model.BlockReasonCredentialStuffing, uri: "/login", duration: 2 * time.Minute}
+				if len(recorder.blocked) != 1 || recorder.blocked[0] != want {
+					t.Errorf("blocked = %+v, want %+v", recorder.blocked, want)
+				}
+			} else if len(recorder.blocked) != 0 {
+				t.Errorf("blocked = %+v, want none", recorder.blocked)
+			}
+			if len(logStore.logs) != 1 || logStore.logs[0].SecMark != model.BlockReasonCredentialStuffing || logStore.logs[0].Payload != "alice" {
+				t.Errorf("logs = %+v, want one credential stuffing log", logStore.logs)
+			}
+		})
+	}
+}
+
+// fakeRuleStatsWriter 记录每次批量写入的统计增量，err 不为空时返回错误，failRule 不为空时该规则的写入失败
+type fakeRuleStatsWriter struct {
+	mu       sync.Mutex
+	writes   [][]string
+	err      error
+	failRule string
+}
+
+func (w *fakeRuleStatsWriter) BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...options.Lister[options.BulkWriteOptions]) (*mongo.BulkWriteResult, error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	write := make([]string, 0, len(models))
+	var bulkErr mongo.BulkWriteException
+	for i, m := range models {
+		write = append(write, describeRuleStatsModel(m))
+		if w.failRule != "" && strings.HasPrefix(write[i], w.failRule+"@") {
+			bulkErr.WriteErrors = append(bulkErr.WriteErrors, mongo.BulkWriteError{WriteError: mongo.WriteError{Index: i, Code: 11000, Message: "duplicate key"}})
+		}
+	}
+	w.writes = append(w.writes, write)
+	if len(bulkErr.WriteErrors) > 0 {
+		return &mongo.BulkWriteResult{}, bulkErr
+	}
+	return &mongo.BulkWriteResult{}, w.err
+}
+
+// flushed 返回所有写入的增量并清空记录
+func (w *fakeRuleStatsWriter) flushed() [][]string {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	writes := w.writes
+	w.writes = nil
+	return writes
+}
+
+// describeRuleStatsModel 把 upsert 操作描述为 "来源/规则ID@统计桶 hits=命中 blocks=拦截 name=规则名"，按规则ID排序后比较
+func describeRuleStatsModel(m mongo.WriteModel) string {
+	update := m.(*mongo.UpdateOneModel)
+	filter := make(map[string]any)
+	for _, field := range update.Filter.(bson.D) {
+		filter[field.Key] = field.Value
+	}
+	fields := make(map[string]any)
+	for _, op := range update.Update.(bson.D) {
+		for _, field := range op.Value.(bson.D) {
+			fields[field.Key] = field.Value
+		}
+	}
+	return fmt.Sprintf("%s/%s@%s hits=%d blocks=%d name=%v last=%s",
+		filter["source"], filter["rule_id"], filter["bucket"].(time.Time).UTC().Format("15:04"),
+		fields["hits"], fields["blocks"], fields["rule_name"], fields["last_hit_at"].(time.Time).UTC().Format("15:04:05"))
+}
+
+// testMatchedRule 只实现 Rule().ID() 的 Coraza 命中规则
+type testMatchedRule struct {
+	types.MatchedRule
+	id int
+}
+
+func (r testMatchedRule) Rule() types.RuleMetadata { return testRuleMetadata{id: r.id} }
+
+type testRuleMetadata struct {
+	types.RuleMetadata
+	id int
+}
+
+func (m testRuleMetadata) ID() int { return m.id }
+
+func newTestRuleStatsCollector(writer *fakeRuleStatsWriter) *RuleStatsCollector {
+	return &RuleStatsCollector{collection: writer, flushInterval: time.Hour, logger: zerolog.Nop()}
+}
+
+// TestRuleStatsCollectorFlush 测试命中按发生时所在的小时写入统计桶，拦截单独计数，刷新后增量清零
+func TestRuleStatsCollectorFlush(t *testing.T) {
+	writer := &fakeRuleStatsWriter{}
+	c := newTestRuleStatsCollector(writer)
+	id := bson.NewObjectIDFromTimestamp(time.Unix(0, 0))
+	rule := &Rule{MicroRule: model.MicroRule{ID: id, Name: "block-admin"}}
+	lastHour := time.Date(2025, 6, 1, 11, 59, 58, 0, time.UTC)
+	now := time.Date(2025, 6, 1, 12, 0, 3, 0, time.UTC)
+
+	c.recordMicroRule(rule, true, lastHour)
+	c.recordMicroRule(rule, false, lastHour.Add(time.Second))
+	c.recordMicroRule(rule, true, now)
+	c.recordCorazaRules([]types.MatchedRule{testMatchedRule{id: 942100}, testMatchedRule{id: 0}, testMatchedRule{id: 949110}},
+		&types.Interruption{RuleID: 949110}, now)
+	c.recordCorazaRules(nil, nil, now)
+	c.recordMicroRule(nil, true, now)
+
+	c.flush(now)
+	writes := writer.flushed()
+	if len(writes) != 1 {
+		t.Fatalf("flush() writes = %d, want 1", len(writes))
+	}
+	got := writes[0]
+	slices.Sort(got)
+	want := []string{
+		"coraza/942100@12:00 hits=1 blocks=0 name=<nil> last=12:00:03",
+		"coraza/949110@12:00 hits=1 blocks=1 name=<nil> last=12:00:03",
+		fmt.Sprintf("micro/%s@11:00 hits=2 blocks=1 name=block-admin last=11:59:59", id.Hex()),
+		fmt.Sprintf("micro/%s@12:00 hits=1 blocks=1 name=block-admin last=12:00:03", id.Hex()),
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("flush() models =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+
+	// 没有新的命中时不写入，已结束小时的计数器在空闲一个刷新周期后删除
+	c.flush(now.Add(10 * time.Second))
+	if writes := writer.flushed(); len(writes) != 0 {
+		t.Errorf("flush() without hits writes = %v, want none", writes)
+	}
+	var buckets []int64
+	c.counters.Range(func(k, v any) bool {
+		buckets = append(buckets, k.(ruleStatsKey).bucket)
+		return true
+	})
+	for _, bucket := range buckets {
+		if bucket != now.Truncate(time.Hour).Unix() {
+			t.Errorf("counter for finished bucket %s kept", time.Unix(bucket, 0).UTC())
+		}
+	}
+	if len(buckets) != 3 {
+		t.Errorf("counters = %d, want 3 for the current hour", len(buckets))
+	}
+}
+
+// TestRuleStatsCollectorFlushFailure 测试写入失败的增量加回计数器并在下次刷新时写入，部分写入失败时只加回失败的增量
+func TestRuleStatsCollectorFlushFailure(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 30, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name     string
+		err      error
+		failRule string
+		want     []string
+	}{
+		{
+			name: "写入失败",
+			err:  errors.New("connection refused"),
+			want: []string{
+				"coraza/1@12:00 hits=3 blocks=0 name=<nil> last=12:30:05",
+				"coraza/2@12:00 hits=1 blocks=1 name=<nil> last=12:30:00",
+			},
+		},
+		{
+			name:     "部分写入失败",
+			failRule: "coraza/2",
+			want: []string{
+				"coraza/1@12:00 hits=1 blocks=0 name=<nil> last=12:30:05",
+				"coraza/2@12:00 hits=1 blocks=1 name=<nil> last=12:30:00",
+			},
+		},
+		{
+			name: "写关注错误",
+			err:  mongo.BulkWriteException{WriteConcernError: &mongo.WriteConcernError{Code: 64, Message: "waiting for replication timed out"}},
+			want: []string{
+				"coraza/1@12:00 hits=3 blocks=0 name=<nil> last=12:30:05",
+				"coraza/2@12:00 hits=1 blocks=1 name=<nil> last=12:30:00",
+			},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			writer := &fakeRuleStatsWriter{err: tt.err, failRule: tt.failRule}
+			c := newTestRuleStatsCollector(writer)
+			c.recordCorazaRules([]types.MatchedRule{testMatchedRule{id: 1}}, nil, now)
+			c.recordCorazaRules([]types.MatchedRule{testMatchedRule{id: 1}}, nil, now)
+			c.recordCorazaRules([]types.MatchedRule{testMatchedRule{id: 2}}, &types.Interruption{RuleID: 2}, now)
+			c.flush(now)
+
+			// 两次刷新之间的新命中与加回的增量合并写入
+			writer.err, writer.failRule = nil, ""
+			c.recordCorazaRules([]types.MatchedRule{testMatchedRule{id: 1}}, nil, now.Add(5*time.Second))
+			c.flush(now.Add(10 * time.Second))
+
+			writes := writer.flushed()
+			if len(writes) != 2 {
+				t.Fatalf("flush() writes = %d, want 2", len(writes))
+			}
+			got := writes[1]
+			slices.Sort(got)
+			if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+				t.Errorf("retry models =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(tt.want, "\n"))
+			}
+		})
+	}
+}
+
+// TestRuleStatsCollectorStartClose 测试关闭时写入剩余增量，重复启动和关闭不会重复创建协程
+func TestRuleStatsCollectorStartClose(t *testing.T) {
+	writer := &fakeRuleStatsWriter{}
+	c := newTestRuleStatsCollector(writer)
+	c.Close()
+
+	for range 2 {
+		c.Start()
+		c.Start()
+		c.RecordCorazaRules([]types.MatchedRule{testMatchedRule{id: 7}}, &types.Interruption{RuleID: 7})
+		c.Close()
+		c.Close()
+
+		writes := writer.flushed()
+		if len(writes) != 1 || len(writes[0]) != 1 || !strings.HasPrefix(writes[0][0], "coraza/7@") || !strings.Contains(writes[0][0], "hits=1 blocks=1") {
+			t.Errorf("writes after Close() = %v, want one flush with the remaining hit", writes)
+		}
+	}
+}
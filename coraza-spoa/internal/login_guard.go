@@ -0,0 +1,409 @@
+package internal
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/corazawaf/coraza/v3/types"
+)
+
+const (
+	loginChallengeCookie = "ruiqi_login_challenge" // 挑战通过后写入的 Cookie 名称
+	loginChallengeTTL    = 30 * time.Minute        // 挑战通过后的有效期
+	loginGuardSweepEvery = time.Minute             // 清理过期计数器的间隔
+	maxLoginUsernameLen  = 256                     // 参与统计的用户名最大长度，避免超长用户名占用内存
+)
+
+// 登录失败统计维度
+const (
+	loginDimensionUsername = "username"
+	loginDimensionIP       = "ip"
+	loginDimensionASN      = "asn"
+)
+
+// loginChallengeSecret 挑战令牌签名密钥，进程启动时随机生成，重启后已通过的挑战失效
+var loginChallengeSecret = func() []byte {
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		panic(fmt.Sprintf("生成登录挑战密钥失败: %v", err))
+	}
+	return secret
+}()
+
+// defaultLoginGuard 登录失败计数器，使用包级实例使统计在应用热更新后保留
+var defaultLoginGuard = newLoginGuard()
+
+// loginProtection 运行时登录保护配置
+type loginProtection struct {
+	*model.LoginProtection
+	siteID    string         // 站点ID，用于隔离不同站点的统计
+	failureRe *regexp.Regexp // 编译后的失败响应内容正则
+}
+
+// loginAttempt 一次登录请求，缓存在 transaction 中，用于在响应阶段判断登录是否失败
+type loginAttempt struct {
+	protection *loginProtection
+	username   string
+	ip         string
+	asn        uint
+}
+
+// addLoginProtection 为站点的所有主机名添加登录保护配置，未启用时忽略
+func (e *RuleEngine) addLoginProtection(siteID string, hostnames []string, config *model.LoginProtection) error {
+	if config == nil || !config.Enabled {
+		return nil
+	}
+
+	protection := &loginProtection{LoginProtection: config, siteID: siteID}
+	if config.FailurePattern != "" {
+		re, err := regexp.Compile(config.FailurePattern)
+		if err != nil {
+			return fmt.Errorf("站点 %s 的登录失败响应正则无效: %v", hostnames[0], err)
+		}
+		protection.failureRe = re
+	}
+
+	for _, hostname := range hostnames {
+		e.loginProtections[strings.ToLower(hostname)] = protection
+	}
+	return nil
+}
+
+// MatchLoginProtection 返回请求命中的登录保护配置，非受保护的登录请求返回 nil
+// 完全匹配的主机名优先于通配符域名，通配符域名越具体越优先
+func (e *RuleEngine) MatchLoginProtection(host, method, path string) *loginProtection {
+	for _, candidate := range model.HostPatternCandidates(host) {
+		if protection, ok := e.loginProtections[candidate]; ok {
+			if !protection.MatchRequest(method, path) {
+				return nil
+			}
+			return protection
+		}
+	}
+	return nil
+}
+
+// HasLoginProtection 是否有站点启用了登录保护
+func (e *RuleEngine) HasLoginProtection() bool {
+	return len(e.loginProtections) > 0
+}
+
+// isFailure 根据响应状态码和响应内容判断登录是否失败
+func (p *loginProtection) isFailure(status int, body []byte) bool {
+	if p.IsFailureStatus(status) {
+		return true
+	}
+	return p.failureRe != nil && p.failureRe.Match(body)
+}
+
+// slidingWindow 滑动窗口计数器，用当前窗口和上一个窗口按时间加权近似滑动窗口内的次数
+type slidingWindow struct {
+	window time.Duration
+	start  time.Time // 当前窗口开始时间
+	prev   int64     // 上一个窗口的次数
+	cur    int64     // 当前窗口的次数
+}
+
+// advance 将窗口推进到 now 所在的窗口
+func (w *slidingWindow) advance(now time.Time) {
+	elapsed := now.Sub(w.start)
+	if elapsed < w.window {
+		return
+	}
+	if elapsed < 2*w.window {
+		w.prev = w.cur
+	} else {
+		w.prev = 0
+	}
+	w.cur = 0
+	w.start = w.start.Add(elapsed / w.window * w.window)
+}
+
+// count 返回 now 之前一个窗口时长内的近似次数
+func (w *slidingWindow) count(now time.Time) int64 {
+	w.advance(now)
+	weight := float64(w.window-now.Sub(w.start)) / float64(w.window)
+	return w.cur + int64(float64(w.prev)*weight)
+}
+
+// loginGuard 按站点和维度统计登录失败次数
+type loginGuard struct {
+	mu        sync.Mutex
+	windows   map[string]*slidingWindow
+	lastSweep time.Time
+}
+
+func newLoginGuard() *loginGuard {
+	return &loginGuard{
+		windows:   make(map[string]*slidingWindow),
+		lastSweep: time.Now(),
+	}
+}
+
+// loginDimension 登录失败统计维度
+type loginDimension struct {
+	name      string // 维度名称
+	key       string // 计数器键，包含站点ID
+	threshold int64  // 失败次数阈值
+}
+
+// dimensions 返回登录请求参与统计的维度，值缺失或阈值为 0 的维度不参与统计
+func (attempt *loginAttempt) dimensions() []loginDimension {
+	p := attempt.protection
+	dims := make([]loginDimension, 0, 3)
+	add := func(name, value string, threshold int64) {
+		if value != "" && threshold > 0 {
+			dims = append(dims, loginDimension{name, p.siteID + "|" + name + "|" + value, threshold})
+		}
+	}
+
+	add(loginDimensionUsername, attempt.username, p.UsernameThreshold)
+	add(loginDimensionIP, attempt.ip, p.IPThreshold)
+	if attempt.asn != 0 {
+		add(loginDimensionASN, strconv.FormatUint(uint64(attempt.asn), 10), p.ASNThreshold)
+	}
+	return dims
+}
+
+// exceeded 返回第一个达到阈值的维度及其失败次数，均未达到时返回空字符串
+func (g *loginGuard) exceeded(attempt *loginAttempt, now time.Time) (string, int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, dim := range attempt.dimensions() {
+		w, ok := g.windows[dim.key]
+		if !ok {
+			continue
+		}
+		if count := w.count(now); count >= dim.threshold {
+			return dim.name, count
+		}
+	}
+	return "", 0
+}
+
+// recordFailure 记录一次登录失败
+func (g *loginGuard) recordFailure(attempt *loginAttempt, now time.Time) {
+	window := attempt.protection.WindowOrDefault()
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	for _, dim := range attempt.dimensions() {
+		w, ok := g.windows[dim.key]
+		if !ok || w.window != window {
+			w = &slidingWindow{window: window, start: now}
+			g.windows[dim.key] = w
+		}
+		w.advance(now)
+		w.cur++
+	}
+
+	if now.Sub(g.lastSweep) >= loginGuardSweepEvery {
+		g.sweep(now)
+	}
+}
+
+// resetUsername 登录成功后清除该用户名的失败次数，来源IP和ASN的统计保留
+func (g *loginGuard) resetUsername(attempt *loginAttempt) {
+	if attempt.username == "" {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	delete(g.windows, attempt.protection.siteID+"|"+loginDimensionUsername+"|"+attempt.username)
+}
+
+// sweep 删除两个窗口内没有失败记录的计数器，调用方需持有锁
+func (g *loginGuard) sweep(now time.Time) {
+	for key, w := range g.windows {
+		if now.Sub(w.start) >= 2*w.window {
+			delete(g.windows, key)
+		}
+	}
+	g.lastSweep = now
+}
+
+// extractLoginUsername 从登录请求体中提取用户名，支持 JSON 和表单格式，返回小写形式
+func extractLoginUsername(headers, body []byte, field string) string {
+	contentType, _ := getHeaderValue(headers, "content-type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+
+	var username string
+	if mediaType == "application/json" || strings.HasSuffix(mediaType, "+json") {
+		var data any
+		if err := json.Unmarshal(body, &data); err != nil {
+			return ""
+		}
+		for _, key := range strings.Split(field, ".") {
+			obj, ok := data.(map[string]any)
+			if !ok {
+				return ""
+			}
+			data = obj[key]
+		}
+		username, _ = data.(string)
+	} else {
+		values, err := url.ParseQuery(string(body))
+		if err != nil {
+			return ""
+		}
+		username = values.Get(field)
+	}
+
+	username = strings.ToLower(strings.TrimSpace(username))
+	if len(username) > maxLoginUsernameLen {
+		username = username[:maxLoginUsernameLen]
+	}
+	return username
+}
+
+// newLoginChallenge 生成与来源IP绑定的挑战令牌，格式为 过期时间.签名
+func newLoginChallenge(ip string, now time.Time) string {
+	expires := strconv.FormatInt(now.Add(loginChallengeTTL).Unix(), 10)
+	return expires + "." + signLoginChallenge(ip, expires)
+}
+
+// verifyLoginChallenge 校验挑战令牌是否由本进程签发、未过期且属于该来源IP
+func verifyLoginChallenge(token, ip string, now time.Time) bool {
+	expires, signature, ok := strings.Cut(token, ".")
+	if !ok {
+		return false
+	}
+	expiresAt, err := strconv.ParseInt(expires, 10, 64)
+	if err != nil || now.Unix() > expiresAt {
+		return false
+	}
+	return hmac.Equal([]byte(signature), []byte(signLoginChallenge(ip, expires)))
+}
+
+func signLoginChallenge(ip, expires string) string {
+	mac := hmac.New(sha256.New, loginChallengeSecret)
+	mac.Write([]byte(ip + "|" + expires))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// loginChallengePage 挑战页面，执行 JavaScript 写入挑战 Cookie 后返回登录页，不执行脚本的自动化工具无法通过
+func loginChallengePage(token string) string {
+	return fmt.Sprintf(`<!DOCTYPE html><html><head><meta charset="utf-8"><title>Verifying</title></head><body>`+
+		`<noscript>Please enable JavaScript and try again.</noscript>`+
+		`<script>document.cookie="%s=%s; path=/; max-age=%d; SameSite=Lax";if(history.length>1){history.back()}else{location.reload()}</script>`+
+		`</body></html>`, loginChallengeCookie, token, int(loginChallengeTTL.Seconds()))
+}
+
+// getCookieValue 从请求头中获取指定 Cookie 的值
+func getCookieValue(headers []byte, name string) string {
+	header, err := getHeaderValue(headers, "cookie")
+	if err != nil || header == "" {
+		return ""
+	}
+	cookies, err := http.ParseCookie(header)
+	if err != nil {
+		return ""
+	}
+	for _, cookie := range cookies {
+		if cookie.Name == name {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// newLoginAttempt 根据登录请求创建登录尝试记录
+func (a *Application) newLoginAttempt(protection *loginProtection, req *applicationRequest, realIP string) *loginAttempt {
+	attempt := &loginAttempt{
+		protection: protection,
+		username:   extractLoginUsername(req.Headers, req.Body, protection.UsernameField),
+		ip:         realIP,
+	}
+	if a.ipProcessor != nil && realIP != "" {
+		if info := a.ipProcessor.GetIPInfo(realIP); info != nil {
+			attempt.asn = info.ASN.Number
+		}
+	}
+	return attempt
+}
+
+// checkLoginAttempt 检查登录请求的失败次数是否达到阈值，达到时按配置的动作处置
+func (a *Application) checkLoginAttempt(attempt *loginAttempt, req *applicationRequest) error {
+	now := time.Now()
+	dimension, count := defaultLoginGuard.exceeded(attempt, now)
+	if dimension == "" {
+		return nil
+	}
+
+	protection := attempt.protection
+	requestURL := buildURLFromBytes(req.Path, req.Query)
+
+	var interruption *types.Interruption
+	switch protection.Action {
+	case model.LoginActionChallenge:
+		if verifyLoginChallenge(getCookieValue(req.Headers, loginChallengeCookie), attempt.ip, now) {
+			return nil
+		}
+		interruption = &types.Interruption{
+			Action: "challenge",
+			Status: 403,
+			Data:   loginChallengePage(newLoginChallenge(attempt.ip, now)),
+		}
+	case model.LoginActionBlock:
+		if a.ipRecorder != nil {
+			if err := a.ipRecorder.RecordBlockedIP(attempt.ip, model.BlockReasonCredentialStuffing, requestURL, protection.BlockDurationOrDefault()); err != nil {
+				a.Logger.Error().Err(err).Str("ip", attempt.ip).Msg("failed to record credential stuffing block")
+			}
+		}
+		interruption = &types.Interruption{
+			Action: "deny",
+			Status: 403,
+		}
+	default:
+		interruption = &types.Interruption{
+			Action: "deny",
+			Status: 429,
+			Data:   "Too many failed login attempts",
+		}
+	}
+
+	a.Logger.Info().
+		Str("action", string(protection.Action)).
+		Str("dimension", dimension).
+		Int64("failures", count).
+		Str("username", attempt.username).
+		Str("clientIP", attempt.ip).
+		Uint("asn", attempt.asn).
+		Str("url", requestURL).
+		Msg("login request blocked by credential stuffing protection")
+
+	if a.logStore != nil {
+		logMessage := fmt.Sprintf("login request blocked by credential stuffing protection, action: %s, dimension: %s, failures: %d, window: %s, username: %s, asn: %d",
+			protection.Action, dimension, count, protection.WindowOrDefault(), attempt.username, attempt.asn)
+		if err := a.saveSecurityEventLog(req, model.BlockReasonCredentialStuffing, logMessage, attempt.username); err != nil {
+			a.Logger.Error().Err(err).Str("clientIP", attempt.ip).Msg("failed to save credential stuffing log")
+		}
+	}
+
+	return ErrInterrupted{Interruption: interruption}
+}
+
+// recordLoginResult 根据响应判断登录是否失败并更新统计
+func (a *Application) recordLoginResult(attempt *loginAttempt, status int, body []byte) {
+	if attempt.protection.isFailure(status, body) {
+		defaultLoginGuard.recordFailure(attempt, time.Now())
+		return
+	}
+	defaultLoginGuard.resetUsername(attempt)
+}
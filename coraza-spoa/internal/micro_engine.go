@@ -5,213 +5,148 @@ import (
 	"encoding/json"
 	"fmt"
 	"net"
-	"regexp"
-	"sort"
 	"strings"
 	"time"
 
+	"github.com/HUAHUAI23/RuiQi/pkg/microrule"
 	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/rs/zerolog"
 	"go.mongodb.org/mongo-driver/v2/bson"
 	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
 )
 
-// 匹配方式
-type MatchType string
-
-const (
-	// IP匹配方式
-	MatchEqual        MatchType = "equal"
-	MatchNotEqual     MatchType = "not_equal"
-	MatchFuzzy        MatchType = "fuzzy"
-	MatchInCIDR       MatchType = "in_cidr"
-	MatchNotInCIDR    MatchType = "not_in_cidr"
-	MatchInIPGroup    MatchType = "in_ipgroup"
-	MatchNotInIPGroup MatchType = "not_in_ipgroup"
-
-	// URL和Path匹配方式
-	MatchInclude       MatchType = "include"
-	MatchContains      MatchType = "contains"
-	MatchNotContains   MatchType = "not_contains"
-	MatchPrefixKeyword MatchType = "prefix_keyword"
-	MatchRegex         MatchType = "regex"
-)
-
-// 匹配目标类型
-type TargetType string
-
-const (
-	SourceIP   TargetType = "source_ip"
-	TargetURL  TargetType = "url"
-	TargetPath TargetType = "path"
-)
-
-// 逻辑操作符
-type LogicalOperator string
-
-const (
-	LogicalAND LogicalOperator = "AND"
-	LogicalOR  LogicalOperator = "OR"
-)
+// Rule 规则
+type Rule struct {
+	// 嵌入MicroRule
+	model.MicroRule `bson:",inline" json:",inline"`
 
-// Matcher接口定义了条件匹配的方法
-type Matcher interface {
-	Match(eng *RuleEngine, ip, url, path string) (bool, error)
+	// 运行时字段，不用于JSON/BSON
+	parsedCondition microrule.Condition     `bson:"-" json:"-"`
+	scope           hostScope               `bson:"-" json:"-"`
+	schedule        *model.CompiledSchedule `bson:"-" json:"-"` // 已解析的生效计划，nil 表示始终生效
 }
 
-// 条件类型
-type ConditionType string
-
-const (
-	SimpleConditionType    ConditionType = "simple"
-	CompositeConditionType ConditionType = "composite"
-)
-
-// SimpleCondition 简单条件
-type SimpleCondition struct {
-	Type       ConditionType `json:"type" bson:"type"`
-	Target     TargetType    `json:"target" bson:"target"`
-	MatchType  MatchType     `json:"match_type" bson:"match_type"`
-	MatchValue string        `json:"match_value" bson:"match_value"`
+// hostScope 运行时站点作用域，站点ID已解析为域名
+type hostScope struct {
+	scoped   bool     // 是否限定了站点，false 表示对所有站点生效
+	patterns []string // 主机名模式
 }
 
-// Match 实现Matcher接口
-func (c *SimpleCondition) Match(eng *RuleEngine, ip, url, path string) (bool, error) {
-	switch c.Target {
-	case SourceIP:
-		return eng.matchIP(c, ip)
-	case TargetURL:
-		return eng.matchURL(c, url)
-	case TargetPath:
-		return eng.matchPath(c, path)
-	default:
-		return false, fmt.Errorf("不支持的目标类型: %s", c.Target)
+// matches 判断请求主机名是否在作用域内
+func (s hostScope) matches(host string) bool {
+	if !s.scoped {
+		return true
+	}
+	for _, pattern := range s.patterns {
+		if model.MatchHostPattern(pattern, host) {
+			return true
+		}
 	}
+	return false
 }
 
-// CompositeCondition 复合条件
-type CompositeCondition struct {
-	Type       ConditionType   `json:"type" bson:"type"`
-	Operator   LogicalOperator `json:"operator" bson:"operator"`
-	Conditions []bson.Raw      `json:"conditions" bson:"conditions"`
+// MongoDB配置
+type MongoDBConfig struct {
+	MongoClient       *mongo.Client
+	Database          string // 数据库名称
+	RuleCollection    string // 规则集合名称
+	IPGroupCollection string // IP组集合名称
+	SiteCollection    string // 站点集合名称，用于把作用域中的站点ID解析为域名
+	TrapCollection    string // 蜜罐陷阱路径集合名称
+}
 
-	// 运行时字段，不用于JSON/BSON
-	parsedConditions []Matcher
+// RuleEngine 规则引擎
+type RuleEngine struct {
+	Rules            []Rule                          `json:"rules"`     // 所有规则列表
+	IPGroups         map[string]*model.IPGroup       `json:"ip_groups"` // IP组映射表
+	ipGroupScopes    map[string]hostScope            // IP组作用域
+	ipGroupExpiry    map[string]map[string]time.Time // IP组名称 -> 条目 -> 过期时间
+	siteDomains      map[string][]string             // 站点ID -> 域名列表
+	trapPaths        []trapPath                      // 已启用的蜜罐陷阱路径
+	loginProtections map[string]*loginProtection     // 站点主机名模式 -> 登录保护配置
+	matcher          *microrule.Matcher              // 条件匹配器
+	logger           zerolog.Logger                  // 记录加载时跳过的规则
+	mongoConfig      *MongoDBConfig                  // MongoDB配置
 }
 
-// Match 实现Matcher接口
-func (c *CompositeCondition) Match(eng *RuleEngine, ip, url, path string) (bool, error) {
-	if len(c.parsedConditions) == 0 {
-		return false, fmt.Errorf("复合条件未初始化")
-	}
+// NewRuleEngine 创建规则引擎
+func NewRuleEngine(logger zerolog.Logger) *RuleEngine {
+	e := &RuleEngine{
+		Rules:            make([]Rule, 0),
+		IPGroups:         make(map[string]*model.IPGroup),
+		ipGroupScopes:    make(map[string]hostScope),
+		ipGroupExpiry:    make(map[string]map[string]time.Time),
+		siteDomains:      make(map[string][]string),
+		loginProtections: make(map[string]*loginProtection),
+		logger:           logger,
+	}
+	e.matcher = microrule.NewMatcher(e.isIPInGroup)
+	return e
+}
 
-	var result bool
-	if c.Operator == LogicalAND {
-		result = true
-	} else {
-		result = false
+// resolveScope 将站点作用域解析为运行时主机名模式
+// 已删除的站点ID会被忽略；若作用域非空但解析后没有任何模式，则对任何站点都不生效
+func (e *RuleEngine) resolveScope(scope *model.SiteScope) hostScope {
+	if scope.IsGlobal() {
+		return hostScope{}
 	}
 
-	for _, condition := range c.parsedConditions {
-		match, err := condition.Match(eng, ip, url, path)
-		if err != nil {
-			return false, err
-		}
-
-		if c.Operator == LogicalAND {
-			result = result && match
-			if !result {
-				return false, nil // 短路
-			}
-		} else {
-			result = result || match
-			if result {
-				return true, nil // 短路
-			}
-		}
+	patterns := make([]string, 0, len(scope.Hosts)+len(scope.SiteIDs))
+	patterns = append(patterns, scope.Hosts...)
+	for _, siteID := range scope.SiteIDs {
+		patterns = append(patterns, e.siteDomains[siteID]...)
 	}
 
-	return result, nil
+	return hostScope{scoped: true, patterns: patterns}
 }
 
-// ConditionFactory 条件工厂
-type ConditionFactory struct{}
-
-// ParseCondition 解析条件
-func (f *ConditionFactory) ParseCondition(data bson.Raw) (Matcher, error) {
-	var baseCondition struct {
-		Type ConditionType `json:"type" bson:"type"`
+// LoadSitesFromMongoDB 从MongoDB加载站点ID与域名的映射
+func (e *RuleEngine) LoadSitesFromMongoDB() error {
+	if e.mongoConfig.MongoClient == nil {
+		return fmt.Errorf("MongoDB客户端未初始化")
 	}
 
-	if err := bson.Unmarshal(data, &baseCondition); err != nil {
-		return nil, fmt.Errorf("解析条件类型失败: %v", err)
+	e.siteDomains = make(map[string][]string)
+	e.loginProtections = make(map[string]*loginProtection)
+	if e.mongoConfig.SiteCollection == "" {
+		return nil
 	}
 
-	switch baseCondition.Type {
-	case SimpleConditionType:
-		var condition SimpleCondition
-		if err := bson.Unmarshal(data, &condition); err != nil {
-			return nil, fmt.Errorf("解析简单条件失败: %v", err)
-		}
-		return &condition, nil
-
-	case CompositeConditionType:
-		var condition CompositeCondition
-		if err := bson.Unmarshal(data, &condition); err != nil {
-			return nil, fmt.Errorf("解析复合条件失败: %v", err)
-		}
-
-		condition.parsedConditions = make([]Matcher, 0, len(condition.Conditions))
-		for _, rawCondition := range condition.Conditions {
-			parsedCondition, err := f.ParseCondition(rawCondition)
-			if err != nil {
-				return nil, err
-			}
-			condition.parsedConditions = append(condition.parsedConditions, parsedCondition)
-		}
+	collection := e.mongoConfig.MongoClient.
+		Database(e.mongoConfig.Database).
+		Collection(e.mongoConfig.SiteCollection)
 
-		return &condition, nil
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
 
-	default:
-		return nil, fmt.Errorf("不支持的条件类型: %s", baseCondition.Type)
+	// 只查询解析作用域和登录保护需要的字段
+	projection := bson.D{{Key: "domain", Value: 1}, {Key: "aliases", Value: 1}, {Key: "loginProtection", Value: 1}}
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetProjection(projection))
+	if err != nil {
+		return fmt.Errorf("查询站点失败: %v", err)
 	}
-}
-
-// Rule 规则 - 添加优先级和序列号字段
-type Rule struct {
-	// 嵌入MicroRule
-	model.MicroRule `bson:",inline" json:",inline"`
-
-	// 运行时字段，不用于JSON/BSON
-	parsedCondition Matcher `bson:"-" json:"-"`
-	sequence        int     `bson:"-" json:"-"`
-}
-
-// MongoDB配置
-type MongoDBConfig struct {
-	MongoClient       *mongo.Client
-	Database          string // 数据库名称
-	RuleCollection    string // 规则集合名称
-	IPGroupCollection string // IP组集合名称
-}
+	defer cursor.Close(ctx)
 
-// RuleEngine 规则引擎
-type RuleEngine struct {
-	Rules       []Rule                    `json:"rules"`     // 所有规则列表
-	IPGroups    map[string]*model.IPGroup `json:"ip_groups"` // IP组映射表
-	regexCache  map[string]*regexp.Regexp // 正则表达式缓存
-	factory     ConditionFactory          // 条件工厂
-	mongoConfig *MongoDBConfig            // MongoDB配置
-}
+	var sites []struct {
+		ID              bson.ObjectID          `bson:"_id"`
+		Domain          string                 `bson:"domain"`
+		Aliases         []string               `bson:"aliases"`
+		LoginProtection *model.LoginProtection `bson:"loginProtection"`
+	}
+	if err = cursor.All(ctx, &sites); err != nil {
+		return fmt.Errorf("解码站点失败: %v", err)
+	}
 
-// NewRuleEngine 创建规则引擎
-func NewRuleEngine() *RuleEngine {
-	return &RuleEngine{
-		Rules:    make([]Rule, 0),
-		IPGroups: make(map[string]*model.IPGroup),
-		// TODO: 使用 LRU 优化，设置缓存过期时间，避免缓存过大
-		regexCache: make(map[string]*regexp.Regexp),
-		factory:    ConditionFactory{},
+	for _, site := range sites {
+		hostnames := append([]string{site.Domain}, site.Aliases...)
+		e.siteDomains[site.ID.Hex()] = append(e.siteDomains[site.ID.Hex()], hostnames...)
+		if err := e.addLoginProtection(site.ID.Hex(), hostnames, site.LoginProtection); err != nil {
+			return err
+		}
 	}
+
+	return nil
 }
 
 func (e *RuleEngine) InitMongoConfig(config *MongoDBConfig) error {
@@ -270,6 +205,8 @@ func (e *RuleEngine) LoadIPGroupsFromMongoDB() error {
 
 	// 初始化映射表
 	e.IPGroups = make(map[string]*model.IPGroup)
+	e.ipGroupScopes = make(map[string]hostScope)
+	e.ipGroupExpiry = make(map[string]map[string]time.Time)
 
 	// 填充IP组映射
 	for _, group := range ipGroups {
@@ -279,6 +216,8 @@ func (e *RuleEngine) LoadIPGroupsFromMongoDB() error {
 			}
 		}
 		e.IPGroups[group.Name] = &group
+		e.ipGroupScopes[group.Name] = e.resolveScope(group.Scope)
+		e.ipGroupExpiry[group.Name] = group.ExpiryMap()
 	}
 
 	return nil
@@ -308,10 +247,10 @@ func (e *RuleEngine) LoadRulesFromMongoDB() error {
 	// 如果默认规则不存在，则创建
 	if defaultRuleCount == 0 {
 		// 创建默认规则条件
-		defaultCondition := SimpleCondition{
-			Type:       "simple",
-			Target:     "source_ip",
-			MatchType:  "in_ipgroup",
+		defaultCondition := microrule.SimpleCondition{
+			Type:       microrule.SimpleConditionType,
+			Target:     microrule.SourceIP,
+			MatchType:  microrule.MatchInIPGroup,
 			MatchValue: "system_default_blacklist",
 		}
 
@@ -323,11 +262,12 @@ func (e *RuleEngine) LoadRulesFromMongoDB() error {
 
 		// 创建默认规则
 		defaultRule := model.MicroRule{
-			Name:      "system_default_ip_block",
-			Type:      "blacklist",
-			Status:    "enabled",
-			Priority:  9999, // 最高优先级
-			Condition: conditionBytes,
+			Name:        "system_default_ip_block",
+			Type:        "blacklist",
+			Status:      "enabled",
+			Priority:    9999, // 最高优先级
+			Condition:   conditionBytes,
+			IPGroupRefs: []string{defaultCondition.MatchValue},
 		}
 
 		// 插入到数据库
@@ -337,8 +277,8 @@ func (e *RuleEngine) LoadRulesFromMongoDB() error {
 		}
 	}
 
-	// 查询所有规则
-	cursor, err := collection.Find(ctx, bson.D{})
+	// 按匹配顺序查询所有规则，优先级相同的规则与 HAProxy 卸载和规则分析的顺序一致
+	cursor, err := collection.Find(ctx, bson.D{}, options.Find().SetSort(model.MicroRuleMatchOrder()))
 	if err != nil {
 		return fmt.Errorf("查询规则失败: %v", err)
 	}
@@ -350,39 +290,60 @@ func (e *RuleEngine) LoadRulesFromMongoDB() error {
 		return fmt.Errorf("解码规则失败: %v", err)
 	}
 
-	// 设置序列号
-	for i := range rules {
-		rules[i].sequence = i
+	// 解析每个规则的条件
+	rules, err = e.prepareRules(rules)
+	if err != nil {
+		return err
 	}
 
-	// 解析每个规则的条件
-	for i := range rules {
-		rule := &rules[i]
-		parsedCondition, err := e.factory.ParseCondition(rule.Condition)
+	sortRules(rules)
+
+	e.Rules = rules
+	return nil
+}
+
+// prepareRules 解析规则的条件、作用域和生效计划，任一规则的条件无效时返回错误
+// 生效计划无效的规则（如运行环境缺少该时区的数据）不加载，视同禁用，不影响其他规则
+func (e *RuleEngine) prepareRules(rules []Rule) ([]Rule, error) {
+	prepared := rules[:0]
+	for _, rule := range rules {
+		parsedCondition, err := microrule.ParseCondition(rule.Condition)
 		if err != nil {
-			return fmt.Errorf("解析规则 %s 的条件失败: %v", rule.ID, err)
+			return nil, fmt.Errorf("解析规则 %s 的条件失败: %v", rule.ID, err)
+		}
+		schedule, err := rule.Schedule.Compile()
+		if err != nil {
+			e.logger.Warn().Err(err).Str("rule_id", rule.ID.Hex()).Str("rule_name", rule.Name).Msg("规则的生效计划无效，跳过该规则")
+			continue
 		}
 		rule.parsedCondition = parsedCondition
+		rule.scope = e.resolveScope(rule.Scope)
+		rule.schedule = schedule
+		prepared = append(prepared, rule)
 	}
+	return prepared, nil
+}
 
-	// 按照优先级排序，优先级相同时按照原始顺序排序
-	sort.Slice(rules, func(i, j int) bool {
-		if rules[i].Priority != rules[j].Priority {
-			return rules[i].Priority > rules[j].Priority // 优先级高的排在前面
-		}
-		return rules[i].sequence < rules[j].sequence // 优先级相同时按原始顺序
-	})
-
-	e.Rules = rules
-	return nil
+// sortRules 按匹配顺序排序：优先级高的排在前面，优先级相同时按ID即创建顺序，没有ID时按原始顺序
+func sortRules(rules []Rule) {
+	microrule.SortRules(rules, func(rule *Rule) *model.MicroRule { return &rule.MicroRule })
 }
 
-// LoadAllFromMongoDB 从MongoDB加载所有规则和IP组
+// LoadAllFromMongoDB 从MongoDB加载所有规则、IP组和蜜罐陷阱路径
 func (e *RuleEngine) LoadAllFromMongoDB() error {
+	// 站点需要先于规则和IP组加载，用于解析作用域
+	if err := e.LoadSitesFromMongoDB(); err != nil {
+		return err
+	}
+
 	if err := e.LoadIPGroupsFromMongoDB(); err != nil {
 		return err
 	}
 
+	if err := e.LoadTrapPathsFromMongoDB(); err != nil {
+		return err
+	}
+
 	return e.LoadRulesFromMongoDB()
 }
 
@@ -399,10 +360,12 @@ func (e *RuleEngine) AddIPGroup(group model.IPGroup) error {
 	}
 
 	e.IPGroups[group.Name] = &group
+	e.ipGroupScopes[group.Name] = e.resolveScope(group.Scope)
+	e.ipGroupExpiry[group.Name] = group.ExpiryMap()
 	return nil
 }
 
-// LoadRulesFromJSON 从JSON加载规则 - 修改加载逻辑，增加序列号处理
+// LoadRulesFromJSON 从JSON加载规则，优先级和ID相同的规则保持原始顺序
 // BUG type transform error bson raw and json raw
 func (e *RuleEngine) LoadRulesFromJSON(data []byte) error {
 	var rules []Rule
@@ -410,28 +373,13 @@ func (e *RuleEngine) LoadRulesFromJSON(data []byte) error {
 		return err
 	}
 
-	// 设置序列号 - 记录规则在原始配置中的顺序
-	for i := range rules {
-		rules[i].sequence = i
-	}
-
 	// 解析每个规则的条件
-	for i := range rules {
-		rule := &rules[i]
-		parsedCondition, err := e.factory.ParseCondition(rule.Condition)
-		if err != nil {
-			return fmt.Errorf("解析规则 %s 的条件失败: %v", rule.ID, err)
-		}
-		rule.parsedCondition = parsedCondition
+	rules, err := e.prepareRules(rules)
+	if err != nil {
+		return err
 	}
 
-	// 按照优先级排序，优先级相同时按照原始顺序排序
-	sort.Slice(rules, func(i, j int) bool {
-		if rules[i].Priority != rules[j].Priority {
-			return rules[i].Priority > rules[j].Priority // 优先级高的排在前面
-		}
-		return rules[i].sequence < rules[j].sequence // 优先级相同时按原始顺序
-	})
+	sortRules(rules)
 
 	e.Rules = rules
 	return nil
@@ -440,31 +388,30 @@ func (e *RuleEngine) LoadRulesFromJSON(data []byte) error {
 // AddRule 添加单个规则
 func (e *RuleEngine) AddRule(rule Rule) error {
 	// 解析规则条件
-	parsedCondition, err := e.factory.ParseCondition(rule.Condition)
+	parsedCondition, err := microrule.ParseCondition(rule.Condition)
 	if err != nil {
 		return fmt.Errorf("解析规则 %s 的条件失败: %v", rule.ID, err)
 	}
+	schedule, err := rule.Schedule.Compile()
+	if err != nil {
+		return fmt.Errorf("解析规则 %s 的生效计划失败: %v", rule.ID, err)
+	}
 	rule.parsedCondition = parsedCondition
-
-	// 设置规则序列号为当前规则列表长度
-	rule.sequence = len(e.Rules)
+	rule.scope = e.resolveScope(rule.Scope)
+	rule.schedule = schedule
 
 	// 添加规则到列表
 	e.Rules = append(e.Rules, rule)
 
 	// 重新排序规则
-	sort.Slice(e.Rules, func(i, j int) bool {
-		if e.Rules[i].Priority != e.Rules[j].Priority {
-			return e.Rules[i].Priority > e.Rules[j].Priority
-		}
-		return e.Rules[i].sequence < e.Rules[j].sequence
-	})
+	sortRules(e.Rules)
 
 	return nil
 }
 
 // MatchRequest 匹配请求
 // 参数：
+// - host: 请求主机名，只评估作用域包含该主机的规则
 // - ip: 源IP地址
 // - url: 请求URL
 // - path: 请求路径
@@ -473,57 +420,22 @@ func (e *RuleEngine) AddRule(rule Rule) error {
 // - ruleType: 匹配的规则类型
 // - rule: 匹配的规则
 // - error: 错误信息
-func (e *RuleEngine) MatchRequest(ip string, url string, path string) (shouldBlock bool, ruleType model.RuleType, rule *Rule, err error) {
+func (e *RuleEngine) MatchRequest(host string, ip string, url string, path string) (shouldBlock bool, ruleType model.RuleType, rule *Rule, err error) {
 	// 验证IP地址格式
 	if !isValidIP(ip) {
 		return false, "", nil, fmt.Errorf("无效的IP地址: %s", ip)
 	}
 
-	// 标记是否存在启用的白名单规则
-	hasWhitelistRule := false
-
-	// 遍历所有规则（已按优先级和序列号排序）
-	for _, r := range e.Rules {
-		// 检查是否存在启用的白名单规则
-		if r.Status == model.RuleEnabled && r.Type == model.WhitelistRule {
-			hasWhitelistRule = true
-		}
-
-		// 跳过禁用的规则
-		if r.Status == model.RuleDisabled {
-			continue
-		}
-
-		// 匹配规则条件
-		match, err := r.parsedCondition.Match(e, ip, url, path)
-		if err != nil {
-			return false, "", nil, err
-		}
-
-		// 如果规则条件匹配
-		if match {
-			// 根据规则类型确定是否需要拦截
-			switch r.Type {
-			case model.BlacklistRule:
-				// 黑名单规则匹配成功 -> 返回true(拦截)
-				return true, r.Type, &r, nil
-			case model.WhitelistRule:
-				// 白名单规则匹配成功 -> 返回false(放行)
-				return false, r.Type, &r, nil
-			default:
-				return false, "", nil, fmt.Errorf("未知的规则类型: %s", r.Type)
-			}
-		}
-	}
-
-	// 如果没有匹配任何规则，且存在白名单规则，则拦截
-	if hasWhitelistRule {
-		// 存在白名单规则但未匹配任何规则 -> 拦截请求（安全默认值）
-		return true, "", nil, nil
+	// 跳过不作用于当前站点或不在生效时间内的规则，白名单兜底拦截也按站点计算
+	now := time.Now()
+	shouldBlock, matched, err := microrule.MatchRules(e.Rules, func(r *Rule) (*model.MicroRule, microrule.Condition, bool) {
+		return &r.MicroRule, r.parsedCondition, r.scope.matches(host) && r.schedule.ActiveAt(now)
+	}, e.matcher, microrule.Request{Host: host, IP: ip, URL: url, Path: path})
+	if err != nil || matched == nil {
+		return shouldBlock, "", nil, err
 	}
-
-	// 如果没有匹配任何规则，且不存在白名单规则，则默认放行
-	return false, "", nil, nil
+	matchedRule := *matched
+	return shouldBlock, matchedRule.Type, &matchedRule, nil
 }
 
 // GetRules 获取当前规则列表
@@ -531,55 +443,6 @@ func (e *RuleEngine) GetRules() []Rule {
 	return e.Rules
 }
 
-// matchIP 匹配IP条件
-func (e *RuleEngine) matchIP(cond *SimpleCondition, ip string) (bool, error) {
-	switch cond.MatchType {
-	case MatchEqual:
-		return ip == cond.MatchValue, nil
-	case MatchNotEqual:
-		return ip != cond.MatchValue, nil
-	case MatchFuzzy:
-		return matchIPFuzzy(ip, cond.MatchValue)
-	case MatchInCIDR:
-		return isIPInCIDR(ip, cond.MatchValue)
-	case MatchNotInCIDR:
-		inCIDR, err := isIPInCIDR(ip, cond.MatchValue)
-		return !inCIDR, err
-	case MatchInIPGroup:
-		return e.isIPInGroup(ip, cond.MatchValue)
-	case MatchNotInIPGroup:
-		inGroup, err := e.isIPInGroup(ip, cond.MatchValue)
-		return !inGroup, err
-	default:
-		return false, fmt.Errorf("IP不支持匹配方式: %s", cond.MatchType)
-	}
-}
-
-// matchURL 匹配URL条件
-func (e *RuleEngine) matchURL(cond *SimpleCondition, url string) (bool, error) {
-	switch cond.MatchType {
-	case MatchEqual:
-		return url == cond.MatchValue, nil
-	case MatchNotEqual:
-		return url != cond.MatchValue, nil
-	case MatchInclude, MatchContains:
-		return strings.Contains(url, cond.MatchValue), nil
-	case MatchNotContains:
-		return !strings.Contains(url, cond.MatchValue), nil
-	case MatchPrefixKeyword:
-		return strings.HasPrefix(url, cond.MatchValue), nil
-	case MatchRegex:
-		return e.matchRegex(url, cond.MatchValue)
-	default:
-		return false, fmt.Errorf("URL不支持匹配方式: %s", cond.MatchType)
-	}
-}
-
-// matchPath 匹配Path条件
-func (e *RuleEngine) matchPath(cond *SimpleCondition, path string) (bool, error) {
-	return e.matchURL(cond, path)
-}
-
 // 以下是辅助函数
 
 // isValidIP 检查IP是否有效
@@ -625,79 +488,16 @@ func isValidIPPattern(pattern string) bool {
 	return true
 }
 
-// isIPInCIDR 检查IP是否在CIDR范围内
-func isIPInCIDR(ipStr, cidrStr string) (bool, error) {
-	ip := net.ParseIP(ipStr)
-	if ip == nil {
-		return false, fmt.Errorf("无效的IP地址: %s", ipStr)
-	}
-
-	_, ipNet, err := net.ParseCIDR(cidrStr)
-	if err != nil {
-		return false, fmt.Errorf("无效的CIDR: %s", cidrStr)
-	}
-
-	return ipNet.Contains(ip), nil
-}
-
-// matchIPFuzzy 模糊匹配IP
-func matchIPFuzzy(ip, pattern string) (bool, error) {
-	ipParts := strings.Split(ip, ".")
-	patternParts := strings.Split(pattern, ".")
-
-	if len(ipParts) != 4 || len(patternParts) != 4 {
-		return false, fmt.Errorf("IP格式错误")
-	}
-
-	for i := 0; i < 4; i++ {
-		if patternParts[i] != "*" && ipParts[i] != patternParts[i] {
-			return false, nil
-		}
-	}
-
-	return true, nil
-}
-
-// isIPInGroup 检查IP是否在IP组中
-// TODO: 避免使用线性遍历 O(N)，使用 基数树 (Radix Tree/Patricia Trie) 优化
-func (e *RuleEngine) isIPInGroup(ip, groupName string) (bool, error) {
+// isIPInGroup 检查IP是否在IP组中，IP组不作用于当前站点时视为空组，已过期的条目会被忽略
+func (e *RuleEngine) isIPInGroup(host, ip, groupName string) (bool, error) {
 	group, exists := e.IPGroups[groupName]
 	if !exists {
 		return false, fmt.Errorf("IP组不存在: %s", groupName)
 	}
 
-	for _, item := range group.Items {
-		if isValidIP(item) {
-			if ip == item {
-				return true, nil
-			}
-		} else if isValidCIDR(item) {
-			inCIDR, err := isIPInCIDR(ip, item)
-			if err != nil {
-				return false, err
-			}
-			if inCIDR {
-				return true, nil
-			}
-		} else {
-			return false, fmt.Errorf("IP组 %s 包含无效项: %s", groupName, item)
-		}
-	}
-
-	return false, nil
-}
-
-// matchRegex 正则表达式匹配
-func (e *RuleEngine) matchRegex(s, pattern string) (bool, error) {
-	re, exists := e.regexCache[pattern]
-	if !exists {
-		var err error
-		re, err = regexp.Compile(pattern)
-		if err != nil {
-			return false, fmt.Errorf("无效的正则表达式: %s", pattern)
-		}
-		e.regexCache[pattern] = re
+	if !e.ipGroupScopes[groupName].matches(host) {
+		return false, nil
 	}
 
-	return re.MatchString(s), nil
+	return microrule.GroupContains(group, e.ipGroupExpiry[groupName], ip, time.Now())
 }
@@ -0,0 +1,275 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/corazawaf/coraza/v3/types"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// ruleStatsKey 规则计数器键，bucket 为命中时所在小时的 Unix 秒
+type ruleStatsKey struct {
+	source model.RuleStatsSource
+	ruleID string
+	bucket int64
+}
+
+// ruleStatsWriter 批量写入统计增量，由 *mongo.Collection 实现
+type ruleStatsWriter interface {
+	BulkWrite(ctx context.Context, models []mongo.WriteModel, opts ...options.Lister[options.BulkWriteOptions]) (*mongo.BulkWriteResult, error)
+}
+
+// ruleCounter 单条规则的计数器，所有字段均为原子操作
+type ruleCounter struct {
+	hits      atomic.Int64
+	blocks    atomic.Int64
+	lastHitAt atomic.Int64 // UnixNano
+	ruleName  atomic.Value // string
+}
+
+// RuleStatsCollector 规则命中统计收集器
+// 热路径只做 sync.Map 读取和原子加法，后台协程定期把增量刷新到 rule_stats 集合
+type RuleStatsCollector struct {
+	collection    ruleStatsWriter
+	counters      sync.Map // ruleStatsKey -> *ruleCounter
+	flushInterval time.Duration
+	logger        zerolog.Logger
+	state         atomic.Uint32 // 0: stopped, 1: running, 2: closing
+	stopCh        chan struct{}
+	wg            sync.WaitGroup
+}
+
+// 单例实例，应用热替换时计数器不丢失
+var (
+	ruleStatsCollectorOnce     sync.Once
+	ruleStatsCollectorInstance *RuleStatsCollector
+)
+
+// NewRuleStatsCollector 创建规则命中统计收集器（单例模式）
+func NewRuleStatsCollector(client *mongo.Client, database string, flushInterval time.Duration, logger zerolog.Logger) *RuleStatsCollector {
+	ruleStatsCollectorOnce.Do(func() {
+		if flushInterval <= 0 {
+			flushInterval = 10 * time.Second
+		}
+
+		var ruleStats model.RuleStats
+		ruleStatsCollectorInstance = &RuleStatsCollector{
+			collection:    client.Database(database).Collection(ruleStats.GetCollectionName()),
+			flushInterval: flushInterval,
+			logger:        logger,
+		}
+		logger.Info().Dur("flush_interval", flushInterval).Msg("创建规则命中统计收集器")
+	})
+
+	return ruleStatsCollectorInstance
+}
+
+// counter 获取或创建规则在 now 所在小时的计数器
+func (c *RuleStatsCollector) counter(source model.RuleStatsSource, ruleID string, now time.Time) *ruleCounter {
+	key := ruleStatsKey{source: source, ruleID: ruleID, bucket: now.Truncate(time.Hour).Unix()}
+	if v, ok := c.counters.Load(key); ok {
+		return v.(*ruleCounter)
+	}
+	v, _ := c.counters.LoadOrStore(key, &ruleCounter{})
+	return v.(*ruleCounter)
+}
+
+// RecordMicroRule 记录微规则命中
+func (c *RuleStatsCollector) RecordMicroRule(rule *Rule, blocked bool) {
+	c.recordMicroRule(rule, blocked, time.Now())
+}
+
+// recordMicroRule 记录微规则在 now 时的命中
+func (c *RuleStatsCollector) recordMicroRule(rule *Rule, blocked bool, now time.Time) {
+	if c == nil || rule == nil {
+		return
+	}
+	counter := c.counter(model.RuleStatsSourceMicro, rule.ID.Hex(), now)
+	counter.hits.Add(1)
+	if blocked {
+		counter.blocks.Add(1)
+	}
+	counter.lastHitAt.Store(now.UnixNano())
+	if name, _ := counter.ruleName.Load().(string); name != rule.Name {
+		counter.ruleName.Store(rule.Name)
+	}
+}
+
+// RecordCorazaRules 记录一次事务中 Coraza 规则的命中，触发中断的规则计为拦截
+func (c *RuleStatsCollector) RecordCorazaRules(matchedRules []types.MatchedRule, interruption *types.Interruption) {
+	c.recordCorazaRules(matchedRules, interruption, time.Now())
+}
+
+// recordCorazaRules 记录一次事务中 Coraza 规则在 now 时的命中
+func (c *RuleStatsCollector) recordCorazaRules(matchedRules []types.MatchedRule, interruption *types.Interruption, now time.Time) {
+	if c == nil || len(matchedRules) == 0 {
+		return
+	}
+	for _, matchedRule := range matchedRules {
+		id := matchedRule.Rule().ID()
+		if id == 0 {
+			continue
+		}
+		counter := c.counter(model.RuleStatsSourceCoraza, strconv.Itoa(id), now)
+		counter.hits.Add(1)
+		if interruption != nil && interruption.RuleID == id {
+			counter.blocks.Add(1)
+		}
+		counter.lastHitAt.Store(now.UnixNano())
+	}
+}
+
+// Start 启动后台刷新协程
+func (c *RuleStatsCollector) Start() {
+	if !c.state.CompareAndSwap(0, 1) {
+		return
+	}
+	c.stopCh = make(chan struct{})
+
+	c.wg.Add(1)
+	go func() {
+		defer c.wg.Done()
+		ticker := time.NewTicker(c.flushInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ticker.C:
+				c.flush(time.Now())
+			case <-c.stopCh:
+				c.flush(time.Now())
+				return
+			}
+		}
+	}()
+}
+
+// Close 停止刷新协程并写入剩余增量
+func (c *RuleStatsCollector) Close() {
+	if !c.state.CompareAndSwap(1, 2) {
+		return
+	}
+	close(c.stopCh)
+	c.wg.Wait()
+	c.state.Store(0)
+}
+
+// ruleStatsDelta 一次刷新中写入的单个计数器增量，写入失败时加回计数器
+type ruleStatsDelta struct {
+	key       ruleStatsKey
+	hits      int64
+	blocks    int64
+	lastHitAt int64
+	ruleName  string
+}
+
+// flush 把自上次刷新以来的增量写入命中时所在小时的统计桶，写入失败的增量加回计数器，下次刷新时重试
+func (c *RuleStatsCollector) flush(now time.Time) {
+	current := now.Truncate(time.Hour).Unix()
+	models := make([]mongo.WriteModel, 0)
+	deltas := make([]ruleStatsDelta, 0)
+
+	c.counters.Range(func(k, v any) bool {
+		key := k.(ruleStatsKey)
+		counter := v.(*ruleCounter)
+
+		hits := counter.hits.Swap(0)
+		blocks := counter.blocks.Swap(0)
+		if hits == 0 && blocks == 0 {
+			// 已结束的小时桶在一个刷新周期内没有新增量时删除，避免计数器无限增长
+			if key.bucket < current && c.counters.CompareAndDelete(k, v) {
+				// 删除前取得计数器的命中可能在删除后才写入，转移到新的计数器
+				c.restore(ruleStatsDelta{key: key, hits: counter.hits.Swap(0), blocks: counter.blocks.Swap(0), lastHitAt: counter.lastHitAt.Load()})
+			}
+			return true
+		}
+
+		delta := ruleStatsDelta{key: key, hits: hits, blocks: blocks, lastHitAt: counter.lastHitAt.Load()}
+		delta.ruleName, _ = counter.ruleName.Load().(string)
+		deltas = append(deltas, delta)
+		models = append(models, delta.writeModel())
+		return true
+	})
+
+	if len(models) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	_, err := c.collection.BulkWrite(ctx, models, options.BulkWrite().SetOrdered(false))
+	if err == nil {
+		return
+	}
+
+	// 只有部分写入失败时只加回失败的增量，其他错误无法确定写入结果，全部加回
+	failed := deltas
+	var bulkErr mongo.BulkWriteException
+	if errors.As(err, &bulkErr) && bulkErr.WriteConcernError == nil && len(bulkErr.WriteErrors) > 0 {
+		failed = make([]ruleStatsDelta, 0, len(bulkErr.WriteErrors))
+		for _, writeErr := range bulkErr.WriteErrors {
+			if writeErr.Index >= 0 && writeErr.Index < len(deltas) {
+				failed = append(failed, deltas[writeErr.Index])
+			}
+		}
+	}
+	for _, delta := range failed {
+		c.restore(delta)
+	}
+	c.logger.Error().Err(err).Int("count", len(models)).Int("restored", len(failed)).Msg("刷新规则命中统计失败")
+}
+
+// restore 把未写入的增量加回计数器
+func (c *RuleStatsCollector) restore(delta ruleStatsDelta) {
+	if delta.hits == 0 && delta.blocks == 0 {
+		return
+	}
+	v, _ := c.counters.LoadOrStore(delta.key, &ruleCounter{})
+	counter := v.(*ruleCounter)
+	counter.hits.Add(delta.hits)
+	counter.blocks.Add(delta.blocks)
+	for {
+		last := counter.lastHitAt.Load()
+		if last >= delta.lastHitAt || counter.lastHitAt.CompareAndSwap(last, delta.lastHitAt) {
+			break
+		}
+	}
+	if delta.ruleName != "" {
+		counter.ruleName.CompareAndSwap(nil, delta.ruleName)
+	}
+}
+
+// writeModel 生成把增量累加到统计桶的 upsert 操作
+func (d ruleStatsDelta) writeModel() mongo.WriteModel {
+	update := bson.D{
+		{Key: "$inc", Value: bson.D{
+			{Key: "hits", Value: d.hits},
+			{Key: "blocks", Value: d.blocks},
+		}},
+		{Key: "$max", Value: bson.D{
+			{Key: "last_hit_at", Value: time.Unix(0, d.lastHitAt)},
+		}},
+	}
+	if d.ruleName != "" {
+		update = append(update, bson.E{Key: "$set", Value: bson.D{{Key: "rule_name", Value: d.ruleName}}})
+	}
+
+	return mongo.NewUpdateOneModel().
+		SetFilter(bson.D{
+			{Key: "source", Value: d.key.source},
+			{Key: "rule_id", Value: d.key.ruleID},
+			{Key: "bucket", Value: time.Unix(d.key.bucket, 0)},
+		}).
+		SetUpdate(update).
+		SetUpsert(true)
+}
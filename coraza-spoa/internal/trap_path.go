@@ -0,0 +1,70 @@
+package internal
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// trapPath 运行时蜜罐陷阱路径
+type trapPath struct {
+	*model.TrapPath
+	scope hostScope // 解析后的站点作用域
+}
+
+// LoadTrapPathsFromMongoDB 从MongoDB加载已启用的蜜罐陷阱路径
+func (e *RuleEngine) LoadTrapPathsFromMongoDB() error {
+	if e.mongoConfig.MongoClient == nil {
+		return fmt.Errorf("MongoDB客户端未初始化")
+	}
+
+	e.trapPaths = nil
+	if e.mongoConfig.TrapCollection == "" {
+		return nil
+	}
+
+	collection := e.mongoConfig.MongoClient.
+		Database(e.mongoConfig.Database).
+		Collection(e.mongoConfig.TrapCollection)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	cursor, err := collection.Find(ctx, bson.D{{Key: "enabled", Value: true}})
+	if err != nil {
+		return fmt.Errorf("查询蜜罐陷阱路径失败: %v", err)
+	}
+	defer cursor.Close(ctx)
+
+	var traps []model.TrapPath
+	if err = cursor.All(ctx, &traps); err != nil {
+		return fmt.Errorf("解码蜜罐陷阱路径失败: %v", err)
+	}
+
+	for i := range traps {
+		e.AddTrapPath(&traps[i])
+	}
+
+	return nil
+}
+
+// AddTrapPath 添加蜜罐陷阱路径
+func (e *RuleEngine) AddTrapPath(trap *model.TrapPath) {
+	e.trapPaths = append(e.trapPaths, trapPath{
+		TrapPath: trap,
+		scope:    e.resolveScope(trap.Scope),
+	})
+}
+
+// MatchTrapPath 返回请求命中的第一个蜜罐陷阱路径，未命中时返回 nil
+func (e *RuleEngine) MatchTrapPath(host, path string) *model.TrapPath {
+	for _, trap := range e.trapPaths {
+		if trap.Enabled && trap.scope.matches(host) && trap.MatchPath(path) {
+			return trap.TrapPath
+		}
+	}
+	return nil
+}
@@ -107,12 +107,15 @@ func (s *AgentServerImpl) Start() error {
 
 	var microRule model.MicroRule
 	var ipGroup model.IPGroup
+	var trapPath model.TrapPath
 
 	ruleEngineMongoConfig := &internal.MongoDBConfig{
 		MongoClient:       mongoClient,
 		Database:          "waf",
 		RuleCollection:    microRule.GetCollectionName(),
 		IPGroupCollection: ipGroup.GetCollectionName(),
+		SiteCollection:    "site", // 站点模型定义在 server 模块中
+		TrapCollection:    trapPath.GetCollectionName(),
 	}
 
 	flowControllerConfig := internal.FlowControllerConfig{
@@ -120,6 +123,11 @@ func (s *AgentServerImpl) Start() error {
 		Database: "waf",
 	}
 
+	ruleStatsConfig := internal.RuleStatsConfig{
+		Client:   mongoClient,
+		Database: "waf",
+	}
+
 	geoIPConfig := internal.GeoIP2Options{
 		ASNDBPath:  globalConfig.Engine.ASNDBPath,
 		CityDBPath: globalConfig.Engine.CityDBPath,
@@ -158,6 +166,7 @@ func (s *AgentServerImpl) Start() error {
 			GeoIPConfig:          &geoIPConfig,
 			RuleEngineDbConfig:   ruleEngineMongoConfig,
 			FlowControllerConfig: &flowControllerConfig,
+			RuleStatsConfig:      &ruleStatsConfig,
 		}, globalConfig.IsDebug)
 		if err != nil {
 			s.logger.Fatal().Err(err).Msg("Failed creating application: " + appConfig.Name)
@@ -278,12 +287,15 @@ func (s *AgentServerImpl) UpdateApplications() error {
 
 	var microRule model.MicroRule
 	var ipGroup model.IPGroup
+	var trapPath model.TrapPath
 
 	ruleEngineMongoConfig := &internal.MongoDBConfig{
 		MongoClient:       mongoClient,
 		Database:          "waf",
 		RuleCollection:    microRule.GetCollectionName(),
 		IPGroupCollection: ipGroup.GetCollectionName(),
+		SiteCollection:    "site", // 站点模型定义在 server 模块中
+		TrapCollection:    trapPath.GetCollectionName(),
 	}
 
 	flowControllerConfig := internal.FlowControllerConfig{
@@ -291,6 +303,11 @@ func (s *AgentServerImpl) UpdateApplications() error {
 		Database: "waf",
 	}
 
+	ruleStatsConfig := internal.RuleStatsConfig{
+		Client:   mongoClient,
+		Database: "waf",
+	}
+
 	geoIPConfig := internal.GeoIP2Options{
 		ASNDBPath:  globalConfig.Engine.ASNDBPath,
 		CityDBPath: globalConfig.Engine.CityDBPath,
@@ -330,6 +347,7 @@ func (s *AgentServerImpl) UpdateApplications() error {
 			GeoIPConfig:          &geoIPConfig,
 			RuleEngineDbConfig:   ruleEngineMongoConfig,
 			FlowControllerConfig: &flowControllerConfig,
+			RuleStatsConfig:      &ruleStatsConfig,
 		}, globalConfig.IsDebug)
 
 		if err != nil {
@@ -0,0 +1,305 @@
+// Package microrule 解析和匹配微规则的条件，WAF 引擎与 HAProxy 卸载规则的测试使用同一个实现
+package microrule
+
+import (
+	"fmt"
+	"net"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// 匹配方式
+type MatchType string
+
+const (
+	// IP匹配方式
+	MatchEqual        MatchType = "equal"
+	MatchNotEqual     MatchType = "not_equal"
+	MatchFuzzy        MatchType = "fuzzy"
+	MatchInCIDR       MatchType = "in_cidr"
+	MatchNotInCIDR    MatchType = "not_in_cidr"
+	MatchInIPGroup    MatchType = model.MatchTypeInIPGroup
+	MatchNotInIPGroup MatchType = model.MatchTypeNotInIPGroup
+
+	// URL和Path匹配方式
+	MatchInclude       MatchType = "include"
+	MatchContains      MatchType = "contains"
+	MatchNotContains   MatchType = "not_contains"
+	MatchPrefixKeyword MatchType = "prefix_keyword"
+	MatchRegex         MatchType = "regex"
+)
+
+// 匹配目标类型
+type TargetType string
+
+const (
+	SourceIP   TargetType = "source_ip"
+	TargetURL  TargetType = "url"
+	TargetPath TargetType = "path"
+)
+
+// 逻辑操作符
+type LogicalOperator string
+
+const (
+	LogicalAND LogicalOperator = "AND"
+	LogicalOR  LogicalOperator = "OR"
+)
+
+// 条件类型
+type ConditionType string
+
+const (
+	SimpleConditionType    ConditionType = model.ConditionTypeSimple
+	CompositeConditionType ConditionType = model.ConditionTypeComposite
+)
+
+// Request 匹配条件使用的请求信息
+type Request struct {
+	Host string // 请求主机名
+	IP   string // 源IP地址
+	URL  string // 请求URL，包含查询字符串
+	Path string // 请求路径
+}
+
+// IPGroupFunc 判断IP是否在IP组中，IP组的作用域由调用方处理，IP组不存在时返回错误
+type IPGroupFunc func(host, ip, group string) (bool, error)
+
+// Matcher 计算条件，缓存编译后的正则表达式
+type Matcher struct {
+	inIPGroup  IPGroupFunc
+	regexCache map[string]*regexp.Regexp
+}
+
+// NewMatcher 创建条件匹配器，inIPGroup 用于匹配IP组条件
+func NewMatcher(inIPGroup IPGroupFunc) *Matcher {
+	return &Matcher{
+		inIPGroup: inIPGroup,
+		// TODO: 使用 LRU 优化，设置缓存过期时间，避免缓存过大
+		regexCache: make(map[string]*regexp.Regexp),
+	}
+}
+
+// Condition 解析后的条件
+type Condition interface {
+	Match(m *Matcher, req Request) (bool, error)
+}
+
+// SimpleCondition 简单条件
+type SimpleCondition struct {
+	Type       ConditionType `json:"type" bson:"type"`
+	Target     TargetType    `json:"target" bson:"target"`
+	MatchType  MatchType     `json:"match_type" bson:"match_type"`
+	MatchValue string        `json:"match_value" bson:"match_value"`
+}
+
+// Match 实现Condition接口
+func (c *SimpleCondition) Match(m *Matcher, req Request) (bool, error) {
+	switch c.Target {
+	case SourceIP:
+		return m.matchIP(c, req.Host, req.IP)
+	case TargetURL:
+		return m.matchString(c, req.URL)
+	case TargetPath:
+		return m.matchString(c, req.Path)
+	default:
+		return false, fmt.Errorf("不支持的目标类型: %s", c.Target)
+	}
+}
+
+// CompositeCondition 复合条件
+type CompositeCondition struct {
+	Type       ConditionType   `json:"type" bson:"type"`
+	Operator   LogicalOperator `json:"operator" bson:"operator"`
+	Conditions []bson.Raw      `json:"conditions" bson:"conditions"`
+
+	// 运行时字段，不用于JSON/BSON
+	parsedConditions []Condition
+}
+
+// Match 实现Condition接口，AND 和 OR 都按顺序短路计算
+func (c *CompositeCondition) Match(m *Matcher, req Request) (bool, error) {
+	if len(c.parsedConditions) == 0 {
+		return false, fmt.Errorf("复合条件未初始化")
+	}
+
+	for _, condition := range c.parsedConditions {
+		match, err := condition.Match(m, req)
+		if err != nil {
+			return false, err
+		}
+		if c.Operator == LogicalAND && !match {
+			return false, nil
+		}
+		if c.Operator != LogicalAND && match {
+			return true, nil
+		}
+	}
+
+	return c.Operator == LogicalAND, nil
+}
+
+// ParseCondition 解析条件
+func ParseCondition(data bson.Raw) (Condition, error) {
+	var baseCondition struct {
+		Type ConditionType `json:"type" bson:"type"`
+	}
+
+	if err := bson.Unmarshal(data, &baseCondition); err != nil {
+		return nil, fmt.Errorf("解析条件类型失败: %v", err)
+	}
+
+	switch baseCondition.Type {
+	case SimpleConditionType:
+		var condition SimpleCondition
+		if err := bson.Unmarshal(data, &condition); err != nil {
+			return nil, fmt.Errorf("解析简单条件失败: %v", err)
+		}
+		return &condition, nil
+
+	case CompositeConditionType:
+		var condition CompositeCondition
+		if err := bson.Unmarshal(data, &condition); err != nil {
+			return nil, fmt.Errorf("解析复合条件失败: %v", err)
+		}
+
+		condition.parsedConditions = make([]Condition, 0, len(condition.Conditions))
+		for _, rawCondition := range condition.Conditions {
+			parsedCondition, err := ParseCondition(rawCondition)
+			if err != nil {
+				return nil, err
+			}
+			condition.parsedConditions = append(condition.parsedConditions, parsedCondition)
+		}
+
+		return &condition, nil
+
+	default:
+		return nil, fmt.Errorf("不支持的条件类型: %s", baseCondition.Type)
+	}
+}
+
+// matchIP 匹配IP条件
+func (m *Matcher) matchIP(cond *SimpleCondition, host, ip string) (bool, error) {
+	switch cond.MatchType {
+	case MatchEqual:
+		return ip == cond.MatchValue, nil
+	case MatchNotEqual:
+		return ip != cond.MatchValue, nil
+	case MatchFuzzy:
+		return matchIPFuzzy(ip, cond.MatchValue)
+	case MatchInCIDR:
+		return isIPInCIDR(ip, cond.MatchValue)
+	case MatchNotInCIDR:
+		inCIDR, err := isIPInCIDR(ip, cond.MatchValue)
+		return !inCIDR, err
+	case MatchInIPGroup:
+		return m.inIPGroup(host, ip, cond.MatchValue)
+	case MatchNotInIPGroup:
+		inGroup, err := m.inIPGroup(host, ip, cond.MatchValue)
+		return !inGroup, err
+	default:
+		return false, fmt.Errorf("IP不支持匹配方式: %s", cond.MatchType)
+	}
+}
+
+// matchString 匹配URL和Path条件
+func (m *Matcher) matchString(cond *SimpleCondition, s string) (bool, error) {
+	switch cond.MatchType {
+	case MatchEqual:
+		return s == cond.MatchValue, nil
+	case MatchNotEqual:
+		return s != cond.MatchValue, nil
+	case MatchInclude, MatchContains:
+		return strings.Contains(s, cond.MatchValue), nil
+	case MatchNotContains:
+		return !strings.Contains(s, cond.MatchValue), nil
+	case MatchPrefixKeyword:
+		return strings.HasPrefix(s, cond.MatchValue), nil
+	case MatchRegex:
+		return m.matchRegex(s, cond.MatchValue)
+	default:
+		return false, fmt.Errorf("URL不支持匹配方式: %s", cond.MatchType)
+	}
+}
+
+// matchRegex 正则表达式匹配
+func (m *Matcher) matchRegex(s, pattern string) (bool, error) {
+	re, exists := m.regexCache[pattern]
+	if !exists {
+		var err error
+		re, err = regexp.Compile(pattern)
+		if err != nil {
+			return false, fmt.Errorf("无效的正则表达式: %s", pattern)
+		}
+		m.regexCache[pattern] = re
+	}
+
+	return re.MatchString(s), nil
+}
+
+// GroupContains 判断IP是否在IP组的条目中，在 now 时已过期的条目会被忽略
+// TODO: 避免使用线性遍历 O(N)，使用 基数树 (Radix Tree/Patricia Trie) 优化
+func GroupContains(group *model.IPGroup, expiry map[string]time.Time, ip string, now time.Time) (bool, error) {
+	for _, item := range group.Items {
+		// 已过期的条目不参与匹配，等待定时任务从组中移除
+		if expiresAt, ok := expiry[item]; ok && !now.Before(expiresAt) {
+			continue
+		}
+
+		if net.ParseIP(item) != nil {
+			if ip == item {
+				return true, nil
+			}
+		} else if _, _, err := net.ParseCIDR(item); err == nil {
+			inCIDR, err := isIPInCIDR(ip, item)
+			if err != nil {
+				return false, err
+			}
+			if inCIDR {
+				return true, nil
+			}
+		} else {
+			return false, fmt.Errorf("IP组 %s 包含无效项: %s", group.Name, item)
+		}
+	}
+
+	return false, nil
+}
+
+// isIPInCIDR 检查IP是否在CIDR范围内
+func isIPInCIDR(ipStr, cidrStr string) (bool, error) {
+	ip := net.ParseIP(ipStr)
+	if ip == nil {
+		return false, fmt.Errorf("无效的IP地址: %s", ipStr)
+	}
+
+	_, ipNet, err := net.ParseCIDR(cidrStr)
+	if err != nil {
+		return false, fmt.Errorf("无效的CIDR: %s", cidrStr)
+	}
+
+	return ipNet.Contains(ip), nil
+}
+
+// matchIPFuzzy 模糊匹配IP
+func matchIPFuzzy(ip, pattern string) (bool, error) {
+	ipParts := strings.Split(ip, ".")
+	patternParts := strings.Split(pattern, ".")
+
+	if len(ipParts) != 4 || len(patternParts) != 4 {
+		return false, fmt.Errorf("IP格式错误")
+	}
+
+	for i := 0; i < 4; i++ {
+		if patternParts[i] != "*" && ipParts[i] != patternParts[i] {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
@@ -0,0 +1,63 @@
+package microrule
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+)
+
+// RuleFunc 返回规则、解析后的条件，以及规则是否作用于请求的站点且在生效时间内
+type RuleFunc[R any] func(rule *R) (*model.MicroRule, Condition, bool)
+
+// SortRules 按匹配顺序稳定排序：优先级高的排在前面，优先级相同时按ID即创建顺序，没有ID时按原始顺序
+func SortRules[R any](rules []R, ruleOf func(rule *R) *model.MicroRule) {
+	slices.SortStableFunc(rules, func(a, b R) int {
+		return model.CompareMicroRuleOrder(ruleOf(&a), ruleOf(&b))
+	})
+}
+
+// MatchRules 按顺序匹配已排序的规则，返回是否拦截请求和第一条匹配的规则，没有匹配的规则时 matched 为 nil
+// 不作用于请求的站点或不在生效时间内的规则视同禁用；没有匹配的规则时，存在启用的白名单规则则拦截，否则放行
+func MatchRules[R any](rules []R, ruleOf RuleFunc[R], m *Matcher, req Request) (shouldBlock bool, matched *R, err error) {
+	// 标记当前站点是否存在启用的白名单规则
+	hasWhitelistRule := false
+
+	for i := range rules {
+		rule, condition, active := ruleOf(&rules[i])
+		if !active {
+			continue
+		}
+
+		// 检查是否存在启用的白名单规则
+		if rule.Status == model.RuleEnabled && rule.Type == model.WhitelistRule {
+			hasWhitelistRule = true
+		}
+
+		// 跳过禁用的规则
+		if rule.Status == model.RuleDisabled {
+			continue
+		}
+
+		match, err := condition.Match(m, req)
+		if err != nil {
+			return false, nil, err
+		}
+		if !match {
+			continue
+		}
+
+		// 根据规则类型确定是否需要拦截：黑名单拦截，白名单放行
+		switch rule.Type {
+		case model.BlacklistRule:
+			return true, &rules[i], nil
+		case model.WhitelistRule:
+			return false, &rules[i], nil
+		default:
+			return false, nil, fmt.Errorf("未知的规则类型: %s", rule.Type)
+		}
+	}
+
+	// 存在白名单规则但未匹配任何规则 -> 拦截请求（安全默认值）
+	return hasWhitelistRule, nil, nil
+}
@@ -0,0 +1,193 @@
+package microrule
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// testRule 测试使用的规则，active 为规则是否作用于请求的站点且在生效时间内
+type testRule struct {
+	rule      model.MicroRule
+	condition Condition
+	active    bool
+}
+
+func newTestRule(t *testing.T, name string, priority int, ruleType model.RuleType, condition bson.D) testRule {
+	t.Helper()
+	raw, err := bson.Marshal(condition)
+	if err != nil {
+		t.Fatalf("bson.Marshal() error = %v", err)
+	}
+	parsed, err := ParseCondition(raw)
+	if err != nil {
+		t.Fatalf("ParseCondition() error = %v", err)
+	}
+	return testRule{
+		rule:      model.MicroRule{Name: name, Priority: priority, Type: ruleType, Status: model.RuleEnabled, Condition: raw},
+		condition: parsed,
+		active:    true,
+	}
+}
+
+func testRuleOf(r *testRule) (*model.MicroRule, Condition, bool) {
+	return &r.rule, r.condition, r.active
+}
+
+// TestMatchRules 测试按顺序匹配规则：第一条命中的规则决定结果，未命中时存在启用的白名单规则则拦截
+func TestMatchRules(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	groups := map[string]*model.IPGroup{
+		"office": {
+			Name:        "office",
+			Items:       []string{"10.0.0.0/24", "192.168.1.1"},
+			Expirations: []model.IPItemExpiration{{Item: "192.168.1.1", ExpiresAt: now}},
+		},
+	}
+	matcher := NewMatcher(func(host, ip, name string) (bool, error) {
+		group, ok := groups[name]
+		if !ok {
+			return false, fmt.Errorf("IP组不存在: %s", name)
+		}
+		return GroupContains(group, group.ExpiryMap(), ip, now)
+	})
+	req := Request{Host: "a.example.com", IP: "10.0.0.8", URL: "/admin/login?next=/", Path: "/admin/login"}
+
+	for _, tt := range []struct {
+		name      string
+		rules     func(t *testing.T) []testRule
+		wantBlock bool
+		wantRule  string
+		wantErr   bool
+	}{
+		{
+			name: "黑名单命中",
+			rules: func(t *testing.T) []testRule {
+				return []testRule{newTestRule(t, "admin", 0, model.BlacklistRule, model.NewSimpleCondition(TargetPath, MatchPrefixKeyword, "/admin"))}
+			},
+			wantBlock: true,
+			wantRule:  "admin",
+		},
+		{
+			name: "白名单优先级更高",
+			rules: func(t *testing.T) []testRule {
+				return []testRule{
+					newTestRule(t, "deny", 1, model.BlacklistRule, model.NewSimpleCondition(TargetURL, MatchContains, "next=")),
+					newTestRule(t, "office", 10, model.WhitelistRule, model.NewSimpleCondition(SourceIP, MatchInIPGroup, "office")),
+				}
+			},
+			wantRule: "office",
+		},
+		{
+			name: "白名单未命中时拦截",
+			rules: func(t *testing.T) []testRule {
+				return []testRule{newTestRule(t, "expired", 0, model.WhitelistRule, model.NewSimpleCondition(SourceIP, MatchEqual, "192.168.1.1"))}
+			},
+			wantBlock: true,
+		},
+		{
+			name: "不生效和禁用的规则",
+			rules: func(t *testing.T) []testRule {
+				inactive := newTestRule(t, "inactive", 0, model.BlacklistRule, model.NewSimpleCondition(TargetPath, MatchRegex, "^/admin"))
+				inactive.active = false
+				disabled := newTestRule(t, "disabled", 0, model.BlacklistRule, model.NewSimpleCondition(TargetPath, MatchRegex, "^/admin"))
+				disabled.rule.Status = model.RuleDisabled
+				return []testRule{inactive, disabled}
+			},
+		},
+		{
+			name: "禁用的白名单不拦截",
+			rules: func(t *testing.T) []testRule {
+				disabled := newTestRule(t, "disabled", 0, model.WhitelistRule, model.NewSimpleCondition(SourceIP, MatchEqual, "1.1.1.1"))
+				disabled.rule.Status = model.RuleDisabled
+				return []testRule{disabled}
+			},
+		},
+		{
+			name: "复合条件",
+			rules: func(t *testing.T) []testRule {
+				return []testRule{
+					newTestRule(t, "and", 1, model.BlacklistRule, model.NewCompositeCondition(LogicalAND,
+						model.NewSimpleCondition(SourceIP, MatchInCIDR, "10.0.0.0/8"), model.NewSimpleCondition(TargetPath, MatchEqual, "/login"))),
+					newTestRule(t, "or", 0, model.BlacklistRule, model.NewCompositeCondition(LogicalOR,
+						model.NewSimpleCondition(SourceIP, MatchFuzzy, "10.0.1.*"), model.NewSimpleCondition(TargetURL, MatchNotContains, "token"))),
+				}
+			},
+			wantBlock: true,
+			wantRule:  "or",
+		},
+		{
+			name: "OR 短路不计算后面的条件",
+			rules: func(t *testing.T) []testRule {
+				return []testRule{newTestRule(t, "or", 0, model.BlacklistRule, model.NewCompositeCondition(LogicalOR,
+					model.NewSimpleCondition(TargetPath, MatchContains, "admin"), model.NewSimpleCondition(SourceIP, MatchInIPGroup, "missing")))}
+			},
+			wantBlock: true,
+			wantRule:  "or",
+		},
+		{
+			name: "IP组不存在",
+			rules: func(t *testing.T) []testRule {
+				return []testRule{newTestRule(t, "missing", 0, model.BlacklistRule, model.NewSimpleCondition(SourceIP, MatchNotInIPGroup, "missing"))}
+			},
+			wantErr: true,
+		},
+		{
+			name: "空的复合条件",
+			rules: func(t *testing.T) []testRule {
+				return []testRule{newTestRule(t, "empty", 0, model.BlacklistRule, model.NewCompositeCondition(LogicalAND))}
+			},
+			wantErr: true,
+		},
+		{
+			name: "无效的正则表达式",
+			rules: func(t *testing.T) []testRule {
+				return []testRule{newTestRule(t, "regex", 0, model.BlacklistRule, model.NewSimpleCondition(TargetURL, MatchRegex, "("))}
+			},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := tt.rules(t)
+			SortRules(rules, func(r *testRule) *model.MicroRule { return &r.rule })
+			block, matched, err := MatchRules(rules, testRuleOf, matcher, req)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("MatchRules() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if block != tt.wantBlock {
+				t.Errorf("MatchRules() block = %v, want %v", block, tt.wantBlock)
+			}
+			name := ""
+			if matched != nil {
+				name = matched.rule.Name
+			}
+			if name != tt.wantRule {
+				t.Errorf("MatchRules() matched = %q, want %q", name, tt.wantRule)
+			}
+		})
+	}
+}
+
+// TestSortRules 测试按优先级降序排序，优先级相同时按ID即创建顺序，没有ID的规则保持原始顺序
+func TestSortRules(t *testing.T) {
+	older, newer := bson.NewObjectIDFromTimestamp(time.Unix(1, 0)), bson.NewObjectIDFromTimestamp(time.Unix(2, 0))
+	rules := []model.MicroRule{
+		{Name: "low", Priority: 1, ID: newer},
+		{Name: "newer", Priority: 5, ID: newer},
+		{Name: "no-id-b", Priority: 1},
+		{Name: "older", Priority: 5, ID: older},
+		{Name: "no-id-a", Priority: 1},
+		{Name: "high", Priority: 9},
+	}
+	SortRules(rules, func(r *model.MicroRule) *model.MicroRule { return r })
+
+	want := []string{"high", "older", "newer", "no-id-b", "no-id-a", "low"}
+	for i, rule := range rules {
+		if rule.Name != want[i] {
+			t.Fatalf("SortRules() order[%d] = %s, want %s", i, rule.Name, want[i])
+		}
+	}
+}
@@ -1,15 +1,70 @@
 package model
 
-import "go.mongodb.org/mongo-driver/v2/bson"
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
 
 // IPGroup 表示IP地址组信息
 // @Description IP地址组信息，包含组名和IP地址列表
 type IPGroup struct {
-	ID    bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty" example:"60d21b4367d0d8992e89e964"` // 组唯一标识符
-	Name  string        `bson:"name" json:"name" example:"内部服务器"`                                     // 组名称
-	Items []string      `bson:"items" json:"items" example:"['192.168.1.1', '10.0.0.1/24']"`          // IP地址或CIDR列表
+	ID          bson.ObjectID      `bson:"_id,omitempty" json:"id,omitempty" example:"60d21b4367d0d8992e89e964"` // 组唯一标识符
+	Name        string             `bson:"name" json:"name" example:"内部服务器"`                                     // 组名称
+	Items       []string           `bson:"items" json:"items" example:"['192.168.1.1', '10.0.0.1/24']"`          // IP地址或CIDR列表
+	Expirations []IPItemExpiration `bson:"expirations,omitempty" json:"expirations,omitempty"`                   // 条目过期时间，未列出的条目永久有效
+	Scope       *SiteScope         `bson:"scope,omitempty" json:"scope,omitempty"`                               // 站点作用域，为空表示对所有站点生效
+	EdgeBlock   bool               `bson:"edge_block,omitempty" json:"edgeBlock,omitempty" example:"false"`      // 是否在 HAProxy 前端直接拒绝组中IP的请求，不再经过 WAF 检测
+}
+
+// IPItemExpiration IP组条目过期时间
+// @Description IP组中单个条目的过期时间，过期后不再参与匹配，并由定时任务从组中移除
+type IPItemExpiration struct {
+	Item      string    `bson:"item" json:"item" example:"192.168.1.1"` // IP地址或CIDR，必须是组中的条目
+	ExpiresAt time.Time `bson:"expires_at" json:"expiresAt"`            // 过期时间
 }
 
 func (i *IPGroup) GetCollectionName() string {
 	return "ip_group"
 }
+
+// ExpiryMap 返回条目到过期时间的映射
+func (i *IPGroup) ExpiryMap() map[string]time.Time {
+	if len(i.Expirations) == 0 {
+		return nil
+	}
+	expiry := make(map[string]time.Time, len(i.Expirations))
+	for _, expiration := range i.Expirations {
+		expiry[expiration.Item] = expiration.ExpiresAt
+	}
+	return expiry
+}
+
+// RemoveExpired 移除在时间 t 已过期的条目及其过期记录，返回被移除的条目
+func (i *IPGroup) RemoveExpired(t time.Time) []string {
+	expiry := i.ExpiryMap()
+	if expiry == nil {
+		return nil
+	}
+
+	var removed []string
+	items := i.Items[:0]
+	for _, item := range i.Items {
+		if expiresAt, ok := expiry[item]; ok && !t.Before(expiresAt) {
+			removed = append(removed, item)
+			continue
+		}
+		items = append(items, item)
+	}
+	i.Items = items
+
+	expirations := i.Expirations[:0]
+	for _, expiration := range i.Expirations {
+		if t.Before(expiration.ExpiresAt) {
+			expirations = append(expirations, expiration)
+		}
+	}
+	i.Expirations = expirations
+
+	return removed
+}
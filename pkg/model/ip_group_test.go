@@ -0,0 +1,68 @@
+package model
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+// TestIPGroupRemoveExpired 测试移除已过期的条目及其过期记录，未过期和永久有效的条目保留
+func TestIPGroupRemoveExpired(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name            string
+		group           IPGroup
+		wantRemoved     []string
+		wantItems       []string
+		wantExpirations []string
+	}{
+		{
+			name:      "没有过期记录",
+			group:     IPGroup{Items: []string{"10.0.0.1"}},
+			wantItems: []string{"10.0.0.1"},
+		},
+		{
+			name: "过期时间等于当前时间",
+			group: IPGroup{
+				Items:       []string{"10.0.0.1", "10.0.0.2", "10.0.0.0/24"},
+				Expirations: []IPItemExpiration{{Item: "10.0.0.1", ExpiresAt: now}, {Item: "10.0.0.2", ExpiresAt: now.Add(time.Second)}},
+			},
+			wantRemoved:     []string{"10.0.0.1"},
+			wantItems:       []string{"10.0.0.2", "10.0.0.0/24"},
+			wantExpirations: []string{"10.0.0.2"},
+		},
+		{
+			name: "全部过期",
+			group: IPGroup{
+				Items:       []string{"10.0.0.1", "2001:db8::/32"},
+				Expirations: []IPItemExpiration{{Item: "10.0.0.1", ExpiresAt: now.Add(-time.Hour)}, {Item: "2001:db8::/32", ExpiresAt: now.Add(-time.Minute)}},
+			},
+			wantRemoved: []string{"10.0.0.1", "2001:db8::/32"},
+		},
+		{
+			name: "过期记录对应的条目已不在组中",
+			group: IPGroup{
+				Items:       []string{"10.0.0.2"},
+				Expirations: []IPItemExpiration{{Item: "10.0.0.1", ExpiresAt: now.Add(-time.Hour)}},
+			},
+			wantItems: []string{"10.0.0.2"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			removed := tt.group.RemoveExpired(now)
+			if !slices.Equal(removed, tt.wantRemoved) {
+				t.Errorf("removed = %v, want %v", removed, tt.wantRemoved)
+			}
+			if !slices.Equal(tt.group.Items, tt.wantItems) {
+				t.Errorf("items = %v, want %v", tt.group.Items, tt.wantItems)
+			}
+			var expirations []string
+			for _, expiration := range tt.group.Expirations {
+				expirations = append(expirations, expiration.Item)
+			}
+			if !slices.Equal(expirations, tt.wantExpirations) {
+				t.Errorf("expirations = %v, want %v", expirations, tt.wantExpirations)
+			}
+		})
+	}
+}
@@ -0,0 +1,69 @@
+package model
+
+import (
+	"strings"
+	"time"
+)
+
+// LoginProtectionAction 登录保护的处置动作
+type LoginProtectionAction string
+
+const (
+	LoginActionChallenge LoginProtectionAction = "challenge" // 返回 JS 挑战页，通过挑战后才能继续提交登录
+	LoginActionThrottle  LoginProtectionAction = "throttle"  // 返回 429，窗口内失败次数回落后自动恢复
+	LoginActionBlock     LoginProtectionAction = "block"     // 封禁来源IP
+)
+
+const (
+	BlockReasonCredentialStuffing = "credential_stuffing" // 撞库攻击的封禁原因
+	DefaultLoginWindow            = 600                   // 默认统计窗口（秒）
+	DefaultLoginBlockDuration     = 3600                  // 默认封禁时长（秒）
+)
+
+// LoginProtection 站点登录保护配置
+// @Description 按用户名、来源IP和来源ASN统计登录失败次数，任一维度在滑动窗口内超过阈值时对后续登录请求执行处置动作；需要开启响应检测
+type LoginProtection struct {
+	Enabled           bool                  `bson:"enabled" json:"enabled" example:"true"`                                       // 是否启用
+	Path              string                `bson:"path" json:"path" example:"/api/login"`                                       // 登录接口路径，完全匹配
+	Method            string                `bson:"method" json:"method" example:"POST"`                                         // 登录接口请求方法
+	UsernameField     string                `bson:"usernameField" json:"usernameField" example:"username"`                       // 用户名字段，表单字段名或 JSON 键，JSON 支持 user.name 形式的嵌套路径
+	FailureStatus     []int                 `bson:"failureStatus,omitempty" json:"failureStatus,omitempty" example:"401,403"`    // 表示登录失败的响应状态码
+	FailurePattern    string                `bson:"failurePattern,omitempty" json:"failurePattern,omitempty" example:"(?i)密码错误"` // 表示登录失败的响应内容正则表达式
+	Window            int64                 `bson:"window" json:"window" example:"600"`                                          // 滑动统计窗口（秒）
+	UsernameThreshold int64                 `bson:"usernameThreshold" json:"usernameThreshold" example:"5"`                      // 单个用户名的失败次数阈值，0 表示不统计
+	IPThreshold       int64                 `bson:"ipThreshold" json:"ipThreshold" example:"20"`                                 // 单个来源IP的失败次数阈值，0 表示不统计
+	ASNThreshold      int64                 `bson:"asnThreshold" json:"asnThreshold" example:"200"`                              // 单个来源ASN的失败次数阈值，0 表示不统计，需要配置 ASN 数据库
+	Action            LoginProtectionAction `bson:"action" json:"action" example:"challenge"`                                    // 超过阈值后的处置动作
+	BlockDuration     int64                 `bson:"blockDuration" json:"blockDuration" example:"3600"`                           // block 动作的封禁时长（秒）
+}
+
+// MatchRequest 判断请求是否为受保护的登录请求
+func (p *LoginProtection) MatchRequest(method, path string) bool {
+	return p != nil && p.Enabled && path == p.Path && strings.EqualFold(method, p.Method)
+}
+
+// IsFailureStatus 判断响应状态码是否表示登录失败
+func (p *LoginProtection) IsFailureStatus(status int) bool {
+	for _, s := range p.FailureStatus {
+		if s == status {
+			return true
+		}
+	}
+	return false
+}
+
+// WindowOrDefault 返回统计窗口，未设置时使用默认值
+func (p *LoginProtection) WindowOrDefault() time.Duration {
+	if p.Window <= 0 {
+		return DefaultLoginWindow * time.Second
+	}
+	return time.Duration(p.Window) * time.Second
+}
+
+// BlockDurationOrDefault 返回封禁时长，未设置时使用默认值
+func (p *LoginProtection) BlockDurationOrDefault() time.Duration {
+	if p.BlockDuration <= 0 {
+		return DefaultLoginBlockDuration * time.Second
+	}
+	return time.Duration(p.BlockDuration) * time.Second
+}
@@ -1,6 +1,9 @@
 package model
 
 import (
+	"bytes"
+	"cmp"
+
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
@@ -33,9 +36,26 @@ type MicroRule struct {
 	Status   RuleStatus    `json:"status" bson:"status" example:"enabled"`                               // 规则状态
 	Priority int           `json:"priority" bson:"priority" example:"100"`                               // 优先级字段，数字越大优先级越高
 	// @Schema(type=object, example={"type":"composite","operator":"AND","conditions":[{"type":"simple","target":"source_ip","match_type":"in_ipgroup","match_value":"blocked_ips"},{"type":"simple","target":"path","match_type":"regex","match_value":"^/admin/.*$"}]})
-	Condition bson.Raw `json:"condition" bson:"condition" swaggertype:"object"`
+	Condition   bson.Raw      `json:"condition" bson:"condition" swaggertype:"object"`
+	Scope       *SiteScope    `json:"scope,omitempty" bson:"scope,omitempty"`                 // 站点作用域，为空表示对所有站点生效
+	Schedule    *RuleSchedule `json:"schedule,omitempty" bson:"schedule,omitempty"`           // 生效计划，为空表示始终生效
+	IPGroupRefs []string      `json:"ipGroupRefs" bson:"ip_group_refs" example:"blocked_ips"` // 条件引用的IP组名称，保存时根据条件生成，用于查询IP组的依赖
 }
 
 func (r *MicroRule) GetCollectionName() string {
 	return "micro_rule"
 }
+
+// MicroRuleMatchOrder 微规则匹配顺序对应的 MongoDB 排序：优先级降序，优先级相同时按ID升序，即创建顺序
+// 规则引擎、HAProxy 卸载和规则冲突分析都按此顺序读取规则，优先级相同的规则在各处的先后顺序一致
+func MicroRuleMatchOrder() bson.D {
+	return bson.D{{Key: "priority", Value: -1}, {Key: "_id", Value: 1}}
+}
+
+// CompareMicroRuleOrder 按匹配顺序比较两条规则，与 MicroRuleMatchOrder 的排序结果一致
+func CompareMicroRuleOrder(a, b *MicroRule) int {
+	if c := cmp.Compare(b.Priority, a.Priority); c != 0 {
+		return c
+	}
+	return bytes.Compare(a.ID[:], b.ID[:])
+}
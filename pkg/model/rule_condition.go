@@ -0,0 +1,144 @@
+package model
+
+import (
+	"sort"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// 条件类型
+const (
+	ConditionTypeSimple    = "simple"
+	ConditionTypeComposite = "composite"
+)
+
+// IP组匹配方式
+const (
+	MatchTypeInIPGroup    = "in_ipgroup"
+	MatchTypeNotInIPGroup = "not_in_ipgroup"
+)
+
+// NewSimpleCondition 返回简单条件的文档，用于构造规则条件
+func NewSimpleCondition[T, M ~string](target T, matchType M, value string) bson.D {
+	return bson.D{
+		{Key: "type", Value: ConditionTypeSimple},
+		{Key: "target", Value: string(target)},
+		{Key: "match_type", Value: string(matchType)},
+		{Key: "match_value", Value: value},
+	}
+}
+
+// NewCompositeCondition 返回按 operator 组合子条件的复合条件文档
+func NewCompositeCondition[O ~string](operator O, conditions ...bson.D) bson.D {
+	children := make(bson.A, len(conditions))
+	for i, condition := range conditions {
+		children[i] = condition
+	}
+	return bson.D{
+		{Key: "type", Value: ConditionTypeComposite},
+		{Key: "operator", Value: string(operator)},
+		{Key: "conditions", Value: children},
+	}
+}
+
+// ConditionIPGroupRefs 返回条件树中引用的IP组名称，已去重并排序
+func ConditionIPGroupRefs(condition bson.Raw) []string {
+	set := make(map[string]struct{})
+	collectIPGroupRefs(condition, set)
+
+	refs := make([]string, 0, len(set))
+	for name := range set {
+		refs = append(refs, name)
+	}
+	sort.Strings(refs)
+	return refs
+}
+
+func collectIPGroupRefs(condition bson.Raw, set map[string]struct{}) {
+	if len(condition) == 0 {
+		return
+	}
+
+	conditionType, _ := condition.Lookup("type").StringValueOK()
+	switch conditionType {
+	case ConditionTypeSimple:
+		matchType, _ := condition.Lookup("match_type").StringValueOK()
+		if matchType != MatchTypeInIPGroup && matchType != MatchTypeNotInIPGroup {
+			return
+		}
+		if name, ok := condition.Lookup("match_value").StringValueOK(); ok && name != "" {
+			set[name] = struct{}{}
+		}
+	case ConditionTypeComposite:
+		conditions, ok := condition.Lookup("conditions").ArrayOK()
+		if !ok {
+			return
+		}
+		values, err := conditions.Values()
+		if err != nil {
+			return
+		}
+		for _, value := range values {
+			if doc, ok := value.DocumentOK(); ok {
+				collectIPGroupRefs(doc, set)
+			}
+		}
+	}
+}
+
+// RenameConditionIPGroup 将条件树中对IP组 oldName 的引用替换为 newName，返回新条件和替换次数
+func RenameConditionIPGroup(condition bson.Raw, oldName, newName string) (bson.Raw, int, error) {
+	var doc bson.D
+	if err := bson.Unmarshal(condition, &doc); err != nil {
+		return nil, 0, err
+	}
+
+	renamed := renameIPGroupInDoc(doc, oldName, newName)
+	if renamed == 0 {
+		return condition, 0, nil
+	}
+
+	data, err := bson.Marshal(doc)
+	if err != nil {
+		return nil, 0, err
+	}
+	return data, renamed, nil
+}
+
+func renameIPGroupInDoc(doc bson.D, oldName, newName string) int {
+	var conditionType, matchType string
+	valueIndex := -1
+	for i, elem := range doc {
+		switch elem.Key {
+		case "type":
+			conditionType, _ = elem.Value.(string)
+		case "match_type":
+			matchType, _ = elem.Value.(string)
+		case "match_value":
+			valueIndex = i
+		}
+	}
+
+	switch conditionType {
+	case ConditionTypeSimple:
+		if (matchType == MatchTypeInIPGroup || matchType == MatchTypeNotInIPGroup) && valueIndex >= 0 && doc[valueIndex].Value == oldName {
+			doc[valueIndex].Value = newName
+			return 1
+		}
+	case ConditionTypeComposite:
+		renamed := 0
+		for _, elem := range doc {
+			if elem.Key != "conditions" {
+				continue
+			}
+			conditions, _ := elem.Value.(bson.A)
+			for _, item := range conditions {
+				if child, ok := item.(bson.D); ok {
+					renamed += renameIPGroupInDoc(child, oldName, newName)
+				}
+			}
+		}
+		return renamed
+	}
+	return 0
+}
@@ -0,0 +1,100 @@
+package model
+
+import (
+	"bytes"
+	"slices"
+	"testing"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+func mustMarshalCondition(t *testing.T, condition bson.D) bson.Raw {
+	t.Helper()
+	raw, err := bson.Marshal(condition)
+	if err != nil {
+		t.Fatalf("marshal condition: %v", err)
+	}
+	return raw
+}
+
+// nestedTestCondition 多层嵌套的复合条件，office 被引用两次，URL 和路径条件的值与IP组同名但不是引用
+func nestedTestCondition(t *testing.T) bson.Raw {
+	return mustMarshalCondition(t, NewCompositeCondition("AND",
+		NewSimpleCondition("source_ip", MatchTypeInIPGroup, "office"),
+		NewCompositeCondition("OR",
+			NewSimpleCondition("source_ip", MatchTypeNotInIPGroup, "vpn"),
+			NewSimpleCondition("path", "equal", "office"),
+			NewCompositeCondition("AND",
+				NewSimpleCondition("source_ip", MatchTypeInIPGroup, "office"),
+				NewSimpleCondition("source_ip", MatchTypeInIPGroup, ""),
+			),
+		),
+		NewSimpleCondition("url", "contains", "office"),
+		NewSimpleCondition("source_ip", "in_cidr", "10.0.0.0/8"),
+	))
+}
+
+// TestConditionIPGroupRefs 测试从嵌套的复合条件中提取去重并排序的IP组引用
+func TestConditionIPGroupRefs(t *testing.T) {
+	for _, tt := range []struct {
+		name      string
+		condition bson.Raw
+		want      []string
+	}{
+		{"空条件", nil, []string{}},
+		{"简单条件", mustMarshalCondition(t, NewSimpleCondition("source_ip", MatchTypeNotInIPGroup, "blacklist")), []string{"blacklist"}},
+		{"非IP组条件", mustMarshalCondition(t, NewSimpleCondition("source_ip", "equal", "office")), []string{}},
+		{"嵌套复合条件", nestedTestCondition(t), []string{"office", "vpn"}},
+		{"没有子条件", mustMarshalCondition(t, bson.D{{Key: "type", Value: ConditionTypeComposite}, {Key: "operator", Value: "AND"}}), []string{}},
+		{"未知类型", mustMarshalCondition(t, bson.D{{Key: "type", Value: "unknown"}, {Key: "match_type", Value: MatchTypeInIPGroup}, {Key: "match_value", Value: "office"}}), []string{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ConditionIPGroupRefs(tt.condition); !slices.Equal(got, tt.want) {
+				t.Errorf("ConditionIPGroupRefs() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRenameConditionIPGroup 测试只替换嵌套条件中的IP组引用，保留其他条件和字段顺序
+func TestRenameConditionIPGroup(t *testing.T) {
+	condition := nestedTestCondition(t)
+
+	renamed, count, err := RenameConditionIPGroup(condition, "office", "headquarters")
+	if err != nil {
+		t.Fatalf("RenameConditionIPGroup() error = %v", err)
+	}
+	if count != 2 {
+		t.Errorf("count = %d, want 2", count)
+	}
+	if got := ConditionIPGroupRefs(renamed); !slices.Equal(got, []string{"headquarters", "vpn"}) {
+		t.Errorf("refs after rename = %v", got)
+	}
+
+	// 除被替换的引用外，条件与原条件完全一致
+	want := mustMarshalCondition(t, NewCompositeCondition("AND",
+		NewSimpleCondition("source_ip", MatchTypeInIPGroup, "headquarters"),
+		NewCompositeCondition("OR",
+			NewSimpleCondition("source_ip", MatchTypeNotInIPGroup, "vpn"),
+			NewSimpleCondition("path", "equal", "office"),
+			NewCompositeCondition("AND",
+				NewSimpleCondition("source_ip", MatchTypeInIPGroup, "headquarters"),
+				NewSimpleCondition("source_ip", MatchTypeInIPGroup, ""),
+			),
+		),
+		NewSimpleCondition("url", "contains", "office"),
+		NewSimpleCondition("source_ip", "in_cidr", "10.0.0.0/8"),
+	))
+	if !bytes.Equal(renamed, want) {
+		t.Errorf("renamed condition = %s, want %s", renamed, want)
+	}
+
+	unchanged, count, err := RenameConditionIPGroup(condition, "missing", "other")
+	if err != nil || count != 0 || !bytes.Equal(unchanged, condition) {
+		t.Errorf("RenameConditionIPGroup(missing) = %s, %d, %v, want original condition", unchanged, count, err)
+	}
+
+	if _, _, err := RenameConditionIPGroup(bson.Raw{0x01, 0x02}, "office", "headquarters"); err == nil {
+		t.Error("RenameConditionIPGroup() should fail for invalid bson")
+	}
+}
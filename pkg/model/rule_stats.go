@@ -0,0 +1,32 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// RuleStatsSource 规则来源
+type RuleStatsSource string
+
+const (
+	RuleStatsSourceMicro  RuleStatsSource = "micro"  // 微规则引擎
+	RuleStatsSourceCoraza RuleStatsSource = "coraza" // Coraza SecLang 规则
+)
+
+// RuleStats 规则命中统计，按小时分桶存储
+// @Description 单条规则在某个小时内的命中次数、拦截次数和最后命中时间
+type RuleStats struct {
+	ID        bson.ObjectID   `bson:"_id,omitempty" json:"id,omitempty" example:"60d21b4367d0d8992e89e964"`
+	Source    RuleStatsSource `bson:"source" json:"source" example:"micro"`                            // 规则来源: micro, coraza
+	RuleID    string          `bson:"rule_id" json:"ruleId" example:"60d21b4367d0d8992e89e964"`        // 微规则ID(hex) 或 Coraza 规则ID
+	RuleName  string          `bson:"rule_name,omitempty" json:"ruleName,omitempty" example:"SQL注入防护"` // 规则名称，仅微规则有效
+	Bucket    time.Time       `bson:"bucket" json:"bucket"`                                            // 统计桶开始时间（整点）
+	Hits      int64           `bson:"hits" json:"hits" example:"128"`                                  // 命中次数
+	Blocks    int64           `bson:"blocks" json:"blocks" example:"64"`                               // 拦截次数
+	LastHitAt time.Time       `bson:"last_hit_at" json:"lastHitAt"`                                    // 最后命中时间
+}
+
+func (r *RuleStats) GetCollectionName() string {
+	return "rule_stats"
+}
@@ -0,0 +1,208 @@
+package model
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInvalidClock    = errors.New("时间格式无效，应为 HH:MM")
+	ErrInvalidTimezone = errors.New("时区无效")
+	ErrInvalidWeekday  = errors.New("星期取值应为 0-6，0 表示周日")
+	ErrInvalidWindow   = errors.New("时段开始时间与结束时间不能相同")
+	ErrInvalidPeriod   = errors.New("生效结束时间必须晚于开始时间")
+)
+
+// RuleSchedule 规则生效计划
+// @Description 规则的生效时间范围和每周重复的生效时段，未设置的部分不做限制
+type RuleSchedule struct {
+	ActiveFrom  *time.Time     `bson:"active_from,omitempty" json:"activeFrom,omitempty"`                    // 生效开始时间
+	ActiveUntil *time.Time     `bson:"active_until,omitempty" json:"activeUntil,omitempty"`                  // 生效结束时间，过期后由定时任务清理
+	Timezone    string         `bson:"timezone,omitempty" json:"timezone,omitempty" example:"Asia/Shanghai"` // 每周时段使用的时区，默认 UTC
+	Windows     []WeeklyWindow `bson:"windows,omitempty" json:"windows,omitempty"`                           // 每周生效时段，为空表示不限时段
+}
+
+// WeeklyWindow 每周重复的生效时段
+// @Description 在指定星期的某个时段内生效，结束时间早于开始时间表示跨越午夜
+type WeeklyWindow struct {
+	Days  []time.Weekday `bson:"days" json:"days" swaggertype:"array,integer" example:"1,2,3,4,5"` // 星期，0 表示周日，为空表示每天；跨午夜时段以开始当天为准
+	Start string         `bson:"start" json:"start" example:"09:00"`                               // 开始时间 HH:MM
+	End   string         `bson:"end" json:"end" example:"18:00"`                                   // 结束时间 HH:MM，可为 24:00
+}
+
+// IsEmpty 是否未设置任何限制
+func (s *RuleSchedule) IsEmpty() bool {
+	return s == nil || (s.ActiveFrom == nil && s.ActiveUntil == nil && len(s.Windows) == 0)
+}
+
+// Location 返回每周时段使用的时区
+func (s *RuleSchedule) Location() (*time.Location, error) {
+	if s == nil || s.Timezone == "" {
+		return time.UTC, nil
+	}
+	loc, err := time.LoadLocation(s.Timezone)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidTimezone, s.Timezone)
+	}
+	return loc, nil
+}
+
+// Validate 校验生效计划
+func (s *RuleSchedule) Validate() error {
+	if s == nil {
+		return nil
+	}
+	if s.ActiveFrom != nil && s.ActiveUntil != nil && !s.ActiveUntil.After(*s.ActiveFrom) {
+		return ErrInvalidPeriod
+	}
+	if _, err := s.Location(); err != nil {
+		return err
+	}
+	for _, window := range s.Windows {
+		if err := window.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Expired 在时间 t 是否已超过生效结束时间
+func (s *RuleSchedule) Expired(t time.Time) bool {
+	return s != nil && s.ActiveUntil != nil && !t.Before(*s.ActiveUntil)
+}
+
+// Compile 解析时区和每周时段，供规则加载时调用一次，匹配请求时不再解析
+// 未设置任何限制时返回 nil，表示始终生效
+func (s *RuleSchedule) Compile() (*CompiledSchedule, error) {
+	if s.IsEmpty() {
+		return nil, nil
+	}
+	loc, err := s.Location()
+	if err != nil {
+		return nil, err
+	}
+
+	compiled := &CompiledSchedule{
+		activeFrom:  s.ActiveFrom,
+		activeUntil: s.ActiveUntil,
+		location:    loc,
+		windows:     make([]compiledWindow, 0, len(s.Windows)),
+	}
+	for _, window := range s.Windows {
+		w, err := window.compile()
+		if err != nil {
+			return nil, err
+		}
+		compiled.windows = append(compiled.windows, w)
+	}
+	return compiled, nil
+}
+
+// CompiledSchedule 已解析的生效计划，时段的开始和结束时间缓存为自午夜起的分钟数
+type CompiledSchedule struct {
+	activeFrom  *time.Time
+	activeUntil *time.Time
+	location    *time.Location
+	windows     []compiledWindow
+}
+
+// compiledWindow 已解析的每周时段
+type compiledWindow struct {
+	days  uint8 // 星期位图，第 n 位表示星期 n，0 表示每天
+	start int   // 开始时间，自午夜起的分钟数
+	end   int   // 结束时间，自午夜起的分钟数
+}
+
+// ActiveAt 在时间 t 是否生效，nil 表示没有生效计划，始终生效
+func (c *CompiledSchedule) ActiveAt(t time.Time) bool {
+	if c == nil {
+		return true
+	}
+	if c.activeFrom != nil && t.Before(*c.activeFrom) {
+		return false
+	}
+	if c.activeUntil != nil && !t.Before(*c.activeUntil) {
+		return false
+	}
+	if len(c.windows) == 0 {
+		return true
+	}
+
+	local := t.In(c.location)
+	for _, window := range c.windows {
+		if window.contains(local) {
+			return true
+		}
+	}
+	return false
+}
+
+// Validate 校验时段
+func (w WeeklyWindow) Validate() error {
+	_, err := w.compile()
+	return err
+}
+
+// compile 校验并解析时段
+func (w WeeklyWindow) compile() (compiledWindow, error) {
+	var compiled compiledWindow
+	for _, day := range w.Days {
+		if day < time.Sunday || day > time.Saturday {
+			return compiledWindow{}, ErrInvalidWeekday
+		}
+		compiled.days |= 1 << day
+	}
+	start, err := ParseClock(w.Start)
+	if err != nil {
+		return compiledWindow{}, err
+	}
+	end, err := ParseClock(w.End)
+	if err != nil {
+		return compiledWindow{}, err
+	}
+	if start == end {
+		return compiledWindow{}, ErrInvalidWindow
+	}
+	compiled.start, compiled.end = start, end
+	return compiled, nil
+}
+
+// contains 判断本地时间是否落在时段内
+func (w compiledWindow) contains(local time.Time) bool {
+	minute := local.Hour()*60 + local.Minute()
+	today := local.Weekday()
+	if w.start < w.end {
+		return w.hasDay(today) && minute >= w.start && minute < w.end
+	}
+
+	// 跨越午夜：开始当天的后半段，或前一天开始的时段在今天的前半段
+	yesterday := (today + 6) % 7
+	return (w.hasDay(today) && minute >= w.start) || (w.hasDay(yesterday) && minute < w.end)
+}
+
+func (w compiledWindow) hasDay(day time.Weekday) bool {
+	return w.days == 0 || w.days&(1<<day) != 0
+}
+
+// ParseClock 解析 HH:MM 格式的时间，返回自午夜起的分钟数，允许 24:00
+func ParseClock(s string) (int, error) {
+	hourStr, minuteStr, ok := strings.Cut(s, ":")
+	if !ok || len(hourStr) != 2 || len(minuteStr) != 2 {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidClock, s)
+	}
+	hour, err := strconv.Atoi(hourStr)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidClock, s)
+	}
+	minute, err := strconv.Atoi(minuteStr)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidClock, s)
+	}
+	if hour < 0 || minute < 0 || minute > 59 || hour > 24 || (hour == 24 && minute != 0) {
+		return 0, fmt.Errorf("%w: %s", ErrInvalidClock, s)
+	}
+	return hour*60 + minute, nil
+}
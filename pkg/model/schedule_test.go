@@ -0,0 +1,152 @@
+package model
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestParseClock 测试 HH:MM 格式的解析，允许 24:00
+func TestParseClock(t *testing.T) {
+	for _, tt := range []struct {
+		value string
+		want  int
+		err   bool
+	}{
+		{"00:00", 0, false},
+		{"09:30", 570, false},
+		{"23:59", 1439, false},
+		{"24:00", 1440, false},
+		{"24:01", 0, true},
+		{"25:00", 0, true},
+		{"12:60", 0, true},
+		{"9:30", 0, true},
+		{"09:3", 0, true},
+		{"0930", 0, true},
+		{"-1:00", 0, true},
+		{"aa:bb", 0, true},
+		{"", 0, true},
+	} {
+		got, err := ParseClock(tt.value)
+		if (err != nil) != tt.err {
+			t.Errorf("ParseClock(%q) error = %v, want error %v", tt.value, err, tt.err)
+			continue
+		}
+		if err != nil && !errors.Is(err, ErrInvalidClock) {
+			t.Errorf("ParseClock(%q) error = %v, want ErrInvalidClock", tt.value, err)
+		}
+		if got != tt.want {
+			t.Errorf("ParseClock(%q) = %d, want %d", tt.value, got, tt.want)
+		}
+	}
+}
+
+// TestRuleScheduleCompile 测试生效计划的校验错误，以及未设置限制时始终生效
+func TestRuleScheduleCompile(t *testing.T) {
+	from := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name     string
+		schedule *RuleSchedule
+		err      error
+	}{
+		{"未设置", nil, nil},
+		{"只设置时区", &RuleSchedule{Timezone: "Asia/Shanghai"}, nil},
+		{"时区无效", &RuleSchedule{Timezone: "Mars/Olympus", Windows: []WeeklyWindow{{Start: "09:00", End: "18:00"}}}, ErrInvalidTimezone},
+		{"时间格式无效", &RuleSchedule{Windows: []WeeklyWindow{{Start: "9:00", End: "18:00"}}}, ErrInvalidClock},
+		{"星期无效", &RuleSchedule{Windows: []WeeklyWindow{{Days: []time.Weekday{7}, Start: "09:00", End: "18:00"}}}, ErrInvalidWeekday},
+		{"开始与结束相同", &RuleSchedule{Windows: []WeeklyWindow{{Start: "09:00", End: "09:00"}}}, ErrInvalidWindow},
+		{"只设置开始时间", &RuleSchedule{ActiveFrom: &from}, nil},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := tt.schedule.Compile()
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Compile() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				if validateErr := tt.schedule.Validate(); !errors.Is(validateErr, tt.err) {
+					t.Errorf("Validate() error = %v, want %v", validateErr, tt.err)
+				}
+				return
+			}
+			if tt.schedule.IsEmpty() != (compiled == nil) {
+				t.Errorf("Compile() = %v for schedule IsEmpty() = %v", compiled, tt.schedule.IsEmpty())
+			}
+		})
+	}
+
+	if err := (&RuleSchedule{Timezone: "Mars/Olympus"}).Validate(); !errors.Is(err, ErrInvalidTimezone) {
+		t.Errorf("Validate() error = %v, want ErrInvalidTimezone", err)
+	}
+	until := from.Add(-time.Hour)
+	if err := (&RuleSchedule{ActiveFrom: &from, ActiveUntil: &until}).Validate(); !errors.Is(err, ErrInvalidPeriod) {
+		t.Errorf("Validate() error = %v, want ErrInvalidPeriod", err)
+	}
+	var empty *CompiledSchedule
+	if !empty.ActiveAt(from) {
+		t.Error("nil compiled schedule should always be active")
+	}
+}
+
+// TestCompiledScheduleActiveAt 测试生效时间范围和每周时段，包括跨午夜、24:00 和非 UTC 时区的日期边界
+func TestCompiledScheduleActiveAt(t *testing.T) {
+	shanghai, err := time.LoadLocation("Asia/Shanghai")
+	if err != nil {
+		t.Skipf("load timezone: %v", err)
+	}
+	from := time.Date(2025, 6, 2, 0, 0, 0, 0, time.UTC)
+	until := time.Date(2025, 6, 9, 0, 0, 0, 0, time.UTC)
+	weekdays := []time.Weekday{time.Monday, time.Tuesday, time.Wednesday, time.Thursday, time.Friday}
+	// 2025-06-02 是周一
+	utc := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 6, day, hour, minute, 0, 0, time.UTC)
+	}
+	local := func(day, hour, minute int) time.Time {
+		return time.Date(2025, 6, day, hour, minute, 0, 0, shanghai)
+	}
+
+	for _, tt := range []struct {
+		name     string
+		schedule RuleSchedule
+		at       time.Time
+		want     bool
+	}{
+		{"开始时间之前", RuleSchedule{ActiveFrom: &from}, from.Add(-time.Second), false},
+		{"开始时间", RuleSchedule{ActiveFrom: &from}, from, true},
+		{"结束时间之前", RuleSchedule{ActiveUntil: &until}, until.Add(-time.Second), true},
+		{"结束时间", RuleSchedule{ActiveUntil: &until}, until, false},
+
+		{"工作日时段内", RuleSchedule{Windows: []WeeklyWindow{{Days: weekdays, Start: "09:00", End: "18:00"}}}, utc(2, 9, 0), true},
+		{"工作日时段结束", RuleSchedule{Windows: []WeeklyWindow{{Days: weekdays, Start: "09:00", End: "18:00"}}}, utc(2, 18, 0), false},
+		{"周末不在时段内", RuleSchedule{Windows: []WeeklyWindow{{Days: weekdays, Start: "09:00", End: "18:00"}}}, utc(7, 10, 0), false},
+		{"未设置星期表示每天", RuleSchedule{Windows: []WeeklyWindow{{Start: "09:00", End: "18:00"}}}, utc(8, 10, 0), true},
+
+		{"跨午夜开始当天", RuleSchedule{Windows: []WeeklyWindow{{Days: []time.Weekday{time.Friday}, Start: "22:00", End: "06:00"}}}, utc(6, 23, 0), true},
+		{"跨午夜次日凌晨", RuleSchedule{Windows: []WeeklyWindow{{Days: []time.Weekday{time.Friday}, Start: "22:00", End: "06:00"}}}, utc(7, 5, 59), true},
+		{"跨午夜次日结束", RuleSchedule{Windows: []WeeklyWindow{{Days: []time.Weekday{time.Friday}, Start: "22:00", End: "06:00"}}}, utc(7, 6, 0), false},
+		{"跨午夜开始当天凌晨", RuleSchedule{Windows: []WeeklyWindow{{Days: []time.Weekday{time.Friday}, Start: "22:00", End: "06:00"}}}, utc(6, 2, 0), false},
+		{"跨午夜开始前", RuleSchedule{Windows: []WeeklyWindow{{Days: []time.Weekday{time.Friday}, Start: "22:00", End: "06:00"}}}, utc(6, 21, 59), false},
+		{"跨午夜周六到周日", RuleSchedule{Windows: []WeeklyWindow{{Days: []time.Weekday{time.Saturday}, Start: "23:00", End: "01:00"}}}, utc(8, 0, 30), true},
+
+		{"结束于 24:00", RuleSchedule{Windows: []WeeklyWindow{{Days: []time.Weekday{time.Monday}, Start: "18:00", End: "24:00"}}}, utc(2, 23, 59), true},
+		{"24:00 不包含次日零点", RuleSchedule{Windows: []WeeklyWindow{{Days: []time.Weekday{time.Monday}, Start: "18:00", End: "24:00"}}}, utc(3, 0, 0), false},
+		{"全天", RuleSchedule{Windows: []WeeklyWindow{{Days: []time.Weekday{time.Monday}, Start: "00:00", End: "24:00"}}}, utc(2, 0, 0), true},
+		{"多个时段任一命中", RuleSchedule{Windows: []WeeklyWindow{{Start: "01:00", End: "02:00"}, {Start: "12:00", End: "13:00"}}}, utc(2, 12, 30), true},
+
+		{"时区：本地周一凌晨对应 UTC 周日", RuleSchedule{Timezone: "Asia/Shanghai", Windows: []WeeklyWindow{{Days: []time.Weekday{time.Monday}, Start: "00:00", End: "08:00"}}}, utc(1, 16, 30), true},
+		{"时区：UTC 周一不在本地时段内", RuleSchedule{Timezone: "Asia/Shanghai", Windows: []WeeklyWindow{{Days: []time.Weekday{time.Monday}, Start: "00:00", End: "08:00"}}}, utc(2, 0, 30), false},
+		{"时区：本地跨午夜", RuleSchedule{Timezone: "Asia/Shanghai", Windows: []WeeklyWindow{{Days: []time.Weekday{time.Sunday}, Start: "22:00", End: "02:00"}}}, local(2, 1, 59), true},
+		{"时区：本地跨午夜结束", RuleSchedule{Timezone: "Asia/Shanghai", Windows: []WeeklyWindow{{Days: []time.Weekday{time.Sunday}, Start: "22:00", End: "02:00"}}}, local(2, 2, 0), false},
+
+		{"时段内但已过期", RuleSchedule{ActiveUntil: &until, Windows: []WeeklyWindow{{Start: "00:00", End: "24:00"}}}, until, false},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			compiled, err := tt.schedule.Compile()
+			if err != nil {
+				t.Fatalf("Compile() error = %v", err)
+			}
+			if got := compiled.ActiveAt(tt.at); got != tt.want {
+				t.Errorf("ActiveAt(%v) = %v, want %v", tt.at, got, tt.want)
+			}
+		})
+	}
+}
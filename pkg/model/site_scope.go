@@ -0,0 +1,42 @@
+package model
+
+import "strings"
+
+// SiteScope 站点作用域
+// @Description 规则或IP组的生效范围，站点ID或主机名模式任一命中即生效，两者均为空表示对所有站点生效
+type SiteScope struct {
+	SiteIDs []string `bson:"site_ids,omitempty" json:"siteIds,omitempty" example:"60d21b4367d0d8992e89e964"` // 站点ID列表
+	Hosts   []string `bson:"hosts,omitempty" json:"hosts,omitempty" example:"*.example.com"`                 // 主机名模式列表，支持 *.example.com 形式的通配符
+}
+
+// IsGlobal 是否对所有站点生效
+func (s *SiteScope) IsGlobal() bool {
+	return s == nil || (len(s.SiteIDs) == 0 && len(s.Hosts) == 0)
+}
+
+// MatchHostPattern 判断主机名是否匹配模式，不区分大小写
+// "*.example.com" 匹配任意层级的子域名，但不匹配 example.com 本身
+func MatchHostPattern(pattern, host string) bool {
+	pattern = strings.ToLower(strings.TrimSpace(pattern))
+	host = strings.ToLower(host)
+	if suffix, ok := strings.CutPrefix(pattern, "*."); ok {
+		return strings.HasSuffix(host, "."+suffix)
+	}
+	return host == pattern
+}
+
+// HostPatternCandidates 返回可能匹配该主机名的所有模式，用于数据库 $in 查询
+// 例如 a.b.example.com -> [a.b.example.com, *.b.example.com, *.example.com, *.com]
+func HostPatternCandidates(host string) []string {
+	host = strings.ToLower(host)
+	candidates := []string{host}
+	for i := strings.IndexByte(host, '.'); i != -1; {
+		candidates = append(candidates, "*"+host[i:])
+		next := strings.IndexByte(host[i+1:], '.')
+		if next == -1 {
+			break
+		}
+		i += next + 1
+	}
+	return candidates
+}
@@ -0,0 +1,73 @@
+package model
+
+import (
+	"slices"
+	"testing"
+)
+
+// TestMatchHostPattern 测试精确匹配、通配符匹配和大小写不敏感
+func TestMatchHostPattern(t *testing.T) {
+	for _, tt := range []struct {
+		pattern string
+		host    string
+		want    bool
+	}{
+		{"example.com", "example.com", true},
+		{"example.com", "EXAMPLE.com", true},
+		{" Example.COM ", "example.com", true},
+		{"example.com", "www.example.com", false},
+		{"example.com", "example.com.cn", false},
+		{"*.example.com", "www.example.com", true},
+		{"*.example.com", "a.b.example.com", true},
+		{"*.Example.com", "WWW.EXAMPLE.COM", true},
+		{"*.example.com", "example.com", false},
+		{"*.example.com", "badexample.com", false},
+		{"*.example.com", "www.example.com.evil.net", false},
+		{"www.*.com", "www.example.com", false},
+		{"*.example.com", "www.example.com:8443", false},
+	} {
+		if got := MatchHostPattern(tt.pattern, tt.host); got != tt.want {
+			t.Errorf("MatchHostPattern(%q, %q) = %v, want %v", tt.pattern, tt.host, got, tt.want)
+		}
+	}
+}
+
+// TestHostPatternCandidates 测试候选模式覆盖主机名本身和所有上级通配符，且与 MatchHostPattern 的结果一致
+func TestHostPatternCandidates(t *testing.T) {
+	for _, tt := range []struct {
+		host string
+		want []string
+	}{
+		{"localhost", []string{"localhost"}},
+		{"example.com", []string{"example.com", "*.com"}},
+		{"A.B.Example.com", []string{"a.b.example.com", "*.b.example.com", "*.example.com", "*.com"}},
+	} {
+		got := HostPatternCandidates(tt.host)
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("HostPatternCandidates(%q) = %v, want %v", tt.host, got, tt.want)
+		}
+		for _, pattern := range got {
+			if !MatchHostPattern(pattern, tt.host) {
+				t.Errorf("candidate %q of %q does not match it", pattern, tt.host)
+			}
+		}
+	}
+
+	host := "api.shop.example.com"
+	for _, pattern := range []string{"api.shop.example.com", "*.shop.example.com", "*.example.com", "shop.example.com", "*.api.shop.example.com", "*.other.com"} {
+		if MatchHostPattern(pattern, host) != slices.Contains(HostPatternCandidates(host), pattern) {
+			t.Errorf("pattern %q: MatchHostPattern and HostPatternCandidates disagree for %q", pattern, host)
+		}
+	}
+}
+
+// TestSiteScopeIsGlobal 测试作用域为空或未设置时对所有站点生效
+func TestSiteScopeIsGlobal(t *testing.T) {
+	var nilScope *SiteScope
+	if !nilScope.IsGlobal() || !(&SiteScope{}).IsGlobal() {
+		t.Error("nil or empty scope should be global")
+	}
+	if (&SiteScope{Hosts: []string{"*.example.com"}}).IsGlobal() || (&SiteScope{SiteIDs: []string{"1"}}).IsGlobal() {
+		t.Error("scope with hosts or site ids should not be global")
+	}
+}
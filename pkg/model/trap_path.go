@@ -0,0 +1,71 @@
+package model
+
+import (
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// TrapMatchType 陷阱路径匹配方式
+type TrapMatchType string
+
+const (
+	TrapMatchExact  TrapMatchType = "exact"  // 路径完全相同
+	TrapMatchPrefix TrapMatchType = "prefix" // 路径以指定值开头
+)
+
+const (
+	BlockReasonHoneypotTrap  = "honeypot_trap" // 访问陷阱路径的封禁原因
+	DefaultTrapBlockDuration = 86400           // 默认封禁时长（秒）
+)
+
+// TrapPath 蜜罐陷阱路径
+// @Description 站点上不存在、只会被扫描器访问的路径，访问后立即封禁来源IP
+type TrapPath struct {
+	ID            bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty" example:"60d21b4367d0d8992e89e964"` // 唯一标识符
+	Name          string        `bson:"name" json:"name" example:"dotenv"`                                    // 名称
+	Path          string        `bson:"path" json:"path" example:"/.env"`                                     // 陷阱路径，不区分大小写
+	MatchType     TrapMatchType `bson:"match_type" json:"matchType" example:"exact"`                          // 匹配方式
+	Scope         *SiteScope    `bson:"scope,omitempty" json:"scope,omitempty"`                               // 站点作用域，为空表示对所有站点生效
+	BlockDuration int64         `bson:"block_duration" json:"blockDuration" example:"86400"`                  // 封禁时长（秒）
+	Decoy         *TrapDecoy    `bson:"decoy,omitempty" json:"decoy,omitempty"`                               // 诱饵响应，为空时返回 403
+	Enabled       bool          `bson:"enabled" json:"enabled" example:"true"`                                // 是否启用
+	CreatedAt     time.Time     `bson:"created_at" json:"createdAt"`                                          // 创建时间
+	UpdatedAt     time.Time     `bson:"updated_at" json:"updatedAt"`                                          // 更新时间
+}
+
+// TrapDecoy 诱饵响应
+// @Description 访问陷阱路径时返回的伪装响应，避免攻击者立即察觉已被封禁
+type TrapDecoy struct {
+	StatusCode int    `bson:"status_code" json:"statusCode" example:"200"`                            // 状态码，支持 200 和 404
+	Body       string `bson:"body" json:"body" example:"APP_KEY=base64:changeme\nDB_PASSWORD=secret"` // 响应内容，以 text/html 返回
+}
+
+// 支持的诱饵响应状态码，HAProxy 的 return 动作只接受固定状态码
+const (
+	TrapDecoyStatusOK       = 200
+	TrapDecoyStatusNotFound = 404
+)
+
+func (t *TrapPath) GetCollectionName() string {
+	return "trap_path"
+}
+
+// MatchPath 判断请求路径是否命中陷阱，不区分大小写
+func (t *TrapPath) MatchPath(path string) bool {
+	switch t.MatchType {
+	case TrapMatchPrefix:
+		return len(path) >= len(t.Path) && strings.EqualFold(path[:len(t.Path)], t.Path)
+	default:
+		return strings.EqualFold(path, t.Path)
+	}
+}
+
+// BlockDurationOrDefault 返回封禁时长，未设置时使用默认值
+func (t *TrapPath) BlockDurationOrDefault() time.Duration {
+	if t.BlockDuration <= 0 {
+		return DefaultTrapBlockDuration * time.Second
+	}
+	return time.Duration(t.BlockDuration) * time.Second
+}
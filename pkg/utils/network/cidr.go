@@ -0,0 +1,208 @@
+package network
+
+import (
+	"math/bits"
+	"net/netip"
+	"sort"
+	"strings"
+)
+
+// ParsePrefix 解析IP地址或CIDR，单个IP转换为 /32 或 /128，CIDR 的主机位会被清零
+// IPv4 映射的 IPv6 地址会被还原为 IPv4
+func ParsePrefix(s string) (netip.Prefix, bool) {
+	s = strings.TrimSpace(s)
+	if strings.Contains(s, "/") {
+		prefix, err := netip.ParsePrefix(s)
+		if err != nil {
+			return netip.Prefix{}, false
+		}
+		addr := prefix.Addr()
+		bitLen := prefix.Bits()
+		if addr.Is4In6() && bitLen >= 96 {
+			addr = addr.Unmap()
+			bitLen -= 96
+		}
+		return netip.PrefixFrom(addr.WithZone(""), bitLen).Masked(), true
+	}
+
+	addr, err := netip.ParseAddr(s)
+	if err != nil {
+		return netip.Prefix{}, false
+	}
+	addr = addr.Unmap().WithZone("")
+	return netip.PrefixFrom(addr, addr.BitLen()), true
+}
+
+// FormatPrefix 格式化前缀，单个主机地址输出为不带掩码的IP
+func FormatPrefix(prefix netip.Prefix) string {
+	if prefix.IsSingleIP() {
+		return prefix.Addr().String()
+	}
+	return prefix.String()
+}
+
+// CollapsePrefixes 合并重叠和相邻的前缀，返回覆盖相同地址集合的最少前缀列表
+// 结果按地址族（IPv4 在前）和起始地址排序
+func CollapsePrefixes(prefixes []netip.Prefix) []netip.Prefix {
+	var v4, v6 []addrRange
+	for _, prefix := range prefixes {
+		if !prefix.IsValid() {
+			continue
+		}
+		r := prefixRange(prefix.Masked())
+		if prefix.Addr().Is4() {
+			v4 = append(v4, r)
+		} else {
+			v6 = append(v6, r)
+		}
+	}
+
+	result := make([]netip.Prefix, 0, len(prefixes))
+	for _, r := range mergeRanges(v4) {
+		result = appendRangePrefixes(result, r, 32)
+	}
+	for _, r := range mergeRanges(v6) {
+		result = appendRangePrefixes(result, r, 128)
+	}
+	return result
+}
+
+// uint128 128位无符号整数，用于统一处理 IPv4 和 IPv6 地址运算
+type uint128 struct {
+	hi, lo uint64
+}
+
+func (u uint128) cmp(v uint128) int {
+	switch {
+	case u.hi < v.hi:
+		return -1
+	case u.hi > v.hi:
+		return 1
+	case u.lo < v.lo:
+		return -1
+	case u.lo > v.lo:
+		return 1
+	default:
+		return 0
+	}
+}
+
+func (u uint128) addOne() (uint128, bool) {
+	lo, carry := bits.Add64(u.lo, 1, 0)
+	hi, overflow := bits.Add64(u.hi, 0, carry)
+	return uint128{hi, lo}, overflow != 0
+}
+
+// trailingZeros 末尾零位数，0 返回 128
+func (u uint128) trailingZeros() int {
+	if u.lo != 0 {
+		return bits.TrailingZeros64(u.lo)
+	}
+	return 64 + bits.TrailingZeros64(u.hi)
+}
+
+// hostMask 低 n 位为 1 的掩码
+func hostMask(n int) uint128 {
+	switch {
+	case n <= 0:
+		return uint128{}
+	case n < 64:
+		return uint128{0, 1<<uint(n) - 1}
+	case n < 128:
+		return uint128{1<<uint(n-64) - 1, ^uint64(0)}
+	default:
+		return uint128{^uint64(0), ^uint64(0)}
+	}
+}
+
+func (u uint128) or(v uint128) uint128 {
+	return uint128{u.hi | v.hi, u.lo | v.lo}
+}
+
+func addrToUint128(addr netip.Addr) uint128 {
+	if addr.Is4() {
+		b := addr.As4()
+		return uint128{0, uint64(b[0])<<24 | uint64(b[1])<<16 | uint64(b[2])<<8 | uint64(b[3])}
+	}
+	b := addr.As16()
+	var u uint128
+	for i := 0; i < 8; i++ {
+		u.hi = u.hi<<8 | uint64(b[i])
+		u.lo = u.lo<<8 | uint64(b[i+8])
+	}
+	return u
+}
+
+func uint128ToAddr(u uint128, bitLen int) netip.Addr {
+	if bitLen == 32 {
+		return netip.AddrFrom4([4]byte{byte(u.lo >> 24), byte(u.lo >> 16), byte(u.lo >> 8), byte(u.lo)})
+	}
+	var b [16]byte
+	for i := 7; i >= 0; i-- {
+		b[i] = byte(u.hi)
+		b[i+8] = byte(u.lo)
+		u.hi >>= 8
+		u.lo >>= 8
+	}
+	return netip.AddrFrom16(b)
+}
+
+// addrRange 闭区间地址范围
+type addrRange struct {
+	start, end uint128
+}
+
+func prefixRange(prefix netip.Prefix) addrRange {
+	start := addrToUint128(prefix.Addr())
+	return addrRange{start: start, end: start.or(hostMask(prefix.Addr().BitLen() - prefix.Bits()))}
+}
+
+// mergeRanges 合并重叠和相邻的范围
+func mergeRanges(ranges []addrRange) []addrRange {
+	if len(ranges) == 0 {
+		return nil
+	}
+
+	sort.Slice(ranges, func(i, j int) bool {
+		return ranges[i].start.cmp(ranges[j].start) < 0
+	})
+
+	merged := []addrRange{ranges[0]}
+	for _, r := range ranges[1:] {
+		last := &merged[len(merged)-1]
+		next, overflow := last.end.addOne()
+		if overflow || r.start.cmp(next) <= 0 {
+			// 重叠或相邻
+			if r.end.cmp(last.end) > 0 {
+				last.end = r.end
+			}
+			continue
+		}
+		merged = append(merged, r)
+	}
+	return merged
+}
+
+// appendRangePrefixes 将范围拆分为最少的前缀
+func appendRangePrefixes(dst []netip.Prefix, r addrRange, bitLen int) []netip.Prefix {
+	start := r.start
+	for {
+		// 起始地址对齐允许的最大主机位数
+		hostBits := start.trailingZeros()
+		if hostBits > bitLen {
+			hostBits = bitLen
+		}
+		// 缩小到不超过范围结束地址
+		for hostBits > 0 && start.or(hostMask(hostBits)).cmp(r.end) > 0 {
+			hostBits--
+		}
+
+		dst = append(dst, netip.PrefixFrom(uint128ToAddr(start, bitLen), bitLen-hostBits))
+
+		last := start.or(hostMask(hostBits))
+		if last.cmp(r.end) >= 0 {
+			return dst
+		}
+		start, _ = last.addOne()
+	}
+}
@@ -0,0 +1,172 @@
+package network
+
+import (
+	"math/rand"
+	"net/netip"
+	"slices"
+	"testing"
+)
+
+// TestParsePrefix 测试单个IP、CIDR、IPv4 映射的 IPv6 地址和无效输入的解析
+func TestParsePrefix(t *testing.T) {
+	for _, tt := range []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"10.0.0.1", "10.0.0.1/32", true},
+		{" 10.0.0.1 ", "10.0.0.1/32", true},
+		{"10.0.0.1/24", "10.0.0.0/24", true},
+		{"10.0.0.1/32", "10.0.0.1/32", true},
+		{"0.0.0.0/0", "0.0.0.0/0", true},
+		{"2001:db8::1", "2001:db8::1/128", true},
+		{"2001:db8::1/32", "2001:db8::/32", true},
+		{"2001:db8::1/128", "2001:db8::1/128", true},
+		{"::/0", "::/0", true},
+		{"fe80::1%eth0", "fe80::1/128", true},
+		{"::ffff:10.0.0.1", "10.0.0.1/32", true},
+		{"::ffff:10.0.0.1/128", "10.0.0.1/32", true},
+		{"::ffff:10.0.0.0/104", "10.0.0.0/8", true},
+		{"::ffff:0.0.0.0/96", "0.0.0.0/0", true},
+		{"::ffff:0.0.0.0/95", "::fffe:0:0/95", true},
+		{"10.0.0.0/33", "", false},
+		{"2001:db8::/129", "", false},
+		{"10.0.0", "", false},
+		{"bad", "", false},
+		{"", "", false},
+	} {
+		prefix, ok := ParsePrefix(tt.input)
+		if ok != tt.ok {
+			t.Errorf("ParsePrefix(%q) ok = %v, want %v", tt.input, ok, tt.ok)
+			continue
+		}
+		if ok && prefix.String() != tt.want {
+			t.Errorf("ParsePrefix(%q) = %s, want %s", tt.input, prefix, tt.want)
+		}
+	}
+}
+
+// TestFormatPrefix 测试单个主机地址不带掩码输出
+func TestFormatPrefix(t *testing.T) {
+	for input, want := range map[string]string{
+		"10.0.0.1/32":     "10.0.0.1",
+		"10.0.0.0/24":     "10.0.0.0/24",
+		"2001:db8::1/128": "2001:db8::1",
+		"2001:db8::/32":   "2001:db8::/32",
+		"0.0.0.0/0":       "0.0.0.0/0",
+	} {
+		if got := FormatPrefix(netip.MustParsePrefix(input)); got != want {
+			t.Errorf("FormatPrefix(%s) = %s, want %s", input, got, want)
+		}
+	}
+}
+
+// TestCollapsePrefixes 测试重叠、相邻、不对齐的范围，/0、/32、/128 边界，以及 IPv4 和 IPv6 混合输入
+func TestCollapsePrefixes(t *testing.T) {
+	for _, tt := range []struct {
+		name  string
+		input []string
+		want  []string
+	}{
+		{"空输入", nil, []string{}},
+		{"重复", []string{"10.0.0.1", "10.0.0.1"}, []string{"10.0.0.1/32"}},
+		{"包含", []string{"10.0.0.0/24", "10.0.0.128/25", "10.0.0.7"}, []string{"10.0.0.0/24"}},
+		{"部分重叠", []string{"10.0.0.0/25", "10.0.0.0/24", "10.0.1.0/25"}, []string{"10.0.0.0/24", "10.0.1.0/25"}},
+		{"相邻对齐", []string{"10.0.0.128/25", "10.0.0.0/25"}, []string{"10.0.0.0/24"}},
+		{"相邻多级合并", []string{"10.0.0.0/26", "10.0.0.64/26", "10.0.0.128/25", "10.0.1.0/24"}, []string{"10.0.0.0/23"}},
+		{"相邻不对齐", []string{"10.0.0.1", "10.0.0.2"}, []string{"10.0.0.1/32", "10.0.0.2/32"}},
+		{"不对齐范围拆分", []string{"10.0.0.1", "10.0.0.2/31", "10.0.0.4/30", "10.0.0.8"}, []string{"10.0.0.1/32", "10.0.0.2/31", "10.0.0.4/30", "10.0.0.8/32"}},
+		{"不相邻", []string{"10.0.0.0/25", "10.0.1.0/25"}, []string{"10.0.0.0/25", "10.0.1.0/25"}},
+		{"IPv4 全部地址", []string{"10.0.0.0/8", "0.0.0.0/0", "2001:db8::/32"}, []string{"0.0.0.0/0", "2001:db8::/32"}},
+		{"IPv4 两半合并为 /0", []string{"128.0.0.0/1", "0.0.0.0/1"}, []string{"0.0.0.0/0"}},
+		{"IPv4 最大地址", []string{"255.255.255.255", "255.255.255.254"}, []string{"255.255.255.254/31"}},
+		{"IPv4 最大地址相邻", []string{"255.255.255.0/25", "255.255.255.128/25"}, []string{"255.255.255.0/24"}},
+		{"IPv6 全部地址", []string{"::/0", "2001:db8::1", "::1"}, []string{"::/0"}},
+		{"IPv6 两半合并为 /0", []string{"8000::/1", "::/1"}, []string{"::/0"}},
+		{"IPv6 最大地址", []string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:ffff", "ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe"}, []string{"ffff:ffff:ffff:ffff:ffff:ffff:ffff:fffe/127"}},
+		{"IPv6 跨 64 位边界", []string{"2001:db8::/65", "2001:db8:0:0:8000::/65"}, []string{"2001:db8::/64"}},
+		{"IPv6 /128 相邻", []string{"2001:db8::", "2001:db8::1", "2001:db8::2"}, []string{"2001:db8::/127", "2001:db8::2/128"}},
+		{"IPv4 在前", []string{"2001:db8::/32", "10.0.0.0/8", "::1", "1.1.1.1"}, []string{"1.1.1.1/32", "10.0.0.0/8", "::1/128", "2001:db8::/32"}},
+		{"IPv4 映射地址与 IPv4 合并", []string{"::ffff:10.0.0.0/121", "10.0.0.128/25"}, []string{"10.0.0.0/24"}},
+		{"IPv4 映射地址保留为 IPv6", []string{"::ffff:0.0.0.0/95", "10.0.0.0/8"}, []string{"10.0.0.0/8", "::fffe:0:0/95"}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			var prefixes []netip.Prefix
+			for _, item := range tt.input {
+				prefix, ok := ParsePrefix(item)
+				if !ok {
+					t.Fatalf("ParsePrefix(%q) failed", item)
+				}
+				prefixes = append(prefixes, prefix)
+			}
+			var got []string
+			for _, prefix := range CollapsePrefixes(prefixes) {
+				got = append(got, prefix.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("CollapsePrefixes(%v) = %v, want %v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestCollapsePrefixesRandom 随机生成前缀，检查合并结果覆盖相同的地址、互不重叠且不能再合并
+func TestCollapsePrefixesRandom(t *testing.T) {
+	rng := rand.New(rand.NewSource(20250601))
+	randomAddr := func(is4 bool) netip.Addr {
+		if is4 {
+			// 集中在较小的地址空间，使前缀经常重叠和相邻
+			return netip.AddrFrom4([4]byte{10, 0, byte(rng.Intn(4)), byte(rng.Intn(256))})
+		}
+		var b [16]byte
+		b[0], b[1], b[7], b[8], b[15] = 0x20, 0x01, byte(rng.Intn(2)), byte(rng.Intn(256)), byte(rng.Intn(256))
+		return netip.AddrFrom16(b)
+	}
+
+	for range 500 {
+		var input []netip.Prefix
+		for range 1 + rng.Intn(12) {
+			is4 := rng.Intn(2) == 0
+			addr := randomAddr(is4)
+			minBits := 20
+			if !is4 {
+				minBits = 56
+			}
+			bits := minBits + rng.Intn(addr.BitLen()-minBits+1)
+			input = append(input, netip.PrefixFrom(addr, bits).Masked())
+		}
+		collapsed := CollapsePrefixes(slices.Clone(input))
+
+		contains := func(prefixes []netip.Prefix, addr netip.Addr) bool {
+			return slices.ContainsFunc(prefixes, func(p netip.Prefix) bool { return p.Contains(addr) })
+		}
+		for range 200 {
+			addr := randomAddr(rng.Intn(2) == 0)
+			if contains(input, addr) != contains(collapsed, addr) {
+				t.Fatalf("CollapsePrefixes(%v) = %v: coverage differs at %s", input, collapsed, addr)
+			}
+		}
+		for _, prefix := range input {
+			if !contains(collapsed, prefix.Addr()) {
+				t.Fatalf("CollapsePrefixes(%v) = %v: %s not covered", input, collapsed, prefix)
+			}
+		}
+		for i, a := range collapsed {
+			if a != a.Masked() {
+				t.Fatalf("CollapsePrefixes(%v) returned unmasked prefix %s", input, a)
+			}
+			for _, b := range collapsed[i+1:] {
+				if a.Overlaps(b) {
+					t.Fatalf("CollapsePrefixes(%v) = %v: %s overlaps %s", input, collapsed, a, b)
+				}
+				// 两个兄弟前缀可以合并为上一级前缀
+				if a.Bits() == b.Bits() && a.Bits() > 0 {
+					parent := netip.PrefixFrom(a.Addr(), a.Bits()-1).Masked()
+					if parent.Contains(b.Addr()) {
+						t.Fatalf("CollapsePrefixes(%v) = %v: %s and %s can be merged", input, collapsed, a, b)
+					}
+				}
+			}
+		}
+	}
+}
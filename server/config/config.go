@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"strconv"
+	"strings"
 	"time"
 
 	mongodb "github.com/HUAHUAI23/RuiQi/pkg/database/mongo"
@@ -29,6 +30,8 @@ type Config struct {
 	Log          LogConfig
 	DBConfig     DBConfig
 	JWT          JWTConfig
+	ACME         ACMEConfig
+	Notify       NotifyConfig
 }
 
 // DBConfig 数据库配置
@@ -43,6 +46,27 @@ type JWTConfig struct {
 	ExpirationHrs int
 }
 
+// ACMEConfig ACME 自动证书配置
+type ACMEConfig struct {
+	DirectoryURL   string        // ACME 服务目录地址
+	Email          string        // 账户联系邮箱，可以为空
+	CAFile         string        // 额外信任的 CA 证书文件，用于访问使用自签名证书的 ACME 服务，如 Pebble
+	RenewBefore    time.Duration // 证书到期前多久开始续期
+	DNSProvider    string        // DNS-01 验证使用的 DNS 服务商
+	DNSEndpoint    string        // DNS 服务商接口地址
+	DNSPropagation time.Duration // 写入 TXT 记录后等待 DNS 生效的时间
+}
+
+// NotifyConfig 告警通知配置，Webhook 和 SMTP 都未配置时只记录日志
+type NotifyConfig struct {
+	WebhookURL   string   // Webhook 地址，通知以 JSON 格式 POST 到该地址
+	SMTPAddr     string   // SMTP 服务器地址，如 smtp.example.com:587
+	SMTPUsername string   // SMTP 用户名，为空时不认证
+	SMTPPassword string   // SMTP 密码
+	SMTPFrom     string   // 发件人地址
+	SMTPTo       []string // 收件人地址
+}
+
 // InitConfig 从环境变量初始化配置
 func InitConfig() error {
 	// 加载.env文件
@@ -72,6 +96,11 @@ func InitConfig() error {
 			Secret:        "default-jwt-secret-key",
 			ExpirationHrs: 24,
 		},
+		ACME: ACMEConfig{
+			DirectoryURL:   "https://acme-v02.api.letsencrypt.org/directory",
+			RenewBefore:    30 * 24 * time.Hour,
+			DNSPropagation: 30 * time.Second,
+		},
 	}
 
 	// 从环境变量加载配置
@@ -124,6 +153,57 @@ func InitConfig() error {
 		Global.WebPath = env
 	}
 
+	// ACME配置
+	if env := os.Getenv("ACME_DIRECTORY_URL"); env != "" {
+		Global.ACME.DirectoryURL = env
+	}
+	if env := os.Getenv("ACME_EMAIL"); env != "" {
+		Global.ACME.Email = env
+	}
+	if env := os.Getenv("ACME_CA_FILE"); env != "" {
+		Global.ACME.CAFile = env
+	}
+	if env := os.Getenv("ACME_RENEW_BEFORE_DAYS"); env != "" {
+		if days, err := strconv.Atoi(env); err == nil && days > 0 {
+			Global.ACME.RenewBefore = time.Duration(days) * 24 * time.Hour
+		}
+	}
+	if env := os.Getenv("ACME_DNS_PROVIDER"); env != "" {
+		Global.ACME.DNSProvider = env
+	}
+	if env := os.Getenv("ACME_DNS_ENDPOINT"); env != "" {
+		Global.ACME.DNSEndpoint = env
+	}
+	if env := os.Getenv("ACME_DNS_PROPAGATION_SECONDS"); env != "" {
+		if seconds, err := strconv.Atoi(env); err == nil && seconds >= 0 {
+			Global.ACME.DNSPropagation = time.Duration(seconds) * time.Second
+		}
+	}
+
+	// 通知配置
+	if env := os.Getenv("NOTIFY_WEBHOOK_URL"); env != "" {
+		Global.Notify.WebhookURL = env
+	}
+	if env := os.Getenv("NOTIFY_SMTP_ADDR"); env != "" {
+		Global.Notify.SMTPAddr = env
+	}
+	if env := os.Getenv("NOTIFY_SMTP_USERNAME"); env != "" {
+		Global.Notify.SMTPUsername = env
+	}
+	if env := os.Getenv("NOTIFY_SMTP_PASSWORD"); env != "" {
+		Global.Notify.SMTPPassword = env
+	}
+	if env := os.Getenv("NOTIFY_SMTP_FROM"); env != "" {
+		Global.Notify.SMTPFrom = env
+	}
+	if env := os.Getenv("NOTIFY_SMTP_TO"); env != "" {
+		for _, to := range strings.Split(env, ",") {
+			if to = strings.TrimSpace(to); to != "" {
+				Global.Notify.SMTPTo = append(Global.Notify.SMTPTo, to)
+			}
+		}
+	}
+
 	// 初始化JWT
 	err = jwt.InitJWTSecret(Global.JWT.Secret)
 	if err != nil {
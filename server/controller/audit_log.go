@@ -0,0 +1,69 @@
+package controller
+
+import (
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/service"
+	"github.com/HUAHUAI23/RuiQi/server/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+)
+
+// AuditLogController 审计日志控制器接口
+type AuditLogController interface {
+	GetAuditLogs(ctx *gin.Context)
+}
+
+// AuditLogControllerImpl 审计日志控制器实现
+type AuditLogControllerImpl struct {
+	auditLogService service.AuditLogService
+	logger          zerolog.Logger
+}
+
+// NewAuditLogController 创建审计日志控制器
+func NewAuditLogController(auditLogService service.AuditLogService) AuditLogController {
+	logger := config.GetControllerLogger("audit_log")
+	return &AuditLogControllerImpl{
+		auditLogService: auditLogService,
+		logger:          logger,
+	}
+}
+
+// GetAuditLogs 获取审计日志列表
+//
+//	@Summary		获取审计日志列表
+//	@Description	获取配置变更的审计日志，包括定时任务自动清理过期规则和IP组条目的记录，按操作时间倒序
+//	@Tags			审计日志
+//	@Produce		json
+//	@Param			page			query	int		false	"页码，从1开始"		default(1)	minimum(1)
+//	@Param			size			query	int		false	"每页数量，最大100"	default(10)	minimum(1)	maximum(100)
+//	@Param			action			query	string	false	"操作类型过滤"		example(expire)
+//	@Param			resourceType	query	string	false	"资源类型过滤"		example(ip_group)
+//	@Param			resourceId		query	string	false	"资源ID过滤"
+//	@Param			operator		query	string	false	"操作人过滤"		example(system)
+//	@Param			startTime		query	string	false	"查询起始时间 (ISO8601格式，如: 2024-03-17T00:00:00Z)"
+//	@Param			endTime			query	string	false	"查询结束时间 (ISO8601格式，如: 2024-03-18T23:59:59Z)"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.AuditLogListResponse}	"获取审计日志成功"
+//	@Failure		400	{object}	model.ErrResponse										"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError							"未授权访问"
+//	@Failure		403	{object}	model.ErrResponseDontShowError							"禁止访问"
+//	@Failure		500	{object}	model.ErrResponseDontShowError							"服务器内部错误"
+//	@Router			/api/v1/audit [get]
+func (c *AuditLogControllerImpl) GetAuditLogs(ctx *gin.Context) {
+	var req dto.AuditLogListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.logger.Warn().Err(err).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	result, err := c.auditLogService.GetAuditLogs(ctx, &req)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("获取审计日志失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "获取审计日志成功", result)
+}
@@ -21,6 +21,9 @@ type CertificateController interface {
 	GetCertificateByID(ctx *gin.Context)
 	UpdateCertificate(ctx *gin.Context)
 	DeleteCertificate(ctx *gin.Context)
+	IssueACMECertificate(ctx *gin.Context)
+	RenewCertificate(ctx *gin.Context)
+	GetExpirySummary(ctx *gin.Context)
 }
 
 // CertificateControllerImpl 证书控制器实现
@@ -41,7 +44,9 @@ func NewCertificateController(certService service.CertificateService) Certificat
 // CreateCertificate 创建证书
 //
 //	@Summary		创建新证书
-//	@Description	创建一个新的SSL/TLS证书
+//	@Description	创建一个新的SSL/TLS证书，服务端解析证书获取过期日期、颁发机构、域名和 SHA-256 指纹。
+//	@Description	证书链需按站点证书、中间证书的顺序排列且完整，私钥需与证书匹配，支持 2048 位及以上的 RSA 和 P-256、P-384 的 ECDSA 密钥；加密的 PKCS#8 私钥需提供密码。
+//	@Description	未指定证书的 HTTPS 站点会按域名自动选择包含其所有域名且过期时间最晚的证书
 //	@Tags			证书管理
 //	@Accept			json
 //	@Produce		json
@@ -71,6 +76,9 @@ func (c *CertificateControllerImpl) CreateCertificate(ctx *gin.Context) {
 		} else if errors.Is(err, service.ErrInvalidCertificate) {
 			response.BadRequest(ctx, err, true)
 			return
+		} else if errors.Is(err, service.ErrCertificateApplyFailed) {
+			response.InternalServerError(ctx, err, true)
+			return
 		}
 		c.logger.Error().Err(err).Msg("创建证书失败")
 		response.InternalServerError(ctx, err, false)
@@ -88,6 +96,8 @@ func (c *CertificateControllerImpl) CreateCertificate(ctx *gin.Context) {
 		IssuerName:  cert.IssuerName,
 		FingerPrint: cert.FingerPrint,
 		Domains:     cert.Domains,
+		Source:      cert.Source,
+		ACME:        cert.ACME,
 		CreatedAt:   cert.CreatedAt,
 		UpdatedAt:   cert.UpdatedAt,
 	}
@@ -99,7 +109,7 @@ func (c *CertificateControllerImpl) CreateCertificate(ctx *gin.Context) {
 // GetCertificates 获取证书列表
 //
 //	@Summary		获取证书列表
-//	@Description	获取所有SSL/TLS证书列表，支持分页
+//	@Description	获取所有SSL/TLS证书列表，支持分页。expiryState 为证书的过期状态：ok、expiring30、expiring14、expiring7 或 expired
 //	@Tags			证书管理
 //	@Produce		json
 //	@Param			page	query	int	false	"页码"	default(1)
@@ -174,6 +184,9 @@ func (c *CertificateControllerImpl) GetCertificateByID(ctx *gin.Context) {
 		IssuerName:  cert.IssuerName,
 		FingerPrint: cert.FingerPrint,
 		Domains:     cert.Domains,
+		Source:      cert.Source,
+		ACME:        cert.ACME,
+		ExpiryState: cert.ExpiryState,
 		CreatedAt:   cert.CreatedAt,
 		UpdatedAt:   cert.UpdatedAt,
 	}
@@ -185,7 +198,8 @@ func (c *CertificateControllerImpl) GetCertificateByID(ctx *gin.Context) {
 // UpdateCertificate 更新证书
 //
 //	@Summary		更新证书
-//	@Description	更新指定证书的信息
+//	@Description	更新指定证书的信息，更新证书链或私钥时重新解析和校验，校验规则与创建证书相同；手动替换 ACME 证书的内容后不再自动续期。
+//	@Description	使用该证书的站点同步更新，HAProxy 运行中时通过运行时 API 热加载
 //	@Tags			证书管理
 //	@Accept			json
 //	@Produce		json
@@ -227,6 +241,9 @@ func (c *CertificateControllerImpl) UpdateCertificate(ctx *gin.Context) {
 		} else if errors.Is(err, service.ErrInvalidCertificate) {
 			response.BadRequest(ctx, err, true)
 			return
+		} else if errors.Is(err, service.ErrCertificateApplyFailed) {
+			response.InternalServerError(ctx, err, true)
+			return
 		}
 		c.logger.Error().Err(err).Str("id", id).Msg("更新证书失败")
 		response.InternalServerError(ctx, err, false)
@@ -244,6 +261,8 @@ func (c *CertificateControllerImpl) UpdateCertificate(ctx *gin.Context) {
 		IssuerName:  cert.IssuerName,
 		FingerPrint: cert.FingerPrint,
 		Domains:     cert.Domains,
+		Source:      cert.Source,
+		ACME:        cert.ACME,
 		CreatedAt:   cert.CreatedAt,
 		UpdatedAt:   cert.UpdatedAt,
 	}
@@ -257,7 +276,7 @@ func (c *CertificateControllerImpl) UpdateCertificate(ctx *gin.Context) {
 // DeleteCertificate 删除证书
 //
 //	@Summary		删除证书
-//	@Description	删除指定的SSL/TLS证书
+//	@Description	删除指定的SSL/TLS证书，指定了该证书或只能使用该证书的站点存在时不能删除
 //	@Tags			证书管理
 //	@Produce		json
 //	@Param			id	path	string	true	"证书ID"
@@ -266,6 +285,7 @@ func (c *CertificateControllerImpl) UpdateCertificate(ctx *gin.Context) {
 //	@Failure		401	{object}	model.ErrResponseDontShowError	"未授权访问"
 //	@Failure		403	{object}	model.ErrResponseDontShowError	"禁止访问"
 //	@Failure		404	{object}	model.ErrResponseDontShowError	"证书不存在"
+//	@Failure		409	{object}	model.ErrResponse				"证书正在被站点使用"
 //	@Failure		500	{object}	model.ErrResponseDontShowError	"服务器内部错误"
 //	@Router			/api/v1/certificates/{id} [delete]
 func (c *CertificateControllerImpl) DeleteCertificate(ctx *gin.Context) {
@@ -283,6 +303,9 @@ func (c *CertificateControllerImpl) DeleteCertificate(ctx *gin.Context) {
 		if errors.Is(err, service.ErrCertificateNotFound) {
 			response.NotFound(ctx, err)
 			return
+		} else if errors.Is(err, service.ErrCertificateInUse) {
+			response.Error(ctx, model.NewAPIError(http.StatusConflict, "证书正在被站点使用", err), true)
+			return
 		}
 		c.logger.Error().Err(err).Str("id", id).Msg("删除证书失败")
 		response.InternalServerError(ctx, err, false)
@@ -292,3 +315,120 @@ func (c *CertificateControllerImpl) DeleteCertificate(ctx *gin.Context) {
 	c.logger.Info().Str("id", id).Msg("证书删除成功")
 	response.Success(ctx, "证书删除成功", nil)
 }
+
+// IssueACMECertificate 通过 ACME 签发证书
+//
+//	@Summary		通过 ACME 签发证书
+//	@Description	通过 ACME 为指定域名签发证书并保存到证书库，签发完成后返回，证书在到期前自动续期。
+//	@Description	HTTP-01 验证要求域名解析到 WAF 且 80 端口上配置了站点，验证请求由 HAProxy 转发到管理服务；通配符域名只能使用 DNS-01 验证，需要配置 DNS 服务商
+//	@Tags			证书管理
+//	@Accept			json
+//	@Produce		json
+//	@Param			certificate	body	dto.ACMECertificateRequest	true	"签发参数"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=model.CertificateStore}	"证书签发成功"
+//	@Failure		400	{object}	model.ErrResponse									"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError						"未授权访问"
+//	@Failure		403	{object}	model.ErrResponseDontShowError						"禁止访问"
+//	@Failure		409	{object}	model.ErrResponseDontShowError						"证书名称已存在"
+//	@Failure		500	{object}	model.ErrResponse									"证书签发失败"
+//	@Router			/api/v1/certificates/acme [post]
+func (c *CertificateControllerImpl) IssueACMECertificate(ctx *gin.Context) {
+	var req dto.ACMECertificateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Warn().Err(err).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	c.logger.Info().Strs("domains", req.Domains).Str("challengeType", string(req.ChallengeType)).Msg("ACME 签发证书请求")
+	cert, err := c.certService.IssueACMECertificate(ctx, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrCertificateNameExists) {
+			response.Error(ctx, model.NewAPIError(http.StatusConflict, "证书名称已存在", err), false)
+			return
+		} else if errors.Is(err, service.ErrInvalidACMERequest) {
+			response.BadRequest(ctx, err, true)
+			return
+		} else if errors.Is(err, service.ErrACMEIssueFailed) || errors.Is(err, service.ErrCertificateApplyFailed) {
+			response.InternalServerError(ctx, err, true)
+			return
+		}
+		c.logger.Error().Err(err).Msg("ACME 签发证书失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	c.logger.Info().Str("id", cert.ID.Hex()).Str("name", cert.Name).Msg("ACME 证书签发成功")
+	response.Success(ctx, "证书签发成功", cert)
+}
+
+// RenewCertificate 立即续期 ACME 证书
+//
+//	@Summary		立即续期 ACME 证书
+//	@Description	立即重新签发 ACME 证书，使用该证书的站点同步更新，HAProxy 运行中时通过运行时 API 热加载，不重新加载配置
+//	@Tags			证书管理
+//	@Produce		json
+//	@Param			id	path	string	true	"证书ID"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=model.CertificateStore}	"证书续期成功"
+//	@Failure		400	{object}	model.ErrResponse									"证书不是通过 ACME 签发的"
+//	@Failure		401	{object}	model.ErrResponseDontShowError						"未授权访问"
+//	@Failure		403	{object}	model.ErrResponseDontShowError						"禁止访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError						"证书不存在"
+//	@Failure		500	{object}	model.ErrResponse									"证书续期失败"
+//	@Router			/api/v1/certificates/{id}/renew [post]
+func (c *CertificateControllerImpl) RenewCertificate(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	c.logger.Info().Str("id", id).Msg("续期证书请求")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", id).Msg("无效的ID格式")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+	cert, err := c.certService.RenewCertificate(ctx, objectID)
+	if err != nil {
+		if errors.Is(err, service.ErrCertificateNotFound) {
+			response.NotFound(ctx, err)
+			return
+		} else if errors.Is(err, service.ErrNotACMECertificate) {
+			response.BadRequest(ctx, err, true)
+			return
+		} else if errors.Is(err, service.ErrACMEIssueFailed) {
+			response.InternalServerError(ctx, err, true)
+			return
+		}
+		c.logger.Error().Err(err).Str("id", id).Msg("续期证书失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	c.logger.Info().Str("id", id).Str("name", cert.Name).Msg("证书续期成功")
+	response.Success(ctx, "证书续期成功", cert)
+}
+
+// GetExpirySummary 获取证书过期概览
+//
+//	@Summary		获取证书过期概览
+//	@Description	统计证书库中的证书和仍在使用的站点内嵌证书在各过期状态（ok、expiring30、expiring14、expiring7、expired）的数量，
+//	@Description	并返回即将过期和已过期的证书，供仪表盘展示
+//	@Tags			证书管理
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.CertificateExpirySummary}	"获取证书过期概览成功"
+//	@Failure		401	{object}	model.ErrResponseDontShowError								"未授权访问"
+//	@Failure		403	{object}	model.ErrResponseDontShowError								"禁止访问"
+//	@Failure		500	{object}	model.ErrResponseDontShowError								"服务器内部错误"
+//	@Router			/api/v1/certificates/expiry-summary [get]
+func (c *CertificateControllerImpl) GetExpirySummary(ctx *gin.Context) {
+	summary, err := c.certService.GetExpirySummary(ctx)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("获取证书过期概览失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "获取证书过期概览成功", summary)
+}
@@ -3,7 +3,9 @@ package controller
 
 import (
 	"errors"
+	"mime/multipart"
 	"net/http"
+	"net/url"
 
 	"github.com/HUAHUAI23/RuiQi/server/config"
 	"github.com/HUAHUAI23/RuiQi/server/dto"
@@ -23,6 +25,11 @@ type IPGroupController interface {
 	UpdateIPGroup(ctx *gin.Context)
 	DeleteIPGroup(ctx *gin.Context)
 	AddIPToBlacklist(ctx *gin.Context)
+	CreateIPGroupFromImport(ctx *gin.Context)
+	ImportIPGroup(ctx *gin.Context)
+	ExportIPGroup(ctx *gin.Context)
+	GetIPGroupReferences(ctx *gin.Context)
+	GetAllIPGroupReferences(ctx *gin.Context)
 }
 
 // IPGroupControllerImpl IP组控制器实现
@@ -70,6 +77,9 @@ func (c *IPGroupControllerImpl) CreateIPGroup(ctx *gin.Context) {
 		if errors.Is(err, service.ErrIPGroupNameExists) {
 			response.Error(ctx, model.NewAPIError(http.StatusConflict, "IP组名称已存在", err), false)
 			return
+		} else if errors.Is(err, service.ErrScopeSiteNotFound) || errors.Is(err, service.ErrExpirationNotInGroup) || errors.Is(err, service.ErrEdgeBlockScoped) {
+			response.BadRequest(ctx, err, true)
+			return
 		}
 		c.logger.Error().Err(err).Msg("创建IP组失败")
 		response.InternalServerError(ctx, err, false)
@@ -83,23 +93,34 @@ func (c *IPGroupControllerImpl) CreateIPGroup(ctx *gin.Context) {
 // GetIPGroups 获取IP组列表
 //
 //	@Summary		获取IP组列表
-//	@Description	获取所有IP组列表，支持分页
+//	@Description	获取所有IP组列表，支持分页和按站点过滤
 //	@Tags			IP组管理
 //	@Produce		json
-//	@Param			page	query	int	false	"页码"	default(1)
-//	@Param			size	query	int	false	"每页数量"	default(10)
+//	@Param			page			query	int		false	"页码"									default(1)
+//	@Param			size			query	int		false	"每页数量"								default(10)
+//	@Param			siteId			query	string	false	"站点ID，只返回作用于该站点的IP组"
+//	@Param			includeGlobal	query	bool	false	"按站点过滤时是否包含全局IP组"	default(true)
 //	@Security		BearerAuth
 //	@Success		200	{object}	model.SuccessResponse{data=dto.IPGroupListResponse}	"获取IP组列表成功"
+//	@Failure		400	{object}	model.ErrResponse									"请求参数错误"
 //	@Failure		401	{object}	model.ErrResponseDontShowError						"未授权访问"
 //	@Failure		500	{object}	model.ErrResponseDontShowError						"服务器内部错误"
 //	@Router			/api/v1/ip-groups [get]
 func (c *IPGroupControllerImpl) GetIPGroups(ctx *gin.Context) {
-	page := ctx.DefaultQuery("page", "1")
-	size := ctx.DefaultQuery("size", "10")
+	var req dto.IPGroupListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.logger.Warn().Err(err).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
 
-	c.logger.Info().Str("page", page).Str("size", size).Msg("获取IP组列表请求")
-	ipGroups, total, err := c.ipGroupService.GetIPGroups(ctx, page, size)
+	c.logger.Info().Str("page", req.Page).Str("size", req.Size).Str("siteId", req.SiteID).Msg("获取IP组列表请求")
+	ipGroups, total, err := c.ipGroupService.GetIPGroups(ctx, &req)
 	if err != nil {
+		if errors.Is(err, service.ErrScopeSiteNotFound) {
+			response.BadRequest(ctx, err, true)
+			return
+		}
 		c.logger.Error().Err(err).Msg("获取IP组列表失败")
 		response.InternalServerError(ctx, err, false)
 		return
@@ -155,7 +176,7 @@ func (c *IPGroupControllerImpl) GetIPGroupByID(ctx *gin.Context) {
 // UpdateIPGroup 更新IP组
 //
 //	@Summary		更新IP组
-//	@Description	更新指定IP组的信息
+//	@Description	更新指定IP组的信息。重命名被微规则引用的IP组时需要设置 updateReferences，引用规则的条件会同时更新
 //	@Tags			IP组管理
 //	@Accept			json
 //	@Produce		json
@@ -167,7 +188,7 @@ func (c *IPGroupControllerImpl) GetIPGroupByID(ctx *gin.Context) {
 //	@Failure		401	{object}	model.ErrResponseDontShowError				"未授权访问"
 //	@Failure		403	{object}	model.ErrResponseDontShowError				"禁止操作系统默认IP组"
 //	@Failure		404	{object}	model.ErrResponseDontShowError				"IP组不存在"
-//	@Failure		409	{object}	model.ErrResponseDontShowError				"IP组名称已存在"
+//	@Failure		409	{object}	model.ErrResponse							"IP组名称已存在，或IP组被规则引用且未要求同时更新引用"
 //	@Failure		500	{object}	model.ErrResponseDontShowError				"服务器内部错误"
 //	@Router			/api/v1/ip-groups/{id} [put]
 func (c *IPGroupControllerImpl) UpdateIPGroup(ctx *gin.Context) {
@@ -197,6 +218,12 @@ func (c *IPGroupControllerImpl) UpdateIPGroup(ctx *gin.Context) {
 		} else if errors.Is(err, service.ErrSystemIPGroupNoMod) {
 			response.Error(ctx, model.NewAPIError(http.StatusForbidden, "系统默认IP组不允许修改名称", err), false)
 			return
+		} else if errors.Is(err, service.ErrIPGroupReferenced) {
+			response.Error(ctx, model.NewAPIError(http.StatusConflict, "IP组被规则引用，重命名需要同时更新引用", err), true)
+			return
+		} else if errors.Is(err, service.ErrScopeSiteNotFound) || errors.Is(err, service.ErrExpirationNotInGroup) || errors.Is(err, service.ErrEdgeBlockScoped) {
+			response.BadRequest(ctx, err, true)
+			return
 		}
 		c.logger.Error().Err(err).Str("id", id).Msg("更新IP组失败")
 		response.InternalServerError(ctx, err, false)
@@ -210,7 +237,7 @@ func (c *IPGroupControllerImpl) UpdateIPGroup(ctx *gin.Context) {
 // DeleteIPGroup 删除IP组
 //
 //	@Summary		删除IP组
-//	@Description	删除指定的IP组，系统默认IP组不允许删除
+//	@Description	删除指定的IP组，系统默认IP组和被微规则引用的IP组不允许删除
 //	@Tags			IP组管理
 //	@Produce		json
 //	@Param			id	path	string	true	"IP组ID"
@@ -220,6 +247,7 @@ func (c *IPGroupControllerImpl) UpdateIPGroup(ctx *gin.Context) {
 //	@Failure		401	{object}	model.ErrResponseDontShowError	"未授权访问"
 //	@Failure		403	{object}	model.ErrResponseDontShowError	"禁止删除系统默认IP组"
 //	@Failure		404	{object}	model.ErrResponseDontShowError	"IP组不存在"
+//	@Failure		409	{object}	model.ErrResponse				"IP组被规则引用"
 //	@Failure		500	{object}	model.ErrResponseDontShowError	"服务器内部错误"
 //	@Router			/api/v1/ip-groups/{id} [delete]
 func (c *IPGroupControllerImpl) DeleteIPGroup(ctx *gin.Context) {
@@ -240,6 +268,9 @@ func (c *IPGroupControllerImpl) DeleteIPGroup(ctx *gin.Context) {
 		} else if errors.Is(err, service.ErrSystemIPGroupNoMod) {
 			response.Error(ctx, model.NewAPIError(http.StatusForbidden, "系统默认IP组不允许删除", err), false)
 			return
+		} else if errors.Is(err, service.ErrIPGroupReferenced) {
+			response.Error(ctx, model.NewAPIError(http.StatusConflict, "IP组被规则引用，不允许删除", err), true)
+			return
 		}
 		c.logger.Error().Err(err).Str("id", id).Msg("删除IP组失败")
 		response.InternalServerError(ctx, err, false)
@@ -250,6 +281,65 @@ func (c *IPGroupControllerImpl) DeleteIPGroup(ctx *gin.Context) {
 	response.Success(ctx, "IP组删除成功", nil)
 }
 
+// GetIPGroupReferences 获取引用IP组的规则
+//
+//	@Summary		获取IP组引用
+//	@Description	获取引用指定IP组的微规则列表
+//	@Tags			IP组管理
+//	@Produce		json
+//	@Param			id	path	string	true	"IP组ID"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.IPGroupReferencesResponse}	"获取IP组引用成功"
+//	@Failure		400	{object}	model.ErrResponse											"无效的ID格式"
+//	@Failure		401	{object}	model.ErrResponseDontShowError								"未授权访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError								"IP组不存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError								"服务器内部错误"
+//	@Router			/api/v1/ip-groups/{id}/references [get]
+func (c *IPGroupControllerImpl) GetIPGroupReferences(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", id).Msg("无效的ID格式")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+	refs, err := c.ipGroupService.GetIPGroupReferences(ctx, objectID)
+	if err != nil {
+		if errors.Is(err, service.ErrIPGroupNotFound) {
+			response.NotFound(ctx, err)
+			return
+		}
+		c.logger.Error().Err(err).Str("id", id).Msg("获取IP组引用失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "获取IP组引用成功", refs)
+}
+
+// GetAllIPGroupReferences 获取所有IP组的引用
+//
+//	@Summary		获取所有IP组引用
+//	@Description	获取所有被微规则引用的IP组及引用它们的规则，exists 为 false 表示规则引用的IP组不存在
+//	@Tags			IP组管理
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=[]dto.IPGroupReferencesResponse}	"获取IP组引用成功"
+//	@Failure		401	{object}	model.ErrResponseDontShowError								"未授权访问"
+//	@Failure		500	{object}	model.ErrResponseDontShowError								"服务器内部错误"
+//	@Router			/api/v1/ip-groups/references [get]
+func (c *IPGroupControllerImpl) GetAllIPGroupReferences(ctx *gin.Context) {
+	refs, err := c.ipGroupService.GetAllIPGroupReferences(ctx)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("获取IP组引用失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "获取IP组引用成功", refs)
+}
+
 // AddIPToBlacklist 添加IP到系统默认黑名单
 //
 //	@Summary		添加IP到黑名单
@@ -288,3 +378,193 @@ func (c *IPGroupControllerImpl) AddIPToBlacklist(ctx *gin.Context) {
 	c.logger.Info().Str("ip", req.IP).Msg("IP添加到黑名单成功")
 	response.Success(ctx, "IP添加到黑名单成功", nil)
 }
+
+// CreateIPGroupFromImport 从文件导入创建IP组
+//
+//	@Summary		从文件导入创建IP组
+//	@Description	上传纯文本、CSV 或 JSON 文件创建新的IP组，跳过注释和无效行，条目去重后合并相邻或重叠的CIDR，返回导入报告
+//	@Tags			IP组管理
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			file	formData	file	true	"导入文件"
+//	@Param			name	formData	string	true	"IP组名称"
+//	@Param			format	formData	string	false	"文件格式，为空时根据扩展名判断"	Enums(txt, csv, json)
+//	@Param			dryRun	formData	bool	false	"只生成导入报告，不保存"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.IPGroupImportReport}	"IP组导入成功"
+//	@Failure		400	{object}	model.ErrResponse									"请求参数错误或文件格式错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError						"未授权访问"
+//	@Failure		409	{object}	model.ErrResponseDontShowError						"IP组名称已存在"
+//	@Failure		413	{object}	model.ErrResponseDontShowError						"导入文件过大"
+//	@Failure		500	{object}	model.ErrResponseDontShowError						"服务器内部错误"
+//	@Router			/api/v1/ip-groups/import [post]
+func (c *IPGroupControllerImpl) CreateIPGroupFromImport(ctx *gin.Context) {
+	var req dto.IPGroupImportRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		c.logger.Warn().Err(err).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	file, header, ok := c.openImportFile(ctx)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	c.logger.Info().Str("name", req.Name).Str("filename", header.Filename).Int64("size", header.Size).Msg("导入创建IP组请求")
+	report, err := c.ipGroupService.CreateIPGroupFromImport(ctx, &req, header.Filename, file)
+	if err != nil {
+		c.handleImportError(ctx, err)
+		return
+	}
+
+	c.logger.Info().Str("id", report.GroupID).Str("name", report.GroupName).Bool("dryRun", report.DryRun).Msg("IP组导入成功")
+	response.Success(ctx, "IP组导入成功", report)
+}
+
+// ImportIPGroup 从文件导入条目到IP组
+//
+//	@Summary		从文件导入IP组条目
+//	@Description	上传纯文本、CSV 或 JSON 文件，与现有条目合并或替换现有条目，跳过注释和无效行，条目去重后合并相邻或重叠的CIDR，返回导入报告
+//	@Tags			IP组管理
+//	@Accept			multipart/form-data
+//	@Produce		json
+//	@Param			id		path		string	true	"IP组ID"
+//	@Param			file	formData	file	true	"导入文件"
+//	@Param			format	formData	string	false	"文件格式，为空时根据扩展名判断"	Enums(txt, csv, json)
+//	@Param			mode	formData	string	false	"导入模式"						Enums(merge, replace)	default(merge)
+//	@Param			dryRun	formData	bool	false	"只生成导入报告，不保存"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.IPGroupImportReport}	"IP组导入成功"
+//	@Failure		400	{object}	model.ErrResponse									"请求参数错误或文件格式错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError						"未授权访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError						"IP组不存在"
+//	@Failure		413	{object}	model.ErrResponseDontShowError						"导入文件过大"
+//	@Failure		500	{object}	model.ErrResponseDontShowError						"服务器内部错误"
+//	@Router			/api/v1/ip-groups/{id}/import [post]
+func (c *IPGroupControllerImpl) ImportIPGroup(ctx *gin.Context) {
+	id := ctx.Param("id")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", id).Msg("无效的ID格式")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	var req dto.IPGroupImportRequest
+	if err := ctx.ShouldBind(&req); err != nil {
+		c.logger.Warn().Err(err).Str("id", id).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	file, header, ok := c.openImportFile(ctx)
+	if !ok {
+		return
+	}
+	defer file.Close()
+
+	c.logger.Info().Str("id", id).Str("filename", header.Filename).Int64("size", header.Size).Msg("导入IP组请求")
+	report, err := c.ipGroupService.ImportIPGroup(ctx, objectID, &req, header.Filename, file)
+	if err != nil {
+		c.handleImportError(ctx, err)
+		return
+	}
+
+	c.logger.Info().Str("id", id).Int("added", report.AddedCount).Int("removed", report.RemovedCount).Bool("dryRun", report.DryRun).Msg("IP组导入成功")
+	response.Success(ctx, "IP组导入成功", report)
+}
+
+// ExportIPGroup 导出IP组
+//
+//	@Summary		导出IP组
+//	@Description	将IP组条目导出为纯文本、CSV 或 JSON 文件，导出的文件可以直接重新导入
+//	@Tags			IP组管理
+//	@Produce		plain
+//	@Produce		text/csv
+//	@Produce		json
+//	@Param			id		path	string	true	"IP组ID"
+//	@Param			format	query	string	false	"文件格式"	Enums(txt, csv, json)	default(txt)
+//	@Security		BearerAuth
+//	@Success		200	{file}		file							"导出文件"
+//	@Failure		400	{object}	model.ErrResponse				"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError	"未授权访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError	"IP组不存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError	"服务器内部错误"
+//	@Router			/api/v1/ip-groups/{id}/export [get]
+func (c *IPGroupControllerImpl) ExportIPGroup(ctx *gin.Context) {
+	id := ctx.Param("id")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", id).Msg("无效的ID格式")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	var req dto.IPGroupExportRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.logger.Warn().Err(err).Str("id", id).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	c.logger.Info().Str("id", id).Str("format", req.Format).Msg("导出IP组请求")
+	file, err := c.ipGroupService.ExportIPGroup(ctx, objectID, req.Format)
+	if err != nil {
+		if errors.Is(err, service.ErrIPGroupNotFound) {
+			response.NotFound(ctx, err)
+			return
+		} else if errors.Is(err, service.ErrIPGroupExportInvalidFormat) {
+			response.BadRequest(ctx, err, true)
+			return
+		}
+		c.logger.Error().Err(err).Str("id", id).Msg("导出IP组失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	ctx.Header("Content-Disposition", "attachment; filename*=UTF-8''"+url.PathEscape(file.Filename))
+	ctx.Data(http.StatusOK, file.ContentType, file.Data)
+}
+
+// openImportFile 打开上传的导入文件，失败时直接写入错误响应
+func (c *IPGroupControllerImpl) openImportFile(ctx *gin.Context) (multipart.File, *multipart.FileHeader, bool) {
+	header, err := ctx.FormFile("file")
+	if err != nil {
+		c.logger.Warn().Err(err).Msg("获取导入文件失败")
+		response.BadRequest(ctx, err, true)
+		return nil, nil, false
+	}
+	if header.Size > service.MaxIPGroupImportSize {
+		response.Error(ctx, model.NewAPIError(http.StatusRequestEntityTooLarge, "导入文件过大", service.ErrIPGroupImportTooLarge), true)
+		return nil, nil, false
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		c.logger.Error().Err(err).Str("filename", header.Filename).Msg("打开导入文件失败")
+		response.InternalServerError(ctx, err, false)
+		return nil, nil, false
+	}
+	return file, header, true
+}
+
+// handleImportError 将导入错误转换为响应
+func (c *IPGroupControllerImpl) handleImportError(ctx *gin.Context, err error) {
+	switch {
+	case errors.Is(err, service.ErrIPGroupNotFound):
+		response.NotFound(ctx, err)
+	case errors.Is(err, service.ErrIPGroupNameExists):
+		response.Error(ctx, model.NewAPIError(http.StatusConflict, "IP组名称已存在", err), false)
+	case errors.Is(err, service.ErrIPGroupImportTooLarge):
+		response.Error(ctx, model.NewAPIError(http.StatusRequestEntityTooLarge, "导入文件过大", err), true)
+	case errors.Is(err, service.ErrIPGroupImportNameRequired),
+		errors.Is(err, service.ErrIPGroupImportInvalidFile),
+		errors.Is(err, service.ErrIPGroupImportNoValidEntry):
+		response.BadRequest(ctx, err, true)
+	default:
+		c.logger.Error().Err(err).Msg("导入IP组失败")
+		response.InternalServerError(ctx, err, false)
+	}
+}
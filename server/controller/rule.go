@@ -24,6 +24,8 @@ type MicroRuleController interface {
 	GetMicroRuleByID(ctx *gin.Context)
 	UpdateMicroRule(ctx *gin.Context)
 	DeleteMicroRule(ctx *gin.Context)
+	AnalyzeMicroRules(ctx *gin.Context)
+	GetMicroRuleOffload(ctx *gin.Context)
 }
 
 // MicroRuleControllerImpl 微规则控制器实现
@@ -81,6 +83,8 @@ func ConvertToResponse(rule *pkgmodel.MicroRule) (*dto.MicroRuleResponse, error)
 		Status:    string(rule.Status),
 		Priority:  &rule.Priority,
 		Condition: jsonCondition,
+		Scope:     rule.Scope,
+		Schedule:  rule.Schedule,
 	}, nil
 }
 
@@ -114,6 +118,10 @@ func (c *MicroRuleControllerImpl) CreateMicroRule(ctx *gin.Context) {
 		if errors.Is(err, service.ErrMicroRuleNameExists) {
 			response.Error(ctx, model.NewAPIError(http.StatusConflict, "微规则名称已存在", err), false)
 			return
+		} else if errors.Is(err, service.ErrScopeSiteNotFound) || errors.Is(err, service.ErrInvalidRuleSchedule) ||
+			errors.Is(err, service.ErrIPGroupRefNotFound) {
+			response.BadRequest(ctx, err, true)
+			return
 		}
 		c.logger.Error().Err(err).Msg("创建微规则失败")
 		response.InternalServerError(ctx, err, false)
@@ -135,28 +143,55 @@ func (c *MicroRuleControllerImpl) CreateMicroRule(ctx *gin.Context) {
 // GetMicroRules 获取微规则列表
 //
 //	@Summary		获取微规则列表
-//	@Description	获取所有WAF微规则列表，支持分页
+//	@Description	获取所有WAF微规则列表，支持分页和按站点过滤，每条规则附带累计命中次数、拦截次数、最后命中时间和是否卸载到 HAProxy 判断
 //	@Tags			规则管理
 //	@Produce		json
-//	@Param			page	query	int	false	"页码"	default(1)
-//	@Param			size	query	int	false	"每页数量"	default(10)
+//	@Param			page			query	int		false	"页码"									default(1)
+//	@Param			size			query	int		false	"每页数量"								default(10)
+//	@Param			siteId			query	string	false	"站点ID，只返回作用于该站点的规则"
+//	@Param			includeGlobal	query	bool	false	"按站点过滤时是否包含全局规则"	default(true)
 //	@Security		BearerAuth
 //	@Success		200	{object}	model.SuccessResponse{data=dto.MicroRuleListResponse}	"获取微规则列表成功"
+//	@Failure		400	{object}	model.ErrResponse										"请求参数错误"
 //	@Failure		401	{object}	model.ErrResponseDontShowError							"未授权访问"
 //	@Failure		500	{object}	model.ErrResponseDontShowError							"服务器内部错误"
 //	@Router			/api/v1/micro-rules [get]
 func (c *MicroRuleControllerImpl) GetMicroRules(ctx *gin.Context) {
-	page := ctx.DefaultQuery("page", "1")
-	size := ctx.DefaultQuery("size", "10")
+	var req dto.MicroRuleListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.logger.Warn().Err(err).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
 
-	c.logger.Info().Str("page", page).Str("size", size).Msg("获取微规则列表请求")
-	rules, total, err := c.ruleService.GetMicroRules(ctx, page, size)
+	c.logger.Info().Str("page", req.Page).Str("size", req.Size).Str("siteId", req.SiteID).Msg("获取微规则列表请求")
+	rules, total, err := c.ruleService.GetMicroRules(ctx, &req)
 	if err != nil {
+		if errors.Is(err, service.ErrScopeSiteNotFound) {
+			response.BadRequest(ctx, err, true)
+			return
+		}
 		c.logger.Error().Err(err).Msg("获取微规则列表失败")
 		response.InternalServerError(ctx, err, false)
 		return
 	}
 
+	// 获取命中统计，统计数据失败不影响规则列表返回
+	hitStats, err := c.ruleService.GetMicroRuleHitStats(ctx, rules)
+	if err != nil {
+		c.logger.Warn().Err(err).Msg("获取微规则命中统计失败")
+	}
+
+	// 获取卸载状态，失败时不返回卸载状态
+	offloads := make(map[string]*dto.RuleOffloadStatus)
+	if offload, err := c.ruleService.GetMicroRuleOffload(ctx); err != nil {
+		c.logger.Warn().Err(err).Msg("获取微规则卸载状态失败")
+	} else {
+		for _, item := range offload.Rules {
+			offloads[item.RuleID] = &dto.RuleOffloadStatus{Offloaded: item.Offloaded, Reason: item.Reason}
+		}
+	}
+
 	// 转换响应对象
 	responses := make([]*dto.MicroRuleResponse, len(rules))
 	for i, rule := range rules {
@@ -166,6 +201,9 @@ func (c *MicroRuleControllerImpl) GetMicroRules(ctx *gin.Context) {
 			response.InternalServerError(ctx, err, false)
 			return
 		}
+		stats := hitStats[rule.ID.Hex()]
+		resp.Stats = &stats
+		resp.Offload = offloads[rule.ID.Hex()]
 		responses[i] = resp
 	}
 
@@ -176,6 +214,50 @@ func (c *MicroRuleControllerImpl) GetMicroRules(ctx *gin.Context) {
 	})
 }
 
+// AnalyzeMicroRules 分析微规则
+//
+//	@Summary		分析微规则
+//	@Description	按规则引擎的匹配顺序静态分析所有微规则，报告被更高优先级规则覆盖、白名单与黑名单冲突、重复、条件不可达（如空IP组）、正则表达式无效等问题
+//	@Tags			规则管理
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.RuleAnalysisResponse}	"微规则分析成功"
+//	@Failure		401	{object}	model.ErrResponseDontShowError							"未授权访问"
+//	@Failure		500	{object}	model.ErrResponseDontShowError							"服务器内部错误"
+//	@Router			/api/v1/micro-rules/analysis [get]
+func (c *MicroRuleControllerImpl) AnalyzeMicroRules(ctx *gin.Context) {
+	result, err := c.ruleService.AnalyzeMicroRules(ctx)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("分析微规则失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "微规则分析成功", result)
+}
+
+// GetMicroRuleOffload 获取微规则卸载状态
+//
+//	@Summary		获取微规则卸载状态
+//	@Description	按规则引擎的匹配顺序列出每条微规则是否卸载到 HAProxy 判断。已启用、对所有站点生效、没有生效计划且条件只使用IP、CIDR、全局IP组、路径和URL匹配的规则编译为 HAProxy ACL，其余规则由 WAF 引擎判断，两者的拦截结果一致；结果在下次应用站点配置或热重载后生效
+//	@Tags			规则管理
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.RuleOffloadResponse}	"获取微规则卸载状态成功"
+//	@Failure		401	{object}	model.ErrResponseDontShowError						"未授权访问"
+//	@Failure		500	{object}	model.ErrResponseDontShowError						"服务器内部错误"
+//	@Router			/api/v1/micro-rules/offload [get]
+func (c *MicroRuleControllerImpl) GetMicroRuleOffload(ctx *gin.Context) {
+	result, err := c.ruleService.GetMicroRuleOffload(ctx)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("获取微规则卸载状态失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "获取微规则卸载状态成功", result)
+}
+
 // GetMicroRuleByID 获取单个微规则
 //
 //	@Summary		获取单个微规则
@@ -269,6 +351,10 @@ func (c *MicroRuleControllerImpl) UpdateMicroRule(ctx *gin.Context) {
 		} else if errors.Is(err, service.ErrSystemRuleNoMod) {
 			response.Error(ctx, model.NewAPIError(http.StatusForbidden, "系统默认规则不允许修改", err), false)
 			return
+		} else if errors.Is(err, service.ErrScopeSiteNotFound) || errors.Is(err, service.ErrInvalidRuleSchedule) ||
+			errors.Is(err, service.ErrIPGroupRefNotFound) {
+			response.BadRequest(ctx, err, true)
+			return
 		}
 		c.logger.Error().Err(err).Str("id", id).Msg("更新微规则失败")
 		response.InternalServerError(ctx, err, false)
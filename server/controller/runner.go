@@ -16,6 +16,9 @@ import (
 type RunnerController interface {
 	GetStatus(ctx *gin.Context)
 	Control(ctx *gin.Context)
+	ListConfigVersions(ctx *gin.Context)
+	DiffConfigVersions(ctx *gin.Context)
+	RollbackConfig(ctx *gin.Context)
 }
 
 // RunnerControllerImpl 运行器控制器实现
@@ -184,3 +187,95 @@ func (c *RunnerControllerImpl) Control(ctx *gin.Context) {
 	c.logger.Info().Str("action", req.Action).Str("state", resp.State).Msg("运行器操作成功")
 	response.Success(ctx, "操作成功", resp)
 }
+
+// ListConfigVersions 获取HAProxy配置版本列表
+//
+//	@Summary		获取HAProxy配置版本列表
+//	@Description	按时间倒序返回通过 haproxy -c 检查并成功加载过的配置版本，保留数量由 haproxy.backupsNumber 配置
+//	@Tags			运行器管理
+//	@Produce		json
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=[]dto.ConfigVersionResponse}	"获取配置版本列表成功"
+//	@Failure		500	{object}	model.ErrResponseDontShowError							"服务器内部错误"
+//	@Router			/api/v1/runner/config-versions [get]
+func (c *RunnerControllerImpl) ListConfigVersions(ctx *gin.Context) {
+	versions, err := c.runnerService.ListConfigVersions(ctx)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("获取配置版本列表失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "获取配置版本列表成功", versions)
+}
+
+// DiffConfigVersions 比较两个HAProxy配置版本
+//
+//	@Summary		比较HAProxy配置版本
+//	@Description	返回两个配置版本 haproxy.cfg 的统一格式差异，未指定目标版本时与当前生效的版本比较
+//	@Tags			运行器管理
+//	@Produce		json
+//	@Param			from	query	string	true	"源版本ID"
+//	@Param			to		query	string	false	"目标版本ID"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.ConfigVersionDiffResponse}	"比较配置版本成功"
+//	@Failure		400	{object}	model.ErrResponse											"请求参数错误"
+//	@Failure		404	{object}	model.ErrResponse											"配置版本不存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError								"服务器内部错误"
+//	@Router			/api/v1/runner/config-versions/diff [get]
+func (c *RunnerControllerImpl) DiffConfigVersions(ctx *gin.Context) {
+	var req dto.ConfigVersionDiffRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.logger.Warn().Err(err).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	resp, err := c.runnerService.DiffConfigVersions(ctx, req.From, req.To)
+	if err != nil {
+		if errors.Is(err, service.ErrConfigVersionNotFound) {
+			response.NotFound(ctx, err)
+			return
+		}
+		c.logger.Error().Err(err).Msg("比较配置版本失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "比较配置版本成功", resp)
+}
+
+// RollbackConfig 回滚HAProxy配置
+//
+//	@Summary		回滚HAProxy配置
+//	@Description	将HAProxy配置、SPOE配置和证书恢复到指定版本并重新加载，配置检查或加载失败时保留当前配置；下次热重载会按站点数据重新生成配置
+//	@Tags			运行器管理
+//	@Produce		json
+//	@Param			id	path	string	true	"版本ID"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse	"回滚配置成功"
+//	@Failure		400	{object}	model.ErrResponse		"运行器未在运行"
+//	@Failure		404	{object}	model.ErrResponse		"配置版本不存在"
+//	@Failure		500	{object}	model.ErrResponse		"回滚配置失败"
+//	@Router			/api/v1/runner/config-versions/{id}/rollback [post]
+func (c *RunnerControllerImpl) RollbackConfig(ctx *gin.Context) {
+	id := ctx.Param("id")
+
+	if err := c.runnerService.RollbackConfig(ctx, id); err != nil {
+		if errors.Is(err, service.ErrRunnerNotRunning) {
+			response.BadRequest(ctx, err, true)
+			return
+		}
+		if errors.Is(err, service.ErrConfigVersionNotFound) {
+			response.NotFound(ctx, err)
+			return
+		}
+		c.logger.Error().Err(err).Str("version", id).Msg("回滚配置失败")
+		// 配置检查失败时需要将 HAProxy 的输出返回给用户
+		response.InternalServerError(ctx, err, true)
+		return
+	}
+
+	c.logger.Info().Str("version", id).Msg("回滚配置成功")
+	response.Success(ctx, "回滚配置成功", nil)
+}
@@ -22,6 +22,10 @@ type SiteController interface {
 	GetSiteByID(ctx *gin.Context)
 	UpdateSite(ctx *gin.Context)
 	DeleteSite(ctx *gin.Context)
+	SetServerState(ctx *gin.Context)
+	SetServerWeight(ctx *gin.Context)
+	AddServer(ctx *gin.Context)
+	RemoveServer(ctx *gin.Context)
 }
 
 // SiteControllerImpl 站点控制器实现
@@ -42,7 +46,7 @@ func NewSiteController(siteService service.SiteService) SiteController {
 // CreateSite 创建站点
 //
 //	@Summary		创建新站点
-//	@Description	创建一个新的站点配置
+//	@Description	创建一个新的站点配置，运行器运行时只将该站点的变更增量应用到 HAProxy
 //	@Tags			站点管理
 //	@Accept			json
 //	@Produce		json
@@ -70,6 +74,12 @@ func (c *SiteControllerImpl) CreateSite(ctx *gin.Context) {
 		if errors.Is(err, repository.ErrDomainPortExists) {
 			response.Error(ctx, model.NewAPIError(http.StatusConflict, "域名和端口组合已存在", err), false)
 			return
+		} else if errors.Is(err, service.ErrInvalidLoginProtection) || errors.Is(err, service.ErrInvalidSiteRouting) || errors.Is(err, service.ErrInvalidBackend) || errors.Is(err, service.ErrInvalidSiteCertificate) || errors.Is(err, service.ErrInvalidTLSPolicy) || errors.Is(err, service.ErrInvalidHeaderRule) || errors.Is(err, service.ErrInvalidSitePage) {
+			response.BadRequest(ctx, err, true)
+			return
+		} else if errors.Is(err, service.ErrSiteApplyFailed) {
+			response.InternalServerError(ctx, err, true)
+			return
 		}
 		c.logger.Error().Err(err).Msg("创建站点失败")
 		response.InternalServerError(ctx, err, false)
@@ -151,13 +161,16 @@ func (c *SiteControllerImpl) GetSiteByID(ctx *gin.Context) {
 	}
 
 	c.logger.Info().Str("id", id).Str("name", site.Name).Msg("获取站点详情成功")
-	response.Success(ctx, "获取站点详情成功", site)
+	response.Success(ctx, "获取站点详情成功", dto.SiteResponse{
+		Site:         *site,
+		ServerStatus: c.siteService.GetSiteServerStatus(site),
+	})
 }
 
 // UpdateSite 更新站点
 //
 //	@Summary		更新站点
-//	@Description	更新指定站点的配置
+//	@Description	更新指定站点的配置，运行器运行时只将该站点的变更增量应用到 HAProxy
 //	@Tags			站点管理
 //	@Accept			json
 //	@Produce		json
@@ -196,6 +209,12 @@ func (c *SiteControllerImpl) UpdateSite(ctx *gin.Context) {
 		} else if errors.Is(err, repository.ErrDomainPortConflict) {
 			response.Error(ctx, model.NewAPIError(http.StatusConflict, "域名和端口组合已被其他站点使用", err), false)
 			return
+		} else if errors.Is(err, service.ErrInvalidLoginProtection) || errors.Is(err, service.ErrInvalidSiteRouting) || errors.Is(err, service.ErrInvalidBackend) || errors.Is(err, service.ErrInvalidSiteCertificate) || errors.Is(err, service.ErrInvalidTLSPolicy) || errors.Is(err, service.ErrInvalidHeaderRule) || errors.Is(err, service.ErrInvalidSitePage) {
+			response.BadRequest(ctx, err, true)
+			return
+		} else if errors.Is(err, service.ErrSiteApplyFailed) {
+			response.InternalServerError(ctx, err, true)
+			return
 		}
 		c.logger.Error().Err(err).Str("id", id).Msg("更新站点失败")
 		response.InternalServerError(ctx, err, false)
@@ -209,7 +228,7 @@ func (c *SiteControllerImpl) UpdateSite(ctx *gin.Context) {
 // DeleteSite 删除站点
 //
 //	@Summary		删除站点
-//	@Description	删除指定的站点配置
+//	@Description	删除指定的站点配置，运行器运行时只从 HAProxy 中删除该站点的配置
 //	@Tags			站点管理
 //	@Produce		json
 //	@Param			id	path	string	true	"站点ID"
@@ -235,6 +254,9 @@ func (c *SiteControllerImpl) DeleteSite(ctx *gin.Context) {
 		if errors.Is(err, repository.ErrSiteNotFound) {
 			response.Error(ctx, model.NewAPIError(http.StatusNotFound, "站点不存在", err), false)
 			return
+		} else if errors.Is(err, service.ErrSiteApplyFailed) {
+			response.InternalServerError(ctx, err, true)
+			return
 		}
 		c.logger.Error().Err(err).Str("id", id).Msg("删除站点失败")
 		response.InternalServerError(ctx, err, false)
@@ -244,3 +266,184 @@ func (c *SiteControllerImpl) DeleteSite(ctx *gin.Context) {
 	c.logger.Info().Str("id", id).Msg("站点删除成功")
 	response.Success(ctx, "站点删除成功", nil)
 }
+
+// SetServerState 设置后端服务器状态
+//
+//	@Summary		设置后端服务器状态
+//	@Description	通过 HAProxy 运行时 API 将服务器设置为 ready、drain 或 maint，无需重新加载配置，并保存到站点配置；HAProxy 未运行时只保存配置
+//	@Tags			站点管理
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string							true	"站点ID"
+//	@Param			server	path	string							true	"HAProxy 服务器名称，见站点详情的 serverStatus"
+//	@Param			request	body	dto.SetSiteServerStateRequest	true	"服务器状态"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.SiteResponse}	"设置服务器状态成功"
+//	@Failure		400	{object}	model.ErrResponse								"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError					"未授权访问"
+//	@Failure		403	{object}	model.ErrResponseDontShowError					"禁止访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError					"站点或服务器不存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError					"服务器内部错误"
+//	@Router			/api/v1/site/{id}/servers/{server}/state [put]
+func (c *SiteControllerImpl) SetServerState(ctx *gin.Context) {
+	objectID, ok := c.parseSiteID(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.SetSiteServerStateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	serverName := ctx.Param("server")
+	site, err := c.siteService.SetServerState(ctx, objectID, serverName, model.ServerState(req.State))
+	if err != nil {
+		c.handleServerError(ctx, err, "设置服务器状态失败")
+		return
+	}
+
+	c.logger.Info().Str("id", objectID.Hex()).Str("server", serverName).Str("state", req.State).Msg("设置服务器状态成功")
+	response.Success(ctx, "设置服务器状态成功", site)
+}
+
+// SetServerWeight 设置后端服务器权重
+//
+//	@Summary		设置后端服务器权重
+//	@Description	通过 HAProxy 运行时 API 修改服务器权重，无需重新加载配置，并保存到站点配置；HAProxy 未运行时只保存配置
+//	@Tags			站点管理
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string							true	"站点ID"
+//	@Param			server	path	string							true	"HAProxy 服务器名称，见站点详情的 serverStatus"
+//	@Param			request	body	dto.SetSiteServerWeightRequest	true	"服务器权重"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.SiteResponse}	"设置服务器权重成功"
+//	@Failure		400	{object}	model.ErrResponse								"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError					"未授权访问"
+//	@Failure		403	{object}	model.ErrResponseDontShowError					"禁止访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError					"站点或服务器不存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError					"服务器内部错误"
+//	@Router			/api/v1/site/{id}/servers/{server}/weight [put]
+func (c *SiteControllerImpl) SetServerWeight(ctx *gin.Context) {
+	objectID, ok := c.parseSiteID(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.SetSiteServerWeightRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	serverName := ctx.Param("server")
+	site, err := c.siteService.SetServerWeight(ctx, objectID, serverName, req.Weight)
+	if err != nil {
+		c.handleServerError(ctx, err, "设置服务器权重失败")
+		return
+	}
+
+	c.logger.Info().Str("id", objectID.Hex()).Str("server", serverName).Int64("weight", req.Weight).Msg("设置服务器权重成功")
+	response.Success(ctx, "设置服务器权重成功", site)
+}
+
+// AddServer 添加后端服务器
+//
+//	@Summary		添加后端服务器
+//	@Description	通过 HAProxy 运行时 API 向站点默认后端或路径路由的后端添加服务器，无需重新加载配置，并保存到站点配置；HAProxy 未运行时只保存配置
+//	@Tags			站点管理
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string					true	"站点ID"
+//	@Param			request	body	dto.AddSiteServerRequest	true	"服务器信息"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.SiteResponse}	"添加服务器成功"
+//	@Failure		400	{object}	model.ErrResponse								"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError					"未授权访问"
+//	@Failure		403	{object}	model.ErrResponseDontShowError					"禁止访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError					"站点或路径路由不存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError					"服务器内部错误"
+//	@Router			/api/v1/site/{id}/servers [post]
+func (c *SiteControllerImpl) AddServer(ctx *gin.Context) {
+	objectID, ok := c.parseSiteID(ctx)
+	if !ok {
+		return
+	}
+
+	var req dto.AddSiteServerRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	site, err := c.siteService.AddServer(ctx, objectID, &req)
+	if err != nil {
+		c.handleServerError(ctx, err, "添加服务器失败")
+		return
+	}
+
+	c.logger.Info().Str("id", objectID.Hex()).Str("host", req.Server.Host).Msg("添加服务器成功")
+	response.Success(ctx, "添加服务器成功", site)
+}
+
+// RemoveServer 删除后端服务器
+//
+//	@Summary		删除后端服务器
+//	@Description	通过 HAProxy 运行时 API 删除服务器，无需重新加载配置，并保存到站点配置；有活动连接的服务器需要先 drain
+//	@Tags			站点管理
+//	@Produce		json
+//	@Param			id		path	string	true	"站点ID"
+//	@Param			server	path	string	true	"HAProxy 服务器名称，见站点详情的 serverStatus"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.SiteResponse}	"删除服务器成功"
+//	@Failure		400	{object}	model.ErrResponse								"不能删除后端的最后一个服务器"
+//	@Failure		401	{object}	model.ErrResponseDontShowError					"未授权访问"
+//	@Failure		403	{object}	model.ErrResponseDontShowError					"禁止访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError					"站点或服务器不存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError					"服务器内部错误"
+//	@Router			/api/v1/site/{id}/servers/{server} [delete]
+func (c *SiteControllerImpl) RemoveServer(ctx *gin.Context) {
+	objectID, ok := c.parseSiteID(ctx)
+	if !ok {
+		return
+	}
+
+	serverName := ctx.Param("server")
+	site, err := c.siteService.RemoveServer(ctx, objectID, serverName)
+	if err != nil {
+		c.handleServerError(ctx, err, "删除服务器失败")
+		return
+	}
+
+	c.logger.Info().Str("id", objectID.Hex()).Str("server", serverName).Msg("删除服务器成功")
+	response.Success(ctx, "删除服务器成功", site)
+}
+
+// parseSiteID 解析路径中的站点ID，格式错误时返回 400
+func (c *SiteControllerImpl) parseSiteID(ctx *gin.Context) (bson.ObjectID, bool) {
+	id := ctx.Param("id")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", id).Msg("无效的ID格式")
+		response.BadRequest(ctx, err, true)
+		return bson.ObjectID{}, false
+	}
+	return objectID, true
+}
+
+// handleServerError 处理后端服务器运行时操作的错误
+func (c *SiteControllerImpl) handleServerError(ctx *gin.Context, err error, message string) {
+	switch {
+	case errors.Is(err, repository.ErrSiteNotFound):
+		response.Error(ctx, model.NewAPIError(http.StatusNotFound, "站点不存在", err), false)
+	case errors.Is(err, service.ErrSiteServerNotFound), errors.Is(err, service.ErrSiteRouteNotFound):
+		response.Error(ctx, model.NewAPIError(http.StatusNotFound, err.Error(), err), false)
+	case errors.Is(err, service.ErrLastSiteServer):
+		response.BadRequest(ctx, err, true)
+	default:
+		c.logger.Error().Err(err).Str("id", ctx.Param("id")).Msg(message)
+		response.InternalServerError(ctx, err, true)
+	}
+}
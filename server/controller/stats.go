@@ -19,6 +19,7 @@ type StatsController interface {
 	GetTimeSeriesData(ctx *gin.Context)
 	GetCombinedTimeSeriesData(ctx *gin.Context)
 	GetTrafficTimeSeriesData(ctx *gin.Context)
+	GetRuleStats(ctx *gin.Context)
 }
 
 type StatsControllerImpl struct {
@@ -246,3 +247,42 @@ func (c *StatsControllerImpl) GetTrafficTimeSeriesData(ctx *gin.Context) {
 
 	response.Success(ctx, "获取流量时间序列数据成功", data)
 }
+
+// GetRuleStats 获取规则命中统计
+//
+//	@Summary		获取规则命中统计
+//	@Description	获取微规则和Coraza规则在指定时间范围内的命中次数、拦截次数、最后命中时间及时间序列，按命中次数降序
+//	@Tags			统计信息
+//	@Produce		json
+//	@Param			timeRange	query	string	true	"时间范围：24h(24小时)、7d(7天)、30d(30天)"	Enums(24h, 7d, 30d)	default(24h)
+//	@Param			source		query	string	false	"规则来源：micro(微规则)、coraza(Coraza规则)，为空表示全部"	Enums(micro, coraza)
+//	@Param			ruleId		query	string	false	"规则ID，为空表示全部规则"
+//	@Param			limit		query	int		false	"返回的规则数量，默认20，最大100"	default(20)	minimum(1)	maximum(100)
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.RuleStatsResponse}	"获取规则命中统计成功"
+//	@Failure		400	{object}	model.ErrResponse									"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError						"未授权访问"
+//	@Failure		500	{object}	model.ErrResponseDontShowError						"服务器内部错误"
+//	@Router			/api/v1/stats/rules [get]
+func (c *StatsControllerImpl) GetRuleStats(ctx *gin.Context) {
+	// 解析请求参数
+	var req dto.RuleStatsRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.logger.Warn().Err(err).Msg("绑定规则命中统计请求参数失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	// 调用服务
+	data, err := c.statsService.GetRuleStats(ctx, &req)
+	if err != nil {
+		c.logger.Error().Err(err).
+			Str("timeRange", req.TimeRange).
+			Str("source", req.Source).
+			Msg("获取规则命中统计失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "获取规则命中统计成功", data)
+}
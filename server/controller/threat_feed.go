@@ -0,0 +1,289 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/HUAHUAI23/RuiQi/server/service"
+	"github.com/HUAHUAI23/RuiQi/server/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ThreatFeedController 威胁情报源控制器接口
+type ThreatFeedController interface {
+	CreateThreatFeed(ctx *gin.Context)
+	GetThreatFeeds(ctx *gin.Context)
+	GetThreatFeedByID(ctx *gin.Context)
+	UpdateThreatFeed(ctx *gin.Context)
+	DeleteThreatFeed(ctx *gin.Context)
+	RefreshThreatFeed(ctx *gin.Context)
+}
+
+// ThreatFeedControllerImpl 威胁情报源控制器实现
+type ThreatFeedControllerImpl struct {
+	threatFeedService service.ThreatFeedService
+	logger            zerolog.Logger
+}
+
+// NewThreatFeedController 创建威胁情报源控制器
+func NewThreatFeedController(threatFeedService service.ThreatFeedService) ThreatFeedController {
+	logger := config.GetControllerLogger("threatfeed")
+	return &ThreatFeedControllerImpl{
+		threatFeedService: threatFeedService,
+		logger:            logger,
+	}
+}
+
+// CreateThreatFeed 创建威胁情报源
+//
+//	@Summary		创建威胁情报源
+//	@Description	订阅外部或本地的IP黑名单，按刷新间隔定时拉取并同步到新建的IP组，拉取失败时IP组保留上一次成功同步的数据
+//	@Tags			威胁情报源
+//	@Accept			json
+//	@Produce		json
+//	@Param			feed	body	dto.ThreatFeedCreateRequest	true	"威胁情报源信息"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=model.ThreatFeed}	"威胁情报源创建成功"
+//	@Failure		400	{object}	model.ErrResponse								"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError					"未授权访问"
+//	@Failure		403	{object}	model.ErrResponseDontShowError					"禁止访问"
+//	@Failure		409	{object}	model.ErrResponseDontShowError					"情报源名称或IP组名称已存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError					"服务器内部错误"
+//	@Router			/api/v1/threat-feeds [post]
+func (c *ThreatFeedControllerImpl) CreateThreatFeed(ctx *gin.Context) {
+	var req dto.ThreatFeedCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Warn().Err(err).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	c.logger.Info().Str("name", req.Name).Str("format", req.Format).Msg("创建威胁情报源请求")
+	feed, err := c.threatFeedService.CreateThreatFeed(ctx, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrThreatFeedNameExists) {
+			response.Error(ctx, model.NewAPIError(http.StatusConflict, "威胁情报源名称已存在", err), false)
+			return
+		} else if errors.Is(err, service.ErrIPGroupNameExists) {
+			response.Error(ctx, model.NewAPIError(http.StatusConflict, "IP组名称已存在", err), false)
+			return
+		} else if errors.Is(err, service.ErrInvalidThreatFeedSource) {
+			response.BadRequest(ctx, err, true)
+			return
+		}
+		c.logger.Error().Err(err).Msg("创建威胁情报源失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	c.logger.Info().Str("id", feed.ID.Hex()).Str("name", feed.Name).Msg("威胁情报源创建成功")
+	response.Success(ctx, "威胁情报源创建成功", feed)
+}
+
+// GetThreatFeeds 获取威胁情报源列表
+//
+//	@Summary		获取威胁情报源列表
+//	@Description	获取威胁情报源列表及其同步状态，按名称排序
+//	@Tags			威胁情报源
+//	@Produce		json
+//	@Param			page	query	int	false	"页码，从1开始"		default(1)	minimum(1)
+//	@Param			size	query	int	false	"每页数量，最大100"	default(10)	minimum(1)	maximum(100)
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.ThreatFeedListResponse}	"获取威胁情报源列表成功"
+//	@Failure		400	{object}	model.ErrResponse										"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError							"未授权访问"
+//	@Failure		500	{object}	model.ErrResponseDontShowError							"服务器内部错误"
+//	@Router			/api/v1/threat-feeds [get]
+func (c *ThreatFeedControllerImpl) GetThreatFeeds(ctx *gin.Context) {
+	var req dto.ThreatFeedListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.logger.Warn().Err(err).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	result, err := c.threatFeedService.GetThreatFeeds(ctx, &req)
+	if err != nil {
+		c.logger.Error().Err(err).Msg("获取威胁情报源列表失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "获取威胁情报源列表成功", result)
+}
+
+// GetThreatFeedByID 获取单个威胁情报源
+//
+//	@Summary		获取单个威胁情报源
+//	@Description	根据ID获取威胁情报源详情，包括最近一次同步的变更、成功和失败信息
+//	@Tags			威胁情报源
+//	@Produce		json
+//	@Param			id	path	string	true	"威胁情报源ID"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=model.ThreatFeed}	"获取威胁情报源详情成功"
+//	@Failure		400	{object}	model.ErrResponse								"无效的ID格式"
+//	@Failure		401	{object}	model.ErrResponseDontShowError					"未授权访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError					"威胁情报源不存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError					"服务器内部错误"
+//	@Router			/api/v1/threat-feeds/{id} [get]
+func (c *ThreatFeedControllerImpl) GetThreatFeedByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", id).Msg("无效的ID格式")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	feed, err := c.threatFeedService.GetThreatFeedByID(ctx, objectID)
+	if err != nil {
+		if errors.Is(err, service.ErrThreatFeedNotFound) {
+			response.NotFound(ctx, err)
+			return
+		}
+		c.logger.Error().Err(err).Str("id", id).Msg("获取威胁情报源详情失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "获取威胁情报源详情成功", feed)
+}
+
+// UpdateThreatFeed 更新威胁情报源
+//
+//	@Summary		更新威胁情报源
+//	@Description	更新威胁情报源配置，修改地址、格式或重新启用后会在下一次调度时完整拉取
+//	@Tags			威胁情报源
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string						true	"威胁情报源ID"
+//	@Param			feed	body	dto.ThreatFeedUpdateRequest	true	"威胁情报源更新信息"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=model.ThreatFeed}	"威胁情报源更新成功"
+//	@Failure		400	{object}	model.ErrResponse								"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError					"未授权访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError					"威胁情报源不存在"
+//	@Failure		409	{object}	model.ErrResponseDontShowError					"威胁情报源名称已存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError					"服务器内部错误"
+//	@Router			/api/v1/threat-feeds/{id} [put]
+func (c *ThreatFeedControllerImpl) UpdateThreatFeed(ctx *gin.Context) {
+	id := ctx.Param("id")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", id).Msg("无效的ID格式")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	var req dto.ThreatFeedUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Warn().Err(err).Str("id", id).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	c.logger.Info().Str("id", id).Msg("更新威胁情报源请求")
+	feed, err := c.threatFeedService.UpdateThreatFeed(ctx, objectID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrThreatFeedNotFound) {
+			response.NotFound(ctx, err)
+			return
+		} else if errors.Is(err, service.ErrThreatFeedNameExists) {
+			response.Error(ctx, model.NewAPIError(http.StatusConflict, "威胁情报源名称已存在", err), false)
+			return
+		} else if errors.Is(err, service.ErrInvalidThreatFeedSource) {
+			response.BadRequest(ctx, err, true)
+			return
+		}
+		c.logger.Error().Err(err).Str("id", id).Msg("更新威胁情报源失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	c.logger.Info().Str("id", id).Str("name", feed.Name).Msg("威胁情报源更新成功")
+	response.Success(ctx, "威胁情报源更新成功", feed)
+}
+
+// DeleteThreatFeed 删除威胁情报源
+//
+//	@Summary		删除威胁情报源
+//	@Description	删除威胁情报源订阅，同步的IP组及其条目会保留
+//	@Tags			威胁情报源
+//	@Produce		json
+//	@Param			id	path	string	true	"威胁情报源ID"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponseNoData		"威胁情报源删除成功"
+//	@Failure		400	{object}	model.ErrResponse				"无效的ID格式"
+//	@Failure		401	{object}	model.ErrResponseDontShowError	"未授权访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError	"威胁情报源不存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError	"服务器内部错误"
+//	@Router			/api/v1/threat-feeds/{id} [delete]
+func (c *ThreatFeedControllerImpl) DeleteThreatFeed(ctx *gin.Context) {
+	id := ctx.Param("id")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", id).Msg("无效的ID格式")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	c.logger.Info().Str("id", id).Msg("删除威胁情报源请求")
+	if err := c.threatFeedService.DeleteThreatFeed(ctx, objectID); err != nil {
+		if errors.Is(err, service.ErrThreatFeedNotFound) {
+			response.NotFound(ctx, err)
+			return
+		}
+		c.logger.Error().Err(err).Str("id", id).Msg("删除威胁情报源失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "威胁情报源删除成功", nil)
+}
+
+// RefreshThreatFeed 立即同步威胁情报源
+//
+//	@Summary		立即同步威胁情报源
+//	@Description	立即拉取威胁情报源并同步到IP组，不等待刷新间隔；同步失败时IP组保留上一次成功同步的数据
+//	@Tags			威胁情报源
+//	@Produce		json
+//	@Param			id	path	string	true	"威胁情报源ID"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=model.ThreatFeed}	"威胁情报源同步成功"
+//	@Failure		400	{object}	model.ErrResponse								"无效的ID格式"
+//	@Failure		401	{object}	model.ErrResponseDontShowError					"未授权访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError					"威胁情报源不存在"
+//	@Failure		502	{object}	model.ErrResponse								"威胁情报源同步失败"
+//	@Failure		500	{object}	model.ErrResponseDontShowError					"服务器内部错误"
+//	@Router			/api/v1/threat-feeds/{id}/refresh [post]
+func (c *ThreatFeedControllerImpl) RefreshThreatFeed(ctx *gin.Context) {
+	id := ctx.Param("id")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", id).Msg("无效的ID格式")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	c.logger.Info().Str("id", id).Msg("立即同步威胁情报源请求")
+	feed, err := c.threatFeedService.RefreshThreatFeed(ctx, objectID)
+	if err != nil {
+		if errors.Is(err, service.ErrThreatFeedNotFound) {
+			response.NotFound(ctx, err)
+			return
+		} else if errors.Is(err, service.ErrThreatFeedSyncFailed) {
+			response.Error(ctx, model.NewAPIError(http.StatusBadGateway, "威胁情报源同步失败", err), true)
+			return
+		}
+		c.logger.Error().Err(err).Str("id", id).Msg("同步威胁情报源失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "威胁情报源同步成功", feed)
+}
@@ -0,0 +1,249 @@
+package controller
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/HUAHUAI23/RuiQi/server/service"
+	"github.com/HUAHUAI23/RuiQi/server/utils/response"
+	"github.com/gin-gonic/gin"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// TrapPathController 蜜罐陷阱路径控制器接口
+type TrapPathController interface {
+	CreateTrapPath(ctx *gin.Context)
+	GetTrapPaths(ctx *gin.Context)
+	GetTrapPathByID(ctx *gin.Context)
+	UpdateTrapPath(ctx *gin.Context)
+	DeleteTrapPath(ctx *gin.Context)
+}
+
+// TrapPathControllerImpl 蜜罐陷阱路径控制器实现
+type TrapPathControllerImpl struct {
+	trapPathService service.TrapPathService
+	logger          zerolog.Logger
+}
+
+// NewTrapPathController 创建蜜罐陷阱路径控制器
+func NewTrapPathController(trapPathService service.TrapPathService) TrapPathController {
+	logger := config.GetControllerLogger("trappath")
+	return &TrapPathControllerImpl{
+		trapPathService: trapPathService,
+		logger:          logger,
+	}
+}
+
+// CreateTrapPath 创建蜜罐陷阱路径
+//
+//	@Summary		创建蜜罐陷阱路径
+//	@Description	创建只会被扫描器访问的陷阱路径，访问后立即按封禁时长封禁来源IP并记录安全日志；配置诱饵响应时返回伪装内容而不是 403
+//	@Tags			蜜罐陷阱
+//	@Accept			json
+//	@Produce		json
+//	@Param			trap	body	dto.TrapPathCreateRequest	true	"蜜罐陷阱路径信息"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=model.TrapPath}	"蜜罐陷阱路径创建成功"
+//	@Failure		400	{object}	model.ErrResponse							"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError				"未授权访问"
+//	@Failure		403	{object}	model.ErrResponseDontShowError				"禁止访问"
+//	@Failure		409	{object}	model.ErrResponseDontShowError				"蜜罐陷阱路径名称已存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError				"服务器内部错误"
+//	@Router			/api/v1/trap-paths [post]
+func (c *TrapPathControllerImpl) CreateTrapPath(ctx *gin.Context) {
+	var req dto.TrapPathCreateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Warn().Err(err).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	c.logger.Info().Str("name", req.Name).Str("path", req.Path).Msg("创建蜜罐陷阱路径请求")
+	trap, err := c.trapPathService.CreateTrapPath(ctx, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrTrapPathNameExists) {
+			response.Error(ctx, model.NewAPIError(http.StatusConflict, "蜜罐陷阱路径名称已存在", err), false)
+			return
+		} else if errors.Is(err, service.ErrScopeSiteNotFound) {
+			response.BadRequest(ctx, err, true)
+			return
+		}
+		c.logger.Error().Err(err).Msg("创建蜜罐陷阱路径失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	c.logger.Info().Str("id", trap.ID.Hex()).Str("name", trap.Name).Msg("蜜罐陷阱路径创建成功")
+	response.Success(ctx, "蜜罐陷阱路径创建成功", trap)
+}
+
+// GetTrapPaths 获取蜜罐陷阱路径列表
+//
+//	@Summary		获取蜜罐陷阱路径列表
+//	@Description	获取蜜罐陷阱路径列表，按名称排序，可按站点过滤
+//	@Tags			蜜罐陷阱
+//	@Produce		json
+//	@Param			page			query	int		false	"页码，从1开始"					default(1)	minimum(1)
+//	@Param			size			query	int		false	"每页数量，最大100"				default(10)	minimum(1)	maximum(100)
+//	@Param			siteId			query	string	false	"站点ID，只返回作用于该站点的陷阱路径"
+//	@Param			includeGlobal	query	bool	false	"按站点过滤时是否包含全局陷阱路径"	default(true)
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=dto.TrapPathListResponse}	"获取蜜罐陷阱路径列表成功"
+//	@Failure		400	{object}	model.ErrResponse										"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError							"未授权访问"
+//	@Failure		500	{object}	model.ErrResponseDontShowError							"服务器内部错误"
+//	@Router			/api/v1/trap-paths [get]
+func (c *TrapPathControllerImpl) GetTrapPaths(ctx *gin.Context) {
+	var req dto.TrapPathListRequest
+	if err := ctx.ShouldBindQuery(&req); err != nil {
+		c.logger.Warn().Err(err).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	result, err := c.trapPathService.GetTrapPaths(ctx, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrScopeSiteNotFound) {
+			response.BadRequest(ctx, err, true)
+			return
+		}
+		c.logger.Error().Err(err).Msg("获取蜜罐陷阱路径列表失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "获取蜜罐陷阱路径列表成功", result)
+}
+
+// GetTrapPathByID 获取单个蜜罐陷阱路径
+//
+//	@Summary		获取单个蜜罐陷阱路径
+//	@Description	根据ID获取蜜罐陷阱路径详情
+//	@Tags			蜜罐陷阱
+//	@Produce		json
+//	@Param			id	path	string	true	"蜜罐陷阱路径ID"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=model.TrapPath}	"获取蜜罐陷阱路径详情成功"
+//	@Failure		400	{object}	model.ErrResponse							"无效的ID格式"
+//	@Failure		401	{object}	model.ErrResponseDontShowError				"未授权访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError				"蜜罐陷阱路径不存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError				"服务器内部错误"
+//	@Router			/api/v1/trap-paths/{id} [get]
+func (c *TrapPathControllerImpl) GetTrapPathByID(ctx *gin.Context) {
+	id := ctx.Param("id")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", id).Msg("无效的ID格式")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	trap, err := c.trapPathService.GetTrapPathByID(ctx, objectID)
+	if err != nil {
+		if errors.Is(err, service.ErrTrapPathNotFound) {
+			response.NotFound(ctx, err)
+			return
+		}
+		c.logger.Error().Err(err).Str("id", id).Msg("获取蜜罐陷阱路径详情失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "获取蜜罐陷阱路径详情成功", trap)
+}
+
+// UpdateTrapPath 更新蜜罐陷阱路径
+//
+//	@Summary		更新蜜罐陷阱路径
+//	@Description	更新蜜罐陷阱路径配置，未传入的字段保持不变；已封禁的IP不受影响
+//	@Tags			蜜罐陷阱
+//	@Accept			json
+//	@Produce		json
+//	@Param			id		path	string						true	"蜜罐陷阱路径ID"
+//	@Param			trap	body	dto.TrapPathUpdateRequest	true	"蜜罐陷阱路径更新信息"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponse{data=model.TrapPath}	"蜜罐陷阱路径更新成功"
+//	@Failure		400	{object}	model.ErrResponse							"请求参数错误"
+//	@Failure		401	{object}	model.ErrResponseDontShowError				"未授权访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError				"蜜罐陷阱路径不存在"
+//	@Failure		409	{object}	model.ErrResponseDontShowError				"蜜罐陷阱路径名称已存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError				"服务器内部错误"
+//	@Router			/api/v1/trap-paths/{id} [put]
+func (c *TrapPathControllerImpl) UpdateTrapPath(ctx *gin.Context) {
+	id := ctx.Param("id")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", id).Msg("无效的ID格式")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	var req dto.TrapPathUpdateRequest
+	if err := ctx.ShouldBindJSON(&req); err != nil {
+		c.logger.Warn().Err(err).Str("id", id).Msg("请求参数绑定失败")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	c.logger.Info().Str("id", id).Msg("更新蜜罐陷阱路径请求")
+	trap, err := c.trapPathService.UpdateTrapPath(ctx, objectID, &req)
+	if err != nil {
+		if errors.Is(err, service.ErrTrapPathNotFound) {
+			response.NotFound(ctx, err)
+			return
+		} else if errors.Is(err, service.ErrTrapPathNameExists) {
+			response.Error(ctx, model.NewAPIError(http.StatusConflict, "蜜罐陷阱路径名称已存在", err), false)
+			return
+		} else if errors.Is(err, service.ErrScopeSiteNotFound) {
+			response.BadRequest(ctx, err, true)
+			return
+		}
+		c.logger.Error().Err(err).Str("id", id).Msg("更新蜜罐陷阱路径失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	c.logger.Info().Str("id", id).Str("name", trap.Name).Msg("蜜罐陷阱路径更新成功")
+	response.Success(ctx, "蜜罐陷阱路径更新成功", trap)
+}
+
+// DeleteTrapPath 删除蜜罐陷阱路径
+//
+//	@Summary		删除蜜罐陷阱路径
+//	@Description	删除蜜罐陷阱路径，已封禁的IP会在封禁到期后自动解封
+//	@Tags			蜜罐陷阱
+//	@Produce		json
+//	@Param			id	path	string	true	"蜜罐陷阱路径ID"
+//	@Security		BearerAuth
+//	@Success		200	{object}	model.SuccessResponseNoData		"蜜罐陷阱路径删除成功"
+//	@Failure		400	{object}	model.ErrResponse				"无效的ID格式"
+//	@Failure		401	{object}	model.ErrResponseDontShowError	"未授权访问"
+//	@Failure		404	{object}	model.ErrResponseDontShowError	"蜜罐陷阱路径不存在"
+//	@Failure		500	{object}	model.ErrResponseDontShowError	"服务器内部错误"
+//	@Router			/api/v1/trap-paths/{id} [delete]
+func (c *TrapPathControllerImpl) DeleteTrapPath(ctx *gin.Context) {
+	id := ctx.Param("id")
+	objectID, err := bson.ObjectIDFromHex(id)
+	if err != nil {
+		c.logger.Error().Err(err).Str("id", id).Msg("无效的ID格式")
+		response.BadRequest(ctx, err, true)
+		return
+	}
+
+	c.logger.Info().Str("id", id).Msg("删除蜜罐陷阱路径请求")
+	if err := c.trapPathService.DeleteTrapPath(ctx, objectID); err != nil {
+		if errors.Is(err, service.ErrTrapPathNotFound) {
+			response.NotFound(ctx, err)
+			return
+		}
+		c.logger.Error().Err(err).Str("id", id).Msg("删除蜜罐陷阱路径失败")
+		response.InternalServerError(ctx, err, false)
+		return
+	}
+
+	response.Success(ctx, "蜜罐陷阱路径删除成功", nil)
+}
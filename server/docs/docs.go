@@ -130,6 +130,125 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/audit": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取配置变更的审计日志，包括定时任务自动清理过期规则和IP组条目的记录，按操作时间倒序",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "审计日志"
+                ],
+                "summary": "获取审计日志列表",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码，从1开始",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量，最大100",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "expire",
+                        "description": "操作类型过滤",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "ip_group",
+                        "description": "资源类型过滤",
+                        "name": "resourceType",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "资源ID过滤",
+                        "name": "resourceId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "example": "system",
+                        "description": "操作人过滤",
+                        "name": "operator",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "查询起始时间 (ISO8601格式，如: 2024-03-17T00:00:00Z)",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "查询结束时间 (ISO8601格式，如: 2024-03-18T23:59:59Z)",
+                        "name": "endTime",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取审计日志成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.AuditLogListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/blocked-ips": {
             "get": {
                 "security": [
@@ -376,7 +495,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取所有SSL/TLS证书列表，支持分页",
+                "description": "获取所有SSL/TLS证书列表，支持分页。expiryState 为证书的过期状态：ok、expiring30、expiring14、expiring7 或 expired",
                 "produces": [
                     "application/json"
                 ],
@@ -439,7 +558,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "创建一个新的SSL/TLS证书",
+                "description": "创建一个新的SSL/TLS证书，服务端解析证书获取过期日期、颁发机构、域名和 SHA-256 指纹。\n证书链需按站点证书、中间证书的顺序排列且完整，私钥需与证书匹配，支持 2048 位及以上的 RSA 和 P-256、P-384 的 ECDSA 密钥；加密的 PKCS#8 私钥需提供密码。\n未指定证书的 HTTPS 站点会按域名自动选择包含其所有域名且过期时间最晚的证书",
                 "consumes": [
                     "application/json"
                 ],
@@ -513,6 +632,142 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/certificates/acme": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "通过 ACME 为指定域名签发证书并保存到证书库，签发完成后返回，证书在到期前自动续期。\nHTTP-01 验证要求域名解析到 WAF 且 80 端口上配置了站点，验证请求由 HAProxy 转发到管理服务；通配符域名只能使用 DNS-01 验证，需要配置 DNS 服务商",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "证书管理"
+                ],
+                "summary": "通过 ACME 签发证书",
+                "parameters": [
+                    {
+                        "description": "签发参数",
+                        "name": "certificate",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ACMECertificateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "证书签发成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.CertificateStore"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "409": {
+                        "description": "证书名称已存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "证书签发失败",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/certificates/expiry-summary": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "统计证书库中的证书和仍在使用的站点内嵌证书在各过期状态（ok、expiring30、expiring14、expiring7、expired）的数量，\n并返回即将过期和已过期的证书，供仪表盘展示",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "证书管理"
+                ],
+                "summary": "获取证书过期概览",
+                "responses": {
+                    "200": {
+                        "description": "获取证书过期概览成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.CertificateExpirySummary"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/certificates/{id}": {
             "get": {
                 "security": [
@@ -582,7 +837,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "更新指定证书的信息",
+                "description": "更新指定证书的信息，更新证书链或私钥时重新解析和校验，校验规则与创建证书相同；手动替换 ACME 证书的内容后不再自动续期。\n使用该证书的站点同步更新，HAProxy 运行中时通过运行时 API 热加载",
                 "consumes": [
                     "application/json"
                 ],
@@ -674,7 +929,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "删除指定的SSL/TLS证书",
+                "description": "删除指定的SSL/TLS证书，指定了该证书或只能使用该证书的站点存在时不能删除",
                 "produces": [
                     "application/json"
                 ],
@@ -716,6 +971,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
+                    "409": {
+                        "description": "证书正在被站点使用",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
@@ -725,24 +986,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/config": {
-            "get": {
+        "/api/v1/certificates/{id}/renew": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取当前系统配置信息",
+                "description": "立即重新签发 ACME 证书，使用该证书的站点同步更新，HAProxy 运行中时通过运行时 API 热加载，不重新加载配置",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "配置管理"
+                    "证书管理"
+                ],
+                "summary": "立即续期 ACME 证书",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "证书ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "获取系统配置",
                 "responses": {
                     "200": {
-                        "description": "获取配置成功",
+                        "description": "证书续期成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -752,13 +1022,19 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.ConfigResponse"
+                                            "$ref": "#/definitions/model.CertificateStore"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "证书不是通过 ACME 签发的",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
                     "401": {
                         "description": "未授权访问",
                         "schema": {
@@ -772,7 +1048,68 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "配置不存在",
+                        "description": "证书不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "证书续期失败",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/config": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取当前系统配置信息",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "配置管理"
+                ],
+                "summary": "获取系统配置",
+                "responses": {
+                    "200": {
+                        "description": "获取配置成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.ConfigResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "404": {
+                        "description": "配置不存在",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
@@ -872,7 +1209,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取所有IP组列表，支持分页",
+                "description": "获取所有IP组列表，支持分页和按站点过滤",
                 "produces": [
                     "application/json"
                 ],
@@ -894,6 +1231,19 @@ const docTemplate = `{
                         "description": "每页数量",
                         "name": "size",
                         "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "站点ID，只返回作用于该站点的IP组",
+                        "name": "siteId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "按站点过滤时是否包含全局IP组",
+                        "name": "includeGlobal",
+                        "in": "query"
                     }
                 ],
                 "responses": {
@@ -915,6 +1265,12 @@ const docTemplate = `{
                             ]
                         }
                     },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
                     "401": {
                         "description": "未授权访问",
                         "schema": {
@@ -1072,6 +1428,161 @@ const docTemplate = `{
                 }
             }
         },
+        "/api/v1/ip-groups/import": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "上传纯文本、CSV 或 JSON 文件创建新的IP组，跳过注释和无效行，条目去重后合并相邻或重叠的CIDR，返回导入报告",
+                "consumes": [
+                    "multipart/form-data"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "IP组管理"
+                ],
+                "summary": "从文件导入创建IP组",
+                "parameters": [
+                    {
+                        "type": "file",
+                        "description": "导入文件",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "IP组名称",
+                        "name": "name",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "txt",
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "文件格式，为空时根据扩展名判断",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "只生成导入报告，不保存",
+                        "name": "dryRun",
+                        "in": "formData"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "IP组导入成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.IPGroupImportReport"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或文件格式错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "409": {
+                        "description": "IP组名称已存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "413": {
+                        "description": "导入文件过大",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/ip-groups/references": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取所有被微规则引用的IP组及引用它们的规则，exists 为 false 表示规则引用的IP组不存在",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "IP组管理"
+                ],
+                "summary": "获取所有IP组引用",
+                "responses": {
+                    "200": {
+                        "description": "获取IP组引用成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.IPGroupReferencesResponse"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
         "/api/v1/ip-groups/{id}": {
             "get": {
                 "security": [
@@ -1147,7 +1658,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "更新指定IP组的信息",
+                "description": "更新指定IP组的信息。重命名被微规则引用的IP组时需要设置 updateReferences，引用规则的条件会同时更新",
                 "consumes": [
                     "application/json"
                 ],
@@ -1220,9 +1731,9 @@ const docTemplate = `{
                         }
                     },
                     "409": {
-                        "description": "IP组名称已存在",
+                        "description": "IP组名称已存在，或IP组被规则引用且未要求同时更新引用",
                         "schema": {
-                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                            "$ref": "#/definitions/model.ErrResponse"
                         }
                     },
                     "500": {
@@ -1239,7 +1750,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "删除指定的IP组，系统默认IP组不允许删除",
+                "description": "删除指定的IP组，系统默认IP组和被微规则引用的IP组不允许删除",
                 "produces": [
                     "application/json"
                 ],
@@ -1287,6 +1798,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
+                    "409": {
+                        "description": "IP组被规则引用",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
@@ -1296,54 +1813,55 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/micro-rules": {
+        "/api/v1/ip-groups/{id}/export": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取所有WAF微规则列表，支持分页",
+                "description": "将IP组条目导出为纯文本、CSV 或 JSON 文件，导出的文件可以直接重新导入",
                 "produces": [
+                    "text/plain",
+                    "text/csv",
                     "application/json"
                 ],
                 "tags": [
-                    "规则管理"
+                    "IP组管理"
                 ],
-                "summary": "获取微规则列表",
+                "summary": "导出IP组",
                 "parameters": [
                     {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
+                        "type": "string",
+                        "description": "IP组ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "每页数量",
-                        "name": "size",
+                        "enum": [
+                            "txt",
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "default": "txt",
+                        "description": "文件格式",
+                        "name": "format",
                         "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取微规则列表成功",
+                        "description": "导出文件",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/model.SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/dto.MicroRuleListResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "type": "file"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
                         }
                     },
                     "401": {
@@ -1352,6 +1870,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
+                    "404": {
+                        "description": "IP组不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
@@ -1359,38 +1883,73 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
+            }
+        },
+        "/api/v1/ip-groups/{id}/import": {
             "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "创建一个新的WAF微规则，用于匹配和过滤请求",
+                "description": "上传纯文本、CSV 或 JSON 文件，与现有条目合并或替换现有条目，跳过注释和无效行，条目去重后合并相邻或重叠的CIDR，返回导入报告",
                 "consumes": [
-                    "application/json"
+                    "multipart/form-data"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "规则管理"
+                    "IP组管理"
                 ],
-                "summary": "创建微规则",
+                "summary": "从文件导入IP组条目",
                 "parameters": [
                     {
-                        "description": "微规则信息",
-                        "name": "rule",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.MicroRuleCreateRequest"
-                        }
+                        "type": "string",
+                        "description": "IP组ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "file",
+                        "description": "导入文件",
+                        "name": "file",
+                        "in": "formData",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "txt",
+                            "csv",
+                            "json"
+                        ],
+                        "type": "string",
+                        "description": "文件格式，为空时根据扩展名判断",
+                        "name": "format",
+                        "in": "formData"
+                    },
+                    {
+                        "enum": [
+                            "merge",
+                            "replace"
+                        ],
+                        "type": "string",
+                        "default": "merge",
+                        "description": "导入模式",
+                        "name": "mode",
+                        "in": "formData"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "只生成导入报告，不保存",
+                        "name": "dryRun",
+                        "in": "formData"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "微规则创建成功",
+                        "description": "IP组导入成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1400,7 +1959,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.MicroRuleResponse"
+                                            "$ref": "#/definitions/dto.IPGroupImportReport"
                                         }
                                     }
                                 }
@@ -1408,7 +1967,7 @@ const docTemplate = `{
                         }
                     },
                     "400": {
-                        "description": "请求参数错误",
+                        "description": "请求参数错误或文件格式错误",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponse"
                         }
@@ -1419,14 +1978,14 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
-                    "403": {
-                        "description": "禁止访问",
+                    "404": {
+                        "description": "IP组不存在",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
-                    "409": {
-                        "description": "微规则名称已存在",
+                    "413": {
+                        "description": "导入文件过大",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
@@ -1440,25 +1999,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/micro-rules/{id}": {
+        "/api/v1/ip-groups/{id}/references": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "根据ID获取微规则详情",
+                "description": "获取引用指定IP组的微规则列表",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "规则管理"
+                    "IP组管理"
                 ],
-                "summary": "获取单个微规则",
+                "summary": "获取IP组引用",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "微规则ID",
+                        "description": "IP组ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1466,7 +2025,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取微规则详情成功",
+                        "description": "获取IP组引用成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1476,7 +2035,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.MicroRuleResponse"
+                                            "$ref": "#/definitions/dto.IPGroupReferencesResponse"
                                         }
                                     }
                                 }
@@ -1496,7 +2055,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "微规则不存在",
+                        "description": "IP组不存在",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
@@ -1508,45 +2067,55 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "put": {
+            }
+        },
+        "/api/v1/micro-rules": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "更新指定微规则的信息，系统默认规则不允许修改",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "获取所有WAF微规则列表，支持分页和按站点过滤，每条规则附带累计命中次数、拦截次数、最后命中时间和是否卸载到 HAProxy 判断",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "规则管理"
                 ],
-                "summary": "更新微规则",
+                "summary": "获取微规则列表",
                 "parameters": [
+                    {
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
+                    },
                     {
                         "type": "string",
-                        "description": "微规则ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "站点ID，只返回作用于该站点的规则",
+                        "name": "siteId",
+                        "in": "query"
                     },
                     {
-                        "description": "微规则更新信息",
-                        "name": "rule",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.MicroRuleUpdateRequest"
-                        }
+                        "type": "boolean",
+                        "default": true,
+                        "description": "按站点过滤时是否包含全局规则",
+                        "name": "includeGlobal",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "微规则更新成功",
+                        "description": "获取微规则列表成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1556,7 +2125,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.MicroRuleResponse"
+                                            "$ref": "#/definitions/dto.MicroRuleListResponse"
                                         }
                                     }
                                 }
@@ -1575,24 +2144,6 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
-                    "403": {
-                        "description": "禁止修改系统默认规则",
-                        "schema": {
-                            "$ref": "#/definitions/model.ErrResponseDontShowError"
-                        }
-                    },
-                    "404": {
-                        "description": "微规则不存在",
-                        "schema": {
-                            "$ref": "#/definitions/model.ErrResponseDontShowError"
-                        }
-                    },
-                    "409": {
-                        "description": "微规则名称已存在",
-                        "schema": {
-                            "$ref": "#/definitions/model.ErrResponseDontShowError"
-                        }
-                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
@@ -1601,38 +2152,55 @@ const docTemplate = `{
                     }
                 }
             },
-            "delete": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "删除指定的微规则，系统默认规则不允许删除",
+                "description": "创建一个新的WAF微规则，用于匹配和过滤请求",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
                     "规则管理"
                 ],
-                "summary": "删除微规则",
+                "summary": "创建微规则",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "微规则ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "微规则信息",
+                        "name": "rule",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.MicroRuleCreateRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "微规则删除成功",
+                        "description": "微规则创建成功",
                         "schema": {
-                            "$ref": "#/definitions/model.SuccessResponseNoData"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.MicroRuleResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
-                        "description": "无效的ID格式",
+                        "description": "请求参数错误",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponse"
                         }
@@ -1644,13 +2212,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "禁止删除系统默认规则",
+                        "description": "禁止访问",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
-                    "404": {
-                        "description": "微规则不存在",
+                    "409": {
+                        "description": "微规则名称已存在",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
@@ -1664,40 +2232,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/site": {
+        "/api/v1/micro-rules/analysis": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取所有站点配置列表",
+                "description": "按规则引擎的匹配顺序静态分析所有微规则，报告被更高优先级规则覆盖、白名单与黑名单冲突、重复、条件不可达（如空IP组）、正则表达式无效等问题",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "站点管理"
-                ],
-                "summary": "获取站点列表",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "default": 1,
-                        "description": "页码",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "default": 10,
-                        "description": "每页数量",
-                        "name": "size",
-                        "in": "query"
-                    }
+                    "规则管理"
                 ],
+                "summary": "分析微规则",
                 "responses": {
                     "200": {
-                        "description": "获取站点列表成功",
+                        "description": "微规则分析成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1707,7 +2259,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.SiteListResponse"
+                                            "$ref": "#/definitions/dto.RuleAnalysisResponse"
                                         }
                                     }
                                 }
@@ -1727,38 +2279,26 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/api/v1/micro-rules/offload": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "创建一个新的站点配置",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "按规则引擎的匹配顺序列出每条微规则是否卸载到 HAProxy 判断。已启用、对所有站点生效、没有生效计划且条件只使用IP、CIDR、全局IP组、路径和URL匹配的规则编译为 HAProxy ACL，其余规则由 WAF 引擎判断，两者的拦截结果一致；结果在下次应用站点配置或热重载后生效",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "站点管理"
-                ],
-                "summary": "创建新站点",
-                "parameters": [
-                    {
-                        "description": "站点信息",
-                        "name": "site",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.CreateSiteRequest"
-                        }
-                    }
+                    "规则管理"
                 ],
+                "summary": "获取微规则卸载状态",
                 "responses": {
                     "200": {
-                        "description": "站点创建成功",
+                        "description": "获取微规则卸载状态成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1768,37 +2308,19 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.SiteResponse"
+                                            "$ref": "#/definitions/dto.RuleOffloadResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "请求参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/model.ErrResponse"
-                        }
-                    },
                     "401": {
                         "description": "未授权访问",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
-                    "403": {
-                        "description": "禁止访问",
-                        "schema": {
-                            "$ref": "#/definitions/model.ErrResponseDontShowError"
-                        }
-                    },
-                    "409": {
-                        "description": "域名和端口组合已存在",
-                        "schema": {
-                            "$ref": "#/definitions/model.ErrResponseDontShowError"
-                        }
-                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
@@ -1808,25 +2330,25 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/site/{id}": {
+        "/api/v1/micro-rules/{id}": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "根据ID获取站点详情",
+                "description": "根据ID获取微规则详情",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "站点管理"
+                    "规则管理"
                 ],
-                "summary": "获取单个站点",
+                "summary": "获取单个微规则",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "站点ID",
+                        "description": "微规则ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1834,7 +2356,7 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取站点详情成功",
+                        "description": "获取微规则详情成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1844,13 +2366,19 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.SiteResponse"
+                                            "$ref": "#/definitions/dto.MicroRuleResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "无效的ID格式",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
                     "401": {
                         "description": "未授权访问",
                         "schema": {
@@ -1858,7 +2386,7 @@ const docTemplate = `{
                         }
                     },
                     "404": {
-                        "description": "站点不存在",
+                        "description": "微规则不存在",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
@@ -1877,7 +2405,7 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "更新指定站点的配置",
+                "description": "更新指定微规则的信息，系统默认规则不允许修改",
                 "consumes": [
                     "application/json"
                 ],
@@ -1885,30 +2413,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "站点管理"
+                    "规则管理"
                 ],
-                "summary": "更新站点",
+                "summary": "更新微规则",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "站点ID",
+                        "description": "微规则ID",
                         "name": "id",
                         "in": "path",
                         "required": true
                     },
                     {
-                        "description": "站点更新信息",
-                        "name": "site",
+                        "description": "微规则更新信息",
+                        "name": "rule",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UpdateSiteRequest"
+                            "$ref": "#/definitions/dto.MicroRuleUpdateRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "站点更新成功",
+                        "description": "微规则更新成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -1918,7 +2446,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.SiteResponse"
+                                            "$ref": "#/definitions/dto.MicroRuleResponse"
                                         }
                                     }
                                 }
@@ -1938,19 +2466,19 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "禁止访问",
+                        "description": "禁止修改系统默认规则",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
                     "404": {
-                        "description": "站点不存在",
+                        "description": "微规则不存在",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
                     "409": {
-                        "description": "域名和端口组合已被其他站点使用",
+                        "description": "微规则名称已存在",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
@@ -1969,18 +2497,18 @@ const docTemplate = `{
                         "BearerAuth": []
                     }
                 ],
-                "description": "删除指定的站点配置",
+                "description": "删除指定的微规则，系统默认规则不允许删除",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "站点管理"
+                    "规则管理"
                 ],
-                "summary": "删除站点",
+                "summary": "删除微规则",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "站点ID",
+                        "description": "微规则ID",
                         "name": "id",
                         "in": "path",
                         "required": true
@@ -1988,11 +2516,17 @@ const docTemplate = `{
                 ],
                 "responses": {
                     "200": {
-                        "description": "站点删除成功",
+                        "description": "微规则删除成功",
                         "schema": {
                             "$ref": "#/definitions/model.SuccessResponseNoData"
                         }
                     },
+                    "400": {
+                        "description": "无效的ID格式",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
                     "401": {
                         "description": "未授权访问",
                         "schema": {
@@ -2000,13 +2534,13 @@ const docTemplate = `{
                         }
                     },
                     "403": {
-                        "description": "禁止访问",
+                        "description": "禁止删除系统默认规则",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
                     "404": {
-                        "description": "站点不存在",
+                        "description": "微规则不存在",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
@@ -2020,39 +2554,24 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/stats/combined-time-series": {
+        "/api/v1/runner/config-versions": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "同时获取请求数和拦截数的时间序列数据，用于图表展示",
+                "description": "按时间倒序返回通过 haproxy -c 检查并成功加载过的配置版本，保留数量由 haproxy.backupsNumber 配置",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "统计信息"
-                ],
-                "summary": "获取组合时间序列数据",
-                "parameters": [
-                    {
-                        "enum": [
-                            "24h",
-                            "7d",
-                            "30d"
-                        ],
-                        "type": "string",
-                        "default": "24h",
-                        "description": "时间范围：24h(24小时)、7d(7天)、30d(30天)",
-                        "name": "timeRange",
-                        "in": "query",
-                        "required": true
-                    }
+                    "运行器管理"
                 ],
+                "summary": "获取HAProxy配置版本列表",
                 "responses": {
                     "200": {
-                        "description": "获取组合时间序列数据成功",
+                        "description": "获取配置版本列表成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -2062,25 +2581,16 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.CombinedTimeSeriesResponse"
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/dto.ConfigVersionResponse"
+                                            }
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "请求参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/model.ErrResponse"
-                        }
-                    },
-                    "401": {
-                        "description": "未授权访问",
-                        "schema": {
-                            "$ref": "#/definitions/model.ErrResponseDontShowError"
-                        }
-                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
@@ -2090,39 +2600,39 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/stats/overview": {
+        "/api/v1/runner/config-versions/diff": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取指定时间范围内的统计概览数据，包括请求数、流量、错误率等",
+                "description": "返回两个配置版本 haproxy.cfg 的统一格式差异，未指定目标版本时与当前生效的版本比较",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "统计信息"
+                    "运行器管理"
                 ],
-                "summary": "获取统计概览数据",
+                "summary": "比较HAProxy配置版本",
                 "parameters": [
                     {
-                        "enum": [
-                            "24h",
-                            "7d",
-                            "30d"
-                        ],
                         "type": "string",
-                        "default": "24h",
-                        "description": "时间范围：24h(24小时)、7d(7天)、30d(30天)",
-                        "name": "timeRange",
+                        "description": "源版本ID",
+                        "name": "from",
                         "in": "query",
                         "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "目标版本ID",
+                        "name": "to",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取统计概览成功",
+                        "description": "比较配置版本成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -2132,7 +2642,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.OverviewStats"
+                                            "$ref": "#/definitions/dto.ConfigVersionDiffResponse"
                                         }
                                     }
                                 }
@@ -2145,10 +2655,10 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrResponse"
                         }
                     },
-                    "401": {
-                        "description": "未授权访问",
+                    "404": {
+                        "description": "配置版本不存在",
                         "schema": {
-                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                            "$ref": "#/definitions/model.ErrResponse"
                         }
                     },
                     "500": {
@@ -2160,117 +2670,92 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/stats/realtime-qps": {
-            "get": {
+        "/api/v1/runner/config-versions/{id}/rollback": {
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取最近的实时QPS数据点",
+                "description": "将HAProxy配置、SPOE配置和证书恢复到指定版本并重新加载，配置检查或加载失败时保留当前配置；下次热重载会按站点数据重新生成配置",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "统计信息"
+                    "运行器管理"
                 ],
-                "summary": "获取实时QPS数据",
+                "summary": "回滚HAProxy配置",
                 "parameters": [
                     {
-                        "maximum": 60,
-                        "minimum": 1,
-                        "type": "integer",
-                        "default": 30,
-                        "description": "返回的数据点数量，默认30个点，最大60个点",
-                        "name": "limit",
-                        "in": "query"
+                        "type": "string",
+                        "description": "版本ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取实时QPS数据成功",
+                        "description": "回滚配置成功",
                         "schema": {
-                            "allOf": [
-                                {
-                                    "$ref": "#/definitions/model.SuccessResponse"
-                                },
-                                {
-                                    "type": "object",
-                                    "properties": {
-                                        "data": {
-                                            "$ref": "#/definitions/dto.RealtimeQPSResponse"
-                                        }
-                                    }
-                                }
-                            ]
+                            "$ref": "#/definitions/model.SuccessResponse"
                         }
                     },
                     "400": {
-                        "description": "请求参数错误",
+                        "description": "运行器未在运行",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponse"
                         }
                     },
-                    "401": {
-                        "description": "未授权访问",
+                    "404": {
+                        "description": "配置版本不存在",
                         "schema": {
-                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                            "$ref": "#/definitions/model.ErrResponse"
                         }
                     },
                     "500": {
-                        "description": "服务器内部错误",
+                        "description": "回滚配置失败",
                         "schema": {
-                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                            "$ref": "#/definitions/model.ErrResponse"
                         }
                     }
                 }
             }
         },
-        "/api/v1/stats/time-series": {
+        "/api/v1/site": {
             "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取指定时间范围和指标类型的时间序列数据，用于图表展示",
+                "description": "获取所有站点配置列表",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "统计信息"
+                    "站点管理"
                 ],
-                "summary": "获取时间序列数据",
+                "summary": "获取站点列表",
                 "parameters": [
                     {
-                        "enum": [
-                            "24h",
-                            "7d",
-                            "30d"
-                        ],
-                        "type": "string",
-                        "default": "24h",
-                        "description": "时间范围：24h(24小时)、7d(7天)、30d(30天)",
-                        "name": "timeRange",
-                        "in": "query",
-                        "required": true
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码",
+                        "name": "page",
+                        "in": "query"
                     },
                     {
-                        "enum": [
-                            "requests",
-                            "blocks"
-                        ],
-                        "type": "string",
-                        "default": "requests",
-                        "description": "指标类型：requests(请求数)、blocks(拦截数)",
-                        "name": "metric",
-                        "in": "query",
-                        "required": true
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量",
+                        "name": "size",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取时间序列数据成功",
+                        "description": "获取站点列表成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -2280,19 +2765,13 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.TimeSeriesResponse"
+                                            "$ref": "#/definitions/dto.SiteListResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "请求参数错误",
-                        "schema": {
-                            "$ref": "#/definitions/model.ErrResponse"
-                        }
-                    },
                     "401": {
                         "description": "未授权访问",
                         "schema": {
@@ -2306,41 +2785,38 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/stats/traffic-time-series": {
-            "get": {
+            },
+            "post": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取指定时间范围的入站和出站流量时间序列数据，用于图表展示",
+                "description": "创建一个新的站点配置，运行器运行时只将该站点的变更增量应用到 HAProxy",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "统计信息"
+                    "站点管理"
                 ],
-                "summary": "获取流量时间序列数据",
+                "summary": "创建新站点",
                 "parameters": [
                     {
-                        "enum": [
-                            "24h",
-                            "7d",
-                            "30d"
-                        ],
-                        "type": "string",
-                        "default": "24h",
-                        "description": "时间范围：24h(24小时)、7d(7天)、30d(30天)",
-                        "name": "timeRange",
-                        "in": "query",
-                        "required": true
+                        "description": "站点信息",
+                        "name": "site",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.CreateSiteRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "获取流量时间序列数据成功",
+                        "description": "站点创建成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -2350,7 +2826,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.TrafficTimeSeriesResponse"
+                                            "$ref": "#/definitions/dto.SiteResponse"
                                         }
                                     }
                                 }
@@ -2369,6 +2845,18 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "409": {
+                        "description": "域名和端口组合已存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
@@ -2378,90 +2866,33 @@ const docTemplate = `{
                 }
             }
         },
-        "/api/v1/waf/logs": {
+        "/api/v1/site/{id}": {
             "get": {
-                "description": "查询详细的WAF攻击日志记录，提供多条件筛选和分页功能，支持按规则ID、IP、域名、端口和时间范围过滤",
-                "consumes": [
-                    "application/json"
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
                 ],
+                "description": "根据ID获取站点详情",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "WAF安全日志"
+                    "站点管理"
                 ],
-                "summary": "获取详细攻击日志",
+                "summary": "获取单个站点",
                 "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "规则ID，触发攻击检测的WAF规则标识",
-                        "name": "ruleId",
-                        "in": "query"
-                    },
                     {
                         "type": "string",
-                        "description": "来源IP地址，攻击者地址",
-                        "name": "srcIp",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "目标IP地址，被攻击的服务器地址",
-                        "name": "dstIp",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "域名，被攻击的站点域名",
-                        "name": "domain",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "来源端口号，发起攻击的端口",
-                        "name": "srcPort",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "目标端口号，被攻击的服务端口",
-                        "name": "dstPort",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "请求ID，唯一标识HTTP请求的ID",
-                        "name": "requestId",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "查询起始时间 (ISO8601格式，如: 2024-03-17T00:00:00Z)",
-                        "name": "startTime",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "查询结束时间 (ISO8601格式，如: 2024-03-18T23:59:59Z)",
-                        "name": "endTime",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "当前页码，从1开始计数 (默认: 1)",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "每页记录数，最大100条 (默认: 10)",
-                        "name": "pageSize",
-                        "in": "query"
+                        "description": "站点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "获取站点详情成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -2471,17 +2902,23 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.AttackLogResponse"
+                                            "$ref": "#/definitions/dto.SiteResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
-                    "400": {
-                        "description": "请求参数错误",
+                    "401": {
+                        "description": "未授权访问",
                         "schema": {
-                            "$ref": "#/definitions/model.ErrResponse"
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "404": {
+                        "description": "站点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
                     "500": {
@@ -2491,11 +2928,14 @@ const docTemplate = `{
                         }
                     }
                 }
-            }
-        },
-        "/api/v1/waf/logs/events": {
-            "get": {
-                "description": "按来源IP、目标端口和域名聚合的攻击事件统计，支持多维度筛选和分页",
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "更新指定站点的配置，运行器运行时只将该站点的变更增量应用到 HAProxy",
                 "consumes": [
                     "application/json"
                 ],
@@ -2503,68 +2943,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "WAF安全日志"
+                    "站点管理"
                 ],
-                "summary": "获取聚合攻击事件",
+                "summary": "更新站点",
                 "parameters": [
                     {
                         "type": "string",
-                        "description": "来源IP地址，攻击者地址",
-                        "name": "srcIp",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "目标IP地址，被攻击的服务器地址",
-                        "name": "dstIp",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "域名，被攻击的站点域名",
-                        "name": "domain",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "来源端口号，发起攻击的端口",
-                        "name": "srcPort",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "目标端口号，被攻击的服务端口",
-                        "name": "dstPort",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "查询起始时间 (ISO8601格式，如: 2024-03-17T00:00:00Z)",
-                        "name": "startTime",
-                        "in": "query"
-                    },
-                    {
-                        "type": "string",
-                        "description": "查询结束时间 (ISO8601格式，如: 2024-03-18T23:59:59Z)",
-                        "name": "endTime",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "当前页码，从1开始计数 (默认: 1)",
-                        "name": "page",
-                        "in": "query"
+                        "description": "站点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
                     {
-                        "type": "integer",
-                        "description": "每页记录数，最大100条 (默认: 10)",
-                        "name": "pageSize",
-                        "in": "query"
+                        "description": "站点更新信息",
+                        "name": "site",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UpdateSiteRequest"
+                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "成功",
+                        "description": "站点更新成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -2574,7 +2976,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.AttackEventResponse"
+                                            "$ref": "#/definitions/dto.SiteResponse"
                                         }
                                     }
                                 }
@@ -2587,6 +2989,86 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrResponse"
                         }
                     },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "404": {
+                        "description": "站点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "409": {
+                        "description": "域名和端口组合已被其他站点使用",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "删除指定的站点配置，运行器运行时只从 HAProxy 中删除该站点的配置",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "站点管理"
+                ],
+                "summary": "删除站点",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "站点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "站点删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessResponseNoData"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "404": {
+                        "description": "站点不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
@@ -2596,9 +3078,14 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/login": {
+        "/api/v1/site/{id}/servers": {
             "post": {
-                "description": "用户登录并获取JWT令牌",
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "通过 HAProxy 运行时 API 向站点默认后端或路径路由的后端添加服务器，无需重新加载配置，并保存到站点配置；HAProxy 未运行时只保存配置",
                 "consumes": [
                     "application/json"
                 ],
@@ -2606,23 +3093,30 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "认证"
+                    "站点管理"
                 ],
-                "summary": "用户登录",
+                "summary": "添加后端服务器",
                 "parameters": [
                     {
-                        "description": "登录信息",
+                        "type": "string",
+                        "description": "站点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "服务器信息",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UserLoginRequest"
+                            "$ref": "#/definitions/dto.AddSiteServerRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "登录成功",
+                        "description": "添加服务器成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -2632,7 +3126,7 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.LoginResponseData"
+                                            "$ref": "#/definitions/dto.SiteResponse"
                                         }
                                     }
                                 }
@@ -2646,12 +3140,24 @@ const docTemplate = `{
                         }
                     },
                     "401": {
-                        "description": "用户名或密码错误",
+                        "description": "未授权访问",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
-                    "500": {
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "404": {
+                        "description": "站点或路径路由不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
                         "description": "服务器内部错误",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
@@ -2660,24 +3166,40 @@ const docTemplate = `{
                 }
             }
         },
-        "/auth/me": {
-            "get": {
+        "/api/v1/site/{id}/servers/{server}": {
+            "delete": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取当前登录用户的详细信息",
+                "description": "通过 HAProxy 运行时 API 删除服务器，无需重新加载配置，并保存到站点配置；有活动连接的服务器需要先 drain",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "认证"
+                    "站点管理"
+                ],
+                "summary": "删除后端服务器",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "站点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "HAProxy 服务器名称，见站点详情的 serverStatus",
+                        "name": "server",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
-                "summary": "获取当前用户信息",
                 "responses": {
                     "200": {
-                        "description": "获取用户信息成功",
+                        "description": "删除服务器成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -2687,66 +3209,33 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "$ref": "#/definitions/dto.GetUserInfoResponseData"
+                                            "$ref": "#/definitions/dto.SiteResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "不能删除后端的最后一个服务器",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
                     "401": {
                         "description": "未授权访问",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
-                    }
-                }
-            }
-        },
-        "/auth/reset-password": {
-            "post": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "用户重置自己的密码",
-                "consumes": [
-                    "application/json"
-                ],
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "认证"
-                ],
-                "summary": "重置密码",
-                "parameters": [
-                    {
-                        "description": "密码重置信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserPasswordResetRequest"
-                        }
-                    }
-                ],
-                "responses": {
-                    "200": {
-                        "description": "密码重置成功",
-                        "schema": {
-                            "$ref": "#/definitions/model.SuccessResponseNoData"
-                        }
                     },
-                    "400": {
-                        "description": "请求参数错误或原密码错误",
+                    "403": {
+                        "description": "禁止访问",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
-                    "401": {
-                        "description": "未授权访问",
+                    "404": {
+                        "description": "站点或服务器不存在",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
@@ -2760,61 +3249,52 @@ const docTemplate = `{
                 }
             }
         },
-        "/stats": {
-            "get": {
+        "/api/v1/site/{id}/servers/{server}/state": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "获取HAProxy原始的统计信息",
+                "description": "通过 HAProxy 运行时 API 将服务器设置为 ready、drain 或 maint，无需重新加载配置，并保存到站点配置；HAProxy 未运行时只保存配置",
+                "consumes": [
+                    "application/json"
+                ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "统计信息"
+                    "站点管理"
                 ],
-                "summary": "获取HAProxy原始统计数据",
-                "responses": {
-                    "200": {
-                        "description": "获取统计数据成功",
-                        "schema": {
-                            "$ref": "#/definitions/model.SuccessResponse"
-                        }
+                "summary": "设置后端服务器状态",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "站点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
                     },
-                    "401": {
-                        "description": "未授权访问",
-                        "schema": {
-                            "$ref": "#/definitions/model.ErrResponseDontShowError"
-                        }
+                    {
+                        "type": "string",
+                        "description": "HAProxy 服务器名称，见站点详情的 serverStatus",
+                        "name": "server",
+                        "in": "path",
+                        "required": true
                     },
-                    "500": {
-                        "description": "服务器内部错误",
+                    {
+                        "description": "服务器状态",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
                         "schema": {
-                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                            "$ref": "#/definitions/dto.SetSiteServerStateRequest"
                         }
                     }
-                }
-            }
-        },
-        "/users": {
-            "get": {
-                "security": [
-                    {
-                        "BearerAuth": []
-                    }
-                ],
-                "description": "获取系统中所有用户的列表",
-                "produces": [
-                    "application/json"
-                ],
-                "tags": [
-                    "用户管理"
                 ],
-                "summary": "获取所有用户",
                 "responses": {
                     "200": {
-                        "description": "获取用户列表成功",
+                        "description": "设置服务器状态成功",
                         "schema": {
                             "allOf": [
                                 {
@@ -2824,16 +3304,19 @@ const docTemplate = `{
                                     "type": "object",
                                     "properties": {
                                         "data": {
-                                            "type": "array",
-                                            "items": {
-                                                "$ref": "#/definitions/model.User"
-                                            }
+                                            "$ref": "#/definitions/dto.SiteResponse"
                                         }
                                     }
                                 }
                             ]
                         }
                     },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
                     "401": {
                         "description": "未授权访问",
                         "schema": {
@@ -2846,6 +3329,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
+                    "404": {
+                        "description": "站点或服务器不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
@@ -2853,14 +3342,16 @@ const docTemplate = `{
                         }
                     }
                 }
-            },
-            "post": {
+            }
+        },
+        "/api/v1/site/{id}/servers/{server}/weight": {
+            "put": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "管理员创建新用户",
+                "description": "通过 HAProxy 运行时 API 修改服务器权重，无需重新加载配置，并保存到站点配置；HAProxy 未运行时只保存配置",
                 "consumes": [
                     "application/json"
                 ],
@@ -2868,25 +3359,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "站点管理"
                 ],
-                "summary": "创建新用户",
+                "summary": "设置后端服务器权重",
                 "parameters": [
                     {
-                        "description": "用户创建信息",
+                        "type": "string",
+                        "description": "站点ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "HAProxy 服务器名称，见站点详情的 serverStatus",
+                        "name": "server",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "服务器权重",
                         "name": "request",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/dto.UserCreateRequest"
+                            "$ref": "#/definitions/dto.SetSiteServerWeightRequest"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "用户创建成功",
+                        "description": "设置服务器权重成功",
                         "schema": {
-                            "$ref": "#/definitions/dto.ResetPasswordResponseData"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.SiteResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
@@ -2907,8 +3424,8 @@ const docTemplate = `{
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
-                    "409": {
-                        "description": "用户名已存在",
+                    "404": {
+                        "description": "站点或服务器不存在",
                         "schema": {
                             "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
@@ -2922,672 +3439,5197 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
-            "put": {
+        "/api/v1/stats/combined-time-series": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "管理员更新指定用户的信息",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "同时获取请求数和拦截数的时间序列数据，用于图表展示",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "统计信息"
                 ],
-                "summary": "更新用户信息",
+                "summary": "获取组合时间序列数据",
                 "parameters": [
                     {
+                        "enum": [
+                            "24h",
+                            "7d",
+                            "30d"
+                        ],
                         "type": "string",
-                        "description": "用户ID",
-                        "name": "id",
-                        "in": "path",
+                        "default": "24h",
+                        "description": "时间范围：24h(24小时)、7d(7天)、30d(30天)",
+                        "name": "timeRange",
+                        "in": "query",
                         "required": true
-                    },
-                    {
-                        "description": "用户更新信息",
-                        "name": "request",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/dto.UserUpdateRequest"
-                        }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "用户更新成功",
+                        "description": "获取组合时间序列数据成功",
                         "schema": {
-                            "$ref": "#/definitions/model.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.CombinedTimeSeriesResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
                     "400": {
                         "description": "请求参数错误",
                         "schema": {
-                            "$ref": "#/definitions/model.APIResponse"
+                            "$ref": "#/definitions/model.ErrResponse"
                         }
                     },
                     "401": {
                         "description": "未授权访问",
                         "schema": {
-                            "$ref": "#/definitions/model.APIResponse"
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
-                    "403": {
-                        "description": "禁止访问",
+                    "500": {
+                        "description": "服务器内部错误",
                         "schema": {
-                            "$ref": "#/definitions/model.APIResponse"
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stats/overview": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取指定时间范围内的统计概览数据，包括请求数、流量、错误率等",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "统计信息"
+                ],
+                "summary": "获取统计概览数据",
+                "parameters": [
+                    {
+                        "enum": [
+                            "24h",
+                            "7d",
+                            "30d"
+                        ],
+                        "type": "string",
+                        "default": "24h",
+                        "description": "时间范围：24h(24小时)、7d(7天)、30d(30天)",
+                        "name": "timeRange",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取统计概览成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.OverviewStats"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "用户不存在",
+                    "400": {
+                        "description": "请求参数错误",
                         "schema": {
-                            "$ref": "#/definitions/model.APIResponse"
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
-                            "$ref": "#/definitions/model.APIResponse"
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     }
                 }
-            },
-            "delete": {
+            }
+        },
+        "/api/v1/stats/realtime-qps": {
+            "get": {
                 "security": [
                     {
                         "BearerAuth": []
                     }
                 ],
-                "description": "管理员删除指定用户",
+                "description": "获取最近的实时QPS数据点",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "用户管理"
+                    "统计信息"
                 ],
-                "summary": "删除用户",
+                "summary": "获取实时QPS数据",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "用户ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "maximum": 60,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 30,
+                        "description": "返回的数据点数量，默认30个点，最大60个点",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "用户删除成功",
+                        "description": "获取实时QPS数据成功",
                         "schema": {
-                            "$ref": "#/definitions/model.APIResponse"
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.RealtimeQPSResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
                         }
                     },
                     "401": {
                         "description": "未授权访问",
                         "schema": {
-                            "$ref": "#/definitions/model.APIResponse"
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
-                    "403": {
-                        "description": "禁止访问",
+                    "500": {
+                        "description": "服务器内部错误",
                         "schema": {
-                            "$ref": "#/definitions/model.APIResponse"
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stats/rules": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取微规则和Coraza规则在指定时间范围内的命中次数、拦截次数、最后命中时间及时间序列，按命中次数降序",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "统计信息"
+                ],
+                "summary": "获取规则命中统计",
+                "parameters": [
+                    {
+                        "enum": [
+                            "24h",
+                            "7d",
+                            "30d"
+                        ],
+                        "type": "string",
+                        "default": "24h",
+                        "description": "时间范围：24h(24小时)、7d(7天)、30d(30天)",
+                        "name": "timeRange",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "micro",
+                            "coraza"
+                        ],
+                        "type": "string",
+                        "description": "规则来源：micro(微规则)、coraza(Coraza规则)，为空表示全部",
+                        "name": "source",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "规则ID，为空表示全部规则",
+                        "name": "ruleId",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 20,
+                        "description": "返回的规则数量，默认20，最大100",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取规则命中统计成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.RuleStatsResponse"
+                                        }
+                                    }
+                                }
+                            ]
                         }
                     },
-                    "404": {
-                        "description": "用户不存在",
+                    "400": {
+                        "description": "请求参数错误",
                         "schema": {
-                            "$ref": "#/definitions/model.APIResponse"
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     },
                     "500": {
                         "description": "服务器内部错误",
                         "schema": {
-                            "$ref": "#/definitions/model.APIResponse"
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
                         }
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "dto.AddIPToBlacklistRequest": {
-            "description": "添加IP地址或CIDR到系统默认黑名单的请求",
-            "type": "object",
-            "required": [
-                "ip"
-            ],
-            "properties": {
-                "ip": {
-                    "description": "IP地址或CIDR",
-                    "type": "string",
-                    "example": "192.168.1.1"
-                }
-            }
-        },
-        "dto.AppConfigDTO": {
-            "type": "object",
-            "properties": {
-                "directives": {
-                    "description": "指令配置",
-                    "type": "string"
-                },
-                "logFile": {
-                    "description": "日志文件",
-                    "type": "string"
-                },
-                "logFormat": {
-                    "description": "日志格式",
-                    "type": "string"
-                },
-                "logLevel": {
-                    "description": "日志级别",
-                    "type": "string"
-                },
-                "name": {
-                    "description": "应用名称",
-                    "type": "string"
-                },
-                "transactionTTL": {
-                    "description": "事务超时时间(毫秒)",
-                    "type": "integer",
-                    "example": 60000
-                }
-            }
         },
-        "dto.AppConfigPatchDTO": {
-            "type": "object",
-            "properties": {
-                "directives": {
-                    "description": "指令配置",
-                    "type": "string"
+        "/api/v1/stats/time-series": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取指定时间范围和指标类型的时间序列数据，用于图表展示",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "统计信息"
+                ],
+                "summary": "获取时间序列数据",
+                "parameters": [
+                    {
+                        "enum": [
+                            "24h",
+                            "7d",
+                            "30d"
+                        ],
+                        "type": "string",
+                        "default": "24h",
+                        "description": "时间范围：24h(24小时)、7d(7天)、30d(30天)",
+                        "name": "timeRange",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "enum": [
+                            "requests",
+                            "blocks"
+                        ],
+                        "type": "string",
+                        "default": "requests",
+                        "description": "指标类型：requests(请求数)、blocks(拦截数)",
+                        "name": "metric",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取时间序列数据成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.TimeSeriesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/stats/traffic-time-series": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取指定时间范围的入站和出站流量时间序列数据，用于图表展示",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "统计信息"
+                ],
+                "summary": "获取流量时间序列数据",
+                "parameters": [
+                    {
+                        "enum": [
+                            "24h",
+                            "7d",
+                            "30d"
+                        ],
+                        "type": "string",
+                        "default": "24h",
+                        "description": "时间范围：24h(24小时)、7d(7天)、30d(30天)",
+                        "name": "timeRange",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取流量时间序列数据成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.TrafficTimeSeriesResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/threat-feeds": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取威胁情报源列表及其同步状态，按名称排序",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "威胁情报源"
+                ],
+                "summary": "获取威胁情报源列表",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码，从1开始",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量，最大100",
+                        "name": "size",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取威胁情报源列表成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.ThreatFeedListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "订阅外部或本地的IP黑名单，按刷新间隔定时拉取并同步到新建的IP组，拉取失败时IP组保留上一次成功同步的数据",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "威胁情报源"
+                ],
+                "summary": "创建威胁情报源",
+                "parameters": [
+                    {
+                        "description": "威胁情报源信息",
+                        "name": "feed",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ThreatFeedCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "威胁情报源创建成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.ThreatFeed"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "409": {
+                        "description": "情报源名称或IP组名称已存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/threat-feeds/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根据ID获取威胁情报源详情，包括最近一次同步的变更、成功和失败信息",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "威胁情报源"
+                ],
+                "summary": "获取单个威胁情报源",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "威胁情报源ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取威胁情报源详情成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.ThreatFeed"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效的ID格式",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "404": {
+                        "description": "威胁情报源不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "更新威胁情报源配置，修改地址、格式或重新启用后会在下一次调度时完整拉取",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "威胁情报源"
+                ],
+                "summary": "更新威胁情报源",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "威胁情报源ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "威胁情报源更新信息",
+                        "name": "feed",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.ThreatFeedUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "威胁情报源更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.ThreatFeed"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "404": {
+                        "description": "威胁情报源不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "409": {
+                        "description": "威胁情报源名称已存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "删除威胁情报源订阅，同步的IP组及其条目会保留",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "威胁情报源"
+                ],
+                "summary": "删除威胁情报源",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "威胁情报源ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "威胁情报源删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessResponseNoData"
+                        }
+                    },
+                    "400": {
+                        "description": "无效的ID格式",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "404": {
+                        "description": "威胁情报源不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/threat-feeds/{id}/refresh": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "立即拉取威胁情报源并同步到IP组，不等待刷新间隔；同步失败时IP组保留上一次成功同步的数据",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "威胁情报源"
+                ],
+                "summary": "立即同步威胁情报源",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "威胁情报源ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "威胁情报源同步成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.ThreatFeed"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效的ID格式",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "404": {
+                        "description": "威胁情报源不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "502": {
+                        "description": "威胁情报源同步失败",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/trap-paths": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取蜜罐陷阱路径列表，按名称排序，可按站点过滤",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "蜜罐陷阱"
+                ],
+                "summary": "获取蜜罐陷阱路径列表",
+                "parameters": [
+                    {
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 1,
+                        "description": "页码，从1开始",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "maximum": 100,
+                        "minimum": 1,
+                        "type": "integer",
+                        "default": 10,
+                        "description": "每页数量，最大100",
+                        "name": "size",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "站点ID，只返回作用于该站点的陷阱路径",
+                        "name": "siteId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "default": true,
+                        "description": "按站点过滤时是否包含全局陷阱路径",
+                        "name": "includeGlobal",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取蜜罐陷阱路径列表成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.TrapPathListResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "创建只会被扫描器访问的陷阱路径，访问后立即按封禁时长封禁来源IP并记录安全日志；配置诱饵响应时返回伪装内容而不是 403",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "蜜罐陷阱"
+                ],
+                "summary": "创建蜜罐陷阱路径",
+                "parameters": [
+                    {
+                        "description": "蜜罐陷阱路径信息",
+                        "name": "trap",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TrapPathCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "蜜罐陷阱路径创建成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.TrapPath"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "409": {
+                        "description": "蜜罐陷阱路径名称已存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/trap-paths/{id}": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "根据ID获取蜜罐陷阱路径详情",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "蜜罐陷阱"
+                ],
+                "summary": "获取单个蜜罐陷阱路径",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "蜜罐陷阱路径ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "获取蜜罐陷阱路径详情成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.TrapPath"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "无效的ID格式",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "404": {
+                        "description": "蜜罐陷阱路径不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "更新蜜罐陷阱路径配置，未传入的字段保持不变；已封禁的IP不受影响",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "蜜罐陷阱"
+                ],
+                "summary": "更新蜜罐陷阱路径",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "蜜罐陷阱路径ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "蜜罐陷阱路径更新信息",
+                        "name": "trap",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.TrapPathUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "蜜罐陷阱路径更新成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/model.TrapPath"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "404": {
+                        "description": "蜜罐陷阱路径不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "409": {
+                        "description": "蜜罐陷阱路径名称已存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "删除蜜罐陷阱路径，已封禁的IP会在封禁到期后自动解封",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "蜜罐陷阱"
+                ],
+                "summary": "删除蜜罐陷阱路径",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "蜜罐陷阱路径ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "蜜罐陷阱路径删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessResponseNoData"
+                        }
+                    },
+                    "400": {
+                        "description": "无效的ID格式",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "404": {
+                        "description": "蜜罐陷阱路径不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/waf/logs": {
+            "get": {
+                "description": "查询详细的WAF攻击日志记录，提供多条件筛选和分页功能，支持按规则ID、IP、域名、端口和时间范围过滤",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "WAF安全日志"
+                ],
+                "summary": "获取详细攻击日志",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "规则ID，触发攻击检测的WAF规则标识",
+                        "name": "ruleId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "来源IP地址，攻击者地址",
+                        "name": "srcIp",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "目标IP地址，被攻击的服务器地址",
+                        "name": "dstIp",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "域名，被攻击的站点域名",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "来源端口号，发起攻击的端口",
+                        "name": "srcPort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "目标端口号，被攻击的服务端口",
+                        "name": "dstPort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "请求ID，唯一标识HTTP请求的ID",
+                        "name": "requestId",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "查询起始时间 (ISO8601格式，如: 2024-03-17T00:00:00Z)",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "查询结束时间 (ISO8601格式，如: 2024-03-18T23:59:59Z)",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "当前页码，从1开始计数 (默认: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页记录数，最大100条 (默认: 10)",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.AttackLogResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/api/v1/waf/logs/events": {
+            "get": {
+                "description": "按来源IP、目标端口和域名聚合的攻击事件统计，支持多维度筛选和分页",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "WAF安全日志"
+                ],
+                "summary": "获取聚合攻击事件",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "来源IP地址，攻击者地址",
+                        "name": "srcIp",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "目标IP地址，被攻击的服务器地址",
+                        "name": "dstIp",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "域名，被攻击的站点域名",
+                        "name": "domain",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "来源端口号，发起攻击的端口",
+                        "name": "srcPort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "目标端口号，被攻击的服务端口",
+                        "name": "dstPort",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "查询起始时间 (ISO8601格式，如: 2024-03-17T00:00:00Z)",
+                        "name": "startTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "查询结束时间 (ISO8601格式，如: 2024-03-18T23:59:59Z)",
+                        "name": "endTime",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "当前页码，从1开始计数 (默认: 1)",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "每页记录数，最大100条 (默认: 10)",
+                        "name": "pageSize",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.AttackEventResponse"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/login": {
+            "post": {
+                "description": "用户登录并获取JWT令牌",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证"
+                ],
+                "summary": "用户登录",
+                "parameters": [
+                    {
+                        "description": "登录信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserLoginRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "登录成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.LoginResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "用户名或密码错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/me": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取当前登录用户的详细信息",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证"
+                ],
+                "summary": "获取当前用户信息",
+                "responses": {
+                    "200": {
+                        "description": "获取用户信息成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "$ref": "#/definitions/dto.GetUserInfoResponseData"
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/auth/reset-password": {
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "用户重置自己的密码",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "认证"
+                ],
+                "summary": "重置密码",
+                "parameters": [
+                    {
+                        "description": "密码重置信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserPasswordResetRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "密码重置成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessResponseNoData"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误或原密码错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/stats": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取HAProxy原始的统计信息",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "统计信息"
+                ],
+                "summary": "获取HAProxy原始统计数据",
+                "responses": {
+                    "200": {
+                        "description": "获取统计数据成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.SuccessResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "获取系统中所有用户的列表",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "获取所有用户",
+                "responses": {
+                    "200": {
+                        "description": "获取用户列表成功",
+                        "schema": {
+                            "allOf": [
+                                {
+                                    "$ref": "#/definitions/model.SuccessResponse"
+                                },
+                                {
+                                    "type": "object",
+                                    "properties": {
+                                        "data": {
+                                            "type": "array",
+                                            "items": {
+                                                "$ref": "#/definitions/model.User"
+                                            }
+                                        }
+                                    }
+                                }
+                            ]
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "管理员创建新用户",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "创建新用户",
+                "parameters": [
+                    {
+                        "description": "用户创建信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserCreateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "用户创建成功",
+                        "schema": {
+                            "$ref": "#/definitions/dto.ResetPasswordResponseData"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "409": {
+                        "description": "用户名已存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.ErrResponseDontShowError"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "put": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "管理员更新指定用户的信息",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "更新用户信息",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "用户更新信息",
+                        "name": "request",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/dto.UserUpdateRequest"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "用户更新成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "400": {
+                        "description": "请求参数错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "security": [
+                    {
+                        "BearerAuth": []
+                    }
+                ],
+                "description": "管理员删除指定用户",
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "用户管理"
+                ],
+                "summary": "删除用户",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "用户ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "用户删除成功",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "401": {
+                        "description": "未授权访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "403": {
+                        "description": "禁止访问",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "404": {
+                        "description": "用户不存在",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    },
+                    "500": {
+                        "description": "服务器内部错误",
+                        "schema": {
+                            "$ref": "#/definitions/model.APIResponse"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "dto.ACMECertificateRequest": {
+            "description": "通过 ACME 自动签发证书的请求参数，签发的证书会在到期前自动续期",
+            "type": "object",
+            "required": [
+                "domains"
+            ],
+            "properties": {
+                "challengeType": {
+                    "description": "域名验证方式，为空时使用 http-01",
+                    "enum": [
+                        "http-01",
+                        "dns-01"
+                    ],
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ACMEChallengeType"
+                        }
+                    ],
+                    "example": "http-01"
+                },
+                "description": {
+                    "description": "证书描述",
+                    "type": "string",
+                    "example": "用于example.com的证书"
+                },
+                "domains": {
+                    "description": "证书包含的域名，通配符域名只能使用 DNS-01 验证",
+                    "type": "array",
+                    "maxItems": 100,
+                    "minItems": 1,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "example.com",
+                        "*.example.com"
+                    ]
+                },
+                "name": {
+                    "description": "证书名称/别名，为空时使用第一个域名",
+                    "type": "string",
+                    "example": "example-cert"
+                }
+            }
+        },
+        "dto.AddIPToBlacklistRequest": {
+            "description": "添加IP地址或CIDR到系统默认黑名单的请求",
+            "type": "object",
+            "required": [
+                "ip"
+            ],
+            "properties": {
+                "ip": {
+                    "description": "IP地址或CIDR",
+                    "type": "string",
+                    "example": "192.168.1.1"
+                }
+            }
+        },
+        "dto.AddSiteServerRequest": {
+            "description": "通过 HAProxy 运行时 API 向站点默认后端或路径路由的后端添加服务器，无需重新加载配置",
+            "type": "object",
+            "required": [
+                "server"
+            ],
+            "properties": {
+                "pathPrefix": {
+                    "description": "路径路由的路径前缀，为空表示站点默认后端",
+                    "type": "string",
+                    "example": "/api/"
+                },
+                "server": {
+                    "description": "服务器配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.ServerDTO"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.AppConfigDTO": {
+            "type": "object",
+            "properties": {
+                "directives": {
+                    "description": "指令配置",
+                    "type": "string"
+                },
+                "logFile": {
+                    "description": "日志文件",
+                    "type": "string"
+                },
+                "logFormat": {
+                    "description": "日志格式",
+                    "type": "string"
+                },
+                "logLevel": {
+                    "description": "日志级别",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "应用名称",
+                    "type": "string"
+                },
+                "transactionTTL": {
+                    "description": "事务超时时间(毫秒)",
+                    "type": "integer",
+                    "example": 60000
+                }
+            }
+        },
+        "dto.AppConfigPatchDTO": {
+            "type": "object",
+            "properties": {
+                "directives": {
+                    "description": "指令配置",
+                    "type": "string"
+                },
+                "logFile": {
+                    "description": "日志文件",
+                    "type": "string",
+                    "example": "/dev/stdout"
+                },
+                "logFormat": {
+                    "description": "日志格式",
+                    "type": "string",
+                    "example": "console"
+                },
+                "logLevel": {
+                    "description": "日志级别",
+                    "type": "string",
+                    "example": "info"
+                },
+                "name": {
+                    "description": "应用名称",
+                    "type": "string",
+                    "example": "coraza"
+                },
+                "transactionTTL": {
+                    "description": "事务超时时间(毫秒)",
+                    "type": "integer",
+                    "example": 60000
+                }
+            }
+        },
+        "dto.AttackEventAggregateResult": {
+            "description": "攻击事件的聚合统计结果，提供IP、域名、端口维度的攻击信息汇总，包含攻击次数、首次和最近攻击时间、持续时间等关键指标",
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "攻击总次数，同一来源的攻击计数",
+                    "type": "integer",
+                    "example": 15
+                },
+                "domain": {
+                    "description": "域名，被攻击的站点",
+                    "type": "string",
+                    "example": "example.com"
+                },
+                "dstPort": {
+                    "description": "目标端口号，被攻击的服务端口",
+                    "type": "integer",
+                    "example": 443
+                },
+                "durationInMinutes": {
+                    "description": "攻击持续时间(分钟)，从首次到最近攻击的时间跨度",
+                    "type": "number",
+                    "example": 18.2
+                },
+                "firstAttackTime": {
+                    "description": "首次攻击时间，该IP首次发起攻击的时间点",
+                    "type": "string",
+                    "example": "2024-03-18T08:12:33Z"
+                },
+                "isOngoing": {
+                    "description": "是否正在进行中，标识攻击是否仍在持续",
+                    "type": "boolean",
+                    "example": true
+                },
+                "lastAttackTime": {
+                    "description": "最近攻击时间，该IP最后一次攻击的时间点",
+                    "type": "string",
+                    "example": "2024-03-18T08:30:45Z"
+                },
+                "srcIp": {
+                    "description": "来源IP地址，攻击者地址",
+                    "type": "string",
+                    "example": "192.168.1.100"
+                },
+                "srcIpInfo": {
+                    "description": "来源IP地址，攻击者地址",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.IPInfo"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.AttackEventResponse": {
+            "description": "攻击事件查询的分页响应结构体，包含聚合结果列表及分页元数据，用于前端展示和翻页控制",
+            "type": "object",
+            "properties": {
+                "currentPage": {
+                    "description": "当前页码，从1开始计数",
+                    "type": "integer",
+                    "example": 1
+                },
+                "pageSize": {
+                    "description": "每页大小，当前设置的每页记录数",
+                    "type": "integer",
+                    "example": 10
+                },
+                "results": {
+                    "description": "聚合结果列表，当前页的攻击事件记录",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AttackEventAggregateResult"
+                    }
+                },
+                "totalCount": {
+                    "description": "总记录数，符合条件的攻击事件总数",
+                    "type": "integer",
+                    "example": 35
+                },
+                "totalPages": {
+                    "description": "总页数，根据总记录数和每页大小计算",
+                    "type": "integer",
+                    "example": 4
+                }
+            }
+        },
+        "dto.AttackLogResponse": {
+            "description": "攻击日志查询的分页响应结构体，返回详细的WAF日志记录及分页信息，便于安全分析和事件追踪",
+            "type": "object",
+            "properties": {
+                "currentPage": {
+                    "description": "当前页码，从1开始计数",
+                    "type": "integer",
+                    "example": 1
+                },
+                "pageSize": {
+                    "description": "每页大小，当前设置的每页记录数",
+                    "type": "integer",
+                    "example": 10
+                },
+                "results": {
+                    "description": "日志记录列表，当前页的WAF攻击日志详情",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.WAFLog"
+                    }
+                },
+                "totalCount": {
+                    "description": "总记录数，符合查询条件的日志总数",
+                    "type": "integer",
+                    "example": 128
+                },
+                "totalPages": {
+                    "description": "总页数，根据总记录数和每页大小计算",
+                    "type": "integer",
+                    "example": 13
+                }
+            }
+        },
+        "dto.AuditLogListResponse": {
+            "description": "审计日志分页列表响应",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "审计日志列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.AuditLog"
+                    }
+                },
+                "total": {
+                    "description": "总数量",
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "dto.BackendDTO": {
+            "type": "object",
+            "required": [
+                "servers"
+            ],
+            "properties": {
+                "balance": {
+                    "description": "负载均衡算法，默认 roundrobin",
+                    "type": "string",
+                    "enum": [
+                        "roundrobin",
+                        "leastconn",
+                        "source",
+                        "uri",
+                        "first",
+                        "random"
+                    ],
+                    "example": "roundrobin"
+                },
+                "healthCheck": {
+                    "description": "HTTP 健康检查，为空时不检查",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.HealthCheckDTO"
+                        }
+                    ]
+                },
+                "servers": {
+                    "description": "服务器列表，至少需要一个服务器",
+                    "type": "array",
+                    "minItems": 1,
+                    "items": {
+                        "$ref": "#/definitions/dto.ServerDTO"
+                    }
+                },
+                "stickyCookie": {
+                    "description": "会话保持 Cookie 名称，为空时不保持会话",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "SERVERID"
+                }
+            }
+        },
+        "dto.BlockedIPCleanupResponse": {
+            "description": "清理过期封禁IP记录的响应数据",
+            "type": "object",
+            "properties": {
+                "deletedCount": {
+                    "description": "删除的记录数量",
+                    "type": "integer",
+                    "example": 25
+                },
+                "message": {
+                    "description": "操作结果消息",
+                    "type": "string",
+                    "example": "已成功清理过期的封禁IP记录"
+                }
+            }
+        },
+        "dto.BlockedIPHourlyStats": {
+            "description": "按小时的封禁统计",
+            "type": "object",
+            "properties": {
+                "count": {
+                    "description": "该小时的封禁数量",
+                    "type": "integer",
+                    "example": 10
+                },
+                "hour": {
+                    "description": "小时时间点",
+                    "type": "string",
+                    "example": "2023-12-01T10:00:00Z"
+                }
+            }
+        },
+        "dto.BlockedIPListResponse": {
+            "description": "封禁IP分页列表响应",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "IP列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BlockedIPResponse"
+                    }
+                },
+                "page": {
+                    "description": "当前页码",
+                    "type": "integer",
+                    "example": 1
+                },
+                "pages": {
+                    "description": "总页数",
+                    "type": "integer",
+                    "example": 10
+                },
+                "size": {
+                    "description": "每页数量",
+                    "type": "integer",
+                    "example": 10
+                },
+                "total": {
+                    "description": "总数量",
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "dto.BlockedIPResponse": {
+            "description": "封禁IP详细信息",
+            "type": "object",
+            "properties": {
+                "blockedAt": {
+                    "description": "封禁开始时间",
+                    "type": "string",
+                    "example": "2023-12-01T10:00:00Z"
+                },
+                "blockedUntil": {
+                    "description": "封禁结束时间",
+                    "type": "string",
+                    "example": "2023-12-01T11:00:00Z"
+                },
+                "ip": {
+                    "description": "被封禁的IP地址",
+                    "type": "string",
+                    "example": "192.168.1.1"
+                },
+                "isActive": {
+                    "description": "是否仍在封禁中",
+                    "type": "boolean",
+                    "example": true
+                },
+                "reason": {
+                    "description": "封禁原因",
+                    "type": "string",
+                    "example": "high_frequency_attack"
+                },
+                "remainingTTL": {
+                    "description": "剩余封禁时间（秒）",
+                    "type": "integer",
+                    "example": 3600
+                },
+                "requestUri": {
+                    "description": "请求URI",
+                    "type": "string",
+                    "example": "/api/v1/login"
+                }
+            }
+        },
+        "dto.BlockedIPStatsResponse": {
+            "description": "封禁IP统计信息",
+            "type": "object",
+            "properties": {
+                "activeBlocked": {
+                    "description": "当前生效的封禁数量",
+                    "type": "integer",
+                    "example": 50
+                },
+                "expiredBlocked": {
+                    "description": "已过期的封禁数量",
+                    "type": "integer",
+                    "example": 950
+                },
+                "last24HourStats": {
+                    "description": "最近24小时统计",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.BlockedIPHourlyStats"
+                    }
+                },
+                "reasonStats": {
+                    "description": "按原因统计",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "totalBlocked": {
+                    "description": "总封禁数量",
+                    "type": "integer",
+                    "example": 1000
+                }
+            }
+        },
+        "dto.CertificateCreateRequest": {
+            "description": "创建证书的请求参数，过期日期、颁发机构、指纹和域名从证书中解析",
+            "type": "object",
+            "required": [
+                "privateKey",
+                "publicKey"
+            ],
+            "properties": {
+                "description": {
+                    "description": "证书描述",
+                    "type": "string",
+                    "example": "用于example.com的证书"
+                },
+                "name": {
+                    "description": "证书名称/别名",
+                    "type": "string",
+                    "example": "example-cert"
+                },
+                "passphrase": {
+                    "description": "加密私钥的密码，私钥解密后保存，密码不保存",
+                    "type": "string"
+                },
+                "privateKey": {
+                    "description": "私钥内容（PEM格式），支持加密的 PKCS#8 私钥",
+                    "type": "string"
+                },
+                "publicKey": {
+                    "description": "证书链内容（PEM格式），按站点证书、中间证书的顺序排列",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.CertificateExpiryItem": {
+            "description": "证书库中的证书或站点内嵌证书的过期状态",
+            "type": "object",
+            "properties": {
+                "daysLeft": {
+                    "description": "剩余天数，已过期时为负数",
+                    "type": "integer",
+                    "example": 6
+                },
+                "domains": {
+                    "description": "证书包含的域名",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "expireDate": {
+                    "description": "证书过期日期",
+                    "type": "string"
+                },
+                "fingerPrint": {
+                    "description": "证书指纹",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "证书ID，站点内嵌证书为站点ID",
+                    "type": "string"
+                },
+                "name": {
+                    "description": "证书名称，站点内嵌证书为站点名称",
+                    "type": "string",
+                    "example": "example-cert"
+                },
+                "sites": {
+                    "description": "使用该证书的站点名称",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "source": {
+                    "description": "证书来源：certificate 为证书库中的证书，site 为站点内嵌的证书",
+                    "type": "string",
+                    "example": "certificate"
+                },
+                "state": {
+                    "description": "过期状态",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.CertExpiryState"
+                        }
+                    ],
+                    "example": "expiring7"
+                }
+            }
+        },
+        "dto.CertificateExpirySummary": {
+            "description": "仪表盘使用的证书过期概览，包含各过期状态的证书数量和需要关注的证书",
+            "type": "object",
+            "properties": {
+                "counts": {
+                    "description": "各过期状态的证书数量",
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "integer"
+                    }
+                },
+                "items": {
+                    "description": "即将过期和已过期的证书，按过期日期升序排列",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.CertificateExpiryItem"
+                    }
+                },
+                "total": {
+                    "description": "证书总数",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CertificateListResponse": {
+            "description": "证书列表响应",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "证书列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.CertificateStore"
+                    }
+                },
+                "total": {
+                    "description": "总数",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.CertificateUpdateRequest": {
+            "description": "更新证书的请求参数，只更新证书链或私钥时与已保存的另一部分一起校验",
+            "type": "object",
+            "properties": {
+                "description": {
+                    "description": "证书描述",
+                    "type": "string",
+                    "example": "用于example.com的证书"
+                },
+                "name": {
+                    "description": "证书名称/别名",
+                    "type": "string",
+                    "example": "example-cert"
+                },
+                "passphrase": {
+                    "description": "加密私钥的密码，私钥解密后保存，密码不保存",
+                    "type": "string"
+                },
+                "privateKey": {
+                    "description": "私钥内容（PEM格式），支持加密的 PKCS#8 私钥",
+                    "type": "string"
+                },
+                "publicKey": {
+                    "description": "证书链内容（PEM格式），按站点证书、中间证书的顺序排列",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ClientAuthDTO": {
+            "description": "使用上传的 CA 证书校验客户端证书；required 要求所有请求携带有效证书，paths 只要求指定路径的请求携带有效证书",
+            "type": "object",
+            "required": [
+                "caCertificate",
+                "mode"
+            ],
+            "properties": {
+                "caCertificate": {
+                    "description": "签发客户端证书的 CA 证书（PEM格式），可以包含多个证书",
+                    "type": "string"
+                },
+                "mode": {
+                    "description": "认证方式：required-所有请求，paths-指定路径",
+                    "type": "string",
+                    "enum": [
+                        "required",
+                        "paths"
+                    ],
+                    "example": "paths"
+                },
+                "paths": {
+                    "description": "需要客户端证书的路径前缀，认证方式为 paths 时必填",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "/admin/"
+                    ]
+                }
+            }
+        },
+        "dto.CombinedTimeSeriesResponse": {
+            "description": "同时包含请求数和拦截数的时间序列数据",
+            "type": "object",
+            "properties": {
+                "blocks": {
+                    "description": "拦截数时间序列",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.TimeSeriesResponse"
+                        }
+                    ]
+                },
+                "requests": {
+                    "description": "请求数时间序列",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.TimeSeriesResponse"
+                        }
+                    ]
+                },
+                "timeRange": {
+                    "description": "时间范围",
+                    "type": "string",
+                    "example": "24h"
+                }
+            }
+        },
+        "dto.ConfigPatchRequest": {
+            "description": "用于部分更新配置的请求参数",
+            "type": "object",
+            "properties": {
+                "engine": {
+                    "description": "引擎配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.EnginePatchDTO"
+                        }
+                    ]
+                },
+                "haproxy": {
+                    "description": "HAProxy配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.HaproxyPatchDTO"
+                        }
+                    ]
+                },
+                "isDebug": {
+                    "description": "是否开启调试模式",
+                    "type": "boolean",
+                    "example": false
+                },
+                "isK8s": {
+                    "description": "是否在K8s环境",
+                    "type": "boolean",
+                    "example": false
+                },
+                "isResponseCheck": {
+                    "description": "是否检查响应",
+                    "type": "boolean",
+                    "example": false
+                },
+                "name": {
+                    "description": "配置名称",
+                    "type": "string",
+                    "example": "AppConfig"
+                }
+            }
+        },
+        "dto.ConfigResponse": {
+            "description": "配置响应",
+            "type": "object",
+            "properties": {
+                "createdAt": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "engine": {
+                    "description": "引擎配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.EngineDTO"
+                        }
+                    ]
+                },
+                "haproxy": {
+                    "description": "HAProxy配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.HaproxyDTO"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "配置ID",
+                    "type": "string"
+                },
+                "isDebug": {
+                    "description": "是否开启调试模式",
+                    "type": "boolean"
+                },
+                "isK8s": {
+                    "description": "是否在K8s环境",
+                    "type": "boolean"
+                },
+                "isResponseCheck": {
+                    "description": "是否检查响应",
+                    "type": "boolean"
+                },
+                "name": {
+                    "description": "配置名称",
+                    "type": "string"
+                },
+                "updatedAt": {
+                    "description": "更新时间",
+                    "type": "string"
+                }
+            }
+        },
+        "dto.ConfigVersionDiffResponse": {
+            "type": "object",
+            "properties": {
+                "diff": {
+                    "description": "haproxy.cfg 的统一格式差异，两个版本相同时为空",
+                    "type": "string"
+                },
+                "from": {
+                    "description": "源版本ID",
+                    "type": "string",
+                    "example": "20250101-120000.000000"
+                },
+                "to": {
+                    "description": "目标版本ID",
+                    "type": "string",
+                    "example": "20250102-120000.000000"
+                }
+            }
+        },
+        "dto.ConfigVersionResponse": {
+            "description": "通过 haproxy -c 检查并成功加载过的配置版本",
+            "type": "object",
+            "properties": {
+                "active": {
+                    "description": "是否为当前生效的版本",
+                    "type": "boolean",
+                    "example": true
+                },
+                "checksum": {
+                    "description": "haproxy.cfg 的 SHA-256",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "description": "生成时间",
+                    "type": "string"
+                },
+                "id": {
+                    "description": "版本ID",
+                    "type": "string",
+                    "example": "20250101-120000.000000"
+                },
+                "reason": {
+                    "description": "生成原因",
+                    "type": "string",
+                    "example": "热重载"
+                }
+            }
+        },
+        "dto.CreateSiteRequest": {
+            "description": "创建站点的请求参数",
+            "type": "object",
+            "required": [
+                "backend",
+                "domain",
+                "listenPort",
+                "name"
+            ],
+            "properties": {
+                "activeStatus": {
+                    "description": "站点状态",
+                    "type": "boolean",
+                    "example": true
+                },
+                "aliases": {
+                    "description": "其他域名，支持 *.example.com 形式的通配符域名，不匹配 example.com 本身",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "www.example.com",
+                        "*.example.com"
+                    ]
+                },
+                "backend": {
+                    "description": "后端服务器配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.BackendDTO"
+                        }
+                    ]
+                },
+                "certificateId": {
+                    "description": "证书库中的证书ID，为空时按域名自动选择包含站点所有域名的证书",
+                    "type": "string",
+                    "example": "60d21b4367d0d8992e89e964"
+                },
+                "domain": {
+                    "description": "域名",
+                    "type": "string",
+                    "example": "example.com"
+                },
+                "enableHTTPS": {
+                    "description": "是否启用HTTPS",
+                    "type": "boolean",
+                    "example": false
+                },
+                "errorPages": {
+                    "description": "自定义错误页",
+                    "type": "array",
+                    "maxItems": 4,
+                    "items": {
+                        "$ref": "#/definitions/dto.ErrorPageDTO"
+                    }
+                },
+                "httpsRedirect": {
+                    "description": "HTTP 请求重定向到 HTTPS 的方式：none-不重定向，301、308-永久重定向；只在启用 HTTPS 时生效，默认 301",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "301",
+                        "308"
+                    ],
+                    "example": "308"
+                },
+                "limits": {
+                    "description": "超时、请求体大小和连接保护，为空时使用 HAProxy 默认配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.SiteLimitsDTO"
+                        }
+                    ]
+                },
+                "listenPort": {
+                    "description": "监听端口",
+                    "type": "integer",
+                    "maximum": 65535,
+                    "minimum": 1,
+                    "example": 8080
+                },
+                "loginProtection": {
+                    "description": "登录保护配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.LoginProtectionDTO"
+                        }
+                    ]
+                },
+                "maintenance": {
+                    "description": "维护模式，为空时不启用",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.MaintenanceDTO"
+                        }
+                    ]
+                },
+                "name": {
+                    "description": "站点名称",
+                    "type": "string",
+                    "example": "my-site"
+                },
+                "requestHeaders": {
+                    "description": "请求头规则，按顺序执行",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/dto.HeaderRuleDTO"
+                    }
+                },
+                "responseHeaders": {
+                    "description": "响应头规则，按顺序执行，在安全响应头预设之后执行",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/dto.HeaderRuleDTO"
+                    }
+                },
+                "routes": {
+                    "description": "路径路由，按顺序匹配，未命中时使用默认后端",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/dto.RouteDTO"
+                    }
+                },
+                "securityHeaders": {
+                    "description": "安全响应头预设：none-不添加，basic-基础，strict-严格",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "basic",
+                        "strict"
+                    ],
+                    "example": "basic"
+                },
+                "tlsPolicy": {
+                    "description": "HTTPS 的 TLS 策略，为空时使用 HAProxy 默认配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.TLSPolicyDTO"
+                        }
+                    ]
+                },
+                "wafEnabled": {
+                    "description": "是否启用WAF",
+                    "type": "boolean",
+                    "example": false
+                },
+                "wafMode": {
+                    "description": "WAF模式",
+                    "type": "string",
+                    "enum": [
+                        "protection",
+                        "observation"
+                    ],
+                    "example": "observation"
+                }
+            }
+        },
+        "dto.EngineDTO": {
+            "type": "object",
+            "properties": {
+                "appConfig": {
+                    "description": "应用配置列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AppConfigDTO"
+                    }
+                },
+                "asnDBPath": {
+                    "description": "ASN数据库路径",
+                    "type": "string"
+                },
+                "bind": {
+                    "description": "引擎绑定地址",
+                    "type": "string"
+                },
+                "cityDBPath": {
+                    "description": "城市数据库路径",
+                    "type": "string"
+                },
+                "flowController": {
+                    "description": "流量控制配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.FlowControllerDTO"
+                        }
+                    ]
+                },
+                "useBuiltinRules": {
+                    "description": "是否使用内置规则",
+                    "type": "boolean"
+                }
+            }
+        },
+        "dto.EnginePatchDTO": {
+            "type": "object",
+            "properties": {
+                "appConfig": {
+                    "description": "应用配置列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.AppConfigPatchDTO"
+                    }
+                },
+                "asnDBPath": {
+                    "description": "ASN数据库路径",
+                    "type": "string",
+                    "example": "/opt/geoip/GeoLite2-ASN.mmdb"
+                },
+                "bind": {
+                    "description": "引擎绑定地址",
+                    "type": "string",
+                    "example": "127.0.0.1:2342"
+                },
+                "cityDBPath": {
+                    "description": "城市数据库路径",
+                    "type": "string",
+                    "example": "/opt/geoip/GeoLite2-City.mmdb"
+                },
+                "flowController": {
+                    "description": "流量控制配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.FlowControllerPatchDTO"
+                        }
+                    ]
+                },
+                "useBuiltinRules": {
+                    "description": "是否使用内置规则",
+                    "type": "boolean",
+                    "example": true
+                }
+            }
+        },
+        "dto.ErrorPageDTO": {
+            "description": "HAProxy 因后端错误、超时或没有可用服务器而返回对应状态码时使用的 HTML 页面",
+            "type": "object",
+            "required": [
+                "content",
+                "status"
+            ],
+            "properties": {
+                "content": {
+                    "description": "错误页 HTML，最大 12KB",
+                    "type": "string",
+                    "maxLength": 12288,
+                    "example": "\u003chtml\u003e\u003cbody\u003e服务暂时不可用\u003c/body\u003e\u003c/html\u003e"
+                },
+                "status": {
+                    "description": "状态码",
+                    "type": "integer",
+                    "enum": [
+                        500,
+                        502,
+                        503,
+                        504
+                    ],
+                    "example": 503
+                }
+            }
+        },
+        "dto.FlowControllerDTO": {
+            "type": "object",
+            "properties": {
+                "attackLimit": {
+                    "description": "攻击频率限制配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.LimitConfigDTO"
+                        }
+                    ]
+                },
+                "errorLimit": {
+                    "description": "错误频率限制配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.LimitConfigDTO"
+                        }
+                    ]
+                },
+                "visitLimit": {
+                    "description": "访问频率限制配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.LimitConfigDTO"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.FlowControllerPatchDTO": {
+            "type": "object",
+            "properties": {
+                "attackLimit": {
+                    "description": "攻击频率限制配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.LimitConfigPatchDTO"
+                        }
+                    ]
+                },
+                "errorLimit": {
+                    "description": "错误频率限制配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.LimitConfigPatchDTO"
+                        }
+                    ]
+                },
+                "visitLimit": {
+                    "description": "访问频率限制配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.LimitConfigPatchDTO"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.GetUserInfoResponseData": {
+            "type": "object",
+            "properties": {
+                "id": {
+                    "type": "string",
+                    "example": "1234567890"
+                },
+                "needReset": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "role": {
+                    "type": "string",
+                    "example": "admin"
+                },
+                "username": {
+                    "type": "string",
+                    "example": "user123"
+                }
+            }
+        },
+        "dto.HSTSDTO": {
+            "description": "HTTPS 响应添加 Strict-Transport-Security 头，申请预加载时有效期至少一年且需要包含子域名",
+            "type": "object",
+            "required": [
+                "maxAge"
+            ],
+            "properties": {
+                "includeSubDomains": {
+                    "description": "是否包含子域名",
+                    "type": "boolean",
+                    "example": true
+                },
+                "maxAge": {
+                    "description": "有效期，单位秒",
+                    "type": "integer",
+                    "maximum": 63072000,
+                    "minimum": 1,
+                    "example": 31536000
+                },
+                "preload": {
+                    "description": "是否申请加入浏览器预加载列表",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.HaproxyDTO": {
+            "type": "object",
+            "properties": {
+                "backupsNumber": {
+                    "description": "备份数量",
+                    "type": "integer"
+                },
+                "configBaseDir": {
+                    "description": "配置文件根目录",
+                    "type": "string"
+                },
+                "haproxyBin": {
+                    "description": "HAProxy二进制文件路径",
+                    "type": "string"
+                },
+                "spoeAgentAddr": {
+                    "description": "SPOE代理地址",
+                    "type": "string"
+                },
+                "spoeAgentPort": {
+                    "description": "SPOE代理端口",
+                    "type": "integer"
+                },
+                "thread": {
+                    "description": "线程数",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.HaproxyPatchDTO": {
+            "type": "object",
+            "properties": {
+                "backupsNumber": {
+                    "description": "备份数量",
+                    "type": "integer",
+                    "example": 5
+                },
+                "configBaseDir": {
+                    "description": "配置文件根目录",
+                    "type": "string",
+                    "example": "/RuiQi"
+                },
+                "haproxyBin": {
+                    "description": "HAProxy二进制文件路径",
+                    "type": "string",
+                    "example": "haproxy"
+                },
+                "spoeAgentAddr": {
+                    "description": "SPOE代理地址",
+                    "type": "string",
+                    "example": "127.0.0.1"
+                },
+                "spoeAgentPort": {
+                    "description": "SPOE代理端口",
+                    "type": "integer",
+                    "example": 2342
+                },
+                "thread": {
+                    "description": "线程数",
+                    "type": "integer",
+                    "maximum": 256,
+                    "minimum": 0,
+                    "example": 4
+                }
+            }
+        },
+        "dto.HeaderRuleDTO": {
+            "description": "set-设置头部，add-添加头部，delete-删除头部，replace-按正则表达式替换头部的值；可以只对指定路径前缀的请求生效",
+            "type": "object",
+            "required": [
+                "action",
+                "name"
+            ],
+            "properties": {
+                "action": {
+                    "description": "操作",
+                    "type": "string",
+                    "enum": [
+                        "set",
+                        "add",
+                        "delete",
+                        "replace"
+                    ],
+                    "example": "set"
+                },
+                "name": {
+                    "description": "头部名称",
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "X-Request-ID"
+                },
+                "pathPrefix": {
+                    "description": "只对路径以该前缀开头的请求生效，为空时对所有请求生效",
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "/api/"
+                },
+                "pattern": {
+                    "description": "replace 时匹配头部值的正则表达式",
+                    "type": "string",
+                    "maxLength": 1024,
+                    "example": "^(.*)$"
+                },
+                "value": {
+                    "description": "set、add 时为头部的值，replace 时为替换内容，支持 HAProxy 日志格式",
+                    "type": "string",
+                    "maxLength": 4096,
+                    "example": "%[uuid()]"
+                }
+            }
+        },
+        "dto.HealthCheckDTO": {
+            "description": "定期向后端服务器发送 HTTP 请求，连续失败达到次数后将服务器标记为 DOWN 并停止转发",
+            "type": "object",
+            "required": [
+                "path"
+            ],
+            "properties": {
+                "expectStatus": {
+                    "description": "期望的响应状态码，支持 200 或 200-399 形式，默认 200-399",
+                    "type": "string",
+                    "maxLength": 7,
+                    "example": "200-399"
+                },
+                "fall": {
+                    "description": "连续失败多少次后标记为 DOWN，默认 3",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 3
+                },
+                "interval": {
+                    "description": "检查间隔，单位毫秒，默认 2000",
+                    "type": "integer",
+                    "maximum": 3600000,
+                    "minimum": 500,
+                    "example": 2000
+                },
+                "path": {
+                    "description": "检查路径",
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "/healthz"
+                },
+                "rise": {
+                    "description": "连续成功多少次后标记为 UP，默认 2",
+                    "type": "integer",
+                    "maximum": 100,
+                    "minimum": 1,
+                    "example": 2
+                }
+            }
+        },
+        "dto.IPGroupCreateRequest": {
+            "description": "创建IP组的请求参数",
+            "type": "object",
+            "required": [
+                "items",
+                "name"
+            ],
+            "properties": {
+                "edgeBlock": {
+                    "description": "是否作为黑名单在 HAProxy 前端直接拒绝组中IP的请求，只能用于对所有站点生效的IP组",
+                    "type": "boolean",
+                    "example": false
+                },
+                "expirations": {
+                    "description": "条目过期时间，未列出的条目永久有效",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.IPItemExpirationRequest"
+                    }
+                },
+                "items": {
+                    "description": "IP地址或CIDR列表",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "[\"192.168.1.1\"]"
+                    ]
+                },
+                "name": {
+                    "description": "IP组名称",
+                    "type": "string",
+                    "example": "内部服务器"
+                },
+                "scope": {
+                    "description": "站点作用域，为空表示对所有站点生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.SiteScopeRequest"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.IPGroupImportInvalidLine": {
+            "description": "导入文件中无法解析为IP地址或CIDR的行",
+            "type": "object",
+            "properties": {
+                "content": {
+                    "description": "行内容",
+                    "type": "string",
+                    "example": "10.0.0.300"
+                },
+                "line": {
+                    "description": "行号，JSON 文件为条目序号",
+                    "type": "integer",
+                    "example": 12
+                },
+                "reason": {
+                    "description": "跳过原因",
+                    "type": "string",
+                    "example": "无效的IP地址或CIDR"
+                }
+            }
+        },
+        "dto.IPGroupImportReport": {
+            "description": "导入结果和条目变更，条目变更列表最多返回 1000 条",
+            "type": "object",
+            "properties": {
+                "added": {
+                    "description": "新增条目",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "10.0.0.0/23"
+                    ]
+                },
+                "addedCount": {
+                    "description": "新增条目数",
+                    "type": "integer",
+                    "example": 3400
+                },
+                "dryRun": {
+                    "description": "是否仅预览",
+                    "type": "boolean",
+                    "example": false
+                },
+                "duplicateEntries": {
+                    "description": "重复条目数",
+                    "type": "integer",
+                    "example": 150
+                },
+                "format": {
+                    "description": "实际使用的文件格式",
+                    "type": "string",
+                    "example": "txt"
+                },
+                "groupId": {
+                    "description": "IP组ID，预览新IP组时为空",
+                    "type": "string",
+                    "example": "60d21b4367d0d8992e89e964"
+                },
+                "groupName": {
+                    "description": "IP组名称",
+                    "type": "string",
+                    "example": "内部服务器"
+                },
+                "invalidLines": {
+                    "description": "无效行数",
+                    "type": "integer",
+                    "example": 50
+                },
+                "invalidSamples": {
+                    "description": "无效行示例，最多 100 条",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.IPGroupImportInvalidLine"
+                    }
+                },
+                "itemsAfter": {
+                    "description": "导入并合并CIDR后的条目数",
+                    "type": "integer",
+                    "example": 3500
+                },
+                "itemsBefore": {
+                    "description": "导入前条目数",
+                    "type": "integer",
+                    "example": 200
+                },
+                "mode": {
+                    "description": "导入模式",
+                    "type": "string",
+                    "example": "merge"
+                },
+                "removed": {
+                    "description": "移除条目",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "10.0.0.0/24"
+                    ]
+                },
+                "removedCount": {
+                    "description": "移除条目数（包括被合并进更大CIDR的条目）",
+                    "type": "integer",
+                    "example": 100
+                },
+                "totalLines": {
+                    "description": "文件总行数（JSON 为条目数）",
+                    "type": "integer",
+                    "example": 10000
+                },
+                "truncated": {
+                    "description": "变更列表是否被截断",
+                    "type": "boolean",
+                    "example": false
+                },
+                "validEntries": {
+                    "description": "有效条目数（去重前）",
+                    "type": "integer",
+                    "example": 9800
+                }
+            }
+        },
+        "dto.IPGroupListResponse": {
+            "description": "IP组列表响应",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "IP组列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.IPGroup"
+                    }
+                },
+                "total": {
+                    "description": "总数",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.IPGroupReference": {
+            "description": "条件中通过 in_ipgroup 或 not_in_ipgroup 引用IP组的微规则",
+            "type": "object",
+            "properties": {
+                "ruleId": {
+                    "description": "规则ID",
+                    "type": "string",
+                    "example": "60d21b4367d0d8992e89e964"
+                },
+                "ruleName": {
+                    "description": "规则名称",
+                    "type": "string",
+                    "example": "阻止恶意IP"
+                },
+                "ruleStatus": {
+                    "description": "规则状态",
+                    "type": "string",
+                    "example": "enabled"
+                },
+                "ruleType": {
+                    "description": "规则类型",
+                    "type": "string",
+                    "example": "blacklist"
+                }
+            }
+        },
+        "dto.IPGroupReferencesResponse": {
+            "description": "IP组及引用它的微规则列表",
+            "type": "object",
+            "properties": {
+                "exists": {
+                    "description": "IP组是否存在，不存在表示规则引用了已失效的IP组",
+                    "type": "boolean",
+                    "example": true
+                },
+                "groupId": {
+                    "description": "IP组ID，IP组不存在时为空",
+                    "type": "string",
+                    "example": "60d21b4367d0d8992e89e964"
+                },
+                "groupName": {
+                    "description": "IP组名称",
+                    "type": "string",
+                    "example": "blocked_ips"
+                },
+                "rules": {
+                    "description": "引用规则列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.IPGroupReference"
+                    }
+                },
+                "total": {
+                    "description": "引用规则数量",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "dto.IPGroupUpdateRequest": {
+            "description": "更新IP组的请求参数",
+            "type": "object",
+            "properties": {
+                "edgeBlock": {
+                    "description": "是否作为黑名单在 HAProxy 前端直接拒绝组中IP的请求，只能用于对所有站点生效的IP组",
+                    "type": "boolean",
+                    "example": false
+                },
+                "expirations": {
+                    "description": "条目过期时间，传入时整体替换；只更新条目时保留仍在组中的条目的过期时间",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.IPItemExpirationRequest"
+                    }
+                },
+                "items": {
+                    "description": "IP地址或CIDR列表",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "[\"192.168.1.1\"]"
+                    ]
+                },
+                "name": {
+                    "description": "IP组名称",
+                    "type": "string",
+                    "example": "内部服务器"
+                },
+                "scope": {
+                    "description": "站点作用域，传空对象表示改为对所有站点生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.SiteScopeRequest"
+                        }
+                    ]
+                },
+                "updateReferences": {
+                    "description": "重命名被规则引用的IP组时，是否同时更新引用它的规则；为 false 时拒绝重命名",
+                    "type": "boolean",
+                    "example": false
+                }
+            }
+        },
+        "dto.IPItemExpirationRequest": {
+            "description": "为IP组中的条目设置过期时间，过期后不再参与匹配并被自动移除",
+            "type": "object",
+            "required": [
+                "expiresAt",
+                "item"
+            ],
+            "properties": {
+                "expiresAt": {
+                    "description": "过期时间",
+                    "type": "string",
+                    "example": "2024-03-18T00:00:00Z"
+                },
+                "item": {
+                    "description": "IP地址或CIDR，必须是组中的条目",
+                    "type": "string",
+                    "example": "192.168.1.1"
+                }
+            }
+        },
+        "dto.LimitConfigDTO": {
+            "type": "object",
+            "properties": {
+                "blockDuration": {
+                    "description": "封禁时长（秒）",
+                    "type": "integer"
+                },
+                "burstCount": {
+                    "description": "允许的突发请求数",
+                    "type": "integer"
+                },
+                "enabled": {
+                    "description": "是否启用",
+                    "type": "boolean"
+                },
+                "paramsCapacity": {
+                    "description": "缓存容量",
+                    "type": "integer"
+                },
+                "statDuration": {
+                    "description": "统计时间窗口（秒）",
+                    "type": "integer"
+                },
+                "threshold": {
+                    "description": "阈值",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.LimitConfigPatchDTO": {
+            "type": "object",
+            "properties": {
+                "blockDuration": {
+                    "description": "封禁时长（秒）",
+                    "type": "integer",
+                    "example": 600
+                },
+                "burstCount": {
+                    "description": "允许的突发请求数",
+                    "type": "integer",
+                    "example": 10
+                },
+                "enabled": {
+                    "description": "是否启用",
+                    "type": "boolean",
+                    "example": true
+                },
+                "paramsCapacity": {
+                    "description": "缓存容量",
+                    "type": "integer",
+                    "example": 10000
+                },
+                "statDuration": {
+                    "description": "统计时间窗口（秒）",
+                    "type": "integer",
+                    "example": 60
+                },
+                "threshold": {
+                    "description": "阈值",
+                    "type": "integer",
+                    "example": 100
+                }
+            }
+        },
+        "dto.LoginProtectionDTO": {
+            "description": "按用户名、来源IP和来源ASN统计登录失败次数，任一维度在滑动窗口内达到阈值后对后续登录请求执行处置动作；需要开启响应检测",
+            "type": "object",
+            "required": [
+                "action",
+                "path",
+                "usernameField"
+            ],
+            "properties": {
+                "action": {
+                    "description": "处置动作：challenge-JS挑战，throttle-返回429，block-封禁来源IP",
+                    "type": "string",
+                    "enum": [
+                        "challenge",
+                        "throttle",
+                        "block"
+                    ],
+                    "example": "challenge"
+                },
+                "asnThreshold": {
+                    "description": "单个来源ASN的失败次数阈值，0 表示不统计，需要配置 ASN 数据库",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 200
+                },
+                "blockDuration": {
+                    "description": "block 动作的封禁时长，单位秒，默认 3600",
+                    "type": "integer",
+                    "maximum": 31536000,
+                    "minimum": 60,
+                    "example": 3600
+                },
+                "enabled": {
+                    "description": "是否启用",
+                    "type": "boolean",
+                    "example": true
+                },
+                "failurePattern": {
+                    "description": "表示登录失败的响应内容正则表达式，与状态码至少配置一项",
+                    "type": "string",
+                    "maxLength": 1024,
+                    "example": "(?i)密码错误"
+                },
+                "failureStatus": {
+                    "description": "表示登录失败的响应状态码",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        401,
+                        403
+                    ]
+                },
+                "ipThreshold": {
+                    "description": "单个来源IP的失败次数阈值，0 表示不统计",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 20
+                },
+                "method": {
+                    "description": "登录接口请求方法，默认 POST",
+                    "type": "string",
+                    "enum": [
+                        "GET",
+                        "POST",
+                        "PUT",
+                        "PATCH"
+                    ],
+                    "example": "POST"
+                },
+                "path": {
+                    "description": "登录接口路径，完全匹配",
+                    "type": "string",
+                    "example": "/api/login"
+                },
+                "usernameField": {
+                    "description": "用户名字段，表单字段名或 JSON 键，JSON 支持 user.name 形式的嵌套路径",
+                    "type": "string",
+                    "maxLength": 128,
+                    "example": "username"
+                },
+                "usernameThreshold": {
+                    "description": "单个用户名的失败次数阈值，0 表示不统计",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 5
+                },
+                "window": {
+                    "description": "滑动统计窗口，单位秒，默认 600",
+                    "type": "integer",
+                    "maximum": 86400,
+                    "minimum": 10,
+                    "example": 600
+                }
+            }
+        },
+        "dto.LoginResponseData": {
+            "type": "object",
+            "properties": {
+                "token": {
+                    "description": "JWT token",
+                    "type": "string",
+                    "example": "eyJhbGciOiJIUzI1NiIs..."
+                },
+                "user": {
+                    "description": "用户信息",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.User"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.MaintenanceDTO": {
+            "description": "启用后除白名单外的请求都返回 503 维护页，维护页为空时使用 503 错误页",
+            "type": "object",
+            "properties": {
+                "allowedIPs": {
+                    "description": "不受维护模式影响的客户端 IP 或 CIDR",
+                    "type": "array",
+                    "maxItems": 100,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "10.0.0.0/8",
+                        "192.168.1.10"
+                    ]
+                },
+                "enabled": {
+                    "description": "是否启用",
+                    "type": "boolean",
+                    "example": true
+                },
+                "page": {
+                    "description": "维护页 HTML，最大 12KB",
+                    "type": "string",
+                    "maxLength": 12288,
+                    "example": "\u003chtml\u003e\u003cbody\u003e系统维护中\u003c/body\u003e\u003c/html\u003e"
+                }
+            }
+        },
+        "dto.MicroRuleCreateRequest": {
+            "description": "创建微规则的请求参数",
+            "type": "object",
+            "required": [
+                "condition",
+                "name",
+                "priority",
+                "status",
+                "type"
+            ],
+            "properties": {
+                "condition": {
+                    "description": "规则条件",
+                    "type": "object"
+                },
+                "name": {
+                    "description": "规则名称",
+                    "type": "string",
+                    "example": "SQL注入防护规则"
+                },
+                "priority": {
+                    "description": "优先级字段，数字越大优先级越高",
+                    "type": "integer",
+                    "example": 100
+                },
+                "schedule": {
+                    "description": "生效计划，为空表示始终生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.RuleScheduleRequest"
+                        }
+                    ]
+                },
+                "scope": {
+                    "description": "站点作用域，为空表示对所有站点生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.SiteScopeRequest"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "规则状态",
+                    "type": "string",
+                    "enum": [
+                        "enabled",
+                        "disabled"
+                    ],
+                    "example": "enabled"
+                },
+                "type": {
+                    "description": "规则类型",
+                    "type": "string",
+                    "enum": [
+                        "whitelist",
+                        "blacklist"
+                    ],
+                    "example": "blacklist"
+                }
+            }
+        },
+        "dto.MicroRuleListResponse": {
+            "description": "微规则列表响应",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "微规则列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.MicroRuleResponse"
+                    }
+                },
+                "total": {
+                    "description": "总数",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.MicroRuleResponse": {
+            "description": "微规则响应参数",
+            "type": "object",
+            "properties": {
+                "condition": {
+                    "description": "规则条件",
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "60a763d0f03239868b50e810"
+                },
+                "name": {
+                    "description": "规则名称",
+                    "type": "string",
+                    "example": "SQL注入防护规则"
+                },
+                "offload": {
+                    "description": "是否卸载到 HAProxy 判断，仅列表接口返回",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.RuleOffloadStatus"
+                        }
+                    ]
+                },
+                "priority": {
+                    "description": "优先级字段，数字越大优先级越高",
+                    "type": "integer",
+                    "example": 100
+                },
+                "schedule": {
+                    "description": "生效计划，为空表示始终生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.RuleSchedule"
+                        }
+                    ]
+                },
+                "scope": {
+                    "description": "站点作用域，为空表示对所有站点生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SiteScope"
+                        }
+                    ]
+                },
+                "stats": {
+                    "description": "命中统计，仅列表接口返回",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.RuleHitStats"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "规则状态",
+                    "type": "string",
+                    "enum": [
+                        "enabled",
+                        "disabled"
+                    ],
+                    "example": "enabled"
+                },
+                "type": {
+                    "description": "规则类型",
+                    "type": "string",
+                    "enum": [
+                        "whitelist",
+                        "blacklist"
+                    ],
+                    "example": "blacklist"
+                }
+            }
+        },
+        "dto.MicroRuleUpdateRequest": {
+            "description": "更新微规则的请求参数",
+            "type": "object",
+            "properties": {
+                "condition": {
+                    "description": "规则条件",
+                    "type": "object"
+                },
+                "name": {
+                    "description": "规则名称",
+                    "type": "string",
+                    "example": "SQL注入防护规则"
+                },
+                "priority": {
+                    "description": "优先级字段，数字越大优先级越高",
+                    "type": "integer",
+                    "example": 100
+                },
+                "schedule": {
+                    "description": "生效计划，传入时整体替换，传空对象表示改为始终生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.RuleScheduleRequest"
+                        }
+                    ]
+                },
+                "scope": {
+                    "description": "站点作用域，传空对象表示改为对所有站点生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.SiteScopeRequest"
+                        }
+                    ]
+                },
+                "status": {
+                    "description": "规则状态",
+                    "type": "string",
+                    "enum": [
+                        "enabled",
+                        "disabled"
+                    ],
+                    "example": "enabled"
+                },
+                "type": {
+                    "description": "规则类型",
+                    "type": "string",
+                    "enum": [
+                        "whitelist",
+                        "blacklist"
+                    ],
+                    "example": "blacklist"
+                }
+            }
+        },
+        "dto.OverviewStats": {
+            "description": "概览统计数据，包含各项关键指标",
+            "type": "object",
+            "properties": {
+                "attackIPCount": {
+                    "description": "攻击IP数量",
+                    "type": "integer",
+                    "example": 45
+                },
+                "blockCount": {
+                    "description": "安全统计",
+                    "type": "integer",
+                    "example": 123
+                },
+                "error4xx": {
+                    "description": "错误统计",
+                    "type": "integer",
+                    "example": 234
+                },
+                "error4xxRate": {
+                    "description": "4xx错误率(百分比)",
+                    "type": "number",
+                    "example": 0.23
+                },
+                "error5xx": {
+                    "description": "5xx错误数量",
+                    "type": "integer",
+                    "example": 45
+                },
+                "error5xxRate": {
+                    "description": "5xx错误率(百分比)",
+                    "type": "number",
+                    "example": 0.05
+                },
+                "inboundTraffic": {
+                    "description": "入站流量(字节)",
+                    "type": "integer",
+                    "example": 67890123
+                },
+                "maxQPS": {
+                    "description": "最大QPS",
+                    "type": "integer",
+                    "example": 150
+                },
+                "outboundTraffic": {
+                    "description": "出站流量(字节)",
+                    "type": "integer",
+                    "example": 12345678
+                },
+                "timeRange": {
+                    "description": "时间范围",
+                    "type": "string",
+                    "example": "24h"
+                },
+                "totalRequests": {
+                    "description": "流量统计",
+                    "type": "integer",
+                    "example": 123456
+                }
+            }
+        },
+        "dto.RealtimeQPSData": {
+            "description": "实时QPS数据点，包含时间戳和值",
+            "type": "object",
+            "properties": {
+                "timestamp": {
+                    "description": "时间戳",
+                    "type": "string",
+                    "example": "2024-01-01T12:30:45Z"
+                },
+                "value": {
+                    "description": "QPS值",
+                    "type": "integer",
+                    "example": 120
+                }
+            }
+        },
+        "dto.RealtimeQPSResponse": {
+            "description": "实时QPS数据响应",
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "QPS数据点列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RealtimeQPSData"
+                    }
+                }
+            }
+        },
+        "dto.ResetPasswordResponseData": {
+            "type": "object",
+            "properties": {
+                "code": {
+                    "type": "integer",
+                    "example": 200
+                },
+                "data": {
+                    "$ref": "#/definitions/model.User"
+                },
+                "message": {
+                    "type": "string",
+                    "example": "操作成功"
+                },
+                "requestId": {
+                    "type": "string",
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                },
+                "success": {
+                    "type": "boolean",
+                    "example": true
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
+                }
+            }
+        },
+        "dto.RouteDTO": {
+            "description": "请求路径以指定前缀开头时转发到该路由的后端，多条路由按顺序匹配",
+            "type": "object",
+            "required": [
+                "backend",
+                "pathPrefix"
+            ],
+            "properties": {
+                "backend": {
+                    "description": "后端服务器配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.BackendDTO"
+                        }
+                    ]
+                },
+                "pathPrefix": {
+                    "description": "路径前缀，区分大小写",
+                    "type": "string",
+                    "maxLength": 256,
+                    "example": "/api/"
+                }
+            }
+        },
+        "dto.RuleAnalysisFinding": {
+            "description": "规则分析发现的单个问题，ruleIds 第一项为受影响的规则，其余为导致问题的规则",
+            "type": "object",
+            "properties": {
+                "kind": {
+                    "description": "问题类型：shadowed、conflict、duplicate、unreachable、invalid_regex、invalid_condition",
+                    "type": "string",
+                    "example": "shadowed"
+                },
+                "message": {
+                    "description": "问题描述",
+                    "type": "string",
+                    "example": "匹配的请求都会先被更高优先级的白名单规则匹配，规则不会命中"
+                },
+                "ruleIds": {
+                    "description": "涉及的规则ID",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "60d21b4367d0d8992e89e964",
+                        "60d21b4367d0d8992e89e965"
+                    ]
+                },
+                "ruleNames": {
+                    "description": "涉及的规则名称，与 ruleIds 一一对应",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "放行办公网",
+                        "拦截后台访问"
+                    ]
+                },
+                "severity": {
+                    "description": "严重程度：error、warning、info",
+                    "type": "string",
+                    "example": "warning"
+                }
+            }
+        },
+        "dto.RuleAnalysisResponse": {
+            "description": "按匹配顺序静态分析微规则的结果，问题按严重程度排序",
+            "type": "object",
+            "properties": {
+                "enabledCount": {
+                    "description": "启用的规则数",
+                    "type": "integer",
+                    "example": 20
+                },
+                "findings": {
+                    "description": "问题列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RuleAnalysisFinding"
+                    }
+                },
+                "ruleCount": {
+                    "description": "规则总数",
+                    "type": "integer",
+                    "example": 24
+                },
+                "summary": {
+                    "description": "问题统计",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.RuleAnalysisSummary"
+                        }
+                    ]
+                }
+            }
+        },
+        "dto.RuleAnalysisSummary": {
+            "description": "按严重程度统计的问题数量",
+            "type": "object",
+            "properties": {
+                "errors": {
+                    "description": "错误数量",
+                    "type": "integer",
+                    "example": 1
+                },
+                "infos": {
+                    "description": "提示数量",
+                    "type": "integer",
+                    "example": 0
+                },
+                "warnings": {
+                    "description": "警告数量",
+                    "type": "integer",
+                    "example": 2
+                }
+            }
+        },
+        "dto.RuleHitStats": {
+            "description": "规则累计命中次数、拦截次数和最后命中时间",
+            "type": "object",
+            "properties": {
+                "blocks": {
+                    "description": "累计拦截次数",
+                    "type": "integer",
+                    "example": 512
+                },
+                "hits": {
+                    "description": "累计命中次数",
+                    "type": "integer",
+                    "example": 1024
+                },
+                "lastHitAt": {
+                    "description": "最后命中时间，从未命中时为空",
+                    "type": "string",
+                    "example": "2024-01-01T12:30:45Z"
+                }
+            }
+        },
+        "dto.RuleOffloadItem": {
+            "description": "规则ID、名称和卸载状态",
+            "type": "object",
+            "properties": {
+                "offloaded": {
+                    "description": "是否在 HAProxy 中判断",
+                    "type": "boolean",
+                    "example": true
+                },
+                "reason": {
+                    "description": "只由 WAF 引擎判断的原因",
+                    "type": "string",
+                    "example": "规则设置了生效计划"
+                },
+                "ruleId": {
+                    "description": "规则ID",
+                    "type": "string",
+                    "example": "60d21b4367d0d8992e89e964"
+                },
+                "ruleName": {
+                    "description": "规则名称",
+                    "type": "string",
+                    "example": "拦截后台访问"
+                }
+            }
+        },
+        "dto.RuleOffloadResponse": {
+            "description": "按匹配顺序列出每条微规则是否卸载到 HAProxy，结果在下次应用站点配置或热重载时生效",
+            "type": "object",
+            "properties": {
+                "offloadedCount": {
+                    "description": "卸载到 HAProxy 的规则数",
+                    "type": "integer",
+                    "example": 10
+                },
+                "ruleCount": {
+                    "description": "规则总数",
+                    "type": "integer",
+                    "example": 24
+                },
+                "rules": {
+                    "description": "每条规则的卸载状态，按匹配顺序排列",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RuleOffloadItem"
+                    }
+                }
+            }
+        },
+        "dto.RuleOffloadStatus": {
+            "description": "只使用IP、CIDR、IP组、路径和URL条件的简单规则在 HAProxy 中以 ACL 判断，其余规则由 WAF 引擎判断",
+            "type": "object",
+            "properties": {
+                "offloaded": {
+                    "description": "是否在 HAProxy 中判断",
+                    "type": "boolean",
+                    "example": false
+                },
+                "reason": {
+                    "description": "只由 WAF 引擎判断的原因",
+                    "type": "string",
+                    "example": "规则只对部分站点生效"
+                }
+            }
+        },
+        "dto.RuleScheduleRequest": {
+            "description": "规则的生效时间范围和每周重复的生效时段，未设置的部分不做限制",
+            "type": "object",
+            "properties": {
+                "activeFrom": {
+                    "description": "生效开始时间",
+                    "type": "string",
+                    "example": "2024-03-17T00:00:00Z"
+                },
+                "activeUntil": {
+                    "description": "生效结束时间，过期后规则会被自动删除",
+                    "type": "string",
+                    "example": "2024-03-18T00:00:00Z"
+                },
+                "timezone": {
+                    "description": "每周时段使用的时区，默认 UTC",
+                    "type": "string",
+                    "example": "Asia/Shanghai"
+                },
+                "windows": {
+                    "description": "每周生效时段，为空表示不限时段",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.WeeklyWindowRequest"
+                    }
+                }
+            }
+        },
+        "dto.RuleStatsDataPoint": {
+            "description": "规则在某个时间桶内的命中和拦截次数",
+            "type": "object",
+            "properties": {
+                "blocks": {
+                    "description": "拦截次数",
+                    "type": "integer",
+                    "example": 64
+                },
+                "hits": {
+                    "description": "命中次数",
+                    "type": "integer",
+                    "example": 128
+                },
+                "timestamp": {
+                    "description": "时间桶开始时间",
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                }
+            }
+        },
+        "dto.RuleStatsItem": {
+            "description": "单条规则在时间范围内的汇总和时间序列",
+            "type": "object",
+            "properties": {
+                "blocks": {
+                    "description": "时间范围内拦截次数",
+                    "type": "integer",
+                    "example": 512
+                },
+                "history": {
+                    "description": "时间序列",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RuleStatsDataPoint"
+                    }
+                },
+                "hits": {
+                    "description": "时间范围内命中次数",
+                    "type": "integer",
+                    "example": 1024
+                },
+                "lastHitAt": {
+                    "description": "最后命中时间",
+                    "type": "string",
+                    "example": "2024-01-01T12:30:45Z"
+                },
+                "ruleId": {
+                    "description": "规则ID",
+                    "type": "string",
+                    "example": "60d21b4367d0d8992e89e964"
                 },
-                "logFile": {
-                    "description": "日志文件",
+                "ruleName": {
+                    "description": "规则名称，仅微规则有效",
                     "type": "string",
-                    "example": "/dev/stdout"
+                    "example": "SQL注入防护规则"
                 },
-                "logFormat": {
-                    "description": "日志格式",
+                "source": {
+                    "description": "规则来源",
                     "type": "string",
-                    "example": "console"
+                    "example": "micro"
+                }
+            }
+        },
+        "dto.RuleStatsResponse": {
+            "description": "规则命中统计响应",
+            "type": "object",
+            "properties": {
+                "interval": {
+                    "description": "时间桶粒度: hour, 6hour, day",
+                    "type": "string",
+                    "example": "hour"
                 },
-                "logLevel": {
-                    "description": "日志级别",
+                "items": {
+                    "description": "规则统计列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.RuleStatsItem"
+                    }
+                },
+                "timeRange": {
+                    "description": "时间范围",
                     "type": "string",
-                    "example": "info"
+                    "example": "24h"
+                }
+            }
+        },
+        "dto.RunnerControlRequest": {
+            "type": "object",
+            "required": [
+                "action"
+            ],
+            "properties": {
+                "action": {
+                    "description": "控制动作",
+                    "type": "string",
+                    "enum": [
+                        "start",
+                        "stop",
+                        "restart",
+                        "force_stop",
+                        "reload"
+                    ]
+                }
+            }
+        },
+        "dto.RunnerControlResponse": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "description": "执行的动作",
+                    "type": "string",
+                    "example": "start"
                 },
-                "name": {
-                    "description": "应用名称",
+                "message": {
+                    "description": "操作消息",
                     "type": "string",
-                    "example": "coraza"
+                    "example": "运行器已成功启动"
                 },
-                "transactionTTL": {
-                    "description": "事务超时时间(毫秒)",
-                    "type": "integer",
-                    "example": 60000
+                "state": {
+                    "description": "操作后的状态",
+                    "type": "string",
+                    "example": "running"
+                },
+                "success": {
+                    "description": "操作是否成功",
+                    "type": "boolean",
+                    "example": true
                 }
             }
         },
-        "dto.AttackEventAggregateResult": {
-            "description": "攻击事件的聚合统计结果，提供IP、域名、端口维度的攻击信息汇总，包含攻击次数、首次和最近攻击时间、持续时间等关键指标",
+        "dto.RunnerStatusResponse": {
             "type": "object",
             "properties": {
-                "count": {
-                    "description": "攻击总次数，同一来源的攻击计数",
-                    "type": "integer",
-                    "example": 15
+                "isRunning": {
+                    "description": "是否正在运行",
+                    "type": "boolean",
+                    "example": true
                 },
-                "domain": {
-                    "description": "域名，被攻击的站点",
+                "state": {
+                    "description": "状态：running, stopped, error",
                     "type": "string",
-                    "example": "example.com"
+                    "example": "running"
+                }
+            }
+        },
+        "dto.ServerDTO": {
+            "type": "object",
+            "required": [
+                "host",
+                "port"
+            ],
+            "properties": {
+                "backup": {
+                    "description": "是否为备用服务器，仅在所有主服务器 DOWN 时使用",
+                    "type": "boolean",
+                    "example": false
                 },
-                "dstPort": {
-                    "description": "目标端口号，被攻击的服务端口",
-                    "type": "integer",
-                    "example": 443
+                "host": {
+                    "description": "主机地址",
+                    "type": "string",
+                    "example": "backend.example.com"
                 },
-                "durationInMinutes": {
-                    "description": "攻击持续时间(分钟)，从首次到最近攻击的时间跨度",
-                    "type": "number",
-                    "example": 18.2
+                "hostHeader": {
+                    "description": "Host 头处理方式：preserve-保留，server-改写为服务器地址，custom-改写为自定义值；默认 k8s 环境为 server，其他环境为 preserve",
+                    "type": "string",
+                    "enum": [
+                        "preserve",
+                        "server",
+                        "custom"
+                    ],
+                    "example": "server"
                 },
-                "firstAttackTime": {
-                    "description": "首次攻击时间，该IP首次发起攻击的时间点",
+                "hostHeaderValue": {
+                    "description": "自定义 Host 头，处理方式为 custom 时必填",
                     "type": "string",
-                    "example": "2024-03-18T08:12:33Z"
+                    "maxLength": 261,
+                    "example": "api.internal:8080"
                 },
-                "isOngoing": {
-                    "description": "是否正在进行中，标识攻击是否仍在持续",
+                "isSSL": {
+                    "description": "是否启用SSL",
                     "type": "boolean",
-                    "example": true
+                    "example": false
+                },
+                "maxConn": {
+                    "description": "最大并发连接数，默认不限制",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 1000
+                },
+                "port": {
+                    "description": "端口",
+                    "type": "integer",
+                    "maximum": 65535,
+                    "minimum": 1,
+                    "example": 80
+                },
+                "weight": {
+                    "description": "权重，默认 1",
+                    "type": "integer",
+                    "maximum": 256,
+                    "minimum": 1,
+                    "example": 1
+                }
+            }
+        },
+        "dto.SetSiteServerStateRequest": {
+            "description": "通过 HAProxy 运行时 API 修改服务器状态，无需重新加载配置",
+            "type": "object",
+            "required": [
+                "state"
+            ],
+            "properties": {
+                "state": {
+                    "description": "状态：ready-正常接收流量，drain-摘流，maint-维护",
+                    "type": "string",
+                    "enum": [
+                        "ready",
+                        "drain",
+                        "maint"
+                    ],
+                    "example": "drain"
+                }
+            }
+        },
+        "dto.SetSiteServerWeightRequest": {
+            "description": "通过 HAProxy 运行时 API 修改服务器权重，无需重新加载配置",
+            "type": "object",
+            "required": [
+                "weight"
+            ],
+            "properties": {
+                "weight": {
+                    "description": "权重",
+                    "type": "integer",
+                    "maximum": 256,
+                    "minimum": 1,
+                    "example": 10
+                }
+            }
+        },
+        "dto.SiteLimitsDTO": {
+            "description": "为 0 的项使用 HAProxy 默认配置；请求头超时、最大连接数和单 IP 连接速率作用于监听端口，同一端口上的多个站点取最严格的值",
+            "type": "object",
+            "properties": {
+                "connRate": {
+                    "description": "单个客户端 IP 每 10 秒最多新建的连接数",
+                    "type": "integer",
+                    "maximum": 100000,
+                    "minimum": 1,
+                    "example": 100
+                },
+                "connectTimeout": {
+                    "description": "连接后端服务器的超时，单位毫秒",
+                    "type": "integer",
+                    "maximum": 300000,
+                    "minimum": 100,
+                    "example": 3000
+                },
+                "httpRequestTimeout": {
+                    "description": "接收完整请求头的超时，单位毫秒",
+                    "type": "integer",
+                    "maximum": 300000,
+                    "minimum": 1000,
+                    "example": 5000
+                },
+                "maxBodySize": {
+                    "description": "请求体最大字节数，超过时返回 413",
+                    "type": "integer",
+                    "minimum": 1,
+                    "example": 10485760
+                },
+                "maxConn": {
+                    "description": "监听端口的最大并发连接数",
+                    "type": "integer",
+                    "maximum": 1000000,
+                    "minimum": 1,
+                    "example": 10000
+                },
+                "requestRate": {
+                    "description": "单个客户端 IP 每 10 秒最多发送到站点的请求数",
+                    "type": "integer",
+                    "maximum": 1000000,
+                    "minimum": 1,
+                    "example": 500
+                },
+                "serverTimeout": {
+                    "description": "等待后端服务器响应的超时，单位毫秒",
+                    "type": "integer",
+                    "maximum": 3600000,
+                    "minimum": 1000,
+                    "example": 60000
+                }
+            }
+        },
+        "dto.SiteListResponse": {
+            "description": "站点列表响应",
+            "type": "object",
+            "properties": {
+                "items": {
+                    "description": "站点列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.Site"
+                    }
+                },
+                "total": {
+                    "description": "总数",
+                    "type": "integer"
+                }
+            }
+        },
+        "dto.SiteResponse": {
+            "description": "站点信息响应",
+            "type": "object",
+            "properties": {
+                "activeStatus": {
+                    "description": "站点是否激活",
+                    "type": "boolean"
+                },
+                "aliases": {
+                    "description": "其他域名，支持 *.example.com 形式的通配符域名",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "backend": {
+                    "description": "后端服务器配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Backend"
+                        }
+                    ]
+                },
+                "certificateId": {
+                    "description": "证书库中的证书ID，为空时按域名从证书库自动选择",
+                    "type": "string"
+                },
+                "createdAt": {
+                    "type": "string"
+                },
+                "domain": {
+                    "description": "域名，如 a.com",
+                    "type": "string"
+                },
+                "enableHTTPS": {
+                    "description": "是否启用HTTPS",
+                    "type": "boolean"
+                },
+                "errorPages": {
+                    "description": "自定义错误页，替换 HAProxy 默认的错误响应",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ErrorPage"
+                    }
+                },
+                "httpsRedirect": {
+                    "description": "HTTP 请求重定向到 HTTPS 的方式，只在启用 HTTPS 时生效，为空时使用 301",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.HTTPSRedirect"
+                        }
+                    ]
+                },
+                "id": {
+                    "description": "站点ID",
+                    "type": "string"
+                },
+                "limits": {
+                    "description": "超时、请求体大小和连接保护，为空时使用 HAProxy 默认配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SiteLimits"
+                        }
+                    ]
                 },
-                "lastAttackTime": {
-                    "description": "最近攻击时间，该IP最后一次攻击的时间点",
-                    "type": "string",
-                    "example": "2024-03-18T08:30:45Z"
+                "listenPort": {
+                    "description": "监听端口，如 9000",
+                    "type": "integer"
                 },
-                "srcIp": {
-                    "description": "来源IP地址，攻击者地址",
-                    "type": "string",
-                    "example": "192.168.1.100"
+                "loginProtection": {
+                    "description": "登录保护配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.LoginProtection"
+                        }
+                    ]
                 },
-                "srcIpInfo": {
-                    "description": "来源IP地址，攻击者地址",
+                "maintenance": {
+                    "description": "维护模式，为空时不启用",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/model.IPInfo"
+                            "$ref": "#/definitions/model.Maintenance"
                         }
                     ]
-                }
-            }
-        },
-        "dto.AttackEventResponse": {
-            "description": "攻击事件查询的分页响应结构体，包含聚合结果列表及分页元数据，用于前端展示和翻页控制",
-            "type": "object",
-            "properties": {
-                "currentPage": {
-                    "description": "当前页码，从1开始计数",
-                    "type": "integer",
-                    "example": 1
                 },
-                "pageSize": {
-                    "description": "每页大小，当前设置的每页记录数",
-                    "type": "integer",
-                    "example": 10
+                "name": {
+                    "description": "站点名称",
+                    "type": "string"
                 },
-                "results": {
-                    "description": "聚合结果列表，当前页的攻击事件记录",
+                "requestHeaders": {
+                    "description": "转发到后端前按顺序执行的请求头规则",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.AttackEventAggregateResult"
+                        "$ref": "#/definitions/model.HeaderRule"
                     }
                 },
-                "totalCount": {
-                    "description": "总记录数，符合条件的攻击事件总数",
-                    "type": "integer",
-                    "example": 35
-                },
-                "totalPages": {
-                    "description": "总页数，根据总记录数和每页大小计算",
-                    "type": "integer",
-                    "example": 4
-                }
-            }
-        },
-        "dto.AttackLogResponse": {
-            "description": "攻击日志查询的分页响应结构体，返回详细的WAF日志记录及分页信息，便于安全分析和事件追踪",
-            "type": "object",
-            "properties": {
-                "currentPage": {
-                    "description": "当前页码，从1开始计数",
-                    "type": "integer",
-                    "example": 1
-                },
-                "pageSize": {
-                    "description": "每页大小，当前设置的每页记录数",
-                    "type": "integer",
-                    "example": 10
+                "responseHeaders": {
+                    "description": "返回客户端前按顺序执行的响应头规则，在安全响应头预设之后执行",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.HeaderRule"
+                    }
                 },
-                "results": {
-                    "description": "日志记录列表，当前页的WAF攻击日志详情",
+                "routes": {
+                    "description": "路径路由，按顺序匹配，未命中时使用默认后端",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.WAFLog"
+                        "$ref": "#/definitions/model.Route"
                     }
                 },
-                "totalCount": {
-                    "description": "总记录数，符合查询条件的日志总数",
-                    "type": "integer",
-                    "example": 128
+                "securityHeaders": {
+                    "description": "安全响应头预设，为空时不添加",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SecurityHeaderPreset"
+                        }
+                    ]
                 },
-                "totalPages": {
-                    "description": "总页数，根据总记录数和每页大小计算",
-                    "type": "integer",
-                    "example": 13
-                }
-            }
-        },
-        "dto.BackendDTO": {
-            "type": "object",
-            "required": [
-                "servers"
-            ],
-            "properties": {
-                "servers": {
-                    "description": "服务器列表，至少需要一个服务器",
+                "serverStatus": {
+                    "description": "后端服务器运行状态，仅在站点详情中返回，HAProxy 未运行时为空",
                     "type": "array",
-                    "minItems": 1,
                     "items": {
-                        "$ref": "#/definitions/dto.ServerDTO"
+                        "$ref": "#/definitions/dto.SiteServerStatus"
                     }
-                }
-            }
-        },
-        "dto.BlockedIPCleanupResponse": {
-            "description": "清理过期封禁IP记录的响应数据",
-            "type": "object",
-            "properties": {
-                "deletedCount": {
-                    "description": "删除的记录数量",
-                    "type": "integer",
-                    "example": 25
                 },
-                "message": {
-                    "description": "操作结果消息",
-                    "type": "string",
-                    "example": "已成功清理过期的封禁IP记录"
-                }
-            }
-        },
-        "dto.BlockedIPHourlyStats": {
-            "description": "按小时的封禁统计",
-            "type": "object",
-            "properties": {
-                "count": {
-                    "description": "该小时的封禁数量",
-                    "type": "integer",
-                    "example": 10
+                "tlsPolicy": {
+                    "description": "HTTPS 的 TLS 策略，为空时使用 HAProxy 默认配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TLSPolicy"
+                        }
+                    ]
                 },
-                "hour": {
-                    "description": "小时时间点",
-                    "type": "string",
-                    "example": "2023-12-01T10:00:00Z"
+                "updatedAt": {
+                    "type": "string"
+                },
+                "wafEnabled": {
+                    "description": "是否启用WAF",
+                    "type": "boolean"
+                },
+                "wafMode": {
+                    "description": "WAF防护模式",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.WAFMode"
+                        }
+                    ]
                 }
             }
         },
-        "dto.BlockedIPListResponse": {
-            "description": "封禁IP分页列表响应",
+        "dto.SiteScopeRequest": {
+            "description": "规则或IP组的站点作用域，站点ID或主机名模式任一命中即生效，两者均为空表示对所有站点生效",
             "type": "object",
             "properties": {
-                "items": {
-                    "description": "IP列表",
+                "hosts": {
+                    "description": "主机名模式列表，支持 *.example.com 形式的通配符",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.BlockedIPResponse"
-                    }
-                },
-                "page": {
-                    "description": "当前页码",
-                    "type": "integer",
-                    "example": 1
-                },
-                "pages": {
-                    "description": "总页数",
-                    "type": "integer",
-                    "example": 10
-                },
-                "size": {
-                    "description": "每页数量",
-                    "type": "integer",
-                    "example": 10
+                        "type": "string"
+                    },
+                    "example": [
+                        "*.example.com"
+                    ]
                 },
-                "total": {
-                    "description": "总数量",
-                    "type": "integer",
-                    "example": 100
+                "siteIds": {
+                    "description": "站点ID列表",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "60d21b4367d0d8992e89e964"
+                    ]
                 }
             }
         },
-        "dto.BlockedIPResponse": {
-            "description": "封禁IP详细信息",
+        "dto.SiteServerStatus": {
+            "description": "从 HAProxy 统计信息中读取的后端服务器状态",
             "type": "object",
             "properties": {
-                "blockedAt": {
-                    "description": "封禁开始时间",
-                    "type": "string",
-                    "example": "2023-12-01T10:00:00Z"
-                },
-                "blockedUntil": {
-                    "description": "封禁结束时间",
+                "backend": {
+                    "description": "HAProxy 后端名称",
                     "type": "string",
-                    "example": "2023-12-01T11:00:00Z"
+                    "example": "be_example_com"
                 },
-                "ip": {
-                    "description": "被封禁的IP地址",
+                "checkStatus": {
+                    "description": "最近一次健康检查结果",
                     "type": "string",
-                    "example": "192.168.1.1"
+                    "example": "L7OK"
                 },
-                "isActive": {
-                    "description": "是否仍在封禁中",
-                    "type": "boolean",
-                    "example": true
+                "currentSessions": {
+                    "description": "当前会话数",
+                    "type": "integer",
+                    "example": 3
                 },
-                "reason": {
-                    "description": "封禁原因",
+                "host": {
+                    "description": "主机地址",
                     "type": "string",
-                    "example": "high_frequency_attack"
-                },
-                "remainingTTL": {
-                    "description": "剩余封禁时间（秒）",
-                    "type": "integer",
-                    "example": 3600
+                    "example": "10.0.0.1"
                 },
-                "requestUri": {
-                    "description": "请求URI",
+                "pathPrefix": {
+                    "description": "所属路径路由，为空表示站点默认后端",
                     "type": "string",
-                    "example": "/api/v1/login"
-                }
-            }
-        },
-        "dto.BlockedIPStatsResponse": {
-            "description": "封禁IP统计信息",
-            "type": "object",
-            "properties": {
-                "activeBlocked": {
-                    "description": "当前生效的封禁数量",
-                    "type": "integer",
-                    "example": 50
+                    "example": "/api/"
                 },
-                "expiredBlocked": {
-                    "description": "已过期的封禁数量",
+                "port": {
+                    "description": "端口",
                     "type": "integer",
-                    "example": 950
+                    "example": 80
                 },
-                "last24HourStats": {
-                    "description": "最近24小时统计",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.BlockedIPHourlyStats"
-                    }
+                "server": {
+                    "description": "HAProxy 服务器名称",
+                    "type": "string",
+                    "example": "example_com_0"
                 },
-                "reasonStats": {
-                    "description": "按原因统计",
-                    "type": "object",
-                    "additionalProperties": {
-                        "type": "integer"
-                    }
+                "status": {
+                    "description": "运行状态，如 UP、DOWN、MAINT、no check，HAProxy 中不存在时为 UNKNOWN",
+                    "type": "string",
+                    "example": "UP"
                 },
-                "totalBlocked": {
-                    "description": "总封禁数量",
+                "weight": {
+                    "description": "当前生效的权重",
                     "type": "integer",
-                    "example": 1000
+                    "example": 1
                 }
             }
         },
-        "dto.CertificateCreateRequest": {
-            "description": "创建证书的请求参数",
+        "dto.TLSPolicyDTO": {
+            "description": "站点 HTTPS 的协议版本、加密套件、ALPN、HSTS、OCSP Stapling 和客户端证书认证配置，按 SNI 作用于站点的所有主机名",
             "type": "object",
-            "required": [
-                "privateKey",
-                "publicKey"
-            ],
             "properties": {
-                "description": {
-                    "description": "证书描述",
-                    "type": "string",
-                    "example": "用于example.com的证书"
-                },
-                "domains": {
-                    "description": "证书绑定的域名列表",
+                "alpn": {
+                    "description": "ALPN 协商的协议，按优先级排列",
                     "type": "array",
+                    "maxItems": 2,
+                    "uniqueItems": true,
                     "items": {
                         "type": "string"
                     },
                     "example": [
-                        "[\"example.com\"]"
+                        "h2",
+                        "http/1.1"
                     ]
                 },
-                "expireDate": {
-                    "description": "证书过期日期",
+                "ciphers": {
+                    "description": "TLSv1.2 及以下的加密套件，OpenSSL 格式，冒号分隔",
                     "type": "string",
-                    "example": "2023-12-31T23:59:59Z"
+                    "maxLength": 2048,
+                    "example": "ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256"
                 },
-                "fingerPrint": {
-                    "description": "证书指纹",
+                "ciphersuites": {
+                    "description": "TLSv1.3 的加密套件，冒号分隔",
                     "type": "string",
-                    "example": "AA:BB:CC:DD:..."
+                    "maxLength": 1024,
+                    "example": "TLS_AES_128_GCM_SHA256:TLS_AES_256_GCM_SHA384"
                 },
-                "issuerName": {
-                    "description": "颁发机构",
-                    "type": "string",
-                    "example": "Let's Encrypt"
+                "clientAuth": {
+                    "description": "客户端证书认证，为空时不校验客户端证书",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.ClientAuthDTO"
+                        }
+                    ]
                 },
-                "name": {
-                    "description": "证书名称/别名",
+                "hsts": {
+                    "description": "HSTS 响应头，为空时不添加",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.HSTSDTO"
+                        }
+                    ]
+                },
+                "maxVersion": {
+                    "description": "最高协议版本",
                     "type": "string",
-                    "example": "example-cert"
+                    "enum": [
+                        "TLSv1.0",
+                        "TLSv1.1",
+                        "TLSv1.2",
+                        "TLSv1.3"
+                    ],
+                    "example": "TLSv1.3"
                 },
-                "privateKey": {
-                    "description": "私钥内容（PEM格式）",
-                    "type": "string"
+                "minVersion": {
+                    "description": "最低协议版本",
+                    "type": "string",
+                    "enum": [
+                        "TLSv1.0",
+                        "TLSv1.1",
+                        "TLSv1.2",
+                        "TLSv1.3"
+                    ],
+                    "example": "TLSv1.2"
                 },
-                "publicKey": {
-                    "description": "公钥内容（PEM格式）",
-                    "type": "string"
+                "ocspStapling": {
+                    "description": "是否启用 OCSP Stapling，证书链需要包含签发证书",
+                    "type": "boolean",
+                    "example": false
                 }
             }
         },
-        "dto.CertificateDTO": {
+        "dto.ThreatFeedCreateRequest": {
+            "description": "创建威胁情报源订阅，会同时创建同步的IP组",
             "type": "object",
             "required": [
-                "certName",
-                "expireDate",
-                "fingerPrint",
-                "issuerName",
-                "privateKey",
-                "publicKey"
+                "format",
+                "name",
+                "refreshInterval",
+                "source"
             ],
             "properties": {
-                "certName": {
-                    "description": "证书名称",
-                    "type": "string",
-                    "example": "my-cert"
+                "csvColumn": {
+                    "description": "CSV 格式中IP所在列，从 0 开始",
+                    "type": "integer",
+                    "maximum": 255,
+                    "minimum": 0,
+                    "example": 0
                 },
-                "expireDate": {
-                    "description": "过期时间",
-                    "type": "string"
+                "csvHeader": {
+                    "description": "CSV 第一行是否为表头",
+                    "type": "boolean",
+                    "example": false
                 },
-                "fingerPrint": {
-                    "description": "证书指纹",
-                    "type": "string"
+                "enabled": {
+                    "description": "是否启用，默认启用",
+                    "type": "boolean",
+                    "example": true
                 },
-                "issuerName": {
-                    "description": "颁发机构",
+                "format": {
+                    "description": "情报源格式：plain-IP列表，cidr-CIDR列表，drop-Spamhaus DROP，csv-CSV指定列",
                     "type": "string",
-                    "example": "Let's Encrypt"
+                    "enum": [
+                        "plain",
+                        "cidr",
+                        "drop",
+                        "csv"
+                    ],
+                    "example": "drop"
                 },
-                "privateKey": {
-                    "description": "私钥内容",
-                    "type": "string"
+                "ipGroupName": {
+                    "description": "同步的IP组名称，默认为 feed_ 加情报源名称",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "feed_spamhaus-drop"
                 },
-                "publicKey": {
-                    "description": "公钥内容",
-                    "type": "string"
+                "name": {
+                    "description": "情报源名称",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "spamhaus-drop"
+                },
+                "refreshInterval": {
+                    "description": "刷新间隔，单位秒，范围 60 秒到 7 天",
+                    "type": "integer",
+                    "maximum": 604800,
+                    "minimum": 60,
+                    "example": 3600
+                },
+                "source": {
+                    "description": "情报源地址，http(s) URL 或本地文件绝对路径",
+                    "type": "string",
+                    "example": "https://www.spamhaus.org/drop/drop.txt"
                 }
             }
         },
-        "dto.CertificateListResponse": {
-            "description": "证书列表响应",
+        "dto.ThreatFeedListResponse": {
+            "description": "威胁情报源分页列表响应",
             "type": "object",
             "properties": {
                 "items": {
-                    "description": "证书列表",
+                    "description": "威胁情报源列表",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.CertificateStore"
+                        "$ref": "#/definitions/model.ThreatFeed"
                     }
                 },
                 "total": {
-                    "description": "总数",
-                    "type": "integer"
+                    "description": "总数量",
+                    "type": "integer",
+                    "example": 3
                 }
             }
         },
-        "dto.CertificateUpdateRequest": {
-            "description": "更新证书的请求参数",
+        "dto.ThreatFeedUpdateRequest": {
+            "description": "更新威胁情报源订阅，未传入的字段保持不变；修改地址或格式后会尽快重新完整拉取",
             "type": "object",
             "properties": {
-                "description": {
-                    "description": "证书描述",
-                    "type": "string",
-                    "example": "用于example.com的证书"
+                "csvColumn": {
+                    "description": "CSV 格式中IP所在列",
+                    "type": "integer",
+                    "maximum": 255,
+                    "minimum": 0,
+                    "example": 0
                 },
-                "domains": {
-                    "description": "证书绑定的域名列表",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "[\"example.com\"]"
-                    ]
+                "csvHeader": {
+                    "description": "CSV 第一行是否为表头",
+                    "type": "boolean",
+                    "example": false
                 },
-                "expireDate": {
-                    "description": "证书过期日期",
-                    "type": "string",
-                    "example": "2023-12-31T23:59:59Z"
+                "enabled": {
+                    "description": "是否启用",
+                    "type": "boolean",
+                    "example": true
                 },
-                "fingerPrint": {
-                    "description": "证书指纹",
+                "format": {
+                    "description": "情报源格式",
                     "type": "string",
-                    "example": "AA:BB:CC:DD:..."
+                    "enum": [
+                        "plain",
+                        "cidr",
+                        "drop",
+                        "csv"
+                    ],
+                    "example": "drop"
                 },
-                "issuerName": {
-                    "description": "颁发机构",
+                "name": {
+                    "description": "情报源名称",
                     "type": "string",
-                    "example": "Let's Encrypt"
+                    "maxLength": 64,
+                    "example": "spamhaus-drop"
                 },
-                "name": {
-                    "description": "证书名称/别名",
+                "refreshInterval": {
+                    "description": "刷新间隔，单位秒",
+                    "type": "integer",
+                    "maximum": 604800,
+                    "minimum": 60,
+                    "example": 3600
+                },
+                "source": {
+                    "description": "情报源地址",
                     "type": "string",
-                    "example": "example-cert"
+                    "example": "https://www.spamhaus.org/drop/drop.txt"
+                }
+            }
+        },
+        "dto.TimeSeriesDataPoint": {
+            "description": "时间序列图表数据点",
+            "type": "object",
+            "properties": {
+                "timestamp": {
+                    "description": "时间戳，表示数据点的时间",
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
                 },
-                "privateKey": {
-                    "description": "私钥内容（PEM格式）",
-                    "type": "string"
+                "value": {
+                    "description": "数值，表示该时间点的指标值",
+                    "type": "integer",
+                    "example": 128
+                }
+            }
+        },
+        "dto.TimeSeriesResponse": {
+            "description": "时间序列图表数据响应",
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "数据点列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TimeSeriesDataPoint"
+                    }
                 },
-                "publicKey": {
-                    "description": "公钥内容（PEM格式）",
-                    "type": "string"
+                "metric": {
+                    "description": "指标名称",
+                    "type": "string",
+                    "example": "requests"
+                },
+                "timeRange": {
+                    "description": "时间范围",
+                    "type": "string",
+                    "example": "24h"
                 }
             }
         },
-        "dto.CombinedTimeSeriesResponse": {
-            "description": "同时包含请求数和拦截数的时间序列数据",
+        "dto.TrafficDataPoint": {
+            "description": "流量时间序列图表数据点",
             "type": "object",
             "properties": {
-                "blocks": {
-                    "description": "拦截数时间序列",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.TimeSeriesResponse"
-                        }
-                    ]
+                "inboundTraffic": {
+                    "description": "入站流量(字节)",
+                    "type": "integer",
+                    "example": 1024000
                 },
-                "requests": {
-                    "description": "请求数时间序列",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.TimeSeriesResponse"
-                        }
-                    ]
+                "outboundTraffic": {
+                    "description": "出站流量(字节)",
+                    "type": "integer",
+                    "example": 2048000
+                },
+                "timestamp": {
+                    "description": "时间戳，表示数据点的时间",
+                    "type": "string",
+                    "example": "2024-01-01T12:00:00Z"
+                }
+            }
+        },
+        "dto.TrafficTimeSeriesResponse": {
+            "description": "流量时间序列图表数据响应",
+            "type": "object",
+            "properties": {
+                "data": {
+                    "description": "流量数据点列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/dto.TrafficDataPoint"
+                    }
                 },
                 "timeRange": {
                     "description": "时间范围",
@@ -3596,113 +8638,188 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.ConfigPatchRequest": {
-            "description": "用于部分更新配置的请求参数",
+        "dto.TrapDecoyRequest": {
+            "description": "访问陷阱路径时返回的伪装响应",
             "type": "object",
+            "required": [
+                "statusCode"
+            ],
             "properties": {
-                "engine": {
-                    "description": "引擎配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.EnginePatchDTO"
-                        }
-                    ]
+                "body": {
+                    "description": "响应内容，以 text/html 返回，受 SPOE 帧大小限制最长 4096 个字符",
+                    "type": "string",
+                    "maxLength": 4096,
+                    "example": "APP_KEY=base64:changeme\nDB_PASSWORD=secret"
                 },
-                "haproxy": {
-                    "description": "HAProxy配置",
+                "statusCode": {
+                    "description": "状态码，支持 200 和 404",
+                    "type": "integer",
+                    "enum": [
+                        200,
+                        404
+                    ],
+                    "example": 200
+                }
+            }
+        },
+        "dto.TrapPathCreateRequest": {
+            "description": "创建蜜罐陷阱路径，访问该路径的来源IP会被立即封禁",
+            "type": "object",
+            "required": [
+                "name",
+                "path"
+            ],
+            "properties": {
+                "blockDuration": {
+                    "description": "封禁时长，单位秒，默认 86400",
+                    "type": "integer",
+                    "maximum": 31536000,
+                    "minimum": 60,
+                    "example": 86400
+                },
+                "decoy": {
+                    "description": "诱饵响应，为空时返回 403",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/dto.HaproxyPatchDTO"
+                            "$ref": "#/definitions/dto.TrapDecoyRequest"
                         }
                     ]
                 },
-                "isDebug": {
-                    "description": "是否开启调试模式",
-                    "type": "boolean",
-                    "example": false
-                },
-                "isK8s": {
-                    "description": "是否在K8s环境",
+                "enabled": {
+                    "description": "是否启用，默认启用",
                     "type": "boolean",
-                    "example": false
+                    "example": true
                 },
-                "isResponseCheck": {
-                    "description": "是否检查响应",
-                    "type": "boolean",
-                    "example": false
+                "matchType": {
+                    "description": "匹配方式：exact-完全匹配，prefix-前缀匹配，默认 exact",
+                    "type": "string",
+                    "enum": [
+                        "exact",
+                        "prefix"
+                    ],
+                    "example": "exact"
                 },
                 "name": {
-                    "description": "配置名称",
+                    "description": "名称",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "dotenv"
+                },
+                "path": {
+                    "description": "陷阱路径，必须以 / 开头，匹配时不区分大小写",
                     "type": "string",
-                    "example": "AppConfig"
+                    "maxLength": 512,
+                    "example": "/.env"
+                },
+                "scope": {
+                    "description": "站点作用域，为空表示对所有站点生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.SiteScopeRequest"
+                        }
+                    ]
                 }
             }
         },
-        "dto.ConfigResponse": {
-            "description": "配置响应",
+        "dto.TrapPathListResponse": {
+            "description": "蜜罐陷阱路径分页列表响应",
             "type": "object",
             "properties": {
-                "createdAt": {
-                    "description": "创建时间",
-                    "type": "string"
+                "items": {
+                    "description": "蜜罐陷阱路径列表",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.TrapPath"
+                    }
                 },
-                "engine": {
-                    "description": "引擎配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.EngineDTO"
-                        }
-                    ]
+                "total": {
+                    "description": "总数量",
+                    "type": "integer",
+                    "example": 3
+                }
+            }
+        },
+        "dto.TrapPathUpdateRequest": {
+            "description": "更新蜜罐陷阱路径，未传入的字段保持不变",
+            "type": "object",
+            "properties": {
+                "blockDuration": {
+                    "description": "封禁时长，单位秒",
+                    "type": "integer",
+                    "maximum": 31536000,
+                    "minimum": 60,
+                    "example": 86400
                 },
-                "haproxy": {
-                    "description": "HAProxy配置",
+                "decoy": {
+                    "description": "诱饵响应，传入时整体替换",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/dto.HaproxyDTO"
+                            "$ref": "#/definitions/dto.TrapDecoyRequest"
                         }
                     ]
                 },
-                "id": {
-                    "description": "配置ID",
-                    "type": "string"
+                "enabled": {
+                    "description": "是否启用",
+                    "type": "boolean",
+                    "example": true
                 },
-                "isDebug": {
-                    "description": "是否开启调试模式",
-                    "type": "boolean"
+                "matchType": {
+                    "description": "匹配方式",
+                    "type": "string",
+                    "enum": [
+                        "exact",
+                        "prefix"
+                    ],
+                    "example": "exact"
                 },
-                "isK8s": {
-                    "description": "是否在K8s环境",
-                    "type": "boolean"
+                "name": {
+                    "description": "名称",
+                    "type": "string",
+                    "maxLength": 64,
+                    "example": "dotenv"
                 },
-                "isResponseCheck": {
-                    "description": "是否检查响应",
-                    "type": "boolean"
+                "path": {
+                    "description": "陷阱路径",
+                    "type": "string",
+                    "maxLength": 512,
+                    "example": "/.env"
                 },
-                "name": {
-                    "description": "配置名称",
-                    "type": "string"
+                "removeDecoy": {
+                    "description": "移除诱饵响应，改为返回 403",
+                    "type": "boolean",
+                    "example": false
                 },
-                "updatedAt": {
-                    "description": "更新时间",
-                    "type": "string"
+                "scope": {
+                    "description": "站点作用域，传空对象表示改为对所有站点生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.SiteScopeRequest"
+                        }
+                    ]
                 }
             }
         },
-        "dto.CreateSiteRequest": {
-            "description": "创建站点的请求参数",
+        "dto.UpdateSiteRequest": {
+            "description": "更新站点的请求参数",
             "type": "object",
-            "required": [
-                "backend",
-                "domain",
-                "listenPort",
-                "name"
-            ],
             "properties": {
                 "activeStatus": {
                     "description": "站点状态",
                     "type": "boolean",
                     "example": true
                 },
+                "aliases": {
+                    "description": "其他域名，传入时整体替换，传入空数组表示清空",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "www.example.com",
+                        "*.example.com"
+                    ]
+                },
                 "backend": {
                     "description": "后端服务器配置",
                     "allOf": [
@@ -3711,13 +8828,10 @@ const docTemplate = `{
                         }
                     ]
                 },
-                "certificate": {
-                    "description": "证书信息",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.CertificateDTO"
-                        }
-                    ]
+                "certificateId": {
+                    "description": "证书库中的证书ID，为空时按域名自动选择包含站点所有域名的证书",
+                    "type": "string",
+                    "example": "60d21b4367d0d8992e89e964"
                 },
                 "domain": {
                     "description": "域名",
@@ -3729,6 +8843,32 @@ const docTemplate = `{
                     "type": "boolean",
                     "example": false
                 },
+                "errorPages": {
+                    "description": "自定义错误页，传入时整体替换，传入空数组表示清空",
+                    "type": "array",
+                    "maxItems": 4,
+                    "items": {
+                        "$ref": "#/definitions/dto.ErrorPageDTO"
+                    }
+                },
+                "httpsRedirect": {
+                    "description": "HTTP 请求重定向到 HTTPS 的方式，为空时保持不变",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "301",
+                        "308"
+                    ],
+                    "example": "308"
+                },
+                "limits": {
+                    "description": "超时、请求体大小和连接保护，传入时整体替换，传入空对象表示使用 HAProxy 默认配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.SiteLimitsDTO"
+                        }
+                    ]
+                },
                 "listenPort": {
                     "description": "监听端口",
                     "type": "integer",
@@ -3736,11 +8876,69 @@ const docTemplate = `{
                     "minimum": 1,
                     "example": 8080
                 },
+                "loginProtection": {
+                    "description": "登录保护配置，传入时整体替换",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.LoginProtectionDTO"
+                        }
+                    ]
+                },
+                "maintenance": {
+                    "description": "维护模式，传入时整体替换",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.MaintenanceDTO"
+                        }
+                    ]
+                },
                 "name": {
                     "description": "站点名称",
                     "type": "string",
                     "example": "my-site"
                 },
+                "requestHeaders": {
+                    "description": "请求头规则，传入时整体替换，传入空数组表示清空",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/dto.HeaderRuleDTO"
+                    }
+                },
+                "responseHeaders": {
+                    "description": "响应头规则，传入时整体替换，传入空数组表示清空",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/dto.HeaderRuleDTO"
+                    }
+                },
+                "routes": {
+                    "description": "路径路由，传入时整体替换，传入空数组表示清空",
+                    "type": "array",
+                    "maxItems": 50,
+                    "items": {
+                        "$ref": "#/definitions/dto.RouteDTO"
+                    }
+                },
+                "securityHeaders": {
+                    "description": "安全响应头预设，为空时保持不变，none 表示不添加",
+                    "type": "string",
+                    "enum": [
+                        "none",
+                        "basic",
+                        "strict"
+                    ],
+                    "example": "basic"
+                },
+                "tlsPolicy": {
+                    "description": "HTTPS 的 TLS 策略，传入时整体替换，传入空对象表示使用 HAProxy 默认配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/dto.TLSPolicyDTO"
+                        }
+                    ]
+                },
                 "wafEnabled": {
                     "description": "是否启用WAF",
                     "type": "boolean",
@@ -3757,1169 +8955,1342 @@ const docTemplate = `{
                 }
             }
         },
-        "dto.EngineDTO": {
+        "dto.UserCreateRequest": {
             "type": "object",
+            "required": [
+                "password",
+                "role",
+                "username"
+            ],
             "properties": {
-                "appConfig": {
-                    "description": "应用配置列表",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.AppConfigDTO"
-                    }
-                },
-                "asnDBPath": {
-                    "description": "ASN数据库路径",
-                    "type": "string"
-                },
-                "bind": {
-                    "description": "引擎绑定地址",
-                    "type": "string"
-                },
-                "cityDBPath": {
-                    "description": "城市数据库路径",
-                    "type": "string"
+                "password": {
+                    "type": "string",
+                    "minLength": 6
                 },
-                "flowController": {
-                    "description": "流量控制配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.FlowControllerDTO"
-                        }
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "user"
                     ]
                 },
-                "useBuiltinRules": {
-                    "description": "是否使用内置规则",
-                    "type": "boolean"
+                "username": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 3
                 }
             }
         },
-        "dto.EnginePatchDTO": {
+        "dto.UserLoginRequest": {
             "type": "object",
+            "required": [
+                "password",
+                "username"
+            ],
             "properties": {
-                "appConfig": {
-                    "description": "应用配置列表",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.AppConfigPatchDTO"
-                    }
-                },
-                "asnDBPath": {
-                    "description": "ASN数据库路径",
-                    "type": "string",
-                    "example": "/opt/geoip/GeoLite2-ASN.mmdb"
-                },
-                "bind": {
-                    "description": "引擎绑定地址",
-                    "type": "string",
-                    "example": "127.0.0.1:2342"
-                },
-                "cityDBPath": {
-                    "description": "城市数据库路径",
-                    "type": "string",
-                    "example": "/opt/geoip/GeoLite2-City.mmdb"
-                },
-                "flowController": {
-                    "description": "流量控制配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.FlowControllerPatchDTO"
-                        }
-                    ]
+                "password": {
+                    "type": "string"
                 },
-                "useBuiltinRules": {
-                    "description": "是否使用内置规则",
-                    "type": "boolean",
-                    "example": true
+                "username": {
+                    "type": "string"
                 }
             }
         },
-        "dto.FlowControllerDTO": {
+        "dto.UserPasswordResetRequest": {
             "type": "object",
+            "required": [
+                "newPassword",
+                "oldPassword"
+            ],
             "properties": {
-                "attackLimit": {
-                    "description": "攻击频率限制配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.LimitConfigDTO"
-                        }
-                    ]
-                },
-                "errorLimit": {
-                    "description": "错误频率限制配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.LimitConfigDTO"
-                        }
-                    ]
+                "newPassword": {
+                    "type": "string",
+                    "minLength": 6
                 },
-                "visitLimit": {
-                    "description": "访问频率限制配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.LimitConfigDTO"
-                        }
-                    ]
+                "oldPassword": {
+                    "type": "string"
                 }
             }
         },
-        "dto.FlowControllerPatchDTO": {
+        "dto.UserUpdateRequest": {
             "type": "object",
             "properties": {
-                "attackLimit": {
-                    "description": "攻击频率限制配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.LimitConfigPatchDTO"
-                        }
-                    ]
+                "needReset": {
+                    "type": "boolean"
                 },
-                "errorLimit": {
-                    "description": "错误频率限制配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.LimitConfigPatchDTO"
-                        }
-                    ]
+                "password": {
+                    "type": "string",
+                    "minLength": 6
                 },
-                "visitLimit": {
-                    "description": "访问频率限制配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.LimitConfigPatchDTO"
-                        }
+                "role": {
+                    "type": "string",
+                    "enum": [
+                        "admin",
+                        "auditor",
+                        "configurator",
+                        "user"
                     ]
+                },
+                "username": {
+                    "type": "string",
+                    "maxLength": 20,
+                    "minLength": 3
                 }
             }
         },
-        "dto.GetUserInfoResponseData": {
+        "dto.WeeklyWindowRequest": {
+            "description": "在指定星期的某个时段内生效，结束时间早于开始时间表示跨越午夜",
             "type": "object",
+            "required": [
+                "end",
+                "start"
+            ],
             "properties": {
-                "id": {
-                    "type": "string",
-                    "example": "1234567890"
-                },
-                "needReset": {
-                    "type": "boolean",
-                    "example": false
+                "days": {
+                    "description": "星期，0 表示周日，为空表示每天",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3,
+                        4,
+                        5
+                    ]
                 },
-                "role": {
+                "end": {
+                    "description": "结束时间 HH:MM，可为 24:00",
                     "type": "string",
-                    "example": "admin"
+                    "example": "18:00"
                 },
-                "username": {
+                "start": {
+                    "description": "开始时间 HH:MM",
                     "type": "string",
-                    "example": "user123"
+                    "example": "09:00"
                 }
             }
         },
-        "dto.HaproxyDTO": {
+        "model.ACMEChallengeType": {
+            "type": "string",
+            "enum": [
+                "http-01",
+                "dns-01"
+            ],
+            "x-enum-comments": {
+                "ACMEChallengeDNS01": "通过 DNS 服务商写入 TXT 记录，通配符域名只能使用该方式",
+                "ACMEChallengeHTTP01": "由 HAProxy 将验证请求转发到管理服务"
+            },
+            "x-enum-varnames": [
+                "ACMEChallengeHTTP01",
+                "ACMEChallengeDNS01"
+            ]
+        },
+        "model.ACMEStatus": {
             "type": "object",
             "properties": {
-                "backupsNumber": {
-                    "description": "备份数量",
-                    "type": "integer"
+                "challengeType": {
+                    "description": "域名验证方式",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ACMEChallengeType"
+                        }
+                    ]
                 },
-                "configBaseDir": {
-                    "description": "配置文件根目录",
+                "directoryURL": {
+                    "description": "签发证书的 ACME 服务目录地址",
                     "type": "string"
                 },
-                "haproxyBin": {
-                    "description": "HAProxy二进制文件路径",
+                "lastAttemptAt": {
+                    "description": "最近一次尝试续期的时间",
                     "type": "string"
                 },
-                "spoeAgentAddr": {
-                    "description": "SPOE代理地址",
+                "lastError": {
+                    "description": "最近一次续期失败的原因，成功后清空",
                     "type": "string"
                 },
-                "spoeAgentPort": {
-                    "description": "SPOE代理端口",
-                    "type": "integer"
-                },
-                "thread": {
-                    "description": "线程数",
-                    "type": "integer"
+                "renewedAt": {
+                    "description": "最近一次签发或续期成功的时间",
+                    "type": "string"
                 }
             }
         },
-        "dto.HaproxyPatchDTO": {
+        "model.APIResponse": {
+            "description": "API响应的标准格式",
             "type": "object",
             "properties": {
-                "backupsNumber": {
-                    "description": "备份数量",
+                "code": {
+                    "description": "HTTP状态码",
                     "type": "integer",
-                    "example": 5
+                    "example": 200
                 },
-                "configBaseDir": {
-                    "description": "配置文件根目录",
+                "data": {
+                    "description": "响应数据"
+                },
+                "error": {
+                    "description": "错误信息(当success=false时)",
                     "type": "string",
-                    "example": "/RuiQi"
+                    "example": "参数错误"
                 },
-                "haproxyBin": {
-                    "description": "HAProxy二进制文件路径",
+                "message": {
+                    "description": "响应消息",
                     "type": "string",
-                    "example": "haproxy"
+                    "example": "操作成功"
                 },
-                "spoeAgentAddr": {
-                    "description": "SPOE代理地址",
+                "requestId": {
+                    "description": "请求ID，用于跟踪",
                     "type": "string",
-                    "example": "127.0.0.1"
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
                 },
-                "spoeAgentPort": {
-                    "description": "SPOE代理端口",
-                    "type": "integer",
-                    "example": 2342
+                "success": {
+                    "description": "是否成功",
+                    "type": "boolean",
+                    "example": true
                 },
-                "thread": {
-                    "description": "线程数",
-                    "type": "integer",
-                    "maximum": 256,
-                    "minimum": 0,
-                    "example": 4
+                "timestamp": {
+                    "description": "响应时间戳",
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
                 }
             }
         },
-        "dto.IPGroupCreateRequest": {
-            "description": "创建IP组的请求参数",
+        "model.AuditLog": {
+            "description": "记录对配置资源的变更，包括用户操作和系统定时任务",
             "type": "object",
-            "required": [
-                "items",
-                "name"
-            ],
             "properties": {
-                "items": {
-                    "description": "IP地址或CIDR列表",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "[\"192.168.1.1\"]"
-                    ]
+                "action": {
+                    "description": "操作类型",
+                    "type": "string",
+                    "example": "expire"
                 },
-                "name": {
-                    "description": "IP组名称",
+                "createdAt": {
+                    "description": "操作时间",
+                    "type": "string"
+                },
+                "detail": {
+                    "description": "变更详情",
+                    "type": "object"
+                },
+                "id": {
+                    "type": "string",
+                    "example": "60d21b4367d0d8992e89e964"
+                },
+                "operator": {
+                    "description": "操作人，系统任务为 system",
+                    "type": "string",
+                    "example": "system"
+                },
+                "resourceId": {
+                    "description": "资源ID",
+                    "type": "string",
+                    "example": "60d21b4367d0d8992e89e964"
+                },
+                "resourceName": {
+                    "description": "资源名称",
                     "type": "string",
                     "example": "内部服务器"
+                },
+                "resourceType": {
+                    "description": "资源类型",
+                    "type": "string",
+                    "example": "ip_group"
                 }
             }
         },
-        "dto.IPGroupListResponse": {
-            "description": "IP组列表响应",
+        "model.Backend": {
             "type": "object",
             "properties": {
-                "items": {
-                    "description": "IP组列表",
+                "balance": {
+                    "description": "负载均衡算法，为空时使用 roundrobin",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.BalanceAlgorithm"
+                        }
+                    ]
+                },
+                "healthCheck": {
+                    "description": "HTTP 健康检查，为空时不检查",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.HealthCheck"
+                        }
+                    ]
+                },
+                "servers": {
+                    "description": "服务器列表",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.IPGroup"
+                        "$ref": "#/definitions/model.Server"
                     }
                 },
-                "total": {
-                    "description": "总数",
-                    "type": "integer"
+                "stickyCookie": {
+                    "description": "会话保持 Cookie 名称，为空时不保持会话",
+                    "type": "string"
                 }
             }
         },
-        "dto.IPGroupUpdateRequest": {
-            "description": "更新IP组的请求参数",
+        "model.BalanceAlgorithm": {
+            "type": "string",
+            "enum": [
+                "roundrobin",
+                "leastconn",
+                "source",
+                "uri",
+                "first",
+                "random"
+            ],
+            "x-enum-comments": {
+                "BalanceFirst": "优先使用第一个未满的服务器",
+                "BalanceLeastConn": "最少连接数",
+                "BalanceRandom": "按权重随机",
+                "BalanceRoundRobin": "按权重轮询",
+                "BalanceSource": "按来源IP哈希",
+                "BalanceURI": "按请求URI哈希"
+            },
+            "x-enum-varnames": [
+                "BalanceRoundRobin",
+                "BalanceLeastConn",
+                "BalanceSource",
+                "BalanceURI",
+                "BalanceFirst",
+                "BalanceRandom"
+            ]
+        },
+        "model.CertExpiryState": {
+            "type": "string",
+            "enum": [
+                "ok",
+                "expiring30",
+                "expiring14",
+                "expiring7",
+                "expired"
+            ],
+            "x-enum-comments": {
+                "CertExpired": "已过期",
+                "CertExpiringIn14Days": "14 天内过期",
+                "CertExpiringIn30Days": "30 天内过期",
+                "CertExpiringIn7Days": "7 天内过期",
+                "CertExpiryOK": "30 天以上过期"
+            },
+            "x-enum-varnames": [
+                "CertExpiryOK",
+                "CertExpiringIn30Days",
+                "CertExpiringIn14Days",
+                "CertExpiringIn7Days",
+                "CertExpired"
+            ]
+        },
+        "model.CertSource": {
+            "type": "string",
+            "enum": [
+                "manual",
+                "acme"
+            ],
+            "x-enum-comments": {
+                "CertSourceACME": "通过 ACME 自动签发和续期",
+                "CertSourceManual": "手动上传"
+            },
+            "x-enum-varnames": [
+                "CertSourceManual",
+                "CertSourceACME"
+            ]
+        },
+        "model.CertificateStore": {
             "type": "object",
             "properties": {
-                "items": {
-                    "description": "IP地址或CIDR列表",
-                    "type": "array",
-                    "items": {
-                        "type": "string"
-                    },
-                    "example": [
-                        "[\"192.168.1.1\"]"
+                "acme": {
+                    "description": "ACME 签发和续期状态，手动上传的证书为空",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ACMEStatus"
+                        }
                     ]
                 },
-                "name": {
-                    "description": "IP组名称",
-                    "type": "string",
-                    "example": "内部服务器"
-                }
-            }
-        },
-        "dto.LimitConfigDTO": {
-            "type": "object",
-            "properties": {
-                "blockDuration": {
-                    "description": "封禁时长（秒）",
-                    "type": "integer"
+                "createdAt": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "description": {
+                    "description": "证书描述",
+                    "type": "string"
+                },
+                "domains": {
+                    "description": "证书绑定的域名列表",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "burstCount": {
-                    "description": "允许的突发请求数",
-                    "type": "integer"
+                "expireDate": {
+                    "description": "证书过期日期",
+                    "type": "string"
                 },
-                "enabled": {
-                    "description": "是否启用",
-                    "type": "boolean"
+                "expiryState": {
+                    "description": "过期状态，查询证书时根据过期日期计算",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.CertExpiryState"
+                        }
+                    ]
                 },
-                "paramsCapacity": {
-                    "description": "缓存容量",
-                    "type": "integer"
+                "fingerPrint": {
+                    "description": "证书指纹",
+                    "type": "string"
                 },
-                "statDuration": {
-                    "description": "统计时间窗口（秒）",
-                    "type": "integer"
+                "id": {
+                    "description": "证书ID",
+                    "type": "string"
                 },
-                "threshold": {
-                    "description": "阈值",
-                    "type": "integer"
-                }
-            }
-        },
-        "dto.LimitConfigPatchDTO": {
-            "type": "object",
-            "properties": {
-                "blockDuration": {
-                    "description": "封禁时长（秒）",
-                    "type": "integer",
-                    "example": 600
+                "issuerName": {
+                    "description": "颁发机构",
+                    "type": "string"
                 },
-                "burstCount": {
-                    "description": "允许的突发请求数",
-                    "type": "integer",
-                    "example": 10
+                "name": {
+                    "description": "证书名称/别名",
+                    "type": "string"
                 },
-                "enabled": {
-                    "description": "是否启用",
-                    "type": "boolean",
-                    "example": true
+                "privateKey": {
+                    "description": "私钥内容（PEM格式）",
+                    "type": "string"
                 },
-                "paramsCapacity": {
-                    "description": "缓存容量",
-                    "type": "integer",
-                    "example": 10000
+                "publicKey": {
+                    "description": "公钥内容（PEM格式）",
+                    "type": "string"
                 },
-                "statDuration": {
-                    "description": "统计时间窗口（秒）",
-                    "type": "integer",
-                    "example": 60
+                "source": {
+                    "description": "证书来源，为空表示手动上传",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.CertSource"
+                        }
+                    ]
                 },
-                "threshold": {
-                    "description": "阈值",
-                    "type": "integer",
-                    "example": 100
+                "updatedAt": {
+                    "description": "更新时间",
+                    "type": "string"
                 }
             }
         },
-        "dto.LoginResponseData": {
+        "model.ClientAuth": {
             "type": "object",
             "properties": {
-                "token": {
-                    "description": "JWT token",
-                    "type": "string",
-                    "example": "eyJhbGciOiJIUzI1NiIs..."
+                "caCertificate": {
+                    "description": "签发客户端证书的 CA 证书（PEM格式），可以包含多个证书",
+                    "type": "string"
                 },
-                "user": {
-                    "description": "用户信息",
+                "mode": {
+                    "description": "认证方式",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/model.User"
+                            "$ref": "#/definitions/model.ClientAuthMode"
                         }
                     ]
+                },
+                "paths": {
+                    "description": "需要客户端证书的路径前缀，认证方式为 paths 时使用",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 }
             }
         },
-        "dto.MicroRuleCreateRequest": {
-            "description": "创建微规则的请求参数",
-            "type": "object",
-            "required": [
-                "condition",
-                "name",
-                "priority",
-                "status",
-                "type"
+        "model.ClientAuthMode": {
+            "type": "string",
+            "enum": [
+                "required",
+                "paths"
             ],
+            "x-enum-comments": {
+                "ClientAuthPaths": "只有指定路径的请求需要有效的客户端证书",
+                "ClientAuthRequired": "所有请求都需要有效的客户端证书"
+            },
+            "x-enum-varnames": [
+                "ClientAuthRequired",
+                "ClientAuthPaths"
+            ]
+        },
+        "model.ErrResponse": {
+            "description": "错误的API响应标准格式",
+            "type": "object",
             "properties": {
-                "condition": {
-                    "description": "规则条件",
-                    "type": "object"
+                "code": {
+                    "type": "integer",
+                    "example": 400
                 },
-                "name": {
-                    "description": "规则名称",
+                "error": {
                     "type": "string",
-                    "example": "SQL注入防护规则"
-                },
-                "priority": {
-                    "description": "优先级字段，数字越大优先级越高",
-                    "type": "integer",
-                    "example": 100
+                    "example": "参数错误"
                 },
-                "status": {
-                    "description": "规则状态",
+                "message": {
                     "type": "string",
-                    "enum": [
-                        "enabled",
-                        "disabled"
-                    ],
-                    "example": "enabled"
+                    "example": "请求参数错误"
                 },
-                "type": {
-                    "description": "规则类型",
+                "requestId": {
                     "type": "string",
-                    "enum": [
-                        "whitelist",
-                        "blacklist"
-                    ],
-                    "example": "blacklist"
-                }
-            }
-        },
-        "dto.MicroRuleListResponse": {
-            "description": "微规则列表响应",
-            "type": "object",
-            "properties": {
-                "items": {
-                    "description": "微规则列表",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/dto.MicroRuleResponse"
-                    }
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
                 },
-                "total": {
-                    "description": "总数",
-                    "type": "integer"
+                "success": {
+                    "type": "boolean",
+                    "example": false
+                },
+                "timestamp": {
+                    "type": "string",
+                    "example": "2023-01-01T12:00:00Z"
                 }
             }
         },
-        "dto.MicroRuleResponse": {
-            "description": "微规则响应参数",
+        "model.ErrResponseDontShowError": {
+            "description": "错误的API响应标准格式,不展示 error",
             "type": "object",
             "properties": {
-                "condition": {
-                    "description": "规则条件",
-                    "type": "object"
+                "code": {
+                    "type": "integer",
+                    "example": 400
                 },
-                "id": {
+                "message": {
                     "type": "string",
-                    "example": "60a763d0f03239868b50e810"
+                    "example": "请求参数错误"
                 },
-                "name": {
-                    "description": "规则名称",
+                "requestId": {
                     "type": "string",
-                    "example": "SQL注入防护规则"
-                },
-                "priority": {
-                    "description": "优先级字段，数字越大优先级越高",
-                    "type": "integer",
-                    "example": 100
+                    "example": "550e8400-e29b-41d4-a716-446655440000"
                 },
-                "status": {
-                    "description": "规则状态",
-                    "type": "string",
-                    "enum": [
-                        "enabled",
-                        "disabled"
-                    ],
-                    "example": "enabled"
+                "success": {
+                    "type": "boolean",
+                    "example": false
                 },
-                "type": {
-                    "description": "规则类型",
+                "timestamp": {
                     "type": "string",
-                    "enum": [
-                        "whitelist",
-                        "blacklist"
-                    ],
-                    "example": "blacklist"
+                    "example": "2023-01-01T12:00:00Z"
                 }
             }
         },
-        "dto.MicroRuleUpdateRequest": {
-            "description": "更新微规则的请求参数",
+        "model.ErrorPage": {
             "type": "object",
             "properties": {
-                "condition": {
-                    "description": "规则条件",
-                    "type": "object"
-                },
-                "name": {
-                    "description": "规则名称",
-                    "type": "string",
-                    "example": "SQL注入防护规则"
-                },
-                "priority": {
-                    "description": "优先级字段，数字越大优先级越高",
-                    "type": "integer",
-                    "example": 100
+                "content": {
+                    "description": "错误页 HTML",
+                    "type": "string"
                 },
                 "status": {
-                    "description": "规则状态",
-                    "type": "string",
-                    "enum": [
-                        "enabled",
-                        "disabled"
-                    ],
-                    "example": "enabled"
-                },
-                "type": {
-                    "description": "规则类型",
-                    "type": "string",
-                    "enum": [
-                        "whitelist",
-                        "blacklist"
-                    ],
-                    "example": "blacklist"
+                    "description": "状态码",
+                    "type": "integer"
+                }
+            }
+        },
+        "model.HSTSPolicy": {
+            "type": "object",
+            "properties": {
+                "includeSubDomains": {
+                    "description": "是否包含子域名",
+                    "type": "boolean"
+                },
+                "maxAge": {
+                    "description": "有效期（秒）",
+                    "type": "integer"
+                },
+                "preload": {
+                    "description": "是否申请加入浏览器预加载列表",
+                    "type": "boolean"
                 }
             }
         },
-        "dto.OverviewStats": {
-            "description": "概览统计数据，包含各项关键指标",
+        "model.HTTPSRedirect": {
+            "type": "string",
+            "enum": [
+                "none",
+                "301",
+                "308"
+            ],
+            "x-enum-comments": {
+                "HTTPSRedirect301": "301 永久重定向，部分客户端会将 POST 请求改为 GET",
+                "HTTPSRedirect308": "308 永久重定向，保持请求方法和请求体",
+                "HTTPSRedirectNone": "不重定向"
+            },
+            "x-enum-varnames": [
+                "HTTPSRedirectNone",
+                "HTTPSRedirect301",
+                "HTTPSRedirect308"
+            ]
+        },
+        "model.HeaderAction": {
+            "type": "string",
+            "enum": [
+                "set",
+                "add",
+                "delete",
+                "replace"
+            ],
+            "x-enum-comments": {
+                "HeaderActionAdd": "添加头部，保留已有的同名头部",
+                "HeaderActionDelete": "删除所有同名头部",
+                "HeaderActionReplace": "使用正则表达式替换头部的值",
+                "HeaderActionSet": "设置头部，已有的同名头部全部被替换"
+            },
+            "x-enum-varnames": [
+                "HeaderActionSet",
+                "HeaderActionAdd",
+                "HeaderActionDelete",
+                "HeaderActionReplace"
+            ]
+        },
+        "model.HeaderRule": {
             "type": "object",
             "properties": {
-                "attackIPCount": {
-                    "description": "攻击IP数量",
-                    "type": "integer",
-                    "example": 45
-                },
-                "blockCount": {
-                    "description": "安全统计",
-                    "type": "integer",
-                    "example": 123
-                },
-                "error4xx": {
-                    "description": "错误统计",
-                    "type": "integer",
-                    "example": 234
-                },
-                "error4xxRate": {
-                    "description": "4xx错误率(百分比)",
-                    "type": "number",
-                    "example": 0.23
-                },
-                "error5xx": {
-                    "description": "5xx错误数量",
-                    "type": "integer",
-                    "example": 45
-                },
-                "error5xxRate": {
-                    "description": "5xx错误率(百分比)",
-                    "type": "number",
-                    "example": 0.05
-                },
-                "inboundTraffic": {
-                    "description": "入站流量(字节)",
-                    "type": "integer",
-                    "example": 67890123
+                "action": {
+                    "description": "操作",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.HeaderAction"
+                        }
+                    ]
                 },
-                "maxQPS": {
-                    "description": "最大QPS",
-                    "type": "integer",
-                    "example": 150
+                "name": {
+                    "description": "头部名称，不区分大小写",
+                    "type": "string"
                 },
-                "outboundTraffic": {
-                    "description": "出站流量(字节)",
-                    "type": "integer",
-                    "example": 12345678
+                "pathPrefix": {
+                    "description": "只对路径以该前缀开头的请求生效，为空时对所有请求生效",
+                    "type": "string"
                 },
-                "timeRange": {
-                    "description": "时间范围",
-                    "type": "string",
-                    "example": "24h"
+                "pattern": {
+                    "description": "replace 时匹配头部值的正则表达式",
+                    "type": "string"
                 },
-                "totalRequests": {
-                    "description": "流量统计",
-                    "type": "integer",
-                    "example": 123456
+                "value": {
+                    "description": "set、add 时为头部的值，replace 时为替换内容；支持 HAProxy 日志格式，如 %[uuid()]",
+                    "type": "string"
                 }
             }
         },
-        "dto.RealtimeQPSData": {
-            "description": "实时QPS数据点，包含时间戳和值",
+        "model.HealthCheck": {
             "type": "object",
             "properties": {
-                "timestamp": {
-                    "description": "时间戳",
-                    "type": "string",
-                    "example": "2024-01-01T12:30:45Z"
+                "expectStatus": {
+                    "description": "期望的响应状态码，如 200 或 200-399",
+                    "type": "string"
                 },
-                "value": {
-                    "description": "QPS值",
-                    "type": "integer",
-                    "example": 120
+                "fall": {
+                    "description": "连续失败多少次后标记为 DOWN",
+                    "type": "integer"
+                },
+                "interval": {
+                    "description": "检查间隔（毫秒）",
+                    "type": "integer"
+                },
+                "path": {
+                    "description": "检查路径，如 /healthz",
+                    "type": "string"
+                },
+                "rise": {
+                    "description": "连续成功多少次后标记为 UP",
+                    "type": "integer"
                 }
             }
         },
-        "dto.RealtimeQPSResponse": {
-            "description": "实时QPS数据响应",
+        "model.HostHeaderMode": {
+            "type": "string",
+            "enum": [
+                "preserve",
+                "server",
+                "custom"
+            ],
+            "x-enum-comments": {
+                "HostHeaderCustom": "改写为自定义值",
+                "HostHeaderPreserve": "保留客户端请求的 Host 头",
+                "HostHeaderServer": "改写为服务器的主机地址"
+            },
+            "x-enum-varnames": [
+                "HostHeaderPreserve",
+                "HostHeaderServer",
+                "HostHeaderCustom"
+            ]
+        },
+        "model.IPGroup": {
+            "description": "IP地址组信息，包含组名和IP地址列表",
             "type": "object",
             "properties": {
-                "data": {
-                    "description": "QPS数据点列表",
+                "edgeBlock": {
+                    "description": "是否在 HAProxy 前端直接拒绝组中IP的请求，不再经过 WAF 检测",
+                    "type": "boolean",
+                    "example": false
+                },
+                "expirations": {
+                    "description": "条目过期时间，未列出的条目永久有效",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.RealtimeQPSData"
+                        "$ref": "#/definitions/model.IPItemExpiration"
                     }
+                },
+                "id": {
+                    "description": "组唯一标识符",
+                    "type": "string",
+                    "example": "60d21b4367d0d8992e89e964"
+                },
+                "items": {
+                    "description": "IP地址或CIDR列表",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "['192.168.1.1'",
+                        " '10.0.0.1/24']"
+                    ]
+                },
+                "name": {
+                    "description": "组名称",
+                    "type": "string",
+                    "example": "内部服务器"
+                },
+                "scope": {
+                    "description": "站点作用域，为空表示对所有站点生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SiteScope"
+                        }
+                    ]
                 }
             }
         },
-        "dto.ResetPasswordResponseData": {
+        "model.IPInfo": {
+            "description": "IP地址地理位置详细信息，包含城市、区域、国家和ASN等数据",
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 200
+                "asn": {
+                    "type": "object",
+                    "properties": {
+                        "number": {
+                            "description": "ASN号码",
+                            "type": "integer",
+                            "example": 4134
+                        },
+                        "organization": {
+                            "description": "组织名称",
+                            "type": "string",
+                            "example": "China Telecom"
+                        }
+                    }
                 },
-                "data": {
-                    "$ref": "#/definitions/model.User"
+                "city": {
+                    "type": "object",
+                    "properties": {
+                        "nameEn": {
+                            "description": "城市英文名称",
+                            "type": "string",
+                            "example": "Hangzhou"
+                        },
+                        "nameZh": {
+                            "description": "城市中文名称",
+                            "type": "string",
+                            "example": "杭州"
+                        }
+                    }
                 },
-                "message": {
-                    "type": "string",
-                    "example": "操作成功"
+                "continent": {
+                    "type": "object",
+                    "properties": {
+                        "nameEn": {
+                            "description": "大洲英文名称",
+                            "type": "string",
+                            "example": "Asia"
+                        },
+                        "nameZh": {
+                            "description": "大洲中文名称",
+                            "type": "string",
+                            "example": "亚洲"
+                        }
+                    }
                 },
-                "requestId": {
-                    "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                "country": {
+                    "type": "object",
+                    "properties": {
+                        "isoCode": {
+                            "description": "国家ISO代码",
+                            "type": "string",
+                            "example": "CN"
+                        },
+                        "nameEn": {
+                            "description": "国家英文名称",
+                            "type": "string",
+                            "example": "China"
+                        },
+                        "nameZh": {
+                            "description": "国家中文名称",
+                            "type": "string",
+                            "example": "中国"
+                        }
+                    }
                 },
-                "success": {
-                    "type": "boolean",
-                    "example": true
+                "location": {
+                    "type": "object",
+                    "properties": {
+                        "latitude": {
+                            "description": "纬度",
+                            "type": "number",
+                            "example": 30.2943
+                        },
+                        "longitude": {
+                            "description": "经度",
+                            "type": "number",
+                            "example": 120.1663
+                        },
+                        "timeZone": {
+                            "description": "时区",
+                            "type": "string",
+                            "example": "Asia/Shanghai"
+                        }
+                    }
                 },
-                "timestamp": {
-                    "type": "string",
-                    "example": "2023-01-01T12:00:00Z"
+                "subdivision": {
+                    "type": "object",
+                    "properties": {
+                        "isoCode": {
+                            "description": "省/州代码",
+                            "type": "string",
+                            "example": "ZJ"
+                        },
+                        "nameEn": {
+                            "description": "省/州英文名称",
+                            "type": "string",
+                            "example": "Zhejiang"
+                        },
+                        "nameZh": {
+                            "description": "省/州中文名称",
+                            "type": "string",
+                            "example": "浙江"
+                        }
+                    }
                 }
             }
         },
-        "dto.RunnerControlRequest": {
+        "model.IPItemExpiration": {
+            "description": "IP组中单个条目的过期时间，过期后不再参与匹配，并由定时任务从组中移除",
             "type": "object",
-            "required": [
-                "action"
-            ],
             "properties": {
-                "action": {
-                    "description": "控制动作",
+                "expiresAt": {
+                    "description": "过期时间",
+                    "type": "string"
+                },
+                "item": {
+                    "description": "IP地址或CIDR，必须是组中的条目",
                     "type": "string",
-                    "enum": [
-                        "start",
-                        "stop",
-                        "restart",
-                        "force_stop",
-                        "reload"
-                    ]
+                    "example": "192.168.1.1"
                 }
             }
         },
-        "dto.RunnerControlResponse": {
+        "model.Log": {
+            "description": "详细的WAF规则匹配记录，包含规则触发的详细信息和原始日志",
             "type": "object",
             "properties": {
-                "action": {
-                    "description": "执行的动作",
+                "accuracy": {
+                    "description": "规则匹配准确度(0-10)",
+                    "type": "integer",
+                    "example": 9
+                },
+                "logRaw": {
+                    "description": "原始日志数据",
                     "type": "string",
-                    "example": "start"
+                    "example": "[2024-03-18 08:12:33] [error] ModSecurity: Access denied with code 403 (phase 1). Matched \"Operator 'Rx' with parameter '(?:scanner)' against variable 'REQUEST_HEADERS:User-Agent'\" [id \"10086\"] [msg \"恶意扫描器检测\"] [severity \"CRITICAL\"]"
                 },
                 "message": {
-                    "description": "操作消息",
+                    "description": "日志消息",
                     "type": "string",
-                    "example": "运行器已成功启动"
+                    "example": "恶意扫描器检测"
                 },
-                "state": {
-                    "description": "操作后的状态",
+                "payload": {
+                    "description": "攻击载荷",
                     "type": "string",
-                    "example": "running"
+                    "example": "Scanner/1.0"
                 },
-                "success": {
-                    "description": "操作是否成功",
-                    "type": "boolean",
-                    "example": true
-                }
-            }
-        },
-        "dto.RunnerStatusResponse": {
-            "type": "object",
-            "properties": {
-                "isRunning": {
-                    "description": "是否正在运行",
-                    "type": "boolean",
-                    "example": true
+                "phase": {
+                    "description": "请求处理阶段",
+                    "type": "integer",
+                    "example": 1
                 },
-                "state": {
-                    "description": "状态：running, stopped, error",
-                    "type": "string",
-                    "example": "running"
-                }
-            }
-        },
-        "dto.ServerDTO": {
-            "type": "object",
-            "required": [
-                "host",
-                "port"
-            ],
-            "properties": {
-                "host": {
-                    "description": "主机地址",
+                "ruleId": {
+                    "description": "规则ID",
+                    "type": "integer",
+                    "example": 10086
+                },
+                "secLangRaw": {
+                    "description": "安全规则原始定义",
                     "type": "string",
-                    "example": "backend.example.com"
+                    "example": "SecRule REQUEST_HEADERS:User-Agent \"@rx (?:scanner)\" \"id:1008,phase:1,severity:'CRITICAL'\""
                 },
-                "isSSL": {
-                    "description": "是否启用SSL",
-                    "type": "boolean",
-                    "example": false
+                "secMark": {
+                    "description": "安全标记",
+                    "type": "string",
+                    "example": "web_scanner"
                 },
-                "port": {
-                    "description": "端口",
+                "severity": {
+                    "description": "严重级别(0-5)",
                     "type": "integer",
-                    "maximum": 65535,
-                    "minimum": 1,
-                    "example": 80
-                }
-            }
-        },
-        "dto.SiteListResponse": {
-            "description": "站点列表响应",
-            "type": "object",
-            "properties": {
-                "items": {
-                    "description": "站点列表",
-                    "type": "array",
-                    "items": {
-                        "$ref": "#/definitions/model.Site"
-                    }
-                },
-                "total": {
-                    "description": "总数",
-                    "type": "integer"
+                    "example": 2
                 }
             }
         },
-        "dto.SiteResponse": {
-            "description": "站点信息响应",
+        "model.LoginProtection": {
+            "description": "按用户名、来源IP和来源ASN统计登录失败次数，任一维度在滑动窗口内超过阈值时对后续登录请求执行处置动作；需要开启响应检测",
             "type": "object",
             "properties": {
-                "activeStatus": {
-                    "description": "站点是否激活",
-                    "type": "boolean"
-                },
-                "backend": {
-                    "description": "后端服务器配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.Backend"
-                        }
-                    ]
-                },
-                "certificate": {
-                    "description": "证书信息",
+                "action": {
+                    "description": "超过阈值后的处置动作",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/model.Certificate"
+                            "$ref": "#/definitions/model.LoginProtectionAction"
                         }
-                    ]
+                    ],
+                    "example": "challenge"
                 },
-                "createdAt": {
-                    "type": "string"
+                "asnThreshold": {
+                    "description": "单个来源ASN的失败次数阈值，0 表示不统计，需要配置 ASN 数据库",
+                    "type": "integer",
+                    "example": 200
                 },
-                "domain": {
-                    "description": "域名，如 a.com",
-                    "type": "string"
+                "blockDuration": {
+                    "description": "block 动作的封禁时长（秒）",
+                    "type": "integer",
+                    "example": 3600
                 },
-                "enableHTTPS": {
-                    "description": "是否启用HTTPS",
-                    "type": "boolean"
+                "enabled": {
+                    "description": "是否启用",
+                    "type": "boolean",
+                    "example": true
                 },
-                "id": {
-                    "description": "站点ID",
-                    "type": "string"
+                "failurePattern": {
+                    "description": "表示登录失败的响应内容正则表达式",
+                    "type": "string",
+                    "example": "(?i)密码错误"
                 },
-                "listenPort": {
-                    "description": "监听端口，如 9000",
-                    "type": "integer"
+                "failureStatus": {
+                    "description": "表示登录失败的响应状态码",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        401,
+                        403
+                    ]
                 },
-                "name": {
-                    "description": "站点名称",
-                    "type": "string"
+                "ipThreshold": {
+                    "description": "单个来源IP的失败次数阈值，0 表示不统计",
+                    "type": "integer",
+                    "example": 20
                 },
-                "updatedAt": {
-                    "type": "string"
+                "method": {
+                    "description": "登录接口请求方法",
+                    "type": "string",
+                    "example": "POST"
                 },
-                "wafEnabled": {
-                    "description": "是否启用WAF",
-                    "type": "boolean"
+                "path": {
+                    "description": "登录接口路径，完全匹配",
+                    "type": "string",
+                    "example": "/api/login"
                 },
-                "wafMode": {
-                    "description": "WAF防护模式",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.WAFMode"
-                        }
-                    ]
-                }
-            }
-        },
-        "dto.TimeSeriesDataPoint": {
-            "description": "时间序列图表数据点",
-            "type": "object",
-            "properties": {
-                "timestamp": {
-                    "description": "时间戳，表示数据点的时间",
+                "usernameField": {
+                    "description": "用户名字段，表单字段名或 JSON 键，JSON 支持 user.name 形式的嵌套路径",
                     "type": "string",
-                    "example": "2024-01-01T12:00:00Z"
+                    "example": "username"
                 },
-                "value": {
-                    "description": "数值，表示该时间点的指标值",
+                "usernameThreshold": {
+                    "description": "单个用户名的失败次数阈值，0 表示不统计",
                     "type": "integer",
-                    "example": 128
+                    "example": 5
+                },
+                "window": {
+                    "description": "滑动统计窗口（秒）",
+                    "type": "integer",
+                    "example": 600
                 }
             }
         },
-        "dto.TimeSeriesResponse": {
-            "description": "时间序列图表数据响应",
+        "model.LoginProtectionAction": {
+            "type": "string",
+            "enum": [
+                "challenge",
+                "throttle",
+                "block"
+            ],
+            "x-enum-comments": {
+                "LoginActionBlock": "封禁来源IP",
+                "LoginActionChallenge": "返回 JS 挑战页，通过挑战后才能继续提交登录",
+                "LoginActionThrottle": "返回 429，窗口内失败次数回落后自动恢复"
+            },
+            "x-enum-varnames": [
+                "LoginActionChallenge",
+                "LoginActionThrottle",
+                "LoginActionBlock"
+            ]
+        },
+        "model.Maintenance": {
             "type": "object",
             "properties": {
-                "data": {
-                    "description": "数据点列表",
+                "allowedIPs": {
+                    "description": "不受维护模式影响的客户端 IP 或 CIDR",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.TimeSeriesDataPoint"
+                        "type": "string"
                     }
                 },
-                "metric": {
-                    "description": "指标名称",
-                    "type": "string",
-                    "example": "requests"
+                "enabled": {
+                    "description": "是否启用",
+                    "type": "boolean"
                 },
-                "timeRange": {
-                    "description": "时间范围",
-                    "type": "string",
-                    "example": "24h"
+                "page": {
+                    "description": "维护页 HTML，为空时使用 503 错误页",
+                    "type": "string"
                 }
             }
         },
-        "dto.TrafficDataPoint": {
-            "description": "流量时间序列图表数据点",
+        "model.Route": {
             "type": "object",
             "properties": {
-                "inboundTraffic": {
-                    "description": "入站流量(字节)",
-                    "type": "integer",
-                    "example": 1024000
-                },
-                "outboundTraffic": {
-                    "description": "出站流量(字节)",
-                    "type": "integer",
-                    "example": 2048000
+                "backend": {
+                    "description": "该路由的后端服务器配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Backend"
+                        }
+                    ]
                 },
-                "timestamp": {
-                    "description": "时间戳，表示数据点的时间",
-                    "type": "string",
-                    "example": "2024-01-01T12:00:00Z"
+                "pathPrefix": {
+                    "description": "路径前缀，如 /api/",
+                    "type": "string"
                 }
             }
         },
-        "dto.TrafficTimeSeriesResponse": {
-            "description": "流量时间序列图表数据响应",
+        "model.RuleSchedule": {
+            "description": "规则的生效时间范围和每周重复的生效时段，未设置的部分不做限制",
             "type": "object",
             "properties": {
-                "data": {
-                    "description": "流量数据点列表",
+                "activeFrom": {
+                    "description": "生效开始时间",
+                    "type": "string"
+                },
+                "activeUntil": {
+                    "description": "生效结束时间，过期后由定时任务清理",
+                    "type": "string"
+                },
+                "timezone": {
+                    "description": "每周时段使用的时区，默认 UTC",
+                    "type": "string",
+                    "example": "Asia/Shanghai"
+                },
+                "windows": {
+                    "description": "每周生效时段，为空表示不限时段",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/dto.TrafficDataPoint"
+                        "$ref": "#/definitions/model.WeeklyWindow"
                     }
-                },
-                "timeRange": {
-                    "description": "时间范围",
-                    "type": "string",
-                    "example": "24h"
                 }
             }
         },
-        "dto.UpdateSiteRequest": {
-            "description": "更新站点的请求参数",
+        "model.SecurityHeaderPreset": {
+            "type": "string",
+            "enum": [
+                "none",
+                "basic",
+                "strict"
+            ],
+            "x-enum-comments": {
+                "SecurityHeadersBasic": "兼容大多数站点的基础安全响应头",
+                "SecurityHeadersNone": "不添加安全响应头",
+                "SecurityHeadersStrict": "禁止跨站嵌入和加载外部资源的严格安全响应头"
+            },
+            "x-enum-varnames": [
+                "SecurityHeadersNone",
+                "SecurityHeadersBasic",
+                "SecurityHeadersStrict"
+            ]
+        },
+        "model.Server": {
             "type": "object",
             "properties": {
-                "activeStatus": {
-                    "description": "站点状态",
-                    "type": "boolean",
-                    "example": true
+                "backup": {
+                    "description": "是否为备用服务器，仅在所有主服务器 DOWN 时使用",
+                    "type": "boolean"
                 },
-                "backend": {
-                    "description": "后端服务器配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/dto.BackendDTO"
-                        }
-                    ]
+                "host": {
+                    "description": "主机地址，如 IP 或域名",
+                    "type": "string"
                 },
-                "certificate": {
-                    "description": "证书信息",
+                "hostHeader": {
+                    "description": "转发到该服务器时 Host 头的处理方式，为空时 k8s 环境改写为服务器地址，其他环境保留",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/dto.CertificateDTO"
+                            "$ref": "#/definitions/model.HostHeaderMode"
                         }
                     ]
                 },
-                "domain": {
-                    "description": "域名",
-                    "type": "string",
-                    "example": "example.com"
+                "hostHeaderValue": {
+                    "description": "Host 头处理方式为 custom 时使用的 Host 头",
+                    "type": "string"
                 },
-                "enableHTTPS": {
-                    "description": "是否启用HTTPS",
-                    "type": "boolean",
-                    "example": false
+                "isSSL": {
+                    "description": "是否启用SSL",
+                    "type": "boolean"
                 },
-                "listenPort": {
-                    "description": "监听端口",
-                    "type": "integer",
-                    "maximum": 65535,
-                    "minimum": 1,
-                    "example": 8080
+                "maxConn": {
+                    "description": "最大并发连接数，为 0 时不限制",
+                    "type": "integer"
                 },
                 "name": {
-                    "description": "站点名称",
-                    "type": "string",
-                    "example": "my-site"
+                    "description": "HAProxy 中的服务器名称，为空时按序号生成",
+                    "type": "string"
                 },
-                "wafEnabled": {
-                    "description": "是否启用WAF",
-                    "type": "boolean",
-                    "example": false
+                "port": {
+                    "description": "端口",
+                    "type": "integer"
                 },
-                "wafMode": {
-                    "description": "WAF模式",
-                    "type": "string",
-                    "enum": [
-                        "protection",
-                        "observation"
-                    ],
-                    "example": "observation"
+                "state": {
+                    "description": "管理状态，为空表示 ready",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ServerState"
+                        }
+                    ]
+                },
+                "weight": {
+                    "description": "权重，为 0 时使用 HAProxy 默认值 1",
+                    "type": "integer"
                 }
             }
         },
-        "dto.UserCreateRequest": {
-            "type": "object",
-            "required": [
-                "password",
-                "role",
-                "username"
+        "model.ServerState": {
+            "type": "string",
+            "enum": [
+                "ready",
+                "drain",
+                "maint"
             ],
+            "x-enum-comments": {
+                "ServerStateDrain": "不再接收新连接，已有会话继续处理，用于发布前摘流",
+                "ServerStateMaint": "维护状态，不接收任何流量，也不进行健康检查",
+                "ServerStateReady": "正常接收流量"
+            },
+            "x-enum-varnames": [
+                "ServerStateReady",
+                "ServerStateDrain",
+                "ServerStateMaint"
+            ]
+        },
+        "model.Site": {
+            "type": "object",
             "properties": {
-                "password": {
-                    "type": "string",
-                    "minLength": 6
+                "activeStatus": {
+                    "description": "站点是否激活",
+                    "type": "boolean"
                 },
-                "role": {
-                    "type": "string",
-                    "enum": [
-                        "admin",
-                        "user"
+                "aliases": {
+                    "description": "其他域名，支持 *.example.com 形式的通配符域名",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "backend": {
+                    "description": "后端服务器配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Backend"
+                        }
                     ]
                 },
-                "username": {
-                    "type": "string",
-                    "maxLength": 20,
-                    "minLength": 3
-                }
-            }
-        },
-        "dto.UserLoginRequest": {
-            "type": "object",
-            "required": [
-                "password",
-                "username"
-            ],
-            "properties": {
-                "password": {
+                "certificateId": {
+                    "description": "证书库中的证书ID，为空时按域名从证书库自动选择",
                     "type": "string"
                 },
-                "username": {
+                "createdAt": {
                     "type": "string"
-                }
-            }
-        },
-        "dto.UserPasswordResetRequest": {
-            "type": "object",
-            "required": [
-                "newPassword",
-                "oldPassword"
-            ],
-            "properties": {
-                "newPassword": {
-                    "type": "string",
-                    "minLength": 6
                 },
-                "oldPassword": {
+                "domain": {
+                    "description": "域名，如 a.com",
                     "type": "string"
-                }
-            }
-        },
-        "dto.UserUpdateRequest": {
-            "type": "object",
-            "properties": {
-                "needReset": {
+                },
+                "enableHTTPS": {
+                    "description": "是否启用HTTPS",
                     "type": "boolean"
                 },
-                "password": {
-                    "type": "string",
-                    "minLength": 6
+                "errorPages": {
+                    "description": "自定义错误页，替换 HAProxy 默认的错误响应",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.ErrorPage"
+                    }
                 },
-                "role": {
-                    "type": "string",
-                    "enum": [
-                        "admin",
-                        "auditor",
-                        "configurator",
-                        "user"
+                "httpsRedirect": {
+                    "description": "HTTP 请求重定向到 HTTPS 的方式，只在启用 HTTPS 时生效，为空时使用 301",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.HTTPSRedirect"
+                        }
                     ]
                 },
-                "username": {
-                    "type": "string",
-                    "maxLength": 20,
-                    "minLength": 3
-                }
-            }
-        },
-        "model.APIResponse": {
-            "description": "API响应的标准格式",
-            "type": "object",
-            "properties": {
-                "code": {
-                    "description": "HTTP状态码",
-                    "type": "integer",
-                    "example": 200
+                "id": {
+                    "description": "站点ID",
+                    "type": "string"
                 },
-                "data": {
-                    "description": "响应数据"
+                "limits": {
+                    "description": "超时、请求体大小和连接保护，为空时使用 HAProxy 默认配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SiteLimits"
+                        }
+                    ]
                 },
-                "error": {
-                    "description": "错误信息(当success=false时)",
-                    "type": "string",
-                    "example": "参数错误"
+                "listenPort": {
+                    "description": "监听端口，如 9000",
+                    "type": "integer"
                 },
-                "message": {
-                    "description": "响应消息",
-                    "type": "string",
-                    "example": "操作成功"
+                "loginProtection": {
+                    "description": "登录保护配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.LoginProtection"
+                        }
+                    ]
                 },
-                "requestId": {
-                    "description": "请求ID，用于跟踪",
-                    "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                "maintenance": {
+                    "description": "维护模式，为空时不启用",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.Maintenance"
+                        }
+                    ]
                 },
-                "success": {
-                    "description": "是否成功",
-                    "type": "boolean",
-                    "example": true
+                "name": {
+                    "description": "站点名称",
+                    "type": "string"
                 },
-                "timestamp": {
-                    "description": "响应时间戳",
-                    "type": "string",
-                    "example": "2023-01-01T12:00:00Z"
-                }
-            }
-        },
-        "model.Backend": {
-            "type": "object",
-            "properties": {
-                "servers": {
-                    "description": "服务器列表",
+                "requestHeaders": {
+                    "description": "转发到后端前按顺序执行的请求头规则",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.HeaderRule"
+                    }
+                },
+                "responseHeaders": {
+                    "description": "返回客户端前按顺序执行的响应头规则，在安全响应头预设之后执行",
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/model.HeaderRule"
+                    }
+                },
+                "routes": {
+                    "description": "路径路由，按顺序匹配，未命中时使用默认后端",
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/model.Server"
+                        "$ref": "#/definitions/model.Route"
                     }
+                },
+                "securityHeaders": {
+                    "description": "安全响应头预设，为空时不添加",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SecurityHeaderPreset"
+                        }
+                    ]
+                },
+                "tlsPolicy": {
+                    "description": "HTTPS 的 TLS 策略，为空时使用 HAProxy 默认配置",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TLSPolicy"
+                        }
+                    ]
+                },
+                "updatedAt": {
+                    "type": "string"
+                },
+                "wafEnabled": {
+                    "description": "是否启用WAF",
+                    "type": "boolean"
+                },
+                "wafMode": {
+                    "description": "WAF防护模式",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.WAFMode"
+                        }
+                    ]
                 }
             }
         },
-        "model.Certificate": {
+        "model.SiteLimits": {
             "type": "object",
             "properties": {
-                "certName": {
-                    "description": "证书名称/别名",
-                    "type": "string"
+                "connRate": {
+                    "description": "单个客户端 IP 每 10 秒最多新建的连接数，超过时直接断开",
+                    "type": "integer"
                 },
-                "expireDate": {
-                    "description": "证书过期日期",
-                    "type": "string"
+                "connectTimeout": {
+                    "description": "连接后端服务器的超时（毫秒）",
+                    "type": "integer"
                 },
-                "fingerPrint": {
-                    "description": "证书指纹",
-                    "type": "string"
+                "httpRequestTimeout": {
+                    "description": "接收完整请求头的超时（毫秒），防御慢速请求攻击",
+                    "type": "integer"
                 },
-                "issuerName": {
-                    "description": "颁发机构",
-                    "type": "string"
+                "maxBodySize": {
+                    "description": "请求体最大字节数，按 Content-Length 判断，超过时返回 413",
+                    "type": "integer"
                 },
-                "privateKey": {
-                    "description": "私钥内容（PEM格式）",
-                    "type": "string"
+                "maxConn": {
+                    "description": "监听端口的最大并发连接数",
+                    "type": "integer"
                 },
-                "publicKey": {
-                    "description": "公钥内容（PEM格式）",
-                    "type": "string"
+                "requestRate": {
+                    "description": "单个客户端 IP 每 10 秒最多发送到站点的请求数，超过时直接断开",
+                    "type": "integer"
+                },
+                "serverTimeout": {
+                    "description": "等待后端服务器响应的超时（毫秒）",
+                    "type": "integer"
                 }
             }
         },
-        "model.CertificateStore": {
+        "model.SiteScope": {
+            "description": "规则或IP组的生效范围，站点ID或主机名模式任一命中即生效，两者均为空表示对所有站点生效",
             "type": "object",
             "properties": {
-                "createdAt": {
-                    "description": "创建时间",
-                    "type": "string"
-                },
-                "description": {
-                    "description": "证书描述",
-                    "type": "string"
-                },
-                "domains": {
-                    "description": "证书绑定的域名列表",
+                "hosts": {
+                    "description": "主机名模式列表，支持 *.example.com 形式的通配符",
                     "type": "array",
                     "items": {
                         "type": "string"
-                    }
-                },
-                "expireDate": {
-                    "description": "证书过期日期",
-                    "type": "string"
-                },
-                "fingerPrint": {
-                    "description": "证书指纹",
-                    "type": "string"
-                },
-                "id": {
-                    "description": "证书ID",
-                    "type": "string"
-                },
-                "issuerName": {
-                    "description": "颁发机构",
-                    "type": "string"
-                },
-                "name": {
-                    "description": "证书名称/别名",
-                    "type": "string"
-                },
-                "privateKey": {
-                    "description": "私钥内容（PEM格式）",
-                    "type": "string"
-                },
-                "publicKey": {
-                    "description": "公钥内容（PEM格式）",
-                    "type": "string"
+                    },
+                    "example": [
+                        "*.example.com"
+                    ]
                 },
-                "updatedAt": {
-                    "description": "更新时间",
-                    "type": "string"
+                "siteIds": {
+                    "description": "站点ID列表",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    },
+                    "example": [
+                        "60d21b4367d0d8992e89e964"
+                    ]
                 }
             }
         },
-        "model.ErrResponse": {
-            "description": "错误的API响应标准格式",
+        "model.SuccessResponse": {
+            "description": "成功的API响应标准格式",
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer",
-                    "example": 400
-                },
-                "error": {
-                    "type": "string",
-                    "example": "参数错误"
+                    "example": 200
                 },
+                "data": {},
                 "message": {
                     "type": "string",
-                    "example": "请求参数错误"
+                    "example": "操作成功"
                 },
                 "requestId": {
                     "type": "string",
@@ -4927,7 +10298,7 @@ const docTemplate = `{
                 },
                 "success": {
                     "type": "boolean",
-                    "example": false
+                    "example": true
                 },
                 "timestamp": {
                     "type": "string",
@@ -4935,17 +10306,17 @@ const docTemplate = `{
                 }
             }
         },
-        "model.ErrResponseDontShowError": {
-            "description": "错误的API响应标准格式,不展示 error",
+        "model.SuccessResponseNoData": {
+            "description": "成功的API响应标准格式,没有 data",
             "type": "object",
             "properties": {
                 "code": {
                     "type": "integer",
-                    "example": 400
+                    "example": 200
                 },
                 "message": {
                     "type": "string",
-                    "example": "请求参数错误"
+                    "example": "操作成功"
                 },
                 "requestId": {
                     "type": "string",
@@ -4953,7 +10324,7 @@ const docTemplate = `{
                 },
                 "success": {
                     "type": "boolean",
-                    "example": false
+                    "example": true
                 },
                 "timestamp": {
                     "type": "string",
@@ -4961,325 +10332,352 @@ const docTemplate = `{
                 }
             }
         },
-        "model.IPGroup": {
-            "description": "IP地址组信息，包含组名和IP地址列表",
+        "model.TLSPolicy": {
             "type": "object",
             "properties": {
-                "id": {
-                    "description": "组唯一标识符",
-                    "type": "string",
-                    "example": "60d21b4367d0d8992e89e964"
-                },
-                "items": {
-                    "description": "IP地址或CIDR列表",
+                "alpn": {
+                    "description": "ALPN 协商的协议，按优先级排列，支持 h2 和 http/1.1",
                     "type": "array",
                     "items": {
                         "type": "string"
-                    },
-                    "example": [
-                        "['192.168.1.1'",
-                        " '10.0.0.1/24']"
-                    ]
-                },
-                "name": {
-                    "description": "组名称",
-                    "type": "string",
-                    "example": "内部服务器"
-                }
-            }
-        },
-        "model.IPInfo": {
-            "description": "IP地址地理位置详细信息，包含城市、区域、国家和ASN等数据",
-            "type": "object",
-            "properties": {
-                "asn": {
-                    "type": "object",
-                    "properties": {
-                        "number": {
-                            "description": "ASN号码",
-                            "type": "integer",
-                            "example": 4134
-                        },
-                        "organization": {
-                            "description": "组织名称",
-                            "type": "string",
-                            "example": "China Telecom"
-                        }
-                    }
-                },
-                "city": {
-                    "type": "object",
-                    "properties": {
-                        "nameEn": {
-                            "description": "城市英文名称",
-                            "type": "string",
-                            "example": "Hangzhou"
-                        },
-                        "nameZh": {
-                            "description": "城市中文名称",
-                            "type": "string",
-                            "example": "杭州"
-                        }
                     }
                 },
-                "continent": {
-                    "type": "object",
-                    "properties": {
-                        "nameEn": {
-                            "description": "大洲英文名称",
-                            "type": "string",
-                            "example": "Asia"
-                        },
-                        "nameZh": {
-                            "description": "大洲中文名称",
-                            "type": "string",
-                            "example": "亚洲"
-                        }
-                    }
+                "ciphers": {
+                    "description": "TLSv1.2 及以下的加密套件，OpenSSL 格式，冒号分隔",
+                    "type": "string"
                 },
-                "country": {
-                    "type": "object",
-                    "properties": {
-                        "isoCode": {
-                            "description": "国家ISO代码",
-                            "type": "string",
-                            "example": "CN"
-                        },
-                        "nameEn": {
-                            "description": "国家英文名称",
-                            "type": "string",
-                            "example": "China"
-                        },
-                        "nameZh": {
-                            "description": "国家中文名称",
-                            "type": "string",
-                            "example": "中国"
-                        }
-                    }
+                "ciphersuites": {
+                    "description": "TLSv1.3 的加密套件，冒号分隔",
+                    "type": "string"
                 },
-                "location": {
-                    "type": "object",
-                    "properties": {
-                        "latitude": {
-                            "description": "纬度",
-                            "type": "number",
-                            "example": 30.2943
-                        },
-                        "longitude": {
-                            "description": "经度",
-                            "type": "number",
-                            "example": 120.1663
-                        },
-                        "timeZone": {
-                            "description": "时区",
-                            "type": "string",
-                            "example": "Asia/Shanghai"
+                "clientAuth": {
+                    "description": "客户端证书认证，为空时不校验客户端证书",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ClientAuth"
                         }
-                    }
-                },
-                "subdivision": {
-                    "type": "object",
-                    "properties": {
-                        "isoCode": {
-                            "description": "省/州代码",
-                            "type": "string",
-                            "example": "ZJ"
-                        },
-                        "nameEn": {
-                            "description": "省/州英文名称",
-                            "type": "string",
-                            "example": "Zhejiang"
-                        },
-                        "nameZh": {
-                            "description": "省/州中文名称",
-                            "type": "string",
-                            "example": "浙江"
+                    ]
+                },
+                "hsts": {
+                    "description": "HSTS 响应头，为空时不添加",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.HSTSPolicy"
                         }
-                    }
+                    ]
+                },
+                "maxVersion": {
+                    "description": "最高协议版本，为空时不限制",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TLSVersion"
+                        }
+                    ]
+                },
+                "minVersion": {
+                    "description": "最低协议版本，为空时使用 HAProxy 默认值",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TLSVersion"
+                        }
+                    ]
+                },
+                "ocspStapling": {
+                    "description": "是否启用 OCSP Stapling，由 HAProxy 自动获取和更新 OCSP 响应",
+                    "type": "boolean"
                 }
             }
         },
-        "model.Log": {
-            "description": "详细的WAF规则匹配记录，包含规则触发的详细信息和原始日志",
+        "model.TLSVersion": {
+            "type": "string",
+            "enum": [
+                "TLSv1.0",
+                "TLSv1.1",
+                "TLSv1.2",
+                "TLSv1.3"
+            ],
+            "x-enum-varnames": [
+                "TLSVersion10",
+                "TLSVersion11",
+                "TLSVersion12",
+                "TLSVersion13"
+            ]
+        },
+        "model.ThreatFeed": {
+            "description": "定时从URL或本地文件拉取IP黑名单，并同步到对应的IP组",
             "type": "object",
             "properties": {
-                "accuracy": {
-                    "description": "规则匹配准确度(0-10)",
+                "createdAt": {
+                    "description": "创建时间",
+                    "type": "string"
+                },
+                "csvColumn": {
+                    "description": "CSV 格式中IP所在列，从 0 开始",
                     "type": "integer",
-                    "example": 9
+                    "example": 0
                 },
-                "logRaw": {
-                    "description": "原始日志数据",
+                "csvHeader": {
+                    "description": "CSV 第一行是否为表头",
+                    "type": "boolean",
+                    "example": false
+                },
+                "enabled": {
+                    "description": "是否启用",
+                    "type": "boolean",
+                    "example": true
+                },
+                "format": {
+                    "description": "情报源格式",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ThreatFeedFormat"
+                        }
+                    ],
+                    "example": "drop"
+                },
+                "id": {
                     "type": "string",
-                    "example": "[2024-03-18 08:12:33] [error] ModSecurity: Access denied with code 403 (phase 1). Matched \"Operator 'Rx' with parameter '(?:scanner)' against variable 'REQUEST_HEADERS:User-Agent'\" [id \"10086\"] [msg \"恶意扫描器检测\"] [severity \"CRITICAL\"]"
+                    "example": "60d21b4367d0d8992e89e964"
                 },
-                "message": {
-                    "description": "日志消息",
+                "ipGroupId": {
+                    "description": "同步的IP组ID",
                     "type": "string",
-                    "example": "恶意扫描器检测"
+                    "example": "60d21b4367d0d8992e89e964"
                 },
-                "payload": {
-                    "description": "攻击载荷",
+                "ipGroupName": {
+                    "description": "同步的IP组名称",
                     "type": "string",
-                    "example": "Scanner/1.0"
+                    "example": "feed_spamhaus-drop"
                 },
-                "phase": {
-                    "description": "请求处理阶段",
-                    "type": "integer",
-                    "example": 1
+                "name": {
+                    "description": "情报源名称",
+                    "type": "string",
+                    "example": "spamhaus-drop"
                 },
-                "ruleId": {
-                    "description": "规则ID",
+                "refreshInterval": {
+                    "description": "刷新间隔，单位秒",
                     "type": "integer",
-                    "example": 10086
+                    "example": 3600
                 },
-                "secLangRaw": {
-                    "description": "安全规则原始定义",
+                "source": {
+                    "description": "情报源地址，http(s) URL 或本地文件绝对路径",
                     "type": "string",
-                    "example": "SecRule REQUEST_HEADERS:User-Agent \"@rx (?:scanner)\" \"id:1008,phase:1,severity:'CRITICAL'\""
+                    "example": "https://www.spamhaus.org/drop/drop.txt"
                 },
-                "secMark": {
-                    "description": "安全标记",
-                    "type": "string",
-                    "example": "web_scanner"
+                "status": {
+                    "description": "同步状态",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.ThreatFeedStatus"
+                        }
+                    ]
                 },
-                "severity": {
-                    "description": "严重级别(0-5)",
-                    "type": "integer",
-                    "example": 2
+                "updatedAt": {
+                    "description": "更新时间",
+                    "type": "string"
                 }
             }
         },
-        "model.Server": {
+        "model.ThreatFeedDiff": {
             "type": "object",
             "properties": {
-                "host": {
-                    "description": "主机地址，如 IP 或域名",
-                    "type": "string"
+                "added": {
+                    "description": "新增条目",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
                 },
-                "isSSL": {
-                    "description": "是否启用SSL",
-                    "type": "boolean"
+                "addedCount": {
+                    "description": "新增条目数",
+                    "type": "integer",
+                    "example": 20
                 },
-                "port": {
-                    "description": "端口",
-                    "type": "integer"
+                "invalidLines": {
+                    "description": "情报源中的无效行数",
+                    "type": "integer",
+                    "example": 3
+                },
+                "removed": {
+                    "description": "移除条目",
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "removedCount": {
+                    "description": "移除条目数",
+                    "type": "integer",
+                    "example": 5
+                },
+                "syncedAt": {
+                    "description": "同步时间",
+                    "type": "string"
+                },
+                "validEntries": {
+                    "description": "情报源中的有效条目数",
+                    "type": "integer",
+                    "example": 1250
                 }
             }
         },
-        "model.Site": {
+        "model.ThreatFeedFormat": {
+            "type": "string",
+            "enum": [
+                "plain",
+                "cidr",
+                "drop",
+                "csv"
+            ],
+            "x-enum-comments": {
+                "ThreatFeedFormatCIDR": "每行一个CIDR或IP地址",
+                "ThreatFeedFormatCSV": "CSV 文件中的指定列",
+                "ThreatFeedFormatDROP": "Spamhaus DROP 格式，如 \"1.10.16.0/20 ; SBL256894\"",
+                "ThreatFeedFormatPlain": "每行一个IP地址"
+            },
+            "x-enum-varnames": [
+                "ThreatFeedFormatPlain",
+                "ThreatFeedFormatCIDR",
+                "ThreatFeedFormatDROP",
+                "ThreatFeedFormatCSV"
+            ]
+        },
+        "model.ThreatFeedStatus": {
+            "description": "最近一次拉取、成功和失败的时间，以及最近一次成功同步的条目变更",
             "type": "object",
             "properties": {
-                "activeStatus": {
-                    "description": "站点是否激活",
-                    "type": "boolean"
+                "consecutiveFailures": {
+                    "description": "连续失败次数",
+                    "type": "integer",
+                    "example": 0
                 },
-                "backend": {
-                    "description": "后端服务器配置",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.Backend"
-                        }
-                    ]
+                "itemCount": {
+                    "description": "IP组当前条目数",
+                    "type": "integer",
+                    "example": 1200
                 },
-                "certificate": {
-                    "description": "证书信息",
+                "lastDiff": {
+                    "description": "最近一次成功同步的条目变更",
                     "allOf": [
                         {
-                            "$ref": "#/definitions/model.Certificate"
+                            "$ref": "#/definitions/model.ThreatFeedDiff"
                         }
                     ]
                 },
-                "createdAt": {
+                "lastError": {
+                    "description": "最近一次失败原因",
                     "type": "string"
                 },
-                "domain": {
-                    "description": "域名，如 a.com",
+                "lastErrorAt": {
+                    "description": "最近一次失败时间",
                     "type": "string"
                 },
-                "enableHTTPS": {
-                    "description": "是否启用HTTPS",
-                    "type": "boolean"
-                },
-                "id": {
-                    "description": "站点ID",
+                "lastFetchAt": {
+                    "description": "最近一次拉取时间",
                     "type": "string"
                 },
-                "listenPort": {
-                    "description": "监听端口，如 9000",
-                    "type": "integer"
-                },
-                "name": {
-                    "description": "站点名称",
+                "lastSuccessAt": {
+                    "description": "最近一次成功时间",
                     "type": "string"
                 },
-                "updatedAt": {
+                "nextFetchAt": {
+                    "description": "下次拉取时间",
                     "type": "string"
-                },
-                "wafEnabled": {
-                    "description": "是否启用WAF",
-                    "type": "boolean"
-                },
-                "wafMode": {
-                    "description": "WAF防护模式",
-                    "allOf": [
-                        {
-                            "$ref": "#/definitions/model.WAFMode"
-                        }
-                    ]
                 }
             }
         },
-        "model.SuccessResponse": {
-            "description": "成功的API响应标准格式",
+        "model.TrapDecoy": {
+            "description": "访问陷阱路径时返回的伪装响应，避免攻击者立即察觉已被封禁",
             "type": "object",
             "properties": {
-                "code": {
-                    "type": "integer",
-                    "example": 200
-                },
-                "data": {},
-                "message": {
-                    "type": "string",
-                    "example": "操作成功"
-                },
-                "requestId": {
+                "body": {
+                    "description": "响应内容，以 text/html 返回",
                     "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
-                },
-                "success": {
-                    "type": "boolean",
-                    "example": true
+                    "example": "APP_KEY=base64:changeme\nDB_PASSWORD=secret"
                 },
-                "timestamp": {
-                    "type": "string",
-                    "example": "2023-01-01T12:00:00Z"
+                "statusCode": {
+                    "description": "状态码，支持 200 和 404",
+                    "type": "integer",
+                    "example": 200
                 }
             }
         },
-        "model.SuccessResponseNoData": {
-            "description": "成功的API响应标准格式,没有 data",
+        "model.TrapMatchType": {
+            "type": "string",
+            "enum": [
+                "exact",
+                "prefix"
+            ],
+            "x-enum-comments": {
+                "TrapMatchExact": "路径完全相同",
+                "TrapMatchPrefix": "路径以指定值开头"
+            },
+            "x-enum-varnames": [
+                "TrapMatchExact",
+                "TrapMatchPrefix"
+            ]
+        },
+        "model.TrapPath": {
+            "description": "站点上不存在、只会被扫描器访问的路径，访问后立即封禁来源IP",
             "type": "object",
             "properties": {
-                "code": {
+                "blockDuration": {
+                    "description": "封禁时长（秒）",
                     "type": "integer",
-                    "example": 200
+                    "example": 86400
                 },
-                "message": {
-                    "type": "string",
-                    "example": "操作成功"
+                "createdAt": {
+                    "description": "创建时间",
+                    "type": "string"
                 },
-                "requestId": {
-                    "type": "string",
-                    "example": "550e8400-e29b-41d4-a716-446655440000"
+                "decoy": {
+                    "description": "诱饵响应，为空时返回 403",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TrapDecoy"
+                        }
+                    ]
                 },
-                "success": {
+                "enabled": {
+                    "description": "是否启用",
                     "type": "boolean",
                     "example": true
                 },
-                "timestamp": {
+                "id": {
+                    "description": "唯一标识符",
                     "type": "string",
-                    "example": "2023-01-01T12:00:00Z"
+                    "example": "60d21b4367d0d8992e89e964"
+                },
+                "matchType": {
+                    "description": "匹配方式",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.TrapMatchType"
+                        }
+                    ],
+                    "example": "exact"
+                },
+                "name": {
+                    "description": "名称",
+                    "type": "string",
+                    "example": "dotenv"
+                },
+                "path": {
+                    "description": "陷阱路径，不区分大小写",
+                    "type": "string",
+                    "example": "/.env"
+                },
+                "scope": {
+                    "description": "站点作用域，为空表示对所有站点生效",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/model.SiteScope"
+                        }
+                    ]
+                },
+                "updatedAt": {
+                    "description": "更新时间",
+                    "type": "string"
                 }
             }
         },
@@ -5470,6 +10868,36 @@ const docTemplate = `{
                 "WAFModeProtection",
                 "WAFModeObservation"
             ]
+        },
+        "model.WeeklyWindow": {
+            "description": "在指定星期的某个时段内生效，结束时间早于开始时间表示跨越午夜",
+            "type": "object",
+            "properties": {
+                "days": {
+                    "description": "星期，0 表示周日，为空表示每天；跨午夜时段以开始当天为准",
+                    "type": "array",
+                    "items": {
+                        "type": "integer"
+                    },
+                    "example": [
+                        1,
+                        2,
+                        3,
+                        4,
+                        5
+                    ]
+                },
+                "end": {
+                    "description": "结束时间 HH:MM，可为 24:00",
+                    "type": "string",
+                    "example": "18:00"
+                },
+                "start": {
+                    "description": "开始时间 HH:MM",
+                    "type": "string",
+                    "example": "09:00"
+                }
+            }
         }
     },
     "securityDefinitions": {
@@ -0,0 +1,27 @@
+package dto
+
+import (
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+)
+
+// AuditLogListRequest 审计日志列表请求
+// @Description 获取审计日志列表的请求参数
+type AuditLogListRequest struct {
+	Page         int       `form:"page" binding:"omitempty,min=1" example:"1"`                                                      // 页码
+	Size         int       `form:"size" binding:"omitempty,min=1,max=100" example:"10"`                                             // 每页数量
+	Action       string    `form:"action" binding:"omitempty" example:"expire"`                                                     // 操作类型过滤
+	ResourceType string    `form:"resourceType" binding:"omitempty" example:"ip_group"`                                             // 资源类型过滤
+	ResourceID   string    `form:"resourceId" binding:"omitempty" example:"60d21b4367d0d8992e89e964"`                               // 资源ID过滤
+	Operator     string    `form:"operator" binding:"omitempty" example:"system"`                                                   // 操作人过滤
+	StartTime    time.Time `form:"startTime" binding:"omitempty" time_format:"2006-01-02T15:04:05Z" example:"2024-03-17T00:00:00Z"` // 查询起始时间
+	EndTime      time.Time `form:"endTime" binding:"omitempty" time_format:"2006-01-02T15:04:05Z" example:"2024-03-18T23:59:59Z"`   // 查询结束时间
+}
+
+// AuditLogListResponse 审计日志列表响应
+// @Description 审计日志分页列表响应
+type AuditLogListResponse struct {
+	Total int64            `json:"total" example:"100"` // 总数量
+	Items []model.AuditLog `json:"items"`               // 审计日志列表
+}
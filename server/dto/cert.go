@@ -7,29 +7,23 @@ import (
 )
 
 // CertificateCreateRequest 创建证书请求
-// @Description 创建证书的请求参数
+// @Description 创建证书的请求参数，过期日期、颁发机构、指纹和域名从证书中解析
 type CertificateCreateRequest struct {
-	Name        string    `json:"name" example:"example-cert"`               // 证书名称/别名
-	Description string    `json:"description" example:"用于example.com的证书"`    // 证书描述
-	PublicKey   string    `json:"publicKey" binding:"required"`              // 公钥内容（PEM格式）
-	PrivateKey  string    `json:"privateKey" binding:"required"`             // 私钥内容（PEM格式）
-	ExpireDate  time.Time `json:"expireDate" example:"2023-12-31T23:59:59Z"` // 证书过期日期
-	IssuerName  string    `json:"issuerName" example:"Let's Encrypt"`        // 颁发机构
-	FingerPrint string    `json:"fingerPrint" example:"AA:BB:CC:DD:..."`     // 证书指纹
-	Domains     []string  `json:"domains" example:"[\"example.com\"]"`       // 证书绑定的域名列表
+	Name        string `json:"name" example:"example-cert"`            // 证书名称/别名
+	Description string `json:"description" example:"用于example.com的证书"` // 证书描述
+	PublicKey   string `json:"publicKey" binding:"required"`           // 证书链内容（PEM格式），按站点证书、中间证书的顺序排列
+	PrivateKey  string `json:"privateKey" binding:"required"`          // 私钥内容（PEM格式），支持加密的 PKCS#8 私钥
+	Passphrase  string `json:"passphrase,omitempty"`                   // 加密私钥的密码，私钥解密后保存，密码不保存
 }
 
 // CertificateUpdateRequest 更新证书请求
-// @Description 更新证书的请求参数
+// @Description 更新证书的请求参数，只更新证书链或私钥时与已保存的另一部分一起校验
 type CertificateUpdateRequest struct {
-	Name        string    `json:"name,omitempty" example:"example-cert"`               // 证书名称/别名
-	Description string    `json:"description,omitempty" example:"用于example.com的证书"`    // 证书描述
-	PublicKey   string    `json:"publicKey,omitempty"`                                 // 公钥内容（PEM格式）
-	PrivateKey  string    `json:"privateKey,omitempty"`                                // 私钥内容（PEM格式）
-	ExpireDate  time.Time `json:"expireDate,omitempty" example:"2023-12-31T23:59:59Z"` // 证书过期日期
-	IssuerName  string    `json:"issuerName,omitempty" example:"Let's Encrypt"`        // 颁发机构
-	FingerPrint string    `json:"fingerPrint,omitempty" example:"AA:BB:CC:DD:..."`     // 证书指纹
-	Domains     []string  `json:"domains,omitempty" example:"[\"example.com\"]"`       // 证书绑定的域名列表
+	Name        string `json:"name,omitempty" example:"example-cert"`            // 证书名称/别名
+	Description string `json:"description,omitempty" example:"用于example.com的证书"` // 证书描述
+	PublicKey   string `json:"publicKey,omitempty"`                              // 证书链内容（PEM格式），按站点证书、中间证书的顺序排列
+	PrivateKey  string `json:"privateKey,omitempty"`                             // 私钥内容（PEM格式），支持加密的 PKCS#8 私钥
+	Passphrase  string `json:"passphrase,omitempty"`                             // 加密私钥的密码，私钥解密后保存，密码不保存
 }
 
 // CertificateListResponse 证书列表响应
@@ -38,3 +32,34 @@ type CertificateListResponse struct {
 	Total int64                    `json:"total"` // 总数
 	Items []model.CertificateStore `json:"items"` // 证书列表
 }
+
+// ACMECertificateRequest 通过 ACME 签发证书请求
+// @Description 通过 ACME 自动签发证书的请求参数，签发的证书会在到期前自动续期
+type ACMECertificateRequest struct {
+	Name          string                  `json:"name" example:"example-cert"`                                                                    // 证书名称/别名，为空时使用第一个域名
+	Description   string                  `json:"description" example:"用于example.com的证书"`                                                         // 证书描述
+	Domains       []string                `json:"domains" binding:"required,min=1,max=100,dive,host_pattern" example:"example.com,*.example.com"` // 证书包含的域名，通配符域名只能使用 DNS-01 验证
+	ChallengeType model.ACMEChallengeType `json:"challengeType" binding:"omitempty,oneof=http-01 dns-01" example:"http-01"`                       // 域名验证方式，为空时使用 http-01
+}
+
+// CertificateExpiryItem 证书过期状态
+// @Description 证书库中的证书或站点内嵌证书的过期状态
+type CertificateExpiryItem struct {
+	Source      string                `json:"source" example:"certificate"` // 证书来源：certificate 为证书库中的证书，site 为站点内嵌的证书
+	ID          string                `json:"id"`                           // 证书ID，站点内嵌证书为站点ID
+	Name        string                `json:"name" example:"example-cert"`  // 证书名称，站点内嵌证书为站点名称
+	Domains     []string              `json:"domains"`                      // 证书包含的域名
+	FingerPrint string                `json:"fingerPrint"`                  // 证书指纹
+	ExpireDate  time.Time             `json:"expireDate"`                   // 证书过期日期
+	DaysLeft    int                   `json:"daysLeft" example:"6"`         // 剩余天数，已过期时为负数
+	State       model.CertExpiryState `json:"state" example:"expiring7"`    // 过期状态
+	Sites       []string              `json:"sites,omitempty"`              // 使用该证书的站点名称
+}
+
+// CertificateExpirySummary 证书过期概览
+// @Description 仪表盘使用的证书过期概览，包含各过期状态的证书数量和需要关注的证书
+type CertificateExpirySummary struct {
+	Total  int                           `json:"total"`  // 证书总数
+	Counts map[model.CertExpiryState]int `json:"counts"` // 各过期状态的证书数量
+	Items  []CertificateExpiryItem       `json:"items"`  // 即将过期和已过期的证书，按过期日期升序排列
+}
@@ -2,21 +2,46 @@
 package dto
 
 import (
+	"time"
+
 	"github.com/HUAHUAI23/RuiQi/pkg/model"
 )
 
 // IPGroupCreateRequest IP组创建请求
 // @Description 创建IP组的请求参数
 type IPGroupCreateRequest struct {
-	Name  string   `json:"name" binding:"required" example:"内部服务器"`              // IP组名称
-	Items []string `json:"items" binding:"required" example:"[\"192.168.1.1\"]"` // IP地址或CIDR列表
+	Name        string                    `json:"name" binding:"required" example:"内部服务器"`              // IP组名称
+	Items       []string                  `json:"items" binding:"required" example:"[\"192.168.1.1\"]"` // IP地址或CIDR列表
+	Expirations []IPItemExpirationRequest `json:"expirations,omitempty" binding:"omitempty,dive"`       // 条目过期时间，未列出的条目永久有效
+	Scope       *SiteScopeRequest         `json:"scope,omitempty"`                                      // 站点作用域，为空表示对所有站点生效
+	EdgeBlock   bool                      `json:"edgeBlock,omitempty" example:"false"`                  // 是否作为黑名单在 HAProxy 前端直接拒绝组中IP的请求，只能用于对所有站点生效的IP组
 }
 
 // IPGroupUpdateRequest IP组更新请求
 // @Description 更新IP组的请求参数
 type IPGroupUpdateRequest struct {
-	Name  string   `json:"name,omitempty" example:"内部服务器"`              // IP组名称
-	Items []string `json:"items,omitempty" example:"[\"192.168.1.1\"]"` // IP地址或CIDR列表
+	Name             string                    `json:"name,omitempty" example:"内部服务器"`                 // IP组名称
+	Items            []string                  `json:"items,omitempty" example:"[\"192.168.1.1\"]"`    // IP地址或CIDR列表
+	Expirations      []IPItemExpirationRequest `json:"expirations,omitempty" binding:"omitempty,dive"` // 条目过期时间，传入时整体替换；只更新条目时保留仍在组中的条目的过期时间
+	Scope            *SiteScopeRequest         `json:"scope,omitempty"`                                // 站点作用域，传空对象表示改为对所有站点生效
+	EdgeBlock        *bool                     `json:"edgeBlock,omitempty" example:"false"`            // 是否作为黑名单在 HAProxy 前端直接拒绝组中IP的请求，只能用于对所有站点生效的IP组
+	UpdateReferences bool                      `json:"updateReferences,omitempty" example:"false"`     // 重命名被规则引用的IP组时，是否同时更新引用它的规则；为 false 时拒绝重命名
+}
+
+// IPItemExpirationRequest IP组条目过期时间请求
+// @Description 为IP组中的条目设置过期时间，过期后不再参与匹配并被自动移除
+type IPItemExpirationRequest struct {
+	Item      string    `json:"item" binding:"required" example:"192.168.1.1"`               // IP地址或CIDR，必须是组中的条目
+	ExpiresAt time.Time `json:"expiresAt" binding:"required" example:"2024-03-18T00:00:00Z"` // 过期时间
+}
+
+// IPGroupListRequest IP组列表请求
+// @Description 获取IP组列表的请求参数
+type IPGroupListRequest struct {
+	Page          string `form:"page" example:"1"`                                                      // 页码
+	Size          string `form:"size" example:"10"`                                                     // 每页数量
+	SiteID        string `form:"siteId" binding:"omitempty,mongodb" example:"60d21b4367d0d8992e89e964"` // 按站点过滤，返回作用于该站点的IP组
+	IncludeGlobal *bool  `form:"includeGlobal" example:"true"`                                          // 按站点过滤时是否包含全局IP组，默认包含
 }
 
 // IPGroupListResponse IP组列表响应
@@ -31,3 +56,67 @@ type IPGroupListResponse struct {
 type AddIPToBlacklistRequest struct {
 	IP string `json:"ip" binding:"required" example:"192.168.1.1"` // IP地址或CIDR
 }
+
+// IPGroupImportRequest IP组导入请求
+// @Description 从文件导入IP组的表单参数，文件通过 file 字段上传
+type IPGroupImportRequest struct {
+	Name   string `form:"name" example:"内部服务器"`                                         // IP组名称，仅导入为新IP组时必填
+	Format string `form:"format" binding:"omitempty,oneof=txt csv json" example:"txt"`  // 文件格式，为空时根据文件扩展名判断，默认 txt
+	Mode   string `form:"mode" binding:"omitempty,oneof=merge replace" example:"merge"` // 导入模式：merge-与现有条目合并，replace-替换现有条目，默认 merge
+	DryRun bool   `form:"dryRun" example:"false"`                                       // 只生成变更报告，不保存
+}
+
+// IPGroupExportRequest IP组导出请求
+// @Description 导出IP组的请求参数
+type IPGroupExportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=txt csv json" example:"txt"` // 文件格式，默认 txt
+}
+
+// IPGroupImportInvalidLine 导入时被跳过的无效行
+// @Description 导入文件中无法解析为IP地址或CIDR的行
+type IPGroupImportInvalidLine struct {
+	Line    int    `json:"line" example:"12"`             // 行号，JSON 文件为条目序号
+	Content string `json:"content" example:"10.0.0.300"`  // 行内容
+	Reason  string `json:"reason" example:"无效的IP地址或CIDR"` // 跳过原因
+}
+
+// IPGroupImportReport IP组导入报告
+// @Description 导入结果和条目变更，条目变更列表最多返回 1000 条
+type IPGroupImportReport struct {
+	GroupID          string                     `json:"groupId,omitempty" example:"60d21b4367d0d8992e89e964"` // IP组ID，预览新IP组时为空
+	GroupName        string                     `json:"groupName" example:"内部服务器"`                            // IP组名称
+	Format           string                     `json:"format" example:"txt"`                                 // 实际使用的文件格式
+	Mode             string                     `json:"mode" example:"merge"`                                 // 导入模式
+	DryRun           bool                       `json:"dryRun" example:"false"`                               // 是否仅预览
+	TotalLines       int                        `json:"totalLines" example:"10000"`                           // 文件总行数（JSON 为条目数）
+	ValidEntries     int                        `json:"validEntries" example:"9800"`                          // 有效条目数（去重前）
+	DuplicateEntries int                        `json:"duplicateEntries" example:"150"`                       // 重复条目数
+	InvalidLines     int                        `json:"invalidLines" example:"50"`                            // 无效行数
+	InvalidSamples   []IPGroupImportInvalidLine `json:"invalidSamples,omitempty"`                             // 无效行示例，最多 100 条
+	ItemsBefore      int                        `json:"itemsBefore" example:"200"`                            // 导入前条目数
+	ItemsAfter       int                        `json:"itemsAfter" example:"3500"`                            // 导入并合并CIDR后的条目数
+	AddedCount       int                        `json:"addedCount" example:"3400"`                            // 新增条目数
+	RemovedCount     int                        `json:"removedCount" example:"100"`                           // 移除条目数（包括被合并进更大CIDR的条目）
+	Added            []string                   `json:"added,omitempty" example:"10.0.0.0/23"`                // 新增条目
+	Removed          []string                   `json:"removed,omitempty" example:"10.0.0.0/24"`              // 移除条目
+	Truncated        bool                       `json:"truncated" example:"false"`                            // 变更列表是否被截断
+}
+
+// IPGroupReference 引用IP组的微规则
+// @Description 条件中通过 in_ipgroup 或 not_in_ipgroup 引用IP组的微规则
+type IPGroupReference struct {
+	RuleID     string `json:"ruleId" example:"60d21b4367d0d8992e89e964"` // 规则ID
+	RuleName   string `json:"ruleName" example:"阻止恶意IP"`                 // 规则名称
+	RuleType   string `json:"ruleType" example:"blacklist"`              // 规则类型
+	RuleStatus string `json:"ruleStatus" example:"enabled"`              // 规则状态
+}
+
+// IPGroupReferencesResponse IP组的引用关系
+// @Description IP组及引用它的微规则列表
+type IPGroupReferencesResponse struct {
+	GroupID   string             `json:"groupId,omitempty" example:"60d21b4367d0d8992e89e964"` // IP组ID，IP组不存在时为空
+	GroupName string             `json:"groupName" example:"blocked_ips"`                      // IP组名称
+	Exists    bool               `json:"exists" example:"true"`                                // IP组是否存在，不存在表示规则引用了已失效的IP组
+	Total     int                `json:"total" example:"2"`                                    // 引用规则数量
+	Rules     []IPGroupReference `json:"rules"`                                                // 引用规则列表
+}
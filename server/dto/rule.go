@@ -3,37 +3,72 @@ package dto
 
 import (
 	"encoding/json"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
 )
 
 // MicroRuleCreateRequest 创建微规则请求
 // @Description 创建微规则的请求参数
 type MicroRuleCreateRequest struct {
-	Name      string          `json:"name" binding:"required" example:"SQL注入防护规则"`                           // 规则名称
-	Type      string          `json:"type" binding:"required,oneof=whitelist blacklist" example:"blacklist"` // 规则类型
-	Status    string          `json:"status" binding:"required,oneof=enabled disabled" example:"enabled"`    // 规则状态
-	Priority  int             `json:"priority" binding:"required" example:"100"`                             // 优先级字段，数字越大优先级越高
-	Condition json.RawMessage `json:"condition" binding:"required" swaggertype:"object"`                     // 规则条件
+	Name      string               `json:"name" binding:"required" example:"SQL注入防护规则"`                           // 规则名称
+	Type      string               `json:"type" binding:"required,oneof=whitelist blacklist" example:"blacklist"` // 规则类型
+	Status    string               `json:"status" binding:"required,oneof=enabled disabled" example:"enabled"`    // 规则状态
+	Priority  int                  `json:"priority" binding:"required" example:"100"`                             // 优先级字段，数字越大优先级越高
+	Condition json.RawMessage      `json:"condition" binding:"required" swaggertype:"object"`                     // 规则条件
+	Scope     *SiteScopeRequest    `json:"scope,omitempty"`                                                       // 站点作用域，为空表示对所有站点生效
+	Schedule  *RuleScheduleRequest `json:"schedule,omitempty"`                                                    // 生效计划，为空表示始终生效
 }
 
 // MicroRuleUpdateRequest 更新微规则请求
 // @Description 更新微规则的请求参数
 type MicroRuleUpdateRequest struct {
-	Name      string          `json:"name,omitempty" example:"SQL注入防护规则"`                                               // 规则名称
-	Type      string          `json:"type,omitempty" binding:"omitempty,oneof=whitelist blacklist" example:"blacklist"` // 规则类型
-	Status    string          `json:"status,omitempty" binding:"omitempty,oneof=enabled disabled" example:"enabled"`    // 规则状态
-	Priority  *int            `json:"priority,omitempty" example:"100"`                                                 // 优先级字段，数字越大优先级越高
-	Condition json.RawMessage `json:"condition,omitempty" swaggertype:"object"`                                         // 规则条件
+	Name      string               `json:"name,omitempty" example:"SQL注入防护规则"`                                               // 规则名称
+	Type      string               `json:"type,omitempty" binding:"omitempty,oneof=whitelist blacklist" example:"blacklist"` // 规则类型
+	Status    string               `json:"status,omitempty" binding:"omitempty,oneof=enabled disabled" example:"enabled"`    // 规则状态
+	Priority  *int                 `json:"priority,omitempty" example:"100"`                                                 // 优先级字段，数字越大优先级越高
+	Condition json.RawMessage      `json:"condition,omitempty" swaggertype:"object"`                                         // 规则条件
+	Scope     *SiteScopeRequest    `json:"scope,omitempty"`                                                                  // 站点作用域，传空对象表示改为对所有站点生效
+	Schedule  *RuleScheduleRequest `json:"schedule,omitempty"`                                                               // 生效计划，传入时整体替换，传空对象表示改为始终生效
+}
+
+// MicroRuleListRequest 微规则列表请求
+// @Description 获取微规则列表的请求参数
+type MicroRuleListRequest struct {
+	Page          string `form:"page" example:"1"`                                                      // 页码
+	Size          string `form:"size" example:"10"`                                                     // 每页数量
+	SiteID        string `form:"siteId" binding:"omitempty,mongodb" example:"60d21b4367d0d8992e89e964"` // 按站点过滤，返回作用于该站点的规则
+	IncludeGlobal *bool  `form:"includeGlobal" example:"true"`                                          // 按站点过滤时是否包含全局规则，默认包含
 }
 
 // MicroRuleResponse 微规则响应
 // @Description 微规则响应参数
 type MicroRuleResponse struct {
-	ID        string          `json:"id,omitempty" example:"60a763d0f03239868b50e810"`
-	Name      string          `json:"name,omitempty" example:"SQL注入防护规则"`                                               // 规则名称
-	Type      string          `json:"type,omitempty" binding:"omitempty,oneof=whitelist blacklist" example:"blacklist"` // 规则类型
-	Status    string          `json:"status,omitempty" binding:"omitempty,oneof=enabled disabled" example:"enabled"`    // 规则状态
-	Priority  *int            `json:"priority,omitempty" example:"100"`                                                 // 优先级字段，数字越大优先级越高
-	Condition json.RawMessage `json:"condition,omitempty" swaggertype:"object"`                                         // 规则条件
+	ID        string              `json:"id,omitempty" example:"60a763d0f03239868b50e810"`
+	Name      string              `json:"name,omitempty" example:"SQL注入防护规则"`                                               // 规则名称
+	Type      string              `json:"type,omitempty" binding:"omitempty,oneof=whitelist blacklist" example:"blacklist"` // 规则类型
+	Status    string              `json:"status,omitempty" binding:"omitempty,oneof=enabled disabled" example:"enabled"`    // 规则状态
+	Priority  *int                `json:"priority,omitempty" example:"100"`                                                 // 优先级字段，数字越大优先级越高
+	Condition json.RawMessage     `json:"condition,omitempty" swaggertype:"object"`                                         // 规则条件
+	Scope     *model.SiteScope    `json:"scope,omitempty"`                                                                  // 站点作用域，为空表示对所有站点生效
+	Schedule  *model.RuleSchedule `json:"schedule,omitempty"`                                                               // 生效计划，为空表示始终生效
+	Stats     *RuleHitStats       `json:"stats,omitempty"`                                                                  // 命中统计，仅列表接口返回
+	Offload   *RuleOffloadStatus  `json:"offload,omitempty"`                                                                // 是否卸载到 HAProxy 判断，仅列表接口返回
+}
+
+// RuleHitStats 规则命中统计
+// @Description 规则累计命中次数、拦截次数和最后命中时间
+type RuleHitStats struct {
+	Hits      int64      `json:"hits" example:"1024"`                                // 累计命中次数
+	Blocks    int64      `json:"blocks" example:"512"`                               // 累计拦截次数
+	LastHitAt *time.Time `json:"lastHitAt,omitempty" example:"2024-01-01T12:30:45Z"` // 最后命中时间，从未命中时为空
+}
+
+// RuleOffloadStatus 规则卸载到 HAProxy 的状态
+// @Description 只使用IP、CIDR、IP组、路径和URL条件的简单规则在 HAProxy 中以 ACL 判断，其余规则由 WAF 引擎判断
+type RuleOffloadStatus struct {
+	Offloaded bool   `json:"offloaded" example:"false"`             // 是否在 HAProxy 中判断
+	Reason    string `json:"reason,omitempty" example:"规则只对部分站点生效"` // 只由 WAF 引擎判断的原因
 }
 
 // MicroRuleListResponse 微规则列表响应
@@ -42,3 +77,47 @@ type MicroRuleListResponse struct {
 	Total int64               `json:"total"` // 总数
 	Items []MicroRuleResponse `json:"items"` // 微规则列表
 }
+
+// RuleAnalysisFinding 规则分析发现的问题
+// @Description 规则分析发现的单个问题，ruleIds 第一项为受影响的规则，其余为导致问题的规则
+type RuleAnalysisFinding struct {
+	Kind      string   `json:"kind" example:"shadowed"`                                             // 问题类型：shadowed、conflict、duplicate、unreachable、invalid_regex、invalid_condition
+	Severity  string   `json:"severity" example:"warning"`                                          // 严重程度：error、warning、info
+	RuleIDs   []string `json:"ruleIds" example:"60d21b4367d0d8992e89e964,60d21b4367d0d8992e89e965"` // 涉及的规则ID
+	RuleNames []string `json:"ruleNames" example:"放行办公网,拦截后台访问"`                                    // 涉及的规则名称，与 ruleIds 一一对应
+	Message   string   `json:"message" example:"匹配的请求都会先被更高优先级的白名单规则匹配，规则不会命中"`                     // 问题描述
+}
+
+// RuleAnalysisSummary 规则分析问题统计
+// @Description 按严重程度统计的问题数量
+type RuleAnalysisSummary struct {
+	Errors   int `json:"errors" example:"1"`   // 错误数量
+	Warnings int `json:"warnings" example:"2"` // 警告数量
+	Infos    int `json:"infos" example:"0"`    // 提示数量
+}
+
+// RuleAnalysisResponse 规则分析结果
+// @Description 按匹配顺序静态分析微规则的结果，问题按严重程度排序
+type RuleAnalysisResponse struct {
+	RuleCount    int                   `json:"ruleCount" example:"24"`    // 规则总数
+	EnabledCount int                   `json:"enabledCount" example:"20"` // 启用的规则数
+	Summary      RuleAnalysisSummary   `json:"summary"`                   // 问题统计
+	Findings     []RuleAnalysisFinding `json:"findings"`                  // 问题列表
+}
+
+// RuleOffloadItem 单条规则的卸载状态
+// @Description 规则ID、名称和卸载状态
+type RuleOffloadItem struct {
+	RuleID    string `json:"ruleId" example:"60d21b4367d0d8992e89e964"` // 规则ID
+	RuleName  string `json:"ruleName" example:"拦截后台访问"`                 // 规则名称
+	Offloaded bool   `json:"offloaded" example:"true"`                  // 是否在 HAProxy 中判断
+	Reason    string `json:"reason,omitempty" example:"规则设置了生效计划"`      // 只由 WAF 引擎判断的原因
+}
+
+// RuleOffloadResponse 微规则卸载结果
+// @Description 按匹配顺序列出每条微规则是否卸载到 HAProxy，结果在下次应用站点配置或热重载时生效
+type RuleOffloadResponse struct {
+	RuleCount      int               `json:"ruleCount" example:"24"`      // 规则总数
+	OffloadedCount int               `json:"offloadedCount" example:"10"` // 卸载到 HAProxy 的规则数
+	Rules          []RuleOffloadItem `json:"rules"`                       // 每条规则的卸载状态，按匹配顺序排列
+}
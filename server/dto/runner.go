@@ -1,5 +1,7 @@
 package dto
 
+import "time"
+
 // RunnerControlRequest 运行器控制请求
 type RunnerControlRequest struct {
 	Action string `json:"action" binding:"required,oneof=start stop restart force_stop reload"` // 控制动作
@@ -18,3 +20,26 @@ type RunnerStatusResponse struct {
 	State     string `json:"state" example:"running"`  // 状态：running, stopped, error
 	IsRunning bool   `json:"isRunning" example:"true"` // 是否正在运行
 }
+
+// ConfigVersionResponse HAProxy 配置版本
+// @Description 通过 haproxy -c 检查并成功加载过的配置版本
+type ConfigVersionResponse struct {
+	ID        string    `json:"id" example:"20250101-120000.000000"` // 版本ID
+	Reason    string    `json:"reason" example:"热重载"`                // 生成原因
+	Checksum  string    `json:"checksum"`                            // haproxy.cfg 的 SHA-256
+	CreatedAt time.Time `json:"createdAt"`                           // 生成时间
+	Active    bool      `json:"active" example:"true"`               // 是否为当前生效的版本
+}
+
+// ConfigVersionDiffRequest 比较配置版本请求
+type ConfigVersionDiffRequest struct {
+	From string `form:"from" binding:"required" example:"20250101-120000.000000"` // 源版本ID
+	To   string `form:"to" example:"20250102-120000.000000"`                      // 目标版本ID，为空时与当前生效的版本比较
+}
+
+// ConfigVersionDiffResponse 比较配置版本响应
+type ConfigVersionDiffResponse struct {
+	From string `json:"from" example:"20250101-120000.000000"` // 源版本ID
+	To   string `json:"to" example:"20250102-120000.000000"`   // 目标版本ID
+	Diff string `json:"diff"`                                  // haproxy.cfg 的统一格式差异，两个版本相同时为空
+}
@@ -0,0 +1,20 @@
+package dto
+
+import "time"
+
+// RuleScheduleRequest 规则生效计划请求
+// @Description 规则的生效时间范围和每周重复的生效时段，未设置的部分不做限制
+type RuleScheduleRequest struct {
+	ActiveFrom  *time.Time            `json:"activeFrom,omitempty" example:"2024-03-17T00:00:00Z"`                     // 生效开始时间
+	ActiveUntil *time.Time            `json:"activeUntil,omitempty" example:"2024-03-18T00:00:00Z"`                    // 生效结束时间，过期后规则会被自动删除
+	Timezone    string                `json:"timezone,omitempty" binding:"omitempty,timezone" example:"Asia/Shanghai"` // 每周时段使用的时区，默认 UTC
+	Windows     []WeeklyWindowRequest `json:"windows,omitempty" binding:"omitempty,dive"`                              // 每周生效时段，为空表示不限时段
+}
+
+// WeeklyWindowRequest 每周生效时段请求
+// @Description 在指定星期的某个时段内生效，结束时间早于开始时间表示跨越午夜
+type WeeklyWindowRequest struct {
+	Days  []int  `json:"days,omitempty" binding:"omitempty,dive,min=0,max=6" example:"1,2,3,4,5"` // 星期，0 表示周日，为空表示每天
+	Start string `json:"start" binding:"required,clock" example:"09:00"`                          // 开始时间 HH:MM
+	End   string `json:"end" binding:"required,clock" example:"18:00"`                            // 结束时间 HH:MM，可为 24:00
+}
@@ -0,0 +1,8 @@
+package dto
+
+// SiteScopeRequest 站点作用域请求
+// @Description 规则或IP组的站点作用域，站点ID或主机名模式任一命中即生效，两者均为空表示对所有站点生效
+type SiteScopeRequest struct {
+	SiteIDs []string `json:"siteIds,omitempty" binding:"omitempty,dive,mongodb" example:"60d21b4367d0d8992e89e964"` // 站点ID列表
+	Hosts   []string `json:"hosts,omitempty" binding:"omitempty,dive,host_pattern" example:"*.example.com"`         // 主机名模式列表，支持 *.example.com 形式的通配符
+}
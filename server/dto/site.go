@@ -1,65 +1,215 @@
 package dto
 
-import (
-	"time"
-
-	"github.com/HUAHUAI23/RuiQi/server/model"
-)
+import "github.com/HUAHUAI23/RuiQi/server/model"
 
 // CreateSiteRequest 创建站点请求
 // @Description 创建站点的请求参数
 type CreateSiteRequest struct {
-	Name         string          `json:"name" binding:"required" example:"my-site"`                                      // 站点名称
-	Domain       string          `json:"domain" binding:"required,domain" example:"example.com"`                         // 域名
-	ListenPort   int             `json:"listenPort" binding:"required,min=1,max=65535" example:"8080"`                   // 监听端口
-	EnableHTTPS  bool            `json:"enableHTTPS" example:"false"`                                                    // 是否启用HTTPS
-	Certificate  *CertificateDTO `json:"certificate,omitempty" binding:"omitempty,required_if=EnableHTTPS true"`         // 证书信息
-	Backend      BackendDTO      `json:"backend" binding:"required"`                                                     // 后端服务器配置
-	WAFEnabled   bool            `json:"wafEnabled" example:"false"`                                                     // 是否启用WAF
-	WAFMode      string          `json:"wafMode" binding:"omitempty,oneof=protection observation" example:"observation"` // WAF模式
-	ActiveStatus bool            `json:"activeStatus" example:"true"`                                                    // 站点状态
+	Name            string              `json:"name" binding:"required" example:"my-site"`                                                              // 站点名称
+	Domain          string              `json:"domain" binding:"required,domain" example:"example.com"`                                                 // 域名
+	Aliases         []string            `json:"aliases,omitempty" binding:"omitempty,max=50,dive,host_pattern" example:"www.example.com,*.example.com"` // 其他域名，支持 *.example.com 形式的通配符域名，不匹配 example.com 本身
+	ListenPort      int                 `json:"listenPort" binding:"required,min=1,max=65535" example:"8080"`                                           // 监听端口
+	EnableHTTPS     bool                `json:"enableHTTPS" example:"false"`                                                                            // 是否启用HTTPS
+	CertificateID   string              `json:"certificateId,omitempty" binding:"omitempty,mongodb" example:"60d21b4367d0d8992e89e964"`                 // 证书库中的证书ID，为空时按域名自动选择包含站点所有域名的证书
+	Backend         BackendDTO          `json:"backend" binding:"required"`                                                                             // 后端服务器配置
+	Routes          []RouteDTO          `json:"routes,omitempty" binding:"omitempty,max=50,dive"`                                                       // 路径路由，按顺序匹配，未命中时使用默认后端
+	WAFEnabled      bool                `json:"wafEnabled" example:"false"`                                                                             // 是否启用WAF
+	WAFMode         string              `json:"wafMode" binding:"omitempty,oneof=protection observation" example:"observation"`                         // WAF模式
+	ActiveStatus    bool                `json:"activeStatus" example:"true"`                                                                            // 站点状态
+	LoginProtection *LoginProtectionDTO `json:"loginProtection,omitempty" binding:"omitempty"`                                                          // 登录保护配置
+	TLSPolicy       *TLSPolicyDTO       `json:"tlsPolicy,omitempty" binding:"omitempty"`                                                                // HTTPS 的 TLS 策略，为空时使用 HAProxy 默认配置
+	RequestHeaders  []HeaderRuleDTO     `json:"requestHeaders,omitempty" binding:"omitempty,max=50,dive"`                                               // 请求头规则，按顺序执行
+	ResponseHeaders []HeaderRuleDTO     `json:"responseHeaders,omitempty" binding:"omitempty,max=50,dive"`                                              // 响应头规则，按顺序执行，在安全响应头预设之后执行
+	SecurityHeaders string              `json:"securityHeaders,omitempty" binding:"omitempty,oneof=none basic strict" example:"basic"`                  // 安全响应头预设：none-不添加，basic-基础，strict-严格
+	HTTPSRedirect   string              `json:"httpsRedirect,omitempty" binding:"omitempty,oneof=none 301 308" example:"308"`                           // HTTP 请求重定向到 HTTPS 的方式：none-不重定向，301、308-永久重定向；只在启用 HTTPS 时生效，默认 301
+	Maintenance     *MaintenanceDTO     `json:"maintenance,omitempty" binding:"omitempty"`                                                              // 维护模式，为空时不启用
+	ErrorPages      []ErrorPageDTO      `json:"errorPages,omitempty" binding:"omitempty,max=4,dive"`                                                    // 自定义错误页
+	Limits          *SiteLimitsDTO      `json:"limits,omitempty" binding:"omitempty"`                                                                   // 超时、请求体大小和连接保护，为空时使用 HAProxy 默认配置
 }
 
 // UpdateSiteRequest 更新站点请求
 // @Description 更新站点的请求参数
 type UpdateSiteRequest struct {
-	Name         string          `json:"name,omitempty" binding:"omitempty" example:"my-site"`                           // 站点名称
-	Domain       string          `json:"domain,omitempty" binding:"omitempty,domain" example:"example.com"`              // 域名
-	ListenPort   int             `json:"listenPort,omitempty" binding:"omitempty,min=1,max=65535" example:"8080"`        // 监听端口
-	EnableHTTPS  bool            `json:"enableHTTPS" example:"false"`                                                    // 是否启用HTTPS
-	Certificate  *CertificateDTO `json:"certificate,omitempty" binding:"omitempty,required_if=EnableHTTPS true"`         // 证书信息
-	Backend      *BackendDTO     `json:"backend,omitempty" binding:"omitempty"`                                          // 后端服务器配置
-	WAFEnabled   bool            `json:"wafEnabled" example:"false"`                                                     // 是否启用WAF
-	WAFMode      string          `json:"wafMode" binding:"omitempty,oneof=protection observation" example:"observation"` // WAF模式
-	ActiveStatus bool            `json:"activeStatus" example:"true"`                                                    // 站点状态
-}
-
-// CertificateDTO 证书DTO
-type CertificateDTO struct {
-	CertName    string    `json:"certName" binding:"required" example:"my-cert"`         // 证书名称
-	PublicKey   string    `json:"publicKey" binding:"required"`                          // 公钥内容
-	PrivateKey  string    `json:"privateKey" binding:"required"`                         // 私钥内容
-	ExpireDate  time.Time `json:"expireDate" binding:"required"`                         // 过期时间
-	IssuerName  string    `json:"issuerName" binding:"required" example:"Let's Encrypt"` // 颁发机构
-	FingerPrint string    `json:"fingerPrint" binding:"required"`                        // 证书指纹
+	Name            string              `json:"name,omitempty" binding:"omitempty" example:"my-site"`                                                   // 站点名称
+	Domain          string              `json:"domain,omitempty" binding:"omitempty,domain" example:"example.com"`                                      // 域名
+	Aliases         []string            `json:"aliases,omitempty" binding:"omitempty,max=50,dive,host_pattern" example:"www.example.com,*.example.com"` // 其他域名，传入时整体替换，传入空数组表示清空
+	ListenPort      int                 `json:"listenPort,omitempty" binding:"omitempty,min=1,max=65535" example:"8080"`                                // 监听端口
+	EnableHTTPS     bool                `json:"enableHTTPS" example:"false"`                                                                            // 是否启用HTTPS
+	CertificateID   string              `json:"certificateId,omitempty" binding:"omitempty,mongodb" example:"60d21b4367d0d8992e89e964"`                 // 证书库中的证书ID，为空时按域名自动选择包含站点所有域名的证书
+	Backend         *BackendDTO         `json:"backend,omitempty" binding:"omitempty"`                                                                  // 后端服务器配置
+	Routes          []RouteDTO          `json:"routes,omitempty" binding:"omitempty,max=50,dive"`                                                       // 路径路由，传入时整体替换，传入空数组表示清空
+	WAFEnabled      bool                `json:"wafEnabled" example:"false"`                                                                             // 是否启用WAF
+	WAFMode         string              `json:"wafMode" binding:"omitempty,oneof=protection observation" example:"observation"`                         // WAF模式
+	ActiveStatus    bool                `json:"activeStatus" example:"true"`                                                                            // 站点状态
+	LoginProtection *LoginProtectionDTO `json:"loginProtection,omitempty" binding:"omitempty"`                                                          // 登录保护配置，传入时整体替换
+	TLSPolicy       *TLSPolicyDTO       `json:"tlsPolicy,omitempty" binding:"omitempty"`                                                                // HTTPS 的 TLS 策略，传入时整体替换，传入空对象表示使用 HAProxy 默认配置
+	RequestHeaders  []HeaderRuleDTO     `json:"requestHeaders,omitempty" binding:"omitempty,max=50,dive"`                                               // 请求头规则，传入时整体替换，传入空数组表示清空
+	ResponseHeaders []HeaderRuleDTO     `json:"responseHeaders,omitempty" binding:"omitempty,max=50,dive"`                                              // 响应头规则，传入时整体替换，传入空数组表示清空
+	SecurityHeaders string              `json:"securityHeaders,omitempty" binding:"omitempty,oneof=none basic strict" example:"basic"`                  // 安全响应头预设，为空时保持不变，none 表示不添加
+	HTTPSRedirect   string              `json:"httpsRedirect,omitempty" binding:"omitempty,oneof=none 301 308" example:"308"`                           // HTTP 请求重定向到 HTTPS 的方式，为空时保持不变
+	Maintenance     *MaintenanceDTO     `json:"maintenance,omitempty" binding:"omitempty"`                                                              // 维护模式，传入时整体替换
+	ErrorPages      []ErrorPageDTO      `json:"errorPages,omitempty" binding:"omitempty,max=4,dive"`                                                    // 自定义错误页，传入时整体替换，传入空数组表示清空
+	Limits          *SiteLimitsDTO      `json:"limits,omitempty" binding:"omitempty"`                                                                   // 超时、请求体大小和连接保护，传入时整体替换，传入空对象表示使用 HAProxy 默认配置
 }
 
 // BackendDTO 后端服务器配置DTO
 type BackendDTO struct {
-	Servers []ServerDTO `json:"servers" binding:"required,min=1,dive"` // 服务器列表，至少需要一个服务器
+	Servers      []ServerDTO     `json:"servers" binding:"required,min=1,dive"`                                                                         // 服务器列表，至少需要一个服务器
+	Balance      string          `json:"balance,omitempty" binding:"omitempty,oneof=roundrobin leastconn source uri first random" example:"roundrobin"` // 负载均衡算法，默认 roundrobin
+	HealthCheck  *HealthCheckDTO `json:"healthCheck,omitempty" binding:"omitempty"`                                                                     // HTTP 健康检查，为空时不检查
+	StickyCookie string          `json:"stickyCookie,omitempty" binding:"omitempty,max=64,alphanum" example:"SERVERID"`                                 // 会话保持 Cookie 名称，为空时不保持会话
+}
+
+// HealthCheckDTO HTTP 健康检查DTO
+// @Description 定期向后端服务器发送 HTTP 请求，连续失败达到次数后将服务器标记为 DOWN 并停止转发
+type HealthCheckDTO struct {
+	Path         string `json:"path" binding:"required,startswith=/,max=256" example:"/healthz"`           // 检查路径
+	ExpectStatus string `json:"expectStatus,omitempty" binding:"omitempty,max=7" example:"200-399"`        // 期望的响应状态码，支持 200 或 200-399 形式，默认 200-399
+	Interval     int64  `json:"interval,omitempty" binding:"omitempty,min=500,max=3600000" example:"2000"` // 检查间隔，单位毫秒，默认 2000
+	Rise         int64  `json:"rise,omitempty" binding:"omitempty,min=1,max=100" example:"2"`              // 连续成功多少次后标记为 UP，默认 2
+	Fall         int64  `json:"fall,omitempty" binding:"omitempty,min=1,max=100" example:"3"`              // 连续失败多少次后标记为 DOWN，默认 3
 }
 
 // ServerDTO 服务器DTO
 type ServerDTO struct {
-	Host  string `json:"host" binding:"required" example:"backend.example.com"` // 主机地址
-	Port  int    `json:"port" binding:"required,min=1,max=65535" example:"80"`  // 端口
-	IsSSL bool   `json:"isSSL" example:"false"`                                 // 是否启用SSL
+	Host            string `json:"host" binding:"required" example:"backend.example.com"`                                  // 主机地址
+	Port            int    `json:"port" binding:"required,min=1,max=65535" example:"80"`                                   // 端口
+	IsSSL           bool   `json:"isSSL" example:"false"`                                                                  // 是否启用SSL
+	Weight          int64  `json:"weight,omitempty" binding:"omitempty,min=1,max=256" example:"1"`                         // 权重，默认 1
+	Backup          bool   `json:"backup,omitempty" example:"false"`                                                       // 是否为备用服务器，仅在所有主服务器 DOWN 时使用
+	MaxConn         int64  `json:"maxConn,omitempty" binding:"omitempty,min=1" example:"1000"`                             // 最大并发连接数，默认不限制
+	HostHeader      string `json:"hostHeader,omitempty" binding:"omitempty,oneof=preserve server custom" example:"server"` // Host 头处理方式：preserve-保留，server-改写为服务器地址，custom-改写为自定义值；默认 k8s 环境为 server，其他环境为 preserve
+	HostHeaderValue string `json:"hostHeaderValue,omitempty" binding:"omitempty,max=261" example:"api.internal:8080"`      // 自定义 Host 头，处理方式为 custom 时必填
+}
+
+// SetSiteServerStateRequest 设置后端服务器状态请求
+// @Description 通过 HAProxy 运行时 API 修改服务器状态，无需重新加载配置
+type SetSiteServerStateRequest struct {
+	State string `json:"state" binding:"required,oneof=ready drain maint" example:"drain"` // 状态：ready-正常接收流量，drain-摘流，maint-维护
+}
+
+// SetSiteServerWeightRequest 设置后端服务器权重请求
+// @Description 通过 HAProxy 运行时 API 修改服务器权重，无需重新加载配置
+type SetSiteServerWeightRequest struct {
+	Weight int64 `json:"weight" binding:"required,min=1,max=256" example:"10"` // 权重
+}
+
+// AddSiteServerRequest 添加后端服务器请求
+// @Description 通过 HAProxy 运行时 API 向站点默认后端或路径路由的后端添加服务器，无需重新加载配置
+type AddSiteServerRequest struct {
+	PathPrefix string    `json:"pathPrefix,omitempty" example:"/api/"` // 路径路由的路径前缀，为空表示站点默认后端
+	Server     ServerDTO `json:"server" binding:"required"`            // 服务器配置
+}
+
+// SiteServerStatus 站点后端服务器运行状态
+// @Description 从 HAProxy 统计信息中读取的后端服务器状态
+type SiteServerStatus struct {
+	Backend         string `json:"backend" example:"be_example_com"`     // HAProxy 后端名称
+	Server          string `json:"server" example:"example_com_0"`       // HAProxy 服务器名称
+	PathPrefix      string `json:"pathPrefix,omitempty" example:"/api/"` // 所属路径路由，为空表示站点默认后端
+	Host            string `json:"host" example:"10.0.0.1"`              // 主机地址
+	Port            int    `json:"port" example:"80"`                    // 端口
+	Status          string `json:"status" example:"UP"`                  // 运行状态，如 UP、DOWN、MAINT、no check，HAProxy 中不存在时为 UNKNOWN
+	CheckStatus     string `json:"checkStatus,omitempty" example:"L7OK"` // 最近一次健康检查结果
+	Weight          int64  `json:"weight" example:"1"`                   // 当前生效的权重
+	CurrentSessions int64  `json:"currentSessions" example:"3"`          // 当前会话数
+}
+
+// RouteDTO 路径路由DTO
+// @Description 请求路径以指定前缀开头时转发到该路由的后端，多条路由按顺序匹配
+type RouteDTO struct {
+	PathPrefix string     `json:"pathPrefix" binding:"required,startswith=/,max=256" example:"/api/"` // 路径前缀，区分大小写
+	Backend    BackendDTO `json:"backend" binding:"required"`                                         // 后端服务器配置
+}
+
+// LoginProtectionDTO 登录保护配置DTO
+// @Description 按用户名、来源IP和来源ASN统计登录失败次数，任一维度在滑动窗口内达到阈值后对后续登录请求执行处置动作；需要开启响应检测
+type LoginProtectionDTO struct {
+	Enabled           bool   `json:"enabled" example:"true"`                                                             // 是否启用
+	Path              string `json:"path" binding:"required,startswith=/" example:"/api/login"`                          // 登录接口路径，完全匹配
+	Method            string `json:"method" binding:"omitempty,oneof=GET POST PUT PATCH" example:"POST"`                 // 登录接口请求方法，默认 POST
+	UsernameField     string `json:"usernameField" binding:"required,max=128" example:"username"`                        // 用户名字段，表单字段名或 JSON 键，JSON 支持 user.name 形式的嵌套路径
+	FailureStatus     []int  `json:"failureStatus,omitempty" binding:"omitempty,dive,min=100,max=599" example:"401,403"` // 表示登录失败的响应状态码
+	FailurePattern    string `json:"failurePattern,omitempty" binding:"omitempty,max=1024" example:"(?i)密码错误"`           // 表示登录失败的响应内容正则表达式，与状态码至少配置一项
+	Window            int64  `json:"window" binding:"omitempty,min=10,max=86400" example:"600"`                          // 滑动统计窗口，单位秒，默认 600
+	UsernameThreshold int64  `json:"usernameThreshold" binding:"omitempty,min=1" example:"5"`                            // 单个用户名的失败次数阈值，0 表示不统计
+	IPThreshold       int64  `json:"ipThreshold" binding:"omitempty,min=1" example:"20"`                                 // 单个来源IP的失败次数阈值，0 表示不统计
+	ASNThreshold      int64  `json:"asnThreshold" binding:"omitempty,min=1" example:"200"`                               // 单个来源ASN的失败次数阈值，0 表示不统计，需要配置 ASN 数据库
+	Action            string `json:"action" binding:"required,oneof=challenge throttle block" example:"challenge"`       // 处置动作：challenge-JS挑战，throttle-返回429，block-封禁来源IP
+	BlockDuration     int64  `json:"blockDuration" binding:"omitempty,min=60,max=31536000" example:"3600"`               // block 动作的封禁时长，单位秒，默认 3600
+}
+
+// HeaderRuleDTO 请求头/响应头规则DTO
+// @Description set-设置头部，add-添加头部，delete-删除头部，replace-按正则表达式替换头部的值；可以只对指定路径前缀的请求生效
+type HeaderRuleDTO struct {
+	Action     string `json:"action" binding:"required,oneof=set add delete replace" example:"set"`          // 操作
+	Name       string `json:"name" binding:"required,max=128" example:"X-Request-ID"`                        // 头部名称
+	Value      string `json:"value,omitempty" binding:"max=4096" example:"%[uuid()]"`                        // set、add 时为头部的值，replace 时为替换内容，支持 HAProxy 日志格式
+	Pattern    string `json:"pattern,omitempty" binding:"max=1024" example:"^(.*)$"`                         // replace 时匹配头部值的正则表达式
+	PathPrefix string `json:"pathPrefix,omitempty" binding:"omitempty,startswith=/,max=256" example:"/api/"` // 只对路径以该前缀开头的请求生效，为空时对所有请求生效
+}
+
+// MaintenanceDTO 维护模式DTO
+// @Description 启用后除白名单外的请求都返回 503 维护页，维护页为空时使用 503 错误页
+type MaintenanceDTO struct {
+	Enabled    bool     `json:"enabled" example:"true"`                                                                          // 是否启用
+	Page       string   `json:"page,omitempty" binding:"max=12288" example:"<html><body>系统维护中</body></html>"`                    // 维护页 HTML，最大 12KB
+	AllowedIPs []string `json:"allowedIPs,omitempty" binding:"omitempty,max=100,dive,ip|cidr" example:"10.0.0.0/8,192.168.1.10"` // 不受维护模式影响的客户端 IP 或 CIDR
+}
+
+// ErrorPageDTO 自定义错误页DTO
+// @Description HAProxy 因后端错误、超时或没有可用服务器而返回对应状态码时使用的 HTML 页面
+type ErrorPageDTO struct {
+	Status  int    `json:"status" binding:"required,oneof=500 502 503 504" example:"503"`                    // 状态码
+	Content string `json:"content" binding:"required,max=12288" example:"<html><body>服务暂时不可用</body></html>"` // 错误页 HTML，最大 12KB
+}
+
+// SiteLimitsDTO 站点超时、请求体大小和连接保护DTO
+// @Description 为 0 的项使用 HAProxy 默认配置；请求头超时、最大连接数和单 IP 连接速率作用于监听端口，同一端口上的多个站点取最严格的值
+type SiteLimitsDTO struct {
+	MaxBodySize        int64 `json:"maxBodySize,omitempty" binding:"omitempty,min=1" example:"10485760"`                  // 请求体最大字节数，超过时返回 413
+	HTTPRequestTimeout int64 `json:"httpRequestTimeout,omitempty" binding:"omitempty,min=1000,max=300000" example:"5000"` // 接收完整请求头的超时，单位毫秒
+	ConnectTimeout     int64 `json:"connectTimeout,omitempty" binding:"omitempty,min=100,max=300000" example:"3000"`      // 连接后端服务器的超时，单位毫秒
+	ServerTimeout      int64 `json:"serverTimeout,omitempty" binding:"omitempty,min=1000,max=3600000" example:"60000"`    // 等待后端服务器响应的超时，单位毫秒
+	MaxConn            int64 `json:"maxConn,omitempty" binding:"omitempty,min=1,max=1000000" example:"10000"`             // 监听端口的最大并发连接数
+	ConnRate           int64 `json:"connRate,omitempty" binding:"omitempty,min=1,max=100000" example:"100"`               // 单个客户端 IP 每 10 秒最多新建的连接数
+	RequestRate        int64 `json:"requestRate,omitempty" binding:"omitempty,min=1,max=1000000" example:"500"`           // 单个客户端 IP 每 10 秒最多发送到站点的请求数
+}
+
+// TLSPolicyDTO TLS 策略DTO
+// @Description 站点 HTTPS 的协议版本、加密套件、ALPN、HSTS、OCSP Stapling 和客户端证书认证配置，按 SNI 作用于站点的所有主机名
+type TLSPolicyDTO struct {
+	MinVersion   string         `json:"minVersion,omitempty" binding:"omitempty,oneof=TLSv1.0 TLSv1.1 TLSv1.2 TLSv1.3" example:"TLSv1.2"`                   // 最低协议版本
+	MaxVersion   string         `json:"maxVersion,omitempty" binding:"omitempty,oneof=TLSv1.0 TLSv1.1 TLSv1.2 TLSv1.3" example:"TLSv1.3"`                   // 最高协议版本
+	Ciphers      string         `json:"ciphers,omitempty" binding:"omitempty,max=2048" example:"ECDHE-ECDSA-AES128-GCM-SHA256:ECDHE-RSA-AES128-GCM-SHA256"` // TLSv1.2 及以下的加密套件，OpenSSL 格式，冒号分隔
+	Ciphersuites string         `json:"ciphersuites,omitempty" binding:"omitempty,max=1024" example:"TLS_AES_128_GCM_SHA256:TLS_AES_256_GCM_SHA384"`        // TLSv1.3 的加密套件，冒号分隔
+	ALPN         []string       `json:"alpn,omitempty" binding:"omitempty,max=2,unique,dive,oneof=h2 http/1.1" example:"h2,http/1.1"`                       // ALPN 协商的协议，按优先级排列
+	HSTS         *HSTSDTO       `json:"hsts,omitempty" binding:"omitempty"`                                                                                 // HSTS 响应头，为空时不添加
+	OCSPStapling bool           `json:"ocspStapling,omitempty" example:"false"`                                                                             // 是否启用 OCSP Stapling，证书链需要包含签发证书
+	ClientAuth   *ClientAuthDTO `json:"clientAuth,omitempty" binding:"omitempty"`                                                                           // 客户端证书认证，为空时不校验客户端证书
+}
+
+// HSTSDTO HSTS 配置DTO
+// @Description HTTPS 响应添加 Strict-Transport-Security 头，申请预加载时有效期至少一年且需要包含子域名
+type HSTSDTO struct {
+	MaxAge            int64 `json:"maxAge" binding:"required,min=1,max=63072000" example:"31536000"` // 有效期，单位秒
+	IncludeSubDomains bool  `json:"includeSubDomains,omitempty" example:"true"`                      // 是否包含子域名
+	Preload           bool  `json:"preload,omitempty" example:"false"`                               // 是否申请加入浏览器预加载列表
+}
+
+// ClientAuthDTO 客户端证书认证DTO
+// @Description 使用上传的 CA 证书校验客户端证书；required 要求所有请求携带有效证书，paths 只要求指定路径的请求携带有效证书
+type ClientAuthDTO struct {
+	Mode          string   `json:"mode" binding:"required,oneof=required paths" example:"paths"`                           // 认证方式：required-所有请求，paths-指定路径
+	CACertificate string   `json:"caCertificate" binding:"required"`                                                       // 签发客户端证书的 CA 证书（PEM格式），可以包含多个证书
+	Paths         []string `json:"paths,omitempty" binding:"omitempty,max=50,dive,startswith=/,max=256" example:"/admin/"` // 需要客户端证书的路径前缀，认证方式为 paths 时必填
 }
 
 // SiteResponse 站点响应
 // @Description 站点信息响应
 type SiteResponse struct {
 	model.Site
+	ServerStatus []SiteServerStatus `json:"serverStatus,omitempty"` // 后端服务器运行状态，仅在站点详情中返回，HAProxy 未运行时为空
 }
 
 // SiteListResponse 站点列表响应
@@ -107,3 +107,40 @@ type CombinedTimeSeriesResponse struct {
 	Requests  TimeSeriesResponse `json:"requests"`                // 请求数时间序列
 	Blocks    TimeSeriesResponse `json:"blocks"`                  // 拦截数时间序列
 }
+
+// RuleStatsRequest 规则命中统计请求
+// @Description 规则命中统计请求参数
+type RuleStatsRequest struct {
+	TimeRange string `json:"timeRange" form:"timeRange" binding:"required,oneof=24h 7d 30d" example:"24h"` // 时间范围: 24h, 7d, 30d
+	Source    string `json:"source" form:"source" binding:"omitempty,oneof=micro coraza" example:"micro"`  // 规则来源: micro(微规则), coraza(Coraza规则)，为空表示全部
+	RuleID    string `json:"ruleId" form:"ruleId" binding:"omitempty" example:"60d21b4367d0d8992e89e964"`  // 规则ID，为空表示全部规则
+	Limit     int    `json:"limit" form:"limit" binding:"omitempty,min=1,max=100" example:"20"`            // 返回的规则数量，按命中次数降序，默认20
+}
+
+// RuleStatsDataPoint 规则命中时间序列数据点
+// @Description 规则在某个时间桶内的命中和拦截次数
+type RuleStatsDataPoint struct {
+	Timestamp time.Time `json:"timestamp" example:"2024-01-01T12:00:00Z"` // 时间桶开始时间
+	Hits      int64     `json:"hits" example:"128"`                       // 命中次数
+	Blocks    int64     `json:"blocks" example:"64"`                      // 拦截次数
+}
+
+// RuleStatsItem 单条规则的命中统计
+// @Description 单条规则在时间范围内的汇总和时间序列
+type RuleStatsItem struct {
+	Source    string               `json:"source" example:"micro"`                    // 规则来源
+	RuleID    string               `json:"ruleId" example:"60d21b4367d0d8992e89e964"` // 规则ID
+	RuleName  string               `json:"ruleName,omitempty" example:"SQL注入防护规则"`    // 规则名称，仅微规则有效
+	Hits      int64                `json:"hits" example:"1024"`                       // 时间范围内命中次数
+	Blocks    int64                `json:"blocks" example:"512"`                      // 时间范围内拦截次数
+	LastHitAt time.Time            `json:"lastHitAt" example:"2024-01-01T12:30:45Z"`  // 最后命中时间
+	History   []RuleStatsDataPoint `json:"history"`                                   // 时间序列
+}
+
+// RuleStatsResponse 规则命中统计响应
+// @Description 规则命中统计响应
+type RuleStatsResponse struct {
+	TimeRange string          `json:"timeRange" example:"24h"` // 时间范围
+	Interval  string          `json:"interval" example:"hour"` // 时间桶粒度: hour, 6hour, day
+	Items     []RuleStatsItem `json:"items"`                   // 规则统计列表
+}
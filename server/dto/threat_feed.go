@@ -0,0 +1,42 @@
+package dto
+
+import "github.com/HUAHUAI23/RuiQi/server/model"
+
+// ThreatFeedCreateRequest 创建威胁情报源请求
+// @Description 创建威胁情报源订阅，会同时创建同步的IP组
+type ThreatFeedCreateRequest struct {
+	Name            string `json:"name" binding:"required,max=64" example:"spamhaus-drop"`                     // 情报源名称
+	Source          string `json:"source" binding:"required" example:"https://www.spamhaus.org/drop/drop.txt"` // 情报源地址，http(s) URL 或本地文件绝对路径
+	Format          string `json:"format" binding:"required,oneof=plain cidr drop csv" example:"drop"`         // 情报源格式：plain-IP列表，cidr-CIDR列表，drop-Spamhaus DROP，csv-CSV指定列
+	CSVColumn       int    `json:"csvColumn" binding:"omitempty,min=0,max=255" example:"0"`                    // CSV 格式中IP所在列，从 0 开始
+	CSVHeader       bool   `json:"csvHeader" example:"false"`                                                  // CSV 第一行是否为表头
+	RefreshInterval int    `json:"refreshInterval" binding:"required,min=60,max=604800" example:"3600"`        // 刷新间隔，单位秒，范围 60 秒到 7 天
+	IPGroupName     string `json:"ipGroupName" binding:"omitempty,max=64" example:"feed_spamhaus-drop"`        // 同步的IP组名称，默认为 feed_ 加情报源名称
+	Enabled         *bool  `json:"enabled,omitempty" example:"true"`                                           // 是否启用，默认启用
+}
+
+// ThreatFeedUpdateRequest 更新威胁情报源请求
+// @Description 更新威胁情报源订阅，未传入的字段保持不变；修改地址或格式后会尽快重新完整拉取
+type ThreatFeedUpdateRequest struct {
+	Name            string `json:"name,omitempty" binding:"omitempty,max=64" example:"spamhaus-drop"`              // 情报源名称
+	Source          string `json:"source,omitempty" example:"https://www.spamhaus.org/drop/drop.txt"`              // 情报源地址
+	Format          string `json:"format,omitempty" binding:"omitempty,oneof=plain cidr drop csv" example:"drop"`  // 情报源格式
+	CSVColumn       *int   `json:"csvColumn,omitempty" binding:"omitempty,min=0,max=255" example:"0"`              // CSV 格式中IP所在列
+	CSVHeader       *bool  `json:"csvHeader,omitempty" example:"false"`                                            // CSV 第一行是否为表头
+	RefreshInterval int    `json:"refreshInterval,omitempty" binding:"omitempty,min=60,max=604800" example:"3600"` // 刷新间隔，单位秒
+	Enabled         *bool  `json:"enabled,omitempty" example:"true"`                                               // 是否启用
+}
+
+// ThreatFeedListRequest 威胁情报源列表请求
+// @Description 获取威胁情报源列表的请求参数
+type ThreatFeedListRequest struct {
+	Page int `form:"page" binding:"omitempty,min=1" example:"1"`          // 页码
+	Size int `form:"size" binding:"omitempty,min=1,max=100" example:"10"` // 每页数量
+}
+
+// ThreatFeedListResponse 威胁情报源列表响应
+// @Description 威胁情报源分页列表响应
+type ThreatFeedListResponse struct {
+	Total int64              `json:"total" example:"3"` // 总数量
+	Items []model.ThreatFeed `json:"items"`             // 威胁情报源列表
+}
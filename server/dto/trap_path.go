@@ -0,0 +1,51 @@
+package dto
+
+import "github.com/HUAHUAI23/RuiQi/pkg/model"
+
+// TrapPathCreateRequest 创建蜜罐陷阱路径请求
+// @Description 创建蜜罐陷阱路径，访问该路径的来源IP会被立即封禁
+type TrapPathCreateRequest struct {
+	Name          string            `json:"name" binding:"required,max=64" example:"dotenv"`                       // 名称
+	Path          string            `json:"path" binding:"required,startswith=/,max=512" example:"/.env"`          // 陷阱路径，必须以 / 开头，匹配时不区分大小写
+	MatchType     string            `json:"matchType" binding:"omitempty,oneof=exact prefix" example:"exact"`      // 匹配方式：exact-完全匹配，prefix-前缀匹配，默认 exact
+	Scope         *SiteScopeRequest `json:"scope,omitempty"`                                                       // 站点作用域，为空表示对所有站点生效
+	BlockDuration int64             `json:"blockDuration" binding:"omitempty,min=60,max=31536000" example:"86400"` // 封禁时长，单位秒，默认 86400
+	Decoy         *TrapDecoyRequest `json:"decoy,omitempty"`                                                       // 诱饵响应，为空时返回 403
+	Enabled       *bool             `json:"enabled,omitempty" example:"true"`                                      // 是否启用，默认启用
+}
+
+// TrapPathUpdateRequest 更新蜜罐陷阱路径请求
+// @Description 更新蜜罐陷阱路径，未传入的字段保持不变
+type TrapPathUpdateRequest struct {
+	Name          string            `json:"name,omitempty" binding:"omitempty,max=64" example:"dotenv"`                      // 名称
+	Path          string            `json:"path,omitempty" binding:"omitempty,startswith=/,max=512" example:"/.env"`         // 陷阱路径
+	MatchType     string            `json:"matchType,omitempty" binding:"omitempty,oneof=exact prefix" example:"exact"`      // 匹配方式
+	Scope         *SiteScopeRequest `json:"scope,omitempty"`                                                                 // 站点作用域，传空对象表示改为对所有站点生效
+	BlockDuration int64             `json:"blockDuration,omitempty" binding:"omitempty,min=60,max=31536000" example:"86400"` // 封禁时长，单位秒
+	Decoy         *TrapDecoyRequest `json:"decoy,omitempty"`                                                                 // 诱饵响应，传入时整体替换
+	RemoveDecoy   bool              `json:"removeDecoy,omitempty" example:"false"`                                           // 移除诱饵响应，改为返回 403
+	Enabled       *bool             `json:"enabled,omitempty" example:"true"`                                                // 是否启用
+}
+
+// TrapDecoyRequest 诱饵响应请求
+// @Description 访问陷阱路径时返回的伪装响应
+type TrapDecoyRequest struct {
+	StatusCode int    `json:"statusCode" binding:"required,oneof=200 404" example:"200"`                     // 状态码，支持 200 和 404
+	Body       string `json:"body" binding:"max=4096" example:"APP_KEY=base64:changeme\nDB_PASSWORD=secret"` // 响应内容，以 text/html 返回，受 SPOE 帧大小限制最长 4096 个字符
+}
+
+// TrapPathListRequest 蜜罐陷阱路径列表请求
+// @Description 获取蜜罐陷阱路径列表的请求参数
+type TrapPathListRequest struct {
+	Page          int    `form:"page" binding:"omitempty,min=1" example:"1"`                            // 页码
+	Size          int    `form:"size" binding:"omitempty,min=1,max=100" example:"10"`                   // 每页数量
+	SiteID        string `form:"siteId" binding:"omitempty,mongodb" example:"60d21b4367d0d8992e89e964"` // 按站点过滤，返回作用于该站点的陷阱路径
+	IncludeGlobal *bool  `form:"includeGlobal" example:"true"`                                          // 按站点过滤时是否包含全局陷阱路径，默认包含
+}
+
+// TrapPathListResponse 蜜罐陷阱路径列表响应
+// @Description 蜜罐陷阱路径分页列表响应
+type TrapPathListResponse struct {
+	Total int64            `json:"total" example:"3"` // 总数量
+	Items []model.TrapPath `json:"items"`             // 蜜罐陷阱路径列表
+}
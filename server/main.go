@@ -8,6 +8,7 @@ import (
 	"os/signal"
 	"syscall"
 	"time"
+	_ "time/tzdata" // 内置时区数据，运行环境缺少 zoneinfo 时规则生效计划仍可使用命名时区
 
 	"github.com/gin-gonic/gin"
 	"github.com/mvrilo/go-redoc"
@@ -18,7 +19,12 @@ import (
 	"github.com/HUAHUAI23/RuiQi/server/config"
 	_ "github.com/HUAHUAI23/RuiQi/server/docs" // 导入 swagger 文档
 	"github.com/HUAHUAI23/RuiQi/server/router"
+	acmeRenew "github.com/HUAHUAI23/RuiQi/server/service/cornjob/acme"
+	certExpiry "github.com/HUAHUAI23/RuiQi/server/service/cornjob/certexpiry"
+	blockedIPSync "github.com/HUAHUAI23/RuiQi/server/service/cornjob/edgeblock"
+	expiryCleanup "github.com/HUAHUAI23/RuiQi/server/service/cornjob/expiry"
 	haproxyStats "github.com/HUAHUAI23/RuiQi/server/service/cornjob/haproxy"
+	threatFeedSync "github.com/HUAHUAI23/RuiQi/server/service/cornjob/threatfeed"
 	"github.com/HUAHUAI23/RuiQi/server/service/daemon"
 	"github.com/HUAHUAI23/RuiQi/server/validator"
 )
@@ -88,6 +94,46 @@ func main() {
 	// Register cleanup function to be called during shutdown
 	defer haproxyStatsCleanup()
 
+	// Start expired rule and IP group item cleanup cornjob service
+	expiryCleanupStop, err := expiryCleanup.Start(db, config.Logger)
+	if err != nil {
+		config.Logger.Error().Err(err).Msg("Failed to start expiry cleanup service")
+		return
+	}
+	defer expiryCleanupStop()
+
+	// Start threat feed sync cornjob service
+	threatFeedSyncStop, err := threatFeedSync.Start(db, config.Logger)
+	if err != nil {
+		config.Logger.Error().Err(err).Msg("Failed to start threat feed sync service")
+		return
+	}
+	defer threatFeedSyncStop()
+
+	// Start ACME certificate renewal cornjob service
+	acmeRenewStop, err := acmeRenew.Start(db, config.Logger)
+	if err != nil {
+		config.Logger.Error().Err(err).Msg("Failed to start ACME renew service")
+		return
+	}
+	defer acmeRenewStop()
+
+	// Start certificate expiry check cornjob service
+	certExpiryStop, err := certExpiry.Start(db, config.Logger)
+	if err != nil {
+		config.Logger.Error().Err(err).Msg("Failed to start certificate expiry service")
+		return
+	}
+	defer certExpiryStop()
+
+	// Start blocked IP sync cornjob service, mirroring blocked IPs to the HAProxy map
+	blockedIPSyncStop, err := blockedIPSync.Start(runner, config.Logger)
+	if err != nil {
+		config.Logger.Error().Err(err).Msg("Failed to start blocked ip sync service")
+		return
+	}
+	defer blockedIPSyncStop()
+
 	// Set Gin mode based on configuration
 	if config.Global.IsProduction {
 		gin.SetMode(gin.ReleaseMode)
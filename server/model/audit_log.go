@@ -0,0 +1,43 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// 审计操作类型
+const (
+	AuditActionCreate = "create" // 创建
+	AuditActionUpdate = "update" // 更新
+	AuditActionDelete = "delete" // 删除
+	AuditActionExpire = "expire" // 到期清理
+)
+
+// 审计资源类型
+const (
+	AuditResourceMicroRule  = "micro_rule"  // 微规则
+	AuditResourceIPGroup    = "ip_group"    // IP组
+	AuditResourceThreatFeed = "threat_feed" // 威胁情报源
+)
+
+// AuditOperatorSystem 系统定时任务等非用户操作的操作人
+const AuditOperatorSystem = "system"
+
+// AuditLog 审计日志
+// @Description 记录对配置资源的变更，包括用户操作和系统定时任务
+type AuditLog struct {
+	ID           bson.ObjectID  `bson:"_id,omitempty" json:"id,omitempty" example:"60d21b4367d0d8992e89e964"`
+	Action       string         `bson:"action" json:"action" example:"expire"`                           // 操作类型
+	ResourceType string         `bson:"resourceType" json:"resourceType" example:"ip_group"`             // 资源类型
+	ResourceID   string         `bson:"resourceId" json:"resourceId" example:"60d21b4367d0d8992e89e964"` // 资源ID
+	ResourceName string         `bson:"resourceName" json:"resourceName" example:"内部服务器"`                // 资源名称
+	Operator     string         `bson:"operator" json:"operator" example:"system"`                       // 操作人，系统任务为 system
+	Detail       map[string]any `bson:"detail,omitempty" json:"detail,omitempty" swaggertype:"object"`   // 变更详情
+	CreatedAt    time.Time      `bson:"createdAt" json:"createdAt"`                                      // 操作时间
+}
+
+// GetCollectionName 返回集合名称
+func (a *AuditLog) GetCollectionName() string {
+	return "audit_log"
+}
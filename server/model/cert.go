@@ -2,6 +2,8 @@ package model
 
 import (
 	"errors"
+	"slices"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/v2/bson"
@@ -9,17 +11,113 @@ import (
 
 // CertificateStore 代表证书库表
 type CertificateStore struct {
-	ID          bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"` // 证书ID
-	Name        string        `bson:"name" json:"name"`                  // 证书名称/别名
-	Description string        `bson:"description" json:"description"`    // 证书描述
-	PublicKey   string        `bson:"publicKey" json:"publicKey"`        // 公钥内容（PEM格式）
-	PrivateKey  string        `bson:"privateKey" json:"privateKey"`      // 私钥内容（PEM格式）
-	ExpireDate  time.Time     `bson:"expireDate" json:"expireDate"`      // 证书过期日期
-	IssuerName  string        `bson:"issuerName" json:"issuerName"`      // 颁发机构
-	FingerPrint string        `bson:"fingerPrint" json:"fingerPrint"`    // 证书指纹
-	Domains     []string      `bson:"domains" json:"domains"`            // 证书绑定的域名列表
-	CreatedAt   time.Time     `bson:"createdAt" json:"createdAt"`        // 创建时间
-	UpdatedAt   time.Time     `bson:"updatedAt" json:"updatedAt"`        // 更新时间
+	ID          bson.ObjectID   `bson:"_id,omitempty" json:"id,omitempty"`        // 证书ID
+	Name        string          `bson:"name" json:"name"`                         // 证书名称/别名
+	Description string          `bson:"description" json:"description"`           // 证书描述
+	PublicKey   string          `bson:"publicKey" json:"publicKey"`               // 公钥内容（PEM格式）
+	PrivateKey  string          `bson:"privateKey" json:"privateKey"`             // 私钥内容（PEM格式）
+	ExpireDate  time.Time       `bson:"expireDate" json:"expireDate"`             // 证书过期日期
+	IssuerName  string          `bson:"issuerName" json:"issuerName"`             // 颁发机构
+	FingerPrint string          `bson:"fingerPrint" json:"fingerPrint"`           // 证书指纹
+	Domains     []string        `bson:"domains" json:"domains"`                   // 证书绑定的域名列表
+	Source      CertSource      `bson:"source,omitempty" json:"source,omitempty"` // 证书来源，为空表示手动上传
+	ACME        *ACMEStatus     `bson:"acme,omitempty" json:"acme,omitempty"`     // ACME 签发和续期状态，手动上传的证书为空
+	ExpiryState CertExpiryState `bson:"-" json:"expiryState,omitempty"`           // 过期状态，查询证书时根据过期日期计算
+	CreatedAt   time.Time       `bson:"createdAt" json:"createdAt"`               // 创建时间
+	UpdatedAt   time.Time       `bson:"updatedAt" json:"updatedAt"`               // 更新时间
+}
+
+// CertSource 证书来源
+type CertSource string
+
+const (
+	CertSourceManual CertSource = "manual" // 手动上传
+	CertSourceACME   CertSource = "acme"   // 通过 ACME 自动签发和续期
+)
+
+// CertExpiryState 证书过期状态
+type CertExpiryState string
+
+const (
+	CertExpiryOK         CertExpiryState = "ok"         // 30 天以上过期
+	CertExpiringIn30Days CertExpiryState = "expiring30" // 30 天内过期
+	CertExpiringIn14Days CertExpiryState = "expiring14" // 14 天内过期
+	CertExpiringIn7Days  CertExpiryState = "expiring7"  // 7 天内过期
+	CertExpired          CertExpiryState = "expired"    // 已过期
+)
+
+// CertExpiryStates 按严重程度从低到高排列的过期状态
+var CertExpiryStates = []CertExpiryState{
+	CertExpiryOK,
+	CertExpiringIn30Days,
+	CertExpiringIn14Days,
+	CertExpiringIn7Days,
+	CertExpired,
+}
+
+// Severity 返回过期状态的严重程度，数值越大越严重
+func (s CertExpiryState) Severity() int {
+	return slices.Index(CertExpiryStates, s)
+}
+
+// CertificateExpiryState 根据过期日期计算证书在 now 时的过期状态
+func CertificateExpiryState(expireDate, now time.Time) CertExpiryState {
+	remaining := expireDate.Sub(now)
+	switch {
+	case remaining <= 0:
+		return CertExpired
+	case remaining <= 7*24*time.Hour:
+		return CertExpiringIn7Days
+	case remaining <= 14*24*time.Hour:
+		return CertExpiringIn14Days
+	case remaining <= 30*24*time.Hour:
+		return CertExpiringIn30Days
+	default:
+		return CertExpiryOK
+	}
+}
+
+// ACMEChallengeType ACME 域名验证方式
+type ACMEChallengeType string
+
+const (
+	ACMEChallengeHTTP01 ACMEChallengeType = "http-01" // 由 HAProxy 将验证请求转发到管理服务
+	ACMEChallengeDNS01  ACMEChallengeType = "dns-01"  // 通过 DNS 服务商写入 TXT 记录，通配符域名只能使用该方式
+)
+
+// ACMEStatus ACME 证书的签发和续期状态
+type ACMEStatus struct {
+	ChallengeType ACMEChallengeType `bson:"challengeType" json:"challengeType"`                     // 域名验证方式
+	DirectoryURL  string            `bson:"directoryURL" json:"directoryURL"`                       // 签发证书的 ACME 服务目录地址
+	RenewedAt     *time.Time        `bson:"renewedAt,omitempty" json:"renewedAt,omitempty"`         // 最近一次签发或续期成功的时间
+	LastAttemptAt *time.Time        `bson:"lastAttemptAt,omitempty" json:"lastAttemptAt,omitempty"` // 最近一次尝试续期的时间
+	LastError     string            `bson:"lastError,omitempty" json:"lastError,omitempty"`         // 最近一次续期失败的原因，成功后清空
+}
+
+// ACMEAccount ACME 账户，按服务目录地址和联系邮箱区分
+type ACMEAccount struct {
+	ID           bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`
+	DirectoryURL string        `bson:"directoryURL" json:"directoryURL"` // ACME 服务目录地址
+	Email        string        `bson:"email" json:"email"`               // 联系邮箱
+	PrivateKey   string        `bson:"privateKey" json:"-"`              // 账户私钥（PEM格式）
+	CreatedAt    time.Time     `bson:"createdAt" json:"createdAt"`
+}
+
+// CertExpiryNotice 证书过期通知记录，按证书指纹记录最近一次通知的过期状态，避免重复通知
+type CertExpiryNotice struct {
+	FingerPrint string          `bson:"_id" json:"fingerPrint"`       // 证书指纹
+	State       CertExpiryState `bson:"state" json:"state"`           // 最近一次通知的过期状态
+	NotifiedAt  time.Time       `bson:"notifiedAt" json:"notifiedAt"` // 最近一次通知的时间
+}
+
+// GetCollectionName 返回集合名称
+func (n *CertExpiryNotice) GetCollectionName() string {
+	return "cert_expiry_notice"
+}
+
+// GetCollectionName 返回集合名称
+func (a *ACMEAccount) GetCollectionName() string {
+	return "acme_account"
 }
 
 // GetCollectionName 返回集合名称
@@ -46,6 +144,72 @@ func ValidateCertificateStore(cert *CertificateStore) error {
 	return nil
 }
 
+// CoversHostname 判断证书是否包含主机名，*.example.com 只匹配 example.com 的一级子域名
+// 站点的通配符域名只能由包含相同通配符域名的证书覆盖
+func (c *CertificateStore) CoversHostname(hostname string) bool {
+	for _, domain := range c.Domains {
+		if strings.EqualFold(domain, hostname) {
+			return true
+		}
+		if suffix, ok := strings.CutPrefix(domain, "*"); ok && !strings.HasPrefix(hostname, "*.") {
+			label, _, found := strings.Cut(hostname, ".")
+			if found && label != "" && strings.EqualFold(hostname[len(label):], suffix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// UncoveredHostnames 返回站点中证书未包含的主机名
+func (c *CertificateStore) UncoveredHostnames(site *Site) []string {
+	var uncovered []string
+	for _, hostname := range site.Hostnames() {
+		if !c.CoversHostname(hostname) {
+			uncovered = append(uncovered, hostname)
+		}
+	}
+	return uncovered
+}
+
+// SiteCertificate 返回站点配置使用的证书内容
+func (c *CertificateStore) SiteCertificate() Certificate {
+	cert := Certificate{
+		CertName:    c.Name,
+		PublicKey:   c.PublicKey,
+		PrivateKey:  c.PrivateKey,
+		ExpireDate:  c.ExpireDate,
+		IssuerName:  c.IssuerName,
+		FingerPrint: c.FingerPrint,
+	}
+	if c.Source == CertSourceACME && c.ACME != nil {
+		cert.ACMEChallenge = c.ACME.ChallengeType
+	}
+	return cert
+}
+
+// SelectSiteCertificate 返回站点使用的证书：站点指定了证书ID时返回该证书，
+// 否则返回包含站点所有主机名且过期时间最晚的证书，没有匹配的证书时返回 nil
+func SelectSiteCertificate(site *Site, certs []CertificateStore) *CertificateStore {
+	var selected *CertificateStore
+	for i := range certs {
+		cert := &certs[i]
+		if !site.CertificateID.IsZero() {
+			if cert.ID == site.CertificateID {
+				return cert
+			}
+			continue
+		}
+		if len(cert.UncoveredHostnames(site)) > 0 {
+			continue
+		}
+		if selected == nil || cert.ExpireDate.After(selected.ExpireDate) {
+			selected = cert
+		}
+	}
+	return selected
+}
+
 // 通用错误
 var (
 	ErrMissingRequiredField = errors.New("缺少必填字段")
@@ -3,6 +3,7 @@ package model
 import (
 	"time"
 
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
@@ -16,21 +17,125 @@ const (
 
 // Site 代表一个站点配置
 type Site struct {
-	ID           bson.ObjectID `bson:"_id,omitempty" json:"id,omitempty"`                  // 站点ID
-	Name         string        `bson:"name" json:"name"`                                   // 站点名称
-	Domain       string        `bson:"domain" json:"domain"`                               // 域名，如 a.com
-	ListenPort   int           `bson:"listenPort" json:"listenPort"`                       // 监听端口，如 9000
-	EnableHTTPS  bool          `bson:"enableHTTPS" json:"enableHTTPS"`                     // 是否启用HTTPS
-	Certificate  Certificate   `bson:"certificate,omitempty" json:"certificate,omitempty"` // 证书信息
-	Backend      Backend       `bson:"backend" json:"backend"`                             // 后端服务器配置
-	WAFEnabled   bool          `bson:"wafEnabled" json:"wafEnabled"`                       // 是否启用WAF
-	WAFMode      WAFMode       `bson:"wafMode" json:"wafMode"`                             // WAF防护模式
-	CreatedAt    time.Time     `bson:"createdAt" json:"createdAt"`
-	UpdatedAt    time.Time     `bson:"updatedAt" json:"updatedAt"`
-	ActiveStatus bool          `bson:"activeStatus" json:"activeStatus"` // 站点是否激活
-}
-
-// Certificate 代表证书信息
+	ID              bson.ObjectID          `bson:"_id,omitempty" json:"id,omitempty"`                          // 站点ID
+	Name            string                 `bson:"name" json:"name"`                                           // 站点名称
+	Domain          string                 `bson:"domain" json:"domain"`                                       // 域名，如 a.com
+	Aliases         []string               `bson:"aliases,omitempty" json:"aliases,omitempty"`                 // 其他域名，支持 *.example.com 形式的通配符域名
+	ListenPort      int                    `bson:"listenPort" json:"listenPort"`                               // 监听端口，如 9000
+	EnableHTTPS     bool                   `bson:"enableHTTPS" json:"enableHTTPS"`                             // 是否启用HTTPS
+	CertificateID   bson.ObjectID          `bson:"certificateId,omitempty" json:"certificateId,omitzero"`      // 证书库中的证书ID，为空时按域名从证书库自动选择
+	Certificate     Certificate            `bson:"certificate,omitempty" json:"-"`                             // 生效的证书，加载站点时从证书库填充；旧版本内嵌保存的证书在证书库没有匹配的证书时继续使用
+	TLSPolicy       *TLSPolicy             `bson:"tlsPolicy,omitempty" json:"tlsPolicy,omitempty"`             // HTTPS 的 TLS 策略，为空时使用 HAProxy 默认配置
+	Backend         Backend                `bson:"backend" json:"backend"`                                     // 后端服务器配置
+	Routes          []Route                `bson:"routes,omitempty" json:"routes,omitempty"`                   // 路径路由，按顺序匹配，未命中时使用默认后端
+	RequestHeaders  []HeaderRule           `bson:"requestHeaders,omitempty" json:"requestHeaders,omitempty"`   // 转发到后端前按顺序执行的请求头规则
+	ResponseHeaders []HeaderRule           `bson:"responseHeaders,omitempty" json:"responseHeaders,omitempty"` // 返回客户端前按顺序执行的响应头规则，在安全响应头预设之后执行
+	SecurityHeaders SecurityHeaderPreset   `bson:"securityHeaders,omitempty" json:"securityHeaders,omitempty"` // 安全响应头预设，为空时不添加
+	HTTPSRedirect   HTTPSRedirect          `bson:"httpsRedirect,omitempty" json:"httpsRedirect,omitempty"`     // HTTP 请求重定向到 HTTPS 的方式，只在启用 HTTPS 时生效，为空时使用 301
+	Maintenance     *Maintenance           `bson:"maintenance,omitempty" json:"maintenance,omitempty"`         // 维护模式，为空时不启用
+	ErrorPages      []ErrorPage            `bson:"errorPages,omitempty" json:"errorPages,omitempty"`           // 自定义错误页，替换 HAProxy 默认的错误响应
+	Limits          *SiteLimits            `bson:"limits,omitempty" json:"limits,omitempty"`                   // 超时、请求体大小和连接保护，为空时使用 HAProxy 默认配置
+	WAFEnabled      bool                   `bson:"wafEnabled" json:"wafEnabled"`                               // 是否启用WAF
+	WAFMode         WAFMode                `bson:"wafMode" json:"wafMode"`                                     // WAF防护模式
+	LoginProtection *model.LoginProtection `bson:"loginProtection,omitempty" json:"loginProtection,omitempty"` // 登录保护配置
+	CreatedAt       time.Time              `bson:"createdAt" json:"createdAt"`
+	UpdatedAt       time.Time              `bson:"updatedAt" json:"updatedAt"`
+	ActiveStatus    bool                   `bson:"activeStatus" json:"activeStatus"` // 站点是否激活
+}
+
+// Route 代表一条路径前缀路由
+type Route struct {
+	PathPrefix string  `bson:"pathPrefix" json:"pathPrefix"` // 路径前缀，如 /api/
+	Backend    Backend `bson:"backend" json:"backend"`       // 该路由的后端服务器配置
+}
+
+// HeaderAction 请求头和响应头规则的操作
+type HeaderAction string
+
+const (
+	HeaderActionSet     HeaderAction = "set"     // 设置头部，已有的同名头部全部被替换
+	HeaderActionAdd     HeaderAction = "add"     // 添加头部，保留已有的同名头部
+	HeaderActionDelete  HeaderAction = "delete"  // 删除所有同名头部
+	HeaderActionReplace HeaderAction = "replace" // 使用正则表达式替换头部的值
+)
+
+// HeaderRule 一条请求头或响应头规则
+type HeaderRule struct {
+	Action     HeaderAction `bson:"action" json:"action"`                             // 操作
+	Name       string       `bson:"name" json:"name"`                                 // 头部名称，不区分大小写
+	Value      string       `bson:"value,omitempty" json:"value,omitempty"`           // set、add 时为头部的值，replace 时为替换内容；支持 HAProxy 日志格式，如 %[uuid()]
+	Pattern    string       `bson:"pattern,omitempty" json:"pattern,omitempty"`       // replace 时匹配头部值的正则表达式
+	PathPrefix string       `bson:"pathPrefix,omitempty" json:"pathPrefix,omitempty"` // 只对路径以该前缀开头的请求生效，为空时对所有请求生效
+}
+
+// SecurityHeaderPreset 安全响应头预设
+type SecurityHeaderPreset string
+
+const (
+	SecurityHeadersNone   SecurityHeaderPreset = "none"   // 不添加安全响应头
+	SecurityHeadersBasic  SecurityHeaderPreset = "basic"  // 兼容大多数站点的基础安全响应头
+	SecurityHeadersStrict SecurityHeaderPreset = "strict" // 禁止跨站嵌入和加载外部资源的严格安全响应头
+)
+
+// SecurityHeaderPresets 安全响应头预设包含的响应头规则，隐藏后端的 Server 和 X-Powered-By 头
+var SecurityHeaderPresets = map[SecurityHeaderPreset][]HeaderRule{
+	SecurityHeadersBasic: {
+		{Action: HeaderActionSet, Name: "X-Content-Type-Options", Value: "nosniff"},
+		{Action: HeaderActionSet, Name: "X-Frame-Options", Value: "SAMEORIGIN"},
+		{Action: HeaderActionSet, Name: "Referrer-Policy", Value: "strict-origin-when-cross-origin"},
+		{Action: HeaderActionDelete, Name: "Server"},
+		{Action: HeaderActionDelete, Name: "X-Powered-By"},
+	},
+	SecurityHeadersStrict: {
+		{Action: HeaderActionSet, Name: "X-Content-Type-Options", Value: "nosniff"},
+		{Action: HeaderActionSet, Name: "X-Frame-Options", Value: "DENY"},
+		{Action: HeaderActionSet, Name: "Referrer-Policy", Value: "no-referrer"},
+		{Action: HeaderActionSet, Name: "Content-Security-Policy", Value: "default-src 'self'; frame-ancestors 'none'; base-uri 'self'; form-action 'self'"},
+		{Action: HeaderActionSet, Name: "Permissions-Policy", Value: "camera=(), microphone=(), geolocation=()"},
+		{Action: HeaderActionSet, Name: "Cross-Origin-Opener-Policy", Value: "same-origin"},
+		{Action: HeaderActionDelete, Name: "Server"},
+		{Action: HeaderActionDelete, Name: "X-Powered-By"},
+	},
+}
+
+// HTTPSRedirect HTTP 请求重定向到 HTTPS 的方式
+type HTTPSRedirect string
+
+const (
+	HTTPSRedirectNone HTTPSRedirect = "none" // 不重定向
+	HTTPSRedirect301  HTTPSRedirect = "301"  // 301 永久重定向，部分客户端会将 POST 请求改为 GET
+	HTTPSRedirect308  HTTPSRedirect = "308"  // 308 永久重定向，保持请求方法和请求体
+)
+
+// Maintenance 站点维护模式，启用后除白名单外的请求都返回 503 维护页
+type Maintenance struct {
+	Enabled    bool     `bson:"enabled" json:"enabled"`                           // 是否启用
+	Page       string   `bson:"page,omitempty" json:"page,omitempty"`             // 维护页 HTML，为空时使用 503 错误页
+	AllowedIPs []string `bson:"allowedIPs,omitempty" json:"allowedIPs,omitempty"` // 不受维护模式影响的客户端 IP 或 CIDR
+}
+
+// ErrorPage 自定义错误页，HAProxy 因后端错误、超时或没有可用服务器而返回该状态码时使用
+type ErrorPage struct {
+	Status  int    `bson:"status" json:"status"`   // 状态码
+	Content string `bson:"content" json:"content"` // 错误页 HTML
+}
+
+// ErrorPageStatuses 支持自定义错误页的状态码
+var ErrorPageStatuses = []int{500, 502, 503, 504}
+
+// SiteLimits 站点的超时、请求体大小和连接保护，为 0 的项使用 HAProxy 默认配置
+// 请求头超时、最大连接数和单 IP 连接速率作用于监听端口，同一端口上的多个站点取最严格的值
+type SiteLimits struct {
+	MaxBodySize        int64 `bson:"maxBodySize,omitempty" json:"maxBodySize,omitempty"`               // 请求体最大字节数，按 Content-Length 判断，超过时返回 413
+	HTTPRequestTimeout int64 `bson:"httpRequestTimeout,omitempty" json:"httpRequestTimeout,omitempty"` // 接收完整请求头的超时（毫秒），防御慢速请求攻击
+	ConnectTimeout     int64 `bson:"connectTimeout,omitempty" json:"connectTimeout,omitempty"`         // 连接后端服务器的超时（毫秒）
+	ServerTimeout      int64 `bson:"serverTimeout,omitempty" json:"serverTimeout,omitempty"`           // 等待后端服务器响应的超时（毫秒）
+	MaxConn            int64 `bson:"maxConn,omitempty" json:"maxConn,omitempty"`                       // 监听端口的最大并发连接数
+	ConnRate           int64 `bson:"connRate,omitempty" json:"connRate,omitempty"`                     // 单个客户端 IP 每 10 秒最多新建的连接数，超过时直接断开
+	RequestRate        int64 `bson:"requestRate,omitempty" json:"requestRate,omitempty"`               // 单个客户端 IP 每 10 秒最多发送到站点的请求数，超过时直接断开
+}
+
+// Certificate 代表站点生效的证书内容
 type Certificate struct {
 	CertName    string    `bson:"certName" json:"certName"`       // 证书名称/别名
 	PublicKey   string    `bson:"publicKey" json:"publicKey"`     // 公钥内容（PEM格式）
@@ -38,20 +143,118 @@ type Certificate struct {
 	ExpireDate  time.Time `bson:"expireDate" json:"expireDate"`   // 证书过期日期
 	IssuerName  string    `bson:"issuerName" json:"issuerName"`   // 颁发机构
 	FingerPrint string    `bson:"fingerPrint" json:"fingerPrint"` // 证书指纹
+
+	ACMEChallenge ACMEChallengeType `bson:"-" json:"-"` // ACME 证书的域名验证方式，手动上传的证书为空
 }
 
+// TLSVersion TLS 协议版本，取值与 HAProxy 的 ssl-min-ver、ssl-max-ver 参数一致
+type TLSVersion string
+
+const (
+	TLSVersion10 TLSVersion = "TLSv1.0"
+	TLSVersion11 TLSVersion = "TLSv1.1"
+	TLSVersion12 TLSVersion = "TLSv1.2"
+	TLSVersion13 TLSVersion = "TLSv1.3"
+)
+
+// TLSVersions 按从低到高排列的 TLS 协议版本
+var TLSVersions = []TLSVersion{TLSVersion10, TLSVersion11, TLSVersion12, TLSVersion13}
+
+// TLSPolicy 站点的 TLS 策略，按 SNI 作用于站点的所有主机名
+type TLSPolicy struct {
+	MinVersion   TLSVersion  `bson:"minVersion,omitempty" json:"minVersion,omitempty"`     // 最低协议版本，为空时使用 HAProxy 默认值
+	MaxVersion   TLSVersion  `bson:"maxVersion,omitempty" json:"maxVersion,omitempty"`     // 最高协议版本，为空时不限制
+	Ciphers      string      `bson:"ciphers,omitempty" json:"ciphers,omitempty"`           // TLSv1.2 及以下的加密套件，OpenSSL 格式，冒号分隔
+	Ciphersuites string      `bson:"ciphersuites,omitempty" json:"ciphersuites,omitempty"` // TLSv1.3 的加密套件，冒号分隔
+	ALPN         []string    `bson:"alpn,omitempty" json:"alpn,omitempty"`                 // ALPN 协商的协议，按优先级排列，支持 h2 和 http/1.1
+	HSTS         *HSTSPolicy `bson:"hsts,omitempty" json:"hsts,omitempty"`                 // HSTS 响应头，为空时不添加
+	OCSPStapling bool        `bson:"ocspStapling,omitempty" json:"ocspStapling,omitempty"` // 是否启用 OCSP Stapling，由 HAProxy 自动获取和更新 OCSP 响应
+	ClientAuth   *ClientAuth `bson:"clientAuth,omitempty" json:"clientAuth,omitempty"`     // 客户端证书认证，为空时不校验客户端证书
+}
+
+// HSTSPolicy HTTPS 响应的 Strict-Transport-Security 头
+type HSTSPolicy struct {
+	MaxAge            int64 `bson:"maxAge" json:"maxAge"`                                           // 有效期（秒）
+	IncludeSubDomains bool  `bson:"includeSubDomains,omitempty" json:"includeSubDomains,omitempty"` // 是否包含子域名
+	Preload           bool  `bson:"preload,omitempty" json:"preload,omitempty"`                     // 是否申请加入浏览器预加载列表
+}
+
+// ClientAuthMode 客户端证书认证方式
+type ClientAuthMode string
+
+const (
+	ClientAuthRequired ClientAuthMode = "required" // 所有请求都需要有效的客户端证书
+	ClientAuthPaths    ClientAuthMode = "paths"    // 只有指定路径的请求需要有效的客户端证书
+)
+
+// ClientAuth 客户端证书认证配置
+type ClientAuth struct {
+	Mode          ClientAuthMode `bson:"mode" json:"mode"`                       // 认证方式
+	CACertificate string         `bson:"caCertificate" json:"caCertificate"`     // 签发客户端证书的 CA 证书（PEM格式），可以包含多个证书
+	Paths         []string       `bson:"paths,omitempty" json:"paths,omitempty"` // 需要客户端证书的路径前缀，认证方式为 paths 时使用
+}
+
+// BalanceAlgorithm 负载均衡算法
+type BalanceAlgorithm string
+
+const (
+	BalanceRoundRobin BalanceAlgorithm = "roundrobin" // 按权重轮询
+	BalanceLeastConn  BalanceAlgorithm = "leastconn"  // 最少连接数
+	BalanceSource     BalanceAlgorithm = "source"     // 按来源IP哈希
+	BalanceURI        BalanceAlgorithm = "uri"        // 按请求URI哈希
+	BalanceFirst      BalanceAlgorithm = "first"      // 优先使用第一个未满的服务器
+	BalanceRandom     BalanceAlgorithm = "random"     // 按权重随机
+)
+
 // Backend 代表后端服务器配置
 type Backend struct {
-	Servers []Server `bson:"servers" json:"servers"` // 服务器列表
+	Servers      []Server         `bson:"servers" json:"servers"`                               // 服务器列表
+	Balance      BalanceAlgorithm `bson:"balance,omitempty" json:"balance,omitempty"`           // 负载均衡算法，为空时使用 roundrobin
+	HealthCheck  *HealthCheck     `bson:"healthCheck,omitempty" json:"healthCheck,omitempty"`   // HTTP 健康检查，为空时不检查
+	StickyCookie string           `bson:"stickyCookie,omitempty" json:"stickyCookie,omitempty"` // 会话保持 Cookie 名称，为空时不保持会话
+}
+
+// HealthCheck 代表后端 HTTP 健康检查配置
+type HealthCheck struct {
+	Path         string `bson:"path" json:"path"`                 // 检查路径，如 /healthz
+	ExpectStatus string `bson:"expectStatus" json:"expectStatus"` // 期望的响应状态码，如 200 或 200-399
+	Interval     int64  `bson:"interval" json:"interval"`         // 检查间隔（毫秒）
+	Rise         int64  `bson:"rise" json:"rise"`                 // 连续成功多少次后标记为 UP
+	Fall         int64  `bson:"fall" json:"fall"`                 // 连续失败多少次后标记为 DOWN
 }
 
 // Server 代表单个后端服务器
 type Server struct {
-	Host  string `bson:"host" json:"host"`   // 主机地址，如 IP 或域名
-	Port  int    `bson:"port" json:"port"`   // 端口
-	IsSSL bool   `bson:"isSSL" json:"isSSL"` // 是否启用SSL
+	Name            string         `bson:"name,omitempty" json:"name,omitempty"`                       // HAProxy 中的服务器名称，为空时按序号生成
+	Host            string         `bson:"host" json:"host"`                                           // 主机地址，如 IP 或域名
+	Port            int            `bson:"port" json:"port"`                                           // 端口
+	IsSSL           bool           `bson:"isSSL" json:"isSSL"`                                         // 是否启用SSL
+	Weight          int64          `bson:"weight,omitempty" json:"weight,omitempty"`                   // 权重，为 0 时使用 HAProxy 默认值 1
+	Backup          bool           `bson:"backup,omitempty" json:"backup,omitempty"`                   // 是否为备用服务器，仅在所有主服务器 DOWN 时使用
+	MaxConn         int64          `bson:"maxConn,omitempty" json:"maxConn,omitempty"`                 // 最大并发连接数，为 0 时不限制
+	State           ServerState    `bson:"state,omitempty" json:"state,omitempty"`                     // 管理状态，为空表示 ready
+	HostHeader      HostHeaderMode `bson:"hostHeader,omitempty" json:"hostHeader,omitempty"`           // 转发到该服务器时 Host 头的处理方式，为空时 k8s 环境改写为服务器地址，其他环境保留
+	HostHeaderValue string         `bson:"hostHeaderValue,omitempty" json:"hostHeaderValue,omitempty"` // Host 头处理方式为 custom 时使用的 Host 头
 }
 
+// HostHeaderMode 转发到后端服务器时 Host 头的处理方式
+type HostHeaderMode string
+
+const (
+	HostHeaderPreserve HostHeaderMode = "preserve" // 保留客户端请求的 Host 头
+	HostHeaderServer   HostHeaderMode = "server"   // 改写为服务器的主机地址
+	HostHeaderCustom   HostHeaderMode = "custom"   // 改写为自定义值
+)
+
+// ServerState 后端服务器管理状态
+type ServerState string
+
+const (
+	ServerStateReady ServerState = "ready" // 正常接收流量
+	ServerStateDrain ServerState = "drain" // 不再接收新连接，已有会话继续处理，用于发布前摘流
+	ServerStateMaint ServerState = "maint" // 维护状态，不接收任何流量，也不进行健康检查
+)
+
 // IsValidWAFMode 检查WAF模式是否有效
 func IsValidWAFMode(mode WAFMode) bool {
 	return mode == WAFModeProtection || mode == WAFModeObservation
@@ -100,6 +303,13 @@ func WAFModeFromString(s string) WAFMode {
 	return mode
 }
 
+// Hostnames 返回站点的所有主机名，主域名在前
+func (r *Site) Hostnames() []string {
+	hostnames := make([]string, 0, len(r.Aliases)+1)
+	hostnames = append(hostnames, r.Domain)
+	return append(hostnames, r.Aliases...)
+}
+
 // GetCollectionName 返回集合名称
 func (r *Site) GetCollectionName() string {
 	return "site"
@@ -0,0 +1,75 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ThreatFeedFormat 威胁情报源格式
+type ThreatFeedFormat string
+
+const (
+	ThreatFeedFormatPlain ThreatFeedFormat = "plain" // 每行一个IP地址
+	ThreatFeedFormatCIDR  ThreatFeedFormat = "cidr"  // 每行一个CIDR或IP地址
+	ThreatFeedFormatDROP  ThreatFeedFormat = "drop"  // Spamhaus DROP 格式，如 "1.10.16.0/20 ; SBL256894"
+	ThreatFeedFormatCSV   ThreatFeedFormat = "csv"   // CSV 文件中的指定列
+)
+
+// ThreatFeed 威胁情报源订阅
+// @Description 定时从URL或本地文件拉取IP黑名单，并同步到对应的IP组
+type ThreatFeed struct {
+	ID              bson.ObjectID    `bson:"_id,omitempty" json:"id,omitempty" example:"60d21b4367d0d8992e89e964"`
+	Name            string           `bson:"name" json:"name" example:"spamhaus-drop"`                              // 情报源名称
+	Source          string           `bson:"source" json:"source" example:"https://www.spamhaus.org/drop/drop.txt"` // 情报源地址，http(s) URL 或本地文件绝对路径
+	Format          ThreatFeedFormat `bson:"format" json:"format" example:"drop"`                                   // 情报源格式
+	CSVColumn       int              `bson:"csvColumn" json:"csvColumn" example:"0"`                                // CSV 格式中IP所在列，从 0 开始
+	CSVHeader       bool             `bson:"csvHeader" json:"csvHeader" example:"false"`                            // CSV 第一行是否为表头
+	RefreshInterval int              `bson:"refreshInterval" json:"refreshInterval" example:"3600"`                 // 刷新间隔，单位秒
+	IPGroupID       bson.ObjectID    `bson:"ipGroupId" json:"ipGroupId" example:"60d21b4367d0d8992e89e964"`         // 同步的IP组ID
+	IPGroupName     string           `bson:"ipGroupName" json:"ipGroupName" example:"feed_spamhaus-drop"`           // 同步的IP组名称
+	Enabled         bool             `bson:"enabled" json:"enabled" example:"true"`                                 // 是否启用
+	Status          ThreatFeedStatus `bson:"status" json:"status"`                                                  // 同步状态
+	CreatedAt       time.Time        `bson:"createdAt" json:"createdAt"`                                            // 创建时间
+	UpdatedAt       time.Time        `bson:"updatedAt" json:"updatedAt"`                                            // 更新时间
+}
+
+// ThreatFeedStatus 威胁情报源同步状态
+// @Description 最近一次拉取、成功和失败的时间，以及最近一次成功同步的条目变更
+type ThreatFeedStatus struct {
+	NextFetchAt         time.Time       `bson:"nextFetchAt" json:"nextFetchAt"`                             // 下次拉取时间
+	LastFetchAt         *time.Time      `bson:"lastFetchAt,omitempty" json:"lastFetchAt,omitempty"`         // 最近一次拉取时间
+	LastSuccessAt       *time.Time      `bson:"lastSuccessAt,omitempty" json:"lastSuccessAt,omitempty"`     // 最近一次成功时间
+	LastErrorAt         *time.Time      `bson:"lastErrorAt,omitempty" json:"lastErrorAt,omitempty"`         // 最近一次失败时间
+	LastError           string          `bson:"lastError,omitempty" json:"lastError,omitempty"`             // 最近一次失败原因
+	ConsecutiveFailures int             `bson:"consecutiveFailures" json:"consecutiveFailures" example:"0"` // 连续失败次数
+	ItemCount           int             `bson:"itemCount" json:"itemCount" example:"1200"`                  // IP组当前条目数
+	ETag                string          `bson:"etag,omitempty" json:"-"`                                    // 用于条件请求的 ETag
+	LastModified        string          `bson:"lastModified,omitempty" json:"-"`                            // 用于条件请求的 Last-Modified
+	LastDiff            *ThreatFeedDiff `bson:"lastDiff,omitempty" json:"lastDiff,omitempty"`               // 最近一次成功同步的条目变更
+}
+
+// ThreatFeedDiff 一次同步的条目变更，变更列表最多保留 100 条
+type ThreatFeedDiff struct {
+	SyncedAt     time.Time `bson:"syncedAt" json:"syncedAt"`                        // 同步时间
+	ValidEntries int       `bson:"validEntries" json:"validEntries" example:"1250"` // 情报源中的有效条目数
+	InvalidLines int       `bson:"invalidLines" json:"invalidLines" example:"3"`    // 情报源中的无效行数
+	AddedCount   int       `bson:"addedCount" json:"addedCount" example:"20"`       // 新增条目数
+	RemovedCount int       `bson:"removedCount" json:"removedCount" example:"5"`    // 移除条目数
+	Added        []string  `bson:"added,omitempty" json:"added,omitempty"`          // 新增条目
+	Removed      []string  `bson:"removed,omitempty" json:"removed,omitempty"`      // 移除条目
+}
+
+// GetCollectionName 返回集合名称
+func (f *ThreatFeed) GetCollectionName() string {
+	return "threat_feed"
+}
+
+// IsValidThreatFeedFormat 检查情报源格式是否有效
+func IsValidThreatFeedFormat(format ThreatFeedFormat) bool {
+	switch format {
+	case ThreatFeedFormatPlain, ThreatFeedFormatCIDR, ThreatFeedFormatDROP, ThreatFeedFormatCSV:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,81 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var ErrACMEAccountNotFound = errors.New("ACME 账户不存在")
+
+// ACMEAccountRepository ACME 账户仓库接口
+type ACMEAccountRepository interface {
+	GetAccount(ctx context.Context, directoryURL, email string) (*model.ACMEAccount, error)
+	CreateAccount(ctx context.Context, account *model.ACMEAccount) error
+}
+
+// MongoACMEAccountRepository MongoDB实现的 ACME 账户仓库
+type MongoACMEAccountRepository struct {
+	collection *mongo.Collection
+	logger     zerolog.Logger
+}
+
+// NewACMEAccountRepository 创建 ACME 账户仓库
+func NewACMEAccountRepository(db *mongo.Database) ACMEAccountRepository {
+	var account model.ACMEAccount
+	collection := db.Collection(account.GetCollectionName())
+	logger := config.GetRepositoryLogger("acme_account")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 每个 ACME 服务和邮箱只保留一个账户
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "directoryURL", Value: 1}, {Key: "email", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("创建 ACME 账户索引失败")
+	}
+
+	return &MongoACMEAccountRepository{
+		collection: collection,
+		logger:     logger,
+	}
+}
+
+// GetAccount 根据 ACME 服务目录地址和邮箱获取账户
+func (r *MongoACMEAccountRepository) GetAccount(ctx context.Context, directoryURL, email string) (*model.ACMEAccount, error) {
+	var account model.ACMEAccount
+	err := r.collection.FindOne(ctx, bson.D{
+		{Key: "directoryURL", Value: directoryURL},
+		{Key: "email", Value: email},
+	}).Decode(&account)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, ErrACMEAccountNotFound
+		}
+		r.logger.Error().Err(err).Str("directoryURL", directoryURL).Msg("查询 ACME 账户时出错")
+		return nil, err
+	}
+	return &account, nil
+}
+
+// CreateAccount 保存新注册的 ACME 账户
+func (r *MongoACMEAccountRepository) CreateAccount(ctx context.Context, account *model.ACMEAccount) error {
+	account.CreatedAt = time.Now()
+	result, err := r.collection.InsertOne(ctx, account)
+	if err != nil {
+		r.logger.Error().Err(err).Str("directoryURL", account.DirectoryURL).Msg("保存 ACME 账户时出错")
+		return err
+	}
+	account.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
@@ -0,0 +1,160 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// AuditLogRepository 审计日志仓库接口
+type AuditLogRepository interface {
+	CreateAuditLog(ctx context.Context, auditLog *model.AuditLog) error
+	GetAuditLogs(ctx context.Context, req *dto.AuditLogListRequest) ([]model.AuditLog, int64, error)
+}
+
+// MongoAuditLogRepository MongoDB实现的审计日志仓库
+type MongoAuditLogRepository struct {
+	collection *mongo.Collection
+	logger     zerolog.Logger
+}
+
+// NewAuditLogRepository 创建审计日志仓库
+func NewAuditLogRepository(db *mongo.Database) AuditLogRepository {
+	var auditLog model.AuditLog
+	collection := db.Collection(auditLog.GetCollectionName())
+	logger := config.GetRepositoryLogger("audit_log")
+
+	// 创建索引
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 操作时间索引
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "createdAt", Value: -1}},
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("创建操作时间索引失败")
+	}
+
+	// 资源索引
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "resourceType", Value: 1},
+			{Key: "resourceId", Value: 1},
+			{Key: "createdAt", Value: -1},
+		},
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("创建资源索引失败")
+	}
+
+	return &MongoAuditLogRepository{
+		collection: collection,
+		logger:     logger,
+	}
+}
+
+// CreateAuditLog 写入审计日志
+func (r *MongoAuditLogRepository) CreateAuditLog(ctx context.Context, auditLog *model.AuditLog) error {
+	if auditLog.CreatedAt.IsZero() {
+		auditLog.CreatedAt = time.Now()
+	}
+
+	result, err := r.collection.InsertOne(ctx, auditLog)
+	if err != nil {
+		r.logger.Error().Err(err).
+			Str("action", auditLog.Action).
+			Str("resourceType", auditLog.ResourceType).
+			Str("resourceId", auditLog.ResourceID).
+			Msg("写入审计日志时出错")
+		return err
+	}
+
+	auditLog.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// GetAuditLogs 获取审计日志列表，按操作时间倒序
+func (r *MongoAuditLogRepository) GetAuditLogs(ctx context.Context, req *dto.AuditLogListRequest) ([]model.AuditLog, int64, error) {
+	filter := r.buildFilter(req)
+
+	// 计算分页
+	page := req.Page
+	if page < 1 {
+		page = 1
+	}
+	size := req.Size
+	if size < 1 {
+		size = 10
+	} else if size > 100 {
+		size = 100
+	}
+	skip := int64((page - 1) * size)
+
+	findOptions := options.Find().
+		SetSkip(skip).
+		SetLimit(int64(size)).
+		SetSort(bson.D{{Key: "createdAt", Value: -1}})
+
+	// 执行查询
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("查询审计日志列表时出错")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	// 解析结果
+	auditLogs := make([]model.AuditLog, 0)
+	if err = cursor.All(ctx, &auditLogs); err != nil {
+		r.logger.Error().Err(err).Msg("解析审计日志列表时出错")
+		return nil, 0, err
+	}
+
+	// 获取总数
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("获取审计日志总数时出错")
+		return nil, 0, err
+	}
+
+	return auditLogs, total, nil
+}
+
+// buildFilter 构建审计日志查询条件
+func (r *MongoAuditLogRepository) buildFilter(req *dto.AuditLogListRequest) bson.D {
+	filter := bson.D{}
+
+	if req.Action != "" {
+		filter = append(filter, bson.E{Key: "action", Value: req.Action})
+	}
+	if req.ResourceType != "" {
+		filter = append(filter, bson.E{Key: "resourceType", Value: req.ResourceType})
+	}
+	if req.ResourceID != "" {
+		filter = append(filter, bson.E{Key: "resourceId", Value: req.ResourceID})
+	}
+	if req.Operator != "" {
+		filter = append(filter, bson.E{Key: "operator", Value: req.Operator})
+	}
+
+	timeRange := bson.D{}
+	if !req.StartTime.IsZero() {
+		timeRange = append(timeRange, bson.E{Key: "$gte", Value: req.StartTime})
+	}
+	if !req.EndTime.IsZero() {
+		timeRange = append(timeRange, bson.E{Key: "$lte", Value: req.EndTime})
+	}
+	if len(timeRange) > 0 {
+		filter = append(filter, bson.E{Key: "createdAt", Value: timeRange})
+	}
+
+	return filter
+}
@@ -310,3 +310,23 @@ func (r *MongoBlockedIPRepository) getHourlyStats(ctx context.Context, start, en
 
 	return stats, nil
 }
+
+// GetActiveBlockedIPs 获取封禁截止时间晚于 now 的封禁IP记录，只返回IP和封禁截止时间
+func GetActiveBlockedIPs(ctx context.Context, collection *mongo.Collection, now time.Time) ([]model.BlockedIPRecord, error) {
+	filter := bson.D{{Key: "blocked_until", Value: bson.D{{Key: "$gt", Value: now}}}}
+	findOptions := options.Find().SetProjection(bson.D{{Key: "ip", Value: 1}, {Key: "blocked_until", Value: 1}})
+
+	cursor, err := collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		config.Logger.Error().Err(err).Msg("查询生效中的封禁IP时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var records []model.BlockedIPRecord
+	if err = cursor.All(ctx, &records); err != nil {
+		config.Logger.Error().Err(err).Msg("解析生效中的封禁IP时出错")
+		return nil, err
+	}
+	return records, nil
+}
@@ -27,6 +27,8 @@ type CertificateRepository interface {
 	UpdateCertificate(ctx context.Context, certificate *model.CertificateStore) error
 	DeleteCertificate(ctx context.Context, id bson.ObjectID) error
 	CheckCertificateNameExists(ctx context.Context, name string, excludeID bson.ObjectID) (bool, error)
+	GetACMECertificatesExpiringBefore(ctx context.Context, before time.Time) ([]model.CertificateStore, error)
+	GetAllCertificates(ctx context.Context) ([]model.CertificateStore, error)
 }
 
 // MongoCertificateRepository MongoDB实现的证书仓库
@@ -181,3 +183,49 @@ func (r *MongoCertificateRepository) CheckCertificateNameExists(ctx context.Cont
 
 	return count > 0, nil
 }
+
+// GetACMECertificatesExpiringBefore 获取在指定时间前过期的 ACME 证书，按过期时间升序排列
+func (r *MongoCertificateRepository) GetACMECertificatesExpiringBefore(ctx context.Context, before time.Time) ([]model.CertificateStore, error) {
+	filter := bson.D{
+		{Key: "source", Value: model.CertSourceACME},
+		{Key: "expireDate", Value: bson.D{{Key: "$lt", Value: before}}},
+	}
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetSort(bson.D{{Key: "expireDate", Value: 1}}))
+	if err != nil {
+		r.logger.Error().Err(err).Msg("查询待续期证书时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var certificates []model.CertificateStore
+	if err = cursor.All(ctx, &certificates); err != nil {
+		r.logger.Error().Err(err).Msg("解析待续期证书时出错")
+		return nil, err
+	}
+	return certificates, nil
+}
+
+// GetAllCertificates 获取所有证书，不分页
+func (r *MongoCertificateRepository) GetAllCertificates(ctx context.Context) ([]model.CertificateStore, error) {
+	certificates, err := GetAllCertificates(ctx, r.collection)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("查询所有证书时出错")
+		return nil, err
+	}
+	return certificates, nil
+}
+
+// GetAllCertificates 获取集合中的所有证书，不分页
+func GetAllCertificates(ctx context.Context, collection *mongo.Collection) ([]model.CertificateStore, error) {
+	cursor, err := collection.Find(ctx, bson.D{})
+	if err != nil {
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var certificates []model.CertificateStore
+	if err = cursor.All(ctx, &certificates); err != nil {
+		return nil, err
+	}
+	return certificates, nil
+}
@@ -0,0 +1,79 @@
+package repository
+
+import (
+	"context"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// CertExpiryNoticeRepository 证书过期通知记录仓库接口
+type CertExpiryNoticeRepository interface {
+	GetNotices(ctx context.Context) ([]model.CertExpiryNotice, error)
+	SaveNotice(ctx context.Context, notice *model.CertExpiryNotice) error
+	DeleteNotices(ctx context.Context, fingerPrints []string) error
+}
+
+// MongoCertExpiryNoticeRepository MongoDB实现的证书过期通知记录仓库
+type MongoCertExpiryNoticeRepository struct {
+	collection *mongo.Collection
+	logger     zerolog.Logger
+}
+
+// NewCertExpiryNoticeRepository 创建证书过期通知记录仓库
+func NewCertExpiryNoticeRepository(db *mongo.Database) CertExpiryNoticeRepository {
+	var notice model.CertExpiryNotice
+	return &MongoCertExpiryNoticeRepository{
+		collection: db.Collection(notice.GetCollectionName()),
+		logger:     config.GetRepositoryLogger("cert_expiry_notice"),
+	}
+}
+
+// GetNotices 获取所有证书过期通知记录
+func (r *MongoCertExpiryNoticeRepository) GetNotices(ctx context.Context) ([]model.CertExpiryNotice, error) {
+	cursor, err := r.collection.Find(ctx, bson.D{})
+	if err != nil {
+		r.logger.Error().Err(err).Msg("查询证书过期通知记录时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var notices []model.CertExpiryNotice
+	if err = cursor.All(ctx, &notices); err != nil {
+		r.logger.Error().Err(err).Msg("解析证书过期通知记录时出错")
+		return nil, err
+	}
+	return notices, nil
+}
+
+// SaveNotice 保存证书最近一次通知的过期状态
+func (r *MongoCertExpiryNoticeRepository) SaveNotice(ctx context.Context, notice *model.CertExpiryNotice) error {
+	_, err := r.collection.ReplaceOne(
+		ctx,
+		bson.D{{Key: "_id", Value: notice.FingerPrint}},
+		notice,
+		options.Replace().SetUpsert(true),
+	)
+	if err != nil {
+		r.logger.Error().Err(err).Str("fingerPrint", notice.FingerPrint).Msg("保存证书过期通知记录时出错")
+		return err
+	}
+	return nil
+}
+
+// DeleteNotices 删除证书过期通知记录
+func (r *MongoCertExpiryNoticeRepository) DeleteNotices(ctx context.Context, fingerPrints []string) error {
+	if len(fingerPrints) == 0 {
+		return nil
+	}
+	_, err := r.collection.DeleteMany(ctx, bson.D{{Key: "_id", Value: bson.D{{Key: "$in", Value: fingerPrints}}}})
+	if err != nil {
+		r.logger.Error().Err(err).Strs("fingerPrints", fingerPrints).Msg("删除证书过期通知记录时出错")
+		return err
+	}
+	return nil
+}
@@ -21,12 +21,16 @@ var (
 // IPGroupRepository IP组仓库接口
 type IPGroupRepository interface {
 	CreateIPGroup(ctx context.Context, ipGroup *model.IPGroup) error
-	GetIPGroups(ctx context.Context, page, size int64) ([]model.IPGroup, int64, error)
+	GetIPGroups(ctx context.Context, page, size int64, scopeFilter *SiteScopeFilter) ([]model.IPGroup, int64, error)
 	GetIPGroupByID(ctx context.Context, id bson.ObjectID) (*model.IPGroup, error)
 	GetIPGroupByName(ctx context.Context, name string) (*model.IPGroup, error)
 	UpdateIPGroup(ctx context.Context, ipGroup *model.IPGroup) error
 	DeleteIPGroup(ctx context.Context, id bson.ObjectID) error
 	CheckIPGroupNameExists(ctx context.Context, name string, excludeID bson.ObjectID) (bool, error)
+	GetIPGroupsWithExpiredItems(ctx context.Context, now time.Time) ([]model.IPGroup, error)
+	RemoveExpiredItems(ctx context.Context, id bson.ObjectID, items []string, now time.Time) error
+	RenameIPGroup(ctx context.Context, id bson.ObjectID, oldName, newName string) (int, error)
+	GetIPGroupsByNames(ctx context.Context, names []string) ([]model.IPGroup, error)
 }
 
 // MongoIPGroupRepository MongoDB实现的IP组仓库
@@ -54,6 +58,15 @@ func NewIPGroupRepository(db *mongo.Database) IPGroupRepository {
 		logger.Error().Err(err).Msg("创建IP组名称索引失败")
 	}
 
+	// 条目过期时间索引（用于过期清理）
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expirations.expires_at", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("创建IP组条目过期时间索引失败")
+	}
+
 	return &MongoIPGroupRepository{
 		collection: collection,
 		logger:     logger,
@@ -74,7 +87,7 @@ func (r *MongoIPGroupRepository) CreateIPGroup(ctx context.Context, ipGroup *mod
 }
 
 // GetIPGroups 获取IP组列表
-func (r *MongoIPGroupRepository) GetIPGroups(ctx context.Context, page, size int64) ([]model.IPGroup, int64, error) {
+func (r *MongoIPGroupRepository) GetIPGroups(ctx context.Context, page, size int64, scopeFilter *SiteScopeFilter) ([]model.IPGroup, int64, error) {
 	// 计算分页
 	skip := (page - 1) * size
 
@@ -84,8 +97,11 @@ func (r *MongoIPGroupRepository) GetIPGroups(ctx context.Context, page, size int
 		SetLimit(size).
 		SetSort(bson.D{{Key: "name", Value: 1}}) // 按名称升序排序
 
+	// 按作用域过滤
+	filter := scopeFilter.toBson()
+
 	// 执行查询
-	cursor, err := r.collection.Find(ctx, bson.D{}, findOptions)
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		r.logger.Error().Err(err).Msg("查询IP组列表时出错")
 		return nil, 0, err
@@ -100,7 +116,7 @@ func (r *MongoIPGroupRepository) GetIPGroups(ctx context.Context, page, size int
 	}
 
 	// 获取总数
-	total, err := r.collection.CountDocuments(ctx, bson.D{})
+	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		r.logger.Error().Err(err).Msg("获取IP组总数时出错")
 		return nil, 0, err
@@ -188,3 +204,216 @@ func (r *MongoIPGroupRepository) CheckIPGroupNameExists(ctx context.Context, nam
 
 	return count > 0, nil
 }
+
+// GetIPGroupsWithExpiredItems 获取存在过期时间不晚于 now 的条目的IP组
+func (r *MongoIPGroupRepository) GetIPGroupsWithExpiredItems(ctx context.Context, now time.Time) ([]model.IPGroup, error) {
+	filter := bson.D{{Key: "expirations.expires_at", Value: bson.D{{Key: "$lte", Value: now}}}}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("查询包含过期条目的IP组时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ipGroups []model.IPGroup
+	if err = cursor.All(ctx, &ipGroups); err != nil {
+		r.logger.Error().Err(err).Msg("解析包含过期条目的IP组时出错")
+		return nil, err
+	}
+
+	return ipGroups, nil
+}
+
+// GetIPGroupsByNames 根据名称批量获取IP组，不存在的名称会被忽略
+func (r *MongoIPGroupRepository) GetIPGroupsByNames(ctx context.Context, names []string) ([]model.IPGroup, error) {
+	if len(names) == 0 {
+		return nil, nil
+	}
+
+	cursor, err := r.collection.Find(ctx, bson.D{{Key: "name", Value: bson.D{{Key: "$in", Value: names}}}})
+	if err != nil {
+		r.logger.Error().Err(err).Msg("批量查询IP组时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ipGroups []model.IPGroup
+	if err = cursor.All(ctx, &ipGroups); err != nil {
+		r.logger.Error().Err(err).Msg("解析IP组列表时出错")
+		return nil, err
+	}
+
+	return ipGroups, nil
+}
+
+// RemoveExpiredItems 从IP组中移除已过期的条目及过期时间不晚于 now 的过期记录
+// 使用 $pull 原地更新，避免覆盖并发的IP组修改
+func (r *MongoIPGroupRepository) RemoveExpiredItems(ctx context.Context, id bson.ObjectID, items []string, now time.Time) error {
+	if items == nil {
+		items = []string{} // $in 不接受 null
+	}
+
+	_, err := r.collection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: id}},
+		bson.D{{Key: "$pull", Value: bson.D{
+			{Key: "items", Value: bson.D{{Key: "$in", Value: items}}},
+			{Key: "expirations", Value: bson.D{{Key: "expires_at", Value: bson.D{{Key: "$lte", Value: now}}}}},
+		}}},
+	)
+	if err != nil {
+		r.logger.Error().Err(err).Str("id", id.Hex()).Msg("移除IP组过期条目时出错")
+		return err
+	}
+
+	return nil
+}
+
+// RenameIPGroup 重命名IP组，并将引用该IP组的微规则条件更新为新名称，返回更新的规则数量
+// 优先在事务中完成；MongoDB 不是副本集部署、不支持事务时按顺序更新，失败时回滚已完成的修改
+func (r *MongoIPGroupRepository) RenameIPGroup(ctx context.Context, id bson.ObjectID, oldName, newName string) (int, error) {
+	session, err := r.collection.Database().Client().StartSession()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("创建数据库会话失败")
+		return 0, err
+	}
+	defer session.EndSession(ctx)
+
+	result, err := session.WithTransaction(ctx, func(txCtx context.Context) (any, error) {
+		return r.renameIPGroup(txCtx, id, oldName, newName, nil)
+	})
+	if err == nil {
+		return result.(int), nil
+	}
+	if !isTransactionUnsupported(err) {
+		r.logger.Error().Err(err).Str("id", id.Hex()).Msg("重命名IP组时出错")
+		return 0, err
+	}
+
+	r.logger.Warn().Str("id", id.Hex()).Msg("MongoDB 不支持事务，按顺序重命名IP组并在失败时回滚")
+	var undo []func(context.Context) error
+	renamed, err := r.renameIPGroup(ctx, id, oldName, newName, &undo)
+	if err != nil {
+		r.logger.Error().Err(err).Str("id", id.Hex()).Msg("重命名IP组时出错，回滚已完成的修改")
+		rollbackCtx := context.WithoutCancel(ctx)
+		for i := len(undo) - 1; i >= 0; i-- {
+			if undoErr := undo[i](rollbackCtx); undoErr != nil {
+				r.logger.Error().Err(undoErr).Str("id", id.Hex()).Msg("回滚IP组重命名失败")
+			}
+		}
+		return 0, err
+	}
+	return renamed, nil
+}
+
+// renameIPGroup 执行重命名，undo 不为空时记录每一步的回滚操作
+func (r *MongoIPGroupRepository) renameIPGroup(ctx context.Context, id bson.ObjectID, oldName, newName string, undo *[]func(context.Context) error) (int, error) {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: id}, {Key: "name", Value: oldName}},
+		bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: newName}}}},
+	)
+	if err != nil {
+		return 0, err
+	}
+	if result.MatchedCount == 0 {
+		return 0, ErrIPGroupNotFound
+	}
+	if undo != nil {
+		*undo = append(*undo, func(ctx context.Context) error {
+			_, err := r.collection.UpdateOne(ctx,
+				bson.D{{Key: "_id", Value: id}},
+				bson.D{{Key: "$set", Value: bson.D{{Key: "name", Value: oldName}}}},
+			)
+			return err
+		})
+	}
+
+	var rule model.MicroRule
+	rules := r.collection.Database().Collection(rule.GetCollectionName())
+
+	cursor, err := rules.Find(ctx, bson.D{{Key: "ip_group_refs", Value: oldName}})
+	if err != nil {
+		return 0, err
+	}
+	var dependents []model.MicroRule
+	if err := cursor.All(ctx, &dependents); err != nil {
+		return 0, err
+	}
+
+	for _, dependent := range dependents {
+		condition, _, err := model.RenameConditionIPGroup(dependent.Condition, oldName, newName)
+		if err != nil {
+			return 0, err
+		}
+		_, err = rules.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: dependent.ID}},
+			bson.D{{Key: "$set", Value: bson.D{
+				{Key: "condition", Value: condition},
+				{Key: "ip_group_refs", Value: model.ConditionIPGroupRefs(condition)},
+			}}},
+		)
+		if err != nil {
+			return 0, err
+		}
+		if undo != nil {
+			*undo = append(*undo, func(ctx context.Context) error {
+				_, err := rules.UpdateOne(ctx,
+					bson.D{{Key: "_id", Value: dependent.ID}},
+					bson.D{{Key: "$set", Value: bson.D{
+						{Key: "condition", Value: dependent.Condition},
+						{Key: "ip_group_refs", Value: dependent.IPGroupRefs},
+					}}},
+				)
+				return err
+			})
+		}
+	}
+
+	return len(dependents), nil
+}
+
+// isTransactionUnsupported 判断错误是否因为 MongoDB 单节点部署不支持事务
+func isTransactionUnsupported(err error) bool {
+	var serverErr mongo.ServerError
+	// IllegalOperation: Transaction numbers are only allowed on a replica set member or mongos
+	return errors.As(err, &serverErr) && serverErr.HasErrorCode(20)
+}
+
+// GetEdgeBlockIPGroups 获取启用前端拦截的全局IP组
+func GetEdgeBlockIPGroups(ctx context.Context, collection *mongo.Collection) ([]model.IPGroup, error) {
+	filter := bson.D{
+		{Key: "edge_block", Value: true},
+		{Key: "scope", Value: bson.D{{Key: "$exists", Value: false}}},
+	}
+
+	cursor, err := collection.Find(ctx, filter)
+	if err != nil {
+		config.Logger.Error().Err(err).Msg("查询启用前端拦截的IP组时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ipGroups []model.IPGroup
+	if err = cursor.All(ctx, &ipGroups); err != nil {
+		config.Logger.Error().Err(err).Msg("解析启用前端拦截的IP组时出错")
+		return nil, err
+	}
+	return ipGroups, nil
+}
+
+// GetAllIPGroups 获取所有IP组
+func GetAllIPGroups(ctx context.Context, collection *mongo.Collection) ([]model.IPGroup, error) {
+	cursor, err := collection.Find(ctx, bson.D{})
+	if err != nil {
+		config.Logger.Error().Err(err).Msg("查询所有IP组时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var ipGroups []model.IPGroup
+	if err = cursor.All(ctx, &ipGroups); err != nil {
+		config.Logger.Error().Err(err).Msg("解析所有IP组时出错")
+		return nil, err
+	}
+	return ipGroups, nil
+}
@@ -21,12 +21,17 @@ var (
 // MicroRuleRepository 微规则仓库接口
 type MicroRuleRepository interface {
 	CreateMicroRule(ctx context.Context, rule *model.MicroRule) error
-	GetMicroRules(ctx context.Context, page, size int64) ([]model.MicroRule, int64, error)
+	GetMicroRules(ctx context.Context, page, size int64, scopeFilter *SiteScopeFilter) ([]model.MicroRule, int64, error)
 	GetMicroRuleByID(ctx context.Context, id bson.ObjectID) (*model.MicroRule, error)
 	GetMicroRuleByName(ctx context.Context, name string) (*model.MicroRule, error)
 	UpdateMicroRule(ctx context.Context, rule *model.MicroRule) error
 	DeleteMicroRule(ctx context.Context, id bson.ObjectID) error
 	CheckMicroRuleNameExists(ctx context.Context, name string, excludeID bson.ObjectID) (bool, error)
+	GetExpiredMicroRules(ctx context.Context, now time.Time) ([]model.MicroRule, error)
+	DeleteExpiredMicroRule(ctx context.Context, id bson.ObjectID, now time.Time) (bool, error)
+	GetMicroRulesByIPGroup(ctx context.Context, ipGroupName string) ([]model.MicroRule, error)
+	GetMicroRulesWithIPGroupRefs(ctx context.Context) ([]model.MicroRule, error)
+	GetAllMicroRules(ctx context.Context) ([]model.MicroRule, error)
 }
 
 // MongoMicroRuleRepository MongoDB实现的微规则仓库
@@ -54,10 +59,34 @@ func NewMicroRuleRepository(db *mongo.Database) MicroRuleRepository {
 		logger.Error().Err(err).Msg("创建规则名称索引失败")
 	}
 
-	return &MongoMicroRuleRepository{
+	// 生效结束时间索引（用于过期清理）
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "schedule.active_until", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("创建规则生效结束时间索引失败")
+	}
+
+	// IP组引用索引（用于查询IP组的依赖）
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "ip_group_refs", Value: 1}},
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("创建规则IP组引用索引失败")
+	}
+
+	repo := &MongoMicroRuleRepository{
 		collection: collection,
 		logger:     logger,
 	}
+
+	// 为引用索引上线前保存的规则补充IP组引用
+	if err := repo.backfillIPGroupRefs(ctx); err != nil {
+		logger.Error().Err(err).Msg("补充规则IP组引用失败")
+	}
+
+	return repo
 }
 
 // CreateMicroRule 创建微规则
@@ -74,7 +103,7 @@ func (r *MongoMicroRuleRepository) CreateMicroRule(ctx context.Context, rule *mo
 }
 
 // GetMicroRules 获取微规则列表
-func (r *MongoMicroRuleRepository) GetMicroRules(ctx context.Context, page, size int64) ([]model.MicroRule, int64, error) {
+func (r *MongoMicroRuleRepository) GetMicroRules(ctx context.Context, page, size int64, scopeFilter *SiteScopeFilter) ([]model.MicroRule, int64, error) {
 	// 计算分页
 	skip := (page - 1) * size
 
@@ -84,8 +113,11 @@ func (r *MongoMicroRuleRepository) GetMicroRules(ctx context.Context, page, size
 		SetLimit(size).
 		SetSort(bson.D{{Key: "priority", Value: -1}})
 
+	// 按作用域过滤
+	filter := scopeFilter.toBson()
+
 	// 执行查询
-	cursor, err := r.collection.Find(ctx, bson.D{}, findOptions)
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
 	if err != nil {
 		r.logger.Error().Err(err).Msg("查询微规则列表时出错")
 		return nil, 0, err
@@ -100,7 +132,7 @@ func (r *MongoMicroRuleRepository) GetMicroRules(ctx context.Context, page, size
 	}
 
 	// 获取总数
-	total, err := r.collection.CountDocuments(ctx, bson.D{})
+	total, err := r.collection.CountDocuments(ctx, filter)
 	if err != nil {
 		r.logger.Error().Err(err).Msg("获取微规则总数时出错")
 		return nil, 0, err
@@ -188,3 +220,147 @@ func (r *MongoMicroRuleRepository) CheckMicroRuleNameExists(ctx context.Context,
 
 	return count > 0, nil
 }
+
+// GetExpiredMicroRules 获取生效结束时间不晚于 now 的微规则
+func (r *MongoMicroRuleRepository) GetExpiredMicroRules(ctx context.Context, now time.Time) ([]model.MicroRule, error) {
+	filter := bson.D{{Key: "schedule.active_until", Value: bson.D{{Key: "$lte", Value: now}}}}
+
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("查询过期微规则时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []model.MicroRule
+	if err = cursor.All(ctx, &rules); err != nil {
+		r.logger.Error().Err(err).Msg("解析过期微规则时出错")
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// DeleteExpiredMicroRule 删除仍处于过期状态的微规则，规则在查询后被修改为未过期时不删除
+func (r *MongoMicroRuleRepository) DeleteExpiredMicroRule(ctx context.Context, id bson.ObjectID, now time.Time) (bool, error) {
+	result, err := r.collection.DeleteOne(ctx, bson.D{
+		{Key: "_id", Value: id},
+		{Key: "schedule.active_until", Value: bson.D{{Key: "$lte", Value: now}}},
+	})
+	if err != nil {
+		r.logger.Error().Err(err).Str("id", id.Hex()).Msg("删除过期微规则时出错")
+		return false, err
+	}
+
+	return result.DeletedCount > 0, nil
+}
+
+// GetMicroRulesByIPGroup 获取条件中引用了指定IP组的微规则
+func (r *MongoMicroRuleRepository) GetMicroRulesByIPGroup(ctx context.Context, ipGroupName string) ([]model.MicroRule, error) {
+	filter := bson.D{{Key: "ip_group_refs", Value: ipGroupName}}
+	findOptions := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		r.logger.Error().Err(err).Str("ipGroup", ipGroupName).Msg("查询引用IP组的微规则时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []model.MicroRule
+	if err = cursor.All(ctx, &rules); err != nil {
+		r.logger.Error().Err(err).Str("ipGroup", ipGroupName).Msg("解析引用IP组的微规则时出错")
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// GetMicroRulesWithIPGroupRefs 获取所有引用了IP组的微规则
+func (r *MongoMicroRuleRepository) GetMicroRulesWithIPGroupRefs(ctx context.Context) ([]model.MicroRule, error) {
+	filter := bson.D{{Key: "ip_group_refs.0", Value: bson.D{{Key: "$exists", Value: true}}}}
+	findOptions := options.Find().SetSort(bson.D{{Key: "name", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("查询引用IP组的微规则时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []model.MicroRule
+	if err = cursor.All(ctx, &rules); err != nil {
+		r.logger.Error().Err(err).Msg("解析引用IP组的微规则时出错")
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// backfillIPGroupRefs 为缺少 ip_group_refs 字段的规则根据条件生成IP组引用
+func (r *MongoMicroRuleRepository) backfillIPGroupRefs(ctx context.Context) error {
+	cursor, err := r.collection.Find(ctx, bson.D{{Key: "ip_group_refs", Value: bson.D{{Key: "$exists", Value: false}}}})
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []model.MicroRule
+	if err = cursor.All(ctx, &rules); err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		_, err := r.collection.UpdateOne(ctx,
+			bson.D{{Key: "_id", Value: rule.ID}},
+			bson.D{{Key: "$set", Value: bson.D{{Key: "ip_group_refs", Value: model.ConditionIPGroupRefs(rule.Condition)}}}},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	if len(rules) > 0 {
+		r.logger.Info().Int("count", len(rules)).Msg("已补充规则的IP组引用")
+	}
+	return nil
+}
+
+// GetAllMicroRules 获取所有微规则，按优先级降序、创建顺序升序排列，与规则引擎的匹配顺序一致
+func (r *MongoMicroRuleRepository) GetAllMicroRules(ctx context.Context) ([]model.MicroRule, error) {
+	findOptions := options.Find().SetSort(model.MicroRuleMatchOrder())
+
+	cursor, err := r.collection.Find(ctx, bson.D{}, findOptions)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("查询所有微规则时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []model.MicroRule
+	if err = cursor.All(ctx, &rules); err != nil {
+		r.logger.Error().Err(err).Msg("解析所有微规则时出错")
+		return nil, err
+	}
+
+	return rules, nil
+}
+
+// GetAllMicroRules 获取所有微规则，按优先级降序、创建顺序升序排列，与规则引擎的匹配顺序一致
+func GetAllMicroRules(ctx context.Context, collection *mongo.Collection) ([]model.MicroRule, error) {
+	findOptions := options.Find().SetSort(model.MicroRuleMatchOrder())
+
+	cursor, err := collection.Find(ctx, bson.D{}, findOptions)
+	if err != nil {
+		config.Logger.Error().Err(err).Msg("查询所有微规则时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var rules []model.MicroRule
+	if err = cursor.All(ctx, &rules); err != nil {
+		config.Logger.Error().Err(err).Msg("解析所有微规则时出错")
+		return nil, err
+	}
+	return rules, nil
+}
@@ -0,0 +1,252 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+// RuleStatsQuery 规则命中统计查询条件
+type RuleStatsQuery struct {
+	StartTime time.Time
+	Source    model.RuleStatsSource // 为空表示全部来源
+	RuleID    string                // 为空表示全部规则
+	Unit      string                // $dateTrunc 单位: hour, day
+	BinSize   int                   // $dateTrunc 桶大小
+	Limit     int64
+}
+
+// RuleStatsRepository 规则命中统计仓库接口
+type RuleStatsRepository interface {
+	GetRuleTotals(ctx context.Context, source model.RuleStatsSource, ruleIDs []string) (map[string]dto.RuleHitStats, error)
+	GetRuleStatsHistory(ctx context.Context, query *RuleStatsQuery) ([]dto.RuleStatsItem, error)
+}
+
+// MongoRuleStatsRepository MongoDB实现的规则命中统计仓库
+type MongoRuleStatsRepository struct {
+	collection *mongo.Collection
+	logger     zerolog.Logger
+}
+
+// NewRuleStatsRepository 创建规则命中统计仓库
+func NewRuleStatsRepository(db *mongo.Database) RuleStatsRepository {
+	var ruleStats model.RuleStats
+	collection := db.Collection(ruleStats.GetCollectionName())
+	logger := config.GetRepositoryLogger("rule_stats")
+
+	// 创建索引
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 规则+统计桶唯一索引，供引擎 upsert 使用
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{
+			{Key: "source", Value: 1},
+			{Key: "rule_id", Value: 1},
+			{Key: "bucket", Value: 1},
+		},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("创建规则统计唯一索引失败")
+	}
+
+	// 统计桶时间索引（用于时间范围查询）
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "bucket", Value: -1}},
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("创建统计桶时间索引失败")
+	}
+
+	return &MongoRuleStatsRepository{
+		collection: collection,
+		logger:     logger,
+	}
+}
+
+// GetRuleTotals 获取指定规则的累计命中统计，返回以规则ID为键的映射
+func (r *MongoRuleStatsRepository) GetRuleTotals(ctx context.Context, source model.RuleStatsSource, ruleIDs []string) (map[string]dto.RuleHitStats, error) {
+	if len(ruleIDs) == 0 {
+		return make(map[string]dto.RuleHitStats), nil
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, ruleTotalsPipeline(source, ruleIDs))
+	if err != nil {
+		r.logger.Error().Err(err).Msg("聚合规则累计命中统计时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	totals, err := decodeRuleTotals(ctx, cursor)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("解析规则累计命中统计时出错")
+		return nil, err
+	}
+
+	return totals, nil
+}
+
+// GetRuleStatsHistory 获取时间范围内按命中次数排序的规则统计及其时间序列
+func (r *MongoRuleStatsRepository) GetRuleStatsHistory(ctx context.Context, query *RuleStatsQuery) ([]dto.RuleStatsItem, error) {
+	cursor, err := r.collection.Aggregate(ctx, ruleStatsHistoryPipeline(query), options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		r.logger.Error().Err(err).Msg("聚合规则命中时间序列时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	items, err := decodeRuleStatsHistory(ctx, cursor)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("解析规则命中时间序列时出错")
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// ruleTotalsPipeline 按规则ID汇总所有统计桶的聚合管道
+func ruleTotalsPipeline(source model.RuleStatsSource, ruleIDs []string) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$match", Value: bson.D{
+			{Key: "source", Value: source},
+			{Key: "rule_id", Value: bson.D{{Key: "$in", Value: ruleIDs}}},
+		}}},
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: "$rule_id"},
+			{Key: "hits", Value: bson.D{{Key: "$sum", Value: "$hits"}}},
+			{Key: "blocks", Value: bson.D{{Key: "$sum", Value: "$blocks"}}},
+			{Key: "lastHitAt", Value: bson.D{{Key: "$max", Value: "$last_hit_at"}}},
+		}}},
+	}
+}
+
+// decodeRuleTotals 解析累计命中统计，没有命中时间的规则 LastHitAt 为空
+func decodeRuleTotals(ctx context.Context, cursor *mongo.Cursor) (map[string]dto.RuleHitStats, error) {
+	var results []struct {
+		ID        string    `bson:"_id"`
+		Hits      int64     `bson:"hits"`
+		Blocks    int64     `bson:"blocks"`
+		LastHitAt time.Time `bson:"lastHitAt"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	totals := make(map[string]dto.RuleHitStats, len(results))
+	for _, result := range results {
+		stats := dto.RuleHitStats{
+			Hits:   result.Hits,
+			Blocks: result.Blocks,
+		}
+		if !result.LastHitAt.IsZero() {
+			lastHitAt := result.LastHitAt
+			stats.LastHitAt = &lastHitAt
+		}
+		totals[result.ID] = stats
+	}
+
+	return totals, nil
+}
+
+// ruleStatsHistoryPipeline 先按规则和时间桶聚合，再按规则汇总为时间序列的聚合管道
+func ruleStatsHistoryPipeline(query *RuleStatsQuery) mongo.Pipeline {
+	match := bson.D{{Key: "bucket", Value: bson.D{{Key: "$gte", Value: query.StartTime.Truncate(time.Hour)}}}}
+	if query.Source != "" {
+		match = append(match, bson.E{Key: "source", Value: query.Source})
+	}
+	if query.RuleID != "" {
+		match = append(match, bson.E{Key: "rule_id", Value: query.RuleID})
+	}
+
+	return mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		// 先按规则和时间桶聚合
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "source", Value: "$source"},
+				{Key: "ruleId", Value: "$rule_id"},
+				{Key: "timestamp", Value: bson.D{{Key: "$dateTrunc", Value: bson.D{
+					{Key: "date", Value: "$bucket"},
+					{Key: "unit", Value: query.Unit},
+					{Key: "binSize", Value: query.BinSize},
+				}}}},
+			}},
+			{Key: "ruleName", Value: bson.D{{Key: "$last", Value: "$rule_name"}}},
+			{Key: "hits", Value: bson.D{{Key: "$sum", Value: "$hits"}}},
+			{Key: "blocks", Value: bson.D{{Key: "$sum", Value: "$blocks"}}},
+			{Key: "lastHitAt", Value: bson.D{{Key: "$max", Value: "$last_hit_at"}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "_id.timestamp", Value: 1}}}},
+		// 再按规则汇总，生成时间序列
+		{{Key: "$group", Value: bson.D{
+			{Key: "_id", Value: bson.D{
+				{Key: "source", Value: "$_id.source"},
+				{Key: "ruleId", Value: "$_id.ruleId"},
+			}},
+			{Key: "ruleName", Value: bson.D{{Key: "$last", Value: "$ruleName"}}},
+			{Key: "hits", Value: bson.D{{Key: "$sum", Value: "$hits"}}},
+			{Key: "blocks", Value: bson.D{{Key: "$sum", Value: "$blocks"}}},
+			{Key: "lastHitAt", Value: bson.D{{Key: "$max", Value: "$lastHitAt"}}},
+			{Key: "history", Value: bson.D{{Key: "$push", Value: bson.D{
+				{Key: "timestamp", Value: "$_id.timestamp"},
+				{Key: "hits", Value: "$hits"},
+				{Key: "blocks", Value: "$blocks"},
+			}}}},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "hits", Value: -1}, {Key: "_id.ruleId", Value: 1}}}},
+		{{Key: "$limit", Value: query.Limit}},
+	}
+}
+
+// decodeRuleStatsHistory 解析规则命中时间序列
+func decodeRuleStatsHistory(ctx context.Context, cursor *mongo.Cursor) ([]dto.RuleStatsItem, error) {
+	var results []struct {
+		ID struct {
+			Source string `bson:"source"`
+			RuleID string `bson:"ruleId"`
+		} `bson:"_id"`
+		RuleName  string    `bson:"ruleName"`
+		Hits      int64     `bson:"hits"`
+		Blocks    int64     `bson:"blocks"`
+		LastHitAt time.Time `bson:"lastHitAt"`
+		History   []struct {
+			Timestamp time.Time `bson:"timestamp"`
+			Hits      int64     `bson:"hits"`
+			Blocks    int64     `bson:"blocks"`
+		} `bson:"history"`
+	}
+	if err := cursor.All(ctx, &results); err != nil {
+		return nil, err
+	}
+
+	items := make([]dto.RuleStatsItem, 0, len(results))
+	for _, result := range results {
+		history := make([]dto.RuleStatsDataPoint, 0, len(result.History))
+		for _, point := range result.History {
+			history = append(history, dto.RuleStatsDataPoint{
+				Timestamp: point.Timestamp,
+				Hits:      point.Hits,
+				Blocks:    point.Blocks,
+			})
+		}
+		items = append(items, dto.RuleStatsItem{
+			Source:    result.ID.Source,
+			RuleID:    result.ID.RuleID,
+			RuleName:  result.RuleName,
+			Hits:      result.Hits,
+			Blocks:    result.Blocks,
+			LastHitAt: result.LastHitAt,
+			History:   history,
+		})
+	}
+
+	return items, nil
+}
@@ -0,0 +1,151 @@
+package repository
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// TestRuleStatsHistoryPipeline 测试时间序列管道的过滤条件、时间桶粒度和返回数量
+func TestRuleStatsHistoryPipeline(t *testing.T) {
+	start := time.Date(2025, 6, 1, 12, 34, 56, 0, time.UTC)
+	bucket := bson.E{Key: "bucket", Value: bson.D{{Key: "$gte", Value: time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)}}}
+	for _, tt := range []struct {
+		name      string
+		query     RuleStatsQuery
+		wantMatch bson.D
+	}{
+		{
+			name:      "全部规则",
+			query:     RuleStatsQuery{StartTime: start, Unit: "hour", BinSize: 1, Limit: 20},
+			wantMatch: bson.D{bucket},
+		},
+		{
+			name:      "指定来源",
+			query:     RuleStatsQuery{StartTime: start, Source: model.RuleStatsSourceCoraza, Unit: "hour", BinSize: 6, Limit: 10},
+			wantMatch: bson.D{bucket, {Key: "source", Value: model.RuleStatsSourceCoraza}},
+		},
+		{
+			name:      "指定规则",
+			query:     RuleStatsQuery{StartTime: start, Source: model.RuleStatsSourceMicro, RuleID: "rule-1", Unit: "day", BinSize: 1, Limit: 1},
+			wantMatch: bson.D{bucket, {Key: "source", Value: model.RuleStatsSourceMicro}, {Key: "rule_id", Value: "rule-1"}},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			pipeline := ruleStatsHistoryPipeline(&tt.query)
+			if match := pipeline[0][0]; match.Key != "$match" || !reflect.DeepEqual(match.Value, tt.wantMatch) {
+				t.Errorf("$match = %v, want %v", match.Value, tt.wantMatch)
+			}
+
+			group := pipeline[1][0].Value.(bson.D)
+			dateTrunc := group[0].Value.(bson.D)[2].Value.(bson.D)[0].Value.(bson.D)
+			wantTrunc := bson.D{{Key: "date", Value: "$bucket"}, {Key: "unit", Value: tt.query.Unit}, {Key: "binSize", Value: tt.query.BinSize}}
+			if !reflect.DeepEqual(dateTrunc, wantTrunc) {
+				t.Errorf("$dateTrunc = %v, want %v", dateTrunc, wantTrunc)
+			}
+
+			if limit := pipeline[len(pipeline)-1][0]; limit.Key != "$limit" || limit.Value != tt.query.Limit {
+				t.Errorf("last stage = %v, want $limit %d", limit, tt.query.Limit)
+			}
+		})
+	}
+}
+
+// TestRuleTotalsPipeline 测试累计统计只匹配指定来源和规则
+func TestRuleTotalsPipeline(t *testing.T) {
+	pipeline := ruleTotalsPipeline(model.RuleStatsSourceMicro, []string{"a", "b"})
+	want := bson.D{
+		{Key: "source", Value: model.RuleStatsSourceMicro},
+		{Key: "rule_id", Value: bson.D{{Key: "$in", Value: []string{"a", "b"}}}},
+	}
+	if match := pipeline[0][0]; match.Key != "$match" || !reflect.DeepEqual(match.Value, want) {
+		t.Errorf("$match = %v, want %v", match.Value, want)
+	}
+}
+
+// TestDecodeRuleTotals 测试解析累计统计，没有命中时间时 LastHitAt 为空
+func TestDecodeRuleTotals(t *testing.T) {
+	lastHitAt := time.Date(2025, 6, 1, 12, 30, 0, 0, time.UTC)
+	cursor, err := mongo.NewCursorFromDocuments([]any{
+		bson.D{{Key: "_id", Value: "a"}, {Key: "hits", Value: int64(10)}, {Key: "blocks", Value: int64(4)}, {Key: "lastHitAt", Value: lastHitAt}},
+		bson.D{{Key: "_id", Value: "b"}, {Key: "hits", Value: int64(0)}, {Key: "blocks", Value: int64(0)}, {Key: "lastHitAt", Value: nil}},
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewCursorFromDocuments() error = %v", err)
+	}
+
+	totals, err := decodeRuleTotals(context.Background(), cursor)
+	if err != nil {
+		t.Fatalf("decodeRuleTotals() error = %v", err)
+	}
+	if got := totals["a"]; got.Hits != 10 || got.Blocks != 4 || got.LastHitAt == nil || !got.LastHitAt.Equal(lastHitAt) {
+		t.Errorf("totals[a] = %+v, want 10 hits, 4 blocks, last hit %s", got, lastHitAt)
+	}
+	if got := totals["b"]; got.LastHitAt != nil {
+		t.Errorf("totals[b].LastHitAt = %v, want nil", got.LastHitAt)
+	}
+	if len(totals) != 2 {
+		t.Errorf("totals = %d, want 2", len(totals))
+	}
+}
+
+// TestDecodeRuleStatsHistory 测试解析按规则汇总的时间序列
+func TestDecodeRuleStatsHistory(t *testing.T) {
+	first := time.Date(2025, 6, 1, 11, 0, 0, 0, time.UTC)
+	second := first.Add(time.Hour)
+	cursor, err := mongo.NewCursorFromDocuments([]any{
+		bson.D{
+			{Key: "_id", Value: bson.D{{Key: "source", Value: "micro"}, {Key: "ruleId", Value: "rule-1"}}},
+			{Key: "ruleName", Value: "block-admin"},
+			{Key: "hits", Value: int64(5)},
+			{Key: "blocks", Value: int64(3)},
+			{Key: "lastHitAt", Value: second.Add(time.Minute)},
+			{Key: "history", Value: bson.A{
+				bson.D{{Key: "timestamp", Value: first}, {Key: "hits", Value: int64(2)}, {Key: "blocks", Value: int64(1)}},
+				bson.D{{Key: "timestamp", Value: second}, {Key: "hits", Value: int64(3)}, {Key: "blocks", Value: int64(2)}},
+			}},
+		},
+	}, nil, nil)
+	if err != nil {
+		t.Fatalf("NewCursorFromDocuments() error = %v", err)
+	}
+
+	items, err := decodeRuleStatsHistory(context.Background(), cursor)
+	if err != nil {
+		t.Fatalf("decodeRuleStatsHistory() error = %v", err)
+	}
+	want := []dto.RuleStatsItem{{
+		Source:    "micro",
+		RuleID:    "rule-1",
+		RuleName:  "block-admin",
+		Hits:      5,
+		Blocks:    3,
+		LastHitAt: second.Add(time.Minute),
+		History: []dto.RuleStatsDataPoint{
+			{Timestamp: first, Hits: 2, Blocks: 1},
+			{Timestamp: second, Hits: 3, Blocks: 2},
+		},
+	}}
+	if len(items) != 1 {
+		t.Fatalf("items = %d, want 1", len(items))
+	}
+	got := items[0]
+	if got.Source != want[0].Source || got.RuleID != want[0].RuleID || got.RuleName != want[0].RuleName ||
+		got.Hits != want[0].Hits || got.Blocks != want[0].Blocks || !got.LastHitAt.Equal(want[0].LastHitAt) {
+		t.Errorf("item = %+v, want %+v", got, want[0])
+	}
+	if len(got.History) != len(want[0].History) {
+		t.Fatalf("history = %+v, want %+v", got.History, want[0].History)
+	}
+	for i, point := range got.History {
+		if wantPoint := want[0].History[i]; !point.Timestamp.Equal(wantPoint.Timestamp) || point.Hits != wantPoint.Hits || point.Blocks != wantPoint.Blocks {
+			t.Errorf("history[%d] = %+v, want %+v", i, point, wantPoint)
+		}
+	}
+}
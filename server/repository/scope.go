@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// SiteScopeFilter 站点作用域过滤条件，用于查询作用于某个站点的规则或IP组
+type SiteScopeFilter struct {
+	SiteID        string   // 站点ID
+	Hosts         []string // 可匹配站点域名的主机名模式
+	IncludeGlobal bool     // 是否包含对所有站点生效的记录
+}
+
+// toBson 构建查询条件，filter 为 nil 时不过滤
+func (f *SiteScopeFilter) toBson() bson.D {
+	if f == nil {
+		return bson.D{}
+	}
+
+	conditions := bson.A{
+		bson.D{{Key: "scope.site_ids", Value: f.SiteID}},
+	}
+	if len(f.Hosts) > 0 {
+		conditions = append(conditions, bson.D{{Key: "scope.hosts", Value: bson.D{{Key: "$in", Value: f.Hosts}}}})
+	}
+	if f.IncludeGlobal {
+		// 未设置作用域，或站点ID和主机名模式均为空
+		conditions = append(conditions, bson.D{
+			{Key: "scope.site_ids", Value: bson.D{{Key: "$in", Value: bson.A{nil, bson.A{}}}}},
+			{Key: "scope.hosts", Value: bson.D{{Key: "$in", Value: bson.A{nil, bson.A{}}}}},
+		})
+	}
+
+	return bson.D{{Key: "$or", Value: conditions}}
+}
@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"slices"
 	"time"
 
 	"github.com/HUAHUAI23/RuiQi/server/config"
@@ -27,6 +28,7 @@ type SiteRepository interface {
 	GetSiteByID(ctx context.Context, id bson.ObjectID) (*model.Site, error)
 	UpdateSite(ctx context.Context, site *model.Site) error
 	DeleteSite(ctx context.Context, id bson.ObjectID) error
+	GetCertificateSites(ctx context.Context) ([]model.Site, error)
 	CheckDomainPortExists(ctx context.Context, site *model.Site) error
 	CheckDomainPortConflict(ctx context.Context, site *model.Site) error
 }
@@ -151,10 +153,31 @@ func (r *MongoSiteRepository) DeleteSite(ctx context.Context, id bson.ObjectID)
 	return nil
 }
 
+// GetCertificateSites 获取启用了 HTTPS 或指定了证书的站点，证书内容不从证书库填充
+func (r *MongoSiteRepository) GetCertificateSites(ctx context.Context) ([]model.Site, error) {
+	filter := bson.D{{Key: "$or", Value: bson.A{
+		bson.D{{Key: "enableHTTPS", Value: true}},
+		bson.D{{Key: "certificateId", Value: bson.D{{Key: "$exists", Value: true}}}},
+	}}}
+	cursor, err := r.collection.Find(ctx, filter)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("查询使用证书的站点时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var sites []model.Site
+	if err = cursor.All(ctx, &sites); err != nil {
+		r.logger.Error().Err(err).Msg("解析使用证书的站点时出错")
+		return nil, err
+	}
+	return sites, nil
+}
+
 func (r *MongoSiteRepository) CheckDomainPortExists(ctx context.Context, site *model.Site) error {
 	// 检查域名和端口组合是否已存在
 	filter := bson.D{
-		{Key: "domain", Value: site.Domain},
+		{Key: "$or", Value: hostnameConflictFilter(site)},
 		{Key: "listenPort", Value: site.ListenPort},
 	}
 	count, err := r.collection.CountDocuments(ctx, filter)
@@ -173,7 +196,7 @@ func (r *MongoSiteRepository) CheckDomainPortConflict(ctx context.Context, site
 	// 检查域名和端口组合是否与其他站点冲突
 	filter := bson.D{
 		{Key: "_id", Value: bson.D{{Key: "$ne", Value: site.ID}}},
-		{Key: "domain", Value: site.Domain},
+		{Key: "$or", Value: hostnameConflictFilter(site)},
 		{Key: "listenPort", Value: site.ListenPort},
 	}
 	count, err := r.collection.CountDocuments(ctx, filter)
@@ -188,7 +211,16 @@ func (r *MongoSiteRepository) CheckDomainPortConflict(ctx context.Context, site
 	return nil
 }
 
-// GetAllSites 获取所有站点，不分页
+// hostnameConflictFilter 匹配主域名或其他域名与该站点任一主机名相同的站点
+func hostnameConflictFilter(site *model.Site) bson.A {
+	hostnames := site.Hostnames()
+	return bson.A{
+		bson.D{{Key: "domain", Value: bson.D{{Key: "$in", Value: hostnames}}}},
+		bson.D{{Key: "aliases", Value: bson.D{{Key: "$in", Value: hostnames}}}},
+	}
+}
+
+// GetAllSites 获取所有站点，不分页；启用 HTTPS 的站点从同一数据库的证书库中填充生效的证书
 func GetAllSites(ctx context.Context, collection *mongo.Collection) ([]model.Site, error) {
 	// 设置查询选项，按创建时间降序排序
 	findOptions := options.Find().
@@ -209,5 +241,34 @@ func GetAllSites(ctx context.Context, collection *mongo.Collection) ([]model.Sit
 		return nil, err
 	}
 
+	if err := resolveSiteCertificates(ctx, collection.Database(), sites); err != nil {
+		config.Logger.Error().Err(err).Msg("查询站点证书时出错")
+		return nil, err
+	}
 	return sites, nil
 }
+
+// resolveSiteCertificates 为启用 HTTPS 的站点选择证书库中的证书，并将选中的证书ID写入站点
+// 证书库中没有匹配的证书时保留站点内嵌的证书
+func resolveSiteCertificates(ctx context.Context, db *mongo.Database, sites []model.Site) error {
+	if !slices.ContainsFunc(sites, func(site model.Site) bool { return site.EnableHTTPS }) {
+		return nil
+	}
+
+	var cert model.CertificateStore
+	certs, err := GetAllCertificates(ctx, db.Collection(cert.GetCollectionName()))
+	if err != nil {
+		return err
+	}
+	for i := range sites {
+		site := &sites[i]
+		if !site.EnableHTTPS {
+			continue
+		}
+		if selected := model.SelectSiteCertificate(site, certs); selected != nil {
+			site.CertificateID = selected.ID
+			site.Certificate = selected.SiteCertificate()
+		}
+	}
+	return nil
+}
@@ -0,0 +1,241 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrThreatFeedNotFound = errors.New("威胁情报源不存在")
+)
+
+// ThreatFeedRepository 威胁情报源仓库接口
+type ThreatFeedRepository interface {
+	CreateThreatFeed(ctx context.Context, feed *model.ThreatFeed) error
+	GetThreatFeeds(ctx context.Context, page, size int64) ([]model.ThreatFeed, int64, error)
+	GetThreatFeedByID(ctx context.Context, id bson.ObjectID) (*model.ThreatFeed, error)
+	UpdateThreatFeed(ctx context.Context, feed *model.ThreatFeed) error
+	DeleteThreatFeed(ctx context.Context, id bson.ObjectID) error
+	CheckThreatFeedNameExists(ctx context.Context, name string, excludeID bson.ObjectID) (bool, error)
+	GetDueThreatFeeds(ctx context.Context, now time.Time) ([]model.ThreatFeed, error)
+	UpdateThreatFeedSyncState(ctx context.Context, feed *model.ThreatFeed) error
+}
+
+// MongoThreatFeedRepository MongoDB实现的威胁情报源仓库
+type MongoThreatFeedRepository struct {
+	collection *mongo.Collection
+	logger     zerolog.Logger
+}
+
+// NewThreatFeedRepository 创建威胁情报源仓库
+func NewThreatFeedRepository(db *mongo.Database) ThreatFeedRepository {
+	var feed model.ThreatFeed
+	collection := db.Collection(feed.GetCollectionName())
+	logger := config.GetRepositoryLogger("threatfeed")
+
+	// 创建索引
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 名称唯一索引
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("创建威胁情报源名称索引失败")
+	}
+
+	// 下次拉取时间索引（用于定时同步）
+	_, err = collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "enabled", Value: 1}, {Key: "status.nextFetchAt", Value: 1}},
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("创建威胁情报源拉取时间索引失败")
+	}
+
+	return &MongoThreatFeedRepository{
+		collection: collection,
+		logger:     logger,
+	}
+}
+
+// CreateThreatFeed 创建威胁情报源
+func (r *MongoThreatFeedRepository) CreateThreatFeed(ctx context.Context, feed *model.ThreatFeed) error {
+	now := time.Now()
+	feed.CreatedAt = now
+	feed.UpdatedAt = now
+
+	result, err := r.collection.InsertOne(ctx, feed)
+	if err != nil {
+		r.logger.Error().Err(err).Str("name", feed.Name).Msg("插入威胁情报源时出错")
+		return err
+	}
+
+	feed.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// GetThreatFeeds 获取威胁情报源列表
+func (r *MongoThreatFeedRepository) GetThreatFeeds(ctx context.Context, page, size int64) ([]model.ThreatFeed, int64, error) {
+	skip := (page - 1) * size
+
+	findOptions := options.Find().
+		SetSkip(skip).
+		SetLimit(size).
+		SetSort(bson.D{{Key: "name", Value: 1}}) // 按名称升序排序
+
+	cursor, err := r.collection.Find(ctx, bson.D{}, findOptions)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("查询威胁情报源列表时出错")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var feeds []model.ThreatFeed
+	if err = cursor.All(ctx, &feeds); err != nil {
+		r.logger.Error().Err(err).Msg("解析威胁情报源列表时出错")
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, bson.D{})
+	if err != nil {
+		r.logger.Error().Err(err).Msg("获取威胁情报源总数时出错")
+		return nil, 0, err
+	}
+
+	return feeds, total, nil
+}
+
+// GetThreatFeedByID 根据ID获取威胁情报源
+func (r *MongoThreatFeedRepository) GetThreatFeedByID(ctx context.Context, id bson.ObjectID) (*model.ThreatFeed, error) {
+	var feed model.ThreatFeed
+	err := r.collection.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&feed)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrThreatFeedNotFound
+		}
+		r.logger.Error().Err(err).Str("id", id.Hex()).Msg("查询威胁情报源时出错")
+		return nil, err
+	}
+
+	return &feed, nil
+}
+
+// UpdateThreatFeed 更新威胁情报源配置
+// 只更新配置字段和下次拉取时间，避免覆盖同步任务并发写入的同步状态
+func (r *MongoThreatFeedRepository) UpdateThreatFeed(ctx context.Context, feed *model.ThreatFeed) error {
+	feed.UpdatedAt = time.Now()
+
+	result, err := r.collection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: feed.ID}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "name", Value: feed.Name},
+			{Key: "source", Value: feed.Source},
+			{Key: "format", Value: feed.Format},
+			{Key: "csvColumn", Value: feed.CSVColumn},
+			{Key: "csvHeader", Value: feed.CSVHeader},
+			{Key: "refreshInterval", Value: feed.RefreshInterval},
+			{Key: "enabled", Value: feed.Enabled},
+			{Key: "status.nextFetchAt", Value: feed.Status.NextFetchAt},
+			{Key: "status.etag", Value: feed.Status.ETag},
+			{Key: "status.lastModified", Value: feed.Status.LastModified},
+			{Key: "updatedAt", Value: feed.UpdatedAt},
+		}}},
+	)
+	if err != nil {
+		r.logger.Error().Err(err).Str("id", feed.ID.Hex()).Msg("更新威胁情报源时出错")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrThreatFeedNotFound
+	}
+
+	return nil
+}
+
+// DeleteThreatFeed 删除威胁情报源
+func (r *MongoThreatFeedRepository) DeleteThreatFeed(ctx context.Context, id bson.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		r.logger.Error().Err(err).Str("id", id.Hex()).Msg("删除威胁情报源时出错")
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrThreatFeedNotFound
+	}
+
+	return nil
+}
+
+// CheckThreatFeedNameExists 检查威胁情报源名称是否已存在
+func (r *MongoThreatFeedRepository) CheckThreatFeedNameExists(ctx context.Context, name string, excludeID bson.ObjectID) (bool, error) {
+	filter := bson.D{{Key: "name", Value: name}}
+
+	// 如果是更新操作，需要排除当前情报源ID
+	if excludeID != bson.NilObjectID {
+		filter = append(filter, bson.E{Key: "_id", Value: bson.D{{Key: "$ne", Value: excludeID}}})
+	}
+
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		r.logger.Error().Err(err).Str("name", name).Msg("检查威胁情报源名称是否存在时出错")
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// GetDueThreatFeeds 获取已启用且到达下次拉取时间的威胁情报源
+func (r *MongoThreatFeedRepository) GetDueThreatFeeds(ctx context.Context, now time.Time) ([]model.ThreatFeed, error) {
+	filter := bson.D{
+		{Key: "enabled", Value: true},
+		{Key: "status.nextFetchAt", Value: bson.D{{Key: "$lte", Value: now}}},
+	}
+	findOptions := options.Find().SetSort(bson.D{{Key: "status.nextFetchAt", Value: 1}})
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("查询待同步的威胁情报源时出错")
+		return nil, err
+	}
+	defer cursor.Close(ctx)
+
+	var feeds []model.ThreatFeed
+	if err = cursor.All(ctx, &feeds); err != nil {
+		r.logger.Error().Err(err).Msg("解析待同步的威胁情报源时出错")
+		return nil, err
+	}
+
+	return feeds, nil
+}
+
+// UpdateThreatFeedSyncState 更新同步状态和同步的IP组
+func (r *MongoThreatFeedRepository) UpdateThreatFeedSyncState(ctx context.Context, feed *model.ThreatFeed) error {
+	result, err := r.collection.UpdateOne(ctx,
+		bson.D{{Key: "_id", Value: feed.ID}},
+		bson.D{{Key: "$set", Value: bson.D{
+			{Key: "ipGroupId", Value: feed.IPGroupID},
+			{Key: "ipGroupName", Value: feed.IPGroupName},
+			{Key: "status", Value: feed.Status},
+		}}},
+	)
+	if err != nil {
+		r.logger.Error().Err(err).Str("id", feed.ID.Hex()).Msg("更新威胁情报源同步状态时出错")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrThreatFeedNotFound
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+	"go.mongodb.org/mongo-driver/v2/mongo/options"
+)
+
+var (
+	ErrTrapPathNotFound = errors.New("蜜罐陷阱路径不存在")
+)
+
+// TrapPathRepository 蜜罐陷阱路径仓库接口
+type TrapPathRepository interface {
+	CreateTrapPath(ctx context.Context, trap *model.TrapPath) error
+	GetTrapPaths(ctx context.Context, page, size int64, scopeFilter *SiteScopeFilter) ([]model.TrapPath, int64, error)
+	GetTrapPathByID(ctx context.Context, id bson.ObjectID) (*model.TrapPath, error)
+	UpdateTrapPath(ctx context.Context, trap *model.TrapPath) error
+	DeleteTrapPath(ctx context.Context, id bson.ObjectID) error
+	CheckTrapPathNameExists(ctx context.Context, name string, excludeID bson.ObjectID) (bool, error)
+}
+
+// MongoTrapPathRepository MongoDB实现的蜜罐陷阱路径仓库
+type MongoTrapPathRepository struct {
+	collection *mongo.Collection
+	logger     zerolog.Logger
+}
+
+// NewTrapPathRepository 创建蜜罐陷阱路径仓库
+func NewTrapPathRepository(db *mongo.Database) TrapPathRepository {
+	var trap model.TrapPath
+	collection := db.Collection(trap.GetCollectionName())
+	logger := config.GetRepositoryLogger("trappath")
+
+	// 创建索引
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	// 名称唯一索引
+	_, err := collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		logger.Error().Err(err).Msg("创建蜜罐陷阱路径名称索引失败")
+	}
+
+	return &MongoTrapPathRepository{
+		collection: collection,
+		logger:     logger,
+	}
+}
+
+// CreateTrapPath 创建蜜罐陷阱路径
+func (r *MongoTrapPathRepository) CreateTrapPath(ctx context.Context, trap *model.TrapPath) error {
+	now := time.Now()
+	trap.CreatedAt = now
+	trap.UpdatedAt = now
+
+	result, err := r.collection.InsertOne(ctx, trap)
+	if err != nil {
+		r.logger.Error().Err(err).Str("name", trap.Name).Msg("插入蜜罐陷阱路径时出错")
+		return err
+	}
+
+	trap.ID = result.InsertedID.(bson.ObjectID)
+	return nil
+}
+
+// GetTrapPaths 获取蜜罐陷阱路径列表
+func (r *MongoTrapPathRepository) GetTrapPaths(ctx context.Context, page, size int64, scopeFilter *SiteScopeFilter) ([]model.TrapPath, int64, error) {
+	skip := (page - 1) * size
+
+	findOptions := options.Find().
+		SetSkip(skip).
+		SetLimit(size).
+		SetSort(bson.D{{Key: "name", Value: 1}}) // 按名称升序排序
+
+	// 按作用域过滤
+	filter := scopeFilter.toBson()
+
+	cursor, err := r.collection.Find(ctx, filter, findOptions)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("查询蜜罐陷阱路径列表时出错")
+		return nil, 0, err
+	}
+	defer cursor.Close(ctx)
+
+	var traps []model.TrapPath
+	if err = cursor.All(ctx, &traps); err != nil {
+		r.logger.Error().Err(err).Msg("解析蜜罐陷阱路径列表时出错")
+		return nil, 0, err
+	}
+
+	total, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("获取蜜罐陷阱路径总数时出错")
+		return nil, 0, err
+	}
+
+	return traps, total, nil
+}
+
+// GetTrapPathByID 根据ID获取蜜罐陷阱路径
+func (r *MongoTrapPathRepository) GetTrapPathByID(ctx context.Context, id bson.ObjectID) (*model.TrapPath, error) {
+	var trap model.TrapPath
+	err := r.collection.FindOne(ctx, bson.D{{Key: "_id", Value: id}}).Decode(&trap)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, ErrTrapPathNotFound
+		}
+		r.logger.Error().Err(err).Str("id", id.Hex()).Msg("查询蜜罐陷阱路径时出错")
+		return nil, err
+	}
+
+	return &trap, nil
+}
+
+// UpdateTrapPath 更新蜜罐陷阱路径
+func (r *MongoTrapPathRepository) UpdateTrapPath(ctx context.Context, trap *model.TrapPath) error {
+	trap.UpdatedAt = time.Now()
+
+	result, err := r.collection.ReplaceOne(ctx, bson.D{{Key: "_id", Value: trap.ID}}, trap)
+	if err != nil {
+		r.logger.Error().Err(err).Str("id", trap.ID.Hex()).Msg("更新蜜罐陷阱路径时出错")
+		return err
+	}
+	if result.MatchedCount == 0 {
+		return ErrTrapPathNotFound
+	}
+
+	return nil
+}
+
+// DeleteTrapPath 删除蜜罐陷阱路径
+func (r *MongoTrapPathRepository) DeleteTrapPath(ctx context.Context, id bson.ObjectID) error {
+	result, err := r.collection.DeleteOne(ctx, bson.D{{Key: "_id", Value: id}})
+	if err != nil {
+		r.logger.Error().Err(err).Str("id", id.Hex()).Msg("删除蜜罐陷阱路径时出错")
+		return err
+	}
+
+	if result.DeletedCount == 0 {
+		return ErrTrapPathNotFound
+	}
+
+	return nil
+}
+
+// CheckTrapPathNameExists 检查蜜罐陷阱路径名称是否已存在
+func (r *MongoTrapPathRepository) CheckTrapPathNameExists(ctx context.Context, name string, excludeID bson.ObjectID) (bool, error) {
+	filter := bson.D{{Key: "name", Value: name}}
+
+	// 如果是更新操作，需要排除当前陷阱路径ID
+	if excludeID != bson.NilObjectID {
+		filter = append(filter, bson.E{Key: "_id", Value: bson.D{{Key: "$ne", Value: excludeID}}})
+	}
+
+	count, err := r.collection.CountDocuments(ctx, filter)
+	if err != nil {
+		r.logger.Error().Err(err).Str("name", name).Msg("检查蜜罐陷阱路径名称是否存在时出错")
+		return false, err
+	}
+
+	return count > 0, nil
+}
@@ -6,6 +6,8 @@ import (
 	"github.com/HUAHUAI23/RuiQi/server/model"
 	"github.com/HUAHUAI23/RuiQi/server/repository"
 	"github.com/HUAHUAI23/RuiQi/server/service"
+	"github.com/HUAHUAI23/RuiQi/server/service/acme"
+	"github.com/HUAHUAI23/RuiQi/server/service/threatfeed"
 
 	"github.com/gin-gonic/gin"
 	"go.mongodb.org/mongo-driver/v2/mongo"
@@ -29,18 +31,28 @@ func Setup(route *gin.Engine, db *mongo.Database) {
 	ipGroupRepo := repository.NewIPGroupRepository(db)
 	ruleRepo := repository.NewMicroRuleRepository(db)
 	blockedIPRepo := repository.NewBlockedIPRepository(db)
+	ruleStatsRepo := repository.NewRuleStatsRepository(db)
+	auditLogRepo := repository.NewAuditLogRepository(db)
+	threatFeedRepo := repository.NewThreatFeedRepository(db)
+	trapPathRepo := repository.NewTrapPathRepository(db)
+	acmeAccountRepo := repository.NewACMEAccountRepository(db)
 
 	// 创建服务
 	authService := service.NewAuthService(userRepo, roleRepo)
-	siteService := service.NewSiteService(siteRepo)
-	wafLogService := service.NewWAFLogService(wafLogRepo)
-	certService := service.NewCertificateService(certRepo)
 	runnerService, _ := service.NewRunnerService()
+	siteService := service.NewSiteService(siteRepo, certRepo, runnerService)
+	wafLogService := service.NewWAFLogService(wafLogRepo)
+	acmeManager := acme.NewManager(certRepo, acmeAccountRepo)
+	certService := service.NewCertificateService(certRepo, siteRepo, runnerService, acmeManager)
 	configService := service.NewConfigService(configRepo)
-	ipGroupService := service.NewIPGroupService(ipGroupRepo)
-	ruleService := service.NewMicroRuleService(ruleRepo)
-	statsService := service.NewStatsService(wafLogRepo)
+	ipGroupService := service.NewIPGroupService(ipGroupRepo, siteRepo, ruleRepo)
+	ruleService := service.NewMicroRuleService(ruleRepo, ruleStatsRepo, siteRepo, ipGroupRepo)
+	statsService := service.NewStatsService(wafLogRepo, ruleStatsRepo)
 	blockedIPService := service.NewBlockedIPService(blockedIPRepo)
+	auditLogService := service.NewAuditLogService(auditLogRepo)
+	threatFeedSyncer := threatfeed.NewSyncer(threatFeedRepo, ipGroupRepo, auditLogRepo, threatfeed.NewFetcher(nil))
+	threatFeedService := service.NewThreatFeedService(threatFeedRepo, ipGroupRepo, threatFeedSyncer)
+	trapPathService := service.NewTrapPathService(trapPathRepo, siteRepo)
 	// 创建控制器
 	authController := controller.NewAuthController(authService)
 	siteController := controller.NewSiteController(siteService)
@@ -52,6 +64,9 @@ func Setup(route *gin.Engine, db *mongo.Database) {
 	ruleController := controller.NewMicroRuleController(ruleService)
 	statsController := controller.NewStatsController(runnerService, statsService)
 	blockedIPController := controller.NewBlockedIPController(blockedIPService)
+	auditLogController := controller.NewAuditLogController(auditLogService)
+	threatFeedController := controller.NewThreatFeedController(threatFeedService)
+	trapPathController := controller.NewTrapPathController(trapPathService)
 	// 将仓库添加到上下文中，供中间件使用
 	route.Use(func(c *gin.Context) {
 		c.Set("userRepo", userRepo)
@@ -64,6 +79,9 @@ func Setup(route *gin.Engine, db *mongo.Database) {
 		c.JSON(200, gin.H{"status": "ok"})
 	})
 
+	// ACME HTTP-01 验证，HAProxy 将所有站点上的验证请求转发到这里 - 不需要认证
+	route.GET(acme.HTTP01ChallengePath+":token", gin.WrapH(acme.HTTP01Handler()))
+
 	// API v1 路由
 	api := route.Group("/api/v1")
 
@@ -120,6 +138,11 @@ func Setup(route *gin.Engine, db *mongo.Database) {
 		siteRoutes.PUT("/:id", middleware.HasPermission(model.PermSiteUpdate), siteController.UpdateSite)
 		// 删除站点 - 需要site:delete权限
 		siteRoutes.DELETE("/:id", middleware.HasPermission(model.PermSiteDelete), siteController.DeleteSite)
+		// 运行时管理后端服务器，无需重新加载配置 - 需要site:update权限
+		siteRoutes.POST("/:id/servers", middleware.HasPermission(model.PermSiteUpdate), siteController.AddServer)
+		siteRoutes.DELETE("/:id/servers/:server", middleware.HasPermission(model.PermSiteUpdate), siteController.RemoveServer)
+		siteRoutes.PUT("/:id/servers/:server/state", middleware.HasPermission(model.PermSiteUpdate), siteController.SetServerState)
+		siteRoutes.PUT("/:id/servers/:server/weight", middleware.HasPermission(model.PermSiteUpdate), siteController.SetServerWeight)
 	}
 
 	// 证书管理路由
@@ -127,9 +150,13 @@ func Setup(route *gin.Engine, db *mongo.Database) {
 	{
 		certRoutes.POST("", middleware.HasPermission(model.PermCertCreate), certController.CreateCertificate)
 		certRoutes.GET("", middleware.HasPermission(model.PermCertRead), certController.GetCertificates)
+		certRoutes.GET("/expiry-summary", middleware.HasPermission(model.PermCertRead), certController.GetExpirySummary)
 		certRoutes.GET("/:id", middleware.HasPermission(model.PermCertRead), certController.GetCertificateByID)
 		certRoutes.PUT("/:id", middleware.HasPermission(model.PermCertUpdate), certController.UpdateCertificate)
 		certRoutes.DELETE("/:id", middleware.HasPermission(model.PermCertDelete), certController.DeleteCertificate)
+		// ACME 自动签发和立即续期
+		certRoutes.POST("/acme", middleware.HasPermission(model.PermCertCreate), certController.IssueACMECertificate)
+		certRoutes.POST("/:id/renew", middleware.HasPermission(model.PermCertUpdate), certController.RenewCertificate)
 	}
 
 	// IP组管理路由
@@ -142,6 +169,35 @@ func Setup(route *gin.Engine, db *mongo.Database) {
 		ipGroupRoutes.DELETE("/:id", middleware.HasPermission(model.PermConfigUpdate), ipGroupController.DeleteIPGroup)
 		// 添加IP到系统默认黑名单
 		ipGroupRoutes.POST("/blacklist/add", middleware.HasPermission(model.PermConfigUpdate), ipGroupController.AddIPToBlacklist)
+		// 批量导入导出
+		ipGroupRoutes.POST("/import", middleware.HasPermission(model.PermConfigUpdate), ipGroupController.CreateIPGroupFromImport)
+		ipGroupRoutes.POST("/:id/import", middleware.HasPermission(model.PermConfigUpdate), ipGroupController.ImportIPGroup)
+		ipGroupRoutes.GET("/:id/export", middleware.HasPermission(model.PermConfigRead), ipGroupController.ExportIPGroup)
+		// 规则引用
+		ipGroupRoutes.GET("/references", middleware.HasPermission(model.PermConfigRead), ipGroupController.GetAllIPGroupReferences)
+		ipGroupRoutes.GET("/:id/references", middleware.HasPermission(model.PermConfigRead), ipGroupController.GetIPGroupReferences)
+	}
+
+	// 威胁情报源管理路由
+	threatFeedRoutes := authenticated.Group("/threat-feeds")
+	{
+		threatFeedRoutes.POST("", middleware.HasPermission(model.PermConfigUpdate), threatFeedController.CreateThreatFeed)
+		threatFeedRoutes.GET("", middleware.HasPermission(model.PermConfigRead), threatFeedController.GetThreatFeeds)
+		threatFeedRoutes.GET("/:id", middleware.HasPermission(model.PermConfigRead), threatFeedController.GetThreatFeedByID)
+		threatFeedRoutes.PUT("/:id", middleware.HasPermission(model.PermConfigUpdate), threatFeedController.UpdateThreatFeed)
+		threatFeedRoutes.DELETE("/:id", middleware.HasPermission(model.PermConfigUpdate), threatFeedController.DeleteThreatFeed)
+		// 立即同步
+		threatFeedRoutes.POST("/:id/refresh", middleware.HasPermission(model.PermConfigUpdate), threatFeedController.RefreshThreatFeed)
+	}
+
+	// 蜜罐陷阱路径管理路由
+	trapPathRoutes := authenticated.Group("/trap-paths")
+	{
+		trapPathRoutes.POST("", middleware.HasPermission(model.PermConfigUpdate), trapPathController.CreateTrapPath)
+		trapPathRoutes.GET("", middleware.HasPermission(model.PermConfigRead), trapPathController.GetTrapPaths)
+		trapPathRoutes.GET("/:id", middleware.HasPermission(model.PermConfigRead), trapPathController.GetTrapPathByID)
+		trapPathRoutes.PUT("/:id", middleware.HasPermission(model.PermConfigUpdate), trapPathController.UpdateTrapPath)
+		trapPathRoutes.DELETE("/:id", middleware.HasPermission(model.PermConfigUpdate), trapPathController.DeleteTrapPath)
 	}
 
 	// rule 管理路由
@@ -149,6 +205,8 @@ func Setup(route *gin.Engine, db *mongo.Database) {
 	{
 		ruleRoutes.POST("", middleware.HasPermission(model.PermConfigUpdate), ruleController.CreateMicroRule)
 		ruleRoutes.GET("", middleware.HasPermission(model.PermConfigRead), ruleController.GetMicroRules)
+		ruleRoutes.GET("/analysis", middleware.HasPermission(model.PermConfigRead), ruleController.AnalyzeMicroRules)
+		ruleRoutes.GET("/offload", middleware.HasPermission(model.PermConfigRead), ruleController.GetMicroRuleOffload)
 		ruleRoutes.GET("/:id", middleware.HasPermission(model.PermConfigRead), ruleController.GetMicroRuleByID)
 		ruleRoutes.PUT("/:id", middleware.HasPermission(model.PermConfigUpdate), ruleController.UpdateMicroRule)
 		ruleRoutes.DELETE("/:id", middleware.HasPermission(model.PermConfigUpdate), ruleController.DeleteMicroRule)
@@ -176,6 +234,8 @@ func Setup(route *gin.Engine, db *mongo.Database) {
 		statsRoutes.GET("/combined-time-series", middleware.HasPermission(model.PermWAFLogRead), statsController.GetCombinedTimeSeriesData)
 		// 获取流量时间序列数据 - 需要config:read权限
 		statsRoutes.GET("/traffic-time-series", middleware.HasPermission(model.PermWAFLogRead), statsController.GetTrafficTimeSeriesData)
+		// 获取规则命中统计 - 需要config:read权限
+		statsRoutes.GET("/rules", middleware.HasPermission(model.PermWAFLogRead), statsController.GetRuleStats)
 	}
 
 	// 配置管理模块
@@ -185,6 +245,10 @@ func Setup(route *gin.Engine, db *mongo.Database) {
 		runnerRoutes.GET("/status", middleware.HasPermission(model.PermConfigRead), runnerController.GetStatus)
 		// 更新配置 - 需要config:update权限
 		runnerRoutes.POST("/control", middleware.HasPermission(model.PermConfigUpdate), runnerController.Control)
+		// HAProxy 配置版本 - 查看需要config:read权限，回滚需要config:update权限
+		runnerRoutes.GET("/config-versions", middleware.HasPermission(model.PermConfigRead), runnerController.ListConfigVersions)
+		runnerRoutes.GET("/config-versions/diff", middleware.HasPermission(model.PermConfigRead), runnerController.DiffConfigVersions)
+		runnerRoutes.POST("/config-versions/:id/rollback", middleware.HasPermission(model.PermConfigUpdate), runnerController.RollbackConfig)
 	}
 	configRoutes := authenticated.Group("/config")
 	{
@@ -206,7 +270,7 @@ func Setup(route *gin.Engine, db *mongo.Database) {
 	auditRoutes := authenticated.Group("/audit")
 	{
 		// 获取审计日志 - 需要audit:read权限
-		auditRoutes.GET("", middleware.HasPermission(model.PermAuditRead), nil)
+		auditRoutes.GET("", middleware.HasPermission(model.PermAuditRead), auditLogController.GetAuditLogs)
 	}
 
 	// 系统管理模块
@@ -0,0 +1,123 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+)
+
+// HTTP01ChallengePath HTTP-01 验证请求的路径前缀，HAProxy 将正在签发的域名和使用 HTTP-01 证书的站点上该路径的请求转发到管理服务
+const HTTP01ChallengePath = "/.well-known/acme-challenge/"
+
+// http01Tokens 等待验证的 HTTP-01 令牌及其响应内容
+// 签发请求和定时续期使用不同的客户端，令牌在进程内共享，由 HTTP01Handler 统一响应
+var http01Tokens sync.Map
+
+// HTTP01Handler 返回响应 HTTP-01 验证请求的处理器，未知的令牌返回 404
+func HTTP01Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := strings.TrimPrefix(r.URL.Path, HTTP01ChallengePath)
+		keyAuth, ok := http01Tokens.Load(token)
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain")
+		io.WriteString(w, keyAuth.(string))
+	})
+}
+
+var ErrDNSProviderNotConfigured = errors.New("未配置 DNS 服务商，无法使用 DNS-01 验证")
+
+// DNSProvider 在 DNS 服务商处写入和删除 DNS-01 验证使用的 TXT 记录
+type DNSProvider interface {
+	// Present 写入 TXT 记录，fqdn 形如 _acme-challenge.example.com.
+	Present(ctx context.Context, fqdn, value string) error
+	// CleanUp 删除 Present 写入的 TXT 记录
+	CleanUp(ctx context.Context, fqdn, value string) error
+}
+
+// DNSProviderFactory 根据 ACME 配置创建 DNS 服务商
+type DNSProviderFactory func(cfg config.ACMEConfig) (DNSProvider, error)
+
+var (
+	dnsProvidersMutex sync.RWMutex
+	dnsProviders      = map[string]DNSProviderFactory{
+		"httpreq": newHTTPReqProvider,
+	}
+)
+
+// RegisterDNSProvider 注册 DNS 服务商，name 与 ACME_DNS_PROVIDER 配置对应，同名时覆盖
+func RegisterDNSProvider(name string, factory DNSProviderFactory) {
+	dnsProvidersMutex.Lock()
+	defer dnsProvidersMutex.Unlock()
+	dnsProviders[name] = factory
+}
+
+func newDNSProvider(cfg config.ACMEConfig) (DNSProvider, error) {
+	if cfg.DNSProvider == "" {
+		return nil, ErrDNSProviderNotConfigured
+	}
+
+	dnsProvidersMutex.RLock()
+	factory, ok := dnsProviders[cfg.DNSProvider]
+	dnsProvidersMutex.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("不支持的 DNS 服务商: %s", cfg.DNSProvider)
+	}
+	return factory(cfg)
+}
+
+// httpReqProvider 通过 HTTP 接口管理 TXT 记录，向 <endpoint>/present 和 <endpoint>/cleanup 发送
+// {"fqdn": "...", "value": "..."}，与 lego 的 httpreq 接口兼容；地址中包含用户名和密码时使用 Basic 认证
+type httpReqProvider struct {
+	endpoint string
+	client   *http.Client
+}
+
+func newHTTPReqProvider(cfg config.ACMEConfig) (DNSProvider, error) {
+	if cfg.DNSEndpoint == "" {
+		return nil, errors.New("httpreq DNS 服务商需要配置 ACME_DNS_ENDPOINT")
+	}
+	return &httpReqProvider{
+		endpoint: strings.TrimSuffix(cfg.DNSEndpoint, "/"),
+		client:   &http.Client{Timeout: 30 * time.Second},
+	}, nil
+}
+
+func (p *httpReqProvider) Present(ctx context.Context, fqdn, value string) error {
+	return p.send(ctx, "/present", fqdn, value)
+}
+
+func (p *httpReqProvider) CleanUp(ctx context.Context, fqdn, value string) error {
+	return p.send(ctx, "/cleanup", fqdn, value)
+}
+
+func (p *httpReqProvider) send(ctx context.Context, path, fqdn, value string) error {
+	body, _ := json.Marshal(map[string]string{"fqdn": fqdn, "value": value})
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("DNS 服务商返回 %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
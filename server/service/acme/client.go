@@ -0,0 +1,367 @@
+package acme
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/utils/certutil"
+	"github.com/rs/zerolog"
+	"golang.org/x/crypto/acme"
+)
+
+// ObtainTimeout 单次签发证书的超时时间，包括所有域名的验证
+const ObtainTimeout = 5 * time.Minute
+
+var ErrWildcardRequiresDNS01 = errors.New("通配符域名只能使用 DNS-01 验证")
+
+// Certificate ACME 签发的证书
+type Certificate struct {
+	Domains        []string  // 证书包含的域名
+	CertificatePEM string    // 证书链（PEM格式），第一个为站点证书
+	PrivateKeyPEM  string    // 私钥（PEM格式）
+	NotAfter       time.Time // 过期时间
+	IssuerName     string    // 颁发机构
+	FingerPrint    string    // 站点证书的 SHA-256 指纹
+}
+
+// HTTP01Router 将主机名的 HTTP-01 验证请求转发到管理服务，由服务运行器实现
+type HTTP01Router interface {
+	AddACMEChallengeHosts(hosts []string) error
+	RemoveACMEChallengeHosts(hosts []string) error
+}
+
+// Client ACME 客户端，首次签发证书时注册或加载账户
+type Client struct {
+	config      config.ACMEConfig
+	accountRepo repository.ACMEAccountRepository
+	router      func() HTTP01Router // 返回验证期间转发 HTTP-01 验证请求的路由，为空或返回 nil 时不转发
+	logger      zerolog.Logger
+
+	mutex  sync.Mutex
+	client *acme.Client // 已加载账户的客户端
+}
+
+// NewClient 创建 ACME 客户端
+func NewClient(cfg config.ACMEConfig, accountRepo repository.ACMEAccountRepository) *Client {
+	return &Client{
+		config:      cfg,
+		accountRepo: accountRepo,
+		logger:      config.GetServiceLogger("acme"),
+	}
+}
+
+// Obtain 为域名签发证书，所有域名使用同一种验证方式
+// HTTP-01 验证要求域名解析到本机，HAProxy 将验证请求转发到管理服务；DNS-01 验证使用配置的 DNS 服务商
+func (c *Client) Obtain(ctx context.Context, domains []string, challengeType model.ACMEChallengeType) (*Certificate, error) {
+	ctx, cancel := context.WithTimeout(ctx, ObtainTimeout)
+	defer cancel()
+
+	var dnsProvider DNSProvider
+	switch challengeType {
+	case model.ACMEChallengeHTTP01:
+		for _, domain := range domains {
+			if strings.HasPrefix(domain, "*.") {
+				return nil, ErrWildcardRequiresDNS01
+			}
+		}
+	case model.ACMEChallengeDNS01:
+		provider, err := newDNSProvider(c.config)
+		if err != nil {
+			return nil, err
+		}
+		dnsProvider = provider
+	default:
+		return nil, fmt.Errorf("不支持的验证方式: %s", challengeType)
+	}
+
+	if challengeType == model.ACMEChallengeHTTP01 {
+		release, err := c.routeHTTP01(domains)
+		if err != nil {
+			return nil, err
+		}
+		defer release()
+	}
+
+	client, err := c.acmeClient(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	order, err := client.AuthorizeOrder(ctx, acme.DomainIDs(domains...))
+	if err != nil {
+		return nil, fmt.Errorf("创建证书订单失败: %w", err)
+	}
+	for _, authzURL := range order.AuthzURLs {
+		if err := c.authorize(ctx, client, authzURL, challengeType, dnsProvider); err != nil {
+			return nil, err
+		}
+	}
+	order, err = client.WaitOrder(ctx, order.URI)
+	if err != nil {
+		return nil, fmt.Errorf("等待证书订单就绪失败: %w", err)
+	}
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("生成证书私钥失败: %w", err)
+	}
+	csr, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:  pkix.Name{CommonName: domains[0]},
+		DNSNames: domains,
+	}, key)
+	if err != nil {
+		return nil, fmt.Errorf("生成证书签名请求失败: %w", err)
+	}
+	chain, _, err := client.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+	if err != nil {
+		return nil, fmt.Errorf("签发证书失败: %w", err)
+	}
+
+	return newCertificate(domains, chain, key)
+}
+
+// routeHTTP01 签发期间将域名的 HTTP-01 验证请求转发到管理服务，返回的函数撤销转发
+// 只有正在签发的域名和使用 HTTP-01 证书的站点转发验证请求，其他站点的验证请求由站点后端自己处理
+func (c *Client) routeHTTP01(domains []string) (func(), error) {
+	var router HTTP01Router
+	if c.router != nil {
+		router = c.router()
+	}
+	if router == nil {
+		return func() {}, nil
+	}
+
+	if err := router.AddACMEChallengeHosts(domains); err != nil {
+		return nil, fmt.Errorf("转发 HTTP-01 验证请求失败: %w", err)
+	}
+	return func() {
+		if err := router.RemoveACMEChallengeHosts(domains); err != nil {
+			c.logger.Warn().Err(err).Strs("domains", domains).Msg("撤销 HTTP-01 验证请求转发失败")
+		}
+	}, nil
+}
+
+// authorize 完成一个域名的验证，已经验证过的域名直接返回
+func (c *Client) authorize(ctx context.Context, client *acme.Client, authzURL string, challengeType model.ACMEChallengeType, dnsProvider DNSProvider) error {
+	authz, err := client.GetAuthorization(ctx, authzURL)
+	if err != nil {
+		return fmt.Errorf("获取域名验证信息失败: %w", err)
+	}
+	if authz.Status == acme.StatusValid {
+		return nil
+	}
+	domain := authz.Identifier.Value
+
+	var challenge *acme.Challenge
+	for _, item := range authz.Challenges {
+		if item.Type == string(challengeType) {
+			challenge = item
+			break
+		}
+	}
+	if challenge == nil {
+		return fmt.Errorf("ACME 服务不支持域名 %s 使用 %s 验证", domain, challengeType)
+	}
+
+	switch challengeType {
+	case model.ACMEChallengeHTTP01:
+		keyAuth, err := client.HTTP01ChallengeResponse(challenge.Token)
+		if err != nil {
+			return fmt.Errorf("生成 HTTP-01 验证响应失败: %w", err)
+		}
+		http01Tokens.Store(challenge.Token, keyAuth)
+		defer http01Tokens.Delete(challenge.Token)
+	case model.ACMEChallengeDNS01:
+		value, err := client.DNS01ChallengeRecord(challenge.Token)
+		if err != nil {
+			return fmt.Errorf("生成 DNS-01 验证记录失败: %w", err)
+		}
+		// 通配符域名的验证记录与主域名相同
+		fqdn := "_acme-challenge." + strings.TrimPrefix(domain, "*.") + "."
+		if err := dnsProvider.Present(ctx, fqdn, value); err != nil {
+			return fmt.Errorf("写入 DNS 验证记录 %s 失败: %w", fqdn, err)
+		}
+		defer func() {
+			if err := dnsProvider.CleanUp(context.WithoutCancel(ctx), fqdn, value); err != nil {
+				c.logger.Warn().Err(err).Str("fqdn", fqdn).Msg("删除 DNS 验证记录失败")
+			}
+		}()
+		if err := sleepContext(ctx, c.config.DNSPropagation); err != nil {
+			return err
+		}
+	}
+
+	if _, err := client.Accept(ctx, challenge); err != nil {
+		return fmt.Errorf("提交域名 %s 验证失败: %w", domain, err)
+	}
+	if _, err := client.WaitAuthorization(ctx, authz.URI); err != nil {
+		return fmt.Errorf("域名 %s 验证失败: %w", domain, err)
+	}
+	return nil
+}
+
+// acmeClient 返回已加载账户的 ACME 客户端，账户不存在时注册新账户并保存私钥
+func (c *Client) acmeClient(ctx context.Context) (*acme.Client, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if c.client != nil {
+		return c.client, nil
+	}
+
+	httpClient, err := newHTTPClient(c.config.CAFile)
+	if err != nil {
+		return nil, err
+	}
+	client := &acme.Client{
+		DirectoryURL: c.config.DirectoryURL,
+		HTTPClient:   httpClient,
+		UserAgent:    "RuiQi-WAF",
+	}
+
+	account, err := c.accountRepo.GetAccount(ctx, c.config.DirectoryURL, c.config.Email)
+	switch {
+	case err == nil:
+		key, err := parsePrivateKey(account.PrivateKey)
+		if err != nil {
+			return nil, fmt.Errorf("解析 ACME 账户私钥失败: %w", err)
+		}
+		// 账户地址在首次请求时按私钥查询
+		client.Key = key
+	case errors.Is(err, repository.ErrACMEAccountNotFound):
+		key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			return nil, fmt.Errorf("生成 ACME 账户私钥失败: %w", err)
+		}
+		client.Key = key
+
+		var contact []string
+		if c.config.Email != "" {
+			contact = []string{"mailto:" + c.config.Email}
+		}
+		if _, err := client.Register(ctx, &acme.Account{Contact: contact}, acme.AcceptTOS); err != nil {
+			return nil, fmt.Errorf("注册 ACME 账户失败: %w", err)
+		}
+
+		keyPEM, err := encodePrivateKey(key)
+		if err != nil {
+			return nil, err
+		}
+		err = c.accountRepo.CreateAccount(ctx, &model.ACMEAccount{
+			DirectoryURL: c.config.DirectoryURL,
+			Email:        c.config.Email,
+			PrivateKey:   keyPEM,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("保存 ACME 账户失败: %w", err)
+		}
+		c.logger.Info().Str("directoryURL", c.config.DirectoryURL).Str("email", c.config.Email).Msg("ACME 账户注册成功")
+	default:
+		return nil, fmt.Errorf("获取 ACME 账户失败: %w", err)
+	}
+
+	c.client = client
+	return client, nil
+}
+
+// newHTTPClient 创建访问 ACME 服务的 HTTP 客户端，caFile 不为空时额外信任其中的 CA 证书
+func newHTTPClient(caFile string) (*http.Client, error) {
+	if caFile == "" {
+		return &http.Client{Timeout: 30 * time.Second}, nil
+	}
+
+	pool, err := x509.SystemCertPool()
+	if err != nil {
+		pool = x509.NewCertPool()
+	}
+	data, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取 ACME CA 证书失败: %w", err)
+	}
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("ACME CA 证书文件 %s 中没有有效的证书", caFile)
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{RootCAs: pool}
+	return &http.Client{Timeout: 30 * time.Second, Transport: transport}, nil
+}
+
+// newCertificate 将签发的证书链和私钥编码为 PEM 格式
+func newCertificate(domains []string, chain [][]byte, key *ecdsa.PrivateKey) (*Certificate, error) {
+	if len(chain) == 0 {
+		return nil, errors.New("ACME 服务返回的证书链为空")
+	}
+	leaf, err := x509.ParseCertificate(chain[0])
+	if err != nil {
+		return nil, fmt.Errorf("解析签发的证书失败: %w", err)
+	}
+
+	var certPEM strings.Builder
+	for _, der := range chain {
+		if err := pem.Encode(&certPEM, &pem.Block{Type: "CERTIFICATE", Bytes: der}); err != nil {
+			return nil, err
+		}
+	}
+	keyPEM, err := encodePrivateKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &Certificate{
+		Domains:        domains,
+		CertificatePEM: certPEM.String(),
+		PrivateKeyPEM:  keyPEM,
+		NotAfter:       leaf.NotAfter,
+		IssuerName:     certutil.IssuerName(leaf),
+		FingerPrint:    certutil.FingerPrint(chain[0]),
+	}, nil
+}
+
+func encodePrivateKey(key *ecdsa.PrivateKey) (string, error) {
+	der, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		return "", fmt.Errorf("编码私钥失败: %w", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})), nil
+}
+
+func parsePrivateKey(data string) (crypto.Signer, error) {
+	block, _ := pem.Decode([]byte(data))
+	if block == nil {
+		return nil, errors.New("无效的 PEM 数据")
+	}
+	return x509.ParseECPrivateKey(block.Bytes)
+}
+
+// sleepContext 等待指定时间，context 取消时提前返回
+func sleepContext(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,208 @@
+package acme
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/utils/certutil"
+)
+
+// 以下测试需要本地运行 Pebble 和 pebble-challtestsrv，未设置 PEBBLE_DIRECTORY 时跳过：
+//
+//	pebble-challtestsrv -defaultIPv4 127.0.0.1 -defaultIPv6 ""
+//	pebble -config test/config/pebble-config.json -dnsserver 127.0.0.1:8053
+//
+//	PEBBLE_DIRECTORY=https://localhost:14000/dir \
+//	PEBBLE_CA_FILE=test/certs/pebble.minica.pem \
+//	go test ./service/acme/
+//
+// Pebble 默认在 5002 端口进行 HTTP-01 验证，可以通过 PEBBLE_HTTP01_ADDR 修改测试监听的地址；
+// challtestsrv 的管理接口默认为 http://localhost:8055，可以通过 PEBBLE_CHALLTESTSRV 修改
+func pebbleConfig(t *testing.T) config.ACMEConfig {
+	t.Helper()
+	directory := os.Getenv("PEBBLE_DIRECTORY")
+	if directory == "" {
+		t.Skip("PEBBLE_DIRECTORY not set")
+	}
+	return config.ACMEConfig{
+		DirectoryURL: directory,
+		Email:        "admin@example.com",
+		CAFile:       os.Getenv("PEBBLE_CA_FILE"),
+	}
+}
+
+func getenv(key, fallback string) string {
+	if value := os.Getenv(key); value != "" {
+		return value
+	}
+	return fallback
+}
+
+// TestObtainHTTP01 通过 HTTP-01 验证签发证书，第二次签发复用已保存的账户
+func TestObtainHTTP01(t *testing.T) {
+	cfg := pebbleConfig(t)
+
+	listener, err := net.Listen("tcp", getenv("PEBBLE_HTTP01_ADDR", ":5002"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	server := &http.Server{Handler: HTTP01Handler()}
+	go server.Serve(listener)
+	defer server.Close()
+
+	accounts := &memoryAccountRepo{}
+	domains := []string{"a.example.com", "b.example.com"}
+	for i := 0; i < 2; i++ {
+		cert, err := NewClient(cfg, accounts).Obtain(context.Background(), domains, model.ACMEChallengeHTTP01)
+		if err != nil {
+			t.Fatalf("Obtain() #%d error = %v", i, err)
+		}
+		checkCertificate(t, cert, domains)
+	}
+	if len(accounts.accounts) != 1 {
+		t.Errorf("accounts = %d, want 1", len(accounts.accounts))
+	}
+}
+
+// TestObtainDNS01 通过注册的 DNS 服务商完成 DNS-01 验证，签发通配符证书
+func TestObtainDNS01(t *testing.T) {
+	cfg := pebbleConfig(t)
+	cfg.DNSProvider = "challtestsrv"
+	cfg.DNSEndpoint = getenv("PEBBLE_CHALLTESTSRV", "http://localhost:8055")
+	RegisterDNSProvider("challtestsrv", func(cfg config.ACMEConfig) (DNSProvider, error) {
+		return &challTestSrvProvider{endpoint: cfg.DNSEndpoint}, nil
+	})
+
+	domains := []string{"example.com", "*.example.com"}
+	cert, err := NewClient(cfg, &memoryAccountRepo{}).Obtain(context.Background(), domains, model.ACMEChallengeDNS01)
+	if err != nil {
+		t.Fatalf("Obtain() error = %v", err)
+	}
+	checkCertificate(t, cert, domains)
+}
+
+// TestObtainWildcardHTTP01 通配符域名不能使用 HTTP-01 验证，不需要访问 ACME 服务
+func TestObtainWildcardHTTP01(t *testing.T) {
+	client := NewClient(config.ACMEConfig{DirectoryURL: "http://127.0.0.1:0/dir"}, &memoryAccountRepo{})
+	_, err := client.Obtain(context.Background(), []string{"*.example.com"}, model.ACMEChallengeHTTP01)
+	if err != ErrWildcardRequiresDNS01 {
+		t.Errorf("Obtain() error = %v, want %v", err, ErrWildcardRequiresDNS01)
+	}
+}
+
+type recordingRouter struct {
+	added   []string
+	removed []string
+}
+
+func (r *recordingRouter) AddACMEChallengeHosts(hosts []string) error {
+	r.added = append(r.added, hosts...)
+	return nil
+}
+
+func (r *recordingRouter) RemoveACMEChallengeHosts(hosts []string) error {
+	r.removed = append(r.removed, hosts...)
+	return nil
+}
+
+// TestObtainRoutesHTTP01 测试 HTTP-01 签发期间转发验证请求，签发结束后撤销，DNS-01 不转发
+func TestObtainRoutesHTTP01(t *testing.T) {
+	tests := []struct {
+		challenge model.ACMEChallengeType
+		want      []string
+	}{
+		{model.ACMEChallengeHTTP01, []string{"example.com", "www.example.com"}},
+		{model.ACMEChallengeDNS01, nil},
+	}
+	for _, tt := range tests {
+		t.Run(string(tt.challenge), func(t *testing.T) {
+			router := &recordingRouter{}
+			client := NewClient(config.ACMEConfig{DirectoryURL: "http://127.0.0.1:0/dir"}, &memoryAccountRepo{})
+			client.router = func() HTTP01Router { return router }
+
+			if _, err := client.Obtain(context.Background(), []string{"example.com", "www.example.com"}, tt.challenge); err == nil {
+				t.Fatal("Obtain() error = nil, want directory error")
+			}
+			if !slices.Equal(router.added, tt.want) || !slices.Equal(router.removed, tt.want) {
+				t.Errorf("added = %v, removed = %v, want %v", router.added, router.removed, tt.want)
+			}
+		})
+	}
+}
+
+func checkCertificate(t *testing.T, cert *Certificate, domains []string) {
+	t.Helper()
+	pair, err := tls.X509KeyPair([]byte(cert.CertificatePEM), []byte(cert.PrivateKeyPEM))
+	if err != nil {
+		t.Fatalf("X509KeyPair() error = %v", err)
+	}
+	if !slices.Equal(pair.Leaf.DNSNames, domains) {
+		t.Errorf("DNSNames = %v, want %v", pair.Leaf.DNSNames, domains)
+	}
+	if !cert.NotAfter.After(time.Now()) {
+		t.Errorf("NotAfter = %v, want future", cert.NotAfter)
+	}
+	if cert.FingerPrint != certutil.FingerPrint(pair.Leaf.Raw) {
+		t.Errorf("FingerPrint = %s, want %s", cert.FingerPrint, certutil.FingerPrint(pair.Leaf.Raw))
+	}
+}
+
+type memoryAccountRepo struct {
+	accounts []model.ACMEAccount
+}
+
+func (r *memoryAccountRepo) GetAccount(ctx context.Context, directoryURL, email string) (*model.ACMEAccount, error) {
+	for _, account := range r.accounts {
+		if account.DirectoryURL == directoryURL && account.Email == email {
+			return &account, nil
+		}
+	}
+	return nil, repository.ErrACMEAccountNotFound
+}
+
+func (r *memoryAccountRepo) CreateAccount(ctx context.Context, account *model.ACMEAccount) error {
+	r.accounts = append(r.accounts, *account)
+	return nil
+}
+
+// challTestSrvProvider 通过 pebble-challtestsrv 的管理接口写入 TXT 记录
+type challTestSrvProvider struct {
+	endpoint string
+}
+
+func (p *challTestSrvProvider) Present(ctx context.Context, fqdn, value string) error {
+	return p.post(ctx, "/set-txt", map[string]string{"host": fqdn, "value": value})
+}
+
+func (p *challTestSrvProvider) CleanUp(ctx context.Context, fqdn, value string) error {
+	return p.post(ctx, "/clear-txt", map[string]string{"host": fqdn})
+}
+
+func (p *challTestSrvProvider) post(ctx context.Context, path string, body map[string]string) error {
+	data, _ := json.Marshal(body)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.endpoint+path, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("challtestsrv returned %s", resp.Status)
+	}
+	return nil
+}
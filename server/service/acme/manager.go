@@ -0,0 +1,149 @@
+package acme
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/service/daemon"
+	"github.com/rs/zerolog"
+)
+
+// RetryInterval 续期失败后再次尝试的间隔，避免触发 ACME 服务的失败次数限制
+const RetryInterval = 6 * time.Hour
+
+var ErrNotACMECertificate = errors.New("证书不是通过 ACME 签发的")
+
+// Manager 签发 ACME 证书并保存到证书库，续期后将新证书热加载到 HAProxy
+type Manager struct {
+	certRepo repository.CertificateRepository
+	client   *Client
+	config   config.ACMEConfig
+	logger   zerolog.Logger
+}
+
+// NewManager 创建 ACME 证书管理器，使用全局 ACME 配置
+func NewManager(
+	certRepo repository.CertificateRepository,
+	accountRepo repository.ACMEAccountRepository,
+) *Manager {
+	client := NewClient(config.Global.ACME, accountRepo)
+	client.router = runnerRouter
+	return &Manager{
+		certRepo: certRepo,
+		client:   client,
+		config:   config.Global.ACME,
+		logger:   config.GetServiceLogger("acme"),
+	}
+}
+
+// runnerRouter 返回服务运行器，由运行器将验证请求的主机名写入 HAProxy 的映射
+func runnerRouter() HTTP01Router {
+	runner, err := daemon.GetRunnerService()
+	if err != nil {
+		return nil
+	}
+	return runner
+}
+
+// Issue 为 cert.Domains 签发证书并保存到证书库，cert.ACME.ChallengeType 指定验证方式
+func (m *Manager) Issue(ctx context.Context, cert *model.CertificateStore) error {
+	issued, err := m.client.Obtain(ctx, cert.Domains, cert.ACME.ChallengeType)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	m.apply(cert, issued, now)
+	if err := m.certRepo.CreateCertificate(ctx, cert); err != nil {
+		return fmt.Errorf("保存证书失败: %w", err)
+	}
+
+	m.logger.Info().Str("name", cert.Name).Strs("domains", cert.Domains).Time("expireDate", cert.ExpireDate).Msg("ACME 证书签发成功")
+	return nil
+}
+
+// Renew 重新签发证书并更新证书库，失败原因记录在 cert.ACME 中
+// 续期成功后使用该证书的站点随之更新，HAProxy 运行中时通过运行时 API 热加载，不重新加载配置
+func (m *Manager) Renew(ctx context.Context, cert *model.CertificateStore) error {
+	if cert.Source != model.CertSourceACME || cert.ACME == nil {
+		return ErrNotACMECertificate
+	}
+
+	now := time.Now()
+	cert.ACME.LastAttemptAt = &now
+	issued, err := m.client.Obtain(ctx, cert.Domains, cert.ACME.ChallengeType)
+	if err != nil {
+		cert.ACME.LastError = err.Error()
+		if saveErr := m.certRepo.UpdateCertificate(ctx, cert); saveErr != nil {
+			return errors.Join(err, fmt.Errorf("保存续期状态失败: %w", saveErr))
+		}
+		return err
+	}
+
+	m.apply(cert, issued, now)
+	if err := m.certRepo.UpdateCertificate(ctx, cert); err != nil {
+		return fmt.Errorf("保存证书失败: %w", err)
+	}
+	m.logger.Info().Str("name", cert.Name).Strs("domains", cert.Domains).Time("expireDate", cert.ExpireDate).Msg("ACME 证书续期成功")
+
+	return m.reloadCertificates()
+}
+
+// RenewDue 续期在 RenewBefore 时间内到期的 ACME 证书，上次续期失败且未超过 RetryInterval 的证书跳过
+// 单个证书失败不影响其他证书，失败原因记录在各自的续期状态中
+func (m *Manager) RenewDue(ctx context.Context, now time.Time) (renewed, failed int, err error) {
+	certs, err := m.certRepo.GetACMECertificatesExpiringBefore(ctx, now.Add(m.config.RenewBefore))
+	if err != nil {
+		return 0, 0, err
+	}
+
+	for i := range certs {
+		if ctx.Err() != nil {
+			return renewed, failed, ctx.Err()
+		}
+		cert := &certs[i]
+		if status := cert.ACME; status != nil && status.LastError != "" &&
+			status.LastAttemptAt != nil && now.Sub(*status.LastAttemptAt) < RetryInterval {
+			continue
+		}
+		if err := m.Renew(ctx, cert); err != nil {
+			m.logger.Error().Err(err).Str("name", cert.Name).Strs("domains", cert.Domains).Msg("ACME 证书续期失败")
+			failed++
+			continue
+		}
+		renewed++
+	}
+	return renewed, failed, nil
+}
+
+// apply 将签发结果写入证书库记录
+func (m *Manager) apply(cert *model.CertificateStore, issued *Certificate, now time.Time) {
+	cert.PublicKey = issued.CertificatePEM
+	cert.PrivateKey = issued.PrivateKeyPEM
+	cert.ExpireDate = issued.NotAfter
+	cert.IssuerName = issued.IssuerName
+	cert.FingerPrint = issued.FingerPrint
+	cert.Source = model.CertSourceACME
+	cert.ACME.DirectoryURL = m.config.DirectoryURL
+	cert.ACME.RenewedAt = &now
+	cert.ACME.LastAttemptAt = &now
+	cert.ACME.LastError = ""
+}
+
+// reloadCertificates 运行器运行中时将站点使用的证书热加载到 HAProxy
+func (m *Manager) reloadCertificates() error {
+	runner, err := daemon.GetRunnerService()
+	if err != nil || runner.GetState() != daemon.ServiceRunning {
+		// 运行器启动时会从证书库读取新证书生成配置
+		return nil
+	}
+	if _, err := runner.UpdateCertificates(); err != nil {
+		return fmt.Errorf("热加载证书失败: %w", err)
+	}
+	return nil
+}
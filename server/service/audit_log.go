@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/rs/zerolog"
+)
+
+// AuditLogService 审计日志服务接口
+type AuditLogService interface {
+	RecordAuditLog(ctx context.Context, auditLog *model.AuditLog) error
+	GetAuditLogs(ctx context.Context, req *dto.AuditLogListRequest) (*dto.AuditLogListResponse, error)
+}
+
+// AuditLogServiceImpl 审计日志服务实现
+type AuditLogServiceImpl struct {
+	auditLogRepo repository.AuditLogRepository
+	logger       zerolog.Logger
+}
+
+// NewAuditLogService 创建审计日志服务
+func NewAuditLogService(auditLogRepo repository.AuditLogRepository) AuditLogService {
+	logger := config.GetServiceLogger("audit_log")
+	return &AuditLogServiceImpl{
+		auditLogRepo: auditLogRepo,
+		logger:       logger,
+	}
+}
+
+// RecordAuditLog 写入审计日志
+func (s *AuditLogServiceImpl) RecordAuditLog(ctx context.Context, auditLog *model.AuditLog) error {
+	if err := s.auditLogRepo.CreateAuditLog(ctx, auditLog); err != nil {
+		s.logger.Error().Err(err).
+			Str("action", auditLog.Action).
+			Str("resourceType", auditLog.ResourceType).
+			Str("resourceId", auditLog.ResourceID).
+			Msg("写入审计日志失败")
+		return err
+	}
+	return nil
+}
+
+// GetAuditLogs 获取审计日志列表
+func (s *AuditLogServiceImpl) GetAuditLogs(ctx context.Context, req *dto.AuditLogListRequest) (*dto.AuditLogListResponse, error) {
+	auditLogs, total, err := s.auditLogRepo.GetAuditLogs(ctx, req)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("获取审计日志列表失败")
+		return nil, err
+	}
+
+	return &dto.AuditLogListResponse{
+		Total: total,
+		Items: auditLogs,
+	}, nil
+}
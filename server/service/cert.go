@@ -3,20 +3,33 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"net"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/HUAHUAI23/RuiQi/server/config"
 	"github.com/HUAHUAI23/RuiQi/server/dto"
 	"github.com/HUAHUAI23/RuiQi/server/model"
 	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/service/acme"
+	"github.com/HUAHUAI23/RuiQi/server/service/certexpiry"
+	"github.com/HUAHUAI23/RuiQi/server/utils/certutil"
 	"github.com/rs/zerolog"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
 var (
-	ErrCertificateNotFound   = errors.New("证书不存在")
-	ErrCertificateNameExists = errors.New("证书名称已存在")
-	ErrInvalidCertificate    = errors.New("无效的证书格式")
+	ErrCertificateNotFound    = errors.New("证书不存在")
+	ErrCertificateNameExists  = errors.New("证书名称已存在")
+	ErrInvalidCertificate     = errors.New("无效的证书格式")
+	ErrInvalidACMERequest     = errors.New("无效的 ACME 签发请求")
+	ErrACMEIssueFailed        = errors.New("ACME 证书签发失败")
+	ErrNotACMECertificate     = acme.ErrNotACMECertificate
+	ErrCertificateInUse       = errors.New("证书正在被站点使用")
+	ErrCertificateApplyFailed = errors.New("证书已保存，但应用到 HAProxy 失败")
 )
 
 // CertificateService 证书服务接口
@@ -26,20 +39,36 @@ type CertificateService interface {
 	GetCertificateByID(ctx context.Context, id bson.ObjectID) (*model.CertificateStore, error)
 	UpdateCertificate(ctx context.Context, id bson.ObjectID, req *dto.CertificateUpdateRequest) (*model.CertificateStore, error)
 	DeleteCertificate(ctx context.Context, id bson.ObjectID) error
+	// ACME 自动证书
+	IssueACMECertificate(ctx context.Context, req *dto.ACMECertificateRequest) (*model.CertificateStore, error)
+	RenewCertificate(ctx context.Context, id bson.ObjectID) (*model.CertificateStore, error)
+	// 证书过期概览
+	GetExpirySummary(ctx context.Context) (*dto.CertificateExpirySummary, error)
 }
 
 // CertificateServiceImpl 证书服务实现
 type CertificateServiceImpl struct {
-	certRepo repository.CertificateRepository
-	logger   zerolog.Logger
+	certRepo      repository.CertificateRepository
+	siteRepo      repository.SiteRepository
+	runnerService RunnerService
+	acmeManager   *acme.Manager
+	logger        zerolog.Logger
 }
 
-// NewCertificateService 创建证书服务
-func NewCertificateService(certRepo repository.CertificateRepository) CertificateService {
+// NewCertificateService 创建证书服务，runnerService 用于将证书变更应用到使用该证书的站点，可以为 nil
+func NewCertificateService(
+	certRepo repository.CertificateRepository,
+	siteRepo repository.SiteRepository,
+	runnerService RunnerService,
+	acmeManager *acme.Manager,
+) CertificateService {
 	logger := config.GetServiceLogger("certificate")
 	return &CertificateServiceImpl{
-		certRepo: certRepo,
-		logger:   logger,
+		certRepo:      certRepo,
+		siteRepo:      siteRepo,
+		runnerService: runnerService,
+		acmeManager:   acmeManager,
+		logger:        logger,
 	}
 }
 
@@ -60,17 +89,11 @@ func (s *CertificateServiceImpl) CreateCertificate(ctx context.Context, req *dto
 	cert := model.NewCertificateStore()
 	cert.Name = req.Name
 	cert.Description = req.Description
-	cert.PublicKey = req.PublicKey
-	cert.PrivateKey = req.PrivateKey
-	cert.ExpireDate = req.ExpireDate
-	cert.IssuerName = req.IssuerName
-	cert.FingerPrint = req.FingerPrint
-	cert.Domains = req.Domains
+	cert.Source = model.CertSourceManual
 
-	// 验证证书
-	if err := model.ValidateCertificateStore(cert); err != nil {
-		s.logger.Error().Err(err).Msg("证书验证失败")
-		return nil, ErrInvalidCertificate
+	// 解析证书，过期日期、颁发机构、指纹和域名从证书中获取
+	if err := s.applyCertificate(cert, req.PublicKey, req.PrivateKey, req.Passphrase); err != nil {
+		return nil, err
 	}
 
 	// 保存证书
@@ -81,6 +104,10 @@ func (s *CertificateServiceImpl) CreateCertificate(ctx context.Context, req *dto
 	}
 
 	s.logger.Info().Str("id", cert.ID.Hex()).Str("name", cert.Name).Msg("证书创建成功")
+	// 按域名自动选择证书的站点可能改用新证书
+	if err := s.applyCertificates(ctx, false); err != nil {
+		return nil, err
+	}
 	return cert, nil
 }
 
@@ -103,6 +130,7 @@ func (s *CertificateServiceImpl) GetCertificates(ctx context.Context, pageStr, s
 	}
 
 	// 转换为DTO
+	now := time.Now()
 	responses := make([]model.CertificateStore, len(certificates))
 	for i, cert := range certificates {
 		responses[i] = model.CertificateStore{
@@ -115,6 +143,9 @@ func (s *CertificateServiceImpl) GetCertificates(ctx context.Context, pageStr, s
 			IssuerName:  cert.IssuerName,
 			FingerPrint: cert.FingerPrint,
 			Domains:     cert.Domains,
+			Source:      cert.Source,
+			ACME:        cert.ACME,
+			ExpiryState: model.CertificateExpiryState(cert.ExpireDate, now),
 			CreatedAt:   cert.CreatedAt,
 			UpdatedAt:   cert.UpdatedAt,
 		}
@@ -134,6 +165,7 @@ func (s *CertificateServiceImpl) GetCertificateByID(ctx context.Context, id bson
 		return nil, err
 	}
 
+	cert.ExpiryState = model.CertificateExpiryState(cert.ExpireDate, time.Now())
 	return cert, nil
 }
 
@@ -164,29 +196,23 @@ func (s *CertificateServiceImpl) UpdateCertificate(ctx context.Context, id bson.
 	if req.Description != "" {
 		cert.Description = req.Description
 	}
-	if req.PublicKey != "" {
-		cert.PublicKey = req.PublicKey
-	}
-	if req.PrivateKey != "" {
-		cert.PrivateKey = req.PrivateKey
-	}
-	if !req.ExpireDate.IsZero() {
-		cert.ExpireDate = req.ExpireDate
-	}
-	if req.IssuerName != "" {
-		cert.IssuerName = req.IssuerName
-	}
-	if req.FingerPrint != "" {
-		cert.FingerPrint = req.FingerPrint
-	}
-	if req.Domains != nil {
-		cert.Domains = req.Domains
-	}
 
-	// 验证证书
-	if err := model.ValidateCertificateStore(cert); err != nil {
-		s.logger.Error().Err(err).Msg("证书验证失败")
-		return nil, ErrInvalidCertificate
+	// 更新证书链或私钥时，与已保存的另一部分一起重新解析
+	if req.PublicKey != "" || req.PrivateKey != "" {
+		certPEM, keyPEM, passphrase := cert.PublicKey, cert.PrivateKey, ""
+		if req.PublicKey != "" {
+			certPEM = req.PublicKey
+		}
+		if req.PrivateKey != "" {
+			// 已保存的私钥是解密后的，密码只用于新上传的私钥
+			keyPEM, passphrase = req.PrivateKey, req.Passphrase
+		}
+		if err := s.applyCertificate(cert, certPEM, keyPEM, passphrase); err != nil {
+			return nil, err
+		}
+		// 手动替换证书内容后不再自动续期
+		cert.Source = model.CertSourceManual
+		cert.ACME = nil
 	}
 
 	// 保存更新
@@ -197,10 +223,30 @@ func (s *CertificateServiceImpl) UpdateCertificate(ctx context.Context, id bson.
 	}
 
 	s.logger.Info().Str("id", id.Hex()).Str("name", cert.Name).Msg("证书更新成功")
+	if err := s.applyCertificates(ctx, true); err != nil {
+		return nil, err
+	}
 	return cert, nil
 }
 
-// DeleteCertificate 删除证书
+// applyCertificate 解析并校验证书链和私钥，将解析结果写入证书库记录
+func (s *CertificateServiceImpl) applyCertificate(cert *model.CertificateStore, certPEM, keyPEM, passphrase string) error {
+	parsed, err := certutil.Parse(certPEM, keyPEM, passphrase)
+	if err != nil {
+		s.logger.Warn().Err(err).Str("name", cert.Name).Msg("证书验证失败")
+		return fmt.Errorf("%w: %w", ErrInvalidCertificate, err)
+	}
+
+	cert.PublicKey = parsed.CertificatePEM
+	cert.PrivateKey = parsed.PrivateKeyPEM
+	cert.ExpireDate = parsed.NotAfter
+	cert.IssuerName = parsed.IssuerName
+	cert.FingerPrint = parsed.FingerPrint
+	cert.Domains = parsed.Domains
+	return model.ValidateCertificateStore(cert)
+}
+
+// DeleteCertificate 删除证书，仍被站点使用的证书不能删除
 func (s *CertificateServiceImpl) DeleteCertificate(ctx context.Context, id bson.ObjectID) error {
 	// 检查证书是否存在
 	_, err := s.certRepo.GetCertificateByID(ctx, id)
@@ -211,6 +257,14 @@ func (s *CertificateServiceImpl) DeleteCertificate(ctx context.Context, id bson.
 		return err
 	}
 
+	sites, err := s.getCertificateSites(ctx, id)
+	if err != nil {
+		return err
+	}
+	if len(sites) > 0 {
+		return fmt.Errorf("%w: %s", ErrCertificateInUse, strings.Join(sites, ", "))
+	}
+
 	// 删除证书
 	err = s.certRepo.DeleteCertificate(ctx, id)
 	if err != nil {
@@ -221,3 +275,137 @@ func (s *CertificateServiceImpl) DeleteCertificate(ctx context.Context, id bson.
 	s.logger.Info().Str("id", id.Hex()).Msg("证书删除成功")
 	return nil
 }
+
+// getCertificateSites 返回使用证书的站点名称：指定了该证书的站点，
+// 以及按域名自动选择了该证书且证书库中没有其他证书可以替代的 HTTPS 站点
+func (s *CertificateServiceImpl) getCertificateSites(ctx context.Context, id bson.ObjectID) ([]string, error) {
+	sites, err := s.siteRepo.GetCertificateSites(ctx)
+	if err != nil {
+		return nil, err
+	}
+	certs, err := s.certRepo.GetAllCertificates(ctx)
+	if err != nil {
+		return nil, err
+	}
+	others := slices.DeleteFunc(slices.Clone(certs), func(cert model.CertificateStore) bool {
+		return cert.ID == id
+	})
+
+	var names []string
+	for _, site := range sites {
+		if site.CertificateID == id {
+			names = append(names, site.Name)
+			continue
+		}
+		if !site.EnableHTTPS || !site.CertificateID.IsZero() {
+			continue
+		}
+		if selected := model.SelectSiteCertificate(&site, certs); selected != nil && selected.ID == id &&
+			model.SelectSiteCertificate(&site, others) == nil {
+			names = append(names, site.Name)
+		}
+	}
+	return names, nil
+}
+
+// applyCertificates 运行器运行时将证书变更应用到站点，运行器未运行时站点在下次启动时使用新证书
+// hotReload 为 true 时先通过运行时 API 热加载已加载证书的新内容，再增量应用按域名选择证书的变化
+func (s *CertificateServiceImpl) applyCertificates(ctx context.Context, hotReload bool) error {
+	if s.runnerService == nil {
+		return nil
+	}
+	var err error
+	if hotReload {
+		_, err = s.runnerService.UpdateCertificates(ctx)
+	}
+	if err == nil {
+		_, err = s.runnerService.ApplySites(ctx)
+	}
+	if err == nil || errors.Is(err, ErrRunnerNotRunning) {
+		return nil
+	}
+	s.logger.Error().Err(err).Msg("应用证书变更失败")
+	return fmt.Errorf("%w: %v", ErrCertificateApplyFailed, err)
+}
+
+// IssueACMECertificate 通过 ACME 签发证书并保存到证书库，签发完成后返回
+func (s *CertificateServiceImpl) IssueACMECertificate(ctx context.Context, req *dto.ACMECertificateRequest) (*model.CertificateStore, error) {
+	challengeType := req.ChallengeType
+	if challengeType == "" {
+		challengeType = model.ACMEChallengeHTTP01
+	}
+	for _, domain := range req.Domains {
+		if net.ParseIP(domain) != nil {
+			return nil, fmt.Errorf("%w: 不支持为 IP 地址 %s 签发证书", ErrInvalidACMERequest, domain)
+		}
+		if challengeType == model.ACMEChallengeHTTP01 && strings.HasPrefix(domain, "*.") {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidACMERequest, acme.ErrWildcardRequiresDNS01)
+		}
+	}
+
+	name := req.Name
+	if name == "" {
+		name = req.Domains[0]
+	}
+	exists, err := s.certRepo.CheckCertificateNameExists(ctx, name, bson.NilObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrCertificateNameExists
+	}
+
+	cert := model.NewCertificateStore()
+	cert.Name = name
+	cert.Description = req.Description
+	cert.Domains = req.Domains
+	cert.ACME = &model.ACMEStatus{ChallengeType: challengeType}
+
+	if err := s.acmeManager.Issue(ctx, cert); err != nil {
+		s.logger.Error().Err(err).Strs("domains", req.Domains).Msg("ACME 证书签发失败")
+		if errors.Is(err, acme.ErrDNSProviderNotConfigured) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidACMERequest, err)
+		}
+		return nil, fmt.Errorf("%w: %v", ErrACMEIssueFailed, err)
+	}
+	if err := s.applyCertificates(ctx, false); err != nil {
+		return nil, err
+	}
+	return cert, nil
+}
+
+// RenewCertificate 立即续期 ACME 证书，使用该证书的站点同步更新并热加载
+func (s *CertificateServiceImpl) RenewCertificate(ctx context.Context, id bson.ObjectID) (*model.CertificateStore, error) {
+	cert, err := s.certRepo.GetCertificateByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrCertNotFound) {
+			return nil, ErrCertificateNotFound
+		}
+		return nil, err
+	}
+
+	if err := s.acmeManager.Renew(ctx, cert); err != nil {
+		if errors.Is(err, acme.ErrNotACMECertificate) {
+			return nil, err
+		}
+		s.logger.Error().Err(err).Str("id", id.Hex()).Msg("ACME 证书续期失败")
+		return nil, fmt.Errorf("%w: %v", ErrACMEIssueFailed, err)
+	}
+	return cert, nil
+}
+
+// GetExpirySummary 获取证书库中的证书和站点内嵌证书的过期概览
+func (s *CertificateServiceImpl) GetExpirySummary(ctx context.Context) (*dto.CertificateExpirySummary, error) {
+	certs, err := s.certRepo.GetAllCertificates(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("获取证书失败")
+		return nil, err
+	}
+	sites, err := s.siteRepo.GetCertificateSites(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("获取使用证书的站点失败")
+		return nil, err
+	}
+
+	return certexpiry.Summarize(certexpiry.Check(certs, sites, time.Now())), nil
+}
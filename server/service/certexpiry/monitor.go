@@ -0,0 +1,244 @@
+// Package certexpiry 检查证书库中的证书和站点内嵌证书的过期状态，在状态变得更严重时发送告警通知
+package certexpiry
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/service/notifier"
+	"github.com/HUAHUAI23/RuiQi/server/utils/certutil"
+	"github.com/rs/zerolog"
+)
+
+// 证书来源
+const (
+	SourceCertificate = "certificate" // 证书库中的证书
+	SourceSite        = "site"        // 站点内嵌的证书
+)
+
+// EventCertificateExpiry 证书过期通知的事件类型
+const EventCertificateExpiry = "certificate.expiry"
+
+// stateLabels 过期状态在通知中的描述
+var stateLabels = map[model.CertExpiryState]string{
+	model.CertExpiryOK:         "正常",
+	model.CertExpiringIn30Days: "30 天内过期",
+	model.CertExpiringIn14Days: "14 天内过期",
+	model.CertExpiringIn7Days:  "7 天内过期",
+	model.CertExpired:          "已过期",
+}
+
+// Check 返回证书库中所有证书和仍在使用的站点内嵌证书在 now 时的过期状态
+// 站点内嵌证书按指纹合并，站点已指定或自动匹配到证书库中的证书时不再检查其内嵌证书
+func Check(certs []model.CertificateStore, sites []model.Site, now time.Time) []dto.CertificateExpiryItem {
+	items := make([]dto.CertificateExpiryItem, 0, len(certs))
+	for i := range certs {
+		cert := &certs[i]
+		item := newItem(SourceCertificate, cert.ID.Hex(), cert.Name, cert.Domains, cert.FingerPrint, cert.ExpireDate, now)
+		for j := range sites {
+			if sites[j].EnableHTTPS && model.SelectSiteCertificate(&sites[j], certs) == cert {
+				item.Sites = append(item.Sites, sites[j].Name)
+			}
+		}
+		items = append(items, item)
+	}
+
+	embedded := make(map[string]int)
+	for i := range sites {
+		site := &sites[i]
+		if !site.EnableHTTPS || site.Certificate.PublicKey == "" || model.SelectSiteCertificate(site, certs) != nil {
+			continue
+		}
+
+		fingerPrint := site.Certificate.FingerPrint
+		expireDate := site.Certificate.ExpireDate
+		domains := site.Hostnames()
+		if leaf, err := certutil.ParseLeaf(site.Certificate.PublicKey); err == nil {
+			fingerPrint = certutil.FingerPrint(leaf.Raw)
+			expireDate = leaf.NotAfter
+			domains = certutil.Domains(leaf)
+		}
+		if index, ok := embedded[fingerPrint]; ok && fingerPrint != "" {
+			items[index].Sites = append(items[index].Sites, site.Name)
+			continue
+		}
+
+		item := newItem(SourceSite, site.ID.Hex(), site.Name, domains, fingerPrint, expireDate, now)
+		item.Sites = []string{site.Name}
+		embedded[fingerPrint] = len(items)
+		items = append(items, item)
+	}
+	return items
+}
+
+func newItem(source, id, name string, domains []string, fingerPrint string, expireDate, now time.Time) dto.CertificateExpiryItem {
+	return dto.CertificateExpiryItem{
+		Source:      source,
+		ID:          id,
+		Name:        name,
+		Domains:     domains,
+		FingerPrint: fingerPrint,
+		ExpireDate:  expireDate,
+		DaysLeft:    int(math.Floor(expireDate.Sub(now).Hours() / 24)),
+		State:       model.CertificateExpiryState(expireDate, now),
+	}
+}
+
+// Summarize 统计各过期状态的证书数量，返回即将过期和已过期的证书，按过期日期升序排列
+func Summarize(items []dto.CertificateExpiryItem) *dto.CertificateExpirySummary {
+	summary := &dto.CertificateExpirySummary{
+		Total:  len(items),
+		Counts: make(map[model.CertExpiryState]int, len(model.CertExpiryStates)),
+		Items:  make([]dto.CertificateExpiryItem, 0),
+	}
+	for _, state := range model.CertExpiryStates {
+		summary.Counts[state] = 0
+	}
+	for _, item := range items {
+		summary.Counts[item.State]++
+		if item.State != model.CertExpiryOK {
+			summary.Items = append(summary.Items, item)
+		}
+	}
+	slices.SortStableFunc(summary.Items, func(a, b dto.CertificateExpiryItem) int {
+		return a.ExpireDate.Compare(b.ExpireDate)
+	})
+	return summary
+}
+
+// Monitor 定期检查证书过期状态，证书进入更严重的状态时发送通知
+// 每个证书按指纹记录最近一次通知的状态，同一状态只通知一次，证书更新后指纹变化，重新开始计算
+type Monitor struct {
+	certRepo   repository.CertificateRepository
+	siteRepo   repository.SiteRepository
+	noticeRepo repository.CertExpiryNoticeRepository
+	notifier   notifier.Notifier
+	logger     zerolog.Logger
+}
+
+// NewMonitor 创建证书过期检查器，notifier 为 nil 时只记录日志
+func NewMonitor(
+	certRepo repository.CertificateRepository,
+	siteRepo repository.SiteRepository,
+	noticeRepo repository.CertExpiryNoticeRepository,
+	notifier notifier.Notifier,
+) *Monitor {
+	return &Monitor{
+		certRepo:   certRepo,
+		siteRepo:   siteRepo,
+		noticeRepo: noticeRepo,
+		notifier:   notifier,
+		logger:     config.GetServiceLogger("certexpiry"),
+	}
+}
+
+// Scan 检查所有证书在 now 时的过期状态
+func (m *Monitor) Scan(ctx context.Context, now time.Time) ([]dto.CertificateExpiryItem, error) {
+	certs, err := m.certRepo.GetAllCertificates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取证书失败: %w", err)
+	}
+	sites, err := m.siteRepo.GetCertificateSites(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取站点失败: %w", err)
+	}
+	return Check(certs, sites, now), nil
+}
+
+// Run 检查证书过期状态，对比上一次通知的状态发送通知，返回本次通知的证书
+func (m *Monitor) Run(ctx context.Context, now time.Time) ([]dto.CertificateExpiryItem, error) {
+	items, err := m.Scan(ctx, now)
+	if err != nil {
+		return nil, err
+	}
+	notices, err := m.noticeRepo.GetNotices(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("获取证书过期通知记录失败: %w", err)
+	}
+	notified := make(map[string]model.CertExpiryState, len(notices))
+	for _, notice := range notices {
+		notified[notice.FingerPrint] = notice.State
+	}
+
+	var due []dto.CertificateExpiryItem
+	active := make(map[string]bool)
+	for _, item := range items {
+		if item.State == model.CertExpiryOK {
+			continue
+		}
+		active[item.FingerPrint] = true
+		if state, ok := notified[item.FingerPrint]; ok && state.Severity() >= item.State.Severity() {
+			continue
+		}
+		due = append(due, item)
+		m.logger.Warn().
+			Str("source", item.Source).
+			Str("name", item.Name).
+			Strs("domains", item.Domains).
+			Time("expireDate", item.ExpireDate).
+			Str("state", string(item.State)).
+			Msg("证书即将过期")
+	}
+
+	// 证书已删除、更新或恢复正常时删除通知记录
+	var stale []string
+	for fingerPrint := range notified {
+		if !active[fingerPrint] {
+			stale = append(stale, fingerPrint)
+		}
+	}
+	var errs []error
+	if err := m.noticeRepo.DeleteNotices(ctx, stale); err != nil {
+		errs = append(errs, fmt.Errorf("删除证书过期通知记录失败: %w", err))
+	}
+
+	if len(due) == 0 || m.notifier == nil {
+		return nil, errors.Join(errs...)
+	}
+	if err := m.notifier.Notify(ctx, buildMessage(due, now)); err != nil {
+		// 不保存通知记录，下次检查时重新发送
+		return nil, errors.Join(append(errs, fmt.Errorf("发送证书过期通知失败: %w", err))...)
+	}
+	for _, item := range due {
+		notice := &model.CertExpiryNotice{FingerPrint: item.FingerPrint, State: item.State, NotifiedAt: now}
+		if err := m.noticeRepo.SaveNotice(ctx, notice); err != nil {
+			errs = append(errs, fmt.Errorf("保存证书过期通知记录失败: %w", err))
+		}
+	}
+	return due, errors.Join(errs...)
+}
+
+// buildMessage 将本次需要通知的证书合并为一条通知
+func buildMessage(items []dto.CertificateExpiryItem, now time.Time) notifier.Message {
+	var body strings.Builder
+	for _, item := range items {
+		source := "证书"
+		if item.Source == SourceSite {
+			source = "站点内嵌证书"
+		}
+		fmt.Fprintf(&body, "[%s] %s %s，域名: %s，过期时间: %s，剩余 %d 天",
+			stateLabels[item.State], source, item.Name, strings.Join(item.Domains, ", "),
+			item.ExpireDate.Local().Format(time.DateTime), max(item.DaysLeft, 0))
+		if len(item.Sites) > 0 {
+			fmt.Fprintf(&body, "，使用站点: %s", strings.Join(item.Sites, ", "))
+		}
+		body.WriteString("\n")
+	}
+
+	return notifier.Message{
+		Event:   EventCertificateExpiry,
+		Subject: fmt.Sprintf("[RuiQi-WAF] %d 个证书即将过期或已过期", len(items)),
+		Body:    body.String(),
+		Data:    items,
+		Time:    now,
+	}
+}
@@ -0,0 +1,96 @@
+package cornjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/service/acme"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/rs/zerolog"
+)
+
+// CheckInterval 检查待续期证书的间隔，证书在到期前 ACME_RENEW_BEFORE_DAYS 天开始续期，不需要很高的频率
+const CheckInterval = time.Hour
+
+// ACMERenewJob ACME 证书定时续期任务
+type ACMERenewJob struct {
+	scheduler gocron.Scheduler
+	manager   *acme.Manager
+	logger    zerolog.Logger
+	isRunning bool
+}
+
+// NewACMERenewJob 创建 ACME 证书续期任务
+func NewACMERenewJob(manager *acme.Manager) (*ACMERenewJob, error) {
+	logger := config.GetLogger().With().Str("component", "cronjob-acme-renew").Logger()
+
+	scheduler, err := gocron.NewScheduler(
+		gocron.WithLocation(time.Local),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+
+	return &ACMERenewJob{
+		scheduler: scheduler,
+		manager:   manager,
+		logger:    logger,
+	}, nil
+}
+
+// Start 启动定时任务
+func (j *ACMERenewJob) Start(ctx context.Context) error {
+	if j.isRunning {
+		return errors.New("job is already running")
+	}
+
+	_, err := j.scheduler.NewJob(
+		gocron.DurationJob(CheckInterval),
+		gocron.NewTask(
+			func(ctx context.Context) {
+				if err := j.RenewDue(ctx, time.Now()); err != nil {
+					j.logger.Error().Err(err).Msg("Failed to renew ACME certificates")
+				}
+			},
+			ctx,
+		),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule), // 上一轮续期未完成时跳过本次
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create ACME renew job: %w", err)
+	}
+
+	j.scheduler.Start()
+	j.isRunning = true
+	j.logger.Info().Dur("interval", CheckInterval).Msg("ACME renew job started")
+	return nil
+}
+
+// Stop 停止定时任务
+func (j *ACMERenewJob) Stop() error {
+	if !j.isRunning {
+		return nil
+	}
+
+	j.isRunning = false
+	if err := j.scheduler.Shutdown(); err != nil {
+		j.logger.Error().Err(err).Msg("Failed to shutdown scheduler")
+		return fmt.Errorf("scheduler shutdown error: %w", err)
+	}
+
+	j.logger.Info().Msg("ACME renew job stopped")
+	return nil
+}
+
+// RenewDue 续期即将到期的 ACME 证书
+func (j *ACMERenewJob) RenewDue(ctx context.Context, now time.Time) error {
+	renewed, failed, err := j.manager.RenewDue(ctx, now)
+	if renewed > 0 || failed > 0 {
+		j.logger.Info().Int("renewed", renewed).Int("failed", failed).Msg("ACME certificate renewal finished")
+	}
+	return err
+}
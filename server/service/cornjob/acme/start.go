@@ -0,0 +1,41 @@
+package cornjob
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/service/acme"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Start 创建并启动 ACME 证书续期任务，返回清理函数供主程序在退出时调用
+func Start(db *mongo.Database, logger zerolog.Logger) (func(), error) {
+	manager := acme.NewManager(
+		repository.NewCertificateRepository(db),
+		repository.NewACMEAccountRepository(db),
+	)
+
+	job, err := NewACMERenewJob(manager)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create ACME renew job: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := job.Start(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start ACME renew job: %w", err)
+	}
+
+	cleanup := func() {
+		logger.Info().Msg("Shutting down ACME renew service...")
+		if err := job.Stop(); err != nil {
+			logger.Error().Err(err).Msg("Error when stopping ACME renew job")
+		}
+		cancel()
+	}
+
+	logger.Info().Msg("ACME renew service started successfully")
+	return cleanup, nil
+}
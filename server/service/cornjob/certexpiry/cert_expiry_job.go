@@ -0,0 +1,96 @@
+package cornjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/service/certexpiry"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/rs/zerolog"
+)
+
+// CheckInterval 检查证书过期状态的间隔，过期状态按天划分，不需要很高的频率
+const CheckInterval = time.Hour
+
+// CertExpiryJob 证书过期检查任务
+type CertExpiryJob struct {
+	scheduler gocron.Scheduler
+	monitor   *certexpiry.Monitor
+	logger    zerolog.Logger
+	isRunning bool
+}
+
+// NewCertExpiryJob 创建证书过期检查任务
+func NewCertExpiryJob(monitor *certexpiry.Monitor) (*CertExpiryJob, error) {
+	logger := config.GetLogger().With().Str("component", "cronjob-cert-expiry").Logger()
+
+	scheduler, err := gocron.NewScheduler(
+		gocron.WithLocation(time.Local),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+
+	return &CertExpiryJob{
+		scheduler: scheduler,
+		monitor:   monitor,
+		logger:    logger,
+	}, nil
+}
+
+// Start 启动定时任务
+func (j *CertExpiryJob) Start(ctx context.Context) error {
+	if j.isRunning {
+		return errors.New("job is already running")
+	}
+
+	_, err := j.scheduler.NewJob(
+		gocron.DurationJob(CheckInterval),
+		gocron.NewTask(
+			func(ctx context.Context) {
+				if err := j.Check(ctx, time.Now()); err != nil {
+					j.logger.Error().Err(err).Msg("Failed to check certificate expiry")
+				}
+			},
+			ctx,
+		),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule), // 上一次检查未完成时跳过本次
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create certificate expiry job: %w", err)
+	}
+
+	j.scheduler.Start()
+	j.isRunning = true
+	j.logger.Info().Dur("interval", CheckInterval).Msg("Certificate expiry job started")
+	return nil
+}
+
+// Stop 停止定时任务
+func (j *CertExpiryJob) Stop() error {
+	if !j.isRunning {
+		return nil
+	}
+
+	j.isRunning = false
+	if err := j.scheduler.Shutdown(); err != nil {
+		j.logger.Error().Err(err).Msg("Failed to shutdown scheduler")
+		return fmt.Errorf("scheduler shutdown error: %w", err)
+	}
+
+	j.logger.Info().Msg("Certificate expiry job stopped")
+	return nil
+}
+
+// Check 检查证书过期状态并发送通知
+func (j *CertExpiryJob) Check(ctx context.Context, now time.Time) error {
+	notified, err := j.monitor.Run(ctx, now)
+	if len(notified) > 0 {
+		j.logger.Info().Int("certificates", len(notified)).Msg("Certificate expiry notification sent")
+	}
+	return err
+}
@@ -0,0 +1,45 @@
+package cornjob
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/service/certexpiry"
+	"github.com/HUAHUAI23/RuiQi/server/service/notifier"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Start 创建并启动证书过期检查任务，返回清理函数供主程序在退出时调用
+func Start(db *mongo.Database, logger zerolog.Logger) (func(), error) {
+	monitor := certexpiry.NewMonitor(
+		repository.NewCertificateRepository(db),
+		repository.NewSiteRepository(db),
+		repository.NewCertExpiryNoticeRepository(db),
+		notifier.New(config.Global.Notify),
+	)
+
+	job, err := NewCertExpiryJob(monitor)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create certificate expiry job: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := job.Start(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start certificate expiry job: %w", err)
+	}
+
+	cleanup := func() {
+		logger.Info().Msg("Shutting down certificate expiry service...")
+		if err := job.Stop(); err != nil {
+			logger.Error().Err(err).Msg("Error when stopping certificate expiry job")
+		}
+		cancel()
+	}
+
+	logger.Info().Msg("Certificate expiry service started successfully")
+	return cleanup, nil
+}
@@ -0,0 +1,106 @@
+package cornjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/service/daemon"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/rs/zerolog"
+)
+
+// SyncInterval 同步封禁IP映射的间隔，WAF 新封禁的IP在下一次同步后由 HAProxy 前端直接拒绝
+const SyncInterval = 30 * time.Second
+
+// BlockedIPSyncJob 封禁IP映射同步任务
+type BlockedIPSyncJob struct {
+	scheduler gocron.Scheduler
+	runner    daemon.ServiceRunner
+	logger    zerolog.Logger
+	isRunning bool
+}
+
+// NewBlockedIPSyncJob 创建封禁IP映射同步任务
+func NewBlockedIPSyncJob(runner daemon.ServiceRunner) (*BlockedIPSyncJob, error) {
+	logger := config.GetLogger().With().Str("component", "cronjob-blocked-ip-sync").Logger()
+
+	scheduler, err := gocron.NewScheduler(
+		gocron.WithLocation(time.Local),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+
+	return &BlockedIPSyncJob{
+		scheduler: scheduler,
+		runner:    runner,
+		logger:    logger,
+	}, nil
+}
+
+// Start 启动定时任务
+func (j *BlockedIPSyncJob) Start(ctx context.Context) error {
+	if j.isRunning {
+		return errors.New("job is already running")
+	}
+
+	_, err := j.scheduler.NewJob(
+		gocron.DurationJob(SyncInterval),
+		gocron.NewTask(
+			func(ctx context.Context) {
+				j.Sync()
+			},
+			ctx,
+		),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule), // 上一次同步未完成时跳过本次
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create blocked ip sync job: %w", err)
+	}
+
+	j.scheduler.Start()
+	j.isRunning = true
+	j.logger.Info().Dur("interval", SyncInterval).Msg("Blocked ip sync job started")
+	return nil
+}
+
+// Stop 停止定时任务
+func (j *BlockedIPSyncJob) Stop() error {
+	if !j.isRunning {
+		return nil
+	}
+
+	j.isRunning = false
+	if err := j.scheduler.Shutdown(); err != nil {
+		j.logger.Error().Err(err).Msg("Failed to shutdown scheduler")
+		return fmt.Errorf("scheduler shutdown error: %w", err)
+	}
+
+	j.logger.Info().Msg("Blocked ip sync job stopped")
+	return nil
+}
+
+// Sync 同步封禁IP映射，服务未运行时跳过
+func (j *BlockedIPSyncJob) Sync() {
+	if j.runner.GetState() != daemon.ServiceRunning {
+		j.logger.Debug().Msg("Services not running, skip blocked ip sync")
+		return
+	}
+
+	result, err := j.runner.SyncBlockedIPs()
+	if err != nil {
+		j.logger.Error().Err(err).Msg("Failed to sync blocked ips to HAProxy")
+		return
+	}
+	if result.Changed() {
+		j.logger.Info().
+			Int("total", result.Total).
+			Int("added", result.Added).
+			Int("updated", result.Updated).
+			Int("deleted", result.Deleted).
+			Msg("Blocked ips synced to HAProxy")
+	}
+}
@@ -0,0 +1,34 @@
+package cornjob
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HUAHUAI23/RuiQi/server/service/daemon"
+	"github.com/rs/zerolog"
+)
+
+// Start 创建并启动封禁IP映射同步任务，返回清理函数供主程序在退出时调用
+func Start(runner daemon.ServiceRunner, logger zerolog.Logger) (func(), error) {
+	job, err := NewBlockedIPSyncJob(runner)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create blocked ip sync job: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := job.Start(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start blocked ip sync job: %w", err)
+	}
+
+	cleanup := func() {
+		logger.Info().Msg("Shutting down blocked ip sync service...")
+		if err := job.Stop(); err != nil {
+			logger.Error().Err(err).Msg("Error when stopping blocked ip sync job")
+		}
+		cancel()
+	}
+
+	logger.Info().Msg("Blocked ip sync service started successfully")
+	return cleanup, nil
+}
@@ -0,0 +1,202 @@
+package cornjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/rs/zerolog"
+)
+
+// CleanupInterval 过期清理任务的执行间隔
+// 引擎在匹配时已忽略过期的规则和条目，清理任务只负责从数据库中移除它们，不需要很高的频率
+const CleanupInterval = time.Minute
+
+// ExpiryCleanupJob 过期规则和IP组条目清理任务
+type ExpiryCleanupJob struct {
+	scheduler    gocron.Scheduler
+	ruleRepo     repository.MicroRuleRepository
+	ipGroupRepo  repository.IPGroupRepository
+	auditLogRepo repository.AuditLogRepository
+	logger       zerolog.Logger
+	isRunning    bool
+}
+
+// NewExpiryCleanupJob 创建过期清理任务
+func NewExpiryCleanupJob(
+	ruleRepo repository.MicroRuleRepository,
+	ipGroupRepo repository.IPGroupRepository,
+	auditLogRepo repository.AuditLogRepository,
+) (*ExpiryCleanupJob, error) {
+	logger := config.GetLogger().With().Str("component", "cronjob-expiry-cleanup").Logger()
+
+	scheduler, err := gocron.NewScheduler(
+		gocron.WithLocation(time.Local),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+
+	return &ExpiryCleanupJob{
+		scheduler:    scheduler,
+		ruleRepo:     ruleRepo,
+		ipGroupRepo:  ipGroupRepo,
+		auditLogRepo: auditLogRepo,
+		logger:       logger,
+	}, nil
+}
+
+// Start 启动定时任务
+func (j *ExpiryCleanupJob) Start(ctx context.Context) error {
+	if j.isRunning {
+		return errors.New("job is already running")
+	}
+
+	_, err := j.scheduler.NewJob(
+		gocron.DurationJob(CleanupInterval),
+		gocron.NewTask(
+			func(ctx context.Context) {
+				if err := j.Cleanup(ctx, time.Now()); err != nil {
+					j.logger.Error().Err(err).Msg("Failed to clean up expired rules and IP group items")
+				}
+			},
+			ctx,
+		),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule), // 上一次清理未完成时跳过本次
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create expiry cleanup job: %w", err)
+	}
+
+	j.scheduler.Start()
+	j.isRunning = true
+	j.logger.Info().Dur("interval", CleanupInterval).Msg("Expiry cleanup job started")
+	return nil
+}
+
+// Stop 停止定时任务
+func (j *ExpiryCleanupJob) Stop() error {
+	if !j.isRunning {
+		return nil
+	}
+
+	j.isRunning = false
+	if err := j.scheduler.Shutdown(); err != nil {
+		j.logger.Error().Err(err).Msg("Failed to shutdown scheduler")
+		return fmt.Errorf("scheduler shutdown error: %w", err)
+	}
+
+	j.logger.Info().Msg("Expiry cleanup job stopped")
+	return nil
+}
+
+// Cleanup 删除生效结束时间已过的微规则，并移除IP组中已过期的条目，每次变更都写入审计日志
+func (j *ExpiryCleanupJob) Cleanup(ctx context.Context, now time.Time) error {
+	var errs []error
+
+	if err := j.cleanupRules(ctx, now); err != nil {
+		errs = append(errs, fmt.Errorf("rule cleanup error: %w", err))
+	}
+	if err := j.cleanupIPGroups(ctx, now); err != nil {
+		errs = append(errs, fmt.Errorf("ip group cleanup error: %w", err))
+	}
+
+	return errors.Join(errs...)
+}
+
+// cleanupRules 删除过期的微规则
+func (j *ExpiryCleanupJob) cleanupRules(ctx context.Context, now time.Time) error {
+	rules, err := j.ruleRepo.GetExpiredMicroRules(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, rule := range rules {
+		deleted, err := j.ruleRepo.DeleteExpiredMicroRule(ctx, rule.ID, now)
+		if err != nil {
+			return err
+		}
+		if !deleted {
+			// 规则已被删除或生效结束时间已被延后
+			continue
+		}
+
+		j.logger.Info().
+			Str("id", rule.ID.Hex()).
+			Str("name", rule.Name).
+			Time("activeUntil", *rule.Schedule.ActiveUntil).
+			Msg("Expired micro rule deleted")
+
+		j.recordAuditLog(ctx, &model.AuditLog{
+			Action:       model.AuditActionExpire,
+			ResourceType: model.AuditResourceMicroRule,
+			ResourceID:   rule.ID.Hex(),
+			ResourceName: rule.Name,
+			Operator:     model.AuditOperatorSystem,
+			Detail: map[string]any{
+				"type":        string(rule.Type),
+				"status":      string(rule.Status),
+				"priority":    rule.Priority,
+				"activeUntil": *rule.Schedule.ActiveUntil,
+				"deleted":     true,
+			},
+			CreatedAt: now,
+		})
+	}
+
+	return nil
+}
+
+// cleanupIPGroups 移除IP组中过期的条目
+func (j *ExpiryCleanupJob) cleanupIPGroups(ctx context.Context, now time.Time) error {
+	ipGroups, err := j.ipGroupRepo.GetIPGroupsWithExpiredItems(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	for _, ipGroup := range ipGroups {
+		removed := ipGroup.RemoveExpired(now)
+		if err := j.ipGroupRepo.RemoveExpiredItems(ctx, ipGroup.ID, removed, now); err != nil {
+			return err
+		}
+		if len(removed) == 0 {
+			// 只清理了不在组中的过期记录
+			continue
+		}
+
+		j.logger.Info().
+			Str("id", ipGroup.ID.Hex()).
+			Str("name", ipGroup.Name).
+			Strs("items", removed).
+			Msg("Expired IP group items removed")
+
+		j.recordAuditLog(ctx, &model.AuditLog{
+			Action:       model.AuditActionExpire,
+			ResourceType: model.AuditResourceIPGroup,
+			ResourceID:   ipGroup.ID.Hex(),
+			ResourceName: ipGroup.Name,
+			Operator:     model.AuditOperatorSystem,
+			Detail: map[string]any{
+				"removedItems": removed,
+			},
+			CreatedAt: now,
+		})
+	}
+
+	return nil
+}
+
+// recordAuditLog 写入审计日志，失败只记录日志，不影响清理结果
+func (j *ExpiryCleanupJob) recordAuditLog(ctx context.Context, auditLog *model.AuditLog) {
+	if err := j.auditLogRepo.CreateAuditLog(ctx, auditLog); err != nil {
+		j.logger.Error().Err(err).
+			Str("resourceType", auditLog.ResourceType).
+			Str("resourceId", auditLog.ResourceID).
+			Msg("Failed to write audit log for expiry cleanup")
+	}
+}
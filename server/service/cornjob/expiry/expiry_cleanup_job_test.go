@@ -0,0 +1,163 @@
+package cornjob
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	pkgmodel "github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeRuleRepo 只实现清理任务使用的方法，modified 中的规则模拟查询后被修改为未过期
+type fakeRuleRepo struct {
+	repository.MicroRuleRepository
+	expired  []pkgmodel.MicroRule
+	modified map[bson.ObjectID]bool
+	deleted  []bson.ObjectID
+	err      error
+}
+
+func (r *fakeRuleRepo) GetExpiredMicroRules(ctx context.Context, now time.Time) ([]pkgmodel.MicroRule, error) {
+	return r.expired, r.err
+}
+
+func (r *fakeRuleRepo) DeleteExpiredMicroRule(ctx context.Context, id bson.ObjectID, now time.Time) (bool, error) {
+	if r.modified[id] {
+		return false, nil
+	}
+	r.deleted = append(r.deleted, id)
+	return true, nil
+}
+
+// fakeIPGroupRepo 记录每个IP组被移除的条目
+type fakeIPGroupRepo struct {
+	repository.IPGroupRepository
+	groups  []pkgmodel.IPGroup
+	removed map[bson.ObjectID][]string
+}
+
+func (r *fakeIPGroupRepo) GetIPGroupsWithExpiredItems(ctx context.Context, now time.Time) ([]pkgmodel.IPGroup, error) {
+	return r.groups, nil
+}
+
+func (r *fakeIPGroupRepo) RemoveExpiredItems(ctx context.Context, id bson.ObjectID, items []string, now time.Time) error {
+	r.removed[id] = items
+	return nil
+}
+
+// fakeAuditLogRepo 记录写入的审计日志，err 不为空时写入失败
+type fakeAuditLogRepo struct {
+	repository.AuditLogRepository
+	logs []*model.AuditLog
+	err  error
+}
+
+func (r *fakeAuditLogRepo) CreateAuditLog(ctx context.Context, auditLog *model.AuditLog) error {
+	r.logs = append(r.logs, auditLog)
+	return r.err
+}
+
+func newTestCleanupJob(ruleRepo *fakeRuleRepo, ipGroupRepo *fakeIPGroupRepo, auditLogRepo *fakeAuditLogRepo) *ExpiryCleanupJob {
+	return &ExpiryCleanupJob{
+		ruleRepo:     ruleRepo,
+		ipGroupRepo:  ipGroupRepo,
+		auditLogRepo: auditLogRepo,
+		logger:       zerolog.Nop(),
+	}
+}
+
+// TestCleanupExpiredRules 测试删除过期的规则并写入审计日志，查询后被修改为未过期的规则不删除
+func TestCleanupExpiredRules(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	until := now.Add(-time.Minute)
+	expired := pkgmodel.MicroRule{ID: bson.NewObjectID(), Name: "expired", Type: pkgmodel.BlacklistRule, Schedule: &pkgmodel.RuleSchedule{ActiveUntil: &until}}
+	extended := pkgmodel.MicroRule{ID: bson.NewObjectID(), Name: "extended", Type: pkgmodel.WhitelistRule, Schedule: &pkgmodel.RuleSchedule{ActiveUntil: &until}}
+
+	ruleRepo := &fakeRuleRepo{expired: []pkgmodel.MicroRule{expired, extended}, modified: map[bson.ObjectID]bool{extended.ID: true}}
+	auditLogRepo := &fakeAuditLogRepo{}
+	job := newTestCleanupJob(ruleRepo, &fakeIPGroupRepo{removed: make(map[bson.ObjectID][]string)}, auditLogRepo)
+
+	if err := job.Cleanup(context.Background(), now); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if !slices.Equal(ruleRepo.deleted, []bson.ObjectID{expired.ID}) {
+		t.Errorf("deleted = %v, want only %s", ruleRepo.deleted, expired.ID.Hex())
+	}
+	if len(auditLogRepo.logs) != 1 {
+		t.Fatalf("audit logs = %d, want 1", len(auditLogRepo.logs))
+	}
+	log := auditLogRepo.logs[0]
+	if log.Action != model.AuditActionExpire || log.ResourceType != model.AuditResourceMicroRule ||
+		log.ResourceID != expired.ID.Hex() || log.Operator != model.AuditOperatorSystem || !log.CreatedAt.Equal(now) {
+		t.Errorf("audit log = %+v", log)
+	}
+	if log.Detail["activeUntil"] != until {
+		t.Errorf("audit log activeUntil = %v, want %v", log.Detail["activeUntil"], until)
+	}
+}
+
+// TestCleanupExpiredIPGroupItems 测试只移除已过期的IP组条目，只清理了过期记录的IP组不写审计日志，审计日志写入失败不影响清理
+func TestCleanupExpiredIPGroupItems(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	office := pkgmodel.IPGroup{
+		ID:    bson.NewObjectID(),
+		Name:  "office",
+		Items: []string{"10.0.0.1", "10.0.0.2", "10.0.0.0/24"},
+		Expirations: []pkgmodel.IPItemExpiration{
+			{Item: "10.0.0.1", ExpiresAt: now},
+			{Item: "10.0.0.2", ExpiresAt: now.Add(time.Hour)},
+		},
+	}
+	stale := pkgmodel.IPGroup{
+		ID:          bson.NewObjectID(),
+		Name:        "stale",
+		Items:       []string{"10.0.0.3"},
+		Expirations: []pkgmodel.IPItemExpiration{{Item: "10.0.0.9", ExpiresAt: now.Add(-time.Hour)}},
+	}
+
+	ipGroupRepo := &fakeIPGroupRepo{groups: []pkgmodel.IPGroup{office, stale}, removed: make(map[bson.ObjectID][]string)}
+	auditLogRepo := &fakeAuditLogRepo{err: errors.New("write failed")}
+	job := newTestCleanupJob(&fakeRuleRepo{}, ipGroupRepo, auditLogRepo)
+
+	if err := job.Cleanup(context.Background(), now); err != nil {
+		t.Fatalf("Cleanup() error = %v", err)
+	}
+	if removed := ipGroupRepo.removed[office.ID]; !slices.Equal(removed, []string{"10.0.0.1"}) {
+		t.Errorf("removed from office = %v, want [10.0.0.1]", removed)
+	}
+	if removed, ok := ipGroupRepo.removed[stale.ID]; !ok || len(removed) != 0 {
+		t.Errorf("removed from stale = %v (called %v), want empty call", removed, ok)
+	}
+	if len(auditLogRepo.logs) != 1 || auditLogRepo.logs[0].ResourceID != office.ID.Hex() {
+		t.Fatalf("audit logs = %+v, want one for office", auditLogRepo.logs)
+	}
+	if items := auditLogRepo.logs[0].Detail["removedItems"]; !slices.Equal(items.([]string), []string{"10.0.0.1"}) {
+		t.Errorf("audit log removedItems = %v", items)
+	}
+}
+
+// TestCleanupJoinsErrors 测试规则清理失败时仍然清理IP组，并返回规则清理的错误
+func TestCleanupJoinsErrors(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	group := pkgmodel.IPGroup{
+		ID:          bson.NewObjectID(),
+		Items:       []string{"10.0.0.1"},
+		Expirations: []pkgmodel.IPItemExpiration{{Item: "10.0.0.1", ExpiresAt: now}},
+	}
+	queryErr := errors.New("query failed")
+	ipGroupRepo := &fakeIPGroupRepo{groups: []pkgmodel.IPGroup{group}, removed: make(map[bson.ObjectID][]string)}
+	job := newTestCleanupJob(&fakeRuleRepo{err: queryErr}, ipGroupRepo, &fakeAuditLogRepo{})
+
+	if err := job.Cleanup(context.Background(), now); !errors.Is(err, queryErr) {
+		t.Errorf("Cleanup() error = %v, want %v", err, queryErr)
+	}
+	if _, ok := ipGroupRepo.removed[group.ID]; !ok {
+		t.Error("ip group cleanup should run after rule cleanup fails")
+	}
+}
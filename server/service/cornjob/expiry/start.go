@@ -0,0 +1,39 @@
+package cornjob
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Start 创建并启动过期规则和IP组条目清理任务，返回清理函数供主程序在退出时调用
+func Start(db *mongo.Database, logger zerolog.Logger) (func(), error) {
+	job, err := NewExpiryCleanupJob(
+		repository.NewMicroRuleRepository(db),
+		repository.NewIPGroupRepository(db),
+		repository.NewAuditLogRepository(db),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create expiry cleanup job: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := job.Start(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start expiry cleanup job: %w", err)
+	}
+
+	cleanup := func() {
+		logger.Info().Msg("Shutting down expiry cleanup service...")
+		if err := job.Stop(); err != nil {
+			logger.Error().Err(err).Msg("Error when stopping expiry cleanup job")
+		}
+		cancel()
+	}
+
+	logger.Info().Msg("Expiry cleanup service started successfully")
+	return cleanup, nil
+}
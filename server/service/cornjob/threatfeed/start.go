@@ -0,0 +1,44 @@
+package cornjob
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/service/threatfeed"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// Start 创建并启动威胁情报源同步任务，返回清理函数供主程序在退出时调用
+func Start(db *mongo.Database, logger zerolog.Logger) (func(), error) {
+	feedRepo := repository.NewThreatFeedRepository(db)
+	syncer := threatfeed.NewSyncer(
+		feedRepo,
+		repository.NewIPGroupRepository(db),
+		repository.NewAuditLogRepository(db),
+		threatfeed.NewFetcher(nil),
+	)
+
+	job, err := NewThreatFeedSyncJob(feedRepo, syncer)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create threat feed sync job: %w", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	if err := job.Start(ctx); err != nil {
+		cancel()
+		return nil, fmt.Errorf("failed to start threat feed sync job: %w", err)
+	}
+
+	cleanup := func() {
+		logger.Info().Msg("Shutting down threat feed sync service...")
+		if err := job.Stop(); err != nil {
+			logger.Error().Err(err).Msg("Error when stopping threat feed sync job")
+		}
+		cancel()
+	}
+
+	logger.Info().Msg("Threat feed sync service started successfully")
+	return cleanup, nil
+}
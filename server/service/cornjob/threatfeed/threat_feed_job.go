@@ -0,0 +1,114 @@
+package cornjob
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/service/threatfeed"
+	"github.com/go-co-op/gocron/v2"
+	"github.com/rs/zerolog"
+)
+
+// CheckInterval 检查到期情报源的间隔，情报源的刷新间隔最小为 1 分钟
+const CheckInterval = time.Minute
+
+// ThreatFeedSyncJob 威胁情报源定时同步任务
+type ThreatFeedSyncJob struct {
+	scheduler gocron.Scheduler
+	feedRepo  repository.ThreatFeedRepository
+	syncer    *threatfeed.Syncer
+	logger    zerolog.Logger
+	isRunning bool
+}
+
+// NewThreatFeedSyncJob 创建威胁情报源同步任务
+func NewThreatFeedSyncJob(feedRepo repository.ThreatFeedRepository, syncer *threatfeed.Syncer) (*ThreatFeedSyncJob, error) {
+	logger := config.GetLogger().With().Str("component", "cronjob-threat-feed").Logger()
+
+	scheduler, err := gocron.NewScheduler(
+		gocron.WithLocation(time.Local),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create scheduler: %w", err)
+	}
+
+	return &ThreatFeedSyncJob{
+		scheduler: scheduler,
+		feedRepo:  feedRepo,
+		syncer:    syncer,
+		logger:    logger,
+	}, nil
+}
+
+// Start 启动定时任务
+func (j *ThreatFeedSyncJob) Start(ctx context.Context) error {
+	if j.isRunning {
+		return errors.New("job is already running")
+	}
+
+	_, err := j.scheduler.NewJob(
+		gocron.DurationJob(CheckInterval),
+		gocron.NewTask(
+			func(ctx context.Context) {
+				if err := j.SyncDue(ctx, time.Now()); err != nil {
+					j.logger.Error().Err(err).Msg("Failed to sync threat feeds")
+				}
+			},
+			ctx,
+		),
+		gocron.WithSingletonMode(gocron.LimitModeReschedule), // 上一轮同步未完成时跳过本次
+		gocron.WithStartAt(gocron.WithStartImmediately()),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create threat feed sync job: %w", err)
+	}
+
+	j.scheduler.Start()
+	j.isRunning = true
+	j.logger.Info().Dur("interval", CheckInterval).Msg("Threat feed sync job started")
+	return nil
+}
+
+// Stop 停止定时任务
+func (j *ThreatFeedSyncJob) Stop() error {
+	if !j.isRunning {
+		return nil
+	}
+
+	j.isRunning = false
+	if err := j.scheduler.Shutdown(); err != nil {
+		j.logger.Error().Err(err).Msg("Failed to shutdown scheduler")
+		return fmt.Errorf("scheduler shutdown error: %w", err)
+	}
+
+	j.logger.Info().Msg("Threat feed sync job stopped")
+	return nil
+}
+
+// SyncDue 同步所有到达下次拉取时间的情报源
+// 单个情报源失败不影响其他情报源，失败原因记录在各自的同步状态中
+func (j *ThreatFeedSyncJob) SyncDue(ctx context.Context, now time.Time) error {
+	feeds, err := j.feedRepo.GetDueThreatFeeds(ctx, now)
+	if err != nil {
+		return err
+	}
+
+	failed := 0
+	for i := range feeds {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := j.syncer.Sync(ctx, &feeds[i], time.Now()); err != nil {
+			failed++
+		}
+	}
+
+	if len(feeds) > 0 {
+		j.logger.Info().Int("feeds", len(feeds)).Int("failed", failed).Msg("Threat feed sync finished")
+	}
+	return nil
+}
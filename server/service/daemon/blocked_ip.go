@@ -0,0 +1,122 @@
+package daemon
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"strings"
+	"time"
+
+	mongodb "github.com/HUAHUAI23/RuiQi/pkg/database/mongo"
+	pkgmodel "github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/service/daemon/haproxy"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// SyncBlockedIPs 从数据库读取生效中的封禁IP和启用前端拦截的IP组，同步到 HAProxy 的封禁IP映射，不重新加载 HAProxy
+func (r *ServiceRunnerImpl) SyncBlockedIPs() (*haproxy.BlockedIPSyncResult, error) {
+	if r.state != ServiceRunning {
+		return nil, fmt.Errorf("服务未在运行中，无法同步封禁IP")
+	}
+
+	r.applyMutex.Lock()
+	defer r.applyMutex.Unlock()
+
+	client, err := mongodb.Connect(config.Global.DBConfig.URI)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("sync blocked ips failed to connect to database")
+		return nil, err
+	}
+
+	// 获取数据库
+	db := client.Database(config.Global.DBConfig.Database)
+	return r.syncBlockedIPs(r.ctx, db)
+}
+
+// syncBlockedIPs 将数据库中的封禁IP写入 HAProxy 的封禁IP映射，HAProxy 未运行时只写入映射文件
+func (r *ServiceRunnerImpl) syncBlockedIPs(ctx context.Context, db *mongo.Database) (*haproxy.BlockedIPSyncResult, error) {
+	now := time.Now()
+
+	var record pkgmodel.BlockedIPRecord
+	records, err := repository.GetActiveBlockedIPs(ctx, db.Collection(record.GetCollectionName()), now)
+	if err != nil {
+		return nil, fmt.Errorf("获取封禁IP失败: %w", err)
+	}
+
+	var ipGroup pkgmodel.IPGroup
+	groups, err := repository.GetEdgeBlockIPGroups(ctx, db.Collection(ipGroup.GetCollectionName()))
+	if err != nil {
+		return nil, fmt.Errorf("获取前端拦截的IP组失败: %w", err)
+	}
+
+	result, err := r.haproxyService.SyncBlockedIPs(buildBlockedIPEntries(records, groups, now))
+	if err != nil {
+		return nil, fmt.Errorf("同步封禁IP映射失败: %w", err)
+	}
+	return result, nil
+}
+
+// reconcileBlockedIPs 在 HAProxy 启动或重新加载后同步封禁IP映射，失败时只记录日志，WAF 仍会拦截封禁的IP
+func (r *ServiceRunnerImpl) reconcileBlockedIPs(db *mongo.Database) {
+	result, err := r.syncBlockedIPs(r.ctx, db)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("同步封禁IP映射失败")
+		return
+	}
+	if result.Changed() {
+		r.logger.Info().Interface("result", result).Msg("封禁IP映射已同步")
+	}
+}
+
+// buildBlockedIPEntries 生成封禁IP映射的条目，键为 IP 或 CIDR，值为封禁截止时间的 Unix 秒数，0 表示永久封禁
+// 同一个地址有多条记录时取最晚的截止时间；IP组中没有过期时间的条目永久封禁，已过期和无法解析的条目被忽略
+func buildBlockedIPEntries(records []pkgmodel.BlockedIPRecord, groups []pkgmodel.IPGroup, now time.Time) map[string]int64 {
+	entries := make(map[string]int64)
+	add := func(item string, until int64) {
+		key, ok := normalizeBlockedIP(item)
+		if !ok {
+			return
+		}
+		if current, exists := entries[key]; exists && (current == 0 || (until != 0 && until <= current)) {
+			return
+		}
+		entries[key] = until
+	}
+
+	for _, record := range records {
+		if record.BlockedUntil.After(now) {
+			add(record.IP, record.BlockedUntil.Unix())
+		}
+	}
+	for _, group := range groups {
+		expiry := group.ExpiryMap()
+		for _, item := range group.Items {
+			expiresAt, ok := expiry[item]
+			if !ok {
+				add(item, 0)
+			} else if expiresAt.After(now) {
+				add(item, expiresAt.Unix())
+			}
+		}
+	}
+	return entries
+}
+
+// normalizeBlockedIP 将 IP 或 CIDR 转换为映射文件中的规范形式，IPv4 映射的 IPv6 地址转换为 IPv4 地址
+func normalizeBlockedIP(item string) (string, bool) {
+	item = strings.TrimSpace(item)
+	if strings.Contains(item, "/") {
+		prefix, err := netip.ParsePrefix(item)
+		if err != nil {
+			return "", false
+		}
+		return prefix.Masked().String(), true
+	}
+	addr, err := netip.ParseAddr(item)
+	if err != nil {
+		return "", false
+	}
+	return addr.Unmap().String(), true
+}
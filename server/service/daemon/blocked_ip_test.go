@@ -0,0 +1,42 @@
+package daemon
+
+import (
+	"maps"
+	"testing"
+	"time"
+
+	pkgmodel "github.com/HUAHUAI23/RuiQi/pkg/model"
+)
+
+// TestBuildBlockedIPEntries 测试封禁记录和前端拦截IP组合并为映射条目
+func TestBuildBlockedIPEntries(t *testing.T) {
+	now := time.Unix(1700000000, 0)
+	records := []pkgmodel.BlockedIPRecord{
+		{IP: "10.0.0.1", BlockedUntil: now.Add(time.Minute)},
+		{IP: "10.0.0.1", BlockedUntil: now.Add(time.Hour)},
+		{IP: "10.0.0.2", BlockedUntil: now.Add(-time.Minute)}, // 已过期
+		{IP: "::ffff:10.0.0.3", BlockedUntil: now.Add(time.Minute)},
+		{IP: "10.0.0.4", BlockedUntil: now.Add(time.Minute)},
+		{IP: "unknown", BlockedUntil: now.Add(time.Minute)},
+	}
+	groups := []pkgmodel.IPGroup{{
+		Items: []string{"10.0.0.4", "192.168.1.7/24", "172.16.0.1", "172.16.0.2", "2001:db8::/32"},
+		Expirations: []pkgmodel.IPItemExpiration{
+			{Item: "172.16.0.1", ExpiresAt: now.Add(time.Second)},
+			{Item: "172.16.0.2", ExpiresAt: now},
+		},
+	}}
+
+	got := buildBlockedIPEntries(records, groups, now)
+	want := map[string]int64{
+		"10.0.0.1":       now.Add(time.Hour).Unix(),
+		"10.0.0.3":       now.Add(time.Minute).Unix(),
+		"10.0.0.4":       0, // IP组中永久封禁的条目优先
+		"192.168.1.0/24": 0,
+		"172.16.0.1":     now.Add(time.Second).Unix(),
+		"2001:db8::/32":  0,
+	}
+	if !maps.Equal(got, want) {
+		t.Errorf("buildBlockedIPEntries() = %v, want %v", got, want)
+	}
+}
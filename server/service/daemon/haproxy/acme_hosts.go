@@ -0,0 +1,120 @@
+package haproxy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// acmeHostMapName 正在进行 HTTP-01 验证的主机名映射文件的名称，与封禁 IP 映射文件在同一目录，运行时 API 按名称查找
+const acmeHostMapName = "acme_hosts"
+
+// AddACMEChallengeHosts 签发或续期证书期间将主机名的 HTTP-01 验证请求转发到管理服务，完成后调用 RemoveACMEChallengeHosts 撤销
+// 主机名写入映射文件，HAProxy 运行中时再通过运行时 API 添加到内存中的映射，不重新加载配置；同一主机名可以同时有多个订单
+func (s *HAProxyServiceImpl) AddACMEChallengeHosts(hosts []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.acmePendingHosts == nil {
+		s.acmePendingHosts = make(map[string]int)
+	}
+	var added []string
+	for _, host := range hosts {
+		host = strings.ToLower(host)
+		if s.acmePendingHosts[host] == 0 {
+			added = append(added, host)
+		}
+		s.acmePendingHosts[host]++
+	}
+	if len(added) == 0 {
+		return nil
+	}
+	if err := writeACMEHostMap(s.acmeHostMapFile(), s.acmePendingHosts); err != nil {
+		return err
+	}
+	if s.GetStatus() != StatusRunning {
+		return nil
+	}
+
+	if err := s.ensureRuntimeClient(); err != nil {
+		return err
+	}
+	for _, host := range added {
+		if err := s.runtimeClient.AddMapEntry(acmeHostMapName, host, "1"); err != nil {
+			return fmt.Errorf("添加ACME验证主机名 %s 失败: %v", host, err)
+		}
+	}
+	return nil
+}
+
+// RemoveACMEChallengeHosts 撤销 AddACMEChallengeHosts 添加的主机名，主机名没有其他订单时不再转发验证请求
+func (s *HAProxyServiceImpl) RemoveACMEChallengeHosts(hosts []string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	var removed []string
+	for _, host := range hosts {
+		host = strings.ToLower(host)
+		if s.acmePendingHosts[host] == 0 {
+			continue
+		}
+		s.acmePendingHosts[host]--
+		if s.acmePendingHosts[host] == 0 {
+			delete(s.acmePendingHosts, host)
+			removed = append(removed, host)
+		}
+	}
+	if len(removed) == 0 {
+		return nil
+	}
+	if err := writeACMEHostMap(s.acmeHostMapFile(), s.acmePendingHosts); err != nil {
+		return err
+	}
+	if s.GetStatus() != StatusRunning {
+		return nil
+	}
+
+	if err := s.ensureRuntimeClient(); err != nil {
+		return err
+	}
+	for _, host := range removed {
+		if err := s.runtimeClient.DeleteMapEntry(acmeHostMapName, host); err != nil {
+			return fmt.Errorf("删除ACME验证主机名 %s 失败: %v", host, err)
+		}
+	}
+	return nil
+}
+
+// acmeHostMapFile 返回 ACME 验证主机名映射文件的路径
+func (s *HAProxyServiceImpl) acmeHostMapFile() string {
+	return filepath.Join(filepath.Dir(s.BlockedIPMapFile), acmeHostMapName+".map")
+}
+
+// ensureACMEHostMap 确保 ACME 验证主机名映射文件存在，站点前端引用该文件，文件不存在时 HAProxy 无法加载配置
+func (s *HAProxyServiceImpl) ensureACMEHostMap() error {
+	if _, err := os.Stat(s.acmeHostMapFile()); err == nil {
+		return nil
+	}
+	return writeACMEHostMap(s.acmeHostMapFile(), s.acmePendingHosts)
+}
+
+// writeACMEHostMap 按主机名排序写入 ACME 验证主机名映射文件，先写临时文件再重命名，避免 HAProxy 读到写了一半的文件
+func writeACMEHostMap(file string, hosts map[string]int) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("创建映射目录失败: %v", err)
+	}
+
+	var content strings.Builder
+	for _, host := range sortedMapKeys(hosts) {
+		fmt.Fprintf(&content, "%s 1\n", host)
+	}
+	tmpFile := file + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(content.String()), 0644); err != nil {
+		return fmt.Errorf("写入ACME验证主机名映射文件失败: %v", err)
+	}
+	if err := os.Rename(tmpFile, file); err != nil {
+		return fmt.Errorf("写入ACME验证主机名映射文件失败: %v", err)
+	}
+	return nil
+}
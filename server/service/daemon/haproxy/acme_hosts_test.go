@@ -0,0 +1,99 @@
+package haproxy
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+)
+
+// newACMESites 返回使用 HTTP-01 证书、DNS-01 证书和手动上传证书的站点
+func newACMESites() []model.Site {
+	newSite := func(name string, challenge model.ACMEChallengeType) model.Site {
+		return model.Site{
+			Name:         name,
+			Domain:       name + ".example.com",
+			ListenPort:   8080,
+			ActiveStatus: true,
+			EnableHTTPS:  true,
+			Certificate:  model.Certificate{PublicKey: "cert", PrivateKey: "key", ACMEChallenge: challenge},
+			Backend:      model.Backend{Servers: []model.Server{{Host: name + ".svc", Port: 80}}},
+		}
+	}
+	return []model.Site{
+		newSite("http01", model.ACMEChallengeHTTP01),
+		newSite("dns01", model.ACMEChallengeDNS01),
+		newSite("manual", ""),
+	}
+}
+
+// TestApplySitesACMEChallengeRules 测试只有正在验证的主机名和使用 HTTP-01 证书的站点将验证请求转发到管理服务，
+// 其他站点的验证请求由站点自己的后端处理
+func TestApplySitesACMEChallengeRules(t *testing.T) {
+	s := newTestHAProxyService(t, false)
+	config := applyTestSites(t, s, newACMESites())
+
+	if _, err := os.Stat(s.acmeHostMapFile()); err != nil {
+		t.Fatalf("acme host map file not created: %v", err)
+	}
+	section := getConfigSection(config, "frontend fe_8080_http")
+	var rules []string
+	for _, line := range strings.Split(section, "\n") {
+		if line = strings.TrimSpace(line); strings.HasPrefix(line, "use_backend") {
+			rules = append(rules, line)
+		}
+	}
+	want := []string{
+		fmt.Sprintf("use_backend acme_challenge if { path_beg /.well-known/acme-challenge/ } { hdr(host),field(1,:),lower,map_str(%s) -m found }", s.acmeHostMapFile()),
+		"use_backend acme_challenge if { path_beg /.well-known/acme-challenge/ } host_http01_example_com",
+		"use_backend be_http01_example_com if host_http01_example_com",
+		"use_backend be_dns01_example_com if host_dns01_example_com",
+		"use_backend be_manual_example_com if host_manual_example_com",
+	}
+	if strings.Join(rules, "\n") != strings.Join(want, "\n") {
+		t.Errorf("switching rules =\n%s\nwant\n%s", strings.Join(rules, "\n"), strings.Join(want, "\n"))
+	}
+	if strings.Contains(getConfigSection(config, "frontend fe_8080_https"), "acme_challenge") {
+		t.Error("https frontend should not route acme challenges")
+	}
+}
+
+// TestACMEChallengeHosts 测试正在验证的主机名按订单计数写入映射文件，所有订单完成后删除
+func TestACMEChallengeHosts(t *testing.T) {
+	s := newTestHAProxyService(t, false)
+	applyTestSites(t, s, newACMESites())
+
+	for _, tt := range []struct {
+		name   string
+		add    []string
+		remove []string
+		want   string
+	}{
+		{name: "开始签发", add: []string{"B.example.com", "a.example.com"}, want: "a.example.com 1\nb.example.com 1\n"},
+		{name: "同一主机名的续期", add: []string{"a.example.com"}, want: "a.example.com 1\nb.example.com 1\n"},
+		{name: "续期完成", remove: []string{"a.example.com"}, want: "a.example.com 1\nb.example.com 1\n"},
+		{name: "签发完成", remove: []string{"a.example.com", "b.example.com", "c.example.com"}, want: ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			if tt.add != nil {
+				if err := s.AddACMEChallengeHosts(tt.add); err != nil {
+					t.Fatalf("AddACMEChallengeHosts() error = %v", err)
+				}
+			}
+			if tt.remove != nil {
+				if err := s.RemoveACMEChallengeHosts(tt.remove); err != nil {
+					t.Fatalf("RemoveACMEChallengeHosts() error = %v", err)
+				}
+			}
+			content, err := os.ReadFile(s.acmeHostMapFile())
+			if err != nil {
+				t.Fatalf("read map file error = %v", err)
+			}
+			if string(content) != tt.want {
+				t.Errorf("map file = %q, want %q", content, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,181 @@
+package haproxy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/haproxytech/client-native/v6/models"
+)
+
+const (
+	blockedIPMapName   = "blocked_ips" // 封禁 IP 映射文件的名称，运行时 API 在映射目录中按名称查找
+	mapPayloadMaxBytes = 8000          // 运行时 API 单条 add map 命令的最大负载，client-native 限制为 8192 字节
+)
+
+// BlockedIPSyncResult 同步封禁 IP 映射的结果
+type BlockedIPSyncResult struct {
+	Total   int `json:"total"`   // 映射中的条目数
+	Added   int `json:"added"`   // 通过运行时 API 新增的条目数
+	Updated int `json:"updated"` // 通过运行时 API 修改封禁截止时间的条目数
+	Deleted int `json:"deleted"` // 通过运行时 API 删除的条目数
+}
+
+// Changed 是否通过运行时 API 修改了运行中的 HAProxy
+func (r *BlockedIPSyncResult) Changed() bool {
+	return r.Added > 0 || r.Updated > 0 || r.Deleted > 0
+}
+
+// SyncBlockedIPs 将封禁 IP 写入映射文件，HAProxy 运行中时再通过运行时 API 增量同步到内存中的映射，不重新加载配置
+// entries 的键为 IP 或 CIDR，值为封禁截止时间的 Unix 秒数，0 表示永久封禁
+// 映射文件在每次同步时整体重写，HAProxy 启动和重新加载时从文件中读取
+func (s *HAProxyServiceImpl) SyncBlockedIPs(entries map[string]int64) (*BlockedIPSyncResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := writeBlockedIPMap(s.BlockedIPMapFile, entries); err != nil {
+		return nil, err
+	}
+	result := &BlockedIPSyncResult{Total: len(entries)}
+	if s.GetStatus() != StatusRunning {
+		return result, nil
+	}
+
+	if err := s.ensureRuntimeClient(); err != nil {
+		return nil, err
+	}
+	current, err := s.runtimeClient.ShowMapEntries(blockedIPMapName)
+	if err != nil {
+		return nil, fmt.Errorf("获取封禁IP映射失败: %v", err)
+	}
+
+	existing := make(map[string]string, len(current))
+	for _, entry := range current {
+		if _, ok := entries[entry.Key]; !ok {
+			if err := s.runtimeClient.DeleteMapEntry(blockedIPMapName, entry.Key); err != nil {
+				return nil, fmt.Errorf("删除封禁IP %s 失败: %v", entry.Key, err)
+			}
+			result.Deleted++
+			continue
+		}
+		existing[entry.Key] = entry.Value
+	}
+
+	var payload strings.Builder
+	flush := func() error {
+		if payload.Len() == 0 {
+			return nil
+		}
+		if err := s.runtimeClient.AddMapPayload(blockedIPMapName, payload.String()); err != nil {
+			return fmt.Errorf("添加封禁IP失败: %v", err)
+		}
+		payload.Reset()
+		return nil
+	}
+	for _, key := range sortedMapKeys(entries) {
+		value := strconv.FormatInt(entries[key], 10)
+		currentValue, ok := existing[key]
+		if !ok {
+			line := key + " " + value + "\n"
+			if payload.Len()+len(line) > mapPayloadMaxBytes {
+				if err := flush(); err != nil {
+					return nil, err
+				}
+			}
+			payload.WriteString(line)
+			result.Added++
+			continue
+		}
+		if currentValue != value {
+			if err := s.runtimeClient.SetMapEntry(blockedIPMapName, key, value); err != nil {
+				return nil, fmt.Errorf("修改封禁IP %s 失败: %v", key, err)
+			}
+			result.Updated++
+		}
+	}
+	if err := flush(); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ensureBlockedIPMap 确保封禁 IP 映射文件存在，站点前端引用该文件，文件不存在时 HAProxy 无法加载配置
+func (s *HAProxyServiceImpl) ensureBlockedIPMap() error {
+	if _, err := os.Stat(s.BlockedIPMapFile); err == nil {
+		return nil
+	}
+	return writeBlockedIPMap(s.BlockedIPMapFile, nil)
+}
+
+// writeBlockedIPMap 按键排序写入封禁 IP 映射文件，先写临时文件再重命名，避免 HAProxy 读到写了一半的文件
+func writeBlockedIPMap(file string, entries map[string]int64) error {
+	if err := os.MkdirAll(filepath.Dir(file), 0755); err != nil {
+		return fmt.Errorf("创建映射目录失败: %v", err)
+	}
+
+	var content strings.Builder
+	for _, key := range sortedMapKeys(entries) {
+		fmt.Fprintf(&content, "%s %d\n", key, entries[key])
+	}
+	tmpFile := file + ".tmp"
+	if err := os.WriteFile(tmpFile, []byte(content.String()), 0644); err != nil {
+		return fmt.Errorf("写入封禁IP映射文件失败: %v", err)
+	}
+	if err := os.Rename(tmpFile, file); err != nil {
+		return fmt.Errorf("写入封禁IP映射文件失败: %v", err)
+	}
+	return nil
+}
+
+// sortedMapKeys 返回排序后的映射键，使映射文件和运行时命令的顺序保持稳定
+func sortedMapKeys[V any](entries map[string]V) []string {
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+	slices.Sort(keys)
+	return keys
+}
+
+// buildBlockedIPRules 生成按封禁 IP 映射拒绝请求的 TCP 请求规则，tcp-request content 规则在 SPOE 之前执行，被封禁的客户端不会发送给 SPOE
+// 客户端 IP 与 WAF 的判断方式一致，优先使用 X-Forwarded-For 中的第一个地址，没有时使用连接的源地址；
+// 映射的值为封禁截止时间的 Unix 秒数，0 表示永久封禁，截止时间已过的条目即使还未删除也不再拒绝
+func buildBlockedIPRules(mapFile string) models.TCPRequestRules {
+	return models.TCPRequestRules{
+		{
+			Type:     "content",
+			Action:   "set-var",
+			VarScope: "txn",
+			VarName:  "client_ip",
+			Expr:     "src",
+		},
+		{
+			Type:     "content",
+			Action:   "set-var",
+			VarScope: "txn",
+			VarName:  "client_ip",
+			Expr:     "req.hdr_ip(x-forwarded-for,1)",
+		},
+		{
+			Type:     "content",
+			Action:   "set-var",
+			VarScope: "txn",
+			VarName:  "blocked_until",
+			Expr:     fmt.Sprintf("var(txn.client_ip),map_ip_int(%s)", mapFile),
+		},
+		{
+			Type:     "content",
+			Action:   "reject",
+			Cond:     "if",
+			CondTest: "{ var(txn.blocked_until) -m int eq 0 } || { date,neg,add(txn.blocked_until) gt 0 }",
+		},
+	}
+}
+
+// siteFrontendTCPRules 返回端口 HTTP/HTTPS 前端的 TCP 请求规则，封禁 IP 的规则排在按站点统计请求速率的规则之前
+func (s *HAProxyServiceImpl) siteFrontendTCPRules(rateRules models.TCPRequestRules) models.TCPRequestRules {
+	return append(buildBlockedIPRules(s.BlockedIPMapFile), rateRules...)
+}
@@ -0,0 +1,68 @@
+package haproxy
+
+import (
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+)
+
+// TestApplySitesBlockedIPRules 测试站点前端在 SPOE 和速率限制之前按封禁IP映射拒绝请求，应用站点时创建空的映射文件
+func TestApplySitesBlockedIPRules(t *testing.T) {
+	s := newTestHAProxyService(t, false)
+	config := applyTestSites(t, s, newLimitsSites())
+
+	if _, err := os.Stat(s.BlockedIPMapFile); err != nil {
+		t.Fatalf("blocked ip map file not created: %v", err)
+	}
+	for _, header := range []string{"frontend fe_8080_http", "frontend fe_8080_https"} {
+		section := getConfigSection(config, header)
+		for _, want := range []string{
+			"tcp-request content set-var(txn.client_ip) src",
+			"tcp-request content set-var(txn.client_ip) req.hdr_ip(x-forwarded-for,1)",
+			"tcp-request content set-var(txn.blocked_until) var(txn.client_ip),map_ip_int(" + s.BlockedIPMapFile + ")",
+			"tcp-request content reject if { var(txn.blocked_until) -m int eq 0 } || { date,neg,add(txn.blocked_until) gt 0 }",
+		} {
+			if !strings.Contains(section, want) {
+				t.Errorf("%s does not contain %q:\n%s", header, want, section)
+			}
+		}
+		if strings.Index(section, "map_ip_int") > strings.Index(section, "track-sc1") {
+			t.Errorf("blocked ip rules should precede rate rules:\n%s", section)
+		}
+	}
+	if strings.Contains(getConfigSection(config, "frontend fe_8080_combined"), "blocked_until") {
+		t.Error("combined frontend should not contain blocked ip rules")
+	}
+}
+
+// TestSyncBlockedIPsWritesMap 测试 HAProxy 未运行时只按键排序重写映射文件
+func TestSyncBlockedIPsWritesMap(t *testing.T) {
+	s := newTestHAProxyService(t, false)
+	applyTestSites(t, s, []model.Site{newLimitsSites()[0]})
+
+	for _, tt := range []struct {
+		entries map[string]int64
+		want    string
+	}{
+		{map[string]int64{"10.0.0.2": 1700000000, "10.0.0.0/24": 0, "2001:db8::1": 1700000100}, "10.0.0.0/24 0\n10.0.0.2 1700000000\n2001:db8::1 1700000100\n"},
+		{map[string]int64{"10.0.0.2": 1700000000}, "10.0.0.2 1700000000\n"},
+		{nil, ""},
+	} {
+		result, err := s.SyncBlockedIPs(tt.entries)
+		if err != nil {
+			t.Fatalf("SyncBlockedIPs() error = %v", err)
+		}
+		if result.Total != len(tt.entries) || result.Changed() {
+			t.Errorf("SyncBlockedIPs() = %+v, want total %d without runtime changes", result, len(tt.entries))
+		}
+		content, err := os.ReadFile(s.BlockedIPMapFile)
+		if err != nil {
+			t.Fatalf("read map file error = %v", err)
+		}
+		if string(content) != tt.want {
+			t.Errorf("map file = %q, want %q", content, tt.want)
+		}
+	}
+}
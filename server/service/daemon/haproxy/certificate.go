@@ -0,0 +1,68 @@
+package haproxy
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+)
+
+// UpdateCertificates 通过运行时 API 将站点证书的变更热加载到运行中的 HAProxy，并写入证书文件，不重新加载配置
+// 只更新 HAProxy 已经加载的证书，新增或删除证书需要通过 ApplySites 修改配置；返回更新的证书文件名
+func (s *HAProxyServiceImpl) UpdateCertificates(sites []model.Site) ([]string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.ensureRuntimeClient(); err != nil {
+		return nil, err
+	}
+
+	var updated []string
+	for _, site := range sites {
+		if !site.ActiveStatus || !site.EnableHTTPS {
+			continue
+		}
+
+		crtLoad := buildSiteCrtLoad(site)
+		certPath := filepath.Join(s.CertDir, crtLoad.Certificate)
+		keyPath := filepath.Join(s.CertDir, crtLoad.Key)
+		certPEM := []byte(site.Certificate.PublicKey)
+		keyPEM := []byte(site.Certificate.PrivateKey)
+
+		currentCert, err := os.ReadFile(certPath)
+		if err != nil {
+			// 证书文件不存在说明 HAProxy 尚未加载该证书
+			continue
+		}
+		currentKey, _ := os.ReadFile(keyPath)
+		if bytes.Equal(currentCert, certPEM) && bytes.Equal(currentKey, keyPEM) {
+			continue
+		}
+
+		// 证书存储中的证书按 @<存储名>/<别名> 引用，证书和私钥在同一个负载中提交
+		name := fmt.Sprintf("@sites/%s", crtLoad.Alias)
+		payload := strings.TrimSpace(site.Certificate.PublicKey) + "\n" + strings.TrimSpace(site.Certificate.PrivateKey)
+		if err := s.runtimeClient.SetCertEntry(name, payload); err != nil {
+			return updated, fmt.Errorf("设置证书 %s 失败: %v", name, err)
+		}
+		if err := s.runtimeClient.CommitCertEntry(name); err != nil {
+			if abortErr := s.runtimeClient.AbortCertEntry(name); abortErr != nil {
+				s.logger.Error().Err(abortErr).Str("certificate", name).Msg("取消证书更新失败")
+			}
+			return updated, fmt.Errorf("提交证书 %s 失败: %v", name, err)
+		}
+
+		// 证书文件与运行中的证书保持一致，下次生成配置或应用站点时不会被视为变更
+		if err := os.WriteFile(certPath, certPEM, 0600); err != nil {
+			return updated, fmt.Errorf("写入证书文件 %s 失败: %v", crtLoad.Certificate, err)
+		}
+		if err := os.WriteFile(keyPath, keyPEM, 0600); err != nil {
+			return updated, fmt.Errorf("写入私钥文件 %s 失败: %v", crtLoad.Key, err)
+		}
+		updated = append(updated, crtLoad.Certificate)
+	}
+	return updated, nil
+}
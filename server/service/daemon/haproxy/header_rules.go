@@ -0,0 +1,121 @@
+package haproxy
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/haproxytech/client-native/v6/models"
+)
+
+// headerPathVar 记录请求路径的事务变量，响应阶段无法获取请求路径，响应头规则的路径条件使用该变量
+const headerPathVar = "header_path"
+
+// buildHeaderRules 生成站点后端的请求头和响应头规则，没有规则时返回 nil
+// hostHeader 不为空时先传递原始主机名，并将 Host 头改为 hostHeader，站点的请求头规则在其后执行，可以覆盖；
+// 安全响应头预设在站点的响应头规则之前执行
+func buildHeaderRules(site model.Site, hostHeader string) (models.HTTPRequestRules, models.HTTPResponseRules) {
+	requestHeaders := site.RequestHeaders
+	if hostHeader != "" {
+		/*
+			Host Header Rewriting "Origin Host Forwarding"（原始主机转发）
+				确保后端服务器接收到正确的原始主机名
+				实现基于主机名的虚拟主机服务
+				解决多层代理环境中的路由问题
+				满足特定后端服务对 Host 头的要求
+				实现透明代理
+		*/
+		requestHeaders = slices.Concat([]model.HeaderRule{
+			{Action: model.HeaderActionSet, Name: "X-Original-Host", Value: "%[req.hdr(host)]"},
+			{Action: model.HeaderActionSet, Name: "Host", Value: hostHeader},
+		}, requestHeaders)
+	}
+	responseHeaders := slices.Concat(model.SecurityHeaderPresets[site.SecurityHeaders], site.ResponseHeaders)
+
+	var requestRules models.HTTPRequestRules
+	if slices.ContainsFunc(responseHeaders, func(rule model.HeaderRule) bool { return rule.PathPrefix != "" }) {
+		requestRules = append(requestRules, &models.HTTPRequestRule{
+			Type:     "set-var",
+			VarScope: "txn",
+			VarName:  headerPathVar,
+			VarExpr:  "path",
+		})
+	}
+	for _, rule := range requestHeaders {
+		requestRules = append(requestRules, buildHeaderRequestRule(rule))
+	}
+
+	var responseRules models.HTTPResponseRules
+	for _, rule := range responseHeaders {
+		responseRules = append(responseRules, buildHeaderResponseRule(rule))
+	}
+	return requestRules, responseRules
+}
+
+// buildHeaderRequestRule 生成一条请求头规则
+func buildHeaderRequestRule(rule model.HeaderRule) *models.HTTPRequestRule {
+	conf := &models.HTTPRequestRule{
+		Type:    getHeaderRuleType(rule.Action),
+		HdrName: rule.Name,
+	}
+	conf.HdrFormat, conf.HdrMatch = getHeaderRuleArgs(rule)
+	if rule.PathPrefix != "" {
+		conf.Cond = "if"
+		conf.CondTest = fmt.Sprintf("{ path_beg %s }", rule.PathPrefix)
+	}
+	return conf
+}
+
+// buildHeaderResponseRule 生成一条响应头规则，路径条件匹配请求阶段记录的路径
+func buildHeaderResponseRule(rule model.HeaderRule) *models.HTTPResponseRule {
+	conf := &models.HTTPResponseRule{
+		Type:    getHeaderRuleType(rule.Action),
+		HdrName: rule.Name,
+	}
+	conf.HdrFormat, conf.HdrMatch = getHeaderRuleArgs(rule)
+	if rule.PathPrefix != "" {
+		conf.Cond = "if"
+		conf.CondTest = fmt.Sprintf("{ var(txn.%s) -m beg %s }", headerPathVar, rule.PathPrefix)
+	}
+	return conf
+}
+
+// getHeaderRuleType 返回头部规则操作对应的 HAProxy 动作
+func getHeaderRuleType(action model.HeaderAction) string {
+	switch action {
+	case model.HeaderActionAdd:
+		return "add-header"
+	case model.HeaderActionDelete:
+		return "del-header"
+	case model.HeaderActionReplace:
+		return "replace-header"
+	default:
+		return "set-header"
+	}
+}
+
+// getHeaderRuleArgs 返回头部规则写入配置的值和正则表达式
+func getHeaderRuleArgs(rule model.HeaderRule) (format, match string) {
+	switch rule.Action {
+	case model.HeaderActionDelete:
+		return "", ""
+	case model.HeaderActionReplace:
+		return quoteConfigArg(rule.Value), quoteConfigArg(rule.Pattern)
+	default:
+		return quoteConfigArg(rule.Value), ""
+	}
+}
+
+// quoteConfigArg 在参数包含空白、引号、反斜杠或 # 时加引号，使其作为一个参数写入配置
+// 优先使用单引号，单引号内的内容不做任何转义和环境变量替换；参数本身包含单引号时使用双引号并转义
+func quoteConfigArg(arg string) string {
+	if arg != "" && !strings.ContainsAny(arg, " \t#\"'\\") {
+		return arg
+	}
+	if !strings.Contains(arg, "'") {
+		return "'" + arg + "'"
+	}
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, `$`, `\$`)
+	return `"` + replacer.Replace(arg) + `"`
+}
@@ -0,0 +1,132 @@
+package haproxy
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+)
+
+// formatResponseRules 将响应头规则格式化为 "动作 头部 [值] [if 条件]" 形式，便于比较
+func formatResponseRules(site model.Site) []string {
+	_, rules := buildHeaderRules(site, "")
+	result := make([]string, len(rules))
+	for i, rule := range rules {
+		fields := []string{rule.Type, rule.HdrName}
+		if rule.HdrFormat != "" {
+			fields = append(fields, rule.HdrFormat)
+		}
+		if rule.Cond != "" {
+			fields = append(fields, rule.Cond, rule.CondTest)
+		}
+		result[i] = strings.Join(fields, " ")
+	}
+	return result
+}
+
+// TestBuildHeaderRulesPresets 测试安全响应头预设展开为 set-header 和 del-header 响应规则，站点的响应头规则排在预设之后
+func TestBuildHeaderRulesPresets(t *testing.T) {
+	tests := []struct {
+		name     string
+		preset   model.SecurityHeaderPreset
+		response []model.HeaderRule
+		want     []string
+	}{
+		{"empty", "", nil, []string{}},
+		{"none", model.SecurityHeadersNone, nil, []string{}},
+		{
+			"basic",
+			model.SecurityHeadersBasic,
+			nil,
+			[]string{
+				"set-header X-Content-Type-Options nosniff",
+				"set-header X-Frame-Options SAMEORIGIN",
+				"set-header Referrer-Policy strict-origin-when-cross-origin",
+				"del-header Server",
+				"del-header X-Powered-By",
+			},
+		},
+		{
+			"strict",
+			model.SecurityHeadersStrict,
+			nil,
+			[]string{
+				"set-header X-Content-Type-Options nosniff",
+				"set-header X-Frame-Options DENY",
+				"set-header Referrer-Policy no-referrer",
+				`set-header Content-Security-Policy "default-src 'self'; frame-ancestors 'none'; base-uri 'self'; form-action 'self'"`,
+				"set-header Permissions-Policy 'camera=(), microphone=(), geolocation=()'",
+				"set-header Cross-Origin-Opener-Policy same-origin",
+				"del-header Server",
+				"del-header X-Powered-By",
+			},
+		},
+		{
+			"site rules after preset",
+			model.SecurityHeadersBasic,
+			[]model.HeaderRule{
+				{Action: model.HeaderActionSet, Name: "X-Frame-Options", Value: "DENY", PathPrefix: "/admin/"},
+				{Action: model.HeaderActionDelete, Name: "Referrer-Policy"},
+			},
+			[]string{
+				"set-header X-Content-Type-Options nosniff",
+				"set-header X-Frame-Options SAMEORIGIN",
+				"set-header Referrer-Policy strict-origin-when-cross-origin",
+				"del-header Server",
+				"del-header X-Powered-By",
+				"set-header X-Frame-Options DENY if { var(txn.header_path) -m beg /admin/ }",
+				"del-header Referrer-Policy",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := model.Site{Domain: "example.com", SecurityHeaders: tt.preset, ResponseHeaders: tt.response}
+			if got := formatResponseRules(site); !slices.Equal(got, tt.want) {
+				t.Errorf("response rules = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestApplySitesSecurityHeaders 测试安全响应头规则只写入启用预设的站点后端，该后端只在站点的 Host ACL 命中时使用
+func TestApplySitesSecurityHeaders(t *testing.T) {
+	s := newTestHAProxyService(t, false)
+	secured := model.Site{
+		Name:            "secured",
+		Domain:          "secured.example.com",
+		ListenPort:      8080,
+		ActiveStatus:    true,
+		SecurityHeaders: model.SecurityHeadersStrict,
+		Backend:         model.Backend{Servers: []model.Server{{Host: "a.svc", Port: 80}}},
+	}
+	plain := secured
+	plain.Name, plain.Domain, plain.SecurityHeaders = "plain", "plain.example.com", ""
+	config := applyTestSites(t, s, []model.Site{secured, plain})
+
+	backend := getConfigSection(config, "backend be_secured_example_com")
+	for _, want := range []string{
+		"http-response set-header X-Frame-Options DENY",
+		"http-response set-header Cross-Origin-Opener-Policy same-origin",
+		"http-response del-header Server",
+		"http-response del-header X-Powered-By",
+	} {
+		if !strings.Contains(backend, want) {
+			t.Errorf("backend be_secured_example_com does not contain %q:\n%s", want, backend)
+		}
+	}
+	for _, header := range []string{"backend be_plain_example_com", "frontend fe_8080_http"} {
+		if section := getConfigSection(config, header); strings.Contains(section, "X-Frame-Options") || strings.Contains(section, "del-header Server") {
+			t.Errorf("%s should not contain security headers:\n%s", header, section)
+		}
+	}
+
+	frontend := getConfigSection(config, "frontend fe_8080_http")
+	if !strings.Contains(frontend, "use_backend be_secured_example_com if host_secured_example_com\n") {
+		t.Errorf("frontend fe_8080_http should route be_secured_example_com only by its host ACL:\n%s", frontend)
+	}
+	if strings.Count(frontend, "be_secured_example_com") != 1 {
+		t.Errorf("frontend fe_8080_http should use be_secured_example_com once:\n%s", frontend)
+	}
+}
@@ -0,0 +1,145 @@
+package haproxy
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/haproxytech/client-native/v6/models"
+)
+
+// hostGroup 后端中转发时使用相同 Host 头的一组服务器
+// HAProxy 不支持服务器级别的请求规则，Host 头不同的服务器拆分到不同的后端，由后端切换规则按权重选择
+type hostGroup struct {
+	name       string // HAProxy 后端名称
+	hostHeader string // 改写的 Host 头，为空时保留客户端的 Host 头
+	servers    []int  // 服务器在后端配置中的序号
+}
+
+// GetServerHostHeader 返回转发到服务器时改写的 Host 头，保留客户端的 Host 头时返回空
+// 未设置处理方式时，k8s 环境改写为服务器地址，其他环境保留
+func GetServerHostHeader(server model.Server, isK8s bool) string {
+	switch server.HostHeader {
+	case model.HostHeaderServer:
+		return server.Host
+	case model.HostHeaderCustom:
+		return server.HostHeaderValue
+	case model.HostHeaderPreserve:
+		return ""
+	}
+	if isK8s {
+		return server.Host
+	}
+	return ""
+}
+
+// buildHostGroups 按改写后的 Host 头将后端服务器分组，按服务器首次出现的顺序排列
+// 第一组使用后端名称，其余组的名称为 <后端名称>_h<序号>；所有服务器的 Host 头相同时只有一组
+func buildHostGroups(name string, backend model.Backend, isK8s bool) []hostGroup {
+	var groups []hostGroup
+	for index, server := range backend.Servers {
+		hostHeader := GetServerHostHeader(server, isK8s)
+		i := slices.IndexFunc(groups, func(group hostGroup) bool { return group.hostHeader == hostHeader })
+		if i == -1 {
+			i = len(groups)
+			groups = append(groups, hostGroup{name: getHostGroupBackendName(name, i), hostHeader: hostHeader})
+		}
+		groups[i].servers = append(groups[i].servers, index)
+	}
+	return groups
+}
+
+// getHostGroupBackendName 返回后端第 index 个 Host 头分组的后端名称
+func getHostGroupBackendName(name string, index int) string {
+	if index == 0 {
+		return name
+	}
+	return fmt.Sprintf("%s_h%d", name, index)
+}
+
+// IsHostGroupBackend 判断 name 是否为后端 backendName 本身或其按 Host 头拆分出的后端
+func IsHostGroupBackend(backendName, name string) bool {
+	if name == backendName {
+		return true
+	}
+	suffix, ok := strings.CutPrefix(name, backendName+"_h")
+	if !ok {
+		return false
+	}
+	_, err := strconv.Atoi(suffix)
+	return err == nil
+}
+
+// buildHostGroupSwitchingRules 生成切换到后端各个 Host 头分组的规则，cond 为命中该后端的条件
+// 只有一组时直接切换；有多组时：
+//   - 启用会话保持时，先按 Cookie 中的服务器名称切换到服务器所在的分组
+//   - 再按分组内主服务器的配置权重随机选择有可用服务器的分组，运行时修改的权重在重新生成配置后生效
+//   - 随机未选中的分组都不可用时依次尝试其他有可用服务器的分组，全部不可用时使用第一组
+func buildHostGroupSwitchingRules(groups []hostGroup, backend model.Backend, servers []string, cond string) models.BackendSwitchingRules {
+	if len(groups) == 1 {
+		return models.BackendSwitchingRules{{Name: groups[0].name, Cond: "if", CondTest: cond}}
+	}
+
+	var rules models.BackendSwitchingRules
+	if backend.StickyCookie != "" {
+		for _, group := range groups {
+			names := make([]string, len(group.servers))
+			for i, index := range group.servers {
+				names[i] = servers[index]
+			}
+			rules = append(rules, &models.BackendSwitchingRule{
+				Name:     group.name,
+				Cond:     "if",
+				CondTest: fmt.Sprintf("%s { req.cook(%s) -m str %s }", cond, backend.StickyCookie, strings.Join(names, " ")),
+			})
+		}
+	}
+
+	weights := make([]int64, len(groups))
+	var total int64
+	for i, group := range groups {
+		weights[i] = getHostGroupWeight(group, backend)
+		total += weights[i]
+	}
+	last := -1
+	for i, group := range groups {
+		if weights[i] == 0 {
+			continue
+		}
+		condTest := fmt.Sprintf("%s { nbsrv(%s) gt 0 }", cond, group.name)
+		// 最后一个有权重的分组不需要随机
+		if weights[i] < total {
+			condTest += fmt.Sprintf(" { rand(%d) lt %d }", total, weights[i])
+		} else {
+			last = i
+		}
+		rules = append(rules, &models.BackendSwitchingRule{Name: group.name, Cond: "if", CondTest: condTest})
+		total -= weights[i]
+	}
+	for i, group := range groups {
+		if i == last {
+			continue
+		}
+		rules = append(rules, &models.BackendSwitchingRule{
+			Name:     group.name,
+			Cond:     "if",
+			CondTest: fmt.Sprintf("%s { nbsrv(%s) gt 0 }", cond, group.name),
+		})
+	}
+	return append(rules, &models.BackendSwitchingRule{Name: groups[0].name, Cond: "if", CondTest: cond})
+}
+
+// getHostGroupWeight 返回分组内主服务器的配置权重之和，备用、维护和摘流的服务器不计入
+func getHostGroupWeight(group hostGroup, backend model.Backend) int64 {
+	var weight int64
+	for _, index := range group.servers {
+		server := backend.Servers[index]
+		if server.Backup || server.State == model.ServerStateMaint || server.State == model.ServerStateDrain {
+			continue
+		}
+		weight += max(server.Weight, 1)
+	}
+	return weight
+}
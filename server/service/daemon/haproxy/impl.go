@@ -40,31 +40,38 @@ const (
 )
 
 type HAProxyServiceImpl struct {
-	ConfigBaseDir      string
-	HAProxyConfigFile  string // 配置文件路径
-	HaproxyBin         string // HAProxy二进制文件路径
-	BackupsNumber      int
-	CertDir            string // 证书目录
-	TransactionDir     string // 事务目录
-	SpoeDir            string // SPOE目录
-	SpoeTransactionDir string // SPOE事务目录
-	SocketFile         string // 套接字文件路径
-	PidFile            string // PID文件路径
-	SpoeConfigFile     string // SPOE配置文件路径
-	SpoeAgentAddress   string // SPOE代理地址
-	SpoeAgentPort      int64  // SPOE代理端口
+	ConfigBaseDir        string
+	HAProxyConfigFile    string // 配置文件路径
+	HaproxyBin           string // HAProxy二进制文件路径
+	BackupsNumber        int
+	CertDir              string // 证书目录
+	VersionDir           string // 已生效配置的历史版本目录
+	TransactionDir       string // 事务目录
+	SpoeDir              string // SPOE目录
+	SpoeTransactionDir   string // SPOE事务目录
+	SocketFile           string // 套接字文件路径
+	PidFile              string // PID文件路径
+	SpoeConfigFile       string // SPOE配置文件路径
+	BlockedIPMapFile     string // 封禁IP映射文件路径
+	ACLDir               string // 卸载的微规则引用的 ACL 模式文件目录
+	StagingDir           string // 生成和检查新配置的暂存目录
+	SpoeAgentAddress     string // SPOE代理地址
+	SpoeAgentPort        int64  // SPOE代理端口
+	ACMEChallengeAddress string // ACME HTTP-01 验证请求转发到的管理服务地址
 
 	// internal field
-	haproxyCmd      *exec.Cmd                   // HAProxy进程命令
-	confClient      configuration.Configuration // 配置客户端
-	runtimeClient   runtime_api.Runtime         // 运行时客户端
-	spoeClient      spoe.Spoe                   // SPOE客户端
-	clientNative    client_native.HAProxyClient // 完整客户端
-	isResponseCheck bool                        // 是否启用响应处理
-	status          atomic.Int32                // 使用原子操作的状态
-	isDebug         bool                        // 是否为生产环境
-	isK8s           bool                        // 是否为K8s环境
-	thread          int                         // 线程数
+	haproxyCmd       *exec.Cmd                   // HAProxy进程命令
+	confClient       configuration.Configuration // 配置客户端
+	runtimeClient    runtime_api.Runtime         // 运行时客户端
+	spoeClient       spoe.Spoe                   // SPOE客户端
+	clientNative     client_native.HAProxyClient // 完整客户端
+	isResponseCheck  bool                        // 是否启用响应处理
+	status           atomic.Int32                // 使用原子操作的状态
+	isDebug          bool                        // 是否为生产环境
+	isK8s            bool                        // 是否为K8s环境
+	thread           int                         // 线程数
+	staged           *stagedBuild                // 暂存构建中推迟执行的文件操作，不在暂存构建中时为 nil
+	acmePendingHosts map[string]int              // 正在进行 HTTP-01 验证的主机名及其订单数
 
 	logger zerolog.Logger
 	ctx    context.Context
@@ -144,239 +151,6 @@ func (s *HAProxyServiceImpl) Start() error {
 	return nil
 }
 
-func (s *HAProxyServiceImpl) AddSiteConfig(site model.Site) error {
-	if err := model.ValidateSite(&site); err != nil {
-		return fmt.Errorf("site config invalid: %v", err)
-	}
-
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	if !site.ActiveStatus {
-		return nil
-	}
-
-	s.logger.Info().Msgf("添加站点配置 %s", site.Domain)
-
-	// 确保配置客户端初始化
-	if err := s.ensureConfClient(); err != nil {
-		return err
-	}
-	if _, err := s.getFeCombined(site.ListenPort); err != nil {
-		err = s.createFeCombined(site.ListenPort, site.EnableHTTPS)
-		if err != nil {
-			return fmt.Errorf("创建前端组合失败: %v", err)
-		}
-	}
-
-	version, err := s.confClient.GetVersion("")
-	if err != nil {
-		return fmt.Errorf("获取版本失败: %v", err)
-	}
-	transaction, err := s.confClient.StartTransaction(version)
-	if err != nil {
-		return fmt.Errorf("启动事务失败: %v", err)
-	}
-
-	// handle http
-	if isIPAddress(site.Domain) {
-		// IP address handling
-		err = s.confClient.DeleteServer("loopback-for-default", "backend", fmt.Sprintf("p%d_backend", site.ListenPort), transaction.ID, 0)
-		if err != nil {
-			return fmt.Errorf("删除后端服务器失败: %v", err)
-		}
-
-		for index, server := range site.Backend.Servers {
-			err = s.createBackendServer(fmt.Sprintf("s%s_%d", getDashDomain(site.Domain), index), server.Host, server.Port, transaction.ID, fmt.Sprintf("p%d_backend", site.ListenPort), server.IsSSL)
-			if err != nil {
-				return fmt.Errorf("创建后端服务器失败: %v", err)
-			}
-		}
-
-	} else {
-		_, aclList, err := s.confClient.GetACLs("frontend", fmt.Sprintf("fe_%d_http", site.ListenPort), "")
-		if err != nil {
-			return fmt.Errorf("获取 ACL 失败: %v", err)
-		}
-		aclIndex := len(aclList)
-		acl_http := &models.ACL{
-			ACLName:   fmt.Sprintf("host_%s", getDashDomain(site.Domain)), // 使用ACLName字段
-			Criterion: "hdr(host) -i -m end",
-			Value:     site.Domain, // 使用Value字段
-			// Criterion: "hdr(host) -i",                                     // 使用Criterion字段
-			// Value:     site.Domain,                                        // 使用Value字段
-		}
-		err = s.confClient.CreateACL(int64(aclIndex), "frontend", fmt.Sprintf("fe_%d_http", site.ListenPort), acl_http, transaction.ID, 0)
-		if err != nil {
-			return fmt.Errorf("创建 ACL 失败: %v", err)
-		}
-
-		backend_http := &models.Backend{
-			BackendBase: models.BackendBase{
-				Name:    fmt.Sprintf("be_%s", getDashDomain(site.Domain)),
-				Mode:    "http",
-				Enabled: true,
-				From:    "http",
-				// 添加forwarded选项
-				Forwardfor: &models.Forwardfor{
-					Enabled: StringP("enabled"),
-					Ifnone:  true,
-				},
-			},
-		}
-		err = s.confClient.CreateBackend(backend_http, transaction.ID, 0)
-		if err != nil {
-			return fmt.Errorf("创建后端失败: %v", err)
-		}
-
-		if s.isK8s {
-			/*
-				Host Header Rewriting "Origin Host Forwarding"（原始主机转发）
-					确保后端服务器接收到正确的原始主机名
-					实现基于主机名的虚拟主机服务
-					解决多层代理环境中的路由问题
-					满足特定后端服务对 Host 头的要求
-					实现透明代理
-			*/
-			site_backend_request_rule := []struct {
-				index int64
-				rule  *models.HTTPRequestRule
-			}{
-				{
-					index: 0,
-					rule: &models.HTTPRequestRule{
-						Type:      "set-header",
-						HdrName:   "X-Original-Host",
-						HdrFormat: "%[req.hdr(host)]",
-					},
-				},
-				{
-					index: 1,
-					rule: &models.HTTPRequestRule{
-						Type:    "set-header",
-						HdrName: "Host",
-						// TODO: 在 k8s 中，如果后端域名有多个，这里只是传递了第一个后端域名，在碰到其他后端时，透明传递的 Host 头是错误的，
-						// TODO: 待解决,不同后端域名，透明传递的 Host 头是不同的，需要根据后端域名来传递 Host 头
-						HdrFormat: site.Backend.Servers[0].Host,
-					},
-				},
-			}
-
-			for _, item := range site_backend_request_rule {
-				err = s.confClient.CreateHTTPRequestRule(item.index, "backend", backend_http.Name, item.rule, transaction.ID, 0)
-				if err != nil {
-					return fmt.Errorf("站点 %s 后端 %s 添加HTTP请求规则 #%d 错误: %v", site.Domain, backend_http.Name, item.index, err)
-				}
-			}
-		}
-
-		_, switchingRules, err := s.confClient.GetBackendSwitchingRules(fmt.Sprintf("fe_%d_http", site.ListenPort), "")
-		if err != nil {
-			return fmt.Errorf("获取后端切换规则失败: %v", err)
-		}
-		switchingRuleIndex := len(switchingRules)
-
-		httpUseBackendRule := &models.BackendSwitchingRule{
-			Name:     backend_http.Name,
-			Cond:     "if",
-			CondTest: acl_http.ACLName,
-		}
-		err = s.confClient.CreateBackendSwitchingRule(int64(switchingRuleIndex), fmt.Sprintf("fe_%d_http", site.ListenPort), httpUseBackendRule, transaction.ID, 0)
-		if err != nil {
-			return fmt.Errorf("创建后端切换规则失败: %v", err)
-		}
-
-		for index, server := range site.Backend.Servers {
-			err = s.createBackendServer(fmt.Sprintf("%s_%d", getDashDomain(site.Domain), index), server.Host, server.Port, transaction.ID, backend_http.Name, server.IsSSL)
-			if err != nil {
-				return fmt.Errorf("创建后端服务器失败: %v", err)
-			}
-		}
-	}
-
-	// handle https
-	if site.EnableHTTPS {
-		// add cert
-		err = s.addSiteCert(site)
-		if err != nil {
-			return fmt.Errorf("添加证书失败: %v", err)
-		}
-		// cert load
-		crtLoad := &models.CrtLoad{
-			Certificate: site.Domain + ".crt",
-			Key:         site.Domain + ".key",
-			Alias:       fmt.Sprintf("%s_cert", getDashDomain(site.Domain)),
-		}
-		err = s.confClient.CreateCrtLoad("sites", crtLoad, transaction.ID, 0)
-		if err != nil {
-			return fmt.Errorf("创建证书加载失败: %v", err)
-		}
-
-		// change bind
-		_, https_bind, err := s.confClient.GetBind("internal_https", "frontend", fmt.Sprintf("fe_%d_https", site.ListenPort), "")
-		if err != nil {
-			return fmt.Errorf("获取绑定失败: %v", err)
-		}
-
-		if len(https_bind.BindParams.DefaultCrtList) > 0 {
-			https_bind.BindParams.DefaultCrtList = append(https_bind.BindParams.DefaultCrtList, fmt.Sprintf("@sites/%s_cert", getDashDomain(site.Domain)))
-		} else {
-			https_bind.BindParams.DefaultCrtList = []string{
-				fmt.Sprintf("@sites/%s_cert", getDashDomain(site.Domain)),
-			}
-		}
-		https_bind.Ssl = true
-
-		err = s.confClient.EditBind("internal_https", "frontend", fmt.Sprintf("fe_%d_https", site.ListenPort), https_bind, transaction.ID, 0)
-		if err != nil {
-			return fmt.Errorf("修改绑定失败: %v", err)
-		}
-
-		_, aclList, err := s.confClient.GetACLs("frontend", fmt.Sprintf("fe_%d_https", site.ListenPort), "")
-		if err != nil {
-			return fmt.Errorf("获取 ACL 失败: %v", err)
-		}
-		aclIndex := len(aclList)
-		// add ack and rule backend
-		acl_https := &models.ACL{
-			ACLName:   fmt.Sprintf("host_%s", getDashDomain(site.Domain)), // 使用ACLName字段
-			Criterion: "hdr(host) -i -m end",                              // 修改Criterion字段使用-m end
-			Value:     site.Domain,                                        // 在域名前加上点号
-			// Criterion: "hdr(host) -i",                                     // 使用Criterion字段
-			// Value:     site.Domain,                                        // 使用Value字段
-		}
-		err = s.confClient.CreateACL(int64(aclIndex), "frontend", fmt.Sprintf("fe_%d_https", site.ListenPort), acl_https, transaction.ID, 0)
-		if err != nil {
-			return fmt.Errorf("创建 ACL 失败: %v", err)
-		}
-
-		_, switchingRules, err := s.confClient.GetBackendSwitchingRules(fmt.Sprintf("fe_%d_https", site.ListenPort), "")
-		if err != nil {
-			return fmt.Errorf("获取后端切换规则失败: %v", err)
-		}
-		switchingRuleIndex := len(switchingRules)
-		httpsUseBackendRule := &models.BackendSwitchingRule{
-			Name:     fmt.Sprintf("be_%s", getDashDomain(site.Domain)),
-			Cond:     "if",
-			CondTest: acl_https.ACLName,
-		}
-		err = s.confClient.CreateBackendSwitchingRule(int64(switchingRuleIndex), fmt.Sprintf("fe_%d_https", site.ListenPort), httpsUseBackendRule, transaction.ID, 0)
-		if err != nil {
-			return fmt.Errorf("创建后端切换规则失败: %v", err)
-		}
-
-	}
-
-	transaction, err = s.confClient.CommitTransaction(transaction.ID)
-	if err != nil {
-		return fmt.Errorf("提交事务失败: %v", err)
-	}
-
-	s.confClient.DeleteTransaction(transaction.ID)
-
-	return nil
-}
-
 func (s *HAProxyServiceImpl) Stop() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -392,99 +166,6 @@ func (s *HAProxyServiceImpl) Reload() error {
 	return s.reloadHAProxy()
 }
 
-func (s *HAProxyServiceImpl) HotReloadRemoveConfig() error {
-	s.mutex.Lock()
-	defer s.mutex.Unlock()
-
-	// 需要删除的文件，不包括 PidFile 和 SocketFile
-	filesToRemove := []string{
-		s.HAProxyConfigFile,
-		s.SpoeConfigFile,
-	}
-
-	// 保存 PidFile 和 SocketFile 的绝对路径，以便后续比较
-	pidFileAbs, _ := filepath.Abs(s.PidFile)
-	socketFileAbs, _ := filepath.Abs(s.SocketFile)
-
-	// 需要删除的目录
-	dirsToRemove := []string{
-		s.TransactionDir,
-		s.SpoeTransactionDir,
-		s.CertDir,
-	}
-
-	// 特殊处理 filepath.Dir(s.HAProxyConfigFile)
-	configDir := filepath.Dir(s.HAProxyConfigFile)
-	if configDir != "" {
-		// 先列出该目录下所有文件
-		entries, err := os.ReadDir(configDir)
-		if err == nil {
-			// 逐个删除文件，跳过 PidFile 和 SocketFile
-			for _, entry := range entries {
-				if entry.IsDir() {
-					continue // 跳过子目录，我们会单独处理目录
-				}
-
-				filePath := filepath.Join(configDir, entry.Name())
-				filePathAbs, _ := filepath.Abs(filePath)
-
-				// 检查是否是要保留的文件
-				if filePathAbs == pidFileAbs || filePathAbs == socketFileAbs {
-					continue // 跳过 PidFile 和 SocketFile
-				}
-
-				s.logger.Info().Msgf("正在删除文件: %s", filePath)
-				if err := os.Remove(filePath); err != nil {
-					s.logger.Error().Msgf("删除文件失败 %s: %v", filePath, err)
-					// 继续删除其他文件，不立即返回错误
-				}
-			}
-		}
-	}
-
-	// 删除文件
-	for _, file := range filesToRemove {
-		if file == "" {
-			continue // 跳过空路径
-		}
-
-		// 检查文件是否存在
-		if _, err := os.Stat(file); err == nil {
-			// 确认不是需要保留的文件
-			fileAbs, _ := filepath.Abs(file)
-			if fileAbs == pidFileAbs || fileAbs == socketFileAbs {
-				continue // 跳过 PidFile 和 SocketFile
-			}
-
-			s.logger.Info().Msgf("正在删除文件: %s", file)
-			if err := os.Remove(file); err != nil {
-				s.logger.Error().Msgf("删除文件失败 %s: %v", file, err)
-				// 继续删除其他文件，不立即返回错误
-			}
-		}
-	}
-
-	// 由于我们需要保留configDir中的某些文件，所以不能直接删除configDir
-	// 删除其他目录
-	for _, dir := range dirsToRemove {
-		if dir == "" || dir == configDir {
-			continue // 跳过空路径和配置目录
-		}
-
-		// 检查目录是否存在
-		if _, err := os.Stat(dir); err == nil {
-			s.logger.Info().Msgf("正在删除目录: %s", dir)
-			if err := os.RemoveAll(dir); err != nil {
-				s.logger.Error().Msgf("删除目录失败 %s: %v", dir, err)
-				// 继续删除其他目录，不立即返回错误
-			}
-		}
-	}
-
-	s.logger.Info().Msg("配置清理完成")
-	return nil
-}
-
 func (s *HAProxyServiceImpl) RemoveConfig() error {
 	s.mutex.Lock()
 	defer s.mutex.Unlock()
@@ -503,6 +184,7 @@ func (s *HAProxyServiceImpl) RemoveConfig() error {
 		s.TransactionDir,
 		s.SpoeTransactionDir,
 		s.CertDir,
+		s.StagingDir,
 	}
 
 	// 删除文件
@@ -734,9 +416,11 @@ func (s *HAProxyServiceImpl) InitSpoeConfig() error {
 		return fmt.Errorf("创建 SPOE 代理错误: %v", err)
 	}
 
-	// 创建 coraza-req 消息
+	// 创建 coraza-req 消息，已被卸载到 HAProxy 的微规则拒绝的请求不再发送
 	reqEvent := &models.SpoeMessageEvent{
-		Name: StringP("on-frontend-http-request"),
+		Name:     StringP("on-frontend-http-request"),
+		Cond:     "unless",
+		CondTest: fmt.Sprintf("{ var(txn.%s) -m str deny }", microActionVar),
 	}
 	reqMsg := &models.SpoeMessage{
 		Name:  StringP("coraza-req"),
@@ -885,6 +569,7 @@ func (s *HAProxyServiceImpl) Reset() error {
 	}
 
 	s.thread = appConfig.Haproxy.Thread
+	s.BackupsNumber = appConfig.Haproxy.BackupsNumber
 	s.isResponseCheck = appConfig.IsResponseCheck
 	s.isDebug = appConfig.IsDebug
 	s.isK8s = appConfig.IsK8s
@@ -942,7 +627,9 @@ func (s *HAProxyServiceImpl) initRuntimeClient() error {
 	}
 
 	ms := runtime_options.MasterSocket(s.SocketFile)
-	runtimeClient, err := runtime_api.New(s.ctx, ms)
+	// 运行时 API 按名称在映射目录中查找封禁IP映射文件
+	md := runtime_options.MapsDir(filepath.Dir(s.BlockedIPMapFile))
+	runtimeClient, err := runtime_api.New(s.ctx, ms, md)
 	if err != nil {
 		return fmt.Errorf("init runtime client failed: %v", err)
 	}
@@ -1154,78 +841,10 @@ func (s *HAProxyServiceImpl) getHAProxyPid() (int, error) {
 	return pid, nil
 }
 
-func (s *HAProxyServiceImpl) addSiteCert(site model.Site) error {
-	// 检查是否启用了HTTPS且证书信息不为空
-	if !site.EnableHTTPS || site.Certificate.PublicKey == "" || site.Certificate.PrivateKey == "" {
-		return fmt.Errorf("site cert invalid or not enable https")
-	}
-
-	// 确保证书目录存在
-	if err := os.MkdirAll(s.CertDir, 0755); err != nil {
-		return fmt.Errorf("failed to create certificate directory: %w", err)
-	}
-
-	// 构建证书文件路径
-	certPath := filepath.Join(s.CertDir, site.Domain+".crt")
-	keyPath := filepath.Join(s.CertDir, site.Domain+".key")
-
-	// 写入公钥证书文件（覆盖模式）
-	if err := os.WriteFile(certPath, []byte(site.Certificate.PublicKey), 0644); err != nil {
-		return fmt.Errorf("failed to write certificate file: %w", err)
-	}
-
-	// 写入私钥文件（覆盖模式）
-	if err := os.WriteFile(keyPath, []byte(site.Certificate.PrivateKey), 0600); err != nil {
-		return fmt.Errorf("failed to write private key file: %w", err)
-	}
-
-	return nil
-}
-
-func (s *HAProxyServiceImpl) removeSiteCert(site model.Site) error {
-	// 构建证书文件路径
-	certPath := filepath.Join(s.CertDir, site.Domain+".crt")
-	keyPath := filepath.Join(s.CertDir, site.Domain+".key")
-
-	// 删除证书文件，忽略不存在的情况
-	if err := os.Remove(certPath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove certificate file: %w", err)
-	}
-
-	// 删除私钥文件，忽略不存在的情况
-	if err := os.Remove(keyPath); err != nil && !os.IsNotExist(err) {
-		return fmt.Errorf("failed to remove private key file: %w", err)
-	}
-
-	return nil
-}
-
-func (s *HAProxyServiceImpl) getFeCombined(port int) (string, error) {
-	if err := s.ensureConfClient(); err != nil {
-		return "", fmt.Errorf("初始化配置客户端失败: %v", err)
-	}
-
-	_, frontend, err := s.confClient.GetFrontend(fmt.Sprintf("fe_%d_combined", port), "")
-	if err != nil {
-		return "", fmt.Errorf("获取前端失败: %v", err)
-	}
-	return frontend.Name, nil
-}
-
-func (s *HAProxyServiceImpl) createFeCombined(port int, isHttpsRedirect bool) error {
-	// 确保配置客户端初始化
-	if err := s.ensureConfClient(); err != nil {
-		return fmt.Errorf("初始化配置客户端失败: %v", err)
-	}
-	version, err := s.confClient.GetVersion("")
-	if err != nil {
-		return fmt.Errorf("获取版本失败: %v", err)
-	}
-	transaction, err := s.confClient.StartTransaction(version)
-	if err != nil {
-		return fmt.Errorf("启动事务失败: %v", err)
-	}
-
+// createPortFrontends 在事务中为监听端口创建 TCP 组合前端和站点 HTTP/HTTPS 前端
+// 组合前端根据首包区分 HTTP 和 TLS 流量，通过抽象命名空间 socket 转发给对应的站点前端
+// 端口默认后端 p(port)_backend 由站点后端一同维护，站点前端的 HTTP 请求和响应规则按端口的期望配置生成
+func (s *HAProxyServiceImpl) createPortFrontends(port int, conf *desiredPort, transactionID string) error {
 	// 创建 fe_(port)_combined
 	fe_combined := &models.Frontend{
 		FrontendBase: models.FrontendBase{
@@ -1234,9 +853,10 @@ func (s *HAProxyServiceImpl) createFeCombined(port int, isHttpsRedirect bool) er
 			DefaultBackend: fmt.Sprintf("be_%d_https", port), // 设置默认后端
 			Enabled:        true,
 			From:           "tcp",
+			Maxconn:        limitP(conf.limits.maxConn),
 		},
 	}
-	err = s.confClient.CreateFrontend(fe_combined, transaction.ID, 0)
+	err := s.confClient.CreateFrontend(fe_combined, transactionID, 0)
 
 	if err != nil {
 		return fmt.Errorf("创建前端失败: %v", err)
@@ -1249,44 +869,17 @@ func (s *HAProxyServiceImpl) createFeCombined(port int, isHttpsRedirect bool) er
 		Address: "*",
 		Port:    Int64P(int64(port)),
 	}
-	err = s.confClient.CreateBind("frontend", fe_combined.Name, bind, transaction.ID, 0)
+	err = s.confClient.CreateBind("frontend", fe_combined.Name, bind, transactionID, 0)
 	if err != nil {
 		return fmt.Errorf("创建绑定失败: %v", err)
 	}
 
 	// rule
-
-	tcpInspectDelay := &models.TCPRequestRule{
-		Type:    "inspect-delay",
-		Timeout: Int64P(2),
-	}
-	err = s.confClient.CreateTCPRequestRule(0, "frontend", fe_combined.Name, tcpInspectDelay, transaction.ID, 0)
-
-	if err != nil {
-		return fmt.Errorf("创建TCP请求规则失败: %v", err)
-	}
-
-	tcpAcceptHTTP := &models.TCPRequestRule{
-		Action:   "accept",
-		Type:     "content",
-		Cond:     "if",
-		CondTest: "HTTP",
-	}
-
-	err = s.confClient.CreateTCPRequestRule(1, "frontend", fe_combined.Name, tcpAcceptHTTP, transaction.ID, 0)
-	if err != nil {
-		return fmt.Errorf("创建TCP请求规则失败: %v", err)
-	}
-
-	tcpAcceptSSL := &models.TCPRequestRule{
-		Action:   "accept",
-		Type:     "content",
-		Cond:     "if",
-		CondTest: "{ req.ssl_hello_type 1 }",
-	}
-	err = s.confClient.CreateTCPRequestRule(2, "frontend", fe_combined.Name, tcpAcceptSSL, transaction.ID, 0)
-	if err != nil {
-		return fmt.Errorf("创建TCP请求规则失败: %v", err)
+	for i, rule := range buildCombinedTCPRequestRules(port, conf.limits) {
+		err = s.confClient.CreateTCPRequestRule(int64(i), "frontend", fe_combined.Name, rule, transactionID, 0)
+		if err != nil {
+			return fmt.Errorf("创建TCP请求规则失败: %v", err)
+		}
 	}
 
 	// backend
@@ -1295,7 +888,7 @@ func (s *HAProxyServiceImpl) createFeCombined(port int, isHttpsRedirect bool) er
 		Cond:     "if",
 		CondTest: "HTTP",
 	}
-	err = s.confClient.CreateBackendSwitchingRule(0, fe_combined.Name, useBackendRule, transaction.ID, 0)
+	err = s.confClient.CreateBackendSwitchingRule(0, fe_combined.Name, useBackendRule, transactionID, 0)
 	if err != nil {
 		return fmt.Errorf("创建后端切换规则失败: %v", err)
 	}
@@ -1309,7 +902,7 @@ func (s *HAProxyServiceImpl) createFeCombined(port int, isHttpsRedirect bool) er
 			From:    "tcp",
 		},
 	}
-	err = s.confClient.CreateBackend(be_http, transaction.ID, 0)
+	err = s.confClient.CreateBackend(be_http, transactionID, 0)
 	if err != nil {
 		return fmt.Errorf("创建后端失败: %v", err)
 	}
@@ -1323,7 +916,7 @@ func (s *HAProxyServiceImpl) createFeCombined(port int, isHttpsRedirect bool) er
 		Port:    Int64P(1000),
 	}
 
-	err = s.confClient.CreateServer("backend", be_http.Name, serverHTTP, transaction.ID, 0)
+	err = s.confClient.CreateServer("backend", be_http.Name, serverHTTP, transactionID, 0)
 	if err != nil {
 		return fmt.Errorf("创建服务器失败: %v", err)
 	}
@@ -1336,7 +929,7 @@ func (s *HAProxyServiceImpl) createFeCombined(port int, isHttpsRedirect bool) er
 			From:    "tcp",
 		},
 	}
-	err = s.confClient.CreateBackend(be_https, transaction.ID, 0)
+	err = s.confClient.CreateBackend(be_https, transactionID, 0)
 	if err != nil {
 		return fmt.Errorf("创建后端失败: %v", err)
 	}
@@ -1350,372 +943,93 @@ func (s *HAProxyServiceImpl) createFeCombined(port int, isHttpsRedirect bool) er
 		Port:    Int64P(1000),
 	}
 
-	err = s.confClient.CreateServer("backend", be_https.Name, serverHTTPS, transaction.ID, 0)
+	err = s.confClient.CreateServer("backend", be_https.Name, serverHTTPS, transactionID, 0)
 	if err != nil {
 		return fmt.Errorf("创建服务器失败: %v", err)
 	}
 
 	// create fe_(port)_http  fe_(port)_https
-
-	// fe_(port)_http
-	fe_http := &models.Frontend{
-		FrontendBase: models.FrontendBase{
-			Name:           fmt.Sprintf("fe_%d_http", port),
-			Mode:           "http",
-			DefaultBackend: fmt.Sprintf("p%d_backend", port),
-			Enabled:        true,
-			From:           "http",
-			// 日志格式使用反斜杠转义空格和特殊字符
-			LogFormat: "\"%ci:%cp\\ [%t]\\ %ft\\ %b/%s\\ %Th/%Ti/%TR/%Tq/%Tw/%Tc/%Tr/%Tt\\ %ST\\ %B\\ %CC\\ %CS\\ %tsc\\ %ac/%fc/%bc/%sc/%rc\\ %sq/%bq\\ %hr\\ %hs\\ %{+Q}r\\ %[var(txn.coraza.id)]\\ spoa-error:\\ %[var(txn.coraza.error)]\\ waf-hit:\\ %[var(txn.coraza.status)]\"",
-			Forwardfor: &models.Forwardfor{
-				Enabled: StringP("enabled"),
-				Ifnone:  true,
+	siteFrontends := []struct {
+		name          string
+		bindName      string
+		bindAddress   string
+		requestRules  models.HTTPRequestRules
+		responseRules models.HTTPResponseRules
+		tcpRules      models.TCPRequestRules
+	}{
+		{fmt.Sprintf("fe_%d_http", port), "internal_http", fmt.Sprintf("abns@haproxy-%d-http", port), buildFeHTTPRequestRules(), buildFeHTTPResponseRules(), s.siteFrontendTCPRules(conf.httpTCP)},
+		{fmt.Sprintf("fe_%d_https", port), "internal_https", fmt.Sprintf("abns@haproxy-%d-https", port), conf.httpsRequestRules(), conf.httpsResponseRules(), s.siteFrontendTCPRules(conf.httpsTCP)},
+	}
+	for _, item := range siteFrontends {
+		frontend := &models.Frontend{
+			FrontendBase: models.FrontendBase{
+				Name:           item.name,
+				Mode:           "http",
+				DefaultBackend: getPortDefaultBackendName(port),
+				Enabled:        true,
+				From:           "http",
+				// 日志格式使用反斜杠转义空格和特殊字符
+				LogFormat: "\"%ci:%cp\\ [%t]\\ %ft\\ %b/%s\\ %Th/%Ti/%TR/%Tq/%Tw/%Tc/%Tr/%Tt\\ %ST\\ %B\\ %CC\\ %CS\\ %tsc\\ %ac/%fc/%bc/%sc/%rc\\ %sq/%bq\\ %hr\\ %hs\\ %{+Q}r\\ %[var(txn.coraza.id)]\\ spoa-error:\\ %[var(txn.coraza.error)]\\ waf-hit:\\ %[var(txn.coraza.status)]\"",
+				Forwardfor: &models.Forwardfor{
+					Enabled: StringP("enabled"),
+					Ifnone:  true,
+				},
+				HTTPRequestTimeout: limitP(conf.limits.httpRequestTimeout),
 			},
-		},
-	}
-	err = s.confClient.CreateFrontend(fe_http, transaction.ID, 0)
-	if err != nil {
-		return fmt.Errorf("创建前端失败: %v", err)
-	}
-
-	fe_http_bind := &models.Bind{
-		BindParams: models.BindParams{
-			Name:        "internal_http",
-			AcceptProxy: true,
-		},
-		Port:    Int64P(1000),
-		Address: fmt.Sprintf("abns@haproxy-%d-http", port),
-	}
-
-	err = s.confClient.CreateBind("frontend", fe_http.Name, fe_http_bind, transaction.ID, 0)
-	if err != nil {
-		return fmt.Errorf("创建绑定失败: %v", err)
-	}
-
-	// 添加 spoe 过滤
-	fe_http_filter := &models.Filter{
-		Type:       "spoe",           // 过滤器类型
-		SpoeEngine: "coraza",         // SPOE引擎名称
-		SpoeConfig: s.SpoeConfigFile, // 使用配置文件的标准路径
-	}
-	err = s.confClient.CreateFilter(0, "frontend", fe_http.Name, fe_http_filter, transaction.ID, 0)
-	if err != nil {
-		return fmt.Errorf("创建过滤器失败: %v", err)
-	}
-
-	// 添加HTTP请求规则
-	var fe_http_request_rule []struct {
-		index int64
-		rule  *models.HTTPRequestRule
-	}
-	if isHttpsRedirect {
-		fe_http_request_rule = []struct {
-			index int64
-			rule  *models.HTTPRequestRule
-		}{
-			{0, &models.HTTPRequestRule{
-				Type:       "redirect",
-				RedirCode:  Int64P(301),
-				RedirType:  "scheme",
-				RedirValue: "https",
-			}},
-			{1, &models.HTTPRequestRule{
-				Type:       "redirect",
-				RedirCode:  Int64P(302),
-				RedirType:  "location", // 指定重定向类型
-				RedirValue: "%[var(txn.coraza.data)]",
-				Cond:       "if",
-				CondTest:   "{ var(txn.coraza.action) -m str redirect }",
-			}},
-			{2, &models.HTTPRequestRule{
-				Type:       "deny",
-				DenyStatus: Int64P(403),
-				HdrName:    "waf-block", // 设置头部名称
-				HdrFormat:  "request",   // 设置头部值
-				Cond:       "if",
-				CondTest:   "{ var(txn.coraza.action) -m str deny }",
-			}},
-			{3, &models.HTTPRequestRule{
-				Type:     "silent-drop",
-				Cond:     "if",
-				CondTest: "{ var(txn.coraza.action) -m str drop }",
-			}},
-			{4, &models.HTTPRequestRule{
-				Type:       "deny",
-				DenyStatus: Int64P(500),
-				Cond:       "if",
-				CondTest:   "{ var(txn.coraza.error) -m int gt 0 }",
-			}},
-		}
-	} else {
-
-		fe_http_request_rule = []struct {
-			index int64
-			rule  *models.HTTPRequestRule
-		}{
-			{0, &models.HTTPRequestRule{
-				Type:       "redirect",
-				RedirCode:  Int64P(302),
-				RedirType:  "location", // 指定重定向类型
-				RedirValue: "%[var(txn.coraza.data)]",
-				Cond:       "if",
-				CondTest:   "{ var(txn.coraza.action) -m str redirect }",
-			}},
-			{1, &models.HTTPRequestRule{
-				Type:       "deny",
-				DenyStatus: Int64P(403),
-				HdrName:    "waf-block", // 设置头部名称
-				HdrFormat:  "request",   // 设置头部值
-				Cond:       "if",
-				CondTest:   "{ var(txn.coraza.action) -m str deny }",
-			}},
-			{2, &models.HTTPRequestRule{
-				Type:     "silent-drop",
-				Cond:     "if",
-				CondTest: "{ var(txn.coraza.action) -m str drop }",
-			}},
-			{3, &models.HTTPRequestRule{
-				Type:       "deny",
-				DenyStatus: Int64P(500),
-				Cond:       "if",
-				CondTest:   "{ var(txn.coraza.error) -m int gt 0 }",
-			}},
 		}
-
-	}
-
-	for i, item := range fe_http_request_rule {
-		err = s.confClient.CreateHTTPRequestRule(item.index, "frontend", fe_http.Name, item.rule, transaction.ID, 0)
+		err = s.confClient.CreateFrontend(frontend, transactionID, 0)
 		if err != nil {
-			return fmt.Errorf("添加HTTP请求规则 #%d 错误: %v", i, err)
+			return fmt.Errorf("创建前端失败: %v", err)
 		}
-	}
 
-	// 添加HTTP响应规则 - 确保HTTP响应规则结构正确
-	fe_http_response_rule := []struct {
-		index int64
-		rule  *models.HTTPResponseRule
-	}{
-		{0, &models.HTTPResponseRule{
-			Type:       "redirect",
-			RedirCode:  Int64P(302),
-			RedirType:  "location", // 指定重定向类型
-			RedirValue: "%[var(txn.coraza.data)]",
-			Cond:       "if",
-			CondTest:   "{ var(txn.coraza.action) -m str redirect }",
-		}},
-		{1, &models.HTTPResponseRule{
-			Type:       "deny",
-			DenyStatus: Int64P(403),
-			HdrName:    "waf-block", // 设置头部名称
-			HdrFormat:  "response",  // 设置头部值
-			Cond:       "if",
-			CondTest:   "{ var(txn.coraza.action) -m str deny }",
-		}},
-		{2, &models.HTTPResponseRule{
-			Type:     "silent-drop",
-			Cond:     "if",
-			CondTest: "{ var(txn.coraza.action) -m str drop }",
-		}},
-		{3, &models.HTTPResponseRule{
-			Type:       "deny",
-			DenyStatus: Int64P(500),
-			Cond:       "if",
-			CondTest:   "{ var(txn.coraza.error) -m int gt 0 }",
-		}},
-	}
-
-	for i, item := range fe_http_response_rule {
-		err = s.confClient.CreateHTTPResponseRule(item.index, "frontend", fe_http.Name, item.rule, transaction.ID, 0)
-		if err != nil {
-			return fmt.Errorf("添加HTTP响应规则 #%d 错误: %v", i, err)
-		}
-	}
-
-	// fe_(port)_https
-	fe_https := &models.Frontend{
-		FrontendBase: models.FrontendBase{
-			Name:           fmt.Sprintf("fe_%d_https", port),
-			Mode:           "http",
-			DefaultBackend: fmt.Sprintf("p%d_backend", port),
-			Enabled:        true,
-			From:           "http",
-			// 日志格式使用反斜杠转义空格和特殊字符
-			LogFormat: "\"%ci:%cp\\ [%t]\\ %ft\\ %b/%s\\ %Th/%Ti/%TR/%Tq/%Tw/%Tc/%Tr/%Tt\\ %ST\\ %B\\ %CC\\ %CS\\ %tsc\\ %ac/%fc/%bc/%sc/%rc\\ %sq/%bq\\ %hr\\ %hs\\ %{+Q}r\\ %[var(txn.coraza.id)]\\ spoa-error:\\ %[var(txn.coraza.error)]\\ waf-hit:\\ %[var(txn.coraza.status)]\"",
-			Forwardfor: &models.Forwardfor{
-				Enabled: StringP("enabled"),
-				Ifnone:  true,
+		frontendBind := &models.Bind{
+			BindParams: models.BindParams{
+				Name:        item.bindName,
+				AcceptProxy: true,
 			},
-		},
-	}
-	err = s.confClient.CreateFrontend(fe_https, transaction.ID, 0)
-	if err != nil {
-		return fmt.Errorf("创建前端失败: %v", err)
-	}
-	fe_https_bind := &models.Bind{
-		BindParams: models.BindParams{
-			Name:        "internal_https",
-			AcceptProxy: true,
-		},
-		Port:    Int64P(1000),
-		Address: fmt.Sprintf("abns@haproxy-%d-https", port),
-	}
-	err = s.confClient.CreateBind("frontend", fe_https.Name, fe_https_bind, transaction.ID, 0)
-	if err != nil {
-		return fmt.Errorf("创建绑定失败: %v", err)
-	}
-
-	// 添加 spoe 过滤
-	fe_https_filter := &models.Filter{
-		Type:       "spoe",           // 过滤器类型
-		SpoeEngine: "coraza",         // SPOE引擎名称
-		SpoeConfig: s.SpoeConfigFile, // 使用配置文件的标准路径
-	}
-	err = s.confClient.CreateFilter(0, "frontend", fe_https.Name, fe_https_filter, transaction.ID, 0)
-	if err != nil {
-		return fmt.Errorf("创建过滤器失败: %v", err)
-	}
-
-	// 添加HTTPs请求规则
-	fe_https_request_rule := []struct {
-		index int64
-		rule  *models.HTTPRequestRule
-	}{
-		{0, &models.HTTPRequestRule{
-			Type:       "redirect",
-			RedirCode:  Int64P(302),
-			RedirType:  "location", // 指定重定向类型
-			RedirValue: "%[var(txn.coraza.data)]",
-			Cond:       "if",
-			CondTest:   "{ var(txn.coraza.action) -m str redirect }",
-		}},
-		{1, &models.HTTPRequestRule{
-			Type:       "deny",
-			DenyStatus: Int64P(403),
-			HdrName:    "waf-block", // 设置头部名称
-			HdrFormat:  "request",   // 设置头部值
-			Cond:       "if",
-			CondTest:   "{ var(txn.coraza.action) -m str deny }",
-		}},
-		{2, &models.HTTPRequestRule{
-			Type:     "silent-drop",
-			Cond:     "if",
-			CondTest: "{ var(txn.coraza.action) -m str drop }",
-		}},
-		{3, &models.HTTPRequestRule{
-			Type:       "deny",
-			DenyStatus: Int64P(500),
-			Cond:       "if",
-			CondTest:   "{ var(txn.coraza.error) -m int gt 0 }",
-		}},
-	}
-
-	for i, item := range fe_https_request_rule {
-		err = s.confClient.CreateHTTPRequestRule(item.index, "frontend", fe_https.Name, item.rule, transaction.ID, 0)
+			Port:    Int64P(1000),
+			Address: item.bindAddress,
+		}
+		err = s.confClient.CreateBind("frontend", frontend.Name, frontendBind, transactionID, 0)
 		if err != nil {
-			return fmt.Errorf("添加HTTP请求规则 #%d 错误: %v", i, err)
+			return fmt.Errorf("创建绑定失败: %v", err)
 		}
-	}
 
-	// 添加HTTPs响应规则 - 确保HTTP响应规则结构正确
-	fe_https_response_rule := []struct {
-		index int64
-		rule  *models.HTTPResponseRule
-	}{
-		{0, &models.HTTPResponseRule{
-			Type:       "redirect",
-			RedirCode:  Int64P(302),
-			RedirType:  "location", // 指定重定向类型
-			RedirValue: "%[var(txn.coraza.data)]",
-			Cond:       "if",
-			CondTest:   "{ var(txn.coraza.action) -m str redirect }",
-		}},
-		{1, &models.HTTPResponseRule{
-			Type:       "deny",
-			DenyStatus: Int64P(403),
-			HdrName:    "waf-block", // 设置头部名称
-			HdrFormat:  "response",  // 设置头部值
-			Cond:       "if",
-			CondTest:   "{ var(txn.coraza.action) -m str deny }",
-		}},
-		{2, &models.HTTPResponseRule{
-			Type:     "silent-drop",
-			Cond:     "if",
-			CondTest: "{ var(txn.coraza.action) -m str drop }",
-		}},
-		{3, &models.HTTPResponseRule{
-			Type:       "deny",
-			DenyStatus: Int64P(500),
-			Cond:       "if",
-			CondTest:   "{ var(txn.coraza.error) -m int gt 0 }",
-		}},
-	}
-
-	for i, item := range fe_https_response_rule {
-		err = s.confClient.CreateHTTPResponseRule(item.index, "frontend", fe_https.Name, item.rule, transaction.ID, 0)
+		// 添加 spoe 过滤
+		filter := &models.Filter{
+			Type:       "spoe",           // 过滤器类型
+			SpoeEngine: "coraza",         // SPOE引擎名称
+			SpoeConfig: s.SpoeConfigFile, // 使用配置文件的标准路径
+		}
+		err = s.confClient.CreateFilter(0, "frontend", frontend.Name, filter, transactionID, 0)
 		if err != nil {
-			return fmt.Errorf("添加HTTP响应规则 #%d 错误: %v", i, err)
+			return fmt.Errorf("创建过滤器失败: %v", err)
 		}
-	}
-
-	// default backend
-	be_default := &models.Backend{
-		BackendBase: models.BackendBase{
-			Name:    fmt.Sprintf("p%d_backend", port),
-			Mode:    "http",
-			Enabled: true,
-			From:    "http",
-			// 添加forwarded选项
-			Forwardfor: &models.Forwardfor{
-				Enabled: StringP("enabled"),
-				Ifnone:  true,
-			},
-		},
-	}
-	err = s.confClient.CreateBackend(be_default, transaction.ID, 0)
-	if err != nil {
-		return fmt.Errorf("创建后端失败: %v", err)
-	}
-
-	be_default_server := &models.Server{
-		Name:    "loopback-for-default",
-		Address: "httpbin.org",
-		Port:    Int64P(80),
-	}
-	err = s.confClient.CreateServer("backend", be_default.Name, be_default_server, transaction.ID, 0)
-	if err != nil {
-		return fmt.Errorf("创建后端服务器失败: %v", err)
-	}
 
-	transaction, err = s.confClient.CommitTransaction(transaction.ID)
-	if err != nil {
-		return fmt.Errorf("提交事务失败: %v", err)
-	}
-
-	s.confClient.DeleteTransaction(transaction.ID)
-
-	return nil
-
-}
+		// 封禁IP和按站点统计请求速率的规则在 SPOE 之前执行
+		for i, rule := range item.tcpRules {
+			err = s.confClient.CreateTCPRequestRule(int64(i), "frontend", frontend.Name, rule, transactionID, 0)
+			if err != nil {
+				return fmt.Errorf("添加TCP请求规则 #%d 错误: %v", i, err)
+			}
+		}
 
-func (s *HAProxyServiceImpl) createBackendServer(name, address string, port int, transactionID string, backendName string, isSsl bool) error {
-	server := &models.Server{
-		Name:    name,
-		Address: address,
-		Port:    Int64P(int64(port)),
-	}
+		for i, rule := range item.requestRules {
+			err = s.confClient.CreateHTTPRequestRule(int64(i), "frontend", frontend.Name, rule, transactionID, 0)
+			if err != nil {
+				return fmt.Errorf("添加HTTP请求规则 #%d 错误: %v", i, err)
+			}
+		}
 
-	if isSsl {
-		server.ServerParams = models.ServerParams{
-			Ssl: "enabled",
-			Sni: fmt.Sprintf("str(%s)", address),
-			// SslCafile: "",
-			Verify: "none", // 不验证证书
+		for i, rule := range item.responseRules {
+			err = s.confClient.CreateHTTPResponseRule(int64(i), "frontend", frontend.Name, rule, transactionID, 0)
+			if err != nil {
+				return fmt.Errorf("添加HTTP响应规则 #%d 错误: %v", i, err)
+			}
 		}
 	}
 
-	return s.confClient.CreateServer("backend", backendName, server, transactionID, 0)
-
+	return nil
 }
 
 // get haproxy stats
@@ -1756,6 +1070,140 @@ func GetSafeInt64(ptr *int64) int64 {
 	return *ptr
 }
 
+// SiteBackendServer 站点后端服务器及其在 HAProxy 中的名称
+type SiteBackendServer struct {
+	BackendName string       // HAProxy 后端名称
+	ServerName  string       // HAProxy 服务器名称
+	PathPrefix  string       // 所属路径路由，为空表示站点默认后端
+	RouteIndex  int          // 所属路径路由序号，-1 表示站点默认后端
+	ServerIndex int          // 在后端服务器列表中的序号
+	Server      model.Server // 服务器配置
+}
+
+// GetSiteBackendServers 返回站点的所有后端服务器，名称与 ApplySites 生成的配置一致
+func GetSiteBackendServers(site model.Site) []SiteBackendServer {
+	if isIPAddress(site.Domain) {
+		servers := make([]SiteBackendServer, len(site.Backend.Servers))
+		for index, server := range site.Backend.Servers {
+			servers[index] = SiteBackendServer{
+				BackendName: getPortDefaultBackendName(site.ListenPort),
+				ServerName:  getServerName(getIPSiteServerName(site, index), server),
+				RouteIndex:  -1,
+				ServerIndex: index,
+				Server:      server,
+			}
+		}
+		return servers
+	}
+
+	var servers []SiteBackendServer
+	appendBackend := func(backendName string, routeIndex int, pathPrefix string, backend model.Backend) {
+		for index, server := range backend.Servers {
+			servers = append(servers, SiteBackendServer{
+				BackendName: backendName,
+				ServerName:  getServerName(getBackendServerName(backendName, index), server),
+				PathPrefix:  pathPrefix,
+				RouteIndex:  routeIndex,
+				ServerIndex: index,
+				Server:      server,
+			})
+		}
+	}
+	appendBackend(GetSiteBackendName(site), -1, "", site.Backend)
+	for index, route := range site.Routes {
+		appendBackend(GetRouteBackendName(site, index), index, route.PathPrefix, route.Backend)
+	}
+	return servers
+}
+
+// GetSiteBackendName 返回站点默认后端的名称，IP 站点使用端口的默认后端
+func GetSiteBackendName(site model.Site) string {
+	if isIPAddress(site.Domain) {
+		return getPortDefaultBackendName(site.ListenPort)
+	}
+	return fmt.Sprintf("be_%s", getDashDomain(site.Domain))
+}
+
+// GetNextServerName 为站点默认后端（routeIndex 为 -1）或路径路由的后端生成一个未被使用的服务器名称
+func GetNextServerName(site model.Site, routeIndex int) string {
+	used := make(map[string]bool)
+	for _, server := range GetSiteBackendServers(site) {
+		used[server.ServerName] = true
+	}
+
+	backend := site.Backend
+	if routeIndex >= 0 {
+		backend = site.Routes[routeIndex].Backend
+	}
+	for index := len(backend.Servers); ; index++ {
+		var name string
+		switch {
+		case isIPAddress(site.Domain):
+			name = getIPSiteServerName(site, index)
+		case routeIndex >= 0:
+			name = getBackendServerName(GetRouteBackendName(site, routeIndex), index)
+		default:
+			name = getBackendServerName(GetSiteBackendName(site), index)
+		}
+		if !used[name] {
+			return name
+		}
+	}
+}
+
+// getServerName 返回服务器名称，未指定名称时使用按序号生成的名称
+func getServerName(generatedName string, server model.Server) string {
+	if server.Name != "" {
+		return server.Name
+	}
+	return generatedName
+}
+
+// getBackendServerName 返回后端中第 index 个服务器的名称
+func getBackendServerName(backendName string, index int) string {
+	return fmt.Sprintf("%s_%d", strings.TrimPrefix(backendName, "be_"), index)
+}
+
+// getIPSiteServerName 返回 IP 站点第 index 个服务器在端口默认后端中的名称
+func getIPSiteServerName(site model.Site, index int) string {
+	return fmt.Sprintf("s%s_%d", getDashDomain(site.Domain), index)
+}
+
+// GetRouteBackendName 返回站点第 index 条路径路由的后端名称
+func GetRouteBackendName(site model.Site, index int) string {
+	return fmt.Sprintf("be_%s_r%d", getDashDomain(site.Domain), index)
+}
+
+// getPortDefaultBackendName 返回端口默认后端的名称
+func getPortDefaultBackendName(port int) string {
+	return fmt.Sprintf("p%d_backend", port)
+}
+
+// getHostACLName 返回站点主机名 ACL 的名称
+func getHostACLName(site model.Site) string {
+	return fmt.Sprintf("host_%s", getDashDomain(site.Domain))
+}
+
+// getRouteACLName 返回站点第 index 条路径路由的 ACL 名称
+func getRouteACLName(site model.Site, index int) string {
+	return fmt.Sprintf("path_%s_r%d", getDashDomain(site.Domain), index)
+}
+
+// getSNIACLName 返回匹配站点 SNI 的 ACL 名称
+func getSNIACLName(site model.Site) string {
+	return fmt.Sprintf("sni_%s", getDashDomain(site.Domain))
+}
+
+// getClientAuthACLName 返回站点需要客户端证书的路径 ACL 名称
+func getClientAuthACLName(site model.Site) string {
+	return fmt.Sprintf("mtls_%s", getDashDomain(site.Domain))
+}
+
+// getCrtListName 返回端口 HTTPS 前端证书列表的文件名
+func getCrtListName(port int) string {
+	return fmt.Sprintf("fe_%d_https.crtlist", port)
+}
+
 func getDashDomain(domain string) string {
 	// 将域名中的点号替换为下划线
 	dashDomain := strings.ReplaceAll(domain, ".", "_")
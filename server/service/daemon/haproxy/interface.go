@@ -3,6 +3,7 @@ package haproxy
 import (
 	"context"
 	"fmt"
+	"net"
 	"path/filepath"
 
 	"github.com/HUAHUAI23/RuiQi/server/config"
@@ -12,18 +13,33 @@ import (
 
 type HAProxyService interface {
 	RemoveConfig() error
-	HotReloadRemoveConfig() error
-	CreateHAProxyCrtStore() error
-	InitSpoeConfig() error
-	InitHAProxyConfig() error
-	AddCorazaBackend() error
-	AddSiteConfig(site model.Site) error
+	BuildConfig(build func(builder ConfigBuilder) error) error
+	ApplySites(sites []model.Site, offload *MicroRuleOffload) (*SiteApplyResult, error)
+	UpdateCertificates(sites []model.Site) ([]string, error)
 	Start() error
 	Reload() error
 	Stop() error
 	GetStatus() HAProxyStatus
 	GetStats() (models.NativeStats, error)
 	Reset() error
+	SyncBlockedIPs(entries map[string]int64) (*BlockedIPSyncResult, error)
+	AddACMEChallengeHosts(hosts []string) error
+	RemoveACMEChallengeHosts(hosts []string) error
+	RuntimeAPI
+	// 配置检查与版本管理
+	ValidateConfig() error
+	SaveConfigVersion(reason string) (*ConfigVersion, error)
+	ListConfigVersions() ([]ConfigVersion, error)
+	GetConfigVersionContent(id string) (string, error)
+	RestoreConfigVersion(id string) error
+}
+
+// RuntimeAPI 通过运行时 API 直接修改运行中的 HAProxy，不重新加载配置，修改在下次重新生成配置后失效
+type RuntimeAPI interface {
+	SetServerState(backendName, serverName string, state model.ServerState) error
+	SetServerWeight(backendName, serverName string, weight int64) error
+	AddServer(backendName, serverName string, server model.Server, backend model.Backend) error
+	DeleteServer(backendName, serverName string) error
 }
 
 // NewHAProxyService 创建一个新的HAProxy服务实例
@@ -50,24 +66,41 @@ func NewHAProxyService(configBaseDir, haproxyBin string, ctx context.Context) (H
 	logger := config.GetLogger().With().Str("component", "haproxy").Logger()
 
 	return &HAProxyServiceImpl{
-		ConfigBaseDir:      configBaseDir,
-		HAProxyConfigFile:  filepath.Join(configBaseDir, "/haproxy/conf/haproxy.cfg"),
-		HaproxyBin:         haproxyBin,
-		BackupsNumber:      3,
-		CertDir:            filepath.Join(configBaseDir, "/haproxy/cert"),
-		TransactionDir:     filepath.Join(configBaseDir, "/haproxy/conf/transaction"),
-		SpoeDir:            filepath.Join(configBaseDir, "/haproxy/spoe"),
-		SpoeTransactionDir: filepath.Join(configBaseDir, "/haproxy/spoe/transaction"),
-		SocketFile:         filepath.Join(configBaseDir, "/haproxy/conf/haproxy-master.sock"),
-		PidFile:            filepath.Join(configBaseDir, "/haproxy/conf/haproxy.pid"),
-		SpoeConfigFile:     filepath.Join(configBaseDir, "/haproxy/spoe/coraza-spoa.yaml"),
-		SpoeAgentAddress:   "127.0.0.1",
-		SpoeAgentPort:      2342,
-		isResponseCheck:    false,
-		ctx:                ctx,
-		logger:             logger,
-		isDebug:            !config.Global.IsProduction,
-		thread:             appConfig.Haproxy.Thread,
-		isK8s:              config.Global.IsK8s,
+		ConfigBaseDir:        configBaseDir,
+		HAProxyConfigFile:    filepath.Join(configBaseDir, "/haproxy/conf/haproxy.cfg"),
+		HaproxyBin:           haproxyBin,
+		BackupsNumber:        appConfig.Haproxy.BackupsNumber,
+		CertDir:              filepath.Join(configBaseDir, "/haproxy/cert"),
+		VersionDir:           filepath.Join(configBaseDir, "/haproxy/versions"),
+		TransactionDir:       filepath.Join(configBaseDir, "/haproxy/conf/transaction"),
+		SpoeDir:              filepath.Join(configBaseDir, "/haproxy/spoe"),
+		SpoeTransactionDir:   filepath.Join(configBaseDir, "/haproxy/spoe/transaction"),
+		SocketFile:           filepath.Join(configBaseDir, "/haproxy/conf/haproxy-master.sock"),
+		PidFile:              filepath.Join(configBaseDir, "/haproxy/conf/haproxy.pid"),
+		SpoeConfigFile:       filepath.Join(configBaseDir, "/haproxy/spoe/coraza-spoa.yaml"),
+		BlockedIPMapFile:     filepath.Join(configBaseDir, "/haproxy/maps/"+blockedIPMapName+".map"),
+		ACLDir:               filepath.Join(configBaseDir, "/haproxy/acl"),
+		StagingDir:           filepath.Join(configBaseDir, "/haproxy/staging"),
+		SpoeAgentAddress:     "127.0.0.1",
+		SpoeAgentPort:        2342,
+		ACMEChallengeAddress: getManagementAddress(config.Global.Bind),
+		isResponseCheck:      false,
+		ctx:                  ctx,
+		logger:               logger,
+		isDebug:              !config.Global.IsProduction,
+		thread:               appConfig.Haproxy.Thread,
+		isK8s:                config.Global.IsK8s,
 	}, nil
 }
+
+// getManagementAddress 返回 HAProxy 访问管理服务使用的地址，监听所有地址时使用本机回环地址
+func getManagementAddress(bind string) string {
+	host, port, err := net.SplitHostPort(bind)
+	if err != nil {
+		return bind
+	}
+	if ip := net.ParseIP(host); host == "" || (ip != nil && ip.IsUnspecified()) {
+		host = "127.0.0.1"
+	}
+	return net.JoinHostPort(host, port)
+}
@@ -0,0 +1,721 @@
+package haproxy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"maps"
+	"net"
+	"os"
+	"path/filepath"
+	"regexp"
+	"regexp/syntax"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	pkgmodel "github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/haproxytech/client-native/v6/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	microActionVar = "micro_action" // 卸载的微规则的判断结果：pending 表示请求满足卸载条件但还没有规则命中，deny 或 allow 为命中规则的结果
+	microURLVar    = "micro_url"    // 与 WAF 引擎一致的 URL，即路径加上非空的查询字符串
+	microQueryVar  = "micro_query"  // 非空的查询字符串
+	microCondVar   = "micro_c"      // 复合条件的中间结果，后跟序号
+)
+
+// 规则引擎的条件目标和匹配方式
+const (
+	microTargetIP   = "source_ip"
+	microTargetURL  = "url"
+	microTargetPath = "path"
+
+	microMatchEqual         = "equal"
+	microMatchNotEqual      = "not_equal"
+	microMatchFuzzy         = "fuzzy"
+	microMatchInCIDR        = "in_cidr"
+	microMatchNotInCIDR     = "not_in_cidr"
+	microMatchInclude       = "include"
+	microMatchContains      = "contains"
+	microMatchNotContains   = "not_contains"
+	microMatchPrefixKeyword = "prefix_keyword"
+	microMatchRegex         = "regex"
+)
+
+// clientIPHeaders WAF 引擎按顺序从这些请求头中获取客户端 IP，都不存在时才使用连接的源地址
+var clientIPHeaders = []string{
+	"x-forwarded-for",
+	"x-real-ip",
+	"true-client-ip",
+	"cf-connecting-ip",
+	"fastly-client-ip",
+	"x-client-ip",
+	"x-original-forwarded-for",
+	"forwarded",
+	"x-cluster-client-ip",
+}
+
+// MicroRuleOffload 微规则卸载到 HAProxy 的编译结果
+//
+// 卸载的规则在站点前端的 tcp-request content 阶段按规则引擎的匹配顺序判断，第一条命中的规则决定结果：
+// 黑名单规则命中时请求直接返回 403，不再发送给 SPOE；白名单规则命中时停止判断后续卸载的规则，请求照常交给 WAF 引擎检测。
+// WAF 引擎仍然加载全部规则，HAProxy 只提前拒绝 WAF 引擎也会因微规则拦截的请求，因此判断结果与只使用 WAF 引擎时一致。
+// 为保证一致，只有以下请求在 HAProxy 中判断：源地址为 IPv4，且没有 WAF 引擎用于获取客户端 IP 的转发请求头。
+// HAProxy 拒绝的请求不经过 WAF 引擎，不计入规则命中统计，也不记录 WAF 日志；
+// 蜜罐陷阱、登录保护和高频访问检查在 WAF 引擎中先于微规则执行，这些请求同样被拦截，但响应统一为 403。
+type MicroRuleOffload struct {
+	Rules    []MicroRuleOffloadStatus // 每条规则的卸载状态，顺序与输入一致
+	compiled []compiledMicroRule      // 卸载的规则，按匹配顺序排列
+	patterns map[string]string        // ACL 模式文件，按文件名索引
+	usesURL  bool                     // 是否有卸载的规则匹配 URL
+}
+
+// MicroRuleOffloadStatus 单条微规则的卸载状态
+type MicroRuleOffloadStatus struct {
+	RuleID    string `json:"ruleId"`           // 规则ID
+	RuleName  string `json:"ruleName"`         // 规则名称
+	Offloaded bool   `json:"offloaded"`        // 是否在 HAProxy 中判断
+	Reason    string `json:"reason,omitempty"` // 只由 WAF 引擎判断的原因
+}
+
+// OffloadedCount 返回卸载到 HAProxy 的规则数
+func (o *MicroRuleOffload) OffloadedCount() int {
+	if o == nil {
+		return 0
+	}
+	return len(o.compiled)
+}
+
+// compiledMicroRule 卸载到 HAProxy 的规则
+type compiledMicroRule struct {
+	action string   // 命中时设置的结果，deny 或 allow
+	cond   *aclNode // 与规则条件等价的 ACL 条件
+}
+
+// aclNode ACL 条件树，叶子节点为单个 ACL 条件
+type aclNode struct {
+	term     string     // 叶子节点的 ACL 条件，模式文件名用 %s 占位，渲染时替换为完整路径
+	file     string     // 叶子节点引用的模式文件名
+	or       bool       // 复合节点的子条件是否为或关系
+	children []*aclNode // 复合节点的子条件
+}
+
+// compiledCondition 编译单个条件的结果
+type compiledCondition struct {
+	node     *aclNode // 等价的 ACL 条件，为 nil 表示不能卸载
+	reason   string   // 不能卸载的原因
+	mayError bool     // WAF 引擎匹配该条件时是否可能出错，出错时 WAF 引擎放行请求
+}
+
+// microRuleCompiler 编译微规则条件，收集引用的模式文件
+type microRuleCompiler struct {
+	groups   map[string]pkgmodel.IPGroup
+	now      time.Time
+	patterns map[string]string
+	usesURL  bool
+}
+
+// CompileMicroRules 将可以在 HAProxy 中等价判断的微规则编译为 ACL，rules 须按规则引擎的匹配顺序排列，即优先级降序、创建顺序升序
+// groups 为规则引用的IP组，now 用于忽略已过期的IP组条目
+//
+// 规则可以卸载的条件：已启用、对所有站点生效、没有生效计划，且条件树中只有以下可以在 HAProxy 中等价表达的条件：
+// 源 IP 的等于、不等于、通配、CIDR 和全局IP组匹配，路径和 URL 的等于、不等于、包含、不包含、前缀和可移植的正则匹配。
+// 排在未卸载的白名单规则或匹配时可能出错的规则之后的规则都不卸载，这些规则在 WAF 引擎中可能让请求在到达后面的规则之前被放行
+func CompileMicroRules(rules []pkgmodel.MicroRule, groups []pkgmodel.IPGroup, now time.Time) *MicroRuleOffload {
+	c := &microRuleCompiler{
+		groups: make(map[string]pkgmodel.IPGroup, len(groups)),
+		now:    now,
+	}
+	for _, group := range groups {
+		c.groups[group.Name] = group
+	}
+
+	offload := &MicroRuleOffload{
+		Rules:    make([]MicroRuleOffloadStatus, 0, len(rules)),
+		patterns: make(map[string]string),
+	}
+	// 有规则的条件无法解析时 WAF 引擎会保留原来的规则，HAProxy 中的规则可能与 WAF 引擎不一致，此时不卸载任何规则
+	barrier := ""
+	for _, rule := range rules {
+		if !isLoadableCondition(rule.Condition) {
+			barrier = fmt.Sprintf("规则 %s 无法被 WAF 引擎加载", rule.Name)
+			break
+		}
+	}
+	for _, rule := range rules {
+		status := MicroRuleOffloadStatus{RuleID: rule.ID.Hex(), RuleName: rule.Name}
+		if rule.Status == pkgmodel.RuleDisabled {
+			status.Reason = "规则已禁用"
+			offload.Rules = append(offload.Rules, status)
+			continue
+		}
+		// 生效计划无效的规则不被 WAF 引擎加载，与禁用的规则一样不影响其他规则
+		if _, err := rule.Schedule.Compile(); err != nil {
+			status.Reason = "规则的生效计划无效，WAF 引擎不加载该规则"
+			offload.Rules = append(offload.Rules, status)
+			continue
+		}
+
+		// 只保留卸载的规则引用的模式文件
+		c.patterns, c.usesURL = make(map[string]string), false
+		cond := c.compileCondition(rule.Condition)
+		switch {
+		case barrier != "":
+			status.Reason = barrier
+		case rule.Status != pkgmodel.RuleEnabled:
+			status.Reason = fmt.Sprintf("规则状态无效: %s", rule.Status)
+		case rule.Type != pkgmodel.BlacklistRule && rule.Type != pkgmodel.WhitelistRule:
+			status.Reason = fmt.Sprintf("规则类型无效: %s", rule.Type)
+		case !rule.Scope.IsGlobal():
+			status.Reason = "规则只对部分站点生效"
+		case !rule.Schedule.IsEmpty():
+			status.Reason = "规则设置了生效计划"
+		case cond.node == nil:
+			status.Reason = cond.reason
+		default:
+			status.Offloaded = true
+			action := "deny"
+			if rule.Type == pkgmodel.WhitelistRule {
+				action = "allow"
+			}
+			offload.compiled = append(offload.compiled, compiledMicroRule{action: action, cond: cond.node})
+			maps.Copy(offload.patterns, c.patterns)
+			offload.usesURL = offload.usesURL || c.usesURL
+		}
+		offload.Rules = append(offload.Rules, status)
+
+		if status.Offloaded || barrier != "" {
+			continue
+		}
+		// WAF 引擎中未卸载的黑名单规则命中时同样拦截，不影响后面卸载的规则；白名单规则命中或条件出错时会放行请求
+		if rule.Type != pkgmodel.BlacklistRule {
+			barrier = fmt.Sprintf("排在未卸载的规则 %s 之后，该规则命中时 WAF 引擎会放行请求", rule.Name)
+		} else if cond.mayError {
+			barrier = fmt.Sprintf("排在未卸载的规则 %s 之后，该规则匹配时可能出错，出错时 WAF 引擎会放行请求", rule.Name)
+		}
+	}
+
+	return offload
+}
+
+// isLoadableCondition 判断条件是否可以被规则引擎解析，规则引擎只在匹配时检查目标类型和匹配方式
+func isLoadableCondition(raw bson.Raw) bool {
+	var base struct {
+		Type       string     `bson:"type"`
+		Operator   string     `bson:"operator"`
+		Conditions []bson.Raw `bson:"conditions"`
+		Target     string     `bson:"target"`
+		MatchType  string     `bson:"match_type"`
+		MatchValue string     `bson:"match_value"`
+	}
+	if err := bson.Unmarshal(raw, &base); err != nil {
+		return false
+	}
+	switch base.Type {
+	case pkgmodel.ConditionTypeSimple:
+		return true
+	case pkgmodel.ConditionTypeComposite:
+		for _, child := range base.Conditions {
+			if !isLoadableCondition(child) {
+				return false
+			}
+		}
+		return true
+	default:
+		return false
+	}
+}
+
+// compileCondition 编译 BSON 格式的条件，与规则引擎的解析方式一致
+func (c *microRuleCompiler) compileCondition(raw bson.Raw) compiledCondition {
+	var base struct {
+		Type       string     `bson:"type"`
+		Operator   string     `bson:"operator"`
+		Conditions []bson.Raw `bson:"conditions"`
+		Target     string     `bson:"target"`
+		MatchType  string     `bson:"match_type"`
+		MatchValue string     `bson:"match_value"`
+	}
+	if err := bson.Unmarshal(raw, &base); err != nil {
+		return compiledCondition{reason: "条件格式无效", mayError: true}
+	}
+
+	switch base.Type {
+	case pkgmodel.ConditionTypeSimple:
+		return c.compileSimple(base.Target, base.MatchType, base.MatchValue)
+	case pkgmodel.ConditionTypeComposite:
+		if len(base.Conditions) == 0 {
+			return compiledCondition{reason: "复合条件没有子条件", mayError: true}
+		}
+		// 规则引擎将 AND 以外的操作符都按 OR 处理
+		result := compiledCondition{node: &aclNode{or: base.Operator != "AND"}}
+		for _, child := range base.Conditions {
+			compiled := c.compileCondition(child)
+			result.mayError = result.mayError || compiled.mayError
+			if compiled.node == nil {
+				if result.node != nil {
+					result.node, result.reason = nil, compiled.reason
+				}
+				continue
+			}
+			if result.node != nil {
+				result.node.children = append(result.node.children, compiled.node)
+			}
+		}
+		return result
+	default:
+		return compiledCondition{reason: fmt.Sprintf("不支持的条件类型: %s", base.Type), mayError: true}
+	}
+}
+
+// compileSimple 编译简单条件，在客户端 IP 为 IPv4 源地址的前提下与规则引擎的匹配结果一致
+func (c *microRuleCompiler) compileSimple(target, matchType, value string) compiledCondition {
+	switch target {
+	case microTargetIP:
+		return c.compileIP(matchType, value)
+	case microTargetURL:
+		c.usesURL = true
+		return c.compileString(fmt.Sprintf("var(txn.%s)", microURLVar), matchType, value)
+	case microTargetPath:
+		return c.compileString("path", matchType, value)
+	default:
+		return compiledCondition{reason: fmt.Sprintf("不支持的目标类型: %s", target), mayError: true}
+	}
+}
+
+// compileIP 编译源 IP 条件，规则引擎按字符串比较 IP，因此只有规范形式的 IPv4 地址可能相等
+func (c *microRuleCompiler) compileIP(matchType, value string) compiledCondition {
+	switch matchType {
+	case microMatchEqual, microMatchNotEqual:
+		node := aclFalse()
+		if ip := net.ParseIP(value); ip != nil && ip.To4() != nil && ip.String() == value {
+			node = c.patternNode("src -m ip", "ip", []string{value})
+		}
+		return compiledCondition{node: negateIf(node, matchType == microMatchNotEqual)}
+
+	case microMatchFuzzy:
+		parts := strings.Split(value, ".")
+		if len(parts) != 4 {
+			return compiledCondition{reason: "通配IP格式无效", mayError: true}
+		}
+		cidr, ok := fuzzyToCIDR(parts)
+		if !ok {
+			return compiledCondition{reason: "通配IP的通配符不在末尾"}
+		}
+		if cidr == "" {
+			return compiledCondition{node: aclFalse()}
+		}
+		return compiledCondition{node: c.patternNode("src -m ip", "ip", []string{cidr})}
+
+	case microMatchInCIDR, microMatchNotInCIDR:
+		_, ipNet, err := net.ParseCIDR(value)
+		if err != nil {
+			return compiledCondition{reason: "CIDR格式无效", mayError: true}
+		}
+		node := aclFalse()
+		if cidr, ok := ipv4CIDR(ipNet); ok {
+			node = c.patternNode("src -m ip", "ip", []string{cidr})
+		}
+		return compiledCondition{node: negateIf(node, matchType == microMatchNotInCIDR)}
+
+	case pkgmodel.MatchTypeInIPGroup, pkgmodel.MatchTypeNotInIPGroup:
+		group, ok := c.groups[value]
+		if !ok {
+			return compiledCondition{reason: fmt.Sprintf("引用的IP组 %s 不存在", value), mayError: true}
+		}
+		items, result := c.ipGroupPatterns(group)
+		if result.reason != "" {
+			return result
+		}
+		node := aclFalse()
+		if len(items) > 0 {
+			node = c.patternNode("src -m ip", "ip", items)
+		}
+		return compiledCondition{node: negateIf(node, matchType == pkgmodel.MatchTypeNotInIPGroup)}
+
+	default:
+		return compiledCondition{reason: fmt.Sprintf("IP不支持匹配方式: %s", matchType), mayError: true}
+	}
+}
+
+// ipGroupPatterns 返回IP组中可能匹配 IPv4 客户端的条目，已过期的条目被忽略
+// 只对部分站点生效或有未过期的临时条目的IP组不能卸载，它们的匹配结果随站点或时间变化
+func (c *microRuleCompiler) ipGroupPatterns(group pkgmodel.IPGroup) ([]string, compiledCondition) {
+	expiry := group.ExpiryMap()
+	var items []string
+	for _, item := range group.Items {
+		if expiresAt, ok := expiry[item]; ok && !c.now.Before(expiresAt) {
+			continue
+		}
+		if ip := net.ParseIP(item); ip != nil {
+			if ip.To4() != nil && ip.String() == item {
+				items = append(items, item)
+			}
+			continue
+		}
+		_, ipNet, err := net.ParseCIDR(item)
+		if err != nil {
+			return nil, compiledCondition{reason: fmt.Sprintf("IP组 %s 包含无效的条目", group.Name), mayError: true}
+		}
+		if cidr, ok := ipv4CIDR(ipNet); ok {
+			items = append(items, cidr)
+		}
+	}
+
+	if !group.Scope.IsGlobal() {
+		return nil, compiledCondition{reason: fmt.Sprintf("IP组 %s 只对部分站点生效", group.Name)}
+	}
+	for item, expiresAt := range expiry {
+		if slices.Contains(group.Items, item) && c.now.Before(expiresAt) {
+			return nil, compiledCondition{reason: fmt.Sprintf("IP组 %s 包含未过期的临时条目", group.Name)}
+		}
+	}
+	return items, compiledCondition{}
+}
+
+// compileString 编译路径或 URL 条件，fetch 为取值的样本表达式
+func (c *microRuleCompiler) compileString(fetch, matchType, value string) compiledCondition {
+	var method string
+	negate := false
+	switch matchType {
+	case microMatchEqual, microMatchNotEqual:
+		method, negate = "str", matchType == microMatchNotEqual
+	case microMatchInclude, microMatchContains, microMatchNotContains:
+		method, negate = "sub", matchType == microMatchNotContains
+	case microMatchPrefixKeyword:
+		method = "beg"
+	case microMatchRegex:
+		if _, err := regexp.Compile(value); err != nil {
+			return compiledCondition{reason: "正则表达式无效", mayError: true}
+		}
+		if !isPortableRegex(value) {
+			return compiledCondition{reason: "正则表达式使用了 HAProxy 中含义可能不同的语法"}
+		}
+		method = "reg"
+	default:
+		return compiledCondition{reason: fmt.Sprintf("不支持的匹配方式: %s", matchType), mayError: true}
+	}
+
+	if !isPatternLine(value) {
+		return compiledCondition{reason: "匹配值为空或包含首尾空白、换行等无法写入模式文件的字符"}
+	}
+	return compiledCondition{node: negateIf(c.patternNode(fetch+" -m "+method, method, []string{value}), negate)}
+}
+
+// patternNode 返回从模式文件匹配的 ACL 条件，内容相同的模式文件共用
+func (c *microRuleCompiler) patternNode(expr, kind string, patterns []string) *aclNode {
+	patterns = slices.Clone(patterns)
+	slices.Sort(patterns)
+	patterns = slices.Compact(patterns)
+	content := strings.Join(patterns, "\n") + "\n"
+
+	sum := sha256.Sum256([]byte(kind + "\n" + content))
+	file := fmt.Sprintf("micro_%s_%s.lst", kind, hex.EncodeToString(sum[:8]))
+	c.patterns[file] = content
+	return &aclNode{term: "{ " + expr + " -f %s }", file: file}
+}
+
+// fuzzyToCIDR 将通配符都在末尾的通配IP转换为 CIDR，如 192.168.*.* 转换为 192.168.0.0/16
+// 规则引擎按字符串比较每一段，非规范十进制的段不会匹配任何 IPv4 地址，此时返回空字符串
+func fuzzyToCIDR(parts []string) (string, bool) {
+	octets := make([]string, 4)
+	bits := 0
+	for i, part := range parts {
+		if part == "*" {
+			octets[i] = "0"
+			continue
+		}
+		if i > 0 && parts[i-1] == "*" {
+			return "", false
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 || n > 255 || strconv.Itoa(n) != part {
+			return "", true
+		}
+		octets[i] = part
+		bits += 8
+	}
+	return fmt.Sprintf("%s/%d", strings.Join(octets, "."), bits), true
+}
+
+// ipv4CIDR 返回 CIDR 匹配 IPv4 地址的范围，与 net.IPNet.Contains 的判断一致，IPv6 网段不匹配 IPv4 地址时返回 false
+func ipv4CIDR(ipNet *net.IPNet) (string, bool) {
+	ip := ipNet.IP.To4()
+	if ip == nil {
+		return "", false
+	}
+	mask := ipNet.Mask
+	if len(mask) == net.IPv6len {
+		mask = mask[12:]
+	}
+	ones, bits := mask.Size()
+	if bits != 8*net.IPv4len {
+		return "", false
+	}
+	return fmt.Sprintf("%s/%d", ip.Mask(mask), ones), true
+}
+
+// isPatternLine 判断字符串是否可以原样写入模式文件的一行，HAProxy 读取模式文件时会忽略行首空白、注释行和空行
+func isPatternLine(value string) bool {
+	if value == "" || value[0] == '#' || strings.TrimSpace(value) != value {
+		return false
+	}
+	return !strings.ContainsAny(value, "\r\n\x00")
+}
+
+// isPortableRegex 判断正则表达式在 Go 和 HAProxy 使用的 PCRE2 中是否有相同的匹配结果
+// 只允许 ASCII 可打印字符的字面量和不取反的字符类、行首行尾锚点、分组、选择和重复；
+// 任意字符、取反的字符类和非 ASCII 字符在 Go 中按 UTF-8 字符匹配而在 PCRE2 中按字节匹配，忽略大小写在 Go 中还会匹配 Unicode 等价字符。
+// 反斜杠后跟数字在 PCRE2 中可能是反向引用，花括号在新版本 PCRE2 中可能被解析为重复次数，也都不允许
+func isPortableRegex(pattern string) bool {
+	for i := 0; i < len(pattern)-1; i++ {
+		if pattern[i] == '\\' {
+			if pattern[i+1] >= '0' && pattern[i+1] <= '9' {
+				return false
+			}
+			i++
+		}
+	}
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return false
+	}
+	return isPortableRegexp(re)
+}
+
+func isPortableRegexp(re *syntax.Regexp) bool {
+	if re.Flags&syntax.FoldCase != 0 {
+		return false
+	}
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			if r < 0x20 || r > 0x7e || r == '{' || r == '}' {
+				return false
+			}
+		}
+	case syntax.OpCharClass:
+		for _, r := range re.Rune {
+			if r < 0x20 || r > 0x7e {
+				return false
+			}
+		}
+	case syntax.OpEmptyMatch, syntax.OpBeginText, syntax.OpEndText,
+		syntax.OpCapture, syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat,
+		syntax.OpConcat, syntax.OpAlternate:
+	default:
+		return false
+	}
+	for _, sub := range re.Sub {
+		if !isPortableRegexp(sub) {
+			return false
+		}
+	}
+	return true
+}
+
+// aclFalse 返回永远不满足的条件
+func aclFalse() *aclNode {
+	return &aclNode{term: "FALSE"}
+}
+
+// negateIf 在 negate 为 true 时对叶子节点取反
+func negateIf(node *aclNode, negate bool) *aclNode {
+	if !negate {
+		return node
+	}
+	switch node.term {
+	case "FALSE":
+		return &aclNode{term: "TRUE"}
+	case "TRUE":
+		return aclFalse()
+	}
+	return &aclNode{term: "!" + node.term, file: node.file}
+}
+
+// buildTCPRules 生成站点前端判断卸载的微规则的 TCP 请求规则，aclDir 为模式文件所在的目录，没有卸载的规则时返回 nil
+// 满足卸载条件的请求先将结果设置为 pending，之后每条规则只在结果仍为 pending 时判断，第一条命中的规则设置最终结果
+func (o *MicroRuleOffload) buildTCPRules(aclDir string) models.TCPRequestRules {
+	if o.OffloadedCount() == 0 {
+		return nil
+	}
+
+	gate := []string{"{ src -m ip 0.0.0.0/0 }"}
+	for _, header := range clientIPHeaders {
+		gate = append(gate, fmt.Sprintf("{ req.hdr_cnt(%s) eq 0 }", header))
+	}
+	pending := fmt.Sprintf("{ var(txn.%s) -m str pending }", microActionVar)
+	r := &aclRenderer{
+		dir:    aclDir,
+		prefix: pending,
+		rules:  models.TCPRequestRules{setVarRule(microActionVar, "str(pending)", strings.Join(gate, " "))},
+	}
+	if o.usesURL {
+		r.rules = append(r.rules,
+			setVarRule(microURLVar, "path", pending),
+			setVarRule(microQueryVar, "query", pending+" { query -m len 1: }"),
+			setVarRule(microURLVar, fmt.Sprintf("path,concat(?,txn.%s)", microQueryVar), fmt.Sprintf("%s { var(txn.%s) -m found }", pending, microQueryVar)),
+		)
+	}
+
+	for _, rule := range o.compiled {
+		terms := r.fit(r.render(rule.cond))
+		r.rules = append(r.rules, setVarRule(microActionVar, "str("+rule.action+")", r.condition(terms)))
+	}
+	return r.rules
+}
+
+// aclMaxWords 单条规则中条件的最大单词数，HAProxy 配置的每一行最多 64 个单词，
+// 规则本身的 tcp-request content set-var(<var>) <expr> if 占用 5 个
+const aclMaxWords = 56
+
+// aclRenderer 将 ACL 条件树渲染为 HAProxy 条件
+// HAProxy 条件只支持用 || 连接的与条件，嵌套的复合条件和超过单行长度的条件先计算到变量中
+type aclRenderer struct {
+	dir    string
+	prefix string // 每条规则共同的前置条件：请求满足卸载条件且还没有卸载的规则命中
+	rules  models.TCPRequestRules
+	vars   int
+}
+
+// render 返回节点的析取范式，每一项为与关系的 ACL 条件
+func (r *aclRenderer) render(node *aclNode) [][]string {
+	if node.children == nil {
+		term := node.term
+		if node.file != "" {
+			term = fmt.Sprintf(term, filepath.Join(r.dir, node.file))
+		}
+		return [][]string{{term}}
+	}
+
+	if node.or {
+		var terms [][]string
+		for _, child := range node.children {
+			terms = append(terms, r.render(child)...)
+		}
+		return terms
+	}
+
+	var conj []string
+	for _, child := range node.children {
+		terms := r.render(child)
+		if len(terms) == 1 {
+			conj = append(conj, terms[0]...)
+			continue
+		}
+		conj = append(conj, r.materialize(r.fit(terms)))
+	}
+	return [][]string{conj}
+}
+
+// fit 确保析取范式可以写在一行中：过长的与条件分段计算，每段以上一段的结果为前提；项过多时分组计算到同一个变量中
+func (r *aclRenderer) fit(terms [][]string) [][]string {
+	fitted := make([][]string, len(terms))
+	for i, conj := range terms {
+		for r.words([][]string{conj}) > aclMaxWords {
+			n := 1
+			for n < len(conj) && r.words([][]string{conj[:n+1]}) <= aclMaxWords {
+				n++
+			}
+			conj = append([]string{r.materialize([][]string{conj[:n]})}, conj[n:]...)
+		}
+		fitted[i] = conj
+	}
+	if r.words(fitted) <= aclMaxWords {
+		return fitted
+	}
+
+	r.vars++
+	name := fmt.Sprintf("%s%d", microCondVar, r.vars)
+	for start := 0; start < len(fitted); {
+		end := start + 1
+		for end < len(fitted) && r.words(fitted[start:end+1]) <= aclMaxWords {
+			end++
+		}
+		r.rules = append(r.rules, setVarRule(name, "int(1)", r.condition(fitted[start:end])))
+		start = end
+	}
+	return [][]string{{fmt.Sprintf("{ var(txn.%s) -m found }", name)}}
+}
+
+// materialize 将可以写在一行中的析取范式计算到新的变量中，返回判断该变量的 ACL 条件
+func (r *aclRenderer) materialize(terms [][]string) string {
+	if len(terms) == 1 && len(terms[0]) == 1 {
+		return terms[0][0]
+	}
+	r.vars++
+	name := fmt.Sprintf("%s%d", microCondVar, r.vars)
+	r.rules = append(r.rules, setVarRule(name, "int(1)", r.condition(terms)))
+	return fmt.Sprintf("{ var(txn.%s) -m found }", name)
+}
+
+// condition 将析取范式与前置条件组合为 HAProxy 条件
+func (r *aclRenderer) condition(terms [][]string) string {
+	parts := make([]string, len(terms))
+	for i, conj := range terms {
+		parts[i] = r.prefix + " " + strings.Join(conj, " ")
+	}
+	return strings.Join(parts, " || ")
+}
+
+// words 返回析取范式与前置条件组合后的单词数
+func (r *aclRenderer) words(terms [][]string) int {
+	return len(strings.Fields(r.condition(terms)))
+}
+
+// setVarRule 生成满足条件时设置事务变量的 TCP 请求规则
+func setVarRule(name, expr, cond string) *models.TCPRequestRule {
+	return &models.TCPRequestRule{
+		Type:     "content",
+		Action:   "set-var",
+		VarScope: "txn",
+		VarName:  name,
+		Expr:     expr,
+		Cond:     "if",
+		CondTest: cond,
+	}
+}
+
+// writeACLFiles 写入卸载的微规则引用的模式文件，文件名由内容决定，已存在的文件不会改变
+func (s *HAProxyServiceImpl) writeACLFiles(offload *MicroRuleOffload) error {
+	if offload.OffloadedCount() == 0 {
+		return nil
+	}
+	if err := os.MkdirAll(s.ACLDir, 0755); err != nil {
+		return fmt.Errorf("创建ACL模式文件目录失败: %v", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(offload.patterns)) {
+		path := filepath.Join(s.ACLDir, name)
+		if _, err := os.Stat(path); err == nil {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(offload.patterns[name]), 0644); err != nil {
+			return fmt.Errorf("写入ACL模式文件 %s 失败: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// removeStaleACLFiles 删除不再被卸载的微规则引用的模式文件
+func (s *HAProxyServiceImpl) removeStaleACLFiles(offload *MicroRuleOffload) {
+	entries, err := os.ReadDir(s.ACLDir)
+	if err != nil {
+		return
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if offload.OffloadedCount() > 0 {
+			if _, ok := offload.patterns[entry.Name()]; ok {
+				continue
+			}
+		}
+		if err := os.Remove(filepath.Join(s.ACLDir, entry.Name())); err != nil {
+			s.logger.Error().Err(err).Str("file", entry.Name()).Msg("删除ACL模式文件失败")
+		}
+	}
+}
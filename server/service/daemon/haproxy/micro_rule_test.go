@@ -0,0 +1,643 @@
+package haproxy
+
+import (
+	"fmt"
+	"math/rand"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/microrule"
+	pkgmodel "github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/haproxytech/client-native/v6/models"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var microTestNow = time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+
+// marshalMicroCondition 将测试条件编码为规则中保存的 BSON 文档
+func marshalMicroCondition(condition bson.D) bson.Raw {
+	raw, err := bson.Marshal(condition)
+	if err != nil {
+		panic(err)
+	}
+	return raw
+}
+
+func newMicroTestRule(name string, ruleType pkgmodel.RuleType, condition bson.D) pkgmodel.MicroRule {
+	return pkgmodel.MicroRule{
+		ID:        bson.NewObjectID(),
+		Name:      name,
+		Type:      ruleType,
+		Status:    pkgmodel.RuleEnabled,
+		Condition: marshalMicroCondition(condition),
+	}
+}
+
+// TestCompileMicroRules 测试规则的卸载条件，以及未卸载的白名单规则和可能出错的规则之后的规则都不卸载，WAF 引擎跳过的规则不影响后面的规则
+func TestCompileMicroRules(t *testing.T) {
+	scoped := newMicroTestRule("scoped", pkgmodel.BlacklistRule, pkgmodel.NewSimpleCondition(microTargetPath, microMatchPrefixKeyword, "/admin"))
+	scoped.Scope = &pkgmodel.SiteScope{Hosts: []string{"a.example.com"}}
+	disabled := newMicroTestRule("disabled", pkgmodel.WhitelistRule, pkgmodel.NewSimpleCondition(microTargetIP, microMatchEqual, "10.0.0.1"))
+	disabled.Status = pkgmodel.RuleDisabled
+	// 时区无效的规则不被 WAF 引擎加载，不影响后面的规则
+	badTimezone := newMicroTestRule("bad-timezone", pkgmodel.WhitelistRule, pkgmodel.NewSimpleCondition(microTargetIP, microMatchEqual, "10.0.0.1"))
+	badTimezone.Schedule = &pkgmodel.RuleSchedule{Timezone: "Mars/Olympus", Windows: []pkgmodel.WeeklyWindow{{Start: "09:00", End: "18:00"}}}
+
+	rules := []pkgmodel.MicroRule{
+		newMicroTestRule("ip", pkgmodel.BlacklistRule, pkgmodel.NewSimpleCondition(microTargetIP, microMatchInCIDR, "10.0.0.0/8")),
+		badTimezone,
+		scoped,
+		disabled,
+		newMicroTestRule("office", pkgmodel.WhitelistRule, pkgmodel.NewCompositeCondition("AND",
+			pkgmodel.NewSimpleCondition(microTargetIP, pkgmodel.MatchTypeInIPGroup, "office"),
+			pkgmodel.NewSimpleCondition(microTargetURL, microMatchRegex, `^/api/v[0-9]+/`),
+		)),
+		newMicroTestRule("unicode", pkgmodel.BlacklistRule, pkgmodel.NewSimpleCondition(microTargetPath, microMatchRegex, "^/é")),
+		newMicroTestRule("missing", pkgmodel.BlacklistRule, pkgmodel.NewSimpleCondition(microTargetIP, pkgmodel.MatchTypeInIPGroup, "missing")),
+		newMicroTestRule("after", pkgmodel.BlacklistRule, pkgmodel.NewSimpleCondition(microTargetPath, microMatchEqual, "/login")),
+	}
+	groups := []pkgmodel.IPGroup{{Name: "office", Items: []string{"192.168.1.0/24", "2001:db8::1"}}}
+
+	offload := CompileMicroRules(rules, groups, microTestNow)
+	want := []bool{true, false, false, false, true, false, false, false}
+	for i, status := range offload.Rules {
+		if status.Offloaded != want[i] {
+			t.Errorf("rule %s offloaded = %v, want %v (reason %q)", status.RuleName, status.Offloaded, want[i], status.Reason)
+		}
+		if !status.Offloaded && status.Reason == "" {
+			t.Errorf("rule %s has no reason", status.RuleName)
+		}
+	}
+	if !strings.Contains(offload.Rules[7].Reason, "missing") {
+		t.Errorf("rule after should be blocked by rule missing, reason = %q", offload.Rules[7].Reason)
+	}
+	if offload.OffloadedCount() != 2 {
+		t.Errorf("OffloadedCount() = %d, want 2", offload.OffloadedCount())
+	}
+}
+
+// TestApplySitesMicroRuleOffload 测试卸载的微规则写入站点前端和模式文件，WAF 引擎不再检测已拒绝的请求，规则不再卸载时删除模式文件
+func TestApplySitesMicroRuleOffload(t *testing.T) {
+	s := newTestHAProxyService(t, false)
+	offload := CompileMicroRules([]pkgmodel.MicroRule{
+		newMicroTestRule("admin", pkgmodel.BlacklistRule, pkgmodel.NewSimpleCondition(microTargetURL, microMatchPrefixKeyword, "/admin")),
+	}, nil, microTestNow)
+
+	sites := newLimitsSites()
+	if _, err := s.ApplySites(sites, offload); err != nil {
+		t.Fatalf("ApplySites() error = %v", err)
+	}
+	data, err := os.ReadFile(s.HAProxyConfigFile)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+	config := string(data)
+	result, err := s.ApplySites(sites, offload)
+	if err != nil {
+		t.Fatalf("ApplySites() error = %v", err)
+	}
+	if result.Changed() {
+		t.Errorf("second ApplySites() changed = %+v", result)
+	}
+
+	files, _ := filepath.Glob(filepath.Join(s.ACLDir, "micro_beg_*.lst"))
+	if len(files) != 1 {
+		t.Fatalf("acl files = %v, want one prefix pattern file", files)
+	}
+	for _, header := range []string{"frontend fe_8080_http", "frontend fe_8080_https"} {
+		section := getConfigSection(config, header)
+		for _, want := range []string{
+			"tcp-request content set-var(txn.micro_url) path if { var(txn.micro_action) -m str pending }",
+			"tcp-request content set-var(txn.micro_action) str(deny) if { var(txn.micro_action) -m str pending } { var(txn.micro_url) -m beg -f " + files[0] + " }",
+			"http-request deny deny_status 403 if { var(txn.micro_action) -m str deny }",
+		} {
+			if !strings.Contains(section, want) {
+				t.Errorf("%s does not contain %q:\n%s", header, want, section)
+			}
+		}
+		if strings.Index(section, "micro_action") < strings.Index(section, "track-sc1") {
+			t.Errorf("micro rules should follow rate rules:\n%s", section)
+		}
+	}
+	spoe, err := os.ReadFile(s.SpoeConfigFile)
+	if err != nil {
+		t.Fatalf("read spoe config: %v", err)
+	}
+	if !strings.Contains(string(spoe), "event on-frontend-http-request unless { var(txn.micro_action) -m str deny }") {
+		t.Errorf("spoe config should skip denied requests:\n%s", spoe)
+	}
+
+	if _, err := s.ApplySites(sites, nil); err != nil {
+		t.Fatalf("ApplySites() error = %v", err)
+	}
+	if files, _ := filepath.Glob(filepath.Join(s.ACLDir, "*")); len(files) != 0 {
+		t.Errorf("stale acl files = %v", files)
+	}
+}
+
+// TestMicroRuleOffloadDifferential 随机生成规则和请求，比较 HAProxy 执行卸载规则的结果与 WAF 引擎的匹配结果：
+// HAProxy 拒绝的请求 WAF 引擎一定拦截，HAProxy 的结果与按顺序第一条命中的卸载规则一致，不满足卸载条件的请求 HAProxy 不做判断
+// 正则表达式在模拟中使用 Go 的实现，与 PCRE2 的差异由可移植语法的检查保证
+func TestMicroRuleOffloadDifferential(t *testing.T) {
+	rng := rand.New(rand.NewSource(20250601))
+	dir := t.TempDir()
+	var offloaded, denied, allowed, gated int
+
+	for i := range 400 {
+		rules, groups := randomMicroRules(rng)
+		offload := CompileMicroRules(rules, groups, microTestNow)
+		offloaded += offload.OffloadedCount()
+
+		s := &HAProxyServiceImpl{ACLDir: filepath.Join(dir, fmt.Sprint(i))}
+		if err := s.writeACLFiles(offload); err != nil {
+			t.Fatalf("writeACLFiles() error = %v", err)
+		}
+		tcpRules := offload.buildTCPRules(s.ACLDir)
+		checkTCPRuleWords(t, tcpRules)
+
+		// WAF 引擎按数据库返回的顺序读取规则后排序，数据库的顺序与匹配顺序无关
+		engineRules := make([]engineRule, len(rules))
+		for j, rule := range rules {
+			condition, err := microrule.ParseCondition(rule.Condition)
+			if err != nil {
+				t.Fatalf("ParseCondition(%s) error = %v", rule.Name, err)
+			}
+			engineRules[j] = engineRule{rule: rule, condition: condition, index: j}
+		}
+		rng.Shuffle(len(engineRules), func(a, b int) { engineRules[a], engineRules[b] = engineRules[b], engineRules[a] })
+		microrule.SortRules(engineRules, func(r *engineRule) *pkgmodel.MicroRule { return &r.rule })
+
+		groupMap := make(map[string]pkgmodel.IPGroup)
+		for _, group := range groups {
+			groupMap[group.Name] = group
+		}
+		// inScope 为只对部分站点生效的IP组是否作用于请求的站点
+		var inScope bool
+		matcher := microrule.NewMatcher(func(host, ip, name string) (bool, error) {
+			group, ok := groupMap[name]
+			if !ok {
+				return false, fmt.Errorf("IP组不存在: %s", name)
+			}
+			if !group.Scope.IsGlobal() && !inScope {
+				return false, nil
+			}
+			return microrule.GroupContains(&group, group.ExpiryMap(), ip, microTestNow)
+		})
+		sim := &haproxySimulator{t: t, patterns: make(map[string][]string)}
+		for range 40 {
+			req := randomMicroRequest(rng)
+			inScope = rng.Intn(2) == 0
+			engineReq := microrule.Request{IP: req.src.String(), Path: req.path, URL: req.path}
+			if req.query != "" {
+				engineReq.URL += "?" + req.query
+			}
+			// active 为规则是否作用于请求的站点且在生效时间内
+			active := make([]bool, len(rules))
+			for j, rule := range rules {
+				active[j] = (rule.Scope.IsGlobal() && rule.Schedule.IsEmpty()) || rng.Intn(2) == 0
+			}
+
+			action, ok := sim.run(tcpRules, req)
+			forwarded := slices.ContainsFunc(req.headers, func(header string) bool {
+				return slices.Contains(clientIPHeaders, strings.ToLower(header))
+			})
+			if !req.src.Is4() || forwarded {
+				if ok {
+					t.Fatalf("request %+v with forwarded headers or IPv6 source got action %q", req, action)
+				}
+				gated++
+				continue
+			}
+
+			// HAProxy 的结果与按顺序第一条命中的卸载规则一致
+			expected := "pending"
+			if offload.OffloadedCount() == 0 {
+				expected = ""
+			}
+			for j, status := range offload.Rules {
+				if !status.Offloaded {
+					continue
+				}
+				condition, err := microrule.ParseCondition(rules[j].Condition)
+				if err != nil {
+					t.Fatalf("ParseCondition(%s) error = %v", rules[j].Name, err)
+				}
+				match, err := condition.Match(matcher, engineReq)
+				if err != nil {
+					t.Fatalf("offloaded rule %s failed to match: %v", rules[j].Name, err)
+				}
+				if match {
+					expected = map[pkgmodel.RuleType]string{pkgmodel.BlacklistRule: "deny", pkgmodel.WhitelistRule: "allow"}[rules[j].Type]
+					break
+				}
+			}
+			if action != expected {
+				t.Fatalf("request %+v: haproxy action = %q, want %q\nrules:\n%s\ntcp rules:\n%s",
+					req, action, expected, describeMicroRules(rules, offload), describeTCPRules(tcpRules))
+			}
+
+			// HAProxy 拒绝的请求 WAF 引擎一定拦截
+			switch action {
+			case "deny":
+				denied++
+				block, _, err := microrule.MatchRules(engineRules, func(r *engineRule) (*pkgmodel.MicroRule, microrule.Condition, bool) {
+					return &r.rule, r.condition, active[r.index]
+				}, matcher, engineReq)
+				if err != nil || !block {
+					t.Fatalf("request %+v denied by haproxy but engine block = %v, err = %v\nrules:\n%s",
+						req, block, err, describeMicroRules(rules, offload))
+				}
+			case "allow":
+				allowed++
+			}
+		}
+	}
+
+	if offloaded == 0 || denied == 0 || allowed == 0 || gated == 0 {
+		t.Errorf("differential test is not exercising offload: offloaded=%d denied=%d allowed=%d gated=%d", offloaded, denied, allowed, gated)
+	}
+}
+
+// checkTCPRuleWords 检查每条规则写入配置文件后不超过 HAProxy 单行 64 个单词的限制
+func checkTCPRuleWords(t *testing.T, rules models.TCPRequestRules) {
+	t.Helper()
+	for _, rule := range rules {
+		line := fmt.Sprintf("tcp-request content set-var(txn.%s) %s %s %s", rule.VarName, rule.Expr, rule.Cond, rule.CondTest)
+		if words := len(strings.Fields(line)); words > 64 {
+			t.Fatalf("rule has %d words: %s", words, line)
+		}
+	}
+}
+
+var (
+	microTestIPs = []string{
+		"10.0.0.1", "10.0.0.2", "10.0.1.7", "10.1.2.3", "192.168.1.10", "192.168.1.200", "172.16.5.4", "8.8.8.8",
+	}
+	microTestIPValues = []string{
+		"10.0.0.1", "10.1.2.3", "192.168.1.10", "8.8.8.8", "010.0.0.1", "::ffff:10.0.0.1", "2001:db8::1", "10.0.0.1 ",
+	}
+	microTestFuzzyValues = []string{
+		"10.0.*.*", "10.*.*.*", "192.168.1.*", "*.*.*.*", "10.0.0.1", "10.*.0.1", "10.0.0", "010.0.*.*", "10.0.0.1*",
+	}
+	microTestCIDRValues = []string{
+		"10.0.0.0/8", "10.0.0.1/24", "192.168.1.0/25", "0.0.0.0/0", "::ffff:10.0.0.0/104", "2001:db8::/32", "::/0", "10.0.0.0/33", "bad",
+	}
+	microTestGroupNames = []string{"office", "scoped", "temp", "expired", "bad", "empty", "missing"}
+	microTestPaths      = []string{
+		"/", "/admin", "/admin/users", "/Admin", "/api/v1/login", "/api/v22/items", "/static/app.js", "/login", "/a%20b",
+	}
+	microTestQueries = []string{"", "", "id=1", "q=admin", "next=/admin", "a=1&b=2"}
+	microTestStrings = []string{
+		"/admin", "/api", "admin", "login", ".js", "/", "/admin/users", "id=1", "/admin?q=admin", "", " /admin", "#admin", "/a%20b",
+	}
+	microTestRegexes = []string{
+		"^/admin", `\.js$`, `^/api/v[0-9]+/`, "admin|login", "^/(admin|login)$", "(", "^/é", "(?i)admin", ".*", `\d+`,
+		"[a-z]+$", "a{2}", `(a)\1`, "^/api/v1/.+", "^$", `[\x00-\x1f]`, "[^/]+$", `\?`, "q=ad(m)?in$",
+	}
+	microTestHeaders = []string{"x-forwarded-for", "X-Real-IP", "forwarded", "user-agent"}
+)
+
+func pick[T any](rng *rand.Rand, items []T) T {
+	return items[rng.Intn(len(items))]
+}
+
+// randomMicroRules 随机生成按规则引擎匹配顺序排列的规则和规则引用的IP组
+func randomMicroRules(rng *rand.Rand) ([]pkgmodel.MicroRule, []pkgmodel.IPGroup) {
+	past, future := microTestNow.Add(-time.Hour), microTestNow.Add(time.Hour)
+	groupItems := []string{"10.0.0.1", "10.0.0.0/24", "192.168.1.0/25", "::ffff:10.1.2.3", "2001:db8::/32", "010.0.0.2", "8.8.8.8", "172.16.0.0/12"}
+	office := pkgmodel.IPGroup{Name: "office"}
+	for _, item := range groupItems {
+		if rng.Intn(2) == 0 {
+			office.Items = append(office.Items, item)
+			if rng.Intn(4) == 0 {
+				office.Expirations = append(office.Expirations, pkgmodel.IPItemExpiration{Item: item, ExpiresAt: past})
+			}
+		}
+	}
+	groups := []pkgmodel.IPGroup{
+		office,
+		{Name: "scoped", Items: []string{"10.0.0.0/16"}, Scope: &pkgmodel.SiteScope{Hosts: []string{"a.example.com"}}},
+		{Name: "temp", Items: []string{"10.0.0.2", "8.8.8.8"}, Expirations: []pkgmodel.IPItemExpiration{{Item: "10.0.0.2", ExpiresAt: future}}},
+		{Name: "expired", Items: []string{"10.0.0.1"}, Expirations: []pkgmodel.IPItemExpiration{{Item: "10.0.0.1", ExpiresAt: past}}},
+		{Name: "bad", Items: []string{"8.8.8.8", "not-an-ip"}},
+		{Name: "empty"},
+	}
+
+	// 一半的规则集只使用可以卸载的条件，使生成的规则覆盖较长和嵌套的条件
+	leaf := randomMicroLeaf
+	if rng.Intn(2) == 0 {
+		c := &microRuleCompiler{groups: make(map[string]pkgmodel.IPGroup), now: microTestNow, patterns: make(map[string]string)}
+		for _, group := range groups {
+			c.groups[group.Name] = group
+		}
+		leaf = func(rng *rand.Rand) bson.D {
+			for {
+				if cond := randomMicroLeaf(rng); c.compileCondition(marshalMicroCondition(cond)).node != nil {
+					return cond
+				}
+			}
+		}
+	}
+
+	rules := make([]pkgmodel.MicroRule, 1+rng.Intn(10))
+	for i := range rules {
+		ruleType := pkgmodel.BlacklistRule
+		if rng.Intn(3) == 0 {
+			ruleType = pkgmodel.WhitelistRule
+		}
+		condition := randomMicroCondition(rng, 0, leaf)
+		if rng.Intn(8) == 0 {
+			// 超过单行长度的与条件，子条件多数情况下满足
+			children := make([]bson.D, 6+rng.Intn(10))
+			for j := range children {
+				target := pick(rng, []string{microTargetPath, microTargetURL})
+				matchType := pick(rng, []string{microMatchNotEqual, microMatchNotContains})
+				children[j] = pkgmodel.NewSimpleCondition(target, matchType, pick(rng, microTestStrings[:9]))
+			}
+			condition = pkgmodel.NewCompositeCondition("AND", children...)
+		}
+		rules[i] = newMicroTestRule(fmt.Sprintf("rule%d", i), ruleType, condition)
+		rules[i].Priority = rng.Intn(3)
+		switch rng.Intn(20) {
+		case 0:
+			rules[i].Status = pkgmodel.RuleDisabled
+		case 1:
+			rules[i].Scope = &pkgmodel.SiteScope{Hosts: []string{"a.example.com"}}
+		case 2:
+			rules[i].Schedule = &pkgmodel.RuleSchedule{ActiveUntil: &future}
+		case 3:
+			rules[i].Status = "paused"
+		}
+	}
+	// 规则的创建顺序与生成顺序无关，优先级相同的规则按ID排序
+	ids := make([]bson.ObjectID, len(rules))
+	for i := range ids {
+		ids[i] = bson.NewObjectID()
+	}
+	rng.Shuffle(len(ids), func(i, j int) { ids[i], ids[j] = ids[j], ids[i] })
+	for i := range rules {
+		rules[i].ID = ids[i]
+	}
+	slices.SortFunc(rules, func(a, b pkgmodel.MicroRule) int {
+		return pkgmodel.CompareMicroRuleOrder(&a, &b)
+	})
+	return rules, groups
+}
+
+func randomMicroCondition(rng *rand.Rand, depth int, leaf func(*rand.Rand) bson.D) bson.D {
+	if depth < 3 && rng.Intn(depth+2) == 0 {
+		children := make([]bson.D, 1+rng.Intn(8-2*depth))
+		if rng.Intn(20) == 0 {
+			children = nil
+		}
+		for i := range children {
+			children[i] = randomMicroCondition(rng, depth+1, leaf)
+		}
+		return pkgmodel.NewCompositeCondition(pick(rng, []string{"AND", "AND", "OR", ""}), children...)
+	}
+	return leaf(rng)
+}
+
+func randomMicroLeaf(rng *rand.Rand) bson.D {
+	switch rng.Intn(3) {
+	case 0:
+		switch matchType := pick(rng, []string{
+			microMatchEqual, microMatchNotEqual, microMatchFuzzy, microMatchInCIDR, microMatchNotInCIDR,
+			pkgmodel.MatchTypeInIPGroup, pkgmodel.MatchTypeNotInIPGroup, microMatchInclude,
+		}); matchType {
+		case microMatchEqual, microMatchNotEqual, microMatchInclude:
+			return pkgmodel.NewSimpleCondition(microTargetIP, matchType, pick(rng, microTestIPValues))
+		case microMatchFuzzy:
+			return pkgmodel.NewSimpleCondition(microTargetIP, matchType, pick(rng, microTestFuzzyValues))
+		case microMatchInCIDR, microMatchNotInCIDR:
+			return pkgmodel.NewSimpleCondition(microTargetIP, matchType, pick(rng, microTestCIDRValues))
+		default:
+			return pkgmodel.NewSimpleCondition(microTargetIP, matchType, pick(rng, microTestGroupNames))
+		}
+	default:
+		target := pick(rng, []string{microTargetPath, microTargetURL, "header"})
+		matchType := pick(rng, []string{
+			microMatchEqual, microMatchNotEqual, microMatchInclude, microMatchContains, microMatchNotContains,
+			microMatchPrefixKeyword, microMatchRegex, microMatchRegex, microMatchFuzzy,
+		})
+		if matchType == microMatchRegex {
+			return pkgmodel.NewSimpleCondition(target, matchType, pick(rng, microTestRegexes))
+		}
+		return pkgmodel.NewSimpleCondition(target, matchType, pick(rng, microTestStrings))
+	}
+}
+
+// microTestRequest 模拟请求，headers 为请求中出现的请求头名称
+type microTestRequest struct {
+	src     netip.Addr
+	path    string
+	query   string
+	headers []string
+}
+
+func randomMicroRequest(rng *rand.Rand) microTestRequest {
+	req := microTestRequest{
+		src:   netip.MustParseAddr(pick(rng, microTestIPs)),
+		path:  pick(rng, microTestPaths),
+		query: pick(rng, microTestQueries),
+	}
+	switch rng.Intn(10) {
+	case 0:
+		req.src = netip.MustParseAddr("2001:db8::1")
+	case 1:
+		req.headers = []string{pick(rng, microTestHeaders)}
+	}
+	return req
+}
+
+// engineRule WAF 引擎加载的规则，index 为规则在匹配顺序中的位置
+type engineRule struct {
+	rule      pkgmodel.MicroRule
+	condition microrule.Condition
+	index     int
+}
+
+// haproxySimulator 按 HAProxy 的语义执行生成的 TCP 请求规则，只支持卸载的微规则使用的样本获取和匹配方式
+type haproxySimulator struct {
+	t        *testing.T
+	patterns map[string][]string
+}
+
+// run 执行规则，返回卸载的微规则的判断结果，请求不满足卸载条件时返回 false
+func (h *haproxySimulator) run(rules models.TCPRequestRules, req microTestRequest) (string, bool) {
+	vars := make(map[string]string)
+	for _, rule := range rules {
+		if rule.Type != "content" || rule.Action != "set-var" || rule.VarScope != "txn" || rule.Cond != "if" {
+			h.t.Fatalf("unexpected rule %+v", rule)
+		}
+		if h.condition(rule.CondTest, req, vars) {
+			vars[rule.VarName] = h.expr(rule.Expr, req, vars)
+		}
+	}
+	action, ok := vars[microActionVar]
+	return action, ok
+}
+
+func (h *haproxySimulator) expr(expr string, req microTestRequest, vars map[string]string) string {
+	switch {
+	case strings.HasPrefix(expr, "str(") && strings.HasSuffix(expr, ")"):
+		return expr[len("str(") : len(expr)-1]
+	case expr == "int(1)":
+		return "1"
+	case expr == "path":
+		return req.path
+	case expr == "query":
+		return req.query
+	case expr == fmt.Sprintf("path,concat(?,txn.%s)", microQueryVar):
+		return req.path + "?" + vars[microQueryVar]
+	}
+	h.t.Fatalf("unexpected expression %q", expr)
+	return ""
+}
+
+// condition 计算用 || 连接的与条件，匿名 ACL 可以用 ! 取反
+func (h *haproxySimulator) condition(cond string, req microTestRequest, vars map[string]string) bool {
+	words := strings.Fields(cond)
+	result, conj := false, true
+	for i := 0; i < len(words); i++ {
+		word := words[i]
+		if word == "||" {
+			result, conj = result || conj, true
+			continue
+		}
+		negate := strings.HasPrefix(word, "!")
+		word = strings.TrimPrefix(word, "!")
+
+		var value bool
+		switch word {
+		case "TRUE":
+			value = true
+		case "FALSE":
+			value = false
+		case "{":
+			end := slices.Index(words[i:], "}")
+			if end == -1 {
+				h.t.Fatalf("unterminated acl in %q", cond)
+			}
+			value = h.term(words[i+1:i+end], req, vars)
+			i += end
+		default:
+			h.t.Fatalf("unexpected word %q in %q", word, cond)
+		}
+		conj = conj && value != negate
+	}
+	return result || conj
+}
+
+func (h *haproxySimulator) term(words []string, req microTestRequest, vars map[string]string) bool {
+	fetch, args := words[0], words[1:]
+	if name, ok := strings.CutPrefix(fetch, "req.hdr_cnt("); ok {
+		name = strings.TrimSuffix(name, ")")
+		if !slices.Equal(args, []string{"eq", "0"}) {
+			h.t.Fatalf("unexpected header acl %v", words)
+		}
+		return !slices.ContainsFunc(req.headers, func(header string) bool { return strings.EqualFold(header, name) })
+	}
+
+	sample, found := "", true
+	switch {
+	case fetch == "src":
+	case fetch == "path":
+		sample = req.path
+	case fetch == "query":
+		sample = req.query
+	case strings.HasPrefix(fetch, "var(txn.") && strings.HasSuffix(fetch, ")"):
+		sample, found = vars[fetch[len("var(txn."):len(fetch)-1]]
+	default:
+		h.t.Fatalf("unexpected fetch %v", words)
+	}
+	if !found {
+		return false
+	}
+	if len(args) < 2 || args[0] != "-m" {
+		h.t.Fatalf("unexpected acl %v", words)
+	}
+	method, patterns := args[1], args[2:]
+	if len(patterns) == 2 && patterns[0] == "-f" {
+		patterns = h.readPatterns(patterns[1])
+	}
+
+	switch method {
+	case "found":
+		return true
+	case "len":
+		if !slices.Equal(patterns, []string{"1:"}) {
+			h.t.Fatalf("unexpected len acl %v", words)
+		}
+		return len(sample) >= 1
+	case "ip":
+		for _, pattern := range patterns {
+			prefix, err := netip.ParsePrefix(pattern)
+			if err != nil {
+				prefix = netip.PrefixFrom(netip.MustParseAddr(pattern), 32)
+			}
+			if req.src.Is4() && prefix.Addr().Is4() && prefix.Contains(req.src) {
+				return true
+			}
+		}
+		return false
+	}
+	return slices.ContainsFunc(patterns, func(pattern string) bool {
+		switch method {
+		case "str":
+			return sample == pattern
+		case "sub":
+			return strings.Contains(sample, pattern)
+		case "beg":
+			return strings.HasPrefix(sample, pattern)
+		case "reg":
+			return regexp.MustCompile(pattern).MatchString(sample)
+		}
+		h.t.Fatalf("unexpected match method %v", words)
+		return false
+	})
+}
+
+// readPatterns 按 HAProxy 的方式读取模式文件，忽略行首空白、空行和注释行
+func (h *haproxySimulator) readPatterns(file string) []string {
+	if patterns, ok := h.patterns[file]; ok {
+		return patterns
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		h.t.Fatalf("read pattern file: %v", err)
+	}
+	var patterns []string
+	for _, line := range strings.Split(string(data), "\n") {
+		line = strings.TrimLeft(line, " \t")
+		if line == "" || line[0] == '#' {
+			continue
+		}
+		patterns = append(patterns, line)
+	}
+	h.patterns[file] = patterns
+	return patterns
+}
+
+func describeMicroRules(rules []pkgmodel.MicroRule, offload *MicroRuleOffload) string {
+	var b strings.Builder
+	for i, rule := range rules {
+		fmt.Fprintf(&b, "%s %s %s offloaded=%v %q %s\n", rule.Name, rule.Type, rule.Status,
+			offload.Rules[i].Offloaded, offload.Rules[i].Reason, rule.Condition.String())
+	}
+	return b.String()
+}
+
+func describeTCPRules(rules models.TCPRequestRules) string {
+	var b strings.Builder
+	for _, rule := range rules {
+		fmt.Fprintf(&b, "set-var(txn.%s) %s if %s\n", rule.VarName, rule.Expr, rule.CondTest)
+	}
+	return b.String()
+}
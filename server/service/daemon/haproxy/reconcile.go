@@ -0,0 +1,616 @@
+package haproxy
+
+import (
+	"bytes"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/haproxytech/client-native/v6/models"
+)
+
+var (
+	// 端口组合前端，每个监听端口一个
+	portFrontendPattern = regexp.MustCompile(`^fe_(\d+)_combined$`)
+	// 端口组合前端转发 HTTP/TLS 流量的 TCP 后端，随端口前端一起创建和删除
+	portTCPBackendPattern = regexp.MustCompile(`^be_\d+_https?$`)
+	// 站点自定义错误页的 http-errors 段
+	siteHTTPErrorsPattern = regexp.MustCompile(`^errors_.+_\d+$`)
+)
+
+// certManifestFile 证书目录中记录由站点配置写入的文件的清单，清理时只删除清单中的文件
+const certManifestFile = ".managed"
+
+// SiteApplyResult 增量应用站点配置时实际发生的变更
+type SiteApplyResult struct {
+	CreatedPorts      []int    `json:"createdPorts,omitempty"`      // 新增的监听端口
+	DeletedPorts      []int    `json:"deletedPorts,omitempty"`      // 删除的监听端口
+	CreatedBackends   []string `json:"createdBackends,omitempty"`   // 新增的后端
+	UpdatedBackends   []string `json:"updatedBackends,omitempty"`   // 修改的后端
+	DeletedBackends   []string `json:"deletedBackends,omitempty"`   // 删除的后端
+	UpdatedFrontends  []string `json:"updatedFrontends,omitempty"`  // ACL、切换规则、请求规则、连接保护或证书绑定有变化的前端
+	UpdatedCrtLoads   []string `json:"updatedCrtLoads,omitempty"`   // 新增、修改或删除的证书加载
+	UpdatedCerts      []string `json:"updatedCerts,omitempty"`      // 新增、修改或删除的证书文件
+	UpdatedHTTPErrors []string `json:"updatedHttpErrors,omitempty"` // 新增、修改或删除的错误页 http-errors 段
+}
+
+// Changed 是否有任何变更，没有变更时无需重新加载 HAProxy
+func (r *SiteApplyResult) Changed() bool {
+	return len(r.CreatedPorts) > 0 || len(r.DeletedPorts) > 0 ||
+		len(r.CreatedBackends) > 0 || len(r.UpdatedBackends) > 0 || len(r.DeletedBackends) > 0 ||
+		len(r.UpdatedFrontends) > 0 || len(r.UpdatedCrtLoads) > 0 || len(r.UpdatedCerts) > 0 ||
+		len(r.UpdatedHTTPErrors) > 0
+}
+
+// ApplySites 将站点列表生成的期望配置与当前配置比较，在一个事务中只修改有变化的端口前端、
+// 后端、ACL、后端切换规则、证书加载和错误页，未变化的部分保持不变
+// offload 为卸载到 HAProxy 的微规则，为 nil 时不卸载任何规则
+// 不重新加载 HAProxy，调用方根据返回结果决定是否需要重新加载；站点配置无效时返回 *SiteConfigError
+func (s *HAProxyServiceImpl) ApplySites(sites []model.Site, offload *MicroRuleOffload) (*SiteApplyResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	desired, err := buildDesiredConfig(sites, s.isK8s, s.ACMEChallengeAddress, s.acmeHostMapFile(), s.CertDir)
+	if err != nil {
+		return nil, err
+	}
+	// 卸载的微规则对所有站点生效，排在按站点统计请求速率的规则之后
+	microRules := offload.buildTCPRules(s.ACLDir)
+	for _, conf := range desired.ports {
+		conf.httpTCP = append(conf.httpTCP, microRules...)
+		conf.httpsTCP = append(conf.httpsTCP, microRules...)
+	}
+
+	// 站点前端引用封禁IP和ACME验证主机名映射文件，提交时的配置检查需要加载这些文件
+	if err := s.ensureBlockedIPMap(); err != nil {
+		return nil, err
+	}
+	if err := s.ensureACMEHostMap(); err != nil {
+		return nil, err
+	}
+
+	// 确保配置客户端初始化
+	if err := s.ensureConfClient(); err != nil {
+		return nil, err
+	}
+	version, err := s.confClient.GetVersion("")
+	if err != nil {
+		return nil, fmt.Errorf("获取版本失败: %v", err)
+	}
+	transaction, err := s.confClient.StartTransaction(version)
+	if err != nil {
+		return nil, fmt.Errorf("启动事务失败: %v", err)
+	}
+	committed := false
+	defer func() {
+		if !committed {
+			s.confClient.DeleteTransaction(transaction.ID)
+		}
+	}()
+
+	result := &SiteApplyResult{}
+	if err := s.applyPorts(desired, transaction.ID, result); err != nil {
+		return nil, err
+	}
+	if err := s.applyHTTPErrors(desired, transaction.ID, result); err != nil {
+		return nil, err
+	}
+	if err := s.applyBackends(desired, transaction.ID, result); err != nil {
+		return nil, err
+	}
+	if err := s.applyFrontendRules(desired, transaction.ID, result); err != nil {
+		return nil, err
+	}
+	if err := s.applyCrtLoads(desired, transaction.ID, result); err != nil {
+		return nil, err
+	}
+
+	// 证书文件在提交前写入，提交时的配置检查需要加载证书；提交失败时恢复原来的文件
+	restoreCerts, err := s.writeCertFiles(desired, result)
+	if err != nil {
+		restoreCerts()
+		return nil, err
+	}
+	// 模式文件名由内容决定，提前写入不影响当前配置引用的文件
+	if err := s.writeACLFiles(offload); err != nil {
+		restoreCerts()
+		return nil, err
+	}
+	if !result.Changed() {
+		s.afterInstall(func() {
+			s.removeStaleCertFiles(desired)
+			s.removeStaleACLFiles(offload)
+		})
+		return result, nil
+	}
+
+	if _, err := s.confClient.CommitTransaction(transaction.ID); err != nil {
+		restoreCerts()
+		return nil, fmt.Errorf("提交事务失败: %v", err)
+	}
+	committed = true
+	s.confClient.DeleteTransaction(transaction.ID)
+
+	// 暂存构建时新配置可能不会替换当前配置，此时恢复证书文件，保留当前配置引用的文件
+	s.onAbort(restoreCerts)
+	s.afterInstall(func() {
+		s.removeStaleCertFiles(desired)
+		s.removeStaleACLFiles(offload)
+	})
+	return result, nil
+}
+
+// applyPorts 创建新增端口的前端，删除不再使用的端口，并按端口上的站点更新前端的 HTTP 请求和响应规则及连接保护
+func (s *HAProxyServiceImpl) applyPorts(desired *desiredConfig, transactionID string, result *SiteApplyResult) error {
+	_, frontends, err := s.confClient.GetFrontends(transactionID)
+	if err != nil {
+		return fmt.Errorf("获取前端失败: %v", err)
+	}
+	currentPorts := make(map[int]bool)
+	for _, frontend := range frontends {
+		if match := portFrontendPattern.FindStringSubmatch(frontend.Name); match != nil {
+			port, _ := strconv.Atoi(match[1])
+			currentPorts[port] = true
+		}
+	}
+
+	for _, port := range slices.Sorted(maps.Keys(desired.ports)) {
+		conf := desired.ports[port]
+		if !currentPorts[port] {
+			if err := s.createPortFrontends(port, conf, transactionID); err != nil {
+				return fmt.Errorf("创建端口 %d 前端失败: %v", port, err)
+			}
+			result.CreatedPorts = append(result.CreatedPorts, port)
+			continue
+		}
+
+		updated, err := s.applyPortLimits(port, conf, transactionID)
+		if err != nil {
+			return err
+		}
+		result.UpdatedFrontends = append(result.UpdatedFrontends, updated...)
+
+		frontends := []struct {
+			name          string
+			requestRules  models.HTTPRequestRules
+			responseRules models.HTTPResponseRules
+		}{
+			{fmt.Sprintf("fe_%d_http", port), buildFeHTTPRequestRules(), buildFeHTTPResponseRules()},
+			{fmt.Sprintf("fe_%d_https", port), conf.httpsRequestRules(), conf.httpsResponseRules()},
+		}
+		for _, frontend := range frontends {
+			changed := false
+
+			_, requestRules, err := s.confClient.GetHTTPRequestRules("frontend", frontend.name, transactionID)
+			if err != nil {
+				return fmt.Errorf("获取前端 %s HTTP请求规则失败: %v", frontend.name, err)
+			}
+			if !frontend.requestRules.Equal(requestRules) {
+				err = s.confClient.ReplaceHTTPRequestRules("frontend", frontend.name, frontend.requestRules, transactionID, 0)
+				if err != nil {
+					return fmt.Errorf("修改前端 %s HTTP请求规则失败: %v", frontend.name, err)
+				}
+				changed = true
+			}
+
+			_, responseRules, err := s.confClient.GetHTTPResponseRules("frontend", frontend.name, transactionID)
+			if err != nil {
+				return fmt.Errorf("获取前端 %s HTTP响应规则失败: %v", frontend.name, err)
+			}
+			if !frontend.responseRules.Equal(normalizeHTTPResponseRules(responseRules)) {
+				err = s.confClient.ReplaceHTTPResponseRules("frontend", frontend.name, frontend.responseRules, transactionID, 0)
+				if err != nil {
+					return fmt.Errorf("修改前端 %s HTTP响应规则失败: %v", frontend.name, err)
+				}
+				changed = true
+			}
+
+			if changed && !slices.Contains(result.UpdatedFrontends, frontend.name) {
+				result.UpdatedFrontends = append(result.UpdatedFrontends, frontend.name)
+			}
+		}
+	}
+
+	for _, port := range slices.Sorted(maps.Keys(currentPorts)) {
+		if _, ok := desired.ports[port]; ok {
+			continue
+		}
+		if err := s.deletePortFrontends(port, transactionID); err != nil {
+			return fmt.Errorf("删除端口 %d 前端失败: %v", port, err)
+		}
+		result.DeletedPorts = append(result.DeletedPorts, port)
+	}
+	return nil
+}
+
+// applyPortLimits 按端口的连接保护更新组合前端的最大连接数和 TCP 请求规则，以及站点前端的请求头超时和 TCP 请求规则
+// 返回有变化的前端
+func (s *HAProxyServiceImpl) applyPortLimits(port int, conf *desiredPort, transactionID string) ([]string, error) {
+	frontends := []struct {
+		name               string
+		maxConn            int64
+		httpRequestTimeout int64
+		tcpRules           models.TCPRequestRules
+	}{
+		{fmt.Sprintf("fe_%d_combined", port), conf.limits.maxConn, 0, buildCombinedTCPRequestRules(port, conf.limits)},
+		{fmt.Sprintf("fe_%d_http", port), 0, conf.limits.httpRequestTimeout, s.siteFrontendTCPRules(conf.httpTCP)},
+		{fmt.Sprintf("fe_%d_https", port), 0, conf.limits.httpRequestTimeout, s.siteFrontendTCPRules(conf.httpsTCP)},
+	}
+
+	var updated []string
+	for _, frontend := range frontends {
+		changed := false
+
+		_, current, err := s.confClient.GetFrontend(frontend.name, transactionID)
+		if err != nil {
+			return nil, fmt.Errorf("获取前端 %s 失败: %v", frontend.name, err)
+		}
+		if GetSafeInt64(current.Maxconn) != frontend.maxConn || GetSafeInt64(current.HTTPRequestTimeout) != frontend.httpRequestTimeout {
+			current.Maxconn = limitP(frontend.maxConn)
+			current.HTTPRequestTimeout = limitP(frontend.httpRequestTimeout)
+			if err := s.confClient.EditFrontend(frontend.name, current, transactionID, 0); err != nil {
+				return nil, fmt.Errorf("修改前端 %s 失败: %v", frontend.name, err)
+			}
+			changed = true
+		}
+
+		_, tcpRules, err := s.confClient.GetTCPRequestRules("frontend", frontend.name, transactionID)
+		if err != nil {
+			return nil, fmt.Errorf("获取前端 %s TCP请求规则失败: %v", frontend.name, err)
+		}
+		if !frontend.tcpRules.Equal(tcpRules) {
+			if err := s.confClient.ReplaceTCPRequestRules("frontend", frontend.name, frontend.tcpRules, transactionID, 0); err != nil {
+				return nil, fmt.Errorf("修改前端 %s TCP请求规则失败: %v", frontend.name, err)
+			}
+			changed = true
+		}
+
+		if changed {
+			updated = append(updated, frontend.name)
+		}
+	}
+	return updated, nil
+}
+
+// deletePortFrontends 删除端口的组合前端、站点前端和转发用的 TCP 后端，端口默认后端随站点后端删除
+func (s *HAProxyServiceImpl) deletePortFrontends(port int, transactionID string) error {
+	for _, name := range []string{
+		fmt.Sprintf("fe_%d_combined", port),
+		fmt.Sprintf("fe_%d_http", port),
+		fmt.Sprintf("fe_%d_https", port),
+	} {
+		if err := s.confClient.DeleteFrontend(name, transactionID, 0); err != nil {
+			return fmt.Errorf("删除前端 %s 失败: %v", name, err)
+		}
+	}
+	for _, name := range []string{
+		fmt.Sprintf("be_%d_http", port),
+		fmt.Sprintf("be_%d_https", port),
+	} {
+		if err := s.confClient.DeleteBackend(name, transactionID, 0); err != nil {
+			return fmt.Errorf("删除后端 %s 失败: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// applyBackends 创建、修改和删除站点后端及端口默认后端，后端的服务器、健康检查和请求规则整体比较
+func (s *HAProxyServiceImpl) applyBackends(desired *desiredConfig, transactionID string, result *SiteApplyResult) error {
+	_, backends, err := s.confClient.GetStructuredBackends(transactionID)
+	if err != nil {
+		return fmt.Errorf("获取后端失败: %v", err)
+	}
+
+	current := make(map[string]*models.Backend)
+	for _, backend := range backends {
+		if isSiteManagedBackend(backend.Name) {
+			current[backend.Name] = backend
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(desired.backends)) {
+		want := desired.backends[name]
+		have, ok := current[name]
+		if !ok {
+			if err := s.writeBackend(want, false, transactionID); err != nil {
+				return fmt.Errorf("创建后端 %s 失败: %v", name, err)
+			}
+			result.CreatedBackends = append(result.CreatedBackends, name)
+			continue
+		}
+		if want.Equal(*have) {
+			continue
+		}
+		if err := s.writeBackend(want, true, transactionID); err != nil {
+			return fmt.Errorf("修改后端 %s 失败: %v", name, err)
+		}
+		result.UpdatedBackends = append(result.UpdatedBackends, name)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(current)) {
+		if _, ok := desired.backends[name]; ok {
+			continue
+		}
+		if err := s.confClient.DeleteBackend(name, transactionID, 0); err != nil {
+			return fmt.Errorf("删除后端 %s 失败: %v", name, err)
+		}
+		result.DeletedBackends = append(result.DeletedBackends, name)
+	}
+	return nil
+}
+
+// writeBackend 创建或替换后端，服务器按名称排序逐个写入
+// 结构化接口按 map 的遍历顺序写入服务器，配置文件内容不固定，每次生成都会产生新的配置版本
+func (s *HAProxyServiceImpl) writeBackend(want *models.Backend, exists bool, transactionID string) error {
+	backend := *want
+	backend.Servers = nil
+	var err error
+	if exists {
+		err = s.confClient.EditStructuredBackend(backend.Name, &backend, transactionID, 0)
+	} else {
+		err = s.confClient.CreateStructuredBackend(&backend, transactionID, 0)
+	}
+	if err != nil {
+		return err
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(want.Servers)) {
+		server := want.Servers[name]
+		if err := s.confClient.CreateServer("backend", backend.Name, &server, transactionID, 0); err != nil {
+			return fmt.Errorf("添加服务器 %s 失败: %v", name, err)
+		}
+	}
+	return nil
+}
+
+// applyHTTPErrors 创建、修改和删除站点自定义错误页的 http-errors 段
+func (s *HAProxyServiceImpl) applyHTTPErrors(desired *desiredConfig, transactionID string, result *SiteApplyResult) error {
+	_, sections, err := s.confClient.GetHTTPErrorsSections(transactionID)
+	if err != nil {
+		return fmt.Errorf("获取 http-errors 段失败: %v", err)
+	}
+	current := make(map[string]*models.HTTPErrorsSection)
+	for _, section := range sections {
+		if siteHTTPErrorsPattern.MatchString(section.Name) {
+			current[section.Name] = section
+		}
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(desired.httpErrors)) {
+		want := desired.httpErrors[name]
+		have, ok := current[name]
+		switch {
+		case !ok:
+			err = s.confClient.CreateHTTPErrorsSection(want, transactionID, 0)
+		case !want.Equal(*have):
+			err = s.confClient.EditHTTPErrorsSection(name, want, transactionID, 0)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("修改 http-errors 段 %s 失败: %v", name, err)
+		}
+		result.UpdatedHTTPErrors = append(result.UpdatedHTTPErrors, name)
+	}
+
+	for _, name := range slices.Sorted(maps.Keys(current)) {
+		if _, ok := desired.httpErrors[name]; ok {
+			continue
+		}
+		if err := s.confClient.DeleteHTTPErrorsSection(name, transactionID, 0); err != nil {
+			return fmt.Errorf("删除 http-errors 段 %s 失败: %v", name, err)
+		}
+		result.UpdatedHTTPErrors = append(result.UpdatedHTTPErrors, name)
+	}
+	return nil
+}
+
+// applyFrontendRules 更新端口站点前端的 ACL、后端切换规则和 HTTPS 证书绑定
+func (s *HAProxyServiceImpl) applyFrontendRules(desired *desiredConfig, transactionID string, result *SiteApplyResult) error {
+	for _, port := range slices.Sorted(maps.Keys(desired.ports)) {
+		conf := desired.ports[port]
+		frontends := []struct {
+			name  string
+			acls  models.Acls
+			rules models.BackendSwitchingRules
+		}{
+			{fmt.Sprintf("fe_%d_http", port), conf.httpACLs, conf.httpRules},
+			{fmt.Sprintf("fe_%d_https", port), conf.httpsACLs, conf.httpsRules},
+		}
+		for _, frontend := range frontends {
+			changed := false
+
+			_, acls, err := s.confClient.GetACLs("frontend", frontend.name, transactionID)
+			if err != nil {
+				return fmt.Errorf("获取前端 %s ACL 失败: %v", frontend.name, err)
+			}
+			if !frontend.acls.Equal(acls) {
+				if err := s.confClient.ReplaceAcls("frontend", frontend.name, frontend.acls, transactionID, 0); err != nil {
+					return fmt.Errorf("修改前端 %s ACL 失败: %v", frontend.name, err)
+				}
+				changed = true
+			}
+
+			_, rules, err := s.confClient.GetBackendSwitchingRules(frontend.name, transactionID)
+			if err != nil {
+				return fmt.Errorf("获取前端 %s 后端切换规则失败: %v", frontend.name, err)
+			}
+			if !frontend.rules.Equal(rules) {
+				if err := s.confClient.ReplaceBackendSwitchingRules(frontend.name, frontend.rules, transactionID, 0); err != nil {
+					return fmt.Errorf("修改前端 %s 后端切换规则失败: %v", frontend.name, err)
+				}
+				changed = true
+			}
+
+			if changed && !slices.Contains(result.UpdatedFrontends, frontend.name) {
+				result.UpdatedFrontends = append(result.UpdatedFrontends, frontend.name)
+			}
+		}
+
+		feHTTPS := fmt.Sprintf("fe_%d_https", port)
+		_, bind, err := s.confClient.GetBind("internal_https", "frontend", feHTTPS, transactionID)
+		if err != nil {
+			return fmt.Errorf("获取绑定失败: %v", err)
+		}
+		// 证书和站点的 TLS 策略写在证书列表文件中，文件内容的变化由证书文件的更新处理
+		var crtList string
+		if len(conf.crtList) > 0 {
+			crtList = filepath.Join(s.CertDir, getCrtListName(port))
+		}
+		ssl := crtList != ""
+		if bind.Ssl == ssl && bind.CrtList == crtList && len(bind.DefaultCrtList) == 0 {
+			continue
+		}
+		bind.Ssl = ssl
+		bind.CrtList = crtList
+		bind.DefaultCrtList = nil
+		if err := s.confClient.EditBind("internal_https", "frontend", feHTTPS, bind, transactionID, 0); err != nil {
+			return fmt.Errorf("修改绑定失败: %v", err)
+		}
+		if !slices.Contains(result.UpdatedFrontends, feHTTPS) {
+			result.UpdatedFrontends = append(result.UpdatedFrontends, feHTTPS)
+		}
+	}
+	return nil
+}
+
+// applyCrtLoads 更新 sites 证书存储中的证书加载
+func (s *HAProxyServiceImpl) applyCrtLoads(desired *desiredConfig, transactionID string, result *SiteApplyResult) error {
+	_, crtLoads, err := s.confClient.GetCrtLoads("sites", transactionID)
+	if err != nil {
+		return fmt.Errorf("获取证书加载失败: %v", err)
+	}
+	current := make(map[string]*models.CrtLoad, len(crtLoads))
+	for _, crtLoad := range crtLoads {
+		current[crtLoad.Certificate] = crtLoad
+	}
+
+	for _, certificate := range slices.Sorted(maps.Keys(desired.crtLoads)) {
+		want := desired.crtLoads[certificate]
+		have, ok := current[certificate]
+		switch {
+		case !ok:
+			err = s.confClient.CreateCrtLoad("sites", want, transactionID, 0)
+		case !want.Equal(*have):
+			err = s.confClient.EditCrtLoad(certificate, "sites", want, transactionID, 0)
+		default:
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("修改证书加载 %s 失败: %v", certificate, err)
+		}
+		result.UpdatedCrtLoads = append(result.UpdatedCrtLoads, certificate)
+	}
+
+	for _, certificate := range slices.Sorted(maps.Keys(current)) {
+		if _, ok := desired.crtLoads[certificate]; ok {
+			continue
+		}
+		if err := s.confClient.DeleteCrtLoad(certificate, "sites", transactionID, 0); err != nil {
+			return fmt.Errorf("删除证书加载 %s 失败: %v", certificate, err)
+		}
+		result.UpdatedCrtLoads = append(result.UpdatedCrtLoads, certificate)
+	}
+	return nil
+}
+
+// writeCertFiles 写入内容有变化的证书和私钥文件，返回的函数用于将这些文件恢复为写入前的状态
+func (s *HAProxyServiceImpl) writeCertFiles(desired *desiredConfig, result *SiteApplyResult) (func(), error) {
+	type original struct {
+		path    string
+		content []byte
+		exists  bool
+	}
+	var originals []original
+	restore := func() {
+		for _, file := range originals {
+			if !file.exists {
+				os.Remove(file.path)
+				continue
+			}
+			if err := os.WriteFile(file.path, file.content, 0600); err != nil {
+				s.logger.Error().Err(err).Str("file", file.path).Msg("恢复证书文件失败")
+			}
+		}
+	}
+
+	if err := os.MkdirAll(s.CertDir, 0755); err != nil {
+		return restore, fmt.Errorf("创建证书目录失败: %v", err)
+	}
+	for _, name := range slices.Sorted(maps.Keys(desired.certs)) {
+		path := filepath.Join(s.CertDir, name)
+		content, err := os.ReadFile(path)
+		exists := err == nil
+		if exists && bytes.Equal(content, desired.certs[name]) {
+			continue
+		}
+		if err != nil && !os.IsNotExist(err) {
+			return restore, fmt.Errorf("读取证书文件 %s 失败: %v", name, err)
+		}
+
+		originals = append(originals, original{path: path, content: content, exists: exists})
+		if err := os.WriteFile(path, desired.certs[name], 0600); err != nil {
+			return restore, fmt.Errorf("写入证书文件 %s 失败: %v", name, err)
+		}
+		result.UpdatedCerts = append(result.UpdatedCerts, name)
+	}
+	return restore, nil
+}
+
+// removeStaleCertFiles 删除之前写入、不再被站点使用的证书和私钥文件，并将当前使用的文件记入清单
+// 只删除清单中记录的文件，证书目录中的其他文件保持不变；站点列表不完整时只更新清单，不删除文件
+func (s *HAProxyServiceImpl) removeStaleCertFiles(desired *desiredConfig) {
+	manifestPath := filepath.Join(s.CertDir, certManifestFile)
+	managed := make(map[string]bool, len(desired.certs))
+	for name := range desired.certs {
+		managed[name] = true
+	}
+
+	keepStale := s.staged != nil && s.staged.keepStaleCerts
+	content, err := os.ReadFile(manifestPath)
+	if err == nil {
+		for _, name := range strings.Fields(string(content)) {
+			// 清单中只应有证书目录下的文件名
+			if managed[name] || name != filepath.Base(name) || name == certManifestFile {
+				continue
+			}
+			if keepStale {
+				managed[name] = true
+				continue
+			}
+			if err := os.Remove(filepath.Join(s.CertDir, name)); err != nil && !os.IsNotExist(err) {
+				s.logger.Error().Err(err).Str("file", name).Msg("删除证书文件失败")
+				managed[name] = true
+			}
+		}
+	}
+
+	manifest := []byte(strings.Join(slices.Sorted(maps.Keys(managed)), "\n") + "\n")
+	if bytes.Equal(content, manifest) {
+		return
+	}
+	if err := os.WriteFile(manifestPath, manifest, 0600); err != nil {
+		s.logger.Error().Err(err).Msg("写入证书文件清单失败")
+	}
+}
+
+// normalizeHTTPResponseRules 配置解析器读回 http-response deny 规则时总是设置空的 ReturnContentType，比较前统一为 nil
+func normalizeHTTPResponseRules(rules models.HTTPResponseRules) models.HTTPResponseRules {
+	for _, rule := range rules {
+		if rule.ReturnContentType != nil && *rule.ReturnContentType == "" {
+			rule.ReturnContentType = nil
+		}
+	}
+	return rules
+}
+
+// isSiteManagedBackend 判断后端是否由站点配置生成，Coraza 后端和端口转发用的 TCP 后端除外
+func isSiteManagedBackend(name string) bool {
+	return name != "coraza-spoa" && !portTCPBackendPattern.MatchString(name)
+}
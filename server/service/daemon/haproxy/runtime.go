@@ -0,0 +1,148 @@
+package haproxy
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/haproxytech/client-native/v6/models"
+)
+
+// portBackendPattern 匹配端口默认后端的名称
+var portBackendPattern = regexp.MustCompile(`^p\d+_backend$`)
+
+// SetServerState 通过运行时 API 设置服务器状态，不重新加载配置
+func (s *HAProxyServiceImpl) SetServerState(backendName, serverName string, state model.ServerState) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.ensureRuntimeClient(); err != nil {
+		return err
+	}
+	backendName = s.resolveServerBackend(backendName, serverName)
+	if err := s.runtimeClient.SetServerState(backendName, serverName, string(state)); err != nil {
+		return fmt.Errorf("设置服务器 %s/%s 状态失败: %v", backendName, serverName, err)
+	}
+	return nil
+}
+
+// SetServerWeight 通过运行时 API 设置服务器权重，不重新加载配置
+func (s *HAProxyServiceImpl) SetServerWeight(backendName, serverName string, weight int64) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.ensureRuntimeClient(); err != nil {
+		return err
+	}
+	backendName = s.resolveServerBackend(backendName, serverName)
+	if err := s.runtimeClient.SetServerWeight(backendName, serverName, strconv.FormatInt(weight, 10)); err != nil {
+		return fmt.Errorf("设置服务器 %s/%s 权重失败: %v", backendName, serverName, err)
+	}
+	return nil
+}
+
+// AddServer 通过运行时 API 向已有后端添加服务器，不重新加载配置
+// 动态添加的服务器默认处于维护状态，添加后按配置的状态启用，并开启健康检查
+// 后端按 Host 头拆分时添加到 Host 头相同的分组，没有相同的分组时需要重新生成配置
+func (s *HAProxyServiceImpl) AddServer(backendName, serverName string, server model.Server, backend model.Backend) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.ensureRuntimeClient(); err != nil {
+		return err
+	}
+	// 端口默认后端属于 IP 站点，未设置处理方式时不改写 Host 头
+	isK8s := s.isK8s && !portBackendPattern.MatchString(backendName)
+	hostHeader := GetServerHostHeader(server, isK8s)
+	groups := buildHostGroups(backendName, backend, isK8s)
+	index := slices.IndexFunc(groups, func(group hostGroup) bool { return group.hostHeader == hostHeader })
+	if index == -1 {
+		return fmt.Errorf("后端 %s 中没有 Host 头为 %q 的服务器，无法通过运行时 API 添加，请修改站点配置后重新应用", backendName, hostHeader)
+	}
+	backendName = groups[index].name
+
+	if err := s.runtimeClient.AddServer(backendName, serverName, getRuntimeServerAttributes(serverName, server, backend)); err != nil {
+		return fmt.Errorf("添加服务器 %s/%s 失败: %v", backendName, serverName, err)
+	}
+
+	if backend.HealthCheck != nil {
+		if err := s.runtimeClient.EnableServerHealth(backendName, serverName); err != nil {
+			return fmt.Errorf("开启服务器 %s/%s 健康检查失败: %v", backendName, serverName, err)
+		}
+	}
+
+	state := server.State
+	if state == "" {
+		state = model.ServerStateReady
+	}
+	if err := s.runtimeClient.SetServerState(backendName, serverName, string(state)); err != nil {
+		return fmt.Errorf("设置服务器 %s/%s 状态失败: %v", backendName, serverName, err)
+	}
+	return nil
+}
+
+// DeleteServer 通过运行时 API 从后端删除服务器，不重新加载配置
+// HAProxy 只允许删除处于维护状态且没有活动连接的服务器，有连接时应先 drain
+func (s *HAProxyServiceImpl) DeleteServer(backendName, serverName string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if err := s.ensureRuntimeClient(); err != nil {
+		return err
+	}
+	backendName = s.resolveServerBackend(backendName, serverName)
+	if err := s.runtimeClient.SetServerState(backendName, serverName, string(model.ServerStateMaint)); err != nil {
+		return fmt.Errorf("设置服务器 %s/%s 为维护状态失败: %v", backendName, serverName, err)
+	}
+	if err := s.runtimeClient.DeleteServer(backendName, serverName); err != nil {
+		return fmt.Errorf("删除服务器 %s/%s 失败，请确认服务器已没有活动连接: %v", backendName, serverName, err)
+	}
+	return nil
+}
+
+// resolveServerBackend 返回服务器实际所在的 HAProxy 后端
+// 后端按 Host 头拆分时服务器可能位于拆分出的后端中，依次查找各个分组直到后端不存在，找不到时返回原后端名称
+func (s *HAProxyServiceImpl) resolveServerBackend(backendName, serverName string) string {
+	for index := 0; ; index++ {
+		name := getHostGroupBackendName(backendName, index)
+		servers, err := s.runtimeClient.GetServersState(name)
+		if err != nil {
+			return backendName
+		}
+		if slices.ContainsFunc(servers, func(server *models.RuntimeServer) bool { return server.Name == serverName }) {
+			return name
+		}
+	}
+}
+
+// getRuntimeServerAttributes 生成运行时 add server 命令的服务器参数，与 buildBackendServer 生成的配置保持一致
+func getRuntimeServerAttributes(serverName string, server model.Server, backend model.Backend) string {
+	attributes := []string{fmt.Sprintf("%s:%d", server.Host, server.Port)}
+	if server.IsSSL {
+		attributes = append(attributes, "ssl", "verify", "none", "sni", fmt.Sprintf("str(%s)", server.Host))
+	}
+	if server.Weight > 0 {
+		attributes = append(attributes, "weight", strconv.FormatInt(server.Weight, 10))
+	}
+	if server.Backup {
+		attributes = append(attributes, "backup")
+	}
+	if server.MaxConn > 0 {
+		attributes = append(attributes, "maxconn", strconv.FormatInt(server.MaxConn, 10))
+	}
+	if check := backend.HealthCheck; check != nil {
+		attributes = append(attributes,
+			"check",
+			"inter", strconv.FormatInt(check.Interval, 10),
+			"rise", strconv.FormatInt(check.Rise, 10),
+			"fall", strconv.FormatInt(check.Fall, 10),
+		)
+	}
+	if backend.StickyCookie != "" {
+		attributes = append(attributes, "cookie", serverName)
+	}
+	return strings.Join(attributes, " ")
+}
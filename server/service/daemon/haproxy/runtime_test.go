@@ -0,0 +1,222 @@
+package haproxy
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/haproxytech/client-native/v6/config-parser/params"
+	"github.com/haproxytech/client-native/v6/configuration"
+	cfg_opt "github.com/haproxytech/client-native/v6/configuration/options"
+	"github.com/haproxytech/client-native/v6/models"
+	runtime_api "github.com/haproxytech/client-native/v6/runtime"
+)
+
+// fakeRuntimeClient 只实现服务器操作使用的方法，servers 为各后端中的服务器名称，按调用顺序记录修改
+type fakeRuntimeClient struct {
+	runtime_api.Runtime
+	servers map[string][]string
+	calls   []string
+}
+
+func (c *fakeRuntimeClient) GetServersState(backend string) (models.RuntimeServers, error) {
+	names, ok := c.servers[backend]
+	if !ok {
+		return nil, errors.New("no such backend")
+	}
+	servers := make(models.RuntimeServers, len(names))
+	for i, name := range names {
+		servers[i] = &models.RuntimeServer{Name: name}
+	}
+	return servers, nil
+}
+
+func (c *fakeRuntimeClient) AddServer(backend, name, attributes string) error {
+	c.calls = append(c.calls, fmt.Sprintf("add %s/%s %s", backend, name, attributes))
+	return nil
+}
+
+func (c *fakeRuntimeClient) EnableServerHealth(backend, server string) error {
+	c.calls = append(c.calls, fmt.Sprintf("health %s/%s", backend, server))
+	return nil
+}
+
+func (c *fakeRuntimeClient) SetServerState(backend, server string, state string) error {
+	c.calls = append(c.calls, fmt.Sprintf("state %s/%s %s", backend, server, state))
+	return nil
+}
+
+// TestResolveServerBackend 测试按 Host 头拆分的后端中查找服务器实际所在的分组
+func TestResolveServerBackend(t *testing.T) {
+	s := &HAProxyServiceImpl{runtimeClient: &fakeRuntimeClient{servers: map[string][]string{
+		"be_example_com":    {"example_com_0"},
+		"be_example_com_h1": {"example_com_1"},
+		"be_example_com_h2": {"example_com_2", "example_com_3"},
+	}}}
+
+	tests := []struct {
+		server string
+		want   string
+	}{
+		{"example_com_0", "be_example_com"},
+		{"example_com_1", "be_example_com_h1"},
+		{"example_com_3", "be_example_com_h2"},
+		{"example_com_9", "be_example_com"},
+	}
+	for _, tt := range tests {
+		if got := s.resolveServerBackend("be_example_com", tt.server); got != tt.want {
+			t.Errorf("resolveServerBackend(%s) = %s, want %s", tt.server, got, tt.want)
+		}
+	}
+}
+
+// TestRuntimeAddServerHostGroup 测试按服务器的 Host 头添加到对应的分组，没有相同 Host 头的分组时返回错误
+func TestRuntimeAddServerHostGroup(t *testing.T) {
+	backend := newHostHeaderSite().Backend
+	backend.HealthCheck = &model.HealthCheck{Interval: 2000, Rise: 2, Fall: 3}
+
+	tests := []struct {
+		name        string
+		isK8s       bool
+		backendName string
+		server      model.Server
+		want        []string
+		wantErr     bool
+	}{
+		{
+			name:        "default group",
+			isK8s:       true,
+			backendName: "be_example_com",
+			server:      model.Server{Host: "a.svc", Port: 81},
+			want: []string{
+				"add be_example_com/example_com_5 a.svc:81 check inter 2000 rise 2 fall 3",
+				"health be_example_com/example_com_5",
+				"state be_example_com/example_com_5 ready",
+			},
+		},
+		{
+			name:        "custom host group",
+			isK8s:       true,
+			backendName: "be_example_com",
+			server:      model.Server{Host: "f.svc", Port: 80, HostHeader: model.HostHeaderCustom, HostHeaderValue: "api.example.com", State: model.ServerStateDrain},
+			want: []string{
+				"add be_example_com_h3/example_com_5 f.svc:80 check inter 2000 rise 2 fall 3",
+				"health be_example_com_h3/example_com_5",
+				"state be_example_com_h3/example_com_5 drain",
+			},
+		},
+		{
+			name:        "new host header",
+			isK8s:       true,
+			backendName: "be_example_com",
+			server:      model.Server{Host: "f.svc", Port: 80, HostHeader: model.HostHeaderCustom, HostHeaderValue: "other.example.com"},
+			wantErr:     true,
+		},
+		{
+			// 端口默认后端属于 IP 站点，未设置处理方式的服务器不改写 Host 头，与同样保留 Host 头的第一个服务器在同一分组
+			name:        "port backend",
+			isK8s:       true,
+			backendName: "p8080_backend",
+			server:      model.Server{Host: "f.svc", Port: 80},
+			want: []string{
+				"add p8080_backend/example_com_5 f.svc:80 check inter 2000 rise 2 fall 3",
+				"health p8080_backend/example_com_5",
+				"state p8080_backend/example_com_5 ready",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			client := &fakeRuntimeClient{}
+			s := &HAProxyServiceImpl{runtimeClient: client, isK8s: tt.isK8s}
+			err := s.AddServer(tt.backendName, "example_com_5", tt.server, backend)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("AddServer() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !slices.Equal(client.calls, tt.want) {
+				t.Errorf("runtime calls = %q, want %q", client.calls, tt.want)
+			}
+		})
+	}
+}
+
+// TestGetRuntimeServerAttributes 测试运行时 add server 命令的服务器参数
+func TestGetRuntimeServerAttributes(t *testing.T) {
+	check := &model.HealthCheck{Interval: 2000, Rise: 2, Fall: 3}
+	tests := []struct {
+		name    string
+		server  model.Server
+		backend model.Backend
+		want    string
+	}{
+		{"plain", model.Server{Host: "a.svc", Port: 80}, model.Backend{}, "a.svc:80"},
+		{
+			"ssl",
+			model.Server{Host: "a.svc", Port: 443, IsSSL: true},
+			model.Backend{},
+			"a.svc:443 ssl verify none sni str(a.svc)",
+		},
+		{
+			"options",
+			model.Server{Host: "a.svc", Port: 80, Weight: 5, Backup: true, MaxConn: 100},
+			model.Backend{},
+			"a.svc:80 weight 5 backup maxconn 100",
+		},
+		{
+			"health check and cookie",
+			model.Server{Host: "a.svc", Port: 80},
+			model.Backend{HealthCheck: check, StickyCookie: "SRV"},
+			"a.svc:80 check inter 2000 rise 2 fall 3 cookie example_com_0",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getRuntimeServerAttributes("example_com_0", tt.server, tt.backend); got != tt.want {
+				t.Errorf("getRuntimeServerAttributes() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestRuntimeServerAttributesMatchConfig 测试运行时添加的服务器与 buildBackendServer 写入配置文件的服务器参数一致
+// 服务器状态由运行时 API 单独设置，不在比较范围内
+func TestRuntimeServerAttributesMatchConfig(t *testing.T) {
+	check := &model.HealthCheck{Interval: 2000, Rise: 2, Fall: 3}
+	servers := []model.Server{
+		{Host: "a.svc", Port: 80},
+		{Host: "a.svc", Port: 443, IsSSL: true, Weight: 5},
+		{Host: "10.0.0.1", Port: 8080, Backup: true, MaxConn: 100},
+		{Host: "b.svc", Port: 443, IsSSL: true, Weight: 2, Backup: true, MaxConn: 10},
+	}
+	backends := []model.Backend{{}, {HealthCheck: check}, {StickyCookie: "SRV"}, {HealthCheck: check, StickyCookie: "SRV"}}
+
+	for _, server := range servers {
+		for _, backend := range backends {
+			fields := strings.Fields(getRuntimeServerAttributes("example_com_0", server, backend))
+			options, err := params.ParseServerOptions(fields[1:])
+			if err != nil {
+				t.Fatalf("ParseServerOptions(%q) error = %v", fields, err)
+			}
+			runtime := serverOptionStrings(options)
+
+			config := configuration.SerializeServer(buildBackendServer("example_com_0", server, backend), &cfg_opt.ConfigurationOptions{})
+			want := serverOptionStrings(config.Params)
+			if fields[0] != config.Address || !slices.Equal(runtime, want) {
+				t.Errorf("server %+v backend %+v: runtime = %s %q, config = %s %q", server, backend, fields[0], runtime, config.Address, want)
+			}
+		}
+	}
+}
+
+// serverOptionStrings 返回排序后的服务器参数，忽略参数顺序
+func serverOptionStrings(options []params.ServerOption) []string {
+	result := make([]string, len(options))
+	for i, option := range options {
+		result[i] = option.String()
+	}
+	slices.Sort(result)
+	return result
+}
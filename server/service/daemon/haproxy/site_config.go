@@ -0,0 +1,624 @@
+package haproxy
+
+import (
+	"bytes"
+	"fmt"
+	"net"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/haproxytech/client-native/v6/models"
+)
+
+const (
+	acmeChallengeBackend = "acme_challenge"               // 转发 ACME HTTP-01 验证请求的后端
+	acmeChallengePath    = "/.well-known/acme-challenge/" // ACME HTTP-01 验证请求的路径前缀
+)
+
+// desiredConfig 由站点列表生成的期望配置，只包含站点相关的部分
+type desiredConfig struct {
+	ports      map[int]*desiredPort                 // 监听端口
+	backends   map[string]*models.Backend           // 站点后端和端口默认后端，按名称索引
+	crtLoads   map[string]*models.CrtLoad           // sites 证书存储中的证书加载，按证书文件名索引
+	httpErrors map[string]*models.HTTPErrorsSection // 站点自定义错误页的 http-errors 段，按名称索引
+	certs      map[string][]byte                    // 证书目录下的证书、私钥、CA 证书、证书列表、维护页和错误页文件内容，按文件名索引
+	certDir    string                               // 证书目录，证书列表中的 CA 证书、HTTPS 绑定的证书列表、维护页和错误页使用绝对路径引用
+
+	acmeHostMap string // 正在进行 HTTP-01 验证的主机名映射文件，签发证书时站点可能还没有使用该证书
+}
+
+// desiredPort 监听端口的期望配置
+type desiredPort struct {
+	httpACLs   models.Acls                  // HTTP 前端的站点 ACL
+	httpRules  models.BackendSwitchingRules // HTTP 前端的后端切换规则
+	httpsACLs  models.Acls                  // HTTPS 前端的站点 ACL
+	httpsRules models.BackendSwitchingRules // HTTPS 前端的后端切换规则
+	crtList    []string                     // HTTPS 前端证书列表的条目，每个站点一行，按 SNI 应用站点的 TLS 策略
+	tlsRules   models.HTTPRequestRules      // HTTPS 前端按站点 TLS 策略校验客户端证书和设置 HSTS 的请求规则
+	hsts       bool                         // HTTPS 前端是否有站点启用了 HSTS
+	ipSite     *model.Site                  // 使用端口默认后端的 IP 站点
+	limits     portLimits                   // 端口前端的连接保护，取端口上所有站点中最严格的值
+	httpHosts  []string                     // HTTP 前端的域名站点主机名 ACL 名称
+	httpsHosts []string                     // HTTPS 前端的域名站点主机名 ACL 名称
+	httpTCP    models.TCPRequestRules       // HTTP 前端按站点统计请求速率和判断卸载的微规则的 TCP 请求规则
+	httpsTCP   models.TCPRequestRules       // HTTPS 前端按站点统计请求速率和判断卸载的微规则的 TCP 请求规则
+}
+
+// httpsRequestRules 返回 HTTPS 前端的请求规则，站点 TLS 策略的规则排在 WAF 处置规则之前
+func (p *desiredPort) httpsRequestRules() models.HTTPRequestRules {
+	return append(slices.Clone(p.tlsRules), buildFeHTTPRequestRules()...)
+}
+
+// httpsResponseRules 返回 HTTPS 前端的响应规则，有站点启用 HSTS 时在最后添加 HSTS 响应头
+func (p *desiredPort) httpsResponseRules() models.HTTPResponseRules {
+	rules := buildFeHTTPResponseRules()
+	if p.hsts {
+		rules = append(rules, buildHSTSResponseRule())
+	}
+	return rules
+}
+
+// SiteConfigError 站点配置无法生成时返回的错误，调用方可以跳过该站点后重试
+type SiteConfigError struct {
+	Site model.Site
+	Err  error
+}
+
+func (e *SiteConfigError) Error() string {
+	return fmt.Sprintf("站点 %s 配置无效: %v", e.Site.Name, e.Err)
+}
+
+func (e *SiteConfigError) Unwrap() error {
+	return e.Err
+}
+
+// buildDesiredConfig 根据启用的站点生成期望配置，站点按给定顺序生成 ACL 和切换规则
+// acmeAddress 为管理服务地址，使用 ACME HTTP-01 证书的站点和 acmeHostMap 中正在验证的主机名的验证请求转发到这里；certDir 为证书目录
+func buildDesiredConfig(sites []model.Site, isK8s bool, acmeAddress, acmeHostMap, certDir string) (*desiredConfig, error) {
+	desired := &desiredConfig{
+		ports:       make(map[int]*desiredPort),
+		backends:    make(map[string]*models.Backend),
+		crtLoads:    make(map[string]*models.CrtLoad),
+		httpErrors:  make(map[string]*models.HTTPErrorsSection),
+		certs:       make(map[string][]byte),
+		certDir:     certDir,
+		acmeHostMap: acmeHostMap,
+	}
+
+	for _, site := range sites {
+		if !site.ActiveStatus {
+			continue
+		}
+		if err := desired.addSite(site, isK8s); err != nil {
+			return nil, &SiteConfigError{Site: site, Err: err}
+		}
+	}
+
+	for port, conf := range desired.ports {
+		name := getPortDefaultBackendName(port)
+		desired.backends[name] = buildPortDefaultBackend(port, conf.ipSite, certDir)
+		if ipSite := conf.ipSite; ipSite != nil && hasRequestRate(*ipSite) {
+			// IP 站点处理未命中任何域名站点主机名的请求
+			conf.httpTCP = append(conf.httpTCP, buildRequestRateRules(*ipSite, getIPSiteRateCond(conf.httpHosts))...)
+			conf.httpsTCP = append(conf.httpsTCP, buildRequestRateRules(*ipSite, getIPSiteRateCond(conf.httpsHosts))...)
+		}
+		if conf.limits.connRate > 0 {
+			desired.backends[getPortRateTableName(port)] = buildRateTableBackend(getPortRateTableName(port), fmt.Sprintf("conn_rate(%s)", ratePeriod))
+		}
+		if len(conf.crtList) > 0 {
+			desired.certs[getCrtListName(port)] = []byte(strings.Join(conf.crtList, "\n") + "\n")
+		}
+	}
+	if len(desired.ports) > 0 {
+		desired.backends[acmeChallengeBackend] = buildACMEChallengeBackend(acmeAddress)
+	}
+	return desired, nil
+}
+
+// addSite 将站点的后端、ACL、切换规则、连接保护、证书、维护页和错误页加入期望配置
+func (d *desiredConfig) addSite(site model.Site, isK8s bool) error {
+	if err := model.ValidateSite(&site); err != nil {
+		return err
+	}
+	if err := checkSiteBackend(site.Backend); err != nil {
+		return err
+	}
+	for index, route := range site.Routes {
+		if err := checkSiteBackend(route.Backend); err != nil {
+			return fmt.Errorf("路径路由 #%d: %v", index, err)
+		}
+	}
+	if site.EnableHTTPS && (site.Certificate.PublicKey == "" || site.Certificate.PrivateKey == "") {
+		return fmt.Errorf("启用了 HTTPS 但证书为空")
+	}
+
+	port, ok := d.ports[site.ListenPort]
+	if !ok {
+		// 正在验证的主机名的 ACME 验证请求的切换规则排在所有站点之前
+		port = &desiredPort{
+			httpRules: models.BackendSwitchingRules{buildACMEChallengeRule(d.acmeHostMap)},
+		}
+		d.ports[site.ListenPort] = port
+	}
+	port.limits.merge(site.Limits)
+	if hasRequestRate(site) {
+		name := getSiteRateTableName(site)
+		if _, exists := d.backends[name]; exists {
+			return fmt.Errorf("速率统计表 %s 与其他站点重复", name)
+		}
+		d.backends[name] = buildRateTableBackend(name, fmt.Sprintf("http_req_rate(%s)", ratePeriod))
+	}
+
+	if isIPAddress(site.Domain) {
+		// IP 站点没有主机名 ACL，使用端口的默认后端
+		if port.ipSite != nil {
+			return fmt.Errorf("端口 %d 已被 IP 站点 %s 使用", site.ListenPort, port.ipSite.Name)
+		}
+		port.ipSite = &site
+	} else {
+		backends := map[string]model.Backend{GetSiteBackendName(site): site.Backend}
+		for index, route := range site.Routes {
+			backends[GetRouteBackendName(site, index)] = route.Backend
+		}
+		for name, backend := range backends {
+			for _, group := range buildHostGroups(name, backend, isK8s) {
+				if _, exists := d.backends[group.name]; exists {
+					return fmt.Errorf("后端 %s 与其他站点重复", group.name)
+				}
+				d.backends[group.name] = buildSiteBackend(name, group, site, backend, d.certDir)
+			}
+		}
+
+		acls := buildSiteACLs(site)
+		rules := buildSiteSwitchingRules(site, isK8s)
+		hostACLName := getHostACLName(site)
+		var rateRules models.TCPRequestRules
+		if hasRequestRate(site) {
+			rateRules = buildRequestRateRules(site, hostACLName)
+		}
+		port.httpACLs = append(port.httpACLs, acls...)
+		if site.Certificate.ACMEChallenge == model.ACMEChallengeHTTP01 {
+			// 续期使用 HTTP-01 验证的证书时验证请求转发到管理服务，其他站点的验证请求由站点后端自己处理
+			port.httpRules = append(port.httpRules, buildSiteACMEChallengeRule(hostACLName))
+		}
+		port.httpRules = append(port.httpRules, rules...)
+		port.httpHosts = append(port.httpHosts, hostACLName)
+		port.httpTCP = append(port.httpTCP, rateRules...)
+		if site.EnableHTTPS {
+			port.httpsACLs = append(port.httpsACLs, acls...)
+			port.httpsRules = append(port.httpsRules, rules...)
+			port.httpsHosts = append(port.httpsHosts, hostACLName)
+			port.httpsTCP = append(port.httpsTCP, rateRules...)
+			if policy := site.TLSPolicy; policy != nil {
+				port.httpsACLs = append(port.httpsACLs, buildTLSPolicyACLs(site)...)
+				port.tlsRules = append(port.tlsRules, buildTLSPolicyRequestRules(site)...)
+				port.hsts = port.hsts || policy.HSTS != nil
+			}
+		}
+	}
+
+	if site.EnableHTTPS {
+		crtLoad := buildSiteCrtLoad(site)
+		certPEM, keyPEM := []byte(site.Certificate.PublicKey), []byte(site.Certificate.PrivateKey)
+		if existing, exists := d.crtLoads[crtLoad.Certificate]; exists {
+			// 证书库中的证书可以被多个站点共用，只加载一次，任一站点启用 OCSP Stapling 时为该证书启用
+			if !bytes.Equal(d.certs[crtLoad.Certificate], certPEM) || !bytes.Equal(d.certs[crtLoad.Key], keyPEM) {
+				return fmt.Errorf("证书 %s 与其他站点重复", crtLoad.Certificate)
+			}
+			crtLoad.OcspUpdate = existing.OcspUpdate
+		}
+		if site.TLSPolicy != nil && site.TLSPolicy.OCSPStapling {
+			crtLoad.OcspUpdate = "enabled"
+		}
+		d.crtLoads[crtLoad.Certificate] = crtLoad
+		d.certs[crtLoad.Certificate] = certPEM
+		d.certs[crtLoad.Key] = keyPEM
+
+		var caFile string
+		if site.TLSPolicy != nil && site.TLSPolicy.ClientAuth != nil && !isIPAddress(site.Domain) {
+			caPEM := []byte(site.TLSPolicy.ClientAuth.CACertificate)
+			caName := getClientCAName(caPEM)
+			d.certs[caName] = caPEM
+			caFile = filepath.Join(d.certDir, caName)
+		}
+		port.crtList = append(port.crtList, buildCrtListEntry(site, crtLoad, caFile))
+	}
+
+	if maintenance := site.Maintenance; maintenance != nil && maintenance.Enabled && maintenance.Page != "" {
+		d.certs[getMaintenancePageName(maintenance.Page)] = []byte(maintenance.Page)
+	}
+	if section := buildHTTPErrorsSection(site, d.certDir); section != nil {
+		if _, exists := d.httpErrors[section.Name]; exists {
+			return fmt.Errorf("错误页 %s 与其他站点重复", section.Name)
+		}
+		d.httpErrors[section.Name] = section
+		for _, page := range site.ErrorPages {
+			content := buildErrorFile(page)
+			d.certs[getContentFileName("error", content, ".http")] = content
+		}
+	}
+	return nil
+}
+
+// checkSiteBackend 检查后端是否可以生成配置
+func checkSiteBackend(backend model.Backend) error {
+	if len(backend.Servers) == 0 {
+		return fmt.Errorf("后端没有服务器")
+	}
+	return nil
+}
+
+// buildSiteBackend 生成站点后端的一个 Host 头分组及其服务器、健康检查、请求头/响应头规则、维护模式、错误页和超时
+// name 为拆分前的后端名称，服务器名称按服务器在拆分前后端中的序号生成，拆分后保持不变
+func buildSiteBackend(name string, group hostGroup, site model.Site, backend model.Backend, certDir string) *models.Backend {
+	conf := &models.Backend{
+		BackendBase: models.BackendBase{
+			Name:    group.name,
+			Mode:    "http",
+			Enabled: true,
+			From:    "http",
+			// 添加forwarded选项
+			Forwardfor: &models.Forwardfor{
+				Enabled: StringP("enabled"),
+				Ifnone:  true,
+			},
+		},
+	}
+	applyBackendOptions(&conf.BackendBase, backend)
+	conf.HTTPCheckList = buildHealthCheckRules(backend)
+	conf.HTTPRequestRuleList, conf.HTTPResponseRuleList = buildHeaderRules(site, group.hostHeader)
+	applySitePages(conf, site, certDir)
+	applySiteLimits(conf, site)
+
+	conf.Servers = make(map[string]models.Server, len(group.servers))
+	for _, index := range group.servers {
+		server := backend.Servers[index]
+		serverName := getServerName(getBackendServerName(name, index), server)
+		conf.Servers[serverName] = buildBackendServer(serverName, server, backend)
+	}
+	return conf
+}
+
+// buildPortDefaultBackend 生成端口的默认后端，未命中任何站点主机名的请求转发到这里
+// 端口上有 IP 站点时使用该站点的后端配置，否则只有一个占位服务器
+func buildPortDefaultBackend(port int, ipSite *model.Site, certDir string) *models.Backend {
+	conf := &models.Backend{
+		BackendBase: models.BackendBase{
+			Name:    getPortDefaultBackendName(port),
+			Mode:    "http",
+			Enabled: true,
+			From:    "http",
+			// 添加forwarded选项
+			Forwardfor: &models.Forwardfor{
+				Enabled: StringP("enabled"),
+				Ifnone:  true,
+			},
+		},
+	}
+
+	if ipSite == nil {
+		conf.Servers = map[string]models.Server{
+			"loopback-for-default": {
+				Name:    "loopback-for-default",
+				Address: "httpbin.org",
+				Port:    Int64P(80),
+			},
+		}
+		return conf
+	}
+
+	applyBackendOptions(&conf.BackendBase, ipSite.Backend)
+	conf.HTTPCheckList = buildHealthCheckRules(ipSite.Backend)
+	// IP 站点只有一个后端，所有服务器使用相同的 Host 头；未设置处理方式时在 k8s 环境中也保留客户端的 Host 头
+	conf.HTTPRequestRuleList, conf.HTTPResponseRuleList = buildHeaderRules(*ipSite, GetServerHostHeader(ipSite.Backend.Servers[0], false))
+	applySitePages(conf, *ipSite, certDir)
+	applySiteLimits(conf, *ipSite)
+	conf.Servers = make(map[string]models.Server, len(ipSite.Backend.Servers))
+	for index, server := range ipSite.Backend.Servers {
+		serverName := getServerName(getIPSiteServerName(*ipSite, index), server)
+		conf.Servers[serverName] = buildBackendServer(serverName, server, ipSite.Backend)
+	}
+	return conf
+}
+
+// buildACMEChallengeBackend 生成将 ACME HTTP-01 验证请求转发到管理服务的后端
+func buildACMEChallengeBackend(address string) *models.Backend {
+	host, portStr, _ := net.SplitHostPort(address)
+	port, _ := strconv.ParseInt(portStr, 10, 64)
+	return &models.Backend{
+		BackendBase: models.BackendBase{
+			Name:    acmeChallengeBackend,
+			Mode:    "http",
+			Enabled: true,
+			From:    "http",
+		},
+		Servers: map[string]models.Server{
+			"management": {
+				Name:    "management",
+				Address: host,
+				Port:    Int64P(port),
+			},
+		},
+	}
+}
+
+// buildACMEChallengeRule 生成将映射文件中正在验证的主机名的 ACME HTTP-01 验证请求切换到管理服务后端的规则
+// 映射文件在签发和续期期间通过运行时 API 修改，主机名去掉端口并转换为小写后查找
+func buildACMEChallengeRule(hostMap string) *models.BackendSwitchingRule {
+	return &models.BackendSwitchingRule{
+		Name:     acmeChallengeBackend,
+		Cond:     "if",
+		CondTest: fmt.Sprintf("{ path_beg %s } { hdr(host),field(1,:),lower,map_str(%s) -m found }", acmeChallengePath, hostMap),
+	}
+}
+
+// buildSiteACMEChallengeRule 生成将使用 ACME HTTP-01 证书的站点的验证请求切换到管理服务后端的规则
+func buildSiteACMEChallengeRule(hostACLName string) *models.BackendSwitchingRule {
+	return &models.BackendSwitchingRule{
+		Name:     acmeChallengeBackend,
+		Cond:     "if",
+		CondTest: fmt.Sprintf("{ path_beg %s } %s", acmeChallengePath, hostACLName),
+	}
+}
+
+// buildBackendServer 生成后端服务器配置
+func buildBackendServer(name string, conf model.Server, backend model.Backend) models.Server {
+	server := models.Server{
+		Name:    name,
+		Address: conf.Host,
+		Port:    Int64P(int64(conf.Port)),
+	}
+
+	if conf.IsSSL {
+		server.ServerParams = models.ServerParams{
+			Ssl: "enabled",
+			Sni: fmt.Sprintf("str(%s)", conf.Host),
+			// SslCafile: "",
+			Verify: "none", // 不验证证书
+		}
+	}
+
+	if conf.Weight > 0 {
+		server.Weight = Int64P(conf.Weight)
+	}
+	if conf.Backup {
+		server.Backup = "enabled"
+	}
+	if conf.MaxConn > 0 {
+		server.Maxconn = Int64P(conf.MaxConn)
+	}
+	switch conf.State {
+	case model.ServerStateMaint:
+		server.Maintenance = "enabled"
+	case model.ServerStateDrain:
+		// 配置文件中没有 drain 关键字，以权重 0 表示
+		server.Weight = Int64P(0)
+	}
+	// 健康检查的间隔和次数是服务器参数，启用 SSL 的服务器会自动使用 SSL 进行检查
+	if check := backend.HealthCheck; check != nil {
+		server.Check = "enabled"
+		server.Inter = Int64P(check.Interval)
+		server.Rise = Int64P(check.Rise)
+		server.Fall = Int64P(check.Fall)
+	}
+	// 会话保持 Cookie 的值使用服务器名称
+	if backend.StickyCookie != "" {
+		server.Cookie = name
+	}
+
+	return server
+}
+
+// buildHealthCheckRules 生成 HTTP 健康检查的请求和期望响应，未配置健康检查时返回 nil
+func buildHealthCheckRules(backend model.Backend) models.HTTPChecks {
+	if backend.HealthCheck == nil {
+		return nil
+	}
+
+	return models.HTTPChecks{
+		{
+			Type:    "send",
+			Method:  "GET",
+			URI:     backend.HealthCheck.Path,
+			Version: "HTTP/1.1",
+			// HTTP/1.1 要求携带 Host 头，使用第一个后端服务器的地址
+			CheckHeaders: []*models.ReturnHeader{
+				{Name: StringP("Host"), Fmt: StringP(backend.Servers[0].Host)},
+			},
+		},
+		{
+			Type:    "expect",
+			Match:   "status",
+			Pattern: backend.HealthCheck.ExpectStatus,
+		},
+	}
+}
+
+// applyBackendOptions 将负载均衡算法、健康检查和会话保持配置写入 HAProxy 后端
+func applyBackendOptions(base *models.BackendBase, backend model.Backend) {
+	if backend.Balance != "" {
+		base.Balance = &models.Balance{Algorithm: StringP(string(backend.Balance))}
+	}
+	if backend.HealthCheck != nil {
+		base.AdvCheck = "httpchk"
+	}
+	if backend.StickyCookie != "" {
+		base.Cookie = &models.Cookie{
+			Name:     StringP(backend.StickyCookie),
+			Type:     "insert",
+			Indirect: true,
+			Nocache:  true,
+			Httponly: true,
+		}
+	}
+}
+
+// buildSiteACLs 生成站点的主机名和路径 ACL
+func buildSiteACLs(site model.Site) models.Acls {
+	acls := buildHostACLs(getHostACLName(site), site.Hostnames())
+	for index, route := range site.Routes {
+		acls = append(acls, &models.ACL{
+			ACLName:   getRouteACLName(site, index),
+			Criterion: "path",
+			Value:     "-m beg " + route.PathPrefix,
+		})
+	}
+	return acls
+}
+
+// buildHostACLs 生成匹配站点主机名的 ACL，同名 ACL 的多行之间为或关系
+// 先去掉 Host 头中的端口再比较；普通域名完全匹配，*.example.com 匹配以 .example.com 结尾的主机名，
+// 不会匹配 example.com 和 evil-example.com
+// 匹配选项写在 Value 中，与配置解析器读回的结构保持一致，便于比较
+func buildHostACLs(aclName string, hostnames []string) models.Acls {
+	acls := make(models.Acls, 0, len(hostnames))
+	for _, hostname := range hostnames {
+		acl := &models.ACL{
+			ACLName:   aclName,
+			Criterion: "hdr(host),field(1,:)",
+			Value:     "-i " + hostname,
+		}
+		if suffix, ok := strings.CutPrefix(hostname, "*."); ok {
+			acl.Value = "-i -m end ." + suffix
+		}
+		acls = append(acls, acl)
+	}
+	return acls
+}
+
+// buildSiteSwitchingRules 生成站点的后端切换规则
+// 路径路由按配置顺序排在站点默认后端之前，先命中的路由生效；后端按 Host 头拆分时切换到各个分组
+func buildSiteSwitchingRules(site model.Site, isK8s bool) models.BackendSwitchingRules {
+	hostACLName := getHostACLName(site)
+	rules := make(models.BackendSwitchingRules, 0, len(site.Routes)+1)
+	appendBackend := func(name string, backend model.Backend, cond string) {
+		servers := make([]string, len(backend.Servers))
+		for index, server := range backend.Servers {
+			servers[index] = getServerName(getBackendServerName(name, index), server)
+		}
+		groups := buildHostGroups(name, backend, isK8s)
+		rules = append(rules, buildHostGroupSwitchingRules(groups, backend, servers, cond)...)
+	}
+	for index, route := range site.Routes {
+		appendBackend(GetRouteBackendName(site, index), route.Backend, fmt.Sprintf("%s %s", hostACLName, getRouteACLName(site, index)))
+	}
+	appendBackend(GetSiteBackendName(site), site.Backend, hostACLName)
+	return rules
+}
+
+// buildSiteCrtLoad 生成站点证书在 sites 证书存储中的加载配置
+// 证书库中的证书按证书ID命名，使用同一证书的站点共用一个加载配置；旧版本内嵌的证书按站点域名命名
+func buildSiteCrtLoad(site model.Site) *models.CrtLoad {
+	if !site.CertificateID.IsZero() {
+		name := "cert_" + site.CertificateID.Hex()
+		return &models.CrtLoad{
+			Certificate: name + ".crt",
+			Key:         name + ".key",
+			Alias:       name,
+		}
+	}
+	return &models.CrtLoad{
+		Certificate: site.Domain + ".crt",
+		Key:         site.Domain + ".key",
+		Alias:       fmt.Sprintf("%s_cert", getDashDomain(site.Domain)),
+	}
+}
+
+// buildFeHTTPRequestRules 生成站点 HTTP/HTTPS 前端根据卸载的微规则和 WAF 检测结果处置请求的规则
+// 重定向到 HTTPS 由各站点的后端处理，ACME 验证请求切换到单独的后端，不受影响
+func buildFeHTTPRequestRules() models.HTTPRequestRules {
+	return models.HTTPRequestRules{
+		{
+			Type:       "deny",
+			DenyStatus: Int64P(403),
+			Cond:       "if",
+			CondTest:   fmt.Sprintf("{ var(txn.%s) -m str deny }", microActionVar),
+		},
+		{
+			Type:       "redirect",
+			RedirCode:  Int64P(302),
+			RedirType:  "location", // 指定重定向类型
+			RedirValue: "%[var(txn.coraza.data)]",
+			Cond:       "if",
+			CondTest:   "{ var(txn.coraza.action) -m str redirect }",
+		},
+		{
+			Type:       "deny",
+			DenyStatus: Int64P(403),
+			Cond:       "if",
+			CondTest:   "{ var(txn.coraza.action) -m str deny }",
+		},
+		{
+			Type:     "silent-drop",
+			Cond:     "if",
+			CondTest: "{ var(txn.coraza.action) -m str drop }",
+		},
+		{
+			Type:                "return",
+			ReturnStatusCode:    Int64P(200),
+			ReturnContentType:   StringP("text/html"),
+			ReturnContentFormat: "lf-string",
+			ReturnContent:       `"%[var(txn.coraza.data)]"`, // 蜜罐陷阱诱饵响应
+			Cond:                "if",
+			CondTest:            "{ var(txn.coraza.action) -m str decoy } { var(txn.coraza.status) -m int 200 }",
+		},
+		{
+			Type:                "return",
+			ReturnStatusCode:    Int64P(404),
+			ReturnContentType:   StringP("text/html"),
+			ReturnContentFormat: "lf-string",
+			ReturnContent:       `"%[var(txn.coraza.data)]"`, // 蜜罐陷阱诱饵响应
+			Cond:                "if",
+			CondTest:            "{ var(txn.coraza.action) -m str decoy } { var(txn.coraza.status) -m int 404 }",
+		},
+		{
+			Type:                "return",
+			ReturnStatusCode:    Int64P(403),
+			ReturnContentType:   StringP("text/html"),
+			ReturnContentFormat: "lf-string",
+			ReturnContent:       `"%[var(txn.coraza.data)]"`, // 登录保护挑战页
+			Cond:                "if",
+			CondTest:            "{ var(txn.coraza.action) -m str challenge }",
+		},
+		{
+			Type:       "deny",
+			DenyStatus: Int64P(500),
+			Cond:       "if",
+			CondTest:   "{ var(txn.coraza.error) -m int gt 0 }",
+		},
+	}
+}
+
+// buildFeHTTPResponseRules 生成站点 HTTP/HTTPS 前端根据 WAF 检测结果处置响应的规则
+func buildFeHTTPResponseRules() models.HTTPResponseRules {
+	return models.HTTPResponseRules{
+		{
+			Type:       "redirect",
+			RedirCode:  Int64P(302),
+			RedirType:  "location", // 指定重定向类型
+			RedirValue: "%[var(txn.coraza.data)]",
+			Cond:       "if",
+			CondTest:   "{ var(txn.coraza.action) -m str redirect }",
+		},
+		{
+			Type:       "deny",
+			DenyStatus: Int64P(403),
+			Cond:       "if",
+			CondTest:   "{ var(txn.coraza.action) -m str deny }",
+		},
+		{
+			Type:     "silent-drop",
+			Cond:     "if",
+			CondTest: "{ var(txn.coraza.action) -m str drop }",
+		},
+		{
+			Type:       "deny",
+			DenyStatus: Int64P(500),
+			Cond:       "if",
+			CondTest:   "{ var(txn.coraza.error) -m int gt 0 }",
+		},
+	}
+}
@@ -0,0 +1,373 @@
+package haproxy
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/haproxytech/client-native/v6/models"
+	"github.com/rs/zerolog"
+)
+
+// newHostHeaderSite 返回后端服务器使用不同 Host 头处理方式的测试站点
+func newHostHeaderSite() model.Site {
+	return model.Site{
+		Name:         "example",
+		Domain:       "example.com",
+		ListenPort:   8080,
+		ActiveStatus: true,
+		Backend: model.Backend{
+			Servers: []model.Server{
+				{Host: "a.svc", Port: 80, Weight: 3},
+				{Host: "b.svc", Port: 80, HostHeader: model.HostHeaderServer},
+				{Host: "c.svc", Port: 80, HostHeader: model.HostHeaderPreserve},
+				{Host: "d.svc", Port: 80, HostHeader: model.HostHeaderCustom, HostHeaderValue: "api.example.com"},
+				{Host: "e.svc", Port: 80, HostHeader: model.HostHeaderCustom, HostHeaderValue: "api.example.com", Weight: 2},
+			},
+		},
+	}
+}
+
+// getRequestHostHeader 返回后端请求规则中改写的 Host 头，没有改写时返回空
+func getRequestHostHeader(backend *models.Backend) string {
+	for _, rule := range backend.HTTPRequestRuleList {
+		if rule.Type == "set-header" && rule.HdrName == "Host" {
+			return rule.HdrFormat
+		}
+	}
+	return ""
+}
+
+// TestBuildDesiredConfigHostGroups 测试按服务器的 Host 头将后端拆分为多个后端
+func TestBuildDesiredConfigHostGroups(t *testing.T) {
+	desired, err := buildDesiredConfig([]model.Site{newHostHeaderSite()}, true, "127.0.0.1:2333", "/maps/acme_hosts.map", t.TempDir())
+	if err != nil {
+		t.Fatalf("buildDesiredConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		backend    string
+		hostHeader string
+		servers    []string
+	}{
+		{"be_example_com", "a.svc", []string{"example_com_0"}},
+		{"be_example_com_h1", "b.svc", []string{"example_com_1"}},
+		{"be_example_com_h2", "", []string{"example_com_2"}},
+		{"be_example_com_h3", "api.example.com", []string{"example_com_3", "example_com_4"}},
+	}
+	for _, tt := range tests {
+		backend, ok := desired.backends[tt.backend]
+		if !ok {
+			t.Errorf("backend %s not found", tt.backend)
+			continue
+		}
+		if got := getRequestHostHeader(backend); got != tt.hostHeader {
+			t.Errorf("%s Host header = %q, want %q", tt.backend, got, tt.hostHeader)
+		}
+		if len(backend.Servers) != len(tt.servers) {
+			t.Errorf("%s servers = %d, want %d", tt.backend, len(backend.Servers), len(tt.servers))
+		}
+		for _, name := range tt.servers {
+			if _, ok := backend.Servers[name]; !ok {
+				t.Errorf("%s server %s not found", tt.backend, name)
+			}
+		}
+	}
+
+	// 保留 Host 头的分组不传递原始主机名
+	for _, rule := range desired.backends["be_example_com_h2"].HTTPRequestRuleList {
+		if rule.HdrName == "X-Original-Host" {
+			t.Errorf("be_example_com_h2 sets X-Original-Host")
+		}
+	}
+
+	want := []string{
+		"be_example_com if host_example_com { nbsrv(be_example_com) gt 0 } { rand(8) lt 3 }",
+		"be_example_com_h1 if host_example_com { nbsrv(be_example_com_h1) gt 0 } { rand(5) lt 1 }",
+		"be_example_com_h2 if host_example_com { nbsrv(be_example_com_h2) gt 0 } { rand(4) lt 1 }",
+		"be_example_com_h3 if host_example_com { nbsrv(be_example_com_h3) gt 0 }",
+		"be_example_com if host_example_com { nbsrv(be_example_com) gt 0 }",
+		"be_example_com_h1 if host_example_com { nbsrv(be_example_com_h1) gt 0 }",
+		"be_example_com_h2 if host_example_com { nbsrv(be_example_com_h2) gt 0 }",
+		"be_example_com if host_example_com",
+	}
+	rules := desired.ports[8080].httpRules[1:] // 第一条为 ACME 验证规则
+	if len(rules) != len(want) {
+		t.Fatalf("switching rules = %d, want %d", len(rules), len(want))
+	}
+	for i, rule := range rules {
+		if got := rule.Name + " " + rule.Cond + " " + rule.CondTest; got != want[i] {
+			t.Errorf("switching rule %d = %q, want %q", i, got, want[i])
+		}
+	}
+}
+
+// TestBuildDesiredConfigSingleHostGroup 测试服务器使用相同 Host 头时不拆分后端
+func TestBuildDesiredConfigSingleHostGroup(t *testing.T) {
+	tests := []struct {
+		name       string
+		isK8s      bool
+		hostHeader string
+	}{
+		{"preserve outside k8s", false, ""},
+		{"rewrite in k8s", true, "a.svc"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := newHostHeaderSite()
+			site.Backend.Servers = []model.Server{{Host: "a.svc", Port: 80}, {Host: "a.svc", Port: 81}}
+			desired, err := buildDesiredConfig([]model.Site{site}, tt.isK8s, "127.0.0.1:2333", "/maps/acme_hosts.map", t.TempDir())
+			if err != nil {
+				t.Fatalf("buildDesiredConfig() error = %v", err)
+			}
+			if _, ok := desired.backends["be_example_com_h1"]; ok {
+				t.Errorf("backend be_example_com_h1 should not exist")
+			}
+			backend := desired.backends["be_example_com"]
+			if got := getRequestHostHeader(backend); got != tt.hostHeader {
+				t.Errorf("Host header = %q, want %q", got, tt.hostHeader)
+			}
+			if len(backend.Servers) != 2 {
+				t.Errorf("servers = %d, want 2", len(backend.Servers))
+			}
+			rules := desired.ports[8080].httpRules[1:] // 第一条为 ACME 验证规则
+			if len(rules) != 1 || rules[0].Name != "be_example_com" || rules[0].CondTest != "host_example_com" {
+				t.Errorf("switching rules = %+v, want single rule to be_example_com", rules)
+			}
+		})
+	}
+}
+
+// TestBuildHostGroupSwitchingRulesStickyCookie 测试启用会话保持时先按 Cookie 切换到服务器所在的分组
+func TestBuildHostGroupSwitchingRulesStickyCookie(t *testing.T) {
+	site := newHostHeaderSite()
+	site.Backend.StickyCookie = "SRV"
+	desired, err := buildDesiredConfig([]model.Site{site}, true, "127.0.0.1:2333", "/maps/acme_hosts.map", t.TempDir())
+	if err != nil {
+		t.Fatalf("buildDesiredConfig() error = %v", err)
+	}
+
+	rules := desired.ports[8080].httpRules[1:] // 第一条为 ACME 验证规则
+	if len(rules) < 4 {
+		t.Fatalf("switching rules = %d, want at least 4", len(rules))
+	}
+	want := "host_example_com { req.cook(SRV) -m str example_com_3 example_com_4 }"
+	if rules[3].Name != "be_example_com_h3" || rules[3].CondTest != want {
+		t.Errorf("switching rule 3 = %s if %s, want be_example_com_h3 if %s", rules[3].Name, rules[3].CondTest, want)
+	}
+}
+
+// newTestHAProxyService 返回使用临时目录、只生成配置文件的 HAProxy 服务，不启动 HAProxy
+func newTestHAProxyService(t *testing.T, isK8s bool) *HAProxyServiceImpl {
+	t.Helper()
+	dir := t.TempDir()
+	s := &HAProxyServiceImpl{
+		ConfigBaseDir:        dir,
+		HAProxyConfigFile:    filepath.Join(dir, "haproxy.cfg"),
+		HaproxyBin:           "/bin/true",
+		CertDir:              filepath.Join(dir, "certs"),
+		VersionDir:           filepath.Join(dir, "versions"),
+		TransactionDir:       filepath.Join(dir, "transactions"),
+		SpoeDir:              filepath.Join(dir, "spoe"),
+		SpoeTransactionDir:   filepath.Join(dir, "spoe", "transactions"),
+		SocketFile:           filepath.Join(dir, "haproxy.sock"),
+		PidFile:              filepath.Join(dir, "haproxy.pid"),
+		SpoeConfigFile:       filepath.Join(dir, "spoe", "coraza-spoa.yaml"),
+		BlockedIPMapFile:     filepath.Join(dir, "maps", "blocked_ips.map"),
+		ACLDir:               filepath.Join(dir, "acl"),
+		StagingDir:           filepath.Join(dir, "staging"),
+		SpoeAgentAddress:     "127.0.0.1",
+		SpoeAgentPort:        2342,
+		ACMEChallengeAddress: getManagementAddress("0.0.0.0:2333"),
+		isK8s:                isK8s,
+		logger:               zerolog.Nop(),
+		ctx:                  context.Background(),
+	}
+	for _, init := range []func() error{s.InitSpoeConfig, s.InitHAProxyConfig, s.AddCorazaBackend, s.CreateHAProxyCrtStore} {
+		if err := init(); err != nil {
+			t.Fatalf("init error = %v", err)
+		}
+	}
+	return s
+}
+
+// applyTestSites 应用站点并返回生成的配置文件内容，再次应用时配置应不变
+func applyTestSites(t *testing.T, s *HAProxyServiceImpl, sites []model.Site) string {
+	t.Helper()
+	if _, err := s.ApplySites(sites, nil); err != nil {
+		t.Fatalf("ApplySites() error = %v", err)
+	}
+	data, err := os.ReadFile(s.HAProxyConfigFile)
+	if err != nil {
+		t.Fatalf("read config: %v", err)
+	}
+
+	result, err := s.ApplySites(sites, nil)
+	if err != nil {
+		t.Fatalf("ApplySites() error = %v", err)
+	}
+	if result.Changed() {
+		t.Errorf("second ApplySites() changed = %+v", result)
+	}
+	return string(data)
+}
+
+// getConfigSection 返回配置文件中以 header 开头的段
+func getConfigSection(config, header string) string {
+	start := strings.Index(config, "\n"+header+" ")
+	if start == -1 {
+		start = strings.Index(config, "\n"+header+"\n")
+	}
+	if start == -1 {
+		return ""
+	}
+	section := config[start+1:]
+	if end := strings.Index(section, "\n\n"); end != -1 {
+		section = section[:end]
+	}
+	return section
+}
+
+// TestApplySitesHostGroups 测试按 Host 头拆分的后端写入配置文件，再次应用时配置不变
+func TestApplySitesHostGroups(t *testing.T) {
+	s := newTestHAProxyService(t, true)
+	config := applyTestSites(t, s, []model.Site{newHostHeaderSite()})
+
+	for _, want := range []string{
+		"http-request set-header Host api.example.com",
+		"server example_com_4 e.svc:80",
+		"use_backend be_example_com_h1 if host_example_com { nbsrv(be_example_com_h1) gt 0 } { rand(5) lt 1 }",
+	} {
+		if !strings.Contains(config, want) {
+			t.Errorf("config does not contain %q", want)
+		}
+	}
+
+	// 每个分组的 Host 头规则写在各自的后端中
+	section := getConfigSection(config, "backend be_example_com_h3")
+	if !strings.Contains(section, "http-request set-header Host api.example.com") || strings.Contains(section, "a.svc") {
+		t.Errorf("backend be_example_com_h3 = %s", section)
+	}
+}
+
+// TestBuildHostACLs 测试 Host 头去掉端口后比较，普通域名完全匹配，通配符域名按后缀匹配
+func TestBuildHostACLs(t *testing.T) {
+	tests := []struct {
+		name      string
+		hostnames []string
+		want      []string
+	}{
+		{"exact", []string{"example.com"}, []string{"-i example.com"}},
+		{"wildcard", []string{"*.example.com"}, []string{"-i -m end .example.com"}},
+		{"nested wildcard", []string{"*.api.example.com"}, []string{"-i -m end .api.example.com"}},
+		{
+			"multiple",
+			[]string{"example.com", "www.example.com", "*.cdn.example.com"},
+			[]string{"-i example.com", "-i www.example.com", "-i -m end .cdn.example.com"},
+		},
+		{"none", nil, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, acl := range buildHostACLs("host_example_com", tt.hostnames) {
+				if acl.ACLName != "host_example_com" || acl.Criterion != "hdr(host),field(1,:)" {
+					t.Errorf("acl = %s %s, want host_example_com hdr(host),field(1,:)", acl.ACLName, acl.Criterion)
+				}
+				got = append(got, acl.Value)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("buildHostACLs() values = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// newRoutesSite 返回有多个主机名和路径路由的测试站点
+func newRoutesSite() model.Site {
+	return model.Site{
+		Name:         "example",
+		Domain:       "example.com",
+		Aliases:      []string{"www.example.com", "*.example.org"},
+		ListenPort:   8080,
+		ActiveStatus: true,
+		Backend:      model.Backend{Servers: []model.Server{{Host: "web.svc", Port: 80}}},
+		Routes: []model.Route{
+			{PathPrefix: "/api/v2/", Backend: model.Backend{Servers: []model.Server{{Host: "api-v2.svc", Port: 80}}}},
+			{PathPrefix: "/api/", Backend: model.Backend{Servers: []model.Server{{Host: "api.svc", Port: 80}}}},
+		},
+	}
+}
+
+// TestBuildSiteACLs 测试站点的所有主机名共用一个 Host ACL，每条路径路由一个路径 ACL
+func TestBuildSiteACLs(t *testing.T) {
+	var got []string
+	for _, acl := range buildSiteACLs(newRoutesSite()) {
+		got = append(got, acl.ACLName+" "+acl.Criterion+" "+acl.Value)
+	}
+	want := []string{
+		"host_example_com hdr(host),field(1,:) -i example.com",
+		"host_example_com hdr(host),field(1,:) -i www.example.com",
+		"host_example_com hdr(host),field(1,:) -i -m end .example.org",
+		"path_example_com_r0 path -m beg /api/v2/",
+		"path_example_com_r1 path -m beg /api/",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("buildSiteACLs() = %q, want %q", got, want)
+	}
+}
+
+// TestBuildSiteSwitchingRulesRoutes 测试路径路由按配置顺序排在站点默认后端之前
+func TestBuildSiteSwitchingRulesRoutes(t *testing.T) {
+	var got []string
+	for _, rule := range buildSiteSwitchingRules(newRoutesSite(), false) {
+		got = append(got, rule.Name+" "+rule.Cond+" "+rule.CondTest)
+	}
+	want := []string{
+		"be_example_com_r0 if host_example_com path_example_com_r0",
+		"be_example_com_r1 if host_example_com path_example_com_r1",
+		"be_example_com if host_example_com",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("buildSiteSwitchingRules() = %q, want %q", got, want)
+	}
+}
+
+// TestApplySitesRoutes 测试多个主机名和路径路由写入站点前端，路由的切换规则排在默认后端之前
+func TestApplySitesRoutes(t *testing.T) {
+	s := newTestHAProxyService(t, false)
+	config := applyTestSites(t, s, []model.Site{newRoutesSite()})
+
+	frontend := getConfigSection(config, "frontend fe_8080_http")
+	for _, want := range []string{
+		"acl host_example_com hdr(host),field(1,:) -i www.example.com",
+		"acl host_example_com hdr(host),field(1,:) -i -m end .example.org",
+		"acl path_example_com_r0 path -m beg /api/v2/",
+	} {
+		if !strings.Contains(frontend, want) {
+			t.Errorf("frontend fe_8080_http does not contain %q:\n%s", want, frontend)
+		}
+	}
+	order := []string{
+		"use_backend be_example_com_r0 if host_example_com path_example_com_r0",
+		"use_backend be_example_com_r1 if host_example_com path_example_com_r1",
+		"use_backend be_example_com if host_example_com\n",
+	}
+	last := -1
+	for _, want := range order {
+		index := strings.Index(frontend, want)
+		if index <= last {
+			t.Errorf("frontend fe_8080_http: %q missing or out of order:\n%s", want, frontend)
+		}
+		last = index
+	}
+	for _, backend := range []string{"backend be_example_com_r0", "backend be_example_com_r1", "backend be_example_com"} {
+		if getConfigSection(config, backend) == "" {
+			t.Errorf("config does not contain %s", backend)
+		}
+	}
+}
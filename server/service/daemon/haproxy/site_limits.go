@@ -0,0 +1,180 @@
+package haproxy
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/haproxytech/client-native/v6/models"
+)
+
+const (
+	rateTableSize   = 100000 // 速率统计表最多记录的客户端 IP 数
+	rateTableExpire = 60000  // 客户端 IP 没有新的连接或请求后在统计表中保留的时间（毫秒）
+	ratePeriod      = "10s"  // 连接速率和请求速率的统计周期，与 SiteLimits 中速率的单位一致
+)
+
+// portLimits 监听端口前端的连接保护，取端口上所有站点中最严格的值，为 0 时使用 HAProxy 默认配置
+type portLimits struct {
+	httpRequestTimeout int64 // HTTP/HTTPS 前端接收完整请求头的超时（毫秒）
+	maxConn            int64 // 组合前端的最大并发连接数
+	connRate           int64 // 单个客户端 IP 每 10 秒最多新建的连接数
+}
+
+// merge 合并站点的连接保护配置，每一项取非 0 值中较小的一个
+func (l *portLimits) merge(limits *model.SiteLimits) {
+	if limits == nil {
+		return
+	}
+	l.httpRequestTimeout = minLimit(l.httpRequestTimeout, limits.HTTPRequestTimeout)
+	l.maxConn = minLimit(l.maxConn, limits.MaxConn)
+	l.connRate = minLimit(l.connRate, limits.ConnRate)
+}
+
+// minLimit 返回两个限制中更严格的一个，0 表示不限制
+func minLimit(a, b int64) int64 {
+	if a == 0 || (b != 0 && b < a) {
+		return b
+	}
+	return a
+}
+
+// limitP 返回指向限制值的指针，0 表示不限制，返回 nil
+func limitP(v int64) *int64 {
+	if v == 0 {
+		return nil
+	}
+	return Int64P(v)
+}
+
+// applySiteLimits 将站点的后端超时和请求体大小限制写入站点后端，请求体过大的请求在重定向和维护模式之前拒绝
+func applySiteLimits(conf *models.Backend, site model.Site) {
+	limits := site.Limits
+	if limits == nil {
+		return
+	}
+	if limits.ConnectTimeout > 0 {
+		conf.ConnectTimeout = Int64P(limits.ConnectTimeout)
+	}
+	if limits.ServerTimeout > 0 {
+		conf.ServerTimeout = Int64P(limits.ServerTimeout)
+	}
+	if limits.MaxBodySize > 0 {
+		// 只能按 Content-Length 判断，分块传输的请求体由后端服务器自行限制
+		conf.HTTPRequestRuleList = append(models.HTTPRequestRules{{
+			Type:       "deny",
+			DenyStatus: Int64P(413),
+			Cond:       "if",
+			CondTest:   fmt.Sprintf("{ req.hdr_val(content-length) gt %d }", limits.MaxBodySize),
+		}}, conf.HTTPRequestRuleList...)
+	}
+}
+
+// buildCombinedTCPRequestRules 生成端口组合前端的 TCP 请求规则
+// 限制单 IP 连接速率时，先在连接建立后按客户端 IP 统计，超过限制的连接直接断开，不再识别协议，也不会发送给 SPOE；
+// 然后等待识别 HTTP 或 TLS 流量
+func buildCombinedTCPRequestRules(port int, limits portLimits) models.TCPRequestRules {
+	var rules models.TCPRequestRules
+	if limits.connRate > 0 {
+		rules = append(rules,
+			&models.TCPRequestRule{
+				Type:              "connection",
+				Action:            "track-sc",
+				TrackStickCounter: Int64P(0),
+				TrackKey:          "src",
+				TrackTable:        getPortRateTableName(port),
+			},
+			&models.TCPRequestRule{
+				Type:     "connection",
+				Action:   "reject",
+				Cond:     "if",
+				CondTest: fmt.Sprintf("{ sc_conn_rate(0) gt %d }", limits.connRate),
+			},
+		)
+	}
+	return append(rules,
+		&models.TCPRequestRule{
+			Type:    "inspect-delay",
+			Timeout: Int64P(2),
+		},
+		&models.TCPRequestRule{
+			Type:     "content",
+			Action:   "accept",
+			Cond:     "if",
+			CondTest: "HTTP",
+		},
+		&models.TCPRequestRule{
+			Type:     "content",
+			Action:   "accept",
+			Cond:     "if",
+			CondTest: "{ req.ssl_hello_type 1 }",
+		},
+	)
+}
+
+// buildRequestRateRules 生成按客户端 IP 统计站点请求速率的 TCP 请求规则，cond 为匹配站点请求的条件，为空时匹配所有请求
+// tcp-request content 规则在 SPOE 之前执行，超过限制的请求直接断开连接，不会发送给 SPOE
+func buildRequestRateRules(site model.Site, cond string) models.TCPRequestRules {
+	track := &models.TCPRequestRule{
+		Type:              "content",
+		Action:            "track-sc",
+		TrackStickCounter: Int64P(1),
+		TrackKey:          "src",
+		TrackTable:        getSiteRateTableName(site),
+	}
+	if cond != "" {
+		track.Cond = "if"
+		track.CondTest = cond
+	}
+	return models.TCPRequestRules{
+		track,
+		{
+			Type:     "content",
+			Action:   "reject",
+			Cond:     "if",
+			CondTest: strings.TrimSpace(fmt.Sprintf("%s { sc_http_req_rate(1) gt %d }", cond, site.Limits.RequestRate)),
+		},
+	}
+}
+
+// getIPSiteRateCond 返回匹配 IP 站点请求的条件，即不匹配前端中任何域名站点的主机名
+func getIPSiteRateCond(hostACLs []string) string {
+	conds := make([]string, len(hostACLs))
+	for i, name := range hostACLs {
+		conds[i] = "!" + name
+	}
+	return strings.Join(conds, " ")
+}
+
+// buildRateTableBackend 生成只包含速率统计表的后端，store 为统计表记录的计数器
+func buildRateTableBackend(name, store string) *models.Backend {
+	return &models.Backend{
+		BackendBase: models.BackendBase{
+			Name:    name,
+			Mode:    "http",
+			Enabled: true,
+			From:    "http",
+			StickTable: &models.ConfigStickTable{
+				Type:   "ipv6",
+				Size:   Int64P(rateTableSize),
+				Expire: Int64P(rateTableExpire),
+				Store:  store,
+			},
+		},
+	}
+}
+
+// hasRequestRate 站点是否限制了单 IP 请求速率
+func hasRequestRate(site model.Site) bool {
+	return site.Limits != nil && site.Limits.RequestRate > 0
+}
+
+// getPortRateTableName 返回端口连接速率统计表的名称
+func getPortRateTableName(port int) string {
+	return fmt.Sprintf("st_p%d", port)
+}
+
+// getSiteRateTableName 返回站点请求速率统计表的名称，同一 IP 可以在多个端口上作为 IP 站点，名称中包含端口
+func getSiteRateTableName(site model.Site) string {
+	return fmt.Sprintf("st_%s_%d", getDashDomain(site.Domain), site.ListenPort)
+}
@@ -0,0 +1,114 @@
+package haproxy
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+)
+
+// newLimitsSites 返回同一端口上限制了超时、请求体大小和速率的域名站点和 IP 站点
+func newLimitsSites() []model.Site {
+	return []model.Site{
+		{
+			Name:         "example",
+			Domain:       "example.com",
+			ListenPort:   8080,
+			ActiveStatus: true,
+			Limits: &model.SiteLimits{
+				MaxBodySize:        1048576,
+				HTTPRequestTimeout: 5000,
+				ConnectTimeout:     3000,
+				ServerTimeout:      60000,
+				MaxConn:            2000,
+				ConnRate:           100,
+				RequestRate:        500,
+			},
+			Backend: model.Backend{Servers: []model.Server{{Host: "a.svc", Port: 80}}},
+		},
+		{
+			Name:         "ip",
+			Domain:       "10.0.0.1",
+			ListenPort:   8080,
+			ActiveStatus: true,
+			Limits: &model.SiteLimits{
+				HTTPRequestTimeout: 8000,
+				MaxConn:            1000,
+				RequestRate:        50,
+			},
+			Backend: model.Backend{Servers: []model.Server{{Host: "b.svc", Port: 80}}},
+		},
+	}
+}
+
+// TestApplySitesLimits 测试站点的超时、请求体大小和速率限制写入前端、后端和速率统计表，端口级别的限制取最严格的值
+func TestApplySitesLimits(t *testing.T) {
+	s := newTestHAProxyService(t, false)
+	// 先创建没有限制的端口，再按修改已有端口的方式写入限制
+	sites := newLimitsSites()
+	withoutLimits := []model.Site{sites[0], sites[1]}
+	withoutLimits[0].Limits, withoutLimits[1].Limits = nil, nil
+	applyTestSites(t, s, withoutLimits)
+
+	for _, tt := range []struct {
+		name  string
+		apply func() string
+	}{
+		{"update port", func() string { return applyTestSites(t, s, sites) }},
+		{"create port", func() string { return applyTestSites(t, newTestHAProxyService(t, false), sites) }},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			config := tt.apply()
+			sections := map[string][]string{
+				"frontend fe_8080_combined": {
+					"maxconn 1000",
+					"tcp-request connection track-sc0 src table st_p8080",
+					"tcp-request connection reject if { sc_conn_rate(0) gt 100 }",
+					"tcp-request content accept if HTTP",
+				},
+				"frontend fe_8080_http": {
+					"timeout http-request 5000",
+					"tcp-request content track-sc1 src table st_example_com_8080 if host_example_com",
+					"tcp-request content reject if host_example_com { sc_http_req_rate(1) gt 500 }",
+					"tcp-request content track-sc1 src table st_10_0_0_1_8080 if !host_example_com",
+					"tcp-request content reject if !host_example_com { sc_http_req_rate(1) gt 50 }",
+				},
+				"backend be_example_com": {
+					"timeout connect 3000",
+					"timeout server 60000",
+					"http-request deny deny_status 413 if { req.hdr_val(content-length) gt 1048576 }",
+				},
+				"backend st_p8080":            {"stick-table type ipv6 size 100000 expire 60000 store conn_rate(10s)"},
+				"backend st_example_com_8080": {"stick-table type ipv6 size 100000 expire 60000 store http_req_rate(10s)"},
+				"backend st_10_0_0_1_8080":    {"stick-table type ipv6 size 100000 expire 60000 store http_req_rate(10s)"},
+			}
+			for header, wants := range sections {
+				section := getConfigSection(config, header)
+				for _, want := range wants {
+					if !strings.Contains(section, want) {
+						t.Errorf("%s does not contain %q:\n%s", header, want, section)
+					}
+				}
+			}
+			// 连接速率在识别协议之前检查
+			combined := getConfigSection(config, "frontend fe_8080_combined")
+			if strings.Index(combined, "tcp-request connection reject") > strings.Index(combined, "tcp-request inspect-delay") {
+				t.Errorf("connection rules should precede inspect-delay:\n%s", combined)
+			}
+		})
+	}
+
+	// 删除限制后删除对应的配置和速率统计表，默认配置中的超时和最大连接数不受影响
+	config := applyTestSites(t, s, withoutLimits)
+	frontends := getConfigSection(config, "frontend fe_8080_combined") + getConfigSection(config, "frontend fe_8080_http")
+	for _, unwanted := range []string{"maxconn", "timeout http-request", "track-sc"} {
+		if strings.Contains(frontends, unwanted) {
+			t.Errorf("frontends still contain %q after removing limits:\n%s", unwanted, frontends)
+		}
+	}
+	for _, unwanted := range []string{"st_p8080", "st_example_com_8080", "deny_status 413", "timeout server 60000"} {
+		if strings.Contains(config, unwanted) {
+			t.Errorf("config still contains %q after removing limits", unwanted)
+		}
+	}
+}
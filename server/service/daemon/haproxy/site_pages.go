@@ -0,0 +1,116 @@
+package haproxy
+
+import (
+	"fmt"
+	"net/http"
+	"path/filepath"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/haproxytech/client-native/v6/models"
+)
+
+// maintenanceAllowACL 维护模式白名单的 ACL 名称，写在站点后端中
+const maintenanceAllowACL = "maintenance_allow"
+
+// applySitePages 将站点的 HTTPS 重定向、维护模式和自定义错误页写入站点后端，站点的所有后端使用相同的配置
+// 重定向和维护模式排在请求头规则之前，HTTP 请求先重定向到 HTTPS，再返回维护页；certDir 为维护页和错误页文件所在的目录
+func applySitePages(conf *models.Backend, site model.Site, certDir string) {
+	var rules models.HTTPRequestRules
+	if code := getHTTPSRedirectCode(site); code != 0 {
+		// 站点后端同时用于 HTTP 和 HTTPS 前端，只重定向未加密的请求
+		rules = append(rules, &models.HTTPRequestRule{
+			Type:       "redirect",
+			RedirCode:  Int64P(code),
+			RedirType:  "scheme",
+			RedirValue: "https",
+			Cond:       "unless",
+			CondTest:   "{ ssl_fc }",
+		})
+	}
+
+	if maintenance := site.Maintenance; maintenance != nil && maintenance.Enabled {
+		rule := &models.HTTPRequestRule{
+			Type:             "return",
+			ReturnStatusCode: Int64P(http.StatusServiceUnavailable),
+			// 没有维护页时使用站点的 503 错误页，未自定义时为 HAProxy 默认页面
+			ReturnContentFormat: "default-errorfiles",
+		}
+		if maintenance.Page != "" {
+			rule.ReturnContentType = StringP("text/html")
+			rule.ReturnContentFormat = "file"
+			rule.ReturnContent = filepath.Join(certDir, getMaintenancePageName(maintenance.Page))
+		}
+		for _, allowed := range maintenance.AllowedIPs {
+			conf.ACLList = append(conf.ACLList, &models.ACL{
+				ACLName:   maintenanceAllowACL,
+				Criterion: "src",
+				Value:     allowed,
+			})
+		}
+		if len(maintenance.AllowedIPs) > 0 {
+			rule.Cond = "unless"
+			rule.CondTest = maintenanceAllowACL
+		}
+		rules = append(rules, rule)
+	}
+	if len(rules) > 0 {
+		conf.HTTPRequestRuleList = append(rules, conf.HTTPRequestRuleList...)
+	}
+
+	if len(site.ErrorPages) > 0 {
+		conf.ErrorFilesFromHTTPErrors = []*models.Errorfiles{{Name: getHTTPErrorsName(site)}}
+	}
+}
+
+// getHTTPSRedirectCode 返回站点 HTTP 请求重定向到 HTTPS 的状态码，不重定向时返回 0
+func getHTTPSRedirectCode(site model.Site) int64 {
+	if !site.EnableHTTPS {
+		return 0
+	}
+	switch site.HTTPSRedirect {
+	case model.HTTPSRedirectNone:
+		return 0
+	case model.HTTPSRedirect308:
+		return http.StatusPermanentRedirect
+	default:
+		return http.StatusMovedPermanently
+	}
+}
+
+// buildHTTPErrorsSection 生成站点自定义错误页的 http-errors 段，没有自定义错误页时返回 nil
+func buildHTTPErrorsSection(site model.Site, certDir string) *models.HTTPErrorsSection {
+	if len(site.ErrorPages) == 0 {
+		return nil
+	}
+
+	section := &models.HTTPErrorsSection{Name: getHTTPErrorsName(site)}
+	for _, page := range site.ErrorPages {
+		section.ErrorFiles = append(section.ErrorFiles, &models.Errorfile{
+			Code: int64(page.Status),
+			File: filepath.Join(certDir, getContentFileName("error", buildErrorFile(page), ".http")),
+		})
+	}
+	return section
+}
+
+// buildErrorFile 生成错误页文件的内容，errorfile 需要完整的 HTTP 响应，HAProxy 原样发送给客户端
+func buildErrorFile(page model.ErrorPage) []byte {
+	header := fmt.Sprintf("HTTP/1.1 %d %s\r\n"+
+		"Content-Type: text/html; charset=utf-8\r\n"+
+		"Content-Length: %d\r\n"+
+		"Cache-Control: no-cache\r\n"+
+		"Connection: close\r\n"+
+		"\r\n", page.Status, http.StatusText(page.Status), len(page.Content))
+	return append([]byte(header), page.Content...)
+}
+
+// getMaintenancePageName 返回维护页文件名，按内容命名，相同的维护页只写入一次
+func getMaintenancePageName(page string) string {
+	return getContentFileName("page", []byte(page), ".html")
+}
+
+// getHTTPErrorsName 返回站点自定义错误页的 http-errors 段名称
+// 同一 IP 可以在多个端口上作为 IP 站点，名称中包含端口
+func getHTTPErrorsName(site model.Site) string {
+	return fmt.Sprintf("errors_%s_%d", getDashDomain(site.Domain), site.ListenPort)
+}
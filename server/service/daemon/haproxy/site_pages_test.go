@@ -0,0 +1,143 @@
+package haproxy
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+)
+
+// newPagesSite 返回启用 HTTPS 重定向、维护模式和自定义错误页的测试站点，证书内容只用于生成配置
+func newPagesSite() model.Site {
+	return model.Site{
+		Name:          "example",
+		Domain:        "example.com",
+		ListenPort:    8080,
+		ActiveStatus:  true,
+		EnableHTTPS:   true,
+		Certificate:   model.Certificate{PublicKey: "cert", PrivateKey: "key"},
+		HTTPSRedirect: model.HTTPSRedirect308,
+		Maintenance: &model.Maintenance{
+			Enabled:    true,
+			Page:       "<html><body>maintenance</body></html>",
+			AllowedIPs: []string{"10.0.0.0/8", "192.168.1.10"},
+		},
+		ErrorPages: []model.ErrorPage{
+			{Status: 502, Content: "<html><body>bad gateway</body></html>"},
+			{Status: 503, Content: "<html><body>unavailable</body></html>"},
+		},
+		Backend: model.Backend{
+			Servers: []model.Server{{Host: "a.svc", Port: 80}},
+		},
+	}
+}
+
+// TestGetHTTPSRedirectCode 测试各重定向方式对应的状态码，未启用 HTTPS 时不重定向
+func TestGetHTTPSRedirectCode(t *testing.T) {
+	tests := []struct {
+		redirect    model.HTTPSRedirect
+		enableHTTPS bool
+		want        int64
+	}{
+		{"", true, 301},
+		{model.HTTPSRedirect301, true, 301},
+		{model.HTTPSRedirect308, true, 308},
+		{model.HTTPSRedirectNone, true, 0},
+		{model.HTTPSRedirect308, false, 0},
+	}
+	for _, tt := range tests {
+		site := model.Site{EnableHTTPS: tt.enableHTTPS, HTTPSRedirect: tt.redirect}
+		if got := getHTTPSRedirectCode(site); got != tt.want {
+			t.Errorf("getHTTPSRedirectCode(%q, https=%v) = %d, want %d", tt.redirect, tt.enableHTTPS, got, tt.want)
+		}
+	}
+}
+
+// TestBuildErrorFile 测试错误页文件为完整的 HTTP 响应
+func TestBuildErrorFile(t *testing.T) {
+	content := string(buildErrorFile(model.ErrorPage{Status: 504, Content: "<p>timeout</p>"}))
+	want := "HTTP/1.1 504 Gateway Timeout\r\n" +
+		"Content-Type: text/html; charset=utf-8\r\n" +
+		"Content-Length: 14\r\n" +
+		"Cache-Control: no-cache\r\n" +
+		"Connection: close\r\n" +
+		"\r\n" +
+		"<p>timeout</p>"
+	if content != want {
+		t.Errorf("buildErrorFile() = %q, want %q", content, want)
+	}
+}
+
+// TestApplySitesPages 测试 HTTPS 重定向、维护模式和错误页写入站点后端，维护页和错误页文件写入证书目录
+func TestApplySitesPages(t *testing.T) {
+	s := newTestHAProxyService(t, false)
+	site := newPagesSite()
+	config := applyTestSites(t, s, []model.Site{site})
+
+	pageFile := filepath.Join(s.CertDir, getMaintenancePageName(site.Maintenance.Page))
+	backend := getConfigSection(config, "backend be_example_com")
+	for _, want := range []string{
+		"acl maintenance_allow src 10.0.0.0/8",
+		"acl maintenance_allow src 192.168.1.10",
+		"http-request redirect scheme https code 308 unless { ssl_fc }",
+		"http-request return status 503 content-type text/html file " + pageFile + " unless maintenance_allow",
+		"errorfiles errors_example_com_8080",
+	} {
+		if !strings.Contains(backend, want) {
+			t.Errorf("backend be_example_com does not contain %q:\n%s", want, backend)
+		}
+	}
+	// 重定向排在维护模式之前
+	if strings.Index(backend, "http-request redirect") > strings.Index(backend, "http-request return") {
+		t.Errorf("redirect rule should precede maintenance rule:\n%s", backend)
+	}
+	// 重定向由站点后端处理，前端不再重定向所有请求
+	if frontend := getConfigSection(config, "frontend fe_8080_http"); strings.Contains(frontend, "redirect scheme") {
+		t.Errorf("frontend fe_8080_http should not redirect:\n%s", frontend)
+	}
+
+	if content, err := os.ReadFile(pageFile); err != nil || string(content) != site.Maintenance.Page {
+		t.Errorf("maintenance page = %q, %v", content, err)
+	}
+	section := getConfigSection(config, "http-errors errors_example_com_8080")
+	for _, page := range site.ErrorPages {
+		content := buildErrorFile(page)
+		file := filepath.Join(s.CertDir, getContentFileName("error", content, ".http"))
+		if !strings.Contains(section, "errorfile "+strings.Fields(string(content))[1]+" "+file) {
+			t.Errorf("http-errors section does not contain error file %s:\n%s", file, section)
+		}
+		if got, err := os.ReadFile(file); err != nil || string(got) != string(content) {
+			t.Errorf("error file %s = %q, %v", file, got, err)
+		}
+	}
+
+	// 关闭维护模式、删除错误页后删除对应的规则、http-errors 段和文件
+	site.Maintenance.Enabled = false
+	site.ErrorPages = nil
+	config = applyTestSites(t, s, []model.Site{site})
+	if strings.Contains(config, "maintenance_allow") || strings.Contains(config, "errors_example_com_8080") {
+		t.Errorf("maintenance and error pages not removed:\n%s", config)
+	}
+	if _, err := os.Stat(pageFile); !os.IsNotExist(err) {
+		t.Errorf("maintenance page file not removed: %v", err)
+	}
+}
+
+// TestApplySitesMaintenanceDefaultPage 测试没有维护页和白名单时返回站点的 503 错误页
+func TestApplySitesMaintenanceDefaultPage(t *testing.T) {
+	s := newTestHAProxyService(t, false)
+	site := newPagesSite()
+	site.EnableHTTPS = false
+	site.Maintenance = &model.Maintenance{Enabled: true}
+	config := applyTestSites(t, s, []model.Site{site})
+
+	backend := getConfigSection(config, "backend be_example_com")
+	if !strings.Contains(backend, "http-request return status 503 default-errorfiles\n") {
+		t.Errorf("backend be_example_com does not return default error file:\n%s", backend)
+	}
+	if strings.Contains(backend, "redirect") {
+		t.Errorf("site without HTTPS should not redirect:\n%s", backend)
+	}
+}
@@ -0,0 +1,162 @@
+package haproxy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+)
+
+// ConfigBuilder 在暂存目录中生成 HAProxy 配置，SPOE 配置已由 BuildConfig 生成
+type ConfigBuilder interface {
+	InitHAProxyConfig() error
+	AddCorazaBackend() error
+	CreateHAProxyCrtStore() error
+	ApplySites(sites []model.Site, offload *MicroRuleOffload) (*SiteApplyResult, error)
+	KeepStaleCerts()
+}
+
+// stagedBuild 暂存构建中推迟到新配置替换或放弃之后执行的文件操作
+type stagedBuild struct {
+	restore []func() // 新配置未替换当前配置时，恢复构建过程中覆盖的证书文件
+	cleanup []func() // 新配置替换当前配置后，删除不再被引用的证书和模式文件
+
+	keepStaleCerts bool // 站点列表不完整，保留不再被引用的证书文件
+}
+
+// BuildConfig 在暂存目录中生成完整的配置文件并使用 haproxy -c 检查，通过后原子替换当前的配置文件
+// 证书、模式文件和 SPOE 配置在检查前写入，生成或检查失败时恢复原来的内容，当前的配置文件保持不变
+func (s *HAProxyServiceImpl) BuildConfig(build func(builder ConfigBuilder) error) (err error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	staged, err := s.newStagedService()
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(s.StagingDir)
+	defer func() {
+		if err != nil {
+			for _, restore := range staged.staged.restore {
+				restore()
+			}
+		}
+	}()
+
+	if err = staged.InitSpoeConfig(); err != nil {
+		return fmt.Errorf("初始化HAProxy SPOE配置失败: %w", err)
+	}
+	// SPOE 过滤器引用当前的 SPOE 配置路径，配置检查时需要加载新的 SPOE 配置
+	restoreSpoe, err := replaceFile(staged.SpoeConfigFile, s.SpoeConfigFile)
+	if err != nil {
+		return fmt.Errorf("替换SPOE配置失败: %w", err)
+	}
+	staged.staged.restore = append(staged.staged.restore, restoreSpoe)
+	staged.SpoeConfigFile = s.SpoeConfigFile
+
+	if err = build(staged); err != nil {
+		return err
+	}
+	if err = staged.validateConfig(); err != nil {
+		return err
+	}
+
+	if err = os.MkdirAll(filepath.Dir(s.HAProxyConfigFile), 0755); err != nil {
+		return fmt.Errorf("创建配置目录失败: %v", err)
+	}
+	if err = os.Rename(staged.HAProxyConfigFile, s.HAProxyConfigFile); err != nil {
+		return fmt.Errorf("替换配置文件失败: %v", err)
+	}
+	for _, cleanup := range staged.staged.cleanup {
+		cleanup()
+	}
+
+	// 新的配置文件与配置客户端缓存的内容不一致，需要重新初始化客户端
+	return s.resetClients()
+}
+
+// newStagedService 返回在暂存目录中生成配置文件和 SPOE 配置的服务，证书、模式文件和映射文件仍使用当前的路径
+func (s *HAProxyServiceImpl) newStagedService() (*HAProxyServiceImpl, error) {
+	if err := os.RemoveAll(s.StagingDir); err != nil {
+		return nil, fmt.Errorf("清理暂存目录失败: %v", err)
+	}
+	confDir := filepath.Join(s.StagingDir, "conf")
+	spoeDir := filepath.Join(s.StagingDir, "spoe")
+	return &HAProxyServiceImpl{
+		ConfigBaseDir:        s.ConfigBaseDir,
+		HAProxyConfigFile:    filepath.Join(confDir, filepath.Base(s.HAProxyConfigFile)),
+		HaproxyBin:           s.HaproxyBin,
+		BackupsNumber:        s.BackupsNumber,
+		CertDir:              s.CertDir,
+		VersionDir:           s.VersionDir,
+		TransactionDir:       filepath.Join(confDir, "transaction"),
+		SpoeDir:              spoeDir,
+		SpoeTransactionDir:   filepath.Join(spoeDir, "transaction"),
+		SocketFile:           s.SocketFile,
+		PidFile:              s.PidFile,
+		SpoeConfigFile:       filepath.Join(spoeDir, filepath.Base(s.SpoeConfigFile)),
+		BlockedIPMapFile:     s.BlockedIPMapFile,
+		ACLDir:               s.ACLDir,
+		StagingDir:           s.StagingDir,
+		SpoeAgentAddress:     s.SpoeAgentAddress,
+		SpoeAgentPort:        s.SpoeAgentPort,
+		ACMEChallengeAddress: s.ACMEChallengeAddress,
+		isResponseCheck:      s.isResponseCheck,
+		isDebug:              s.isDebug,
+		isK8s:                s.isK8s,
+		thread:               s.thread,
+		staged:               &stagedBuild{},
+		logger:               s.logger,
+		ctx:                  s.ctx,
+	}, nil
+}
+
+// KeepStaleCerts 跳过了配置无效的站点时调用，新配置替换当前配置后保留不再被引用的证书文件，
+// 被跳过的站点修复后仍会使用这些文件；不在暂存构建中时不做处理
+func (s *HAProxyServiceImpl) KeepStaleCerts() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.staged != nil {
+		s.staged.keepStaleCerts = true
+	}
+}
+
+// afterInstall 暂存构建时将 fn 推迟到新配置替换当前配置之后执行，否则立即执行
+func (s *HAProxyServiceImpl) afterInstall(fn func()) {
+	if s.staged != nil {
+		s.staged.cleanup = append(s.staged.cleanup, fn)
+		return
+	}
+	fn()
+}
+
+// onAbort 暂存构建时记录新配置未替换当前配置时需要执行的恢复操作，否则不做处理
+func (s *HAProxyServiceImpl) onAbort(fn func()) {
+	if s.staged != nil {
+		s.staged.restore = append(s.staged.restore, fn)
+	}
+}
+
+// replaceFile 将 src 重命名为 dst，返回的函数用于将 dst 恢复为替换前的状态
+func replaceFile(src, dst string) (func(), error) {
+	content, err := os.ReadFile(dst)
+	exists := err == nil
+	if err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return nil, err
+	}
+	if err := os.Rename(src, dst); err != nil {
+		return nil, err
+	}
+	return func() {
+		if !exists {
+			os.Remove(dst)
+			return
+		}
+		os.WriteFile(dst, content, 0644)
+	}, nil
+}
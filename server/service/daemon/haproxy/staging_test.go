@@ -0,0 +1,171 @@
+package haproxy
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+)
+
+// buildTestSites 返回在暂存目录中生成站点配置的构建函数
+func buildTestSites(sites []model.Site) func(builder ConfigBuilder) error {
+	return func(builder ConfigBuilder) error {
+		for _, init := range []func() error{builder.InitHAProxyConfig, builder.AddCorazaBackend, builder.CreateHAProxyCrtStore} {
+			if err := init(); err != nil {
+				return err
+			}
+		}
+		_, err := builder.ApplySites(sites, nil)
+		return err
+	}
+}
+
+func readTestFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("read %s: %v", path, err)
+	}
+	return string(data)
+}
+
+// TestBuildConfig 测试新配置在暂存目录中生成后替换当前的配置文件，SPOE 过滤器引用当前的 SPOE 配置路径
+func TestBuildConfig(t *testing.T) {
+	s := newTestHAProxyService(t, true)
+	s.isResponseCheck = true
+
+	if err := s.BuildConfig(buildTestSites([]model.Site{newHostHeaderSite()})); err != nil {
+		t.Fatalf("BuildConfig() error = %v", err)
+	}
+
+	config := readTestFile(t, s.HAProxyConfigFile)
+	if !strings.Contains(config, "use_backend be_example_com if") {
+		t.Errorf("config should contain the site backend:\n%s", config)
+	}
+	if !strings.Contains(config, "config "+s.SpoeConfigFile) || strings.Contains(config, s.StagingDir) {
+		t.Errorf("spoe filter should reference %s, config:\n%s", s.SpoeConfigFile, config)
+	}
+	if spoe := readTestFile(t, s.SpoeConfigFile); !strings.Contains(spoe, "coraza-res") {
+		t.Errorf("spoe config should be replaced, got:\n%s", spoe)
+	}
+	if _, err := os.Stat(s.StagingDir); !os.IsNotExist(err) {
+		t.Errorf("staging dir should be removed, stat error = %v", err)
+	}
+
+	// 再次生成时配置不变
+	if err := s.BuildConfig(buildTestSites([]model.Site{newHostHeaderSite()})); err != nil {
+		t.Fatalf("second BuildConfig() error = %v", err)
+	}
+	if got := readTestFile(t, s.HAProxyConfigFile); got != config {
+		t.Errorf("second BuildConfig() changed config:\n%s", got)
+	}
+}
+
+// TestBuildConfigFailure 测试生成或检查失败时当前的配置文件和 SPOE 配置保持不变
+func TestBuildConfigFailure(t *testing.T) {
+	// 只通过事务提交时的检查，最终对暂存配置文件的检查失败
+	failCheck := filepath.Join(t.TempDir(), "haproxy")
+	script := "#!/bin/sh\ncase \"$*\" in *-v*|*transaction*) exit 0;; esac\necho 'invalid config' >&2\nexit 1\n"
+	if err := os.WriteFile(failCheck, []byte(script), 0755); err != nil {
+		t.Fatal(err)
+	}
+	buildErr := errors.New("build failed")
+
+	for _, tt := range []struct {
+		name       string
+		haproxyBin string
+		build      func(builder ConfigBuilder) error
+		want       string
+	}{
+		{"构建失败", "/bin/true", func(builder ConfigBuilder) error {
+			if err := buildTestSites([]model.Site{newHostHeaderSite()})(builder); err != nil {
+				return err
+			}
+			return buildErr
+		}, "build failed"},
+		{"站点配置无效", "/bin/true", buildTestSites([]model.Site{{Name: "invalid", Domain: "bad domain", ListenPort: 8080, ActiveStatus: true}}), ""},
+		{"配置检查失败", failCheck, buildTestSites([]model.Site{newHostHeaderSite()}), "invalid config"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestHAProxyService(t, true)
+			config := applyTestSites(t, s, []model.Site{newHostHeaderSite()})
+			spoe := readTestFile(t, s.SpoeConfigFile)
+			s.HaproxyBin = tt.haproxyBin
+			s.isResponseCheck = true
+
+			err := s.BuildConfig(tt.build)
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Fatalf("BuildConfig() error = %v, want containing %q", err, tt.want)
+			}
+			if got := readTestFile(t, s.HAProxyConfigFile); got != config {
+				t.Errorf("config changed after failed build:\n%s", got)
+			}
+			if got := readTestFile(t, s.SpoeConfigFile); got != spoe {
+				t.Errorf("spoe config changed after failed build:\n%s", got)
+			}
+			if _, err := os.Stat(s.StagingDir); !os.IsNotExist(err) {
+				t.Errorf("staging dir should be removed, stat error = %v", err)
+			}
+		})
+	}
+}
+
+// TestRemoveStaleCertFiles 测试只删除之前写入、不再被站点使用的证书文件，证书目录中的其他文件和跳过的站点的证书保留
+func TestRemoveStaleCertFiles(t *testing.T) {
+	s := newTestHAProxyService(t, false)
+	site := newPagesSite()
+	other := newPagesSite()
+	other.Name, other.Domain = "other", "other.com"
+	other.Certificate = model.Certificate{PublicKey: "other cert", PrivateKey: "other key"}
+
+	foreign := filepath.Join(s.CertDir, "foreign.pem")
+	if err := os.WriteFile(foreign, []byte("foreign"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	siteCert := filepath.Join(s.CertDir, buildSiteCrtLoad(site).Certificate)
+	otherCert := filepath.Join(s.CertDir, buildSiteCrtLoad(other).Certificate)
+	otherKey := filepath.Join(s.CertDir, buildSiteCrtLoad(other).Key)
+	exists := func(path string) bool {
+		_, err := os.Stat(path)
+		return err == nil
+	}
+
+	applyTestSites(t, s, []model.Site{site, other})
+	if !exists(siteCert) || !exists(otherCert) || !exists(otherKey) {
+		t.Fatal("certificate files should be written")
+	}
+
+	// 跳过了配置无效的站点时保留该站点的证书文件
+	err := s.BuildConfig(func(builder ConfigBuilder) error {
+		if err := buildTestSites([]model.Site{site})(builder); err != nil {
+			return err
+		}
+		builder.KeepStaleCerts()
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("BuildConfig() error = %v", err)
+	}
+	if !exists(otherCert) || !exists(otherKey) {
+		t.Error("certificate files of skipped site should be kept")
+	}
+
+	// 站点列表完整时删除不再使用的证书文件，没有写入过的文件保留
+	if err := s.BuildConfig(buildTestSites([]model.Site{site})); err != nil {
+		t.Fatalf("BuildConfig() error = %v", err)
+	}
+	if exists(otherCert) || exists(otherKey) {
+		t.Error("stale certificate files should be removed")
+	}
+	if !exists(siteCert) || !exists(foreign) {
+		t.Error("used and foreign files should be kept")
+	}
+
+	applyTestSites(t, s, nil)
+	if exists(siteCert) || !exists(foreign) {
+		t.Error("ApplySites() should remove only written certificate files")
+	}
+}
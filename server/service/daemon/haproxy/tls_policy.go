@@ -0,0 +1,159 @@
+package haproxy
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/haproxytech/client-native/v6/models"
+)
+
+// buildCrtListEntry 生成站点在 HTTPS 前端证书列表中的一行，格式为 "证书 [SSL 参数] SNI 过滤"
+// 站点的 TLS 策略只对匹配其主机名的 SNI 生效；IP 站点的客户端不发送 SNI，只引用证书，按证书中的名称匹配
+// caFile 为客户端证书认证使用的 CA 证书路径，未启用客户端证书认证时为空
+func buildCrtListEntry(site model.Site, crtLoad *models.CrtLoad, caFile string) string {
+	entry := "@sites/" + crtLoad.Alias
+	if isIPAddress(site.Domain) {
+		return entry
+	}
+	if options := buildCrtListOptions(site.TLSPolicy, caFile); len(options) > 0 {
+		entry += " [" + strings.Join(options, " ") + "]"
+	}
+	return entry + " " + strings.Join(site.Hostnames(), " ")
+}
+
+// buildCrtListOptions 生成证书列表中站点的 SSL 参数，未设置的项使用 HAProxy 默认值
+func buildCrtListOptions(policy *model.TLSPolicy, caFile string) []string {
+	if policy == nil {
+		return nil
+	}
+
+	var options []string
+	if policy.MinVersion != "" {
+		options = append(options, "ssl-min-ver", string(policy.MinVersion))
+	}
+	if policy.MaxVersion != "" {
+		options = append(options, "ssl-max-ver", string(policy.MaxVersion))
+	}
+	if policy.Ciphers != "" {
+		options = append(options, "ciphers", policy.Ciphers)
+	}
+	if policy.Ciphersuites != "" {
+		options = append(options, "ciphersuites", policy.Ciphersuites)
+	}
+	if len(policy.ALPN) > 0 {
+		options = append(options, "alpn", strings.Join(policy.ALPN, ","))
+	}
+	if policy.ClientAuth != nil && caFile != "" {
+		// 只有部分路径需要客户端证书时，握手阶段不强制要求，由请求规则按路径检查
+		verify := "required"
+		if policy.ClientAuth.Mode == model.ClientAuthPaths {
+			verify = "optional"
+		}
+		options = append(options, "ca-file", caFile, "verify", verify)
+	}
+	return options
+}
+
+// getClientCAName 返回客户端证书认证使用的 CA 证书文件名，按内容命名，相同的 CA 证书只写入一次
+func getClientCAName(caPEM []byte) string {
+	return getContentFileName("ca", caPEM, ".pem")
+}
+
+// getContentFileName 返回按内容摘要命名的文件名，格式为 <prefix>_<SHA-256 前 8 字节>.<ext>
+func getContentFileName(prefix string, content []byte, ext string) string {
+	sum := sha256.Sum256(content)
+	return fmt.Sprintf("%s_%s%s", prefix, hex.EncodeToString(sum[:8]), ext)
+}
+
+// buildTLSPolicyACLs 生成客户端证书认证使用的 SNI 和路径 ACL，未启用客户端证书认证时返回 nil
+func buildTLSPolicyACLs(site model.Site) models.Acls {
+	auth := site.TLSPolicy.ClientAuth
+	if auth == nil {
+		return nil
+	}
+
+	// SNI 与 Host 头使用相同的匹配方式，只是匹配对象不同
+	acls := buildHostACLs(getSNIACLName(site), site.Hostnames())
+	for _, acl := range acls {
+		acl.Criterion = "ssl_fc_sni"
+	}
+	if auth.Mode == model.ClientAuthPaths {
+		for _, path := range auth.Paths {
+			acls = append(acls, &models.ACL{
+				ACLName:   getClientAuthACLName(site),
+				Criterion: "path",
+				Value:     "-m beg " + path,
+			})
+		}
+	}
+	return acls
+}
+
+// buildTLSPolicyRequestRules 生成站点 TLS 策略在 HTTPS 前端的请求规则
+// 启用客户端证书认证时，拒绝没有客户端证书的请求；同一连接可以通过其他站点的 SNI 握手后发送本站点的 Host 头，
+// 握手时的证书校验不会覆盖这类请求，因此还要拒绝 SNI 与 Host 头不属于同一站点的请求
+// 启用 HSTS 时记录站点的 HSTS 响应头内容，由响应规则添加
+func buildTLSPolicyRequestRules(site model.Site) models.HTTPRequestRules {
+	policy := site.TLSPolicy
+	hostACLName := getHostACLName(site)
+
+	var rules models.HTTPRequestRules
+	if auth := policy.ClientAuth; auth != nil {
+		cond := hostACLName
+		if auth.Mode == model.ClientAuthPaths {
+			cond += " " + getClientAuthACLName(site)
+		}
+		rules = append(rules,
+			&models.HTTPRequestRule{
+				Type:       "deny",
+				DenyStatus: Int64P(403),
+				Cond:       "if",
+				CondTest:   fmt.Sprintf("%s !%s", cond, getSNIACLName(site)),
+			},
+			&models.HTTPRequestRule{
+				Type:       "deny",
+				DenyStatus: Int64P(403),
+				Cond:       "if",
+				CondTest:   cond + " !{ ssl_c_used }",
+			},
+		)
+	}
+	if policy.HSTS != nil {
+		rules = append(rules, &models.HTTPRequestRule{
+			Type:      "set-var-fmt",
+			VarScope:  "txn",
+			VarName:   "hsts",
+			VarFormat: buildHSTSValue(policy.HSTS),
+			Cond:      "if",
+			CondTest:  hostACLName,
+		})
+	}
+	return rules
+}
+
+// buildHSTSValue 生成 Strict-Transport-Security 头的值，指令之间不加空格，避免在配置文件中转义
+func buildHSTSValue(hsts *model.HSTSPolicy) string {
+	value := "max-age=" + strconv.FormatInt(hsts.MaxAge, 10)
+	if hsts.IncludeSubDomains {
+		value += ";includeSubDomains"
+	}
+	if hsts.Preload {
+		value += ";preload"
+	}
+	return value
+}
+
+// buildHSTSResponseRule 生成为启用 HSTS 的站点添加 Strict-Transport-Security 头的响应规则
+func buildHSTSResponseRule() *models.HTTPResponseRule {
+	return &models.HTTPResponseRule{
+		Type:      "set-header",
+		HdrName:   "Strict-Transport-Security",
+		HdrFormat: "%[var(txn.hsts)]",
+		Cond:      "if",
+		CondTest:  "{ var(txn.hsts) -m found }",
+	}
+}
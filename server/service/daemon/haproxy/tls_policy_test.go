@@ -0,0 +1,233 @@
+package haproxy
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/haproxytech/client-native/v6/models"
+)
+
+// newTLSPolicySite 返回启用 HTTPS 和 TLS 策略的测试站点，证书内容只用于生成配置
+func newTLSPolicySite(domain string, policy *model.TLSPolicy) model.Site {
+	return model.Site{
+		Name:         domain,
+		Domain:       domain,
+		ListenPort:   8443,
+		ActiveStatus: true,
+		EnableHTTPS:  true,
+		Certificate:  model.Certificate{PublicKey: "cert " + domain, PrivateKey: "key " + domain},
+		TLSPolicy:    policy,
+		Backend: model.Backend{
+			Servers: []model.Server{{Host: "a.svc", Port: 80}},
+		},
+	}
+}
+
+// TestBuildCrtListEntry 测试站点证书列表条目的 SSL 参数和 SNI 过滤，IP 站点只引用证书
+func TestBuildCrtListEntry(t *testing.T) {
+	crtLoad := &models.CrtLoad{Alias: "example_com_cert"}
+	tests := []struct {
+		name    string
+		domain  string
+		aliases []string
+		policy  *model.TLSPolicy
+		caFile  string
+		want    string
+	}{
+		{"no policy", "example.com", nil, nil, "", "@sites/example_com_cert example.com"},
+		{"empty policy", "example.com", nil, &model.TLSPolicy{HSTS: &model.HSTSPolicy{MaxAge: 300}}, "", "@sites/example_com_cert example.com"},
+		{
+			"ssl options",
+			"example.com",
+			[]string{"www.example.com", "*.api.example.com"},
+			&model.TLSPolicy{
+				MinVersion:   model.TLSVersion12,
+				MaxVersion:   model.TLSVersion13,
+				Ciphers:      "ECDHE-RSA-AES128-GCM-SHA256:ECDHE-RSA-AES256-GCM-SHA384",
+				Ciphersuites: "TLS_AES_128_GCM_SHA256",
+				ALPN:         []string{"h2", "http/1.1"},
+			},
+			"",
+			"@sites/example_com_cert [ssl-min-ver TLSv1.2 ssl-max-ver TLSv1.3 ciphers ECDHE-RSA-AES128-GCM-SHA256:ECDHE-RSA-AES256-GCM-SHA384 " +
+				"ciphersuites TLS_AES_128_GCM_SHA256 alpn h2,http/1.1] example.com www.example.com *.api.example.com",
+		},
+		{
+			"client auth required",
+			"example.com",
+			nil,
+			&model.TLSPolicy{ClientAuth: &model.ClientAuth{Mode: model.ClientAuthRequired}},
+			"/certs/ca_1.pem",
+			"@sites/example_com_cert [ca-file /certs/ca_1.pem verify required] example.com",
+		},
+		{
+			"client auth paths",
+			"example.com",
+			nil,
+			&model.TLSPolicy{MinVersion: model.TLSVersion12, ClientAuth: &model.ClientAuth{Mode: model.ClientAuthPaths, Paths: []string{"/admin/"}}},
+			"/certs/ca_1.pem",
+			"@sites/example_com_cert [ssl-min-ver TLSv1.2 ca-file /certs/ca_1.pem verify optional] example.com",
+		},
+		{
+			"ip site",
+			"10.0.0.1",
+			nil,
+			&model.TLSPolicy{MinVersion: model.TLSVersion12, ClientAuth: &model.ClientAuth{Mode: model.ClientAuthRequired}},
+			"/certs/ca_1.pem",
+			"@sites/example_com_cert",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			site := model.Site{Domain: tt.domain, Aliases: tt.aliases, TLSPolicy: tt.policy}
+			if got := buildCrtListEntry(site, crtLoad, tt.caFile); got != tt.want {
+				t.Errorf("buildCrtListEntry() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBuildTLSPolicyRequestRules 测试各客户端证书认证方式拒绝跨站点 SNI 和没有客户端证书的请求，HSTS 只对站点的 Host 生效
+func TestBuildTLSPolicyRequestRules(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *model.TLSPolicy
+		want   []string
+	}{
+		{"no client auth", &model.TLSPolicy{MinVersion: model.TLSVersion12}, nil},
+		{
+			"required",
+			&model.TLSPolicy{ClientAuth: &model.ClientAuth{Mode: model.ClientAuthRequired}},
+			[]string{
+				"deny 403 if host_example_com !sni_example_com",
+				"deny 403 if host_example_com !{ ssl_c_used }",
+			},
+		},
+		{
+			"paths",
+			&model.TLSPolicy{ClientAuth: &model.ClientAuth{Mode: model.ClientAuthPaths, Paths: []string{"/admin/", "/api/"}}},
+			[]string{
+				"deny 403 if host_example_com mtls_example_com !sni_example_com",
+				"deny 403 if host_example_com mtls_example_com !{ ssl_c_used }",
+			},
+		},
+		{
+			"hsts",
+			&model.TLSPolicy{
+				ClientAuth: &model.ClientAuth{Mode: model.ClientAuthRequired},
+				HSTS:       &model.HSTSPolicy{MaxAge: 31536000, IncludeSubDomains: true, Preload: true},
+			},
+			[]string{
+				"deny 403 if host_example_com !sni_example_com",
+				"deny 403 if host_example_com !{ ssl_c_used }",
+				"set-var-fmt txn.hsts max-age=31536000;includeSubDomains;preload if host_example_com",
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, rule := range buildTLSPolicyRequestRules(newTLSPolicySite("example.com", tt.policy)) {
+				switch rule.Type {
+				case "deny":
+					got = append(got, fmt.Sprintf("deny %d %s %s", *rule.DenyStatus, rule.Cond, rule.CondTest))
+				case "set-var-fmt":
+					got = append(got, fmt.Sprintf("set-var-fmt %s.%s %s %s %s", rule.VarScope, rule.VarName, rule.VarFormat, rule.Cond, rule.CondTest))
+				default:
+					t.Errorf("unexpected rule type %s", rule.Type)
+				}
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("buildTLSPolicyRequestRules() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestBuildTLSPolicyACLs 测试 SNI ACL 覆盖站点的所有主机名，paths 方式按路径前缀匹配
+func TestBuildTLSPolicyACLs(t *testing.T) {
+	site := newTLSPolicySite("example.com", &model.TLSPolicy{ClientAuth: &model.ClientAuth{Mode: model.ClientAuthPaths, Paths: []string{"/admin/"}}})
+	site.Aliases = []string{"*.example.com"}
+
+	var got []string
+	for _, acl := range buildTLSPolicyACLs(site) {
+		got = append(got, acl.ACLName+" "+acl.Criterion+" "+acl.Value)
+	}
+	want := []string{
+		"sni_example_com ssl_fc_sni -i example.com",
+		"sni_example_com ssl_fc_sni -i -m end .example.com",
+		"mtls_example_com path -m beg /admin/",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("buildTLSPolicyACLs() = %q, want %q", got, want)
+	}
+
+	site.TLSPolicy.ClientAuth = nil
+	if acls := buildTLSPolicyACLs(site); acls != nil {
+		t.Errorf("buildTLSPolicyACLs() without client auth = %v, want nil", acls)
+	}
+}
+
+// TestApplySitesTLSPolicy 测试 TLS 策略写入证书列表和 HTTPS 前端，客户端 CA 证书写入证书目录，IP 站点不按 SNI 过滤
+func TestApplySitesTLSPolicy(t *testing.T) {
+	s := newTestHAProxyService(t, false)
+	ca := "-----BEGIN CERTIFICATE-----\nca\n-----END CERTIFICATE-----\n"
+	admin := newTLSPolicySite("admin.example.com", &model.TLSPolicy{
+		MinVersion: model.TLSVersion12,
+		HSTS:       &model.HSTSPolicy{MaxAge: 300},
+		ClientAuth: &model.ClientAuth{Mode: model.ClientAuthPaths, CACertificate: ca, Paths: []string{"/admin/"}},
+	})
+	public := newTLSPolicySite("www.example.com", nil)
+	ipCA := "-----BEGIN CERTIFICATE-----\nip ca\n-----END CERTIFICATE-----\n"
+	ip := newTLSPolicySite("10.0.0.1", &model.TLSPolicy{
+		MinVersion: model.TLSVersion12,
+		ClientAuth: &model.ClientAuth{Mode: model.ClientAuthRequired, CACertificate: ipCA},
+	})
+	config := applyTestSites(t, s, []model.Site{admin, public, ip})
+
+	caFile := filepath.Join(s.CertDir, getClientCAName([]byte(ca)))
+	if content, err := os.ReadFile(caFile); err != nil || string(content) != ca {
+		t.Errorf("client ca file = %q, %v", content, err)
+	}
+	if _, err := os.Stat(filepath.Join(s.CertDir, getClientCAName([]byte(ipCA)))); !os.IsNotExist(err) {
+		t.Errorf("ip site client ca file should not be written, stat error = %v", err)
+	}
+	crtList, err := os.ReadFile(filepath.Join(s.CertDir, getCrtListName(8443)))
+	if err != nil {
+		t.Fatalf("read crt-list: %v", err)
+	}
+	for _, want := range []string{
+		"@sites/admin_example_com_cert [ssl-min-ver TLSv1.2 ca-file " + caFile + " verify optional] admin.example.com\n",
+		"@sites/www_example_com_cert www.example.com\n",
+		"@sites/10_0_0_1_cert\n",
+	} {
+		if !strings.Contains(string(crtList), want) {
+			t.Errorf("crt-list does not contain %q:\n%s", want, crtList)
+		}
+	}
+
+	frontend := getConfigSection(config, "frontend fe_8443_https")
+	for _, want := range []string{
+		"acl sni_admin_example_com ssl_fc_sni -i admin.example.com",
+		"acl mtls_admin_example_com path -m beg /admin/",
+		"http-request deny deny_status 403 if host_admin_example_com mtls_admin_example_com !sni_admin_example_com",
+		"http-request deny deny_status 403 if host_admin_example_com mtls_admin_example_com !{ ssl_c_used }",
+		"http-request set-var-fmt(txn.hsts) max-age=300 if host_admin_example_com",
+		"http-response set-header Strict-Transport-Security %[var(txn.hsts)] if { var(txn.hsts) -m found }",
+	} {
+		if !strings.Contains(frontend, want) {
+			t.Errorf("frontend fe_8443_https does not contain %q:\n%s", want, frontend)
+		}
+	}
+	if strings.Contains(frontend, "10_0_0_1") {
+		t.Errorf("ip site should not add SNI filters or client certificate rules:\n%s", frontend)
+	}
+	// 客户端证书只在 HTTPS 前端校验
+	if strings.Contains(getConfigSection(config, "frontend fe_8443_http"), "ssl_c_used") {
+		t.Error("http frontend should not check client certificates")
+	}
+}
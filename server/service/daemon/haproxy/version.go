@@ -0,0 +1,284 @@
+package haproxy
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+)
+
+var ErrConfigVersionNotFound = errors.New("配置版本不存在")
+
+const (
+	versionMetaFile     = "version.json"
+	versionConfigFile   = "haproxy.cfg"
+	versionSpoeFile     = "spoe.yaml"
+	versionCertDir      = "cert"
+	versionACLDir       = "acl"
+	configCheckTimeout  = 30 * time.Second
+	defaultBackupNumber = 3
+)
+
+// ConfigVersion 已验证并生效过的 HAProxy 配置版本
+type ConfigVersion struct {
+	ID        string    `json:"id"`        // 版本ID，按生成时间排序
+	Reason    string    `json:"reason"`    // 生成原因
+	Checksum  string    `json:"checksum"`  // haproxy.cfg 的 SHA-256
+	CreatedAt time.Time `json:"createdAt"` // 生成时间
+}
+
+// ValidateConfig 使用 haproxy -c 检查当前配置文件，返回的错误包含 HAProxy 的输出
+func (s *HAProxyServiceImpl) ValidateConfig() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.validateConfig()
+}
+
+func (s *HAProxyServiceImpl) validateConfig() error {
+	ctx, cancel := context.WithTimeout(s.ctx, configCheckTimeout)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, s.HaproxyBin, "-c", "-q", "-f", s.HAProxyConfigFile).CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("HAProxy 配置检查未通过: %v: %s", err, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// SaveConfigVersion 将当前配置文件、SPOE 配置、证书和 ACL 模式文件保存为新版本，只保留最近 BackupsNumber 个版本
+// 配置与最新版本相同时不创建新版本，直接返回最新版本
+func (s *HAProxyServiceImpl) SaveConfigVersion(reason string) (*ConfigVersion, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	content, err := os.ReadFile(s.HAProxyConfigFile)
+	if err != nil {
+		return nil, fmt.Errorf("读取配置文件失败: %v", err)
+	}
+	sum := sha256.Sum256(content)
+	checksum := hex.EncodeToString(sum[:])
+
+	versions, err := s.listConfigVersions()
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) > 0 && versions[0].Checksum == checksum {
+		return &versions[0], nil
+	}
+
+	now := time.Now()
+	version := ConfigVersion{
+		ID:        now.Format("20060102-150405.000000"),
+		Reason:    reason,
+		Checksum:  checksum,
+		CreatedAt: now,
+	}
+	dir := filepath.Join(s.VersionDir, version.ID)
+	if err := os.MkdirAll(filepath.Join(dir, versionCertDir), 0700); err != nil {
+		return nil, fmt.Errorf("创建版本目录失败: %v", err)
+	}
+	if err := s.snapshotConfig(dir, content); err != nil {
+		os.RemoveAll(dir)
+		return nil, err
+	}
+	meta, _ := json.MarshalIndent(version, "", "  ")
+	if err := os.WriteFile(filepath.Join(dir, versionMetaFile), meta, 0600); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("写入版本信息失败: %v", err)
+	}
+
+	s.pruneConfigVersions(append([]ConfigVersion{version}, versions...))
+	return &version, nil
+}
+
+// snapshotConfig 将配置文件、SPOE 配置、证书和 ACL 模式文件复制到版本目录
+func (s *HAProxyServiceImpl) snapshotConfig(dir string, content []byte) error {
+	if err := os.WriteFile(filepath.Join(dir, versionConfigFile), content, 0600); err != nil {
+		return fmt.Errorf("保存配置文件失败: %v", err)
+	}
+	if err := copyFile(s.SpoeConfigFile, filepath.Join(dir, versionSpoeFile)); err != nil {
+		return fmt.Errorf("保存 SPOE 配置失败: %v", err)
+	}
+	if err := copyDir(s.CertDir, filepath.Join(dir, versionCertDir)); err != nil {
+		return fmt.Errorf("保存证书失败: %v", err)
+	}
+	// 模式文件名由内容决定，配置文件的校验和相同时引用的模式文件也相同
+	if err := copyDir(s.ACLDir, filepath.Join(dir, versionACLDir)); err != nil {
+		return fmt.Errorf("保存ACL模式文件失败: %v", err)
+	}
+	return nil
+}
+
+// pruneConfigVersions 删除超出保留数量的旧版本，versions 按时间倒序
+func (s *HAProxyServiceImpl) pruneConfigVersions(versions []ConfigVersion) {
+	keep := s.BackupsNumber
+	if keep <= 0 {
+		keep = defaultBackupNumber
+	}
+	for _, version := range versions[min(keep, len(versions)):] {
+		if err := os.RemoveAll(filepath.Join(s.VersionDir, version.ID)); err != nil {
+			s.logger.Error().Err(err).Str("version", version.ID).Msg("删除旧配置版本失败")
+		}
+	}
+}
+
+// ListConfigVersions 按时间倒序返回所有配置版本，第一个为当前生效的版本
+func (s *HAProxyServiceImpl) ListConfigVersions() ([]ConfigVersion, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	return s.listConfigVersions()
+}
+
+func (s *HAProxyServiceImpl) listConfigVersions() ([]ConfigVersion, error) {
+	entries, err := os.ReadDir(s.VersionDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []ConfigVersion{}, nil
+		}
+		return nil, fmt.Errorf("读取版本目录失败: %v", err)
+	}
+
+	versions := make([]ConfigVersion, 0, len(entries))
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		version, err := readVersionMeta(filepath.Join(s.VersionDir, entry.Name()))
+		if err != nil {
+			s.logger.Warn().Err(err).Str("version", entry.Name()).Msg("跳过无效的配置版本")
+			continue
+		}
+		versions = append(versions, version)
+	}
+	slices.SortFunc(versions, func(a, b ConfigVersion) int {
+		return strings.Compare(b.ID, a.ID)
+	})
+	return versions, nil
+}
+
+// GetConfigVersionContent 返回指定版本的 haproxy.cfg 内容
+func (s *HAProxyServiceImpl) GetConfigVersionContent(id string) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	dir, err := s.versionDir(id)
+	if err != nil {
+		return "", err
+	}
+	content, err := os.ReadFile(filepath.Join(dir, versionConfigFile))
+	if err != nil {
+		return "", fmt.Errorf("读取版本配置失败: %v", err)
+	}
+	return string(content), nil
+}
+
+// RestoreConfigVersion 用指定版本覆盖当前的配置文件、SPOE 配置、证书和 ACL 模式文件，不重新加载 HAProxy
+func (s *HAProxyServiceImpl) RestoreConfigVersion(id string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	dir, err := s.versionDir(id)
+	if err != nil {
+		return err
+	}
+
+	if err := copyFile(filepath.Join(dir, versionConfigFile), s.HAProxyConfigFile); err != nil {
+		return fmt.Errorf("恢复配置文件失败: %v", err)
+	}
+	if err := copyFile(filepath.Join(dir, versionSpoeFile), s.SpoeConfigFile); err != nil {
+		return fmt.Errorf("恢复 SPOE 配置失败: %v", err)
+	}
+	if err := os.RemoveAll(s.CertDir); err != nil {
+		return fmt.Errorf("清理证书目录失败: %v", err)
+	}
+	if err := copyDir(filepath.Join(dir, versionCertDir), s.CertDir); err != nil {
+		return fmt.Errorf("恢复证书失败: %v", err)
+	}
+	if err := os.RemoveAll(s.ACLDir); err != nil {
+		return fmt.Errorf("清理ACL模式文件目录失败: %v", err)
+	}
+	if err := copyDir(filepath.Join(dir, versionACLDir), s.ACLDir); err != nil {
+		return fmt.Errorf("恢复ACL模式文件失败: %v", err)
+	}
+
+	// 恢复的文件与配置客户端缓存的内容不一致，需要重新初始化客户端
+	if err := s.resetClients(); err != nil {
+		return fmt.Errorf("重置客户端失败: %v", err)
+	}
+	return nil
+}
+
+// versionDir 返回版本目录，版本ID只允许由时间格式字符组成，防止路径穿越
+func (s *HAProxyServiceImpl) versionDir(id string) (string, error) {
+	if id == "" || strings.ContainsFunc(id, func(r rune) bool {
+		return !(r >= '0' && r <= '9') && r != '-' && r != '.'
+	}) {
+		return "", ErrConfigVersionNotFound
+	}
+	dir := filepath.Join(s.VersionDir, id)
+	if _, err := os.Stat(filepath.Join(dir, versionMetaFile)); err != nil {
+		return "", ErrConfigVersionNotFound
+	}
+	return dir, nil
+}
+
+func readVersionMeta(dir string) (ConfigVersion, error) {
+	var version ConfigVersion
+	data, err := os.ReadFile(filepath.Join(dir, versionMetaFile))
+	if err != nil {
+		return version, err
+	}
+	err = json.Unmarshal(data, &version)
+	return version, err
+}
+
+// copyFile 复制文件，源文件不存在时删除目标文件
+func copyFile(src, dst string) error {
+	data, err := os.ReadFile(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+			return nil
+		}
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return err
+	}
+	return os.WriteFile(dst, data, 0600)
+}
+
+// copyDir 复制目录下的所有文件（不递归），源目录不存在时只创建目标目录
+func copyDir(src, dst string) error {
+	if err := os.MkdirAll(dst, 0700); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		if err := copyFile(filepath.Join(src, entry.Name()), filepath.Join(dst, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
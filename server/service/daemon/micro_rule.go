@@ -0,0 +1,33 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	pkgmodel "github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/service/daemon/haproxy"
+	"go.mongodb.org/mongo-driver/v2/mongo"
+)
+
+// loadMicroRuleOffload 从数据库读取微规则和IP组，编译可以卸载到 HAProxy 的规则
+// 读取失败时返回 nil，不卸载任何规则，所有微规则仍由 WAF 引擎判断
+func (r *ServiceRunnerImpl) loadMicroRuleOffload(ctx context.Context, db *mongo.Database) *haproxy.MicroRuleOffload {
+	var rule pkgmodel.MicroRule
+	rules, err := repository.GetAllMicroRules(ctx, db.Collection(rule.GetCollectionName()))
+	if err != nil {
+		r.logger.Error().Err(err).Msg("获取微规则失败，不卸载微规则到HAProxy")
+		return nil
+	}
+
+	var ipGroup pkgmodel.IPGroup
+	groups, err := repository.GetAllIPGroups(ctx, db.Collection(ipGroup.GetCollectionName()))
+	if err != nil {
+		r.logger.Error().Err(err).Msg("获取IP组失败，不卸载微规则到HAProxy")
+		return nil
+	}
+
+	offload := haproxy.CompileMicroRules(rules, groups, time.Now())
+	r.logger.Info().Int("rules", len(rules)).Int("offloaded", offload.OffloadedCount()).Msg("微规则卸载到HAProxy")
+	return offload
+}
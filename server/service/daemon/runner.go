@@ -2,7 +2,9 @@ package daemon
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"slices"
 	"sync"
 	"time"
 
@@ -33,6 +35,15 @@ type ServiceRunner interface {
 	HotReload() error
 	GetState() ServiceState
 	GetStats() (models.NativeStats, error)
+	GetRuntimeAPI() (haproxy.RuntimeAPI, error)
+	ListConfigVersions() ([]haproxy.ConfigVersion, error)
+	GetConfigVersionContent(id string) (string, error)
+	RollbackConfig(id string) error
+	ApplySites() (*haproxy.SiteApplyResult, error)
+	UpdateCertificates() ([]string, error)
+	SyncBlockedIPs() (*haproxy.BlockedIPSyncResult, error)
+	AddACMEChallengeHosts(hosts []string) error
+	RemoveACMEChallengeHosts(hosts []string) error
 }
 
 // ServiceRunner 负责管理和协调所有后台服务
@@ -46,6 +57,7 @@ type ServiceRunnerImpl struct {
 	haproxyDone    chan struct{} // 通知HAProxy服务已停止
 	engineDone     chan struct{} // 通知Engine服务已停止
 	state          ServiceState
+	applyMutex     sync.Mutex // 串行执行热重载、站点增量应用、配置回滚和封禁IP同步
 }
 
 // 单例模式实现
@@ -134,43 +146,28 @@ func (r *ServiceRunnerImpl) StartServices() error {
 			return
 		}
 
-		if err = r.haproxyService.InitSpoeConfig(); err != nil {
-			r.logger.Error().Err(err).Msg("初始化HAProxy SPOE配置失败")
-			r.errChan <- err
-			return
-		}
-
-		if err = r.haproxyService.InitHAProxyConfig(); err != nil {
-			r.logger.Error().Err(err).Msg("初始化HAProxy配置失败")
-			r.errChan <- err
-			return
-		}
-
-		if err = r.haproxyService.AddCorazaBackend(); err != nil {
-			r.logger.Error().Err(err).Msg("添加Coraza后端失败")
-			r.errChan <- err
-			return
+		// 启动前写入封禁IP映射文件，HAProxy 启动时加载
+		if _, err = r.syncBlockedIPs(r.ctx, db); err != nil {
+			r.logger.Error().Err(err).Msg("写入封禁IP映射失败")
 		}
 
-		if err = r.haproxyService.CreateHAProxyCrtStore(); err != nil {
-			r.logger.Error().Err(err).Msg("创建HAProxy证书存储失败")
+		offload := r.loadMicroRuleOffload(r.ctx, db)
+		if err = r.buildHAProxyConfig(siteList, offload, false); err != nil {
+			r.logger.Error().Err(err).Msg("生成HAProxy配置失败")
 			r.errChan <- err
 			return
 		}
 
-		for i, site := range siteList {
-			if err := r.haproxyService.AddSiteConfig(site); err != nil {
-				r.logger.Error().Err(err).Msgf("添加站点配置失败 %d", i)
-				// 继续处理其他站点，不返回错误
-			}
-		}
-
 		if err := r.haproxyService.Start(); err != nil {
 			r.logger.Error().Err(err).Msg("HAProxy服务启动失败")
 			r.errChan <- err
 			return
 		}
 
+		if _, err := r.haproxyService.SaveConfigVersion("启动"); err != nil {
+			r.logger.Error().Err(err).Msg("保存HAProxy配置版本失败")
+		}
+
 		// 等待停止信号
 		<-r.ctx.Done()
 		r.logger.Info().Msg("收到停止信号，停止HAProxy服务")
@@ -364,6 +361,9 @@ func (r *ServiceRunnerImpl) HotReload() error {
 	if r.state != ServiceRunning {
 		return fmt.Errorf("服务未在运行中，无法热重载")
 	}
+
+	r.applyMutex.Lock()
+	defer r.applyMutex.Unlock()
 	r.logger.Info().Msg("开始热重载...")
 
 	// // reload haproxy config
@@ -391,55 +391,278 @@ func (r *ServiceRunnerImpl) HotReload() error {
 	}
 
 	r.logger.Info().Msg("开始热加载HAProxy配置...")
-	if err = r.haproxyService.HotReloadRemoveConfig(); err != nil {
-		r.logger.Error().Err(err).Msg("热加载删除HAProxy配置失败")
+
+	// 当前生效的版本，新配置加载失败时恢复到该版本
+	activeVersion, err := r.activeConfigVersion()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("获取当前HAProxy配置版本失败")
 		return err
 	}
 
-	if err = r.haproxyService.InitSpoeConfig(); err != nil {
-		r.logger.Error().Err(err).Msg("初始化HAProxy SPOE配置失败")
+	// 新配置在暂存目录中生成和检查，失败时当前的配置文件保持不变
+	offload := r.loadMicroRuleOffload(r.ctx, db)
+	if err = r.buildHAProxyConfig(siteList, offload, true); err != nil {
+		r.logger.Error().Err(err).Msg("生成HAProxy配置失败，保留当前生效的配置")
 		return err
 	}
 
-	if err = r.haproxyService.InitHAProxyConfig(); err != nil {
-		r.logger.Error().Err(err).Msg("初始化HAProxy配置失败")
+	if err := r.haproxyService.Reload(); err != nil {
+		r.logger.Error().Err(err).Msg("热加载HAProxy配置失败")
+		r.restoreConfigVersion(activeVersion)
 		return err
 	}
 
-	if err = r.haproxyService.AddCorazaBackend(); err != nil {
-		r.logger.Error().Err(err).Msg("添加Coraza后端失败")
-		return err
+	if _, err := r.haproxyService.SaveConfigVersion("热重载"); err != nil {
+		r.logger.Error().Err(err).Msg("保存HAProxy配置版本失败")
 	}
 
-	if err = r.haproxyService.CreateHAProxyCrtStore(); err != nil {
-		r.logger.Error().Err(err).Msg("创建HAProxy证书存储失败")
+	// 重新加载后的进程从映射文件加载封禁IP，再同步上次写入文件后新增或过期的封禁
+	r.reconcileBlockedIPs(db)
+
+	// reload engine config
+
+	if err := r.engineService.Reload(); err != nil {
+		r.logger.Error().Err(err).Msg("热加载Engine配置失败")
 		return err
 	}
 
-	for i, site := range siteList {
-		if err := r.haproxyService.AddSiteConfig(site); err != nil {
-			r.logger.Error().Err(err).Msgf("添加站点配置失败 %d", i)
-			// 继续处理其他站点，不返回错误
+	r.logger.Info().Msg("热重载成功")
+
+	return nil
+}
+
+// buildHAProxyConfig 在暂存目录中生成完整的 HAProxy 配置并使用 haproxy -c 检查，通过后替换当前的配置文件
+// offload 为卸载到 HAProxy 的微规则；strict 为 true 时任一站点配置无效即返回错误，否则跳过该站点继续处理
+func (r *ServiceRunnerImpl) buildHAProxyConfig(siteList []model.Site, offload *haproxy.MicroRuleOffload, strict bool) error {
+	return r.haproxyService.BuildConfig(func(builder haproxy.ConfigBuilder) error {
+		if err := builder.InitHAProxyConfig(); err != nil {
+			return fmt.Errorf("初始化HAProxy配置失败: %w", err)
 		}
+
+		if err := builder.AddCorazaBackend(); err != nil {
+			return fmt.Errorf("添加Coraza后端失败: %w", err)
+		}
+
+		if err := builder.CreateHAProxyCrtStore(); err != nil {
+			return fmt.Errorf("创建HAProxy证书存储失败: %w", err)
+		}
+
+		return r.applySites(builder, siteList, offload, strict)
+	})
+}
+
+// applySites 将站点配置应用到暂存的 HAProxy 配置文件，strict 为 false 时跳过配置无效的站点
+func (r *ServiceRunnerImpl) applySites(builder haproxy.ConfigBuilder, siteList []model.Site, offload *haproxy.MicroRuleOffload, strict bool) error {
+	for {
+		_, err := builder.ApplySites(siteList, offload)
+		var siteErr *haproxy.SiteConfigError
+		if err == nil || strict || !errors.As(err, &siteErr) {
+			return err
+		}
+		r.logger.Error().Err(err).Msg("跳过配置无效的站点")
+		// 继续处理其他站点，不返回错误；被跳过的站点使用的证书文件不删除
+		builder.KeepStaleCerts()
+		siteList = slices.DeleteFunc(slices.Clone(siteList), func(site model.Site) bool {
+			return site.ID == siteErr.Site.ID
+		})
 	}
+}
 
-	if err := r.haproxyService.Reload(); err != nil {
-		r.logger.Error().Err(err).Msg("热加载HAProxy配置失败")
+// activeConfigVersion 返回当前生效的配置版本ID，没有版本时返回空字符串
+func (r *ServiceRunnerImpl) activeConfigVersion() (string, error) {
+	versions, err := r.haproxyService.ListConfigVersions()
+	if err != nil {
+		return "", err
+	}
+	if len(versions) == 0 {
+		return "", nil
+	}
+	return versions[0].ID, nil
+}
+
+// restoreConfigVersion 将配置文件恢复到指定版本，id 为空时不做处理
+func (r *ServiceRunnerImpl) restoreConfigVersion(id string) {
+	if id == "" {
+		r.logger.Warn().Msg("没有可恢复的HAProxy配置版本")
+		return
+	}
+	if err := r.haproxyService.RestoreConfigVersion(id); err != nil {
+		r.logger.Error().Err(err).Str("version", id).Msg("恢复HAProxy配置版本失败")
+	}
+}
+
+// ListConfigVersions 按时间倒序返回已生效过的 HAProxy 配置版本
+func (r *ServiceRunnerImpl) ListConfigVersions() ([]haproxy.ConfigVersion, error) {
+	return r.haproxyService.ListConfigVersions()
+}
+
+// GetConfigVersionContent 返回指定配置版本的 haproxy.cfg 内容
+func (r *ServiceRunnerImpl) GetConfigVersionContent(id string) (string, error) {
+	return r.haproxyService.GetConfigVersionContent(id)
+}
+
+// RollbackConfig 回滚到指定的配置版本并重新加载 HAProxy
+// 回滚的配置检查或加载失败时恢复当前生效的配置
+func (r *ServiceRunnerImpl) RollbackConfig(id string) error {
+	if r.state != ServiceRunning {
+		return fmt.Errorf("服务未在运行中，无法回滚配置")
+	}
+
+	r.applyMutex.Lock()
+	defer r.applyMutex.Unlock()
+
+	activeVersion, err := r.activeConfigVersion()
+	if err != nil {
 		return err
 	}
 
-	// reload engine config
+	r.logger.Info().Str("version", id).Msg("开始回滚HAProxy配置...")
+	if err := r.haproxyService.RestoreConfigVersion(id); err != nil {
+		r.restoreConfigVersion(activeVersion)
+		return err
+	}
 
-	if err := r.engineService.Reload(); err != nil {
-		r.logger.Error().Err(err).Msg("热加载Engine配置失败")
+	if err := r.haproxyService.ValidateConfig(); err != nil {
+		r.logger.Error().Err(err).Str("version", id).Msg("回滚的HAProxy配置检查未通过")
+		r.restoreConfigVersion(activeVersion)
 		return err
 	}
 
-	r.logger.Info().Msg("热重载成功")
+	if err := r.haproxyService.Reload(); err != nil {
+		r.logger.Error().Err(err).Str("version", id).Msg("回滚后重新加载HAProxy失败")
+		r.restoreConfigVersion(activeVersion)
+		return err
+	}
 
+	if _, err := r.haproxyService.SaveConfigVersion(fmt.Sprintf("回滚到版本 %s", id)); err != nil {
+		r.logger.Error().Err(err).Msg("保存HAProxy配置版本失败")
+	}
+
+	client, err := mongodb.Connect(config.Global.DBConfig.URI)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("rollback config failed to connect to database")
+	} else {
+		r.reconcileBlockedIPs(client.Database(config.Global.DBConfig.Database))
+	}
+
+	r.logger.Info().Str("version", id).Msg("HAProxy配置回滚成功")
 	return nil
 }
 
+// ApplySites 从数据库读取站点，只将有变化的部分增量应用到 HAProxy 配置，有变更时重新加载 HAProxy
+// 与热重载不同，不会删除并重建全部前端、后端和证书，也不会重新读取应用配置
+func (r *ServiceRunnerImpl) ApplySites() (*haproxy.SiteApplyResult, error) {
+	if r.state != ServiceRunning {
+		return nil, fmt.Errorf("服务未在运行中，无法应用站点配置")
+	}
+
+	r.applyMutex.Lock()
+	defer r.applyMutex.Unlock()
+
+	client, err := mongodb.Connect(config.Global.DBConfig.URI)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("apply sites failed to connect to database")
+		return nil, err
+	}
+
+	// 获取数据库
+	db := client.Database(config.Global.DBConfig.Database)
+
+	var site model.Site
+	siteList, err := repository.GetAllSites(r.ctx, db.Collection(site.GetCollectionName()))
+	if err != nil {
+		r.logger.Error().Err(err).Msg("应用站点配置获取站点列表失败")
+		return nil, err
+	}
+
+	activeVersion, err := r.activeConfigVersion()
+	if err != nil {
+		r.logger.Error().Err(err).Msg("获取当前HAProxy配置版本失败")
+		return nil, err
+	}
+
+	// 微规则与 Engine 同时重新加载，卸载到 HAProxy 的规则与 Engine 中的规则保持一致
+	offload := r.loadMicroRuleOffload(r.ctx, db)
+
+	// 事务提交失败时配置文件保持不变
+	result, err := r.haproxyService.ApplySites(siteList, offload)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("应用站点配置失败")
+		return nil, err
+	}
+
+	if result.Changed() {
+		if err := r.haproxyService.Reload(); err != nil {
+			r.logger.Error().Err(err).Msg("应用站点配置后重新加载HAProxy失败")
+			r.restoreConfigVersion(activeVersion)
+			return nil, err
+		}
+		if _, err := r.haproxyService.SaveConfigVersion("应用站点变更"); err != nil {
+			r.logger.Error().Err(err).Msg("保存HAProxy配置版本失败")
+		}
+		r.reconcileBlockedIPs(db)
+	}
+
+	// 站点的 WAF 模式和登录保护等配置由 Engine 读取
+	if err := r.engineService.Reload(); err != nil {
+		r.logger.Error().Err(err).Msg("重新加载Engine配置失败")
+		return nil, err
+	}
+
+	r.logger.Info().Interface("result", result).Msg("站点配置已增量应用")
+	return result, nil
+}
+
+// UpdateCertificates 从数据库读取站点，通过 HAProxy 运行时 API 热加载证书有变化的站点，不重新加载 HAProxy
+// 新启用 HTTPS 的站点需要通过 ApplySites 修改配置
+func (r *ServiceRunnerImpl) UpdateCertificates() ([]string, error) {
+	if r.state != ServiceRunning {
+		return nil, fmt.Errorf("服务未在运行中，无法更新证书")
+	}
+
+	r.applyMutex.Lock()
+	defer r.applyMutex.Unlock()
+
+	client, err := mongodb.Connect(config.Global.DBConfig.URI)
+	if err != nil {
+		r.logger.Error().Err(err).Msg("update certificates failed to connect to database")
+		return nil, err
+	}
+
+	// 获取数据库
+	db := client.Database(config.Global.DBConfig.Database)
+
+	var site model.Site
+	siteList, err := repository.GetAllSites(r.ctx, db.Collection(site.GetCollectionName()))
+	if err != nil {
+		r.logger.Error().Err(err).Msg("更新证书获取站点列表失败")
+		return nil, err
+	}
+
+	updated, err := r.haproxyService.UpdateCertificates(siteList)
+	if len(updated) > 0 {
+		if _, err := r.haproxyService.SaveConfigVersion("更新证书"); err != nil {
+			r.logger.Error().Err(err).Msg("保存HAProxy配置版本失败")
+		}
+		r.logger.Info().Strs("certificates", updated).Msg("证书已热加载")
+	}
+	if err != nil {
+		r.logger.Error().Err(err).Msg("热加载证书失败")
+		return updated, err
+	}
+	return updated, nil
+}
+
+// AddACMEChallengeHosts 签发或续期证书期间将主机名的 HTTP-01 验证请求转发到管理服务，不重新加载配置
+func (r *ServiceRunnerImpl) AddACMEChallengeHosts(hosts []string) error {
+	return r.haproxyService.AddACMEChallengeHosts(hosts)
+}
+
+// RemoveACMEChallengeHosts 撤销 AddACMEChallengeHosts 添加的主机名
+func (r *ServiceRunnerImpl) RemoveACMEChallengeHosts(hosts []string) error {
+	return r.haproxyService.RemoveACMEChallengeHosts(hosts)
+}
+
 // GetState 获取当前服务状态
 func (r *ServiceRunnerImpl) GetState() ServiceState {
 	return r.state
@@ -452,3 +675,14 @@ func (r *ServiceRunnerImpl) GetStats() (models.NativeStats, error) {
 	}
 	return r.haproxyService.GetStats()
 }
+
+// GetRuntimeAPI 获取HAProxy运行时API，服务未运行时返回错误
+func (r *ServiceRunnerImpl) GetRuntimeAPI() (haproxy.RuntimeAPI, error) {
+	if r.haproxyService == nil {
+		return nil, fmt.Errorf("haproxy service not initialized")
+	}
+	if r.state != ServiceRunning {
+		return nil, fmt.Errorf("haproxy service not running")
+	}
+	return r.haproxyService, nil
+}
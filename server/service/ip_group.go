@@ -4,7 +4,11 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
+	"io"
+	"sort"
 	"strconv"
+	"strings"
 
 	"github.com/HUAHUAI23/RuiQi/pkg/model"
 	"github.com/HUAHUAI23/RuiQi/server/config"
@@ -19,32 +23,44 @@ const (
 )
 
 var (
-	ErrIPGroupNotFound    = errors.New("IP组不存在")
-	ErrIPGroupNameExists  = errors.New("IP组名称已存在")
-	ErrSystemIPGroupNoMod = errors.New("系统默认IP组不允许删除")
+	ErrIPGroupNotFound      = errors.New("IP组不存在")
+	ErrIPGroupNameExists    = errors.New("IP组名称已存在")
+	ErrSystemIPGroupNoMod   = errors.New("系统默认IP组不允许删除")
+	ErrExpirationNotInGroup = errors.New("过期时间对应的条目不在IP组中")
+	ErrIPGroupReferenced    = errors.New("IP组正在被微规则引用")
+	ErrEdgeBlockScoped      = errors.New("只有对所有站点生效的IP组才能在HAProxy前端拦截")
 )
 
 // IPGroupService IP组服务接口
 type IPGroupService interface {
 	CreateIPGroup(ctx context.Context, req *dto.IPGroupCreateRequest) (*model.IPGroup, error)
-	GetIPGroups(ctx context.Context, pageStr, sizeStr string) ([]model.IPGroup, int64, error)
+	GetIPGroups(ctx context.Context, req *dto.IPGroupListRequest) ([]model.IPGroup, int64, error)
 	GetIPGroupByID(ctx context.Context, id bson.ObjectID) (*model.IPGroup, error)
 	UpdateIPGroup(ctx context.Context, id bson.ObjectID, req *dto.IPGroupUpdateRequest) (*model.IPGroup, error)
 	DeleteIPGroup(ctx context.Context, id bson.ObjectID) error
 	AddIPToBlacklist(ctx context.Context, ip string) error
+	ImportIPGroup(ctx context.Context, id bson.ObjectID, req *dto.IPGroupImportRequest, filename string, r io.Reader) (*dto.IPGroupImportReport, error)
+	CreateIPGroupFromImport(ctx context.Context, req *dto.IPGroupImportRequest, filename string, r io.Reader) (*dto.IPGroupImportReport, error)
+	ExportIPGroup(ctx context.Context, id bson.ObjectID, format string) (*IPGroupExportFile, error)
+	GetIPGroupReferences(ctx context.Context, id bson.ObjectID) (*dto.IPGroupReferencesResponse, error)
+	GetAllIPGroupReferences(ctx context.Context) ([]dto.IPGroupReferencesResponse, error)
 }
 
 // IPGroupServiceImpl IP组服务实现
 type IPGroupServiceImpl struct {
 	ipGroupRepo repository.IPGroupRepository
+	siteRepo    repository.SiteRepository
+	ruleRepo    repository.MicroRuleRepository
 	logger      zerolog.Logger
 }
 
 // NewIPGroupService 创建IP组服务
-func NewIPGroupService(ipGroupRepo repository.IPGroupRepository) IPGroupService {
+func NewIPGroupService(ipGroupRepo repository.IPGroupRepository, siteRepo repository.SiteRepository, ruleRepo repository.MicroRuleRepository) IPGroupService {
 	logger := config.GetServiceLogger("ipgroup")
 	return &IPGroupServiceImpl{
 		ipGroupRepo: ipGroupRepo,
+		siteRepo:    siteRepo,
+		ruleRepo:    ruleRepo,
 		logger:      logger,
 	}
 }
@@ -62,14 +78,33 @@ func (s *IPGroupServiceImpl) CreateIPGroup(ctx context.Context, req *dto.IPGroup
 		}
 	}
 
+	// 校验站点作用域
+	scope, err := buildSiteScope(ctx, s.siteRepo, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+	// HAProxy 前端无法区分请求属于哪个站点，只有全局IP组可以在前端拦截
+	if req.EdgeBlock && scope != nil {
+		return nil, ErrEdgeBlockScoped
+	}
+
+	// 校验条目过期时间
+	expirations, err := buildIPItemExpirations(req.Items, req.Expirations)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建新IP组
 	ipGroup := &model.IPGroup{
-		Name:  req.Name,
-		Items: req.Items,
+		Name:        req.Name,
+		Items:       req.Items,
+		Expirations: expirations,
+		Scope:       scope,
+		EdgeBlock:   req.EdgeBlock,
 	}
 
 	// 保存IP组
-	err := s.ipGroupRepo.CreateIPGroup(ctx, ipGroup)
+	err = s.ipGroupRepo.CreateIPGroup(ctx, ipGroup)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("创建IP组失败")
 		return nil, err
@@ -80,18 +115,23 @@ func (s *IPGroupServiceImpl) CreateIPGroup(ctx context.Context, req *dto.IPGroup
 }
 
 // GetIPGroups 获取IP组列表
-func (s *IPGroupServiceImpl) GetIPGroups(ctx context.Context, pageStr, sizeStr string) ([]model.IPGroup, int64, error) {
-	page, err := strconv.ParseInt(pageStr, 10, 64)
+func (s *IPGroupServiceImpl) GetIPGroups(ctx context.Context, req *dto.IPGroupListRequest) ([]model.IPGroup, int64, error) {
+	page, err := strconv.ParseInt(req.Page, 10, 64)
 	if err != nil || page < 1 {
 		page = 1
 	}
 
-	size, err := strconv.ParseInt(sizeStr, 10, 64)
+	size, err := strconv.ParseInt(req.Size, 10, 64)
 	if err != nil || size < 1 {
 		size = 10
 	}
 
-	ipGroups, total, err := s.ipGroupRepo.GetIPGroups(ctx, page, size)
+	scopeFilter, err := buildSiteScopeFilter(ctx, s.siteRepo, req.SiteID, req.IncludeGlobal)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	ipGroups, total, err := s.ipGroupRepo.GetIPGroups(ctx, page, size, scopeFilter)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("获取IP组列表失败")
 		return nil, 0, err
@@ -135,6 +175,8 @@ func (s *IPGroupServiceImpl) UpdateIPGroup(ctx context.Context, id bson.ObjectID
 	}
 
 	// 检查IP组名称是否已存在（如果要更新名称）
+	oldName := ipGroup.Name
+	var dependents []model.MicroRule
 	if req.Name != "" && req.Name != ipGroup.Name {
 		exists, err := s.ipGroupRepo.CheckIPGroupNameExists(ctx, req.Name, id)
 		if err != nil {
@@ -143,6 +185,15 @@ func (s *IPGroupServiceImpl) UpdateIPGroup(ctx context.Context, id bson.ObjectID
 		if exists {
 			return nil, ErrIPGroupNameExists
 		}
+
+		// 被规则引用时，只有明确要求同时更新引用才允许重命名
+		dependents, err = s.ruleRepo.GetMicroRulesByIPGroup(ctx, ipGroup.Name)
+		if err != nil {
+			return nil, err
+		}
+		if len(dependents) > 0 && !req.UpdateReferences {
+			return nil, referencedError(dependents)
+		}
 		ipGroup.Name = req.Name
 	}
 
@@ -151,6 +202,43 @@ func (s *IPGroupServiceImpl) UpdateIPGroup(ctx context.Context, id bson.ObjectID
 		ipGroup.Items = req.Items
 	}
 
+	// 更新条目过期时间，未传入时只保留仍在组中的条目的过期时间
+	if req.Expirations != nil {
+		expirations, err := buildIPItemExpirations(ipGroup.Items, req.Expirations)
+		if err != nil {
+			return nil, err
+		}
+		ipGroup.Expirations = expirations
+	} else if req.Items != nil {
+		ipGroup.Expirations = retainIPItemExpirations(ipGroup.Items, ipGroup.Expirations)
+	}
+	if req.Scope != nil {
+		scope, err := buildSiteScope(ctx, s.siteRepo, req.Scope)
+		if err != nil {
+			return nil, err
+		}
+		ipGroup.Scope = scope
+	}
+	if req.EdgeBlock != nil {
+		ipGroup.EdgeBlock = *req.EdgeBlock
+	}
+	if ipGroup.EdgeBlock && ipGroup.Scope != nil {
+		return nil, ErrEdgeBlockScoped
+	}
+
+	// 重命名被引用的IP组时，IP组名称和引用它的规则一起更新
+	if len(dependents) > 0 {
+		renamed, err := s.ipGroupRepo.RenameIPGroup(ctx, id, oldName, ipGroup.Name)
+		if err != nil {
+			if errors.Is(err, repository.ErrIPGroupNotFound) {
+				return nil, ErrIPGroupNotFound
+			}
+			s.logger.Error().Err(err).Str("id", id.Hex()).Msg("重命名IP组失败")
+			return nil, err
+		}
+		s.logger.Info().Str("id", id.Hex()).Str("from", oldName).Str("to", ipGroup.Name).Int("rules", renamed).Msg("IP组重命名成功，已更新引用规则")
+	}
+
 	// 保存更新
 	err = s.ipGroupRepo.UpdateIPGroup(ctx, ipGroup)
 	if err != nil {
@@ -179,6 +267,16 @@ func (s *IPGroupServiceImpl) DeleteIPGroup(ctx context.Context, id bson.ObjectID
 		return ErrSystemIPGroupNoMod
 	}
 
+	// 被规则引用的IP组删除后，规则匹配时会因IP组不存在而出错
+	dependents, err := s.ruleRepo.GetMicroRulesByIPGroup(ctx, ipGroup.Name)
+	if err != nil {
+		return err
+	}
+	if len(dependents) > 0 {
+		s.logger.Warn().Str("id", id.Hex()).Int("rules", len(dependents)).Msg("尝试删除被规则引用的IP组")
+		return referencedError(dependents)
+	}
+
 	// 删除IP组
 	err = s.ipGroupRepo.DeleteIPGroup(ctx, id)
 	if err != nil {
@@ -224,3 +322,138 @@ func (s *IPGroupServiceImpl) AddIPToBlacklist(ctx context.Context, ip string) er
 	s.logger.Info().Str("ip", ip).Msg("IP成功添加到黑名单")
 	return nil
 }
+
+// GetIPGroupReferences 获取引用指定IP组的微规则
+func (s *IPGroupServiceImpl) GetIPGroupReferences(ctx context.Context, id bson.ObjectID) (*dto.IPGroupReferencesResponse, error) {
+	ipGroup, err := s.GetIPGroupByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	rules, err := s.ruleRepo.GetMicroRulesByIPGroup(ctx, ipGroup.Name)
+	if err != nil {
+		s.logger.Error().Err(err).Str("id", id.Hex()).Msg("获取IP组引用失败")
+		return nil, err
+	}
+
+	return &dto.IPGroupReferencesResponse{
+		GroupID:   ipGroup.ID.Hex(),
+		GroupName: ipGroup.Name,
+		Exists:    true,
+		Total:     len(rules),
+		Rules:     toIPGroupReferences(rules),
+	}, nil
+}
+
+// GetAllIPGroupReferences 获取所有被微规则引用的IP组及其引用规则，包括引用了不存在的IP组的规则
+func (s *IPGroupServiceImpl) GetAllIPGroupReferences(ctx context.Context) ([]dto.IPGroupReferencesResponse, error) {
+	rules, err := s.ruleRepo.GetMicroRulesWithIPGroupRefs(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("获取IP组引用失败")
+		return nil, err
+	}
+
+	byGroup := make(map[string][]model.MicroRule)
+	for _, rule := range rules {
+		for _, name := range rule.IPGroupRefs {
+			byGroup[name] = append(byGroup[name], rule)
+		}
+	}
+
+	names := make([]string, 0, len(byGroup))
+	for name := range byGroup {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]dto.IPGroupReferencesResponse, 0, len(names))
+	for _, name := range names {
+		refs := dto.IPGroupReferencesResponse{
+			GroupName: name,
+			Total:     len(byGroup[name]),
+			Rules:     toIPGroupReferences(byGroup[name]),
+		}
+
+		ipGroup, err := s.ipGroupRepo.GetIPGroupByName(ctx, name)
+		if err == nil {
+			refs.GroupID = ipGroup.ID.Hex()
+			refs.Exists = true
+		} else if !errors.Is(err, repository.ErrIPGroupNotFound) {
+			return nil, err
+		}
+
+		result = append(result, refs)
+	}
+
+	return result, nil
+}
+
+// toIPGroupReferences 将规则转换为引用信息
+func toIPGroupReferences(rules []model.MicroRule) []dto.IPGroupReference {
+	refs := make([]dto.IPGroupReference, 0, len(rules))
+	for _, rule := range rules {
+		refs = append(refs, dto.IPGroupReference{
+			RuleID:     rule.ID.Hex(),
+			RuleName:   rule.Name,
+			RuleType:   string(rule.Type),
+			RuleStatus: string(rule.Status),
+		})
+	}
+	return refs
+}
+
+// referencedError 返回列出引用规则名称的 ErrIPGroupReferenced
+func referencedError(rules []model.MicroRule) error {
+	names := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		names = append(names, rule.Name)
+	}
+	return fmt.Errorf("%w: %s", ErrIPGroupReferenced, strings.Join(names, ", "))
+}
+
+// buildIPItemExpirations 将条目过期时间请求转换为模型，条目必须在IP组中，同一条目以最后一次设置为准
+func buildIPItemExpirations(items []string, reqs []dto.IPItemExpirationRequest) ([]model.IPItemExpiration, error) {
+	if len(reqs) == 0 {
+		return nil, nil
+	}
+
+	itemSet := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		itemSet[item] = struct{}{}
+	}
+
+	indexes := make(map[string]int, len(reqs))
+	expirations := make([]model.IPItemExpiration, 0, len(reqs))
+	for _, req := range reqs {
+		if _, ok := itemSet[req.Item]; !ok {
+			return nil, fmt.Errorf("%w: %s", ErrExpirationNotInGroup, req.Item)
+		}
+		if i, ok := indexes[req.Item]; ok {
+			expirations[i].ExpiresAt = req.ExpiresAt
+			continue
+		}
+		indexes[req.Item] = len(expirations)
+		expirations = append(expirations, model.IPItemExpiration{
+			Item:      req.Item,
+			ExpiresAt: req.ExpiresAt,
+		})
+	}
+
+	return expirations, nil
+}
+
+// retainIPItemExpirations 只保留仍在IP组中的条目的过期时间
+func retainIPItemExpirations(items []string, expirations []model.IPItemExpiration) []model.IPItemExpiration {
+	itemSet := make(map[string]struct{}, len(items))
+	for _, item := range items {
+		itemSet[item] = struct{}{}
+	}
+
+	var retained []model.IPItemExpiration
+	for _, expiration := range expirations {
+		if _, ok := itemSet[expiration.Item]; ok {
+			retained = append(retained, expiration)
+		}
+	}
+	return retained
+}
@@ -0,0 +1,715 @@
+// server/service/ip_group_import.go
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/pkg/utils/network"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+const (
+	IPGroupFormatTXT  = "txt"
+	IPGroupFormatCSV  = "csv"
+	IPGroupFormatJSON = "json"
+
+	IPGroupImportModeMerge   = "merge"
+	IPGroupImportModeReplace = "replace"
+
+	MaxIPGroupImportSize = 20 << 20 // 导入文件大小上限 20MB
+
+	maxImportLineSize      = 1 << 20 // 单行长度上限
+	maxImportInvalidSample = 100     // 报告中无效行示例的数量上限
+	maxImportChangeSample  = 1000    // 报告中变更条目的数量上限
+)
+
+var (
+	ErrIPGroupImportTooLarge      = errors.New("导入文件超过大小限制")
+	ErrIPGroupImportNameRequired  = errors.New("导入为新IP组时名称不能为空")
+	ErrIPGroupImportInvalidFile   = errors.New("导入文件格式错误")
+	ErrIPGroupImportNoValidEntry  = errors.New("导入文件中没有有效的IP地址或CIDR")
+	ErrIPGroupExportInvalidFormat = errors.New("不支持的导出格式")
+)
+
+// IPGroupExportFile 导出的IP组文件
+type IPGroupExportFile struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+// importEntry 导入文件中的一个有效条目
+type importEntry struct {
+	prefix    netip.Prefix
+	expiresAt *time.Time
+}
+
+// importParseResult 导入文件解析结果
+type importParseResult struct {
+	totalLines     int
+	entries        []importEntry
+	invalidLines   int
+	invalidSamples []dto.IPGroupImportInvalidLine
+}
+
+func (r *importParseResult) addInvalid(line int, content, reason string) {
+	r.invalidLines++
+	if len(r.invalidSamples) < maxImportInvalidSample {
+		r.invalidSamples = append(r.invalidSamples, dto.IPGroupImportInvalidLine{
+			Line:    line,
+			Content: content,
+			Reason:  reason,
+		})
+	}
+}
+
+// addItem 解析条目和过期时间，无效的条目记为无效行
+func (r *importParseResult) addItem(line int, item string, expiresAt *time.Time, now time.Time) {
+	prefix, ok := network.ParsePrefix(item)
+	if !ok {
+		r.addInvalid(line, item, "无效的IP地址或CIDR")
+		return
+	}
+	if expiresAt != nil && !now.Before(*expiresAt) {
+		r.addInvalid(line, item, "过期时间已过")
+		return
+	}
+	r.entries = append(r.entries, importEntry{prefix: prefix, expiresAt: expiresAt})
+}
+
+// ImportIPGroup 从文件导入条目到已有IP组
+func (s *IPGroupServiceImpl) ImportIPGroup(ctx context.Context, id bson.ObjectID, req *dto.IPGroupImportRequest, filename string, r io.Reader) (*dto.IPGroupImportReport, error) {
+	ipGroup, err := s.ipGroupRepo.GetIPGroupByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrIPGroupNotFound) {
+			return nil, ErrIPGroupNotFound
+		}
+		return nil, err
+	}
+
+	report, err := s.applyImport(ipGroup, req, filename, r)
+	if err != nil {
+		return nil, err
+	}
+	if req.DryRun {
+		return report, nil
+	}
+
+	if err := s.ipGroupRepo.UpdateIPGroup(ctx, ipGroup); err != nil {
+		s.logger.Error().Err(err).Str("id", id.Hex()).Msg("保存导入的IP组失败")
+		return nil, err
+	}
+
+	s.logger.Info().
+		Str("id", id.Hex()).
+		Str("name", ipGroup.Name).
+		Int("added", report.AddedCount).
+		Int("removed", report.RemovedCount).
+		Msg("IP组导入成功")
+	return report, nil
+}
+
+// CreateIPGroupFromImport 从文件导入创建新的IP组
+func (s *IPGroupServiceImpl) CreateIPGroupFromImport(ctx context.Context, req *dto.IPGroupImportRequest, filename string, r io.Reader) (*dto.IPGroupImportReport, error) {
+	if req.Name == "" {
+		return nil, ErrIPGroupImportNameRequired
+	}
+
+	exists, err := s.ipGroupRepo.CheckIPGroupNameExists(ctx, req.Name, bson.NilObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrIPGroupNameExists
+	}
+
+	ipGroup := &model.IPGroup{Name: req.Name}
+	report, err := s.applyImport(ipGroup, req, filename, r)
+	if err != nil {
+		return nil, err
+	}
+	if req.DryRun {
+		return report, nil
+	}
+
+	if err := s.ipGroupRepo.CreateIPGroup(ctx, ipGroup); err != nil {
+		s.logger.Error().Err(err).Str("name", req.Name).Msg("创建导入的IP组失败")
+		return nil, err
+	}
+
+	report.GroupID = ipGroup.ID.Hex()
+	s.logger.Info().
+		Str("id", report.GroupID).
+		Str("name", ipGroup.Name).
+		Int("items", report.ItemsAfter).
+		Msg("IP组导入创建成功")
+	return report, nil
+}
+
+// ExportIPGroup 按指定格式导出IP组
+func (s *IPGroupServiceImpl) ExportIPGroup(ctx context.Context, id bson.ObjectID, format string) (*IPGroupExportFile, error) {
+	ipGroup, err := s.ipGroupRepo.GetIPGroupByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrIPGroupNotFound) {
+			return nil, ErrIPGroupNotFound
+		}
+		s.logger.Error().Err(err).Str("id", id.Hex()).Msg("获取IP组失败")
+		return nil, err
+	}
+
+	if format == "" {
+		format = IPGroupFormatTXT
+	}
+
+	file := &IPGroupExportFile{Filename: ipGroup.Name + "." + format}
+	switch format {
+	case IPGroupFormatTXT:
+		file.ContentType = "text/plain; charset=utf-8"
+		file.Data = exportIPGroupTXT(ipGroup, time.Now())
+	case IPGroupFormatCSV:
+		file.ContentType = "text/csv; charset=utf-8"
+		file.Data, err = exportIPGroupCSV(ipGroup)
+	case IPGroupFormatJSON:
+		file.ContentType = "application/json; charset=utf-8"
+		file.Data, err = exportIPGroupJSON(ipGroup)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrIPGroupExportInvalidFormat, format)
+	}
+	if err != nil {
+		s.logger.Error().Err(err).Str("id", id.Hex()).Str("format", format).Msg("导出IP组失败")
+		return nil, err
+	}
+
+	return file, nil
+}
+
+// applyImport 解析导入文件并将结果写入 ipGroup，返回变更报告
+func (s *IPGroupServiceImpl) applyImport(ipGroup *model.IPGroup, req *dto.IPGroupImportRequest, filename string, r io.Reader) (*dto.IPGroupImportReport, error) {
+	data, err := io.ReadAll(io.LimitReader(r, MaxIPGroupImportSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > MaxIPGroupImportSize {
+		return nil, ErrIPGroupImportTooLarge
+	}
+
+	format := importFormat(req.Format, filename)
+	mode := req.Mode
+	if mode == "" {
+		mode = IPGroupImportModeMerge
+	}
+
+	now := time.Now()
+	parsed, err := parseIPGroupImport(format, data, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(parsed.entries) == 0 {
+		return nil, ErrIPGroupImportNoValidEntry
+	}
+
+	report := &dto.IPGroupImportReport{
+		GroupName:      ipGroup.Name,
+		Format:         format,
+		Mode:           mode,
+		DryRun:         req.DryRun,
+		TotalLines:     parsed.totalLines,
+		ValidEntries:   len(parsed.entries),
+		InvalidLines:   parsed.invalidLines,
+		InvalidSamples: parsed.invalidSamples,
+		ItemsBefore:    len(ipGroup.Items),
+	}
+	if !ipGroup.ID.IsZero() {
+		report.GroupID = ipGroup.ID.Hex()
+	}
+
+	agg := newIPItemAggregator()
+
+	// 合并模式保留现有条目及其过期时间，无法解析的现有条目原样保留
+	if mode == IPGroupImportModeMerge {
+		expiry := ipGroup.ExpiryMap()
+		for _, item := range ipGroup.Items {
+			prefix, ok := network.ParsePrefix(item)
+			if !ok {
+				agg.keepRaw(item, expiry)
+				continue
+			}
+			var expiresAt *time.Time
+			if t, ok := expiry[item]; ok {
+				expiresAt = &t
+			}
+			agg.add(prefix, expiresAt)
+		}
+	}
+
+	for _, entry := range parsed.entries {
+		if agg.add(entry.prefix, entry.expiresAt) {
+			report.DuplicateEntries++
+		}
+	}
+
+	items, expirations := agg.result()
+	report.ItemsAfter = len(items)
+	report.Added, report.AddedCount = diffItems(items, ipGroup.Items)
+	report.Removed, report.RemovedCount = diffItems(ipGroup.Items, items)
+	report.Truncated = report.AddedCount > len(report.Added) || report.RemovedCount > len(report.Removed)
+
+	ipGroup.Items = items
+	ipGroup.Expirations = expirations
+	return report, nil
+}
+
+// importFormat 确定导入文件格式，优先使用请求参数，其次使用文件扩展名，默认按纯文本处理
+func importFormat(format, filename string) string {
+	if format != "" {
+		return format
+	}
+	switch strings.ToLower(strings.TrimPrefix(filepath.Ext(filename), ".")) {
+	case IPGroupFormatCSV:
+		return IPGroupFormatCSV
+	case IPGroupFormatJSON:
+		return IPGroupFormatJSON
+	default:
+		return IPGroupFormatTXT
+	}
+}
+
+func parseIPGroupImport(format string, data []byte, now time.Time) (*importParseResult, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // 去除 UTF-8 BOM
+
+	switch format {
+	case IPGroupFormatCSV:
+		return parseImportCSV(data, now)
+	case IPGroupFormatJSON:
+		return parseImportJSON(data, now)
+	default:
+		return parseImportTXT(data, now)
+	}
+}
+
+// parseImportTXT 解析纯文本文件，每行一个IP或CIDR，支持 #、// 和 ; 注释，行内多列时取第一列
+// 导出文件中 "# expires_at <RFC3339>" 形式的行内注释作为条目的过期时间
+func parseImportTXT(data []byte, now time.Time) (*importParseResult, error) {
+	result := &importParseResult{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxImportLineSize)
+	for scanner.Scan() {
+		result.totalLines++
+		line := stripImportComment(scanner.Text())
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return unicode.IsSpace(r) || r == ','
+		})
+		if len(fields) == 0 {
+			continue
+		}
+		expiresAt, err := txtImportExpiry(scanner.Text())
+		if err != nil {
+			result.addInvalid(result.totalLines, scanner.Text(), "无效的过期时间，应为 RFC3339 格式")
+			continue
+		}
+		result.addItem(result.totalLines, fields[0], expiresAt, now)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIPGroupImportInvalidFile, err)
+	}
+
+	return result, nil
+}
+
+// txtImportExpiry 解析行内 "# expires_at <RFC3339>" 注释中的过期时间，没有该注释时返回 nil
+func txtImportExpiry(line string) (*time.Time, error) {
+	i := strings.Index(line, "#")
+	if i < 0 {
+		return nil, nil
+	}
+	value, ok := strings.CutPrefix(strings.TrimSpace(line[i+1:]), "expires_at")
+	if !ok {
+		return nil, nil
+	}
+	t, err := time.Parse(time.RFC3339, strings.TrimSpace(value))
+	if err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func stripImportComment(line string) string {
+	for _, marker := range []string{"#", "//", ";"} {
+		if i := strings.Index(line, marker); i >= 0 {
+			line = line[:i]
+		}
+	}
+	return strings.TrimSpace(line)
+}
+
+// parseImportCSV 解析 CSV 文件
+// 第一行包含 ip、cidr、item、address 或 network 列名时视为表头，可选的 expires_at 列为 RFC3339 格式的过期时间
+// 没有表头时使用第一列
+func parseImportCSV(data []byte, now time.Time) (*importParseResult, error) {
+	result := &importParseResult{totalLines: countLines(data)}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	itemCol, expiresCol := 0, -1
+	first := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				result.addInvalid(parseErr.StartLine, "", parseErr.Err.Error())
+				continue
+			}
+			return nil, fmt.Errorf("%w: %v", ErrIPGroupImportInvalidFile, err)
+		}
+
+		line, _ := reader.FieldPos(0)
+		if first {
+			first = false
+			if col, expCol, ok := csvImportHeader(record); ok {
+				itemCol, expiresCol = col, expCol
+				continue
+			}
+		}
+
+		if itemCol >= len(record) || strings.TrimSpace(record[itemCol]) == "" {
+			if strings.TrimSpace(strings.Join(record, "")) != "" {
+				result.addInvalid(line, strings.Join(record, ","), "缺少IP地址或CIDR列")
+			}
+			continue
+		}
+
+		item := strings.TrimSpace(record[itemCol])
+		var expiresAt *time.Time
+		if expiresCol >= 0 && expiresCol < len(record) && strings.TrimSpace(record[expiresCol]) != "" {
+			t, err := time.Parse(time.RFC3339, strings.TrimSpace(record[expiresCol]))
+			if err != nil {
+				result.addInvalid(line, strings.Join(record, ","), "无效的过期时间，应为 RFC3339 格式")
+				continue
+			}
+			expiresAt = &t
+		}
+		result.addItem(line, item, expiresAt, now)
+	}
+
+	return result, nil
+}
+
+// csvImportHeader 识别 CSV 表头，返回条目列和过期时间列的位置
+func csvImportHeader(record []string) (itemCol, expiresCol int, ok bool) {
+	itemCol, expiresCol = -1, -1
+	for i, name := range record {
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "ip", "cidr", "item", "address", "network", "ip_address":
+			if itemCol < 0 {
+				itemCol = i
+			}
+		case "expires_at", "expiresat", "expires", "expiry":
+			if expiresCol < 0 {
+				expiresCol = i
+			}
+		}
+	}
+	if itemCol < 0 {
+		return 0, -1, false
+	}
+	return itemCol, expiresCol, true
+}
+
+func countLines(data []byte) int {
+	if len(data) == 0 {
+		return 0
+	}
+	n := bytes.Count(data, []byte("\n"))
+	if data[len(data)-1] != '\n' {
+		n++
+	}
+	return n
+}
+
+// jsonImportItem JSON 导入文件中的对象条目
+type jsonImportItem struct {
+	Item      string     `json:"item"`
+	IP        string     `json:"ip"`
+	CIDR      string     `json:"cidr"`
+	ExpiresAt *time.Time `json:"expiresAt"`
+}
+
+// parseImportJSON 解析 JSON 文件，支持以下结构：
+// 字符串数组 ["10.0.0.1", ...]；
+// 对象数组 [{"item": "10.0.0.1", "expiresAt": "..."}, ...]，条目字段也可以是 ip 或 cidr；
+// 导出格式 {"items": [...], "expirations": [{"item": "...", "expiresAt": "..."}]}
+func parseImportJSON(data []byte, now time.Time) (*importParseResult, error) {
+	result := &importParseResult{}
+
+	trimmed := bytes.TrimSpace(data)
+	if len(trimmed) == 0 {
+		return result, nil
+	}
+
+	if trimmed[0] == '{' {
+		var file struct {
+			Items       []string                 `json:"items"`
+			Expirations []model.IPItemExpiration `json:"expirations"`
+		}
+		if err := json.Unmarshal(trimmed, &file); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrIPGroupImportInvalidFile, err)
+		}
+
+		expiry := make(map[string]time.Time, len(file.Expirations))
+		for _, expiration := range file.Expirations {
+			expiry[expiration.Item] = expiration.ExpiresAt
+		}
+		for i, item := range file.Items {
+			result.totalLines++
+			var expiresAt *time.Time
+			if t, ok := expiry[item]; ok {
+				expiresAt = &t
+			}
+			result.addItem(i+1, item, expiresAt, now)
+		}
+		return result, nil
+	}
+
+	var rawItems []json.RawMessage
+	if err := json.Unmarshal(trimmed, &rawItems); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIPGroupImportInvalidFile, err)
+	}
+
+	for i, raw := range rawItems {
+		result.totalLines++
+		line := i + 1
+
+		var item string
+		if err := json.Unmarshal(raw, &item); err == nil {
+			result.addItem(line, item, nil, now)
+			continue
+		}
+
+		var obj jsonImportItem
+		if err := json.Unmarshal(raw, &obj); err != nil {
+			result.addInvalid(line, string(raw), "无法解析的条目")
+			continue
+		}
+		item = obj.Item
+		if item == "" {
+			item = obj.IP
+		}
+		if item == "" {
+			item = obj.CIDR
+		}
+		if item == "" {
+			result.addInvalid(line, string(raw), "缺少 item、ip 或 cidr 字段")
+			continue
+		}
+		result.addItem(line, item, obj.ExpiresAt, now)
+	}
+
+	return result, nil
+}
+
+// ipItemAggregator 对条目去重并合并CIDR
+// 永久条目合并为最少的CIDR集合；带过期时间的条目单独保留，以免合并后过期时一起移除永久地址
+// 同一条目既有永久记录又有过期记录时以永久为准，已被永久CIDR覆盖的过期条目会被丢弃
+type ipItemAggregator struct {
+	permanent map[netip.Prefix]struct{}
+	expiring  map[netip.Prefix]time.Time
+	raw       []string
+	rawExpiry []model.IPItemExpiration
+}
+
+func newIPItemAggregator() *ipItemAggregator {
+	return &ipItemAggregator{
+		permanent: make(map[netip.Prefix]struct{}),
+		expiring:  make(map[netip.Prefix]time.Time),
+	}
+}
+
+// add 添加条目，返回是否为重复条目
+func (a *ipItemAggregator) add(prefix netip.Prefix, expiresAt *time.Time) bool {
+	_, isPermanent := a.permanent[prefix]
+	existing, isExpiring := a.expiring[prefix]
+	duplicate := isPermanent || isExpiring
+
+	switch {
+	case isPermanent:
+	case expiresAt == nil:
+		delete(a.expiring, prefix)
+		a.permanent[prefix] = struct{}{}
+	case !isExpiring || expiresAt.After(existing):
+		// 同一条目多次设置过期时间时取最晚的
+		a.expiring[prefix] = *expiresAt
+	}
+	return duplicate
+}
+
+// keepRaw 保留无法解析的现有条目
+func (a *ipItemAggregator) keepRaw(item string, expiry map[string]time.Time) {
+	a.raw = append(a.raw, item)
+	if t, ok := expiry[item]; ok {
+		a.rawExpiry = append(a.rawExpiry, model.IPItemExpiration{Item: item, ExpiresAt: t})
+	}
+}
+
+// result 返回合并后的条目和过期时间
+func (a *ipItemAggregator) result() ([]string, []model.IPItemExpiration) {
+	permanent := make([]netip.Prefix, 0, len(a.permanent))
+	for prefix := range a.permanent {
+		permanent = append(permanent, prefix)
+	}
+	collapsed := network.CollapsePrefixes(permanent)
+
+	covered := make(map[netip.Prefix]struct{}, len(collapsed))
+	for _, prefix := range collapsed {
+		covered[prefix] = struct{}{}
+	}
+
+	items := make([]string, 0, len(collapsed)+len(a.expiring)+len(a.raw))
+	for _, prefix := range collapsed {
+		items = append(items, network.FormatPrefix(prefix))
+	}
+
+	expiring := make([]netip.Prefix, 0, len(a.expiring))
+	for prefix := range a.expiring {
+		if !coveredBy(prefix, covered) {
+			expiring = append(expiring, prefix)
+		}
+	}
+	sort.Slice(expiring, func(i, j int) bool {
+		if c := expiring[i].Addr().Compare(expiring[j].Addr()); c != 0 {
+			return c < 0
+		}
+		return expiring[i].Bits() < expiring[j].Bits()
+	})
+
+	expirations := make([]model.IPItemExpiration, 0, len(expiring)+len(a.rawExpiry))
+	for _, prefix := range expiring {
+		item := network.FormatPrefix(prefix)
+		items = append(items, item)
+		expirations = append(expirations, model.IPItemExpiration{Item: item, ExpiresAt: a.expiring[prefix]})
+	}
+
+	items = append(items, a.raw...)
+	expirations = append(expirations, a.rawExpiry...)
+	if len(expirations) == 0 {
+		expirations = nil
+	}
+	return items, expirations
+}
+
+// coveredBy 判断前缀是否被集合中的某个前缀包含
+func coveredBy(prefix netip.Prefix, set map[netip.Prefix]struct{}) bool {
+	for bits := prefix.Bits(); bits >= 0; bits-- {
+		parent := netip.PrefixFrom(prefix.Addr(), bits).Masked()
+		if _, ok := set[parent]; ok {
+			return true
+		}
+	}
+	return false
+}
+
+// diffItems 返回在 a 中但不在 b 中的条目（最多 maxImportChangeSample 条）及其总数
+func diffItems(a, b []string) ([]string, int) {
+	set := make(map[string]struct{}, len(b))
+	for _, item := range b {
+		set[item] = struct{}{}
+	}
+
+	var diff []string
+	count := 0
+	for _, item := range a {
+		if _, ok := set[item]; ok {
+			continue
+		}
+		count++
+		if len(diff) < maxImportChangeSample {
+			diff = append(diff, item)
+		}
+	}
+	return diff, count
+}
+
+// exportIPGroupTXT 导出为纯文本，每行一个条目，过期时间以行内注释标注，重新导入时保留过期时间
+func exportIPGroupTXT(ipGroup *model.IPGroup, now time.Time) []byte {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# IP组: %s\n", ipGroup.Name)
+	fmt.Fprintf(&buf, "# 导出时间: %s\n", now.Format(time.RFC3339))
+	fmt.Fprintf(&buf, "# 条目数: %d\n", len(ipGroup.Items))
+
+	expiry := ipGroup.ExpiryMap()
+	for _, item := range ipGroup.Items {
+		buf.WriteString(item)
+		if t, ok := expiry[item]; ok {
+			fmt.Fprintf(&buf, " # expires_at %s", t.Format(time.RFC3339))
+		}
+		buf.WriteByte('\n')
+	}
+	return buf.Bytes()
+}
+
+// exportIPGroupCSV 导出为 CSV，包含 item 和 expires_at 两列
+func exportIPGroupCSV(ipGroup *model.IPGroup) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	if err := writer.Write([]string{"item", "expires_at"}); err != nil {
+		return nil, err
+	}
+	expiry := ipGroup.ExpiryMap()
+	for _, item := range ipGroup.Items {
+		expiresAt := ""
+		if t, ok := expiry[item]; ok {
+			expiresAt = t.Format(time.RFC3339)
+		}
+		if err := writer.Write([]string{item, expiresAt}); err != nil {
+			return nil, err
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// exportIPGroupJSON 导出为 JSON，结构与导入支持的 {"items", "expirations"} 格式一致
+func exportIPGroupJSON(ipGroup *model.IPGroup) ([]byte, error) {
+	items := ipGroup.Items
+	if items == nil {
+		items = []string{}
+	}
+	return json.MarshalIndent(struct {
+		Name        string                   `json:"name"`
+		Items       []string                 `json:"items"`
+		Expirations []model.IPItemExpiration `json:"expirations,omitempty"`
+	}{
+		Name:        ipGroup.Name,
+		Items:       items,
+		Expirations: ipGroup.Expirations,
+	}, "", "  ")
+}
@@ -0,0 +1,323 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// importEntryStrings 将解析结果格式化为 "前缀" 或 "前缀 过期时间" 形式，便于比较
+func importEntryStrings(entries []importEntry) []string {
+	result := make([]string, 0, len(entries))
+	for _, entry := range entries {
+		s := entry.prefix.String()
+		if entry.expiresAt != nil {
+			s += " " + entry.expiresAt.UTC().Format(time.RFC3339)
+		}
+		result = append(result, s)
+	}
+	return result
+}
+
+// TestParseIPGroupImport 测试 txt、csv、json 三种格式的解析，包括注释、表头、过期时间和无效行
+func TestParseIPGroupImport(t *testing.T) {
+	now := time.Date(2025, 6, 1, 0, 0, 0, 0, time.UTC)
+	for _, tt := range []struct {
+		name       string
+		format     string
+		data       string
+		want       []string
+		totalLines int
+		invalid    int
+		err        error
+	}{
+		{
+			name:       "txt 注释和多列",
+			format:     IPGroupFormatTXT,
+			data:       "\xef\xbb\xbf# 注释\n10.0.0.1\n\n10.0.0.0/24 office ; 注释\n2001:db8::1, backup\n// 注释\n::ffff:10.1.0.0/112\n10.0.0.300\n",
+			want:       []string{"10.0.0.1/32", "10.0.0.0/24", "2001:db8::1/128", "10.1.0.0/16"},
+			totalLines: 8,
+			invalid:    1,
+		},
+		{
+			name:       "txt 过期时间注释",
+			format:     IPGroupFormatTXT,
+			data:       "10.0.0.1 # expires_at 2025-07-01T00:00:00Z\n10.0.0.2 # expires_at 2025-05-01T00:00:00Z\n10.0.0.3 # expires_at tomorrow\n10.0.0.4 # 其他注释\n",
+			want:       []string{"10.0.0.1/32 2025-07-01T00:00:00Z", "10.0.0.4/32"},
+			totalLines: 4,
+			invalid:    2,
+		},
+		{
+			name:       "csv 表头和过期时间",
+			format:     IPGroupFormatCSV,
+			data:       "name,ip,expires_at\noffice,10.0.0.0/24,\nvpn,\"10.8.0.1\",2025-07-01T08:00:00+08:00\nbad,10.0.0.2,yesterday\nexpired,10.0.0.3,2025-05-01T00:00:00Z\nempty,,\n# 注释\n",
+			want:       []string{"10.0.0.0/24", "10.8.0.1/32 2025-07-01T00:00:00Z"},
+			totalLines: 7,
+			invalid:    3,
+		},
+		{
+			name:       "csv 没有表头时使用第一列",
+			format:     IPGroupFormatCSV,
+			data:       "10.0.0.1,office\n2001:db8::/32\nnot-an-ip\n",
+			want:       []string{"10.0.0.1/32", "2001:db8::/32"},
+			totalLines: 3,
+			invalid:    1,
+		},
+		{
+			name:       "json 字符串数组",
+			format:     IPGroupFormatJSON,
+			data:       `["10.0.0.1", "2001:db8::/32", "bad"]`,
+			want:       []string{"10.0.0.1/32", "2001:db8::/32"},
+			totalLines: 3,
+			invalid:    1,
+		},
+		{
+			name:       "json 对象数组",
+			format:     IPGroupFormatJSON,
+			data:       `[{"item": "10.0.0.1"}, {"ip": "10.0.0.2", "expiresAt": "2025-07-01T00:00:00Z"}, {"cidr": "10.1.0.0/16"}, {"name": "x"}, 1]`,
+			want:       []string{"10.0.0.1/32", "10.0.0.2/32 2025-07-01T00:00:00Z", "10.1.0.0/16"},
+			totalLines: 5,
+			invalid:    2,
+		},
+		{
+			name:       "json 导出格式",
+			format:     IPGroupFormatJSON,
+			data:       `{"name": "g", "items": ["10.0.0.1", "10.0.0.2"], "expirations": [{"item": "10.0.0.2", "expiresAt": "2025-07-01T00:00:00Z"}]}`,
+			want:       []string{"10.0.0.1/32", "10.0.0.2/32 2025-07-01T00:00:00Z"},
+			totalLines: 2,
+		},
+		{
+			name:   "json 格式错误",
+			format: IPGroupFormatJSON,
+			data:   `["10.0.0.1"`,
+			err:    ErrIPGroupImportInvalidFile,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := parseIPGroupImport(tt.format, []byte(tt.data), now)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("parseIPGroupImport() error = %v, want %v", err, tt.err)
+			}
+			if err != nil {
+				return
+			}
+			if got := importEntryStrings(result.entries); !slices.Equal(got, tt.want) {
+				t.Errorf("entries = %v, want %v", got, tt.want)
+			}
+			if result.totalLines != tt.totalLines || result.invalidLines != tt.invalid || len(result.invalidSamples) != tt.invalid {
+				t.Errorf("totalLines = %d, invalidLines = %d, samples = %v, want %d and %d", result.totalLines, result.invalidLines, result.invalidSamples, tt.totalLines, tt.invalid)
+			}
+		})
+	}
+}
+
+// TestImportFormat 测试优先使用请求参数，其次按文件扩展名判断格式
+func TestImportFormat(t *testing.T) {
+	for _, tt := range []struct {
+		format, filename, want string
+	}{
+		{"", "list.CSV", IPGroupFormatCSV},
+		{"", "list.json", IPGroupFormatJSON},
+		{"", "list", IPGroupFormatTXT},
+		{IPGroupFormatTXT, "list.json", IPGroupFormatTXT},
+	} {
+		if got := importFormat(tt.format, tt.filename); got != tt.want {
+			t.Errorf("importFormat(%q, %q) = %q, want %q", tt.format, tt.filename, got, tt.want)
+		}
+	}
+}
+
+// TestApplyImportModes 测试合并和替换模式下的条目合并、过期时间处理和变更报告
+func TestApplyImportModes(t *testing.T) {
+	later := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	latest := later.Add(24 * time.Hour)
+	newGroup := func() *model.IPGroup {
+		return &model.IPGroup{
+			ID:          bson.NewObjectID(),
+			Name:        "office",
+			Items:       []string{"10.0.0.0/25", "192.168.1.1", "172.16.0.1", "legacy-item"},
+			Expirations: []model.IPItemExpiration{{Item: "192.168.1.1", ExpiresAt: later}, {Item: "172.16.0.1", ExpiresAt: later}},
+		}
+	}
+	data := strings.Join([]string{
+		"10.0.0.128/25",
+		"192.168.1.1",
+		"::ffff:10.0.1.0/120",
+		"2001:db8::1",
+		"2001:db8::1",
+		"172.16.0.1 # expires_at " + latest.Format(time.RFC3339),
+		"10.9.0.0/16 # expires_at " + later.Format(time.RFC3339),
+		"10.9.1.0/24",
+		"10.8.0.5 # expires_at " + later.Format(time.RFC3339),
+		"10.8.0.0/24",
+		"bad",
+	}, "\n")
+
+	s := &IPGroupServiceImpl{logger: zerolog.Nop()}
+	for _, tt := range []struct {
+		mode            string
+		wantItems       []string
+		wantExpirations []model.IPItemExpiration
+		wantAdded       []string
+		wantRemoved     []string
+	}{
+		{
+			mode: IPGroupImportModeMerge,
+			// 10.0.0.0/25、10.0.0.128/25 和 IPv4 映射的 10.0.1.0/24 合并为 /23；192.168.1.1 有永久记录，不再过期；
+			// 被永久的 10.8.0.0/24 覆盖的 10.8.0.5 被丢弃；172.16.0.1 取较晚的过期时间；无法解析的现有条目原样保留
+			wantItems:       []string{"10.0.0.0/23", "10.8.0.0/24", "10.9.1.0/24", "192.168.1.1", "2001:db8::1", "10.9.0.0/16", "172.16.0.1", "legacy-item"},
+			wantExpirations: []model.IPItemExpiration{{Item: "10.9.0.0/16", ExpiresAt: later}, {Item: "172.16.0.1", ExpiresAt: latest}},
+			wantAdded:       []string{"10.0.0.0/23", "10.8.0.0/24", "10.9.1.0/24", "2001:db8::1", "10.9.0.0/16"},
+			wantRemoved:     []string{"10.0.0.0/25"},
+		},
+		{
+			mode: IPGroupImportModeReplace,
+			// 不保留现有的 10.0.0.0/25，导入的 10.0.0.128/25 和 10.0.1.0/24 不能合并
+			wantItems:       []string{"10.0.0.128/25", "10.0.1.0/24", "10.8.0.0/24", "10.9.1.0/24", "192.168.1.1", "2001:db8::1", "10.9.0.0/16", "172.16.0.1"},
+			wantExpirations: []model.IPItemExpiration{{Item: "10.9.0.0/16", ExpiresAt: later}, {Item: "172.16.0.1", ExpiresAt: latest}},
+			wantAdded:       []string{"10.0.0.128/25", "10.0.1.0/24", "10.8.0.0/24", "10.9.1.0/24", "2001:db8::1", "10.9.0.0/16"},
+			wantRemoved:     []string{"10.0.0.0/25", "legacy-item"},
+		},
+	} {
+		t.Run(tt.mode, func(t *testing.T) {
+			ipGroup := newGroup()
+			report, err := s.applyImport(ipGroup, &dto.IPGroupImportRequest{Mode: tt.mode}, "list.txt", strings.NewReader(data))
+			if err != nil {
+				t.Fatalf("applyImport() error = %v", err)
+			}
+			if !slices.Equal(ipGroup.Items, tt.wantItems) {
+				t.Errorf("items = %v, want %v", ipGroup.Items, tt.wantItems)
+			}
+			if !slices.EqualFunc(ipGroup.Expirations, tt.wantExpirations, func(a, b model.IPItemExpiration) bool {
+				return a.Item == b.Item && a.ExpiresAt.Equal(b.ExpiresAt)
+			}) {
+				t.Errorf("expirations = %v, want %v", ipGroup.Expirations, tt.wantExpirations)
+			}
+			if !slices.Equal(report.Added, tt.wantAdded) || report.AddedCount != len(tt.wantAdded) {
+				t.Errorf("added = %v (%d), want %v", report.Added, report.AddedCount, tt.wantAdded)
+			}
+			if !slices.Equal(report.Removed, tt.wantRemoved) || report.RemovedCount != len(tt.wantRemoved) {
+				t.Errorf("removed = %v (%d), want %v", report.Removed, report.RemovedCount, tt.wantRemoved)
+			}
+			if report.Format != IPGroupFormatTXT || report.TotalLines != 11 || report.ValidEntries != 10 || report.InvalidLines != 1 ||
+				report.ItemsBefore != 4 || report.ItemsAfter != len(tt.wantItems) {
+				t.Errorf("report = %+v", report)
+			}
+			// 第二个 2001:db8::1 重复，合并模式下 192.168.1.1 和 172.16.0.1 还与现有条目重复
+			wantDuplicates := 1
+			if tt.mode == IPGroupImportModeMerge {
+				wantDuplicates = 3
+			}
+			if report.DuplicateEntries != wantDuplicates {
+				t.Errorf("duplicates = %d, want %d", report.DuplicateEntries, wantDuplicates)
+			}
+		})
+	}
+
+	if _, err := s.applyImport(newGroup(), &dto.IPGroupImportRequest{}, "list.txt", strings.NewReader("# 空文件\nbad\n")); !errors.Is(err, ErrIPGroupImportNoValidEntry) {
+		t.Errorf("applyImport() error = %v, want ErrIPGroupImportNoValidEntry", err)
+	}
+	large := bytes.Repeat([]byte("10.0.0.1\n"), MaxIPGroupImportSize/9+1)
+	if _, err := s.applyImport(newGroup(), &dto.IPGroupImportRequest{}, "list.txt", bytes.NewReader(large)); !errors.Is(err, ErrIPGroupImportTooLarge) {
+		t.Errorf("applyImport() error = %v, want ErrIPGroupImportTooLarge", err)
+	}
+}
+
+// fakeImportIPGroupRepo 只实现导入使用的方法，记录保存的IP组
+type fakeImportIPGroupRepo struct {
+	repository.IPGroupRepository
+	group   model.IPGroup
+	updated []model.IPGroup
+}
+
+func (r *fakeImportIPGroupRepo) GetIPGroupByID(ctx context.Context, id bson.ObjectID) (*model.IPGroup, error) {
+	if id != r.group.ID {
+		return nil, repository.ErrIPGroupNotFound
+	}
+	group := r.group
+	group.Items = slices.Clone(r.group.Items)
+	return &group, nil
+}
+
+func (r *fakeImportIPGroupRepo) UpdateIPGroup(ctx context.Context, ipGroup *model.IPGroup) error {
+	r.updated = append(r.updated, *ipGroup)
+	return nil
+}
+
+// TestImportIPGroupDryRun 测试预览只返回变更报告不保存，正式导入保存合并后的条目
+func TestImportIPGroupDryRun(t *testing.T) {
+	repo := &fakeImportIPGroupRepo{group: model.IPGroup{ID: bson.NewObjectID(), Name: "office", Items: []string{"10.0.0.0/25"}}}
+	s := &IPGroupServiceImpl{ipGroupRepo: repo, logger: zerolog.Nop()}
+
+	report, err := s.ImportIPGroup(context.Background(), repo.group.ID, &dto.IPGroupImportRequest{DryRun: true}, "list.txt", strings.NewReader("10.0.0.128/25\n"))
+	if err != nil {
+		t.Fatalf("ImportIPGroup() error = %v", err)
+	}
+	if !report.DryRun || !slices.Equal(report.Added, []string{"10.0.0.0/24"}) || !slices.Equal(report.Removed, []string{"10.0.0.0/25"}) {
+		t.Errorf("dry run report = %+v", report)
+	}
+	if len(repo.updated) != 0 {
+		t.Errorf("dry run saved ip group: %+v", repo.updated)
+	}
+
+	if _, err := s.ImportIPGroup(context.Background(), repo.group.ID, &dto.IPGroupImportRequest{}, "list.txt", strings.NewReader("10.0.0.128/25\n")); err != nil {
+		t.Fatalf("ImportIPGroup() error = %v", err)
+	}
+	if len(repo.updated) != 1 || !slices.Equal(repo.updated[0].Items, []string{"10.0.0.0/24"}) {
+		t.Errorf("saved ip groups = %+v, want items [10.0.0.0/24]", repo.updated)
+	}
+
+	if _, err := s.ImportIPGroup(context.Background(), bson.NewObjectID(), &dto.IPGroupImportRequest{}, "list.txt", strings.NewReader("10.0.0.1\n")); !errors.Is(err, ErrIPGroupNotFound) {
+		t.Errorf("ImportIPGroup() error = %v, want ErrIPGroupNotFound", err)
+	}
+}
+
+// TestIPGroupExportRoundTrip 测试三种格式导出后重新导入得到相同的条目和过期时间
+func TestIPGroupExportRoundTrip(t *testing.T) {
+	later := time.Now().Add(24 * time.Hour).UTC().Truncate(time.Second)
+	group := &model.IPGroup{
+		Name:        "office",
+		Items:       []string{"10.0.0.0/24", "2001:db8::1", "192.168.1.1"},
+		Expirations: []model.IPItemExpiration{{Item: "192.168.1.1", ExpiresAt: later}},
+	}
+
+	csvData, err := exportIPGroupCSV(group)
+	if err != nil {
+		t.Fatalf("exportIPGroupCSV() error = %v", err)
+	}
+	jsonData, err := exportIPGroupJSON(group)
+	if err != nil {
+		t.Fatalf("exportIPGroupJSON() error = %v", err)
+	}
+	s := &IPGroupServiceImpl{logger: zerolog.Nop()}
+	for format, data := range map[string][]byte{
+		IPGroupFormatTXT:  exportIPGroupTXT(group, time.Now()),
+		IPGroupFormatCSV:  csvData,
+		IPGroupFormatJSON: jsonData,
+	} {
+		imported := &model.IPGroup{Name: group.Name}
+		report, err := s.applyImport(imported, &dto.IPGroupImportRequest{Format: format, Mode: IPGroupImportModeReplace}, "", bytes.NewReader(data))
+		if err != nil {
+			t.Fatalf("%s: applyImport() error = %v", format, err)
+		}
+		if report.InvalidLines != 0 {
+			t.Errorf("%s: invalid lines = %v", format, report.InvalidSamples)
+		}
+		if !slices.Equal(imported.Items, group.Items) {
+			t.Errorf("%s: items = %v, want %v", format, imported.Items, group.Items)
+		}
+		if len(imported.Expirations) != 1 || imported.Expirations[0].Item != "192.168.1.1" || !imported.Expirations[0].ExpiresAt.Equal(later) {
+			t.Errorf("%s: expirations = %v", format, imported.Expirations)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/config"
+)
+
+// Timeout 单次发送通知的超时时间
+const Timeout = 30 * time.Second
+
+// Message 告警通知
+type Message struct {
+	Event   string    `json:"event"`          // 事件类型，如 certificate.expiry
+	Subject string    `json:"subject"`        // 标题
+	Body    string    `json:"body"`           // 正文，纯文本
+	Data    any       `json:"data,omitempty"` // 事件数据，Webhook 以 JSON 格式发送
+	Time    time.Time `json:"time"`           // 事件时间
+}
+
+// Notifier 发送告警通知
+type Notifier interface {
+	Notify(ctx context.Context, msg Message) error
+}
+
+// Multi 依次通过多个通知渠道发送，单个渠道失败不影响其他渠道，返回所有失败原因
+type Multi []Notifier
+
+func (m Multi) Notify(ctx context.Context, msg Message) error {
+	var errs []error
+	for _, n := range m {
+		if err := n.Notify(ctx, msg); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// New 根据通知配置创建通知渠道，没有配置任何渠道时返回 nil
+func New(cfg config.NotifyConfig) Notifier {
+	var notifiers Multi
+	if cfg.WebhookURL != "" {
+		notifiers = append(notifiers, NewWebhook(cfg.WebhookURL))
+	}
+	if cfg.SMTPAddr != "" && cfg.SMTPFrom != "" && len(cfg.SMTPTo) > 0 {
+		notifiers = append(notifiers, &SMTP{
+			Addr:     cfg.SMTPAddr,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+			To:       cfg.SMTPTo,
+		})
+	}
+	if len(notifiers) == 0 {
+		return nil
+	}
+	return notifiers
+}
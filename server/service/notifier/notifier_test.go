@@ -0,0 +1,188 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestWebhookNotify 测试向本地 HTTP 服务发送 Webhook 通知
+func TestWebhookNotify(t *testing.T) {
+	var received Message
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			t.Errorf("Method = %s, want POST", r.Method)
+		}
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", r.Header.Get("Content-Type"))
+		}
+		if err := json.NewDecoder(r.Body).Decode(&received); err != nil {
+			t.Errorf("decode body: %v", err)
+		}
+		if received.Event == "fail" {
+			http.Error(w, "bad event", http.StatusBadRequest)
+		}
+	}))
+	defer server.Close()
+
+	msg := Message{
+		Event:   "certificate.expiry",
+		Subject: "证书即将过期",
+		Body:    "example.com 将在 7 天后过期",
+		Data:    map[string]string{"name": "example.com"},
+		Time:    time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+	}
+	if err := NewWebhook(server.URL).Notify(context.Background(), msg); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	if received.Event != msg.Event || received.Subject != msg.Subject || received.Body != msg.Body {
+		t.Errorf("received = %+v, want %+v", received, msg)
+	}
+
+	msg.Event = "fail"
+	err := NewWebhook(server.URL).Notify(context.Background(), msg)
+	if err == nil || !strings.Contains(err.Error(), "bad event") {
+		t.Errorf("Notify() error = %v, want non-2xx error", err)
+	}
+}
+
+// smtpStub 只实现发送邮件所需的最小 SMTP 会话，不支持 STARTTLS 和认证
+type smtpStub struct {
+	listener net.Listener
+	from     string
+	to       []string
+	data     string
+	done     chan struct{}
+}
+
+func newSMTPStub(t *testing.T) *smtpStub {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	stub := &smtpStub{listener: listener, done: make(chan struct{})}
+	go stub.serve()
+	return stub
+}
+
+func (s *smtpStub) serve() {
+	defer close(s.done)
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(line string) { conn.Write([]byte(line + "\r\n")) }
+	reply("220 localhost ESMTP stub")
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		line = strings.TrimRight(line, "\r\n")
+		command := strings.ToUpper(line)
+		switch {
+		case strings.HasPrefix(command, "EHLO"), strings.HasPrefix(command, "HELO"):
+			reply("250 localhost")
+		case strings.HasPrefix(command, "MAIL FROM:"):
+			s.from = strings.Trim(line[len("MAIL FROM:"):], "<> ")
+			reply("250 OK")
+		case strings.HasPrefix(command, "RCPT TO:"):
+			s.to = append(s.to, strings.Trim(line[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case command == "DATA":
+			reply("354 End data with <CR><LF>.<CR><LF>")
+			var data strings.Builder
+			for {
+				line, err := reader.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if line == ".\r\n" {
+					break
+				}
+				data.WriteString(line)
+			}
+			s.data = data.String()
+			reply("250 OK")
+		case command == "QUIT":
+			reply("221 Bye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// TestSMTPNotify 测试通过本地 SMTP 服务发送邮件通知
+func TestSMTPNotify(t *testing.T) {
+	stub := newSMTPStub(t)
+	defer stub.listener.Close()
+
+	notifier := &SMTP{
+		Addr: stub.listener.Addr().String(),
+		From: "waf@example.com",
+		To:   []string{"ops@example.com", "admin@example.com"},
+	}
+	msg := Message{
+		Event:   "certificate.expiry",
+		Subject: "证书即将过期",
+		Body:    "example.com 将在 7 天后过期",
+		Time:    time.Date(2026, 10, 19, 0, 0, 0, 0, time.UTC),
+	}
+	if err := notifier.Notify(context.Background(), msg); err != nil {
+		t.Fatalf("Notify() error = %v", err)
+	}
+	<-stub.done
+
+	if stub.from != notifier.From {
+		t.Errorf("MAIL FROM = %q, want %q", stub.from, notifier.From)
+	}
+	if strings.Join(stub.to, ",") != strings.Join(notifier.To, ",") {
+		t.Errorf("RCPT TO = %v, want %v", stub.to, notifier.To)
+	}
+
+	header, body, ok := strings.Cut(stub.data, "\r\n\r\n")
+	if !ok {
+		t.Fatalf("mail has no header separator: %q", stub.data)
+	}
+	if !strings.Contains(header, "To: ops@example.com, admin@example.com\r\n") {
+		t.Errorf("header missing To: %q", header)
+	}
+	if !strings.Contains(header, "Subject: =?UTF-8?b?") {
+		t.Errorf("subject is not encoded: %q", header)
+	}
+	decoded, err := base64.StdEncoding.DecodeString(strings.ReplaceAll(body, "\r\n", ""))
+	if err != nil {
+		t.Fatalf("decode body: %v", err)
+	}
+	if string(decoded) != msg.Body {
+		t.Errorf("body = %q, want %q", decoded, msg.Body)
+	}
+}
+
+// TestMultiNotify 测试单个渠道失败时仍然通过其他渠道发送
+func TestMultiNotify(t *testing.T) {
+	var calls int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls++
+	}))
+	defer server.Close()
+
+	multi := Multi{NewWebhook("http://127.0.0.1:0/unreachable"), NewWebhook(server.URL)}
+	if err := multi.Notify(context.Background(), Message{Event: "test"}); err == nil {
+		t.Error("Notify() should return the failed channel error")
+	}
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1, a failed channel should not stop the others", calls)
+	}
+}
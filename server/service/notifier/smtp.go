@@ -0,0 +1,101 @@
+package notifier
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"fmt"
+	"mime"
+	"net"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// SMTP 通过邮件发送通知，服务器支持时使用 STARTTLS，465 端口使用 TLS 连接
+type SMTP struct {
+	Addr     string   // 服务器地址，如 smtp.example.com:587
+	Username string   // 用户名，为空时不认证
+	Password string   // 密码
+	From     string   // 发件人地址
+	To       []string // 收件人地址
+}
+
+func (s *SMTP) Notify(ctx context.Context, msg Message) error {
+	host, port, err := net.SplitHostPort(s.Addr)
+	if err != nil {
+		return fmt.Errorf("SMTP 服务器地址 %s 无效: %w", s.Addr, err)
+	}
+
+	dialer := &net.Dialer{Timeout: Timeout}
+	conn, err := dialer.DialContext(ctx, "tcp", s.Addr)
+	if err != nil {
+		return fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(Timeout)
+	}
+	conn.SetDeadline(deadline)
+	if port == "465" {
+		conn = tls.Client(conn, &tls.Config{ServerName: host})
+	}
+
+	client, err := smtp.NewClient(conn, host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("连接 SMTP 服务器失败: %w", err)
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok {
+		if err := client.StartTLS(&tls.Config{ServerName: host}); err != nil {
+			return fmt.Errorf("SMTP STARTTLS 失败: %w", err)
+		}
+	}
+	if s.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", s.Username, s.Password, host)); err != nil {
+			return fmt.Errorf("SMTP 认证失败: %w", err)
+		}
+	}
+
+	if err := client.Mail(s.From); err != nil {
+		return fmt.Errorf("SMTP 设置发件人失败: %w", err)
+	}
+	for _, to := range s.To {
+		if err := client.Rcpt(to); err != nil {
+			return fmt.Errorf("SMTP 设置收件人 %s 失败: %w", to, err)
+		}
+	}
+	writer, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("SMTP 发送邮件失败: %w", err)
+	}
+	if _, err := writer.Write(s.buildMail(msg)); err != nil {
+		return fmt.Errorf("SMTP 发送邮件失败: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return fmt.Errorf("SMTP 发送邮件失败: %w", err)
+	}
+	return client.Quit()
+}
+
+// buildMail 生成纯文本邮件，标题和正文使用 UTF-8 编码
+func (s *SMTP) buildMail(msg Message) []byte {
+	var b strings.Builder
+	b.WriteString("From: " + s.From + "\r\n")
+	b.WriteString("To: " + strings.Join(s.To, ", ") + "\r\n")
+	b.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", msg.Subject) + "\r\n")
+	b.WriteString("Date: " + msg.Time.Format(time.RFC1123Z) + "\r\n")
+	b.WriteString("MIME-Version: 1.0\r\n")
+	b.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	b.WriteString("Content-Transfer-Encoding: base64\r\n\r\n")
+
+	body := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(body) > 76 {
+		b.WriteString(body[:76] + "\r\n")
+		body = body[76:]
+	}
+	b.WriteString(body + "\r\n")
+	return []byte(b.String())
+}
@@ -0,0 +1,48 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Webhook 将通知以 JSON 格式 POST 到指定地址，响应状态码为 2xx 时视为成功
+type Webhook struct {
+	URL    string
+	Client *http.Client
+}
+
+// NewWebhook 创建 Webhook 通知渠道
+func NewWebhook(url string) *Webhook {
+	return &Webhook{
+		URL:    url,
+		Client: &http.Client{Timeout: Timeout},
+	}
+}
+
+func (w *Webhook) Notify(ctx context.Context, msg Message) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("编码 Webhook 通知失败: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, w.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := w.Client.Do(req)
+	if err != nil {
+		return fmt.Errorf("发送 Webhook 通知失败: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode/100 != 2 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return fmt.Errorf("Webhook 返回 %s: %s", resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
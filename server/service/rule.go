@@ -5,7 +5,9 @@ import (
 	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
 	"strconv"
+	"time"
 
 	"github.com/HUAHUAI23/RuiQi/pkg/model"
 	"github.com/HUAHUAI23/RuiQi/server/config"
@@ -24,29 +26,45 @@ var (
 	ErrMicroRuleNameExists = errors.New("微规则名称已存在")
 	ErrSystemRuleNoMod     = errors.New("系统默认规则不允许修改")
 	ErrSystemRuleNoDelete  = errors.New("系统默认规则不允许删除")
+	ErrInvalidRuleSchedule = errors.New("规则生效计划无效")
+	ErrIPGroupRefNotFound  = errors.New("规则引用的IP组不存在")
 )
 
 // MicroRuleService 微规则服务接口
 type MicroRuleService interface {
 	CreateMicroRule(ctx context.Context, req *dto.MicroRuleCreateRequest) (*model.MicroRule, error)
-	GetMicroRules(ctx context.Context, pageStr, sizeStr string) ([]model.MicroRule, int64, error)
+	GetMicroRules(ctx context.Context, req *dto.MicroRuleListRequest) ([]model.MicroRule, int64, error)
 	GetMicroRuleByID(ctx context.Context, id bson.ObjectID) (*model.MicroRule, error)
 	UpdateMicroRule(ctx context.Context, id bson.ObjectID, req *dto.MicroRuleUpdateRequest) (*model.MicroRule, error)
 	DeleteMicroRule(ctx context.Context, id bson.ObjectID) error
+	GetMicroRuleHitStats(ctx context.Context, rules []model.MicroRule) (map[string]dto.RuleHitStats, error)
+	AnalyzeMicroRules(ctx context.Context) (*dto.RuleAnalysisResponse, error)
+	GetMicroRuleOffload(ctx context.Context) (*dto.RuleOffloadResponse, error)
 }
 
 // MicroRuleServiceImpl 微规则服务实现
 type MicroRuleServiceImpl struct {
-	ruleRepo repository.MicroRuleRepository
-	logger   zerolog.Logger
+	ruleRepo      repository.MicroRuleRepository
+	ruleStatsRepo repository.RuleStatsRepository
+	siteRepo      repository.SiteRepository
+	ipGroupRepo   repository.IPGroupRepository
+	logger        zerolog.Logger
 }
 
 // NewMicroRuleService 创建微规则服务
-func NewMicroRuleService(ruleRepo repository.MicroRuleRepository) MicroRuleService {
+func NewMicroRuleService(
+	ruleRepo repository.MicroRuleRepository,
+	ruleStatsRepo repository.RuleStatsRepository,
+	siteRepo repository.SiteRepository,
+	ipGroupRepo repository.IPGroupRepository,
+) MicroRuleService {
 	logger := config.GetServiceLogger("microrule")
 	return &MicroRuleServiceImpl{
-		ruleRepo: ruleRepo,
-		logger:   logger,
+		ruleRepo:      ruleRepo,
+		ruleStatsRepo: ruleStatsRepo,
+		siteRepo:      siteRepo,
+		ipGroupRepo:   ipGroupRepo,
+		logger:        logger,
 	}
 }
 
@@ -83,17 +101,38 @@ func (s *MicroRuleServiceImpl) CreateMicroRule(ctx context.Context, req *dto.Mic
 		condition = bsonData
 	}
 
+	// 校验条件引用的IP组
+	ipGroupRefs, err := s.resolveIPGroupRefs(ctx, condition)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验站点作用域
+	scope, err := buildSiteScope(ctx, s.siteRepo, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	// 校验生效计划
+	schedule, err := buildRuleSchedule(req.Schedule)
+	if err != nil {
+		return nil, err
+	}
+
 	// 创建新微规则
 	rule := &model.MicroRule{
-		Name:      req.Name,
-		Type:      model.RuleType(req.Type),
-		Status:    model.RuleStatus(req.Status),
-		Priority:  req.Priority,
-		Condition: condition,
+		Name:        req.Name,
+		Type:        model.RuleType(req.Type),
+		Status:      model.RuleStatus(req.Status),
+		Priority:    req.Priority,
+		Condition:   condition,
+		IPGroupRefs: ipGroupRefs,
+		Scope:       scope,
+		Schedule:    schedule,
 	}
 
 	// 保存微规则
-	err := s.ruleRepo.CreateMicroRule(ctx, rule)
+	err = s.ruleRepo.CreateMicroRule(ctx, rule)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("创建微规则失败")
 		return nil, err
@@ -104,18 +143,23 @@ func (s *MicroRuleServiceImpl) CreateMicroRule(ctx context.Context, req *dto.Mic
 }
 
 // GetMicroRules 获取微规则列表
-func (s *MicroRuleServiceImpl) GetMicroRules(ctx context.Context, pageStr, sizeStr string) ([]model.MicroRule, int64, error) {
-	page, err := strconv.ParseInt(pageStr, 10, 64)
+func (s *MicroRuleServiceImpl) GetMicroRules(ctx context.Context, req *dto.MicroRuleListRequest) ([]model.MicroRule, int64, error) {
+	page, err := strconv.ParseInt(req.Page, 10, 64)
 	if err != nil || page < 1 {
 		page = 1
 	}
 
-	size, err := strconv.ParseInt(sizeStr, 10, 64)
+	size, err := strconv.ParseInt(req.Size, 10, 64)
 	if err != nil || size < 1 {
 		size = 10
 	}
 
-	rules, total, err := s.ruleRepo.GetMicroRules(ctx, page, size)
+	scopeFilter, err := buildSiteScopeFilter(ctx, s.siteRepo, req.SiteID, req.IncludeGlobal)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	rules, total, err := s.ruleRepo.GetMicroRules(ctx, page, size, scopeFilter)
 	if err != nil {
 		s.logger.Error().Err(err).Msg("获取微规则列表失败")
 		return nil, 0, err
@@ -192,7 +236,27 @@ func (s *MicroRuleServiceImpl) UpdateMicroRule(ctx context.Context, id bson.Obje
 			return nil, err
 		}
 
+		ipGroupRefs, err := s.resolveIPGroupRefs(ctx, bsonData)
+		if err != nil {
+			return nil, err
+		}
+
 		rule.Condition = bsonData
+		rule.IPGroupRefs = ipGroupRefs
+	}
+	if req.Scope != nil {
+		scope, err := buildSiteScope(ctx, s.siteRepo, req.Scope)
+		if err != nil {
+			return nil, err
+		}
+		rule.Scope = scope
+	}
+	if req.Schedule != nil {
+		schedule, err := buildRuleSchedule(req.Schedule)
+		if err != nil {
+			return nil, err
+		}
+		rule.Schedule = schedule
 	}
 
 	// 保存更新
@@ -233,3 +297,65 @@ func (s *MicroRuleServiceImpl) DeleteMicroRule(ctx context.Context, id bson.Obje
 	s.logger.Info().Str("id", id.Hex()).Msg("微规则删除成功")
 	return nil
 }
+
+// GetMicroRuleHitStats 获取微规则的累计命中统计，返回以规则ID(hex)为键的映射
+func (s *MicroRuleServiceImpl) GetMicroRuleHitStats(ctx context.Context, rules []model.MicroRule) (map[string]dto.RuleHitStats, error) {
+	ruleIDs := make([]string, 0, len(rules))
+	for _, rule := range rules {
+		ruleIDs = append(ruleIDs, rule.ID.Hex())
+	}
+
+	stats, err := s.ruleStatsRepo.GetRuleTotals(ctx, model.RuleStatsSourceMicro, ruleIDs)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("获取微规则命中统计失败")
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// resolveIPGroupRefs 提取条件引用的IP组名称，并校验IP组均存在
+func (s *MicroRuleServiceImpl) resolveIPGroupRefs(ctx context.Context, condition bson.Raw) ([]string, error) {
+	refs := model.ConditionIPGroupRefs(condition)
+	for _, name := range refs {
+		if _, err := s.ipGroupRepo.GetIPGroupByName(ctx, name); err != nil {
+			if errors.Is(err, repository.ErrIPGroupNotFound) {
+				return nil, fmt.Errorf("%w: %s", ErrIPGroupRefNotFound, name)
+			}
+			return nil, err
+		}
+	}
+	return refs, nil
+}
+
+// buildRuleSchedule 将生效计划请求转换为模型并校验，未设置任何限制时返回 nil
+func buildRuleSchedule(req *dto.RuleScheduleRequest) (*model.RuleSchedule, error) {
+	if req == nil {
+		return nil, nil
+	}
+
+	schedule := &model.RuleSchedule{
+		ActiveFrom:  req.ActiveFrom,
+		ActiveUntil: req.ActiveUntil,
+		Timezone:    req.Timezone,
+	}
+	for _, window := range req.Windows {
+		days := make([]time.Weekday, 0, len(window.Days))
+		for _, day := range window.Days {
+			days = append(days, time.Weekday(day))
+		}
+		schedule.Windows = append(schedule.Windows, model.WeeklyWindow{
+			Days:  days,
+			Start: window.Start,
+			End:   window.End,
+		})
+	}
+
+	if err := schedule.Validate(); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidRuleSchedule, err)
+	}
+	if schedule.IsEmpty() {
+		return nil, nil
+	}
+	return schedule, nil
+}
@@ -0,0 +1,730 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"net/netip"
+	"reflect"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/pkg/utils/network"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// 规则分析问题类型
+const (
+	RuleFindingShadowed         = "shadowed"          // 被更高优先级的规则完全覆盖，永远不会命中
+	RuleFindingConflict         = "conflict"          // 白名单和黑名单规则条件相同
+	RuleFindingDuplicate        = "duplicate"         // 相同类型的规则条件相同
+	RuleFindingUnreachable      = "unreachable"       // 条件永远不会满足
+	RuleFindingInvalidRegex     = "invalid_regex"     // 正则表达式无法编译
+	RuleFindingInvalidCondition = "invalid_condition" // 条件结构错误或引用了不存在的IP组，匹配时会出错
+)
+
+// 规则分析问题严重程度
+const (
+	RuleSeverityError   = "error"
+	RuleSeverityWarning = "warning"
+	RuleSeverityInfo    = "info"
+)
+
+// 条件目标，与规则引擎一致
+const (
+	conditionTargetIP   = "source_ip"
+	conditionTargetURL  = "url"
+	conditionTargetPath = "path"
+)
+
+// 条件匹配方式，与规则引擎一致
+const (
+	matchEqual         = "equal"
+	matchNotEqual      = "not_equal"
+	matchFuzzy         = "fuzzy"
+	matchInCIDR        = "in_cidr"
+	matchNotInCIDR     = "not_in_cidr"
+	matchInclude       = "include"
+	matchContains      = "contains"
+	matchNotContains   = "not_contains"
+	matchPrefixKeyword = "prefix_keyword"
+	matchRegex         = "regex"
+)
+
+var (
+	ipMatchTypes = map[string]bool{
+		matchEqual: true, matchNotEqual: true, matchFuzzy: true, matchInCIDR: true, matchNotInCIDR: true,
+		model.MatchTypeInIPGroup: true, model.MatchTypeNotInIPGroup: true,
+	}
+	stringMatchTypes = map[string]bool{
+		matchEqual: true, matchNotEqual: true, matchInclude: true, matchContains: true,
+		matchNotContains: true, matchPrefixKeyword: true, matchRegex: true,
+	}
+	// negatedMatchTypes 可以取反的匹配方式
+	negatedMatchTypes = map[string]string{
+		matchEqual:                  matchNotEqual,
+		matchNotEqual:               matchEqual,
+		matchContains:               matchNotContains,
+		matchNotContains:            matchContains,
+		matchInCIDR:                 matchNotInCIDR,
+		matchNotInCIDR:              matchInCIDR,
+		model.MatchTypeInIPGroup:    model.MatchTypeNotInIPGroup,
+		model.MatchTypeNotInIPGroup: model.MatchTypeInIPGroup,
+	}
+	severityRank = map[string]int{RuleSeverityError: 0, RuleSeverityWarning: 1, RuleSeverityInfo: 2}
+)
+
+// AnalyzeMicroRules 静态分析所有微规则，报告被覆盖、冲突、重复、不可达和无效的规则
+func (s *MicroRuleServiceImpl) AnalyzeMicroRules(ctx context.Context) (*dto.RuleAnalysisResponse, error) {
+	rules, err := s.ruleRepo.GetAllMicroRules(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("获取微规则失败")
+		return nil, err
+	}
+
+	ipGroups, err := s.getReferencedIPGroups(ctx, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	result := analyzeRules(rules, ipGroups, time.Now())
+	s.logger.Info().Int("rules", result.RuleCount).Int("findings", len(result.Findings)).Msg("微规则分析完成")
+	return result, nil
+}
+
+// getReferencedIPGroups 获取规则条件引用的IP组，不存在的IP组被忽略
+func (s *MicroRuleServiceImpl) getReferencedIPGroups(ctx context.Context, rules []model.MicroRule) ([]model.IPGroup, error) {
+	refSet := make(map[string]struct{})
+	for _, rule := range rules {
+		for _, name := range model.ConditionIPGroupRefs(rule.Condition) {
+			refSet[name] = struct{}{}
+		}
+	}
+	refs := make([]string, 0, len(refSet))
+	for name := range refSet {
+		refs = append(refs, name)
+	}
+
+	ipGroups, err := s.ipGroupRepo.GetIPGroupsByNames(ctx, refs)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("获取规则引用的IP组失败")
+		return nil, err
+	}
+	return ipGroups, nil
+}
+
+// conditionNode 用于分析的条件树
+type conditionNode struct {
+	composite bool
+	operator  string // AND 或 OR，复合条件使用
+	children  []*conditionNode
+	target    string // 简单条件使用
+	matchType string
+	value     string
+}
+
+// analyzedRule 分析中的规则
+type analyzedRule struct {
+	rule      *model.MicroRule
+	condition *conditionNode
+	canonical string
+}
+
+// ruleAnalyzer 规则分析器，只做保守判断：报告的覆盖关系一定成立，但不保证找出所有覆盖关系
+type ruleAnalyzer struct {
+	ipGroups map[string]*model.IPGroup
+	now      time.Time
+	regexes  map[string]*regexp.Regexp
+	findings []dto.RuleAnalysisFinding
+}
+
+// analyzeRules 分析规则列表，规则按规则引擎的匹配顺序比较，与传入的顺序无关
+func analyzeRules(rules []model.MicroRule, ipGroups []model.IPGroup, now time.Time) *dto.RuleAnalysisResponse {
+	rules = slices.Clone(rules)
+	slices.SortStableFunc(rules, func(a, b model.MicroRule) int {
+		return model.CompareMicroRuleOrder(&a, &b)
+	})
+
+	a := &ruleAnalyzer{
+		ipGroups: make(map[string]*model.IPGroup, len(ipGroups)),
+		now:      now,
+		regexes:  make(map[string]*regexp.Regexp),
+	}
+	for i := range ipGroups {
+		a.ipGroups[ipGroups[i].Name] = &ipGroups[i]
+	}
+
+	result := &dto.RuleAnalysisResponse{RuleCount: len(rules)}
+
+	// 逐条检查条件本身，只有条件有效且可达的启用规则参与两两比较
+	var active []*analyzedRule
+	for i := range rules {
+		rule := &rules[i]
+		enabled := rule.Status == model.RuleEnabled
+		if enabled {
+			result.EnabledCount++
+		}
+
+		condition, err := parseConditionNode(rule.Condition)
+		if err != nil {
+			a.add(RuleFindingInvalidCondition, invalidSeverity(enabled), fmt.Sprintf("条件无效: %v", err), rule)
+			continue
+		}
+		if !a.checkValues(rule, condition, enabled) || !enabled {
+			continue
+		}
+		if a.unsatisfiable(condition) {
+			a.add(RuleFindingUnreachable, RuleSeverityWarning, "条件永远不会满足，规则不会命中", rule)
+			continue
+		}
+
+		active = append(active, &analyzedRule{rule: rule, condition: condition, canonical: condition.canonical()})
+	}
+
+	for i, lower := range active {
+		for _, higher := range active[:i] {
+			if a.comparePair(higher, lower) {
+				break
+			}
+		}
+	}
+
+	sort.SliceStable(a.findings, func(i, j int) bool {
+		return severityRank[a.findings[i].Severity] < severityRank[a.findings[j].Severity]
+	})
+	for _, finding := range a.findings {
+		switch finding.Severity {
+		case RuleSeverityError:
+			result.Summary.Errors++
+		case RuleSeverityWarning:
+			result.Summary.Warnings++
+		default:
+			result.Summary.Infos++
+		}
+	}
+	result.Findings = a.findings
+	if result.Findings == nil {
+		result.Findings = []dto.RuleAnalysisFinding{}
+	}
+	return result
+}
+
+// comparePair 比较高优先级规则和低优先级规则，返回低优先级规则是否已确定永远不会命中
+func (a *ruleAnalyzer) comparePair(higher, lower *analyzedRule) bool {
+	dominates := scopeCovers(higher.rule.Scope, lower.rule.Scope) && scheduleCovers(higher.rule.Schedule, lower.rule.Schedule)
+	sameType := higher.rule.Type == lower.rule.Type
+
+	if higher.canonical == lower.canonical {
+		if !dominates && !scopesOverlap(higher.rule.Scope, lower.rule.Scope) {
+			return false
+		}
+		if sameType {
+			severity, msg := RuleSeverityInfo, "与更高优先级的规则条件相同，作用域或生效计划不同"
+			if dominates {
+				severity, msg = RuleSeverityWarning, "与更高优先级的规则条件相同且类型相同，规则不会命中"
+			}
+			a.add(RuleFindingDuplicate, severity, msg, lower.rule, higher.rule)
+		} else {
+			severity, msg := RuleSeverityWarning, fmt.Sprintf("与更高优先级的%s规则条件相同，在作用域重叠部分不会命中", ruleTypeName(higher.rule.Type))
+			if dominates {
+				severity, msg = RuleSeverityError, fmt.Sprintf("与更高优先级的%s规则条件相同，规则不会命中", ruleTypeName(higher.rule.Type))
+			}
+			a.add(RuleFindingConflict, severity, msg, lower.rule, higher.rule)
+		}
+		return dominates
+	}
+
+	if !dominates || !a.implies(lower.condition, higher.condition) {
+		return false
+	}
+
+	severity := RuleSeverityWarning
+	if !sameType {
+		severity = RuleSeverityError
+	}
+	msg := fmt.Sprintf("匹配的请求都会先被更高优先级的%s规则匹配，规则不会命中", ruleTypeName(higher.rule.Type))
+	a.add(RuleFindingShadowed, severity, msg, lower.rule, higher.rule)
+	return true
+}
+
+// checkValues 检查条件中的匹配值，条件在匹配时会出错则返回 false
+func (a *ruleAnalyzer) checkValues(rule *model.MicroRule, node *conditionNode, enabled bool) bool {
+	if node.composite {
+		valid := true
+		for _, child := range node.children {
+			valid = a.checkValues(rule, child, enabled) && valid
+		}
+		return valid
+	}
+
+	switch node.matchType {
+	case matchRegex:
+		if _, ok := a.regex(node.value); !ok {
+			a.add(RuleFindingInvalidRegex, invalidSeverity(enabled), fmt.Sprintf("无效的正则表达式: %s", node.value), rule)
+			return false
+		}
+	case matchInCIDR, matchNotInCIDR:
+		if !strings.Contains(node.value, "/") {
+			a.add(RuleFindingInvalidCondition, invalidSeverity(enabled), fmt.Sprintf("无效的CIDR: %s", node.value), rule)
+			return false
+		}
+		if _, ok := network.ParsePrefix(node.value); !ok {
+			a.add(RuleFindingInvalidCondition, invalidSeverity(enabled), fmt.Sprintf("无效的CIDR: %s", node.value), rule)
+			return false
+		}
+	case model.MatchTypeInIPGroup, model.MatchTypeNotInIPGroup:
+		if _, ok := a.ipGroups[node.value]; !ok {
+			a.add(RuleFindingInvalidCondition, invalidSeverity(enabled), fmt.Sprintf("引用的IP组不存在: %s", node.value), rule)
+			return false
+		}
+	}
+	return true
+}
+
+// unsatisfiable 判断条件是否永远不会满足
+func (a *ruleAnalyzer) unsatisfiable(node *conditionNode) bool {
+	if !node.composite {
+		switch node.matchType {
+		case model.MatchTypeInIPGroup:
+			return len(a.activeItems(node.value)) == 0
+		case matchEqual:
+			// 规则引擎只匹配有效的IP地址
+			if node.target == conditionTargetIP {
+				_, err := netip.ParseAddr(node.value)
+				return err != nil
+			}
+		}
+		return a.always(node.negate())
+	}
+
+	if node.operator == logicalAND {
+		for i, child := range node.children {
+			if a.unsatisfiable(child) {
+				return true
+			}
+			// 两个子条件互斥
+			for _, other := range node.children[i+1:] {
+				if negated := other.negate(); negated != nil && a.implies(child, negated) {
+					return true
+				}
+				if negated := child.negate(); negated != nil && a.implies(other, negated) {
+					return true
+				}
+			}
+		}
+		return false
+	}
+
+	for _, child := range node.children {
+		if !a.unsatisfiable(child) {
+			return false
+		}
+	}
+	return true
+}
+
+// always 判断条件是否总是满足
+func (a *ruleAnalyzer) always(node *conditionNode) bool {
+	if node == nil {
+		return false
+	}
+	if !node.composite {
+		switch node.matchType {
+		case model.MatchTypeNotInIPGroup:
+			return len(a.activeItems(node.value)) == 0
+		case matchContains, matchPrefixKeyword:
+			return node.value == ""
+		}
+		return false
+	}
+
+	if node.operator == logicalAND {
+		for _, child := range node.children {
+			if !a.always(child) {
+				return false
+			}
+		}
+		return true
+	}
+
+	for i, child := range node.children {
+		if a.always(child) {
+			return true
+		}
+		// 同时包含某个条件和它的否定
+		for _, other := range node.children[i+1:] {
+			if negated := other.negate(); negated != nil && negated.canonical() == child.canonical() {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// implies 判断满足条件 b 的请求是否一定满足条件 c
+func (a *ruleAnalyzer) implies(b, c *conditionNode) bool {
+	if a.always(c) || b.canonical() == c.canonical() {
+		return true
+	}
+
+	if b.composite && b.operator != logicalAND {
+		for _, child := range b.children {
+			if !a.implies(child, c) {
+				return false
+			}
+		}
+		return true
+	}
+	if c.composite && c.operator == logicalAND {
+		for _, child := range c.children {
+			if !a.implies(b, child) {
+				return false
+			}
+		}
+		return true
+	}
+	if c.composite {
+		for _, child := range c.children {
+			if a.implies(b, child) {
+				return true
+			}
+		}
+	}
+	if b.composite {
+		for _, child := range b.children {
+			if a.implies(child, c) {
+				return true
+			}
+		}
+		return false
+	}
+	if c.composite {
+		return false
+	}
+
+	return a.simpleImplies(b, c)
+}
+
+// simpleImplies 判断满足简单条件 b 的请求是否一定满足简单条件 c
+func (a *ruleAnalyzer) simpleImplies(b, c *conditionNode) bool {
+	if b.target != c.target {
+		return false
+	}
+
+	if b.target == conditionTargetIP {
+		return a.ipImplies(b, c)
+	}
+
+	switch c.matchType {
+	case matchNotEqual:
+		return b.matchType == matchEqual && b.value != c.value
+	case matchContains:
+		return (b.matchType == matchEqual || b.matchType == matchContains || b.matchType == matchPrefixKeyword) &&
+			strings.Contains(b.value, c.value)
+	case matchNotContains:
+		return (b.matchType == matchEqual && !strings.Contains(b.value, c.value)) ||
+			(b.matchType == matchNotContains && strings.Contains(c.value, b.value))
+	case matchPrefixKeyword:
+		return (b.matchType == matchEqual || b.matchType == matchPrefixKeyword) && strings.HasPrefix(b.value, c.value)
+	case matchRegex:
+		re, ok := a.regex(c.value)
+		return ok && b.matchType == matchEqual && re.MatchString(b.value)
+	}
+	return false
+}
+
+// ipImplies 判断源IP条件之间的蕴含关系
+func (a *ruleAnalyzer) ipImplies(b, c *conditionNode) bool {
+	var bPrefix netip.Prefix
+	switch b.matchType {
+	case matchEqual:
+		addr, err := netip.ParseAddr(b.value)
+		if err != nil {
+			return false
+		}
+		bPrefix = netip.PrefixFrom(addr, addr.BitLen())
+	case matchInCIDR:
+		bPrefix, _ = network.ParsePrefix(b.value)
+	}
+
+	switch c.matchType {
+	case matchNotEqual:
+		if b.matchType == matchEqual {
+			return b.value != c.value
+		}
+		if bPrefix.IsValid() {
+			addr, err := netip.ParseAddr(c.value)
+			return err == nil && !bPrefix.Contains(addr)
+		}
+	case matchInCIDR:
+		cPrefix, _ := network.ParsePrefix(c.value)
+		return bPrefix.IsValid() && prefixCovers(cPrefix, bPrefix)
+	case matchNotInCIDR:
+		cPrefix, _ := network.ParsePrefix(c.value)
+		if bPrefix.IsValid() {
+			return !bPrefix.Overlaps(cPrefix)
+		}
+		if b.matchType == matchNotInCIDR {
+			outer, _ := network.ParsePrefix(b.value)
+			return prefixCovers(outer, cPrefix)
+		}
+	case model.MatchTypeInIPGroup:
+		// 有作用域或会过期的条目随请求和时间变化，只使用全局IP组中的永久条目判断
+		group := a.ipGroups[c.value]
+		if !bPrefix.IsValid() || group == nil || !group.Scope.IsGlobal() {
+			return false
+		}
+		expiry := group.ExpiryMap()
+		for _, item := range group.Items {
+			if _, expiring := expiry[item]; expiring {
+				continue
+			}
+			if prefix, ok := network.ParsePrefix(item); ok && prefixCovers(prefix, bPrefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// activeItems 返回IP组中未过期的条目
+func (a *ruleAnalyzer) activeItems(name string) []string {
+	group := a.ipGroups[name]
+	if group == nil {
+		return nil
+	}
+	expiry := group.ExpiryMap()
+	var items []string
+	for _, item := range group.Items {
+		if expiresAt, ok := expiry[item]; ok && !a.now.Before(expiresAt) {
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+func (a *ruleAnalyzer) regex(pattern string) (*regexp.Regexp, bool) {
+	if re, ok := a.regexes[pattern]; ok {
+		return re, re != nil
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		re = nil
+	}
+	a.regexes[pattern] = re
+	return re, re != nil
+}
+
+// add 记录问题，rules 第一条为受影响的规则，其余为相关规则
+func (a *ruleAnalyzer) add(kind, severity, message string, rules ...*model.MicroRule) {
+	finding := dto.RuleAnalysisFinding{
+		Kind:      kind,
+		Severity:  severity,
+		Message:   message,
+		RuleIDs:   make([]string, 0, len(rules)),
+		RuleNames: make([]string, 0, len(rules)),
+	}
+	for _, rule := range rules {
+		finding.RuleIDs = append(finding.RuleIDs, rule.ID.Hex())
+		finding.RuleNames = append(finding.RuleNames, rule.Name)
+	}
+	a.findings = append(a.findings, finding)
+}
+
+const logicalAND = "AND"
+
+// parseConditionNode 解析条件，校验结构是否能被规则引擎接受
+func parseConditionNode(raw bson.Raw) (*conditionNode, error) {
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("条件为空")
+	}
+
+	var base struct {
+		Type       string     `bson:"type"`
+		Operator   string     `bson:"operator"`
+		Conditions []bson.Raw `bson:"conditions"`
+		Target     string     `bson:"target"`
+		MatchType  string     `bson:"match_type"`
+		MatchValue string     `bson:"match_value"`
+	}
+	if err := bson.Unmarshal(raw, &base); err != nil {
+		return nil, fmt.Errorf("解析条件失败: %v", err)
+	}
+
+	switch base.Type {
+	case model.ConditionTypeSimple:
+		var valid bool
+		switch base.Target {
+		case conditionTargetIP:
+			valid = ipMatchTypes[base.MatchType]
+		case conditionTargetURL, conditionTargetPath:
+			valid = stringMatchTypes[base.MatchType]
+		default:
+			return nil, fmt.Errorf("不支持的目标类型: %s", base.Target)
+		}
+		if !valid {
+			return nil, fmt.Errorf("%s 不支持匹配方式: %s", base.Target, base.MatchType)
+		}
+		matchType := base.MatchType
+		if matchType == matchInclude {
+			matchType = matchContains
+		}
+		return &conditionNode{target: base.Target, matchType: matchType, value: base.MatchValue}, nil
+
+	case model.ConditionTypeComposite:
+		if len(base.Conditions) == 0 {
+			return nil, fmt.Errorf("复合条件没有子条件")
+		}
+		// 规则引擎将 AND 以外的操作符都按 OR 处理
+		operator := "OR"
+		if base.Operator == logicalAND {
+			operator = logicalAND
+		}
+		node := &conditionNode{composite: true, operator: operator}
+		for _, rawChild := range base.Conditions {
+			child, err := parseConditionNode(rawChild)
+			if err != nil {
+				return nil, err
+			}
+			// 展开相同操作符的嵌套复合条件
+			if child.composite && child.operator == operator {
+				node.children = append(node.children, child.children...)
+			} else {
+				node.children = append(node.children, child)
+			}
+		}
+		if len(node.children) == 1 {
+			return node.children[0], nil
+		}
+		return node, nil
+
+	default:
+		return nil, fmt.Errorf("不支持的条件类型: %s", base.Type)
+	}
+}
+
+// canonical 返回条件的规范形式，语义相同的条件（子条件顺序不同、CIDR 写法不同）规范形式相同
+func (n *conditionNode) canonical() string {
+	if !n.composite {
+		value := n.value
+		if n.matchType == matchInCIDR || n.matchType == matchNotInCIDR {
+			if prefix, ok := network.ParsePrefix(value); ok {
+				value = prefix.String()
+			}
+		}
+		return n.target + "|" + n.matchType + "|" + value
+	}
+
+	parts := make([]string, 0, len(n.children))
+	seen := make(map[string]bool, len(n.children))
+	for _, child := range n.children {
+		part := child.canonical()
+		if !seen[part] {
+			seen[part] = true
+			parts = append(parts, part)
+		}
+	}
+	if len(parts) == 1 {
+		return parts[0]
+	}
+	sort.Strings(parts)
+	return n.operator + "(" + strings.Join(parts, ",") + ")"
+}
+
+// negate 返回简单条件的否定，无法表示时返回 nil
+func (n *conditionNode) negate() *conditionNode {
+	if n.composite {
+		return nil
+	}
+	matchType, ok := negatedMatchTypes[n.matchType]
+	if !ok {
+		return nil
+	}
+	return &conditionNode{target: n.target, matchType: matchType, value: n.value}
+}
+
+// prefixCovers 判断前缀 outer 是否包含前缀 inner
+func prefixCovers(outer, inner netip.Prefix) bool {
+	return outer.IsValid() && inner.IsValid() && outer.Bits() <= inner.Bits() && outer.Contains(inner.Addr())
+}
+
+// scopeCovers 判断作用域 outer 是否包含作用域 inner
+func scopeCovers(outer, inner *model.SiteScope) bool {
+	if outer.IsGlobal() {
+		return true
+	}
+	if inner.IsGlobal() {
+		return false
+	}
+	for _, siteID := range inner.SiteIDs {
+		if !containsString(outer.SiteIDs, siteID) {
+			return false
+		}
+	}
+	for _, host := range inner.Hosts {
+		covered := false
+		for _, pattern := range outer.Hosts {
+			if strings.EqualFold(pattern, host) || (!strings.HasPrefix(host, "*.") && model.MatchHostPattern(pattern, host)) {
+				covered = true
+				break
+			}
+		}
+		if !covered {
+			return false
+		}
+	}
+	return true
+}
+
+// scopesOverlap 判断两个作用域是否可能同时作用于某个站点
+func scopesOverlap(a, b *model.SiteScope) bool {
+	if a.IsGlobal() || b.IsGlobal() {
+		return true
+	}
+	for _, siteID := range a.SiteIDs {
+		if containsString(b.SiteIDs, siteID) {
+			return true
+		}
+	}
+	for _, host := range a.Hosts {
+		for _, other := range b.Hosts {
+			if strings.EqualFold(host, other) || model.MatchHostPattern(host, other) || model.MatchHostPattern(other, host) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// scheduleCovers 判断生效计划 inner 生效时 outer 是否一定生效
+func scheduleCovers(outer, inner *model.RuleSchedule) bool {
+	return outer.IsEmpty() || reflect.DeepEqual(outer, inner)
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+func invalidSeverity(enabled bool) string {
+	if enabled {
+		return RuleSeverityError
+	}
+	return RuleSeverityWarning
+}
+
+func ruleTypeName(ruleType model.RuleType) string {
+	if ruleType == model.WhitelistRule {
+		return "白名单"
+	}
+	return "黑名单"
+}
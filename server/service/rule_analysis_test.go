@@ -0,0 +1,332 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// analysisRule 测试用规则，ID 在 analysisRules 中按创建顺序生成
+type analysisRule struct {
+	name      string
+	ruleType  model.RuleType
+	priority  int
+	condition bson.D
+	disabled  bool
+	scope     *model.SiteScope
+	schedule  *model.RuleSchedule
+}
+
+// analysisRules 按定义顺序生成递增的ID，与规则创建的先后顺序一致
+func analysisRules(t *testing.T, defs []analysisRule) []model.MicroRule {
+	t.Helper()
+	rules := make([]model.MicroRule, 0, len(defs))
+	for i, def := range defs {
+		condition, err := bson.Marshal(def.condition)
+		if err != nil {
+			t.Fatalf("marshal condition of %s: %v", def.name, err)
+		}
+		status := model.RuleEnabled
+		if def.disabled {
+			status = model.RuleDisabled
+		}
+		rules = append(rules, model.MicroRule{
+			ID:        bson.ObjectID{11: byte(i + 1)},
+			Name:      def.name,
+			Type:      def.ruleType,
+			Status:    status,
+			Priority:  def.priority,
+			Condition: condition,
+			Scope:     def.scope,
+			Schedule:  def.schedule,
+		})
+	}
+	return rules
+}
+
+// TestAnalyzeRules 测试被覆盖、冲突、重复、不可达和无效规则的判断，结果与传入规则的顺序无关
+func TestAnalyzeRules(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	const (
+		white = model.WhitelistRule
+		black = model.BlacklistRule
+	)
+	adminPrefix := model.NewSimpleCondition("path", "prefix_keyword", "/admin")
+	officeCIDR := model.NewSimpleCondition("source_ip", "in_cidr", "10.0.0.0/8")
+	office := model.IPGroup{Name: "office", Items: []string{"10.0.0.0/8", "192.168.1.1"}}
+	workHours := &model.RuleSchedule{Windows: []model.WeeklyWindow{{Start: "09:00", End: "18:00"}}}
+
+	for _, tt := range []struct {
+		name     string
+		rules    []analysisRule
+		ipGroups []model.IPGroup
+		// want 每项为 "类型/严重程度/受影响的规则<相关规则"
+		want []string
+	}{
+		{
+			name: "前缀覆盖同类型规则",
+			rules: []analysisRule{
+				{name: "admin", ruleType: black, priority: 10, condition: adminPrefix},
+				{name: "users", ruleType: black, priority: 5, condition: model.NewSimpleCondition("path", "equal", "/admin/users")},
+			},
+			want: []string{"shadowed/warning/users<admin"},
+		},
+		{
+			name: "白名单覆盖黑名单",
+			rules: []analysisRule{
+				{name: "office", ruleType: white, priority: 10, condition: officeCIDR},
+				{name: "host", ruleType: black, priority: 5, condition: model.NewSimpleCondition("source_ip", "equal", "10.1.2.3")},
+			},
+			want: []string{"shadowed/error/host<office"},
+		},
+		{
+			name: "低优先级规则范围更大",
+			rules: []analysisRule{
+				{name: "users", ruleType: black, priority: 10, condition: model.NewSimpleCondition("path", "equal", "/admin/users")},
+				{name: "admin", ruleType: black, priority: 5, condition: adminPrefix},
+			},
+		},
+		{
+			name: "AND 条件的一个子条件被 OR 条件覆盖",
+			rules: []analysisRule{
+				{name: "paths", ruleType: white, priority: 10, condition: model.NewCompositeCondition("OR", adminPrefix, model.NewSimpleCondition("path", "prefix_keyword", "/api"))},
+				{name: "api", ruleType: black, priority: 5, condition: model.NewCompositeCondition("AND", officeCIDR, model.NewSimpleCondition("path", "equal", "/api/v1"))},
+			},
+			want: []string{"shadowed/error/api<paths"},
+		},
+		{
+			name: "OR 条件的每个分支都被覆盖",
+			rules: []analysisRule{
+				{name: "paths", ruleType: black, priority: 10, condition: model.NewCompositeCondition("OR", adminPrefix, model.NewSimpleCondition("path", "prefix_keyword", "/api"))},
+				{name: "either", ruleType: black, priority: 5, condition: model.NewCompositeCondition("OR",
+					model.NewSimpleCondition("path", "equal", "/admin/login"),
+					model.NewSimpleCondition("path", "prefix_keyword", "/api/v2"),
+				)},
+			},
+			want: []string{"shadowed/warning/either<paths"},
+		},
+		{
+			name: "OR 条件只有部分分支被覆盖",
+			rules: []analysisRule{
+				{name: "admin", ruleType: black, priority: 10, condition: adminPrefix},
+				{name: "either", ruleType: black, priority: 5, condition: model.NewCompositeCondition("OR",
+					model.NewSimpleCondition("path", "equal", "/admin/login"),
+					model.NewSimpleCondition("path", "equal", "/login"),
+				)},
+			},
+		},
+		{
+			name: "更高优先级的 AND 条件更严格",
+			rules: []analysisRule{
+				{name: "office-admin", ruleType: white, priority: 10, condition: model.NewCompositeCondition("AND", officeCIDR, adminPrefix)},
+				{name: "admin", ruleType: black, priority: 5, condition: adminPrefix},
+			},
+		},
+		{
+			name: "复合条件子条件顺序和CIDR写法不同的冲突",
+			rules: []analysisRule{
+				{name: "allow", ruleType: white, priority: 10, condition: model.NewCompositeCondition("AND", officeCIDR, adminPrefix)},
+				{name: "deny", ruleType: black, priority: 5, condition: model.NewCompositeCondition("AND",
+					adminPrefix,
+					model.NewSimpleCondition("source_ip", "in_cidr", "10.1.2.3/8"),
+				)},
+			},
+			want: []string{"conflict/error/deny<allow"},
+		},
+		{
+			name: "嵌套的同类复合条件展开后相同",
+			rules: []analysisRule{
+				{name: "flat", ruleType: black, priority: 10, condition: model.NewCompositeCondition("AND", officeCIDR, adminPrefix, model.NewSimpleCondition("url", "contains", "debug"))},
+				{name: "nested", ruleType: black, priority: 5, condition: model.NewCompositeCondition("AND",
+					model.NewCompositeCondition("AND", model.NewSimpleCondition("url", "include", "debug"), adminPrefix),
+					model.NewCompositeCondition("OR", officeCIDR),
+				)},
+			},
+			want: []string{"duplicate/warning/nested<flat"},
+		},
+		{
+			name: "作用域部分重叠的冲突",
+			rules: []analysisRule{
+				{name: "allow", ruleType: white, priority: 10, condition: adminPrefix, scope: &model.SiteScope{Hosts: []string{"*.example.com"}}},
+				{name: "deny", ruleType: black, priority: 5, condition: adminPrefix, scope: &model.SiteScope{Hosts: []string{"api.example.com", "example.org"}}},
+			},
+			want: []string{"conflict/warning/deny<allow"},
+		},
+		{
+			name: "作用域不重叠",
+			rules: []analysisRule{
+				{name: "allow", ruleType: white, priority: 10, condition: adminPrefix, scope: &model.SiteScope{SiteIDs: []string{"a"}}},
+				{name: "deny", ruleType: black, priority: 5, condition: adminPrefix, scope: &model.SiteScope{SiteIDs: []string{"b"}}},
+			},
+		},
+		{
+			name: "有作用域的规则不覆盖全局规则",
+			rules: []analysisRule{
+				{name: "admin", ruleType: black, priority: 10, condition: adminPrefix, scope: &model.SiteScope{SiteIDs: []string{"a"}}},
+				{name: "users", ruleType: black, priority: 5, condition: model.NewSimpleCondition("path", "equal", "/admin/users")},
+			},
+		},
+		{
+			name: "生效计划不同的重复规则",
+			rules: []analysisRule{
+				{name: "work-hours", ruleType: black, priority: 10, condition: adminPrefix, schedule: workHours},
+				{name: "always", ruleType: black, priority: 5, condition: adminPrefix},
+			},
+			want: []string{"duplicate/info/always<work-hours"},
+		},
+		{
+			name: "生效计划相同的重复规则",
+			rules: []analysisRule{
+				{name: "first", ruleType: black, priority: 10, condition: adminPrefix, schedule: workHours},
+				{name: "second", ruleType: black, priority: 5, condition: adminPrefix, schedule: workHours},
+			},
+			want: []string{"duplicate/warning/second<first"},
+		},
+		{
+			name: "相同优先级先创建的规则先匹配",
+			rules: []analysisRule{
+				{name: "allow", ruleType: white, priority: 10, condition: adminPrefix},
+				{name: "deny", ruleType: black, priority: 10, condition: adminPrefix},
+			},
+			want: []string{"conflict/error/deny<allow"},
+		},
+		{
+			name: "相同优先级先创建的规则更窄",
+			rules: []analysisRule{
+				{name: "users", ruleType: black, priority: 10, condition: model.NewSimpleCondition("path", "equal", "/admin/users")},
+				{name: "admin", ruleType: white, priority: 10, condition: adminPrefix},
+			},
+		},
+		{
+			name: "后创建的规则优先级更高",
+			rules: []analysisRule{
+				{name: "deny", ruleType: black, priority: 5, condition: adminPrefix},
+				{name: "login", ruleType: white, priority: 5, condition: model.NewSimpleCondition("path", "equal", "/admin/login")},
+				{name: "allow", ruleType: white, priority: 20, condition: adminPrefix},
+			},
+			want: []string{"conflict/error/deny<allow", "shadowed/warning/login<allow"},
+		},
+		{
+			name: "只报告第一条覆盖的规则",
+			rules: []analysisRule{
+				{name: "admin", ruleType: black, priority: 10, condition: adminPrefix},
+				{name: "users", ruleType: black, priority: 8, condition: model.NewSimpleCondition("path", "prefix_keyword", "/admin/users")},
+				{name: "user", ruleType: black, priority: 5, condition: model.NewSimpleCondition("path", "equal", "/admin/users/1")},
+			},
+			want: []string{"shadowed/warning/users<admin", "shadowed/warning/user<admin"},
+		},
+		{
+			name: "禁用的规则不覆盖其他规则",
+			rules: []analysisRule{
+				{name: "admin", ruleType: white, priority: 10, condition: adminPrefix, disabled: true},
+				{name: "users", ruleType: black, priority: 5, condition: model.NewSimpleCondition("path", "equal", "/admin/users")},
+			},
+		},
+		{
+			name: "IP组的永久条目覆盖",
+			rules: []analysisRule{
+				{name: "office", ruleType: white, priority: 10, condition: model.NewSimpleCondition("source_ip", model.MatchTypeInIPGroup, "office")},
+				{name: "host", ruleType: black, priority: 5, condition: model.NewSimpleCondition("source_ip", "in_cidr", "10.1.0.0/16")},
+			},
+			ipGroups: []model.IPGroup{office},
+			want:     []string{"shadowed/error/host<office"},
+		},
+		{
+			name: "IP组的条目会过期",
+			rules: []analysisRule{
+				{name: "office", ruleType: white, priority: 10, condition: model.NewSimpleCondition("source_ip", model.MatchTypeInIPGroup, "office")},
+				{name: "host", ruleType: black, priority: 5, condition: model.NewSimpleCondition("source_ip", "equal", "10.1.2.3")},
+			},
+			ipGroups: []model.IPGroup{{
+				Name:        "office",
+				Items:       office.Items,
+				Expirations: []model.IPItemExpiration{{Item: "10.0.0.0/8", ExpiresAt: now.Add(time.Hour)}},
+			}},
+		},
+		{
+			name: "IP组有作用域",
+			rules: []analysisRule{
+				{name: "office", ruleType: white, priority: 10, condition: model.NewSimpleCondition("source_ip", model.MatchTypeInIPGroup, "office")},
+				{name: "host", ruleType: black, priority: 5, condition: model.NewSimpleCondition("source_ip", "equal", "10.1.2.3")},
+			},
+			ipGroups: []model.IPGroup{{Name: "office", Items: office.Items, Scope: &model.SiteScope{SiteIDs: []string{"a"}}}},
+		},
+		{
+			name: "不可达的条件",
+			rules: []analysisRule{
+				{name: "never", ruleType: black, priority: 10, condition: model.NewCompositeCondition("AND",
+					model.NewSimpleCondition("source_ip", "equal", "10.1.2.3"),
+					model.NewSimpleCondition("source_ip", "not_equal", "10.1.2.3"),
+				)},
+				{name: "empty-group", ruleType: black, priority: 5, condition: model.NewSimpleCondition("source_ip", model.MatchTypeInIPGroup, "expired")},
+			},
+			ipGroups: []model.IPGroup{{
+				Name:        "expired",
+				Items:       []string{"10.0.0.1"},
+				Expirations: []model.IPItemExpiration{{Item: "10.0.0.1", ExpiresAt: now}},
+			}},
+			want: []string{"unreachable/warning/never", "unreachable/warning/empty-group"},
+		},
+		{
+			name: "无效的条件不参与比较",
+			rules: []analysisRule{
+				{name: "bad-regex", ruleType: white, priority: 10, condition: model.NewSimpleCondition("path", "regex", "^/admin/(")},
+				{name: "missing-group", ruleType: white, priority: 8, condition: model.NewSimpleCondition("source_ip", model.MatchTypeInIPGroup, "missing")},
+				{name: "disabled-regex", ruleType: black, priority: 6, condition: model.NewSimpleCondition("path", "regex", "("), disabled: true},
+				{name: "admin", ruleType: black, priority: 5, condition: adminPrefix},
+			},
+			want: []string{"invalid_regex/error/bad-regex", "invalid_condition/error/missing-group", "invalid_regex/warning/disabled-regex"},
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			rules := analysisRules(t, tt.rules)
+			for _, order := range []string{"定义顺序", "倒序"} {
+				input := slices.Clone(rules)
+				if order == "倒序" {
+					slices.Reverse(input)
+				}
+
+				result := analyzeRules(input, tt.ipGroups, now)
+				var got []string
+				for _, finding := range result.Findings {
+					got = append(got, fmt.Sprintf("%s/%s/%s", finding.Kind, finding.Severity, strings.Join(finding.RuleNames, "<")))
+				}
+				if !slices.Equal(got, tt.want) {
+					t.Errorf("%s: findings = %q, want %q", order, got, tt.want)
+				}
+				if summary := result.Summary; summary.Errors+summary.Warnings+summary.Infos != len(result.Findings) {
+					t.Errorf("%s: summary = %+v, findings = %d", order, summary, len(result.Findings))
+				}
+			}
+		})
+	}
+}
+
+// TestAnalyzeRulesCounts 测试规则总数、启用数和按严重程度的统计
+func TestAnalyzeRulesCounts(t *testing.T) {
+	rules := analysisRules(t, []analysisRule{
+		{name: "allow", ruleType: model.WhitelistRule, priority: 10, condition: model.NewSimpleCondition("path", "equal", "/a")},
+		{name: "deny", ruleType: model.BlacklistRule, priority: 5, condition: model.NewSimpleCondition("path", "equal", "/a")},
+		{name: "deny-again", ruleType: model.BlacklistRule, priority: 5, condition: model.NewSimpleCondition("path", "contains", "/b"), scope: &model.SiteScope{SiteIDs: []string{"a"}}},
+		{name: "deny-b", ruleType: model.BlacklistRule, priority: 1, condition: model.NewSimpleCondition("path", "contains", "/b")},
+		{name: "off", ruleType: model.BlacklistRule, priority: 1, condition: model.NewSimpleCondition("path", "regex", "("), disabled: true},
+	})
+
+	result := analyzeRules(rules, nil, time.Now())
+	if result.RuleCount != 5 || result.EnabledCount != 4 {
+		t.Errorf("counts = %d/%d, want 5/4", result.RuleCount, result.EnabledCount)
+	}
+	if result.Summary.Errors != 1 || result.Summary.Warnings != 1 || result.Summary.Infos != 1 {
+		t.Errorf("summary = %+v, want 1 error, 1 warning and 1 info", result.Summary)
+	}
+	for i := 1; i < len(result.Findings); i++ {
+		if severityRank[result.Findings[i-1].Severity] > severityRank[result.Findings[i].Severity] {
+			t.Errorf("findings not sorted by severity: %+v", result.Findings)
+		}
+	}
+}
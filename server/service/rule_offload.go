@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"time"
+
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/service/daemon/haproxy"
+)
+
+// GetMicroRuleOffload 按当前的微规则和IP组编译卸载到 HAProxy 的规则，返回每条规则的卸载状态
+// 与应用站点配置和热重载时的编译方式一致，结果在下次应用站点配置或热重载后生效
+func (s *MicroRuleServiceImpl) GetMicroRuleOffload(ctx context.Context) (*dto.RuleOffloadResponse, error) {
+	rules, err := s.ruleRepo.GetAllMicroRules(ctx)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("获取微规则失败")
+		return nil, err
+	}
+
+	ipGroups, err := s.getReferencedIPGroups(ctx, rules)
+	if err != nil {
+		return nil, err
+	}
+
+	offload := haproxy.CompileMicroRules(rules, ipGroups, time.Now())
+	result := &dto.RuleOffloadResponse{
+		RuleCount:      len(rules),
+		OffloadedCount: offload.OffloadedCount(),
+		Rules:          make([]dto.RuleOffloadItem, len(offload.Rules)),
+	}
+	for i, status := range offload.Rules {
+		result.Rules[i] = dto.RuleOffloadItem{
+			RuleID:    status.RuleID,
+			RuleName:  status.RuleName,
+			Offloaded: status.Offloaded,
+			Reason:    status.Reason,
+		}
+	}
+	return result, nil
+}
@@ -6,16 +6,20 @@ import (
 	"fmt"
 
 	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
 	cornjob "github.com/HUAHUAI23/RuiQi/server/service/cornjob/haproxy"
 	"github.com/HUAHUAI23/RuiQi/server/service/daemon"
+	"github.com/HUAHUAI23/RuiQi/server/service/daemon/haproxy"
+	"github.com/HUAHUAI23/RuiQi/server/utils/diff"
 	"github.com/haproxytech/client-native/v6/models"
 	"github.com/rs/zerolog"
 )
 
 // 定义错误
 var (
-	ErrRunnerNotRunning     = errors.New("运行器未在运行")
-	ErrRunnerAlreadyRunning = errors.New("运行器已在运行")
+	ErrRunnerNotRunning      = errors.New("运行器未在运行")
+	ErrRunnerAlreadyRunning  = errors.New("运行器已在运行")
+	ErrConfigVersionNotFound = haproxy.ErrConfigVersionNotFound
 )
 
 // RunnerService 运行器服务接口
@@ -31,6 +35,18 @@ type RunnerService interface {
 	Reload(ctx context.Context) error
 	// get haproxy stats
 	GetStats() (models.NativeStats, error)
+	// 获取HAProxy运行时API，运行器未运行时返回 ErrRunnerNotRunning
+	GetRuntimeAPI() (haproxy.RuntimeAPI, error)
+
+	// HAProxy 配置版本管理
+	ListConfigVersions(ctx context.Context) ([]dto.ConfigVersionResponse, error)
+	DiffConfigVersions(ctx context.Context, from, to string) (*dto.ConfigVersionDiffResponse, error)
+	RollbackConfig(ctx context.Context, id string) error
+
+	// 将站点变更增量应用到 HAProxy，运行器未运行时返回 ErrRunnerNotRunning
+	ApplySites(ctx context.Context) (*haproxy.SiteApplyResult, error)
+	// 通过运行时 API 热加载站点证书的变更，运行器未运行时返回 ErrRunnerNotRunning
+	UpdateCertificates(ctx context.Context) ([]string, error)
 }
 
 // RunnerServiceImpl 运行器服务实现
@@ -170,3 +186,105 @@ func (s *RunnerServiceImpl) Reload(ctx context.Context) error {
 func (s *RunnerServiceImpl) GetStats() (models.NativeStats, error) {
 	return s.runner.GetStats()
 }
+
+// GetRuntimeAPI 获取HAProxy运行时API
+func (s *RunnerServiceImpl) GetRuntimeAPI() (haproxy.RuntimeAPI, error) {
+	if s.runner.GetState() != daemon.ServiceRunning {
+		return nil, ErrRunnerNotRunning
+	}
+	return s.runner.GetRuntimeAPI()
+}
+
+// ListConfigVersions 按时间倒序返回已生效过的 HAProxy 配置版本，第一个为当前生效的版本
+func (s *RunnerServiceImpl) ListConfigVersions(ctx context.Context) ([]dto.ConfigVersionResponse, error) {
+	versions, err := s.runner.ListConfigVersions()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("获取配置版本列表失败")
+		return nil, fmt.Errorf("获取配置版本列表失败: %w", err)
+	}
+
+	items := make([]dto.ConfigVersionResponse, 0, len(versions))
+	for i, version := range versions {
+		items = append(items, dto.ConfigVersionResponse{
+			ID:        version.ID,
+			Reason:    version.Reason,
+			Checksum:  version.Checksum,
+			CreatedAt: version.CreatedAt,
+			Active:    i == 0,
+		})
+	}
+	return items, nil
+}
+
+// DiffConfigVersions 比较两个配置版本的 haproxy.cfg，to 为空时与当前生效的版本比较
+func (s *RunnerServiceImpl) DiffConfigVersions(ctx context.Context, from, to string) (*dto.ConfigVersionDiffResponse, error) {
+	if to == "" {
+		versions, err := s.runner.ListConfigVersions()
+		if err != nil {
+			return nil, fmt.Errorf("获取配置版本列表失败: %w", err)
+		}
+		if len(versions) == 0 {
+			return nil, ErrConfigVersionNotFound
+		}
+		to = versions[0].ID
+	}
+
+	fromContent, err := s.runner.GetConfigVersionContent(from)
+	if err != nil {
+		return nil, err
+	}
+	toContent, err := s.runner.GetConfigVersionContent(to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &dto.ConfigVersionDiffResponse{
+		From: from,
+		To:   to,
+		Diff: diff.Unified(fromContent, toContent, from+"/haproxy.cfg", to+"/haproxy.cfg", 3),
+	}, nil
+}
+
+// RollbackConfig 回滚到指定的 HAProxy 配置版本
+func (s *RunnerServiceImpl) RollbackConfig(ctx context.Context, id string) error {
+	if s.runner.GetState() != daemon.ServiceRunning {
+		return ErrRunnerNotRunning
+	}
+
+	if err := s.runner.RollbackConfig(id); err != nil {
+		if errors.Is(err, ErrConfigVersionNotFound) {
+			return err
+		}
+		s.logger.Error().Err(err).Str("version", id).Msg("回滚配置失败")
+		return fmt.Errorf("回滚配置失败: %w", err)
+	}
+	return nil
+}
+
+// ApplySites 将站点变更增量应用到 HAProxy
+func (s *RunnerServiceImpl) ApplySites(ctx context.Context) (*haproxy.SiteApplyResult, error) {
+	if s.runner.GetState() != daemon.ServiceRunning {
+		return nil, ErrRunnerNotRunning
+	}
+
+	result, err := s.runner.ApplySites()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("应用站点配置失败")
+		return nil, fmt.Errorf("应用站点配置失败: %w", err)
+	}
+	return result, nil
+}
+
+// UpdateCertificates 通过 HAProxy 运行时 API 热加载站点证书的变更，不重新加载配置
+func (s *RunnerServiceImpl) UpdateCertificates(ctx context.Context) ([]string, error) {
+	if s.runner.GetState() != daemon.ServiceRunning {
+		return nil, ErrRunnerNotRunning
+	}
+
+	updated, err := s.runner.UpdateCertificates()
+	if err != nil {
+		s.logger.Error().Err(err).Msg("热加载证书失败")
+		return updated, fmt.Errorf("热加载证书失败: %w", err)
+	}
+	return updated, nil
+}
@@ -0,0 +1,92 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrScopeSiteNotFound = errors.New("作用域中的站点不存在")
+)
+
+// buildSiteScope 将作用域请求转换为模型，校验站点是否存在并去重
+// 请求为空或站点ID和主机名模式均为空时返回 nil，表示对所有站点生效
+func buildSiteScope(ctx context.Context, siteRepo repository.SiteRepository, req *dto.SiteScopeRequest) (*model.SiteScope, error) {
+	if req == nil {
+		return nil, nil
+	}
+
+	scope := &model.SiteScope{}
+	seen := make(map[string]struct{})
+	for _, siteID := range req.SiteIDs {
+		if _, ok := seen[siteID]; ok {
+			continue
+		}
+		seen[siteID] = struct{}{}
+
+		id, err := bson.ObjectIDFromHex(siteID)
+		if err != nil {
+			return nil, ErrScopeSiteNotFound
+		}
+		if _, err := siteRepo.GetSiteByID(ctx, id); err != nil {
+			if errors.Is(err, repository.ErrSiteNotFound) {
+				return nil, ErrScopeSiteNotFound
+			}
+			return nil, err
+		}
+		scope.SiteIDs = append(scope.SiteIDs, siteID)
+	}
+
+	for _, host := range req.Hosts {
+		host = strings.ToLower(strings.TrimSpace(host))
+		if _, ok := seen[host]; ok {
+			continue
+		}
+		seen[host] = struct{}{}
+		scope.Hosts = append(scope.Hosts, host)
+	}
+
+	if scope.IsGlobal() {
+		return nil, nil
+	}
+	return scope, nil
+}
+
+// buildSiteScopeFilter 根据站点ID构建作用域过滤条件，站点ID为空时不过滤
+func buildSiteScopeFilter(ctx context.Context, siteRepo repository.SiteRepository, siteID string, includeGlobal *bool) (*repository.SiteScopeFilter, error) {
+	if siteID == "" {
+		return nil, nil
+	}
+
+	id, err := bson.ObjectIDFromHex(siteID)
+	if err != nil {
+		return nil, ErrScopeSiteNotFound
+	}
+	site, err := siteRepo.GetSiteByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrSiteNotFound) {
+			return nil, ErrScopeSiteNotFound
+		}
+		return nil, err
+	}
+
+	var hosts []string
+	for _, hostname := range site.Hostnames() {
+		hosts = append(hosts, model.HostPatternCandidates(hostname)...)
+	}
+	filter := &repository.SiteScopeFilter{
+		SiteID:        siteID,
+		Hosts:         hosts,
+		IncludeGlobal: true,
+	}
+	if includeGlobal != nil {
+		filter.IncludeGlobal = *includeGlobal
+	}
+	return filter, nil
+}
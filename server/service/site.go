@@ -2,36 +2,91 @@ package service
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net"
+	"regexp"
+	"slices"
 	"strconv"
+	"strings"
+	"unicode"
 
+	pkgmodel "github.com/HUAHUAI23/RuiQi/pkg/model"
 	"github.com/HUAHUAI23/RuiQi/server/config"
 	"github.com/HUAHUAI23/RuiQi/server/dto"
 	"github.com/HUAHUAI23/RuiQi/server/model"
 	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/service/daemon/haproxy"
+	"github.com/haproxytech/client-native/v6/models"
 	"github.com/rs/zerolog"
 	"go.mongodb.org/mongo-driver/v2/bson"
 )
 
+var (
+	ErrInvalidLoginProtection = errors.New("登录保护配置无效")
+	ErrInvalidSiteRouting     = errors.New("站点域名或路由配置无效")
+	ErrInvalidBackend         = errors.New("后端配置无效")
+	ErrSiteServerNotFound     = errors.New("站点后端服务器不存在")
+	ErrSiteRouteNotFound      = errors.New("站点路径路由不存在")
+	ErrLastSiteServer         = errors.New("不能删除后端的最后一个服务器")
+	ErrSiteApplyFailed        = errors.New("站点已保存，但应用到 HAProxy 失败")
+	ErrInvalidSiteCertificate = errors.New("站点证书配置无效")
+	ErrInvalidTLSPolicy       = errors.New("TLS 策略配置无效")
+	ErrInvalidHeaderRule      = errors.New("请求头/响应头规则无效")
+	ErrInvalidSitePage        = errors.New("维护模式或错误页配置无效")
+)
+
+// cipherListPattern OpenSSL 加密套件列表允许的字符，加密套件会原样写入 HAProxy 证书列表
+var cipherListPattern = regexp.MustCompile(`^[A-Za-z0-9_+!@=.:-]+$`)
+
+// hostHeaderPattern 自定义 Host 头允许的格式，主机名或 IPv4 地址，可以带端口
+var hostHeaderPattern = regexp.MustCompile(`^[A-Za-z0-9._-]+(:[0-9]{1,5})?$`)
+
+// headerNamePattern 头部名称允许的字符，不包含会被 HAProxy 配置解析器当作引号或注释的字符
+var headerNamePattern = regexp.MustCompile(`^[A-Za-z0-9!$&*+.^_|~-]+$`)
+
+// hstsPreloadMinMaxAge 申请 HSTS 预加载要求的最短有效期（一年）
+const hstsPreloadMinMaxAge = 31536000
+
+// 健康检查默认参数
+const (
+	defaultHealthCheckExpectStatus = "200-399"
+	defaultHealthCheckInterval     = 2000
+	defaultHealthCheckRise         = 2
+	defaultHealthCheckFall         = 3
+)
+
 type SiteService interface {
 	CreateSite(ctx context.Context, req *dto.CreateSiteRequest) (*model.Site, error)
 	GetSites(ctx context.Context, pageStr, sizeStr string) ([]model.Site, int64, error)
 	GetSiteByID(ctx context.Context, id bson.ObjectID) (*model.Site, error)
 	UpdateSite(ctx context.Context, id bson.ObjectID, req *dto.UpdateSiteRequest) (*model.Site, error)
 	DeleteSite(ctx context.Context, id bson.ObjectID) error
+	GetSiteServerStatus(site *model.Site) []dto.SiteServerStatus
+	SetServerState(ctx context.Context, id bson.ObjectID, serverName string, state model.ServerState) (*model.Site, error)
+	SetServerWeight(ctx context.Context, id bson.ObjectID, serverName string, weight int64) (*model.Site, error)
+	AddServer(ctx context.Context, id bson.ObjectID, req *dto.AddSiteServerRequest) (*model.Site, error)
+	RemoveServer(ctx context.Context, id bson.ObjectID, serverName string) (*model.Site, error)
 }
 
 // SiteService 站点服务
 type SiteServiceImpl struct {
-	siteRepo repository.SiteRepository
-	logger   zerolog.Logger
+	siteRepo      repository.SiteRepository
+	certRepo      repository.CertificateRepository
+	runnerService RunnerService
+	logger        zerolog.Logger
 }
 
-// NewSiteService 创建站点服务
-func NewSiteService(siteRepo repository.SiteRepository) SiteService {
+// NewSiteService 创建站点服务，runnerService 用于读取后端服务器运行状态，可以为 nil
+func NewSiteService(siteRepo repository.SiteRepository, certRepo repository.CertificateRepository, runnerService RunnerService) SiteService {
 	logger := config.GetServiceLogger("site")
 	return &SiteServiceImpl{
-		siteRepo: siteRepo,
-		logger:   logger,
+		siteRepo:      siteRepo,
+		certRepo:      certRepo,
+		runnerService: runnerService,
+		logger:        logger,
 	}
 }
 
@@ -41,30 +96,62 @@ func (s *SiteServiceImpl) CreateSite(ctx context.Context, req *dto.CreateSiteReq
 	site := model.NewSite()
 	site.Name = req.Name
 	site.Domain = req.Domain
+	site.Aliases = req.Aliases
 	site.ListenPort = req.ListenPort
 	site.EnableHTTPS = req.EnableHTTPS
 	site.WAFEnabled = req.WAFEnabled
 	site.WAFMode = model.WAFModeFromString(req.WAFMode)
 	site.ActiveStatus = req.ActiveStatus
 	// 设置后端服务器
-	site.Backend.Servers = make([]model.Server, len(req.Backend.Servers))
-	for i, server := range req.Backend.Servers {
-		site.Backend.Servers[i] = model.Server{
-			Host: server.Host,
-			Port: server.Port,
-		}
+	backend, err := buildBackend(req.Backend)
+	if err != nil {
+		return nil, err
 	}
+	site.Backend = backend
+	routes, err := buildRoutes(req.Routes)
+	if err != nil {
+		return nil, err
+	}
+	site.Routes = routes
 
-	// 如果启用HTTPS，设置证书信息
-	if req.EnableHTTPS && req.Certificate != nil {
-		site.Certificate = model.Certificate{
-			CertName:    req.Certificate.CertName,
-			PublicKey:   req.Certificate.PublicKey,
-			PrivateKey:  req.Certificate.PrivateKey,
-			ExpireDate:  req.Certificate.ExpireDate,
-			IssuerName:  req.Certificate.IssuerName,
-			FingerPrint: req.Certificate.FingerPrint,
-		}
+	loginProtection, err := buildLoginProtection(req.LoginProtection)
+	if err != nil {
+		return nil, err
+	}
+	site.LoginProtection = loginProtection
+
+	tlsPolicy, err := buildTLSPolicy(req.TLSPolicy)
+	if err != nil {
+		return nil, err
+	}
+	site.TLSPolicy = tlsPolicy
+
+	if site.RequestHeaders, err = buildHeaderRules(req.RequestHeaders); err != nil {
+		return nil, err
+	}
+	if site.ResponseHeaders, err = buildHeaderRules(req.ResponseHeaders); err != nil {
+		return nil, err
+	}
+	site.SecurityHeaders = securityHeaderPresetFromString(req.SecurityHeaders)
+
+	site.HTTPSRedirect = model.HTTPSRedirect(req.HTTPSRedirect)
+	if site.Maintenance, err = buildMaintenance(req.Maintenance); err != nil {
+		return nil, err
+	}
+	if site.ErrorPages, err = buildErrorPages(req.ErrorPages); err != nil {
+		return nil, err
+	}
+	site.Limits = buildSiteLimits(req.Limits)
+
+	if err := normalizeSiteRouting(site); err != nil {
+		return nil, err
+	}
+	if site.TLSPolicy != nil && net.ParseIP(site.Domain) != nil {
+		// TLS 策略按 SNI 生效，访问 IP 站点的客户端不发送 SNI
+		return nil, fmt.Errorf("%w: IP 站点不支持配置 TLS 策略", ErrInvalidTLSPolicy)
+	}
+	if err := s.setSiteCertificate(ctx, site, req.CertificateID); err != nil {
+		return nil, err
 	}
 
 	// 验证站点配置
@@ -74,7 +161,7 @@ func (s *SiteServiceImpl) CreateSite(ctx context.Context, req *dto.CreateSiteReq
 	}
 
 	// 检查域名和端口是否已存在
-	err := s.siteRepo.CheckDomainPortExists(ctx, site)
+	err = s.siteRepo.CheckDomainPortExists(ctx, site)
 	if err != nil {
 		return nil, err
 	}
@@ -87,6 +174,9 @@ func (s *SiteServiceImpl) CreateSite(ctx context.Context, req *dto.CreateSiteReq
 	}
 
 	s.logger.Info().Str("name", site.Name).Str("domain", site.Domain).Msg("站点创建成功")
+	if err := s.applySites(ctx); err != nil {
+		return nil, err
+	}
 	return site, nil
 }
 
@@ -123,17 +213,6 @@ func (s *SiteServiceImpl) GetSiteByID(ctx context.Context, id bson.ObjectID) (*m
 
 // UpdateSite 更新站点
 func (s *SiteServiceImpl) UpdateSite(ctx context.Context, id bson.ObjectID, req *dto.UpdateSiteRequest) (*model.Site, error) {
-
-	err := s.siteRepo.CheckDomainPortConflict(ctx, &model.Site{
-		ID:         id,
-		Domain:     req.Domain,
-		ListenPort: req.ListenPort,
-	})
-
-	if err != nil {
-		return nil, err
-	}
-
 	// 获取现有站点
 	site, err := s.siteRepo.GetSiteByID(ctx, id)
 	if err != nil {
@@ -147,6 +226,9 @@ func (s *SiteServiceImpl) UpdateSite(ctx context.Context, id bson.ObjectID, req
 	if req.Domain != "" {
 		site.Domain = req.Domain
 	}
+	if req.Aliases != nil {
+		site.Aliases = req.Aliases
+	}
 	if req.ListenPort != 0 {
 		site.ListenPort = req.ListenPort
 	}
@@ -161,26 +243,82 @@ func (s *SiteServiceImpl) UpdateSite(ctx context.Context, id bson.ObjectID, req
 
 	// 更新后端服务器
 	if req.Backend != nil && len(req.Backend.Servers) > 0 {
-		site.Backend.Servers = make([]model.Server, len(req.Backend.Servers))
-		for i, server := range req.Backend.Servers {
-			site.Backend.Servers[i] = model.Server{
-				Host:  server.Host,
-				Port:  server.Port,
-				IsSSL: server.IsSSL,
-			}
+		backend, err := buildBackend(*req.Backend)
+		if err != nil {
+			return nil, err
+		}
+		site.Backend = backend
+	}
+
+	// 更新路径路由
+	if req.Routes != nil {
+		routes, err := buildRoutes(req.Routes)
+		if err != nil {
+			return nil, err
+		}
+		site.Routes = routes
+	}
+
+	// 更新登录保护配置
+	if req.LoginProtection != nil {
+		loginProtection, err := buildLoginProtection(req.LoginProtection)
+		if err != nil {
+			return nil, err
 		}
+		site.LoginProtection = loginProtection
 	}
 
-	// 更新证书信息
-	if req.EnableHTTPS && req.Certificate != nil {
-		site.Certificate = model.Certificate{
-			CertName:    req.Certificate.CertName,
-			PublicKey:   req.Certificate.PublicKey,
-			PrivateKey:  req.Certificate.PrivateKey,
-			ExpireDate:  req.Certificate.ExpireDate,
-			IssuerName:  req.Certificate.IssuerName,
-			FingerPrint: req.Certificate.FingerPrint,
+	// 更新 TLS 策略
+	if req.TLSPolicy != nil {
+		tlsPolicy, err := buildTLSPolicy(req.TLSPolicy)
+		if err != nil {
+			return nil, err
 		}
+		site.TLSPolicy = tlsPolicy
+	}
+
+	// 更新请求头/响应头规则和安全响应头预设
+	if req.RequestHeaders != nil {
+		if site.RequestHeaders, err = buildHeaderRules(req.RequestHeaders); err != nil {
+			return nil, err
+		}
+	}
+	if req.ResponseHeaders != nil {
+		if site.ResponseHeaders, err = buildHeaderRules(req.ResponseHeaders); err != nil {
+			return nil, err
+		}
+	}
+	if req.SecurityHeaders != "" {
+		site.SecurityHeaders = securityHeaderPresetFromString(req.SecurityHeaders)
+	}
+
+	// 更新 HTTPS 重定向、维护模式、错误页和连接保护
+	if req.HTTPSRedirect != "" {
+		site.HTTPSRedirect = model.HTTPSRedirect(req.HTTPSRedirect)
+	}
+	if req.Maintenance != nil {
+		if site.Maintenance, err = buildMaintenance(req.Maintenance); err != nil {
+			return nil, err
+		}
+	}
+	if req.ErrorPages != nil {
+		if site.ErrorPages, err = buildErrorPages(req.ErrorPages); err != nil {
+			return nil, err
+		}
+	}
+	if req.Limits != nil {
+		site.Limits = buildSiteLimits(req.Limits)
+	}
+
+	if err := normalizeSiteRouting(site); err != nil {
+		return nil, err
+	}
+	if site.TLSPolicy != nil && net.ParseIP(site.Domain) != nil {
+		// TLS 策略按 SNI 生效，访问 IP 站点的客户端不发送 SNI
+		return nil, fmt.Errorf("%w: IP 站点不支持配置 TLS 策略", ErrInvalidTLSPolicy)
+	}
+	if err := s.setSiteCertificate(ctx, site, req.CertificateID); err != nil {
+		return nil, err
 	}
 
 	// 验证站点配置
@@ -189,6 +327,12 @@ func (s *SiteServiceImpl) UpdateSite(ctx context.Context, id bson.ObjectID, req
 		return nil, err
 	}
 
+	// 检查域名和端口是否与其他站点冲突
+	err = s.siteRepo.CheckDomainPortConflict(ctx, site)
+	if err != nil {
+		return nil, err
+	}
+
 	// 保存更新
 	err = s.siteRepo.UpdateSite(ctx, site)
 	if err != nil {
@@ -197,6 +341,9 @@ func (s *SiteServiceImpl) UpdateSite(ctx context.Context, id bson.ObjectID, req
 	}
 
 	s.logger.Info().Str("id", id.Hex()).Str("name", site.Name).Msg("站点更新成功")
+	if err := s.applySites(ctx); err != nil {
+		return nil, err
+	}
 	return site, nil
 }
 
@@ -216,5 +363,731 @@ func (s *SiteServiceImpl) DeleteSite(ctx context.Context, id bson.ObjectID) erro
 	}
 
 	s.logger.Info().Str("id", id.Hex()).Str("name", site.Name).Msg("站点删除成功")
+	return s.applySites(ctx)
+}
+
+// setSiteCertificate 设置站点引用的证书库证书，certificateID 为空时按域名自动选择
+// 启用 HTTPS 时证书必须包含站点的所有主机名；证书内容在生成配置时从证书库读取，不保存在站点中
+func (s *SiteServiceImpl) setSiteCertificate(ctx context.Context, site *model.Site, certificateID string) error {
+	site.Certificate = model.Certificate{}
+	site.CertificateID = bson.NilObjectID
+	if certificateID != "" {
+		id, err := bson.ObjectIDFromHex(certificateID)
+		if err != nil {
+			return fmt.Errorf("%w: 证书ID %s 格式无效", ErrInvalidSiteCertificate, certificateID)
+		}
+		site.CertificateID = id
+	}
+	if !site.EnableHTTPS {
+		return nil
+	}
+
+	if !site.CertificateID.IsZero() {
+		cert, err := s.certRepo.GetCertificateByID(ctx, site.CertificateID)
+		if err != nil {
+			if errors.Is(err, repository.ErrCertNotFound) {
+				return fmt.Errorf("%w: 证书 %s 不存在", ErrInvalidSiteCertificate, certificateID)
+			}
+			return err
+		}
+		if uncovered := cert.UncoveredHostnames(site); len(uncovered) > 0 {
+			return fmt.Errorf("%w: 证书 %s 不包含域名 %s", ErrInvalidSiteCertificate, cert.Name, strings.Join(uncovered, ", "))
+		}
+		return nil
+	}
+
+	certs, err := s.certRepo.GetAllCertificates(ctx)
+	if err != nil {
+		return err
+	}
+	if model.SelectSiteCertificate(site, certs) == nil {
+		return fmt.Errorf("%w: 证书库中没有包含站点所有域名（%s）的证书", ErrInvalidSiteCertificate, strings.Join(site.Hostnames(), ", "))
+	}
+	return nil
+}
+
+// applySites 运行器运行时将站点变更增量应用到 HAProxy，只修改受影响的站点配置
+// 运行器未运行时不做处理，站点在下次启动时生效
+func (s *SiteServiceImpl) applySites(ctx context.Context) error {
+	if s.runnerService == nil {
+		return nil
+	}
+	_, err := s.runnerService.ApplySites(ctx)
+	if err == nil || errors.Is(err, ErrRunnerNotRunning) {
+		return nil
+	}
+	s.logger.Error().Err(err).Msg("应用站点配置失败")
+	return fmt.Errorf("%w: %v", ErrSiteApplyFailed, err)
+}
+
+// GetSiteServerStatus 从 HAProxy 统计信息中读取站点后端服务器的运行状态
+// HAProxy 未运行或统计信息不可用时返回 nil
+func (s *SiteServiceImpl) GetSiteServerStatus(site *model.Site) []dto.SiteServerStatus {
+	if s.runnerService == nil {
+		return nil
+	}
+	stats, err := s.runnerService.GetStats()
+	if err != nil {
+		s.logger.Debug().Err(err).Str("site", site.Name).Msg("获取HAProxy统计信息失败，跳过后端服务器状态")
+		return nil
+	}
+
+	var serverStats []*models.NativeStat
+	for _, stat := range stats.Stats {
+		if stat != nil && stat.Type == models.NativeStatTypeServer && stat.Stats != nil {
+			serverStats = append(serverStats, stat)
+		}
+	}
+
+	servers := haproxy.GetSiteBackendServers(*site)
+	statuses := make([]dto.SiteServerStatus, len(servers))
+	for i, server := range servers {
+		status := dto.SiteServerStatus{
+			Backend:    server.BackendName,
+			Server:     server.ServerName,
+			PathPrefix: server.PathPrefix,
+			Host:       server.Server.Host,
+			Port:       server.Server.Port,
+			Status:     "UNKNOWN",
+		}
+		// 后端按 Host 头拆分时，服务器位于拆分出的后端中
+		index := slices.IndexFunc(serverStats, func(stat *models.NativeStat) bool {
+			return stat.Name == server.ServerName && haproxy.IsHostGroupBackend(server.BackendName, stat.BackendName)
+		})
+		if index != -1 {
+			status.Backend = serverStats[index].BackendName
+			stat := serverStats[index].Stats
+			status.Status = stat.Status
+			status.CheckStatus = stat.CheckStatus
+			if stat.Weight != nil {
+				status.Weight = *stat.Weight
+			}
+			if stat.Scur != nil {
+				status.CurrentSessions = *stat.Scur
+			}
+		}
+		statuses[i] = status
+	}
+	return statuses
+}
+
+// SetServerState 设置后端服务器状态，运行中的 HAProxy 通过运行时 API 立即生效，并保存到站点配置
+func (s *SiteServiceImpl) SetServerState(ctx context.Context, id bson.ObjectID, serverName string, state model.ServerState) (*model.Site, error) {
+	site, ref, err := s.getSiteServer(ctx, id, serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.applyRuntime(site, func(api haproxy.RuntimeAPI) error {
+		return setRuntimeServerState(api, ref, state)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	server := &siteBackend(site, ref.RouteIndex).Servers[ref.ServerIndex]
+	server.State = state
+	if state == model.ServerStateReady {
+		server.State = ""
+	}
+	err = s.saveSiteServers(ctx, site, "设置后端服务器状态", serverName, func(api haproxy.RuntimeAPI) error {
+		previous := ref.Server.State
+		if previous == "" {
+			previous = model.ServerStateReady
+		}
+		return setRuntimeServerState(api, ref, previous)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return site, nil
+}
+
+// setRuntimeServerState 通过运行时 API 设置服务器状态
+func setRuntimeServerState(api haproxy.RuntimeAPI, ref haproxy.SiteBackendServer, state model.ServerState) error {
+	if err := api.SetServerState(ref.BackendName, ref.ServerName, state); err != nil {
+		return err
+	}
+	// 配置文件中 drain 以权重 0 表示，恢复 ready 时需要同时恢复权重
+	if state == model.ServerStateReady {
+		return api.SetServerWeight(ref.BackendName, ref.ServerName, max(ref.Server.Weight, 1))
+	}
+	return nil
+}
+
+// SetServerWeight 设置后端服务器权重，运行中的 HAProxy 通过运行时 API 立即生效，并保存到站点配置
+func (s *SiteServiceImpl) SetServerWeight(ctx context.Context, id bson.ObjectID, serverName string, weight int64) (*model.Site, error) {
+	site, ref, err := s.getSiteServer(ctx, id, serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	// drain 状态的服务器只保存权重，恢复 ready 时生效
+	if ref.Server.State != model.ServerStateDrain {
+		err = s.applyRuntime(site, func(api haproxy.RuntimeAPI) error {
+			return api.SetServerWeight(ref.BackendName, ref.ServerName, weight)
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	siteBackend(site, ref.RouteIndex).Servers[ref.ServerIndex].Weight = weight
+	err = s.saveSiteServers(ctx, site, "设置后端服务器权重", serverName, func(api haproxy.RuntimeAPI) error {
+		if ref.Server.State == model.ServerStateDrain {
+			return nil
+		}
+		return api.SetServerWeight(ref.BackendName, ref.ServerName, max(ref.Server.Weight, 1))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return site, nil
+}
+
+// AddServer 向站点默认后端或路径路由的后端添加服务器，运行中的 HAProxy 通过运行时 API 立即生效，并保存到站点配置
+func (s *SiteServiceImpl) AddServer(ctx context.Context, id bson.ObjectID, req *dto.AddSiteServerRequest) (*model.Site, error) {
+	site, err := s.siteRepo.GetSiteByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	routeIndex := -1
+	backendName := haproxy.GetSiteBackendName(*site)
+	if req.PathPrefix != "" {
+		routeIndex = slices.IndexFunc(site.Routes, func(route model.Route) bool {
+			return route.PathPrefix == req.PathPrefix
+		})
+		if routeIndex == -1 {
+			return nil, ErrSiteRouteNotFound
+		}
+		backendName = haproxy.GetRouteBackendName(*site, routeIndex)
+	}
+
+	backend := siteBackend(site, routeIndex)
+	server, err := buildServer(req.Server)
+	if err != nil {
+		return nil, err
+	}
+	server.Name = haproxy.GetNextServerName(*site, routeIndex)
+	err = s.applyRuntime(site, func(api haproxy.RuntimeAPI) error {
+		return api.AddServer(backendName, server.Name, server, *backend)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	fixServerNames(site)
+	backend.Servers = append(backend.Servers, server)
+	err = s.saveSiteServers(ctx, site, "添加后端服务器", server.Name, func(api haproxy.RuntimeAPI) error {
+		return api.DeleteServer(backendName, server.Name)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return site, nil
+}
+
+// RemoveServer 删除后端服务器，运行中的 HAProxy 通过运行时 API 立即生效，并保存到站点配置
+func (s *SiteServiceImpl) RemoveServer(ctx context.Context, id bson.ObjectID, serverName string) (*model.Site, error) {
+	site, ref, err := s.getSiteServer(ctx, id, serverName)
+	if err != nil {
+		return nil, err
+	}
+
+	backend := siteBackend(site, ref.RouteIndex)
+	if len(backend.Servers) == 1 {
+		return nil, ErrLastSiteServer
+	}
+
+	err = s.applyRuntime(site, func(api haproxy.RuntimeAPI) error {
+		return api.DeleteServer(ref.BackendName, ref.ServerName)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// 删除前固定其余服务器的名称，避免序号变化后与运行中的 HAProxy 不一致
+	fixServerNames(site)
+	previous := *backend
+	previous.Servers = slices.Clone(backend.Servers)
+	backend.Servers = slices.Delete(backend.Servers, ref.ServerIndex, ref.ServerIndex+1)
+	err = s.saveSiteServers(ctx, site, "删除后端服务器", serverName, func(api haproxy.RuntimeAPI) error {
+		return api.AddServer(ref.BackendName, ref.ServerName, ref.Server, previous)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return site, nil
+}
+
+// getSiteServer 获取站点及其指定名称的后端服务器
+func (s *SiteServiceImpl) getSiteServer(ctx context.Context, id bson.ObjectID, serverName string) (*model.Site, haproxy.SiteBackendServer, error) {
+	site, err := s.siteRepo.GetSiteByID(ctx, id)
+	if err != nil {
+		return nil, haproxy.SiteBackendServer{}, err
+	}
+	for _, server := range haproxy.GetSiteBackendServers(*site) {
+		if server.ServerName == serverName {
+			return site, server, nil
+		}
+	}
+	return nil, haproxy.SiteBackendServer{}, ErrSiteServerNotFound
+}
+
+// applyRuntime 对运行中的 HAProxy 执行运行时操作
+// 站点未激活或 HAProxy 未运行时只保存配置，下次生成配置时生效
+func (s *SiteServiceImpl) applyRuntime(site *model.Site, apply func(api haproxy.RuntimeAPI) error) error {
+	if !site.ActiveStatus || s.runnerService == nil {
+		return nil
+	}
+	api, err := s.runnerService.GetRuntimeAPI()
+	if err != nil {
+		if errors.Is(err, ErrRunnerNotRunning) {
+			return nil
+		}
+		return err
+	}
+	return apply(api)
+}
+
+// saveSiteServers 保存运行时修改后的站点配置，保存失败时通过 undo 撤销已生效的运行时修改，使运行中的 HAProxy 与站点配置保持一致
+func (s *SiteServiceImpl) saveSiteServers(ctx context.Context, site *model.Site, action, serverName string, undo func(api haproxy.RuntimeAPI) error) error {
+	if err := s.siteRepo.UpdateSite(ctx, site); err != nil {
+		s.logger.Error().Err(err).Str("id", site.ID.Hex()).Str("server", serverName).Msg(action + "后保存站点失败")
+		if undoErr := s.applyRuntime(site, undo); undoErr != nil {
+			s.logger.Error().Err(undoErr).Str("id", site.ID.Hex()).Str("server", serverName).Msg("撤销运行时修改失败，重新应用站点配置后恢复一致")
+			return errors.Join(err, fmt.Errorf("撤销运行时修改失败: %w", undoErr))
+		}
+		return err
+	}
+	s.logger.Info().Str("id", site.ID.Hex()).Str("server", serverName).Msg(action + "成功")
+	return nil
+}
+
+// siteBackend 返回站点默认后端（routeIndex 为 -1）或路径路由的后端
+func siteBackend(site *model.Site, routeIndex int) *model.Backend {
+	if routeIndex < 0 {
+		return &site.Backend
+	}
+	return &site.Routes[routeIndex].Backend
+}
+
+// fixServerNames 将所有按序号生成的服务器名称写入配置
+func fixServerNames(site *model.Site) {
+	for _, server := range haproxy.GetSiteBackendServers(*site) {
+		siteBackend(site, server.RouteIndex).Servers[server.ServerIndex].Name = server.ServerName
+	}
+}
+
+// buildRoutes 转换路径路由配置
+func buildRoutes(req []dto.RouteDTO) ([]model.Route, error) {
+	routes := make([]model.Route, len(req))
+	for i, route := range req {
+		backend, err := buildBackend(route.Backend)
+		if err != nil {
+			return nil, err
+		}
+		routes[i] = model.Route{PathPrefix: route.PathPrefix, Backend: backend}
+	}
+	return routes, nil
+}
+
+// buildBackend 校验并转换后端配置，健康检查未设置的参数使用默认值
+func buildBackend(req dto.BackendDTO) (model.Backend, error) {
+	backend := model.Backend{
+		Servers:      make([]model.Server, len(req.Servers)),
+		Balance:      model.BalanceAlgorithm(req.Balance),
+		StickyCookie: req.StickyCookie,
+	}
+	for i, server := range req.Servers {
+		var err error
+		if backend.Servers[i], err = buildServer(server); err != nil {
+			return model.Backend{}, err
+		}
+	}
+
+	if req.HealthCheck != nil {
+		expectStatus := req.HealthCheck.ExpectStatus
+		if expectStatus == "" {
+			expectStatus = defaultHealthCheckExpectStatus
+		} else if !isValidStatusRange(expectStatus) {
+			return model.Backend{}, fmt.Errorf("%w: 健康检查期望状态码 %s 无效", ErrInvalidBackend, expectStatus)
+		}
+		backend.HealthCheck = &model.HealthCheck{
+			Path:         req.HealthCheck.Path,
+			ExpectStatus: expectStatus,
+			Interval:     valueOrDefault(req.HealthCheck.Interval, defaultHealthCheckInterval),
+			Rise:         valueOrDefault(req.HealthCheck.Rise, defaultHealthCheckRise),
+			Fall:         valueOrDefault(req.HealthCheck.Fall, defaultHealthCheckFall),
+		}
+		if strings.ContainsFunc(backend.HealthCheck.Path, unicode.IsSpace) || strings.Contains(backend.HealthCheck.Path, "#") {
+			return model.Backend{}, fmt.Errorf("%w: 健康检查路径 %q 不能包含空白字符或 #", ErrInvalidBackend, backend.HealthCheck.Path)
+		}
+	}
+
+	return backend, nil
+}
+
+// buildServer 校验并转换后端服务器配置
+func buildServer(req dto.ServerDTO) (model.Server, error) {
+	server := model.Server{
+		Host:            req.Host,
+		Port:            req.Port,
+		IsSSL:           req.IsSSL,
+		Weight:          req.Weight,
+		Backup:          req.Backup,
+		MaxConn:         req.MaxConn,
+		HostHeader:      model.HostHeaderMode(req.HostHeader),
+		HostHeaderValue: req.HostHeaderValue,
+	}
+	if server.HostHeader == model.HostHeaderCustom {
+		// 自定义 Host 头会原样写入 HAProxy 配置
+		if !hostHeaderPattern.MatchString(server.HostHeaderValue) {
+			return model.Server{}, fmt.Errorf("%w: 服务器 %s 的自定义 Host 头 %q 无效", ErrInvalidBackend, server.Host, server.HostHeaderValue)
+		}
+	} else {
+		server.HostHeaderValue = ""
+	}
+	return server, nil
+}
+
+// isValidStatusRange 判断是否为 200 或 200-399 形式的状态码范围
+func isValidStatusRange(value string) bool {
+	low, high, isRange := strings.Cut(value, "-")
+	if !isRange {
+		high = low
+	}
+	lowCode, err := strconv.Atoi(low)
+	if err != nil || lowCode < 100 || lowCode > 599 {
+		return false
+	}
+	highCode, err := strconv.Atoi(high)
+	if err != nil || highCode < lowCode || highCode > 599 {
+		return false
+	}
+	return true
+}
+
+func valueOrDefault(value, defaultValue int64) int64 {
+	if value == 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// normalizeSiteRouting 规范化并校验站点的其他域名和路径路由
+// IP 站点作为端口的默认后端接收所有请求，不支持其他域名和路径路由，所有服务器需要使用相同的 Host 头
+func normalizeSiteRouting(site *model.Site) error {
+	if net.ParseIP(site.Domain) != nil && (len(site.Aliases) > 0 || len(site.Routes) > 0) {
+		return fmt.Errorf("%w: IP 站点不支持配置其他域名和路径路由", ErrInvalidSiteRouting)
+	}
+	if net.ParseIP(site.Domain) != nil && len(site.Backend.Servers) > 0 {
+		// IP 站点使用端口默认后端，不按 Host 头拆分，所有服务器需要使用相同的 Host 头
+		hostHeader := haproxy.GetServerHostHeader(site.Backend.Servers[0], false)
+		for _, server := range site.Backend.Servers[1:] {
+			if haproxy.GetServerHostHeader(server, false) != hostHeader {
+				return fmt.Errorf("%w: IP 站点的所有服务器需要使用相同的 Host 头", ErrInvalidBackend)
+			}
+		}
+	}
+
+	seen := map[string]bool{strings.ToLower(site.Domain): true}
+	aliases := make([]string, 0, len(site.Aliases))
+	for _, alias := range site.Aliases {
+		alias = strings.ToLower(strings.TrimSpace(alias))
+		if seen[alias] {
+			return fmt.Errorf("%w: 域名 %s 重复", ErrInvalidSiteRouting, alias)
+		}
+		seen[alias] = true
+		aliases = append(aliases, alias)
+	}
+	site.Aliases = aliases
+
+	prefixes := make(map[string]bool, len(site.Routes))
+	for _, route := range site.Routes {
+		// 路径前缀会原样写入 HAProxy 配置，空白字符和 # 会破坏配置行
+		if strings.ContainsFunc(route.PathPrefix, unicode.IsSpace) || strings.Contains(route.PathPrefix, "#") {
+			return fmt.Errorf("%w: 路径前缀 %q 不能包含空白字符或 #", ErrInvalidSiteRouting, route.PathPrefix)
+		}
+		if prefixes[route.PathPrefix] {
+			return fmt.Errorf("%w: 路径前缀 %s 重复", ErrInvalidSiteRouting, route.PathPrefix)
+		}
+		prefixes[route.PathPrefix] = true
+	}
 	return nil
 }
+
+// buildLoginProtection 校验并转换登录保护配置，请求为空时返回 nil
+func buildLoginProtection(req *dto.LoginProtectionDTO) (*pkgmodel.LoginProtection, error) {
+	if req == nil {
+		return nil, nil
+	}
+
+	if len(req.FailureStatus) == 0 && req.FailurePattern == "" {
+		return nil, fmt.Errorf("%w: 至少需要配置失败状态码或失败响应内容", ErrInvalidLoginProtection)
+	}
+	if req.FailurePattern != "" {
+		if _, err := regexp.Compile(req.FailurePattern); err != nil {
+			return nil, fmt.Errorf("%w: 失败响应内容正则表达式无效: %v", ErrInvalidLoginProtection, err)
+		}
+	}
+	if req.UsernameThreshold == 0 && req.IPThreshold == 0 && req.ASNThreshold == 0 {
+		return nil, fmt.Errorf("%w: 至少需要配置一个失败次数阈值", ErrInvalidLoginProtection)
+	}
+
+	method := strings.ToUpper(req.Method)
+	if method == "" {
+		method = "POST"
+	}
+	window := req.Window
+	if window == 0 {
+		window = pkgmodel.DefaultLoginWindow
+	}
+	blockDuration := req.BlockDuration
+	if blockDuration == 0 {
+		blockDuration = pkgmodel.DefaultLoginBlockDuration
+	}
+
+	return &pkgmodel.LoginProtection{
+		Enabled:           req.Enabled,
+		Path:              req.Path,
+		Method:            method,
+		UsernameField:     req.UsernameField,
+		FailureStatus:     req.FailureStatus,
+		FailurePattern:    req.FailurePattern,
+		Window:            window,
+		UsernameThreshold: req.UsernameThreshold,
+		IPThreshold:       req.IPThreshold,
+		ASNThreshold:      req.ASNThreshold,
+		Action:            pkgmodel.LoginProtectionAction(req.Action),
+		BlockDuration:     blockDuration,
+	}, nil
+}
+
+// buildHeaderRules 校验并转换请求头/响应头规则
+// 规则会写入 HAProxy 配置，名称只允许 HTTP 头部名称的字符，值不能包含控制字符，正则表达式需要能够编译
+func buildHeaderRules(req []dto.HeaderRuleDTO) ([]model.HeaderRule, error) {
+	if len(req) == 0 {
+		return nil, nil
+	}
+
+	rules := make([]model.HeaderRule, len(req))
+	for i, item := range req {
+		rule := model.HeaderRule{
+			Action:     model.HeaderAction(item.Action),
+			Name:       item.Name,
+			Value:      item.Value,
+			Pattern:    item.Pattern,
+			PathPrefix: item.PathPrefix,
+		}
+		if !headerNamePattern.MatchString(rule.Name) {
+			return nil, fmt.Errorf("%w: 第 %d 条规则的头部名称 %q 无效", ErrInvalidHeaderRule, i+1, rule.Name)
+		}
+		if strings.ContainsFunc(rule.Value+rule.Pattern, unicode.IsControl) {
+			return nil, fmt.Errorf("%w: 第 %d 条规则的值不能包含控制字符", ErrInvalidHeaderRule, i+1)
+		}
+		// 路径前缀会原样写入 HAProxy 配置，空白字符和 # 会破坏配置行
+		if strings.ContainsFunc(rule.PathPrefix, unicode.IsSpace) || strings.Contains(rule.PathPrefix, "#") {
+			return nil, fmt.Errorf("%w: 第 %d 条规则的路径前缀 %q 不能包含空白字符或 #", ErrInvalidHeaderRule, i+1, rule.PathPrefix)
+		}
+
+		switch rule.Action {
+		case model.HeaderActionSet, model.HeaderActionAdd:
+			if rule.Value == "" || rule.Pattern != "" {
+				return nil, fmt.Errorf("%w: 第 %d 条规则设置或添加头部时需要值且不能设置正则表达式", ErrInvalidHeaderRule, i+1)
+			}
+		case model.HeaderActionDelete:
+			if rule.Value != "" || rule.Pattern != "" {
+				return nil, fmt.Errorf("%w: 第 %d 条规则删除头部时不能设置值和正则表达式", ErrInvalidHeaderRule, i+1)
+			}
+		case model.HeaderActionReplace:
+			if rule.Pattern == "" {
+				return nil, fmt.Errorf("%w: 第 %d 条规则替换头部时需要正则表达式", ErrInvalidHeaderRule, i+1)
+			}
+			if _, err := regexp.Compile(rule.Pattern); err != nil {
+				return nil, fmt.Errorf("%w: 第 %d 条规则的正则表达式无效: %v", ErrInvalidHeaderRule, i+1, err)
+			}
+		}
+		rules[i] = rule
+	}
+	return rules, nil
+}
+
+// securityHeaderPresetFromString 转换安全响应头预设，none 和未知的预设表示不添加
+func securityHeaderPresetFromString(preset string) model.SecurityHeaderPreset {
+	if _, ok := model.SecurityHeaderPresets[model.SecurityHeaderPreset(preset)]; !ok {
+		return ""
+	}
+	return model.SecurityHeaderPreset(preset)
+}
+
+// buildMaintenance 校验并转换维护模式配置，请求为空时返回 nil
+// 白名单统一为标准格式并去重，重复的 IP 或 CIDR 只保留一个
+func buildMaintenance(req *dto.MaintenanceDTO) (*model.Maintenance, error) {
+	if req == nil {
+		return nil, nil
+	}
+
+	maintenance := &model.Maintenance{
+		Enabled: req.Enabled,
+		Page:    req.Page,
+	}
+	for _, item := range req.AllowedIPs {
+		allowed := strings.TrimSpace(item)
+		if ip := net.ParseIP(allowed); ip != nil {
+			allowed = ip.String()
+		} else if _, ipNet, err := net.ParseCIDR(allowed); err == nil {
+			allowed = ipNet.String()
+		} else {
+			return nil, fmt.Errorf("%w: 白名单 %q 不是有效的 IP 或 CIDR", ErrInvalidSitePage, item)
+		}
+		if !slices.Contains(maintenance.AllowedIPs, allowed) {
+			maintenance.AllowedIPs = append(maintenance.AllowedIPs, allowed)
+		}
+	}
+	return maintenance, nil
+}
+
+// buildErrorPages 校验并转换自定义错误页，每个状态码只能有一个错误页
+func buildErrorPages(req []dto.ErrorPageDTO) ([]model.ErrorPage, error) {
+	if len(req) == 0 {
+		return nil, nil
+	}
+
+	pages := make([]model.ErrorPage, len(req))
+	for i, item := range req {
+		if !slices.Contains(model.ErrorPageStatuses, item.Status) {
+			return nil, fmt.Errorf("%w: 不支持自定义状态码 %d 的错误页", ErrInvalidSitePage, item.Status)
+		}
+		if slices.ContainsFunc(pages[:i], func(page model.ErrorPage) bool { return page.Status == item.Status }) {
+			return nil, fmt.Errorf("%w: 状态码 %d 的错误页重复", ErrInvalidSitePage, item.Status)
+		}
+		pages[i] = model.ErrorPage{Status: item.Status, Content: item.Content}
+	}
+	return pages, nil
+}
+
+// buildSiteLimits 转换站点的超时、请求体大小和连接保护配置，请求为空或没有配置任何项时返回 nil
+func buildSiteLimits(req *dto.SiteLimitsDTO) *model.SiteLimits {
+	if req == nil {
+		return nil
+	}
+
+	limits := &model.SiteLimits{
+		MaxBodySize:        req.MaxBodySize,
+		HTTPRequestTimeout: req.HTTPRequestTimeout,
+		ConnectTimeout:     req.ConnectTimeout,
+		ServerTimeout:      req.ServerTimeout,
+		MaxConn:            req.MaxConn,
+		ConnRate:           req.ConnRate,
+		RequestRate:        req.RequestRate,
+	}
+	if *limits == (model.SiteLimits{}) {
+		return nil
+	}
+	return limits
+}
+
+// buildTLSPolicy 校验并转换 TLS 策略，请求为空或没有配置任何项时返回 nil
+func buildTLSPolicy(req *dto.TLSPolicyDTO) (*model.TLSPolicy, error) {
+	if req == nil {
+		return nil, nil
+	}
+
+	policy := &model.TLSPolicy{
+		MinVersion:   model.TLSVersion(req.MinVersion),
+		MaxVersion:   model.TLSVersion(req.MaxVersion),
+		Ciphers:      req.Ciphers,
+		Ciphersuites: req.Ciphersuites,
+		ALPN:         req.ALPN,
+		OCSPStapling: req.OCSPStapling,
+	}
+	if policy.MinVersion != "" && policy.MaxVersion != "" &&
+		slices.Index(model.TLSVersions, policy.MinVersion) > slices.Index(model.TLSVersions, policy.MaxVersion) {
+		return nil, fmt.Errorf("%w: 最低协议版本 %s 高于最高协议版本 %s", ErrInvalidTLSPolicy, policy.MinVersion, policy.MaxVersion)
+	}
+	for _, cipherList := range []string{policy.Ciphers, policy.Ciphersuites} {
+		if cipherList != "" && !cipherListPattern.MatchString(cipherList) {
+			return nil, fmt.Errorf("%w: 加密套件 %q 包含无效字符", ErrInvalidTLSPolicy, cipherList)
+		}
+	}
+
+	if req.HSTS != nil {
+		if req.HSTS.Preload && (req.HSTS.MaxAge < hstsPreloadMinMaxAge || !req.HSTS.IncludeSubDomains) {
+			return nil, fmt.Errorf("%w: 申请 HSTS 预加载时有效期至少为 %d 秒且需要包含子域名", ErrInvalidTLSPolicy, hstsPreloadMinMaxAge)
+		}
+		policy.HSTS = &model.HSTSPolicy{
+			MaxAge:            req.HSTS.MaxAge,
+			IncludeSubDomains: req.HSTS.IncludeSubDomains,
+			Preload:           req.HSTS.Preload,
+		}
+	}
+
+	if req.ClientAuth != nil {
+		clientAuth, err := buildClientAuth(req.ClientAuth)
+		if err != nil {
+			return nil, err
+		}
+		policy.ClientAuth = clientAuth
+	}
+
+	if policy.MinVersion == "" && policy.MaxVersion == "" && policy.Ciphers == "" && policy.Ciphersuites == "" &&
+		len(policy.ALPN) == 0 && policy.HSTS == nil && !policy.OCSPStapling && policy.ClientAuth == nil {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+// buildClientAuth 校验客户端证书认证配置，CA 证书只保留证书内容
+func buildClientAuth(req *dto.ClientAuthDTO) (*model.ClientAuth, error) {
+	var (
+		bundle strings.Builder
+		count  int
+	)
+	rest := []byte(req.CACertificate)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 第 %d 个 CA 证书解析失败: %v", ErrInvalidTLSPolicy, count+1, err)
+		}
+		if !cert.IsCA {
+			return nil, fmt.Errorf("%w: 证书 %s 不是 CA 证书", ErrInvalidTLSPolicy, cert.Subject.CommonName)
+		}
+		pem.Encode(&bundle, block)
+		count++
+	}
+	if count == 0 {
+		return nil, fmt.Errorf("%w: 未找到 PEM 格式的 CA 证书", ErrInvalidTLSPolicy)
+	}
+
+	clientAuth := &model.ClientAuth{
+		Mode:          model.ClientAuthMode(req.Mode),
+		CACertificate: bundle.String(),
+	}
+	if clientAuth.Mode != model.ClientAuthPaths {
+		return clientAuth, nil
+	}
+	if len(req.Paths) == 0 {
+		return nil, fmt.Errorf("%w: 按路径认证时至少需要一个路径前缀", ErrInvalidTLSPolicy)
+	}
+	for _, path := range req.Paths {
+		// 路径前缀会原样写入 HAProxy 配置，空白字符和 # 会破坏配置行
+		if strings.ContainsFunc(path, unicode.IsSpace) || strings.Contains(path, "#") {
+			return nil, fmt.Errorf("%w: 路径前缀 %q 不能包含空白字符或 #", ErrInvalidTLSPolicy, path)
+		}
+		if slices.Contains(clientAuth.Paths, path) {
+			return nil, fmt.Errorf("%w: 路径前缀 %s 重复", ErrInvalidTLSPolicy, path)
+		}
+		clientAuth.Paths = append(clientAuth.Paths, path)
+	}
+	return clientAuth, nil
+}
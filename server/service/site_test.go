@@ -0,0 +1,158 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/service/daemon/haproxy"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// fakeServerSiteRepo 只实现后端服务器操作使用的方法，err 不为空时保存失败
+type fakeServerSiteRepo struct {
+	repository.SiteRepository
+	site    model.Site
+	err     error
+	updated []model.Site
+}
+
+func (r *fakeServerSiteRepo) GetSiteByID(ctx context.Context, id bson.ObjectID) (*model.Site, error) {
+	site := r.site
+	site.Backend.Servers = slices.Clone(r.site.Backend.Servers)
+	return &site, nil
+}
+
+func (r *fakeServerSiteRepo) UpdateSite(ctx context.Context, site *model.Site) error {
+	if r.err != nil {
+		return r.err
+	}
+	r.updated = append(r.updated, *site)
+	return nil
+}
+
+// fakeRuntimeRunner 返回记录调用的运行时 API
+type fakeRuntimeRunner struct {
+	RunnerService
+	api *fakeRuntimeAPI
+}
+
+func (r *fakeRuntimeRunner) GetRuntimeAPI() (haproxy.RuntimeAPI, error) {
+	return r.api, nil
+}
+
+// fakeRuntimeAPI 按调用顺序记录运行时操作
+type fakeRuntimeAPI struct {
+	calls []string
+}
+
+func (a *fakeRuntimeAPI) SetServerState(backendName, serverName string, state model.ServerState) error {
+	a.calls = append(a.calls, fmt.Sprintf("state %s/%s %s", backendName, serverName, state))
+	return nil
+}
+
+func (a *fakeRuntimeAPI) SetServerWeight(backendName, serverName string, weight int64) error {
+	a.calls = append(a.calls, fmt.Sprintf("weight %s/%s %d", backendName, serverName, weight))
+	return nil
+}
+
+func (a *fakeRuntimeAPI) AddServer(backendName, serverName string, server model.Server, backend model.Backend) error {
+	a.calls = append(a.calls, fmt.Sprintf("add %s/%s %s:%d servers=%d", backendName, serverName, server.Host, server.Port, len(backend.Servers)))
+	return nil
+}
+
+func (a *fakeRuntimeAPI) DeleteServer(backendName, serverName string) error {
+	a.calls = append(a.calls, fmt.Sprintf("delete %s/%s", backendName, serverName))
+	return nil
+}
+
+// TestSiteServerRuntimeUndo 测试保存站点失败时撤销已生效的运行时修改，保存成功时只执行一次运行时修改
+func TestSiteServerRuntimeUndo(t *testing.T) {
+	tests := []struct {
+		name    string
+		apply   func(s *SiteServiceImpl, id bson.ObjectID) (*model.Site, error)
+		applied []string
+		undo    []string
+	}{
+		{
+			name: "drain",
+			apply: func(s *SiteServiceImpl, id bson.ObjectID) (*model.Site, error) {
+				return s.SetServerState(context.Background(), id, "example_com_0", model.ServerStateDrain)
+			},
+			applied: []string{"state be_example_com/example_com_0 drain"},
+			undo:    []string{"state be_example_com/example_com_0 ready", "weight be_example_com/example_com_0 3"},
+		},
+		{
+			name: "ready",
+			apply: func(s *SiteServiceImpl, id bson.ObjectID) (*model.Site, error) {
+				return s.SetServerState(context.Background(), id, "example_com_1", model.ServerStateReady)
+			},
+			applied: []string{"state be_example_com/example_com_1 ready", "weight be_example_com/example_com_1 1"},
+			undo:    []string{"state be_example_com/example_com_1 drain"},
+		},
+		{
+			name: "weight",
+			apply: func(s *SiteServiceImpl, id bson.ObjectID) (*model.Site, error) {
+				return s.SetServerWeight(context.Background(), id, "example_com_0", 10)
+			},
+			applied: []string{"weight be_example_com/example_com_0 10"},
+			undo:    []string{"weight be_example_com/example_com_0 3"},
+		},
+		{
+			name: "add",
+			apply: func(s *SiteServiceImpl, id bson.ObjectID) (*model.Site, error) {
+				return s.AddServer(context.Background(), id, &dto.AddSiteServerRequest{Server: dto.ServerDTO{Host: "c.svc", Port: 80}})
+			},
+			applied: []string{"add be_example_com/example_com_2 c.svc:80 servers=2"},
+			undo:    []string{"delete be_example_com/example_com_2"},
+		},
+		{
+			name: "remove",
+			apply: func(s *SiteServiceImpl, id bson.ObjectID) (*model.Site, error) {
+				return s.RemoveServer(context.Background(), id, "example_com_0")
+			},
+			applied: []string{"delete be_example_com/example_com_0"},
+			undo:    []string{"add be_example_com/example_com_0 a.svc:80 servers=2"},
+		},
+	}
+	for _, tt := range tests {
+		for _, saveErr := range []error{nil, errors.New("save failed")} {
+			t.Run(fmt.Sprintf("%s save error %v", tt.name, saveErr), func(t *testing.T) {
+				repo := &fakeServerSiteRepo{
+					site: model.Site{
+						ID:           bson.NewObjectID(),
+						Domain:       "example.com",
+						ActiveStatus: true,
+						Backend: model.Backend{Servers: []model.Server{
+							{Host: "a.svc", Port: 80, Weight: 3},
+							{Host: "b.svc", Port: 80, State: model.ServerStateDrain},
+						}},
+					},
+					err: saveErr,
+				}
+				api := &fakeRuntimeAPI{}
+				s := &SiteServiceImpl{siteRepo: repo, runnerService: &fakeRuntimeRunner{api: api}, logger: zerolog.Nop()}
+
+				site, err := tt.apply(s, repo.site.ID)
+				want := tt.applied
+				if saveErr != nil {
+					want = append(slices.Clone(tt.applied), tt.undo...)
+					if !errors.Is(err, saveErr) || site != nil {
+						t.Errorf("error = %v, site = %v, want save error", err, site)
+					}
+				} else if err != nil || len(repo.updated) != 1 {
+					t.Errorf("error = %v, updated = %d, want saved once", err, len(repo.updated))
+				}
+				if !slices.Equal(api.calls, want) {
+					t.Errorf("runtime calls = %q, want %q", api.calls, want)
+				}
+			})
+		}
+	}
+}
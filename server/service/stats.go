@@ -24,21 +24,24 @@ type StatsService interface {
 	GetTimeSeriesData(ctx context.Context, timeRange string, metric string) (*dto.TimeSeriesResponse, error)
 	GetCombinedTimeSeriesData(ctx context.Context, timeRange string) (*dto.CombinedTimeSeriesResponse, error)
 	GetTrafficTimeSeriesData(ctx context.Context, timeRange string) (*dto.TrafficTimeSeriesResponse, error)
+	GetRuleStats(ctx context.Context, req *dto.RuleStatsRequest) (*dto.RuleStatsResponse, error)
 }
 
 type StatsServiceImpl struct {
-	wafLogRepository repository.WAFLogRepository
-	dbName           string
-	logger           zerolog.Logger
+	wafLogRepository    repository.WAFLogRepository
+	ruleStatsRepository repository.RuleStatsRepository
+	dbName              string
+	logger              zerolog.Logger
 }
 
-func NewStatsService(wafLogRepository repository.WAFLogRepository) StatsService {
+func NewStatsService(wafLogRepository repository.WAFLogRepository, ruleStatsRepository repository.RuleStatsRepository) StatsService {
 	dbName := config.Global.DBConfig.Database
 	logger := config.GetServiceLogger("stats")
 	return &StatsServiceImpl{
-		wafLogRepository: wafLogRepository,
-		dbName:           dbName,
-		logger:           logger,
+		wafLogRepository:    wafLogRepository,
+		ruleStatsRepository: ruleStatsRepository,
+		dbName:              dbName,
+		logger:              logger,
 	}
 }
 
@@ -410,6 +413,49 @@ func (s *StatsServiceImpl) GetTrafficTimeSeriesData(ctx context.Context, timeRan
 	}, nil
 }
 
+// GetRuleStats 获取规则命中统计及时间序列
+func (s *StatsServiceImpl) GetRuleStats(ctx context.Context, req *dto.RuleStatsRequest) (*dto.RuleStatsResponse, error) {
+	// 确定时间范围
+	startTime, err := s.getTimeRangeStart(req.TimeRange)
+	if err != nil {
+		return nil, err
+	}
+
+	// 根据时间范围决定数据聚合粒度，与其他时间序列接口保持一致
+	query := &repository.RuleStatsQuery{
+		StartTime: startTime,
+		Source:    pkgModel.RuleStatsSource(req.Source),
+		RuleID:    req.RuleID,
+		Limit:     int64(req.Limit),
+	}
+	var interval string
+	switch req.TimeRange {
+	case dto.TimeRange24Hours:
+		interval = "hour"
+		query.Unit, query.BinSize = "hour", 1
+	case dto.TimeRange7Days:
+		interval = "6hour"
+		query.Unit, query.BinSize = "hour", 6
+	case dto.TimeRange30Days:
+		interval = "day"
+		query.Unit, query.BinSize = "day", 1
+	}
+	if query.Limit <= 0 {
+		query.Limit = 20
+	}
+
+	items, err := s.ruleStatsRepository.GetRuleStatsHistory(ctx, query)
+	if err != nil {
+		return nil, fmt.Errorf("获取规则命中统计失败: %w", err)
+	}
+
+	return &dto.RuleStatsResponse{
+		TimeRange: req.TimeRange,
+		Interval:  interval,
+		Items:     items,
+	}, nil
+}
+
 // 辅助方法 - 获取时间范围的开始时间
 func (s *StatsServiceImpl) getTimeRangeStart(timeRange string) (time.Time, error) {
 	now := time.Now()
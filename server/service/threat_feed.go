@@ -0,0 +1,258 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pkgmodel "github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/HUAHUAI23/RuiQi/server/service/threatfeed"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+// ThreatFeedIPGroupPrefix 情报源默认IP组名称前缀
+const ThreatFeedIPGroupPrefix = "feed_"
+
+var (
+	ErrThreatFeedNotFound      = errors.New("威胁情报源不存在")
+	ErrThreatFeedNameExists    = errors.New("威胁情报源名称已存在")
+	ErrInvalidThreatFeedSource = errors.New("威胁情报源地址无效")
+	ErrThreatFeedSyncFailed    = errors.New("威胁情报源同步失败")
+)
+
+// ThreatFeedService 威胁情报源服务接口
+type ThreatFeedService interface {
+	CreateThreatFeed(ctx context.Context, req *dto.ThreatFeedCreateRequest) (*model.ThreatFeed, error)
+	GetThreatFeeds(ctx context.Context, req *dto.ThreatFeedListRequest) (*dto.ThreatFeedListResponse, error)
+	GetThreatFeedByID(ctx context.Context, id bson.ObjectID) (*model.ThreatFeed, error)
+	UpdateThreatFeed(ctx context.Context, id bson.ObjectID, req *dto.ThreatFeedUpdateRequest) (*model.ThreatFeed, error)
+	DeleteThreatFeed(ctx context.Context, id bson.ObjectID) error
+	RefreshThreatFeed(ctx context.Context, id bson.ObjectID) (*model.ThreatFeed, error)
+}
+
+// ThreatFeedServiceImpl 威胁情报源服务实现
+type ThreatFeedServiceImpl struct {
+	feedRepo    repository.ThreatFeedRepository
+	ipGroupRepo repository.IPGroupRepository
+	syncer      *threatfeed.Syncer
+	logger      zerolog.Logger
+}
+
+// NewThreatFeedService 创建威胁情报源服务
+func NewThreatFeedService(
+	feedRepo repository.ThreatFeedRepository,
+	ipGroupRepo repository.IPGroupRepository,
+	syncer *threatfeed.Syncer,
+) ThreatFeedService {
+	logger := config.GetServiceLogger("threatfeed")
+	return &ThreatFeedServiceImpl{
+		feedRepo:    feedRepo,
+		ipGroupRepo: ipGroupRepo,
+		syncer:      syncer,
+		logger:      logger,
+	}
+}
+
+// CreateThreatFeed 创建威胁情报源，同时创建同步的空IP组，由定时任务尽快完成首次同步
+func (s *ThreatFeedServiceImpl) CreateThreatFeed(ctx context.Context, req *dto.ThreatFeedCreateRequest) (*model.ThreatFeed, error) {
+	if err := threatfeed.ValidateSource(req.Source); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidThreatFeedSource, err)
+	}
+
+	exists, err := s.feedRepo.CheckThreatFeedNameExists(ctx, req.Name, bson.NilObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrThreatFeedNameExists
+	}
+
+	ipGroupName := req.IPGroupName
+	if ipGroupName == "" {
+		ipGroupName = ThreatFeedIPGroupPrefix + req.Name
+	}
+	exists, err = s.ipGroupRepo.CheckIPGroupNameExists(ctx, ipGroupName, bson.NilObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrIPGroupNameExists
+	}
+
+	ipGroup := &pkgmodel.IPGroup{Name: ipGroupName, Items: []string{}}
+	if err := s.ipGroupRepo.CreateIPGroup(ctx, ipGroup); err != nil {
+		s.logger.Error().Err(err).Str("ipGroup", ipGroupName).Msg("创建情报源IP组失败")
+		return nil, err
+	}
+
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	feed := &model.ThreatFeed{
+		Name:            req.Name,
+		Source:          req.Source,
+		Format:          model.ThreatFeedFormat(req.Format),
+		CSVColumn:       req.CSVColumn,
+		CSVHeader:       req.CSVHeader,
+		RefreshInterval: req.RefreshInterval,
+		IPGroupID:       ipGroup.ID,
+		IPGroupName:     ipGroup.Name,
+		Enabled:         enabled,
+		Status: model.ThreatFeedStatus{
+			NextFetchAt: time.Now(),
+		},
+	}
+
+	if err := s.feedRepo.CreateThreatFeed(ctx, feed); err != nil {
+		s.logger.Error().Err(err).Str("name", req.Name).Msg("创建威胁情报源失败")
+		// 回滚创建的IP组
+		if delErr := s.ipGroupRepo.DeleteIPGroup(ctx, ipGroup.ID); delErr != nil {
+			s.logger.Error().Err(delErr).Str("ipGroup", ipGroupName).Msg("回滚情报源IP组失败")
+		}
+		return nil, err
+	}
+
+	s.logger.Info().Str("id", feed.ID.Hex()).Str("name", feed.Name).Str("ipGroup", feed.IPGroupName).Msg("威胁情报源创建成功")
+	return feed, nil
+}
+
+// GetThreatFeeds 获取威胁情报源列表
+func (s *ThreatFeedServiceImpl) GetThreatFeeds(ctx context.Context, req *dto.ThreatFeedListRequest) (*dto.ThreatFeedListResponse, error) {
+	page, size := int64(req.Page), int64(req.Size)
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+
+	feeds, total, err := s.feedRepo.GetThreatFeeds(ctx, page, size)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("获取威胁情报源列表失败")
+		return nil, err
+	}
+
+	return &dto.ThreatFeedListResponse{
+		Total: total,
+		Items: feeds,
+	}, nil
+}
+
+// GetThreatFeedByID 根据ID获取威胁情报源
+func (s *ThreatFeedServiceImpl) GetThreatFeedByID(ctx context.Context, id bson.ObjectID) (*model.ThreatFeed, error) {
+	feed, err := s.feedRepo.GetThreatFeedByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrThreatFeedNotFound) {
+			return nil, ErrThreatFeedNotFound
+		}
+		s.logger.Error().Err(err).Str("id", id.Hex()).Msg("获取威胁情报源失败")
+		return nil, err
+	}
+
+	return feed, nil
+}
+
+// UpdateThreatFeed 更新威胁情报源
+func (s *ThreatFeedServiceImpl) UpdateThreatFeed(ctx context.Context, id bson.ObjectID, req *dto.ThreatFeedUpdateRequest) (*model.ThreatFeed, error) {
+	feed, err := s.GetThreatFeedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" && req.Name != feed.Name {
+		exists, err := s.feedRepo.CheckThreatFeedNameExists(ctx, req.Name, id)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrThreatFeedNameExists
+		}
+		feed.Name = req.Name
+	}
+
+	// 内容来源或解析方式变化后需要重新完整拉取
+	refetch := false
+	if req.Source != "" && req.Source != feed.Source {
+		if err := threatfeed.ValidateSource(req.Source); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidThreatFeedSource, err)
+		}
+		feed.Source = req.Source
+		refetch = true
+	}
+	if req.Format != "" && model.ThreatFeedFormat(req.Format) != feed.Format {
+		feed.Format = model.ThreatFeedFormat(req.Format)
+		refetch = true
+	}
+	if req.CSVColumn != nil && *req.CSVColumn != feed.CSVColumn {
+		feed.CSVColumn = *req.CSVColumn
+		refetch = true
+	}
+	if req.CSVHeader != nil && *req.CSVHeader != feed.CSVHeader {
+		feed.CSVHeader = *req.CSVHeader
+		refetch = true
+	}
+	if req.Enabled != nil && *req.Enabled != feed.Enabled {
+		feed.Enabled = *req.Enabled
+		refetch = refetch || feed.Enabled
+	}
+
+	now := time.Now()
+	if req.RefreshInterval != 0 && req.RefreshInterval != feed.RefreshInterval {
+		feed.RefreshInterval = req.RefreshInterval
+		if feed.Status.LastFetchAt != nil {
+			feed.Status.NextFetchAt = feed.Status.LastFetchAt.Add(time.Duration(feed.RefreshInterval) * time.Second)
+		}
+	}
+	if refetch {
+		feed.Status.NextFetchAt = now
+		feed.Status.ETag = ""
+		feed.Status.LastModified = ""
+	}
+
+	if err := s.feedRepo.UpdateThreatFeed(ctx, feed); err != nil {
+		if errors.Is(err, repository.ErrThreatFeedNotFound) {
+			return nil, ErrThreatFeedNotFound
+		}
+		s.logger.Error().Err(err).Str("id", id.Hex()).Msg("更新威胁情报源失败")
+		return nil, err
+	}
+
+	s.logger.Info().Str("id", id.Hex()).Str("name", feed.Name).Bool("refetch", refetch).Msg("威胁情报源更新成功")
+	return feed, nil
+}
+
+// DeleteThreatFeed 删除威胁情报源，同步的IP组会保留，可能仍被规则引用
+func (s *ThreatFeedServiceImpl) DeleteThreatFeed(ctx context.Context, id bson.ObjectID) error {
+	if err := s.feedRepo.DeleteThreatFeed(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrThreatFeedNotFound) {
+			return ErrThreatFeedNotFound
+		}
+		s.logger.Error().Err(err).Str("id", id.Hex()).Msg("删除威胁情报源失败")
+		return err
+	}
+
+	s.logger.Info().Str("id", id.Hex()).Msg("威胁情报源删除成功")
+	return nil
+}
+
+// RefreshThreatFeed 立即同步威胁情报源，同步失败时返回最新的同步状态和 ErrThreatFeedSyncFailed
+func (s *ThreatFeedServiceImpl) RefreshThreatFeed(ctx context.Context, id bson.ObjectID) (*model.ThreatFeed, error) {
+	feed, err := s.GetThreatFeedByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.syncer.Sync(ctx, feed, time.Now()); err != nil {
+		return feed, fmt.Errorf("%w: %v", ErrThreatFeedSyncFailed, err)
+	}
+
+	return feed, nil
+}
@@ -0,0 +1,192 @@
+// Package threatfeed 拉取外部威胁情报源并同步到IP组
+package threatfeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// DefaultFetchTimeout 单次拉取的默认超时时间
+	DefaultFetchTimeout = 60 * time.Second
+	// DefaultMaxFeedSize 情报源内容大小上限
+	DefaultMaxFeedSize = 50 << 20
+
+	userAgent = "RuiQi-WAF-ThreatFeed/1.0"
+)
+
+var (
+	ErrUnsupportedSource = errors.New("情报源地址必须是 http(s) URL 或本地文件绝对路径")
+	ErrFeedTooLarge      = errors.New("情报源内容超过大小限制")
+)
+
+// FetchResult 拉取结果
+type FetchResult struct {
+	Data         []byte // 情报源内容，NotModified 时为空
+	NotModified  bool   // 内容自上次拉取后未变化
+	ETag         string // 响应的 ETag，用于下次条件请求
+	LastModified string // 响应的 Last-Modified 或本地文件修改时间，用于下次条件请求
+}
+
+// Fetcher 情报源拉取器，支持 http(s) URL 和本地文件
+type Fetcher struct {
+	client  *http.Client
+	maxSize int64
+}
+
+// NewFetcher 创建拉取器，client 为空时使用默认超时的客户端
+func NewFetcher(client *http.Client) *Fetcher {
+	if client == nil {
+		client = &http.Client{Timeout: DefaultFetchTimeout}
+	}
+	return &Fetcher{
+		client:  client,
+		maxSize: DefaultMaxFeedSize,
+	}
+}
+
+// ValidateSource 校验情报源地址
+func ValidateSource(source string) error {
+	_, _, err := parseSource(source)
+	return err
+}
+
+// parseSource 解析情报源地址，返回 http(s) URL 或本地文件路径之一
+func parseSource(source string) (httpURL string, path string, err error) {
+	if filepath.IsAbs(source) {
+		return "", filepath.Clean(source), nil
+	}
+
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", "", fmt.Errorf("%w: %v", ErrUnsupportedSource, err)
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return "", "", ErrUnsupportedSource
+		}
+		return source, "", nil
+	case "file":
+		if !filepath.IsAbs(u.Path) {
+			return "", "", ErrUnsupportedSource
+		}
+		return "", filepath.Clean(u.Path), nil
+	default:
+		return "", "", ErrUnsupportedSource
+	}
+}
+
+// Fetch 拉取情报源内容
+// etag 和 lastModified 为上次拉取的结果，内容未变化时返回 NotModified
+func (f *Fetcher) Fetch(ctx context.Context, source, etag, lastModified string) (*FetchResult, error) {
+	httpURL, path, err := parseSource(source)
+	if err != nil {
+		return nil, err
+	}
+	if path != "" {
+		return f.fetchFile(path, lastModified)
+	}
+	return f.fetchHTTP(ctx, httpURL, etag, lastModified)
+}
+
+func (f *Fetcher) fetchHTTP(ctx context.Context, source, etag, lastModified string) (*FetchResult, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, source, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", userAgent)
+	if etag != "" {
+		req.Header.Set("If-None-Match", etag)
+	}
+	if lastModified != "" {
+		req.Header.Set("If-Modified-Since", lastModified)
+	}
+
+	resp, err := f.client.Do(req)
+	if err != nil {
+		// url.Error 的信息包含完整的 URL，可能带有访问凭据
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, fmt.Errorf("请求情报源失败: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified {
+		return &FetchResult{NotModified: true, ETag: etag, LastModified: lastModified}, nil
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, fmt.Errorf("情报源返回异常状态码: %s", resp.Status)
+	}
+
+	data, err := f.readLimited(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FetchResult{
+		Data:         data,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}, nil
+}
+
+func (f *Fetcher) fetchFile(path, lastModified string) (*FetchResult, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("打开情报源文件失败: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("读取情报源文件信息失败: %w", err)
+	}
+	if info.IsDir() {
+		return nil, fmt.Errorf("情报源路径是目录: %s", path)
+	}
+
+	modTime := info.ModTime().UTC().Format(time.RFC3339Nano)
+	if lastModified != "" && modTime == lastModified {
+		return &FetchResult{NotModified: true, LastModified: lastModified}, nil
+	}
+
+	data, err := f.readLimited(file)
+	if err != nil {
+		return nil, err
+	}
+
+	return &FetchResult{Data: data, LastModified: modTime}, nil
+}
+
+func (f *Fetcher) readLimited(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, f.maxSize+1))
+	if err != nil {
+		return nil, fmt.Errorf("读取情报源内容失败: %w", err)
+	}
+	if int64(len(data)) > f.maxSize {
+		return nil, ErrFeedTooLarge
+	}
+	return data, nil
+}
+
+// sourceForLog 去除 URL 中的用户信息和查询参数，避免在日志和错误中泄露凭据
+func sourceForLog(source string) string {
+	u, err := url.Parse(source)
+	if err != nil || u.Scheme == "" {
+		return source
+	}
+	u.User = nil
+	u.RawQuery = ""
+	return strings.TrimSuffix(u.String(), "?")
+}
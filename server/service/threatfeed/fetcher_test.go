@@ -0,0 +1,213 @@
+package threatfeed
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/utils/network"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+)
+
+// TestFetchHTTP 测试从本地HTTP服务拉取情报源，并使用 ETag 做条件请求
+func TestFetchHTTP(t *testing.T) {
+	const body = "1.2.3.4\n5.6.7.0/24\n"
+	const etag = `"v1"`
+
+	var requests int
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.Header.Get("User-Agent") != userAgent {
+			t.Errorf("User-Agent = %q, want %q", r.Header.Get("User-Agent"), userAgent)
+		}
+		if r.Header.Get("If-None-Match") == etag {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Header().Set("ETag", etag)
+		w.Header().Set("Last-Modified", "Mon, 19 Oct 2026 00:00:00 GMT")
+		w.Write([]byte(body))
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(server.Client())
+
+	result, err := fetcher.Fetch(context.Background(), server.URL+"/feed.txt", "", "")
+	if err != nil {
+		t.Fatalf("Fetch() error = %v", err)
+	}
+	if result.NotModified {
+		t.Fatal("first Fetch() returned NotModified")
+	}
+	if string(result.Data) != body {
+		t.Errorf("Data = %q, want %q", result.Data, body)
+	}
+	if result.ETag != etag {
+		t.Errorf("ETag = %q, want %q", result.ETag, etag)
+	}
+
+	result, err = fetcher.Fetch(context.Background(), server.URL+"/feed.txt", result.ETag, result.LastModified)
+	if err != nil {
+		t.Fatalf("conditional Fetch() error = %v", err)
+	}
+	if !result.NotModified {
+		t.Error("conditional Fetch() should return NotModified")
+	}
+	if result.ETag != etag {
+		t.Errorf("NotModified result should keep ETag, got %q", result.ETag)
+	}
+	if requests != 2 {
+		t.Errorf("server received %d requests, want 2", requests)
+	}
+}
+
+// TestFetchHTTPError 测试异常状态码和超出大小限制时返回错误
+func TestFetchHTTPError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/error":
+			http.Error(w, "internal error", http.StatusInternalServerError)
+		case "/large":
+			w.Write([]byte(strings.Repeat("1.2.3.4\n", 100)))
+		}
+	}))
+	defer server.Close()
+
+	fetcher := NewFetcher(server.Client())
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/error", "", ""); err == nil {
+		t.Error("Fetch() should fail on 500 response")
+	}
+
+	fetcher.maxSize = 64
+	if _, err := fetcher.Fetch(context.Background(), server.URL+"/large", "", ""); !errors.Is(err, ErrFeedTooLarge) {
+		t.Errorf("Fetch() error = %v, want ErrFeedTooLarge", err)
+	}
+}
+
+// TestFetchFile 测试拉取本地文件，文件未修改时返回 NotModified
+func TestFetchFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "feed.txt")
+	if err := os.WriteFile(path, []byte("10.0.0.1\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	fetcher := NewFetcher(nil)
+	for _, source := range []string{path, "file://" + path} {
+		result, err := fetcher.Fetch(context.Background(), source, "", "")
+		if err != nil {
+			t.Fatalf("Fetch(%q) error = %v", source, err)
+		}
+		if string(result.Data) != "10.0.0.1\n" {
+			t.Errorf("Fetch(%q) Data = %q", source, result.Data)
+		}
+
+		result, err = fetcher.Fetch(context.Background(), source, "", result.LastModified)
+		if err != nil {
+			t.Fatalf("conditional Fetch(%q) error = %v", source, err)
+		}
+		if !result.NotModified {
+			t.Errorf("conditional Fetch(%q) should return NotModified", source)
+		}
+	}
+
+	if _, err := fetcher.Fetch(context.Background(), filepath.Join(t.TempDir(), "missing.txt"), "", ""); err == nil {
+		t.Error("Fetch() should fail on missing file")
+	}
+}
+
+// TestValidateSource 测试情报源地址校验
+func TestValidateSource(t *testing.T) {
+	tests := []struct {
+		source string
+		valid  bool
+	}{
+		{"https://www.spamhaus.org/drop/drop.txt", true},
+		{"http://127.0.0.1:8080/feed", true},
+		{"/etc/ruiqi/feeds/internal.txt", true},
+		{"file:///etc/ruiqi/feeds/internal.txt", true},
+		{"relative/feed.txt", false},
+		{"ftp://example.com/feed.txt", false},
+		{"https://", false},
+	}
+
+	for _, tt := range tests {
+		err := ValidateSource(tt.source)
+		if (err == nil) != tt.valid {
+			t.Errorf("ValidateSource(%q) error = %v, want valid = %v", tt.source, err, tt.valid)
+		}
+	}
+}
+
+// TestParse 测试各种情报源格式的解析
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    ParseOptions
+		data    string
+		want    []string
+		invalid int
+	}{
+		{
+			name: "plain",
+			opts: ParseOptions{Format: model.ThreatFeedFormatPlain},
+			data: "# comment\n1.2.3.4\n\n5.6.7.8 # inline\n9.9.9.9\t12\n10.0.0.0/8\nnot-an-ip\n2001:db8::1\n",
+			want: []string{"1.2.3.4", "5.6.7.8", "9.9.9.9", "2001:db8::1"},
+			// CIDR 不是单个IP
+			invalid: 2,
+		},
+		{
+			name:    "cidr",
+			opts:    ParseOptions{Format: model.ThreatFeedFormatCIDR},
+			data:    "10.0.0.0/8\n192.168.1.5/24\n1.2.3.4\n# comment\n300.0.0.0/8\n",
+			want:    []string{"10.0.0.0/8", "192.168.1.0/24", "1.2.3.4"},
+			invalid: 1,
+		},
+		{
+			name: "drop",
+			opts: ParseOptions{Format: model.ThreatFeedFormatDROP},
+			data: "; Spamhaus DROP List 2026/10/19\n; Last-Modified: Mon, 19 Oct 2026\n" +
+				"1.10.16.0/20 ; SBL256894\n2.56.192.0/22 ; SBL459831\n" +
+				`{"cidr":"5.42.92.0/24","sblid":"SBL611923","rir":"ripencc"}` + "\n" +
+				`{"type":"metadata","timestamp":1760832000,"size":2,"records":2}` + "\n" +
+				"garbage ; SBL0\n",
+			want:    []string{"1.10.16.0/20", "2.56.192.0/22", "5.42.92.0/24"},
+			invalid: 1,
+		},
+		{
+			name:    "csv",
+			opts:    ParseOptions{Format: model.ThreatFeedFormatCSV, CSVColumn: 1, CSVHeader: true},
+			data:    "first_seen,ip,port\n2026-10-19,1.2.3.4,443\n# comment\n2026-10-19,\"5.6.7.0/24\",80\n2026-10-19,bad,22\nshort\n",
+			want:    []string{"1.2.3.4", "5.6.7.0/24"},
+			invalid: 2,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := Parse([]byte(tt.data), tt.opts)
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+
+			got := make([]string, 0, len(result.Prefixes))
+			for _, prefix := range result.Prefixes {
+				got = append(got, network.FormatPrefix(prefix))
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("Parse() prefixes = %v, want %v", got, tt.want)
+			}
+			if result.InvalidLines != tt.invalid {
+				t.Errorf("Parse() InvalidLines = %d, want %d", result.InvalidLines, tt.invalid)
+			}
+		})
+	}
+
+	if _, err := Parse(nil, ParseOptions{Format: "xml"}); !errors.Is(err, ErrInvalidFormat) {
+		t.Errorf("Parse() with unknown format error = %v, want ErrInvalidFormat", err)
+	}
+}
@@ -0,0 +1,175 @@
+package threatfeed
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/netip"
+	"strings"
+	"unicode"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/utils/network"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+)
+
+const maxLineSize = 1 << 20 // 单行长度上限
+
+// ErrInvalidFormat 不支持的情报源格式
+var ErrInvalidFormat = errors.New("不支持的情报源格式")
+
+// ParseOptions 解析选项
+type ParseOptions struct {
+	Format    model.ThreatFeedFormat
+	CSVColumn int  // CSV 格式中IP所在列，从 0 开始
+	CSVHeader bool // CSV 第一行是否为表头
+}
+
+// ParseResult 解析结果
+type ParseResult struct {
+	Prefixes     []netip.Prefix // 有效条目，未去重
+	InvalidLines int            // 无法解析的行数
+}
+
+// Parse 按格式解析情报源内容，注释和空行会被跳过
+func Parse(data []byte, opts ParseOptions) (*ParseResult, error) {
+	data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf")) // 去除 UTF-8 BOM
+
+	switch opts.Format {
+	case model.ThreatFeedFormatPlain:
+		return parseLines(data, stripComment, parseAddr)
+	case model.ThreatFeedFormatCIDR:
+		return parseLines(data, stripComment, network.ParsePrefix)
+	case model.ThreatFeedFormatDROP:
+		return parseLines(data, dropEntry, network.ParsePrefix)
+	case model.ThreatFeedFormatCSV:
+		return parseCSV(data, opts.CSVColumn, opts.CSVHeader)
+	default:
+		return nil, fmt.Errorf("%w: %s", ErrInvalidFormat, opts.Format)
+	}
+}
+
+// parseLines 逐行解析，extract 返回行中的条目，为空表示该行没有条目
+func parseLines(data []byte, extract func(string) string, parse func(string) (netip.Prefix, bool)) (*ParseResult, error) {
+	result := &ParseResult{}
+
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	scanner.Buffer(make([]byte, 0, 64*1024), maxLineSize)
+	for scanner.Scan() {
+		entry := extract(scanner.Text())
+		if entry == "" {
+			continue
+		}
+		prefix, ok := parse(entry)
+		if !ok {
+			result.InvalidLines++
+			continue
+		}
+		result.Prefixes = append(result.Prefixes, prefix)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("解析情报源内容失败: %w", err)
+	}
+
+	return result, nil
+}
+
+// stripComment 去除 # 和 ; 注释，返回行中的第一列
+func stripComment(line string) string {
+	if i := strings.IndexAny(line, "#;"); i >= 0 {
+		line = line[:i]
+	}
+	return firstField(line)
+}
+
+// dropEntry 解析 Spamhaus DROP 格式的行
+// 文本格式为 "1.10.16.0/20 ; SBL256894"，以 ; 开头的行为注释；
+// 同时兼容 JSON Lines 格式 {"cidr":"1.10.16.0/20","sblid":"SBL256894"}，元数据行会被跳过
+func dropEntry(line string) string {
+	line = strings.TrimSpace(line)
+	if strings.HasPrefix(line, "{") {
+		var entry struct {
+			CIDR string `json:"cidr"`
+			Type string `json:"type"`
+		}
+		if err := json.Unmarshal([]byte(line), &entry); err != nil {
+			return line // 作为无效行计数
+		}
+		if entry.Type == "metadata" {
+			return ""
+		}
+		return entry.CIDR
+	}
+	return stripComment(line)
+}
+
+func firstField(line string) string {
+	fields := strings.FieldsFunc(line, func(r rune) bool {
+		return unicode.IsSpace(r) || r == ','
+	})
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[0]
+}
+
+// parseAddr 只接受单个IP地址
+func parseAddr(s string) (netip.Prefix, bool) {
+	if strings.Contains(s, "/") {
+		return netip.Prefix{}, false
+	}
+	return network.ParsePrefix(s)
+}
+
+// parseCSV 解析 CSV 的指定列，以 # 开头的行为注释
+func parseCSV(data []byte, column int, header bool) (*ParseResult, error) {
+	result := &ParseResult{}
+
+	reader := csv.NewReader(bytes.NewReader(data))
+	reader.Comment = '#'
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = true
+
+	first := true
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			var parseErr *csv.ParseError
+			if errors.As(err, &parseErr) {
+				result.InvalidLines++
+				continue
+			}
+			return nil, fmt.Errorf("解析情报源内容失败: %w", err)
+		}
+		if first {
+			first = false
+			if header {
+				continue
+			}
+		}
+
+		if column >= len(record) {
+			result.InvalidLines++
+			continue
+		}
+		value := strings.TrimSpace(record[column])
+		if value == "" {
+			continue
+		}
+		prefix, ok := network.ParsePrefix(value)
+		if !ok {
+			result.InvalidLines++
+			continue
+		}
+		result.Prefixes = append(result.Prefixes, prefix)
+	}
+
+	return result, nil
+}
@@ -0,0 +1,215 @@
+package threatfeed
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	pkgmodel "github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/pkg/utils/network"
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/model"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/rs/zerolog"
+)
+
+const maxDiffSample = 100 // 同步状态中保留的变更条目数量上限
+
+// ErrNoValidEntries 情报源中没有有效条目，通常是情报源返回了错误页面，此时不覆盖已有数据
+var ErrNoValidEntries = errors.New("情报源中没有有效的IP地址或CIDR")
+
+// Syncer 将威胁情报源同步到IP组
+type Syncer struct {
+	feedRepo     repository.ThreatFeedRepository
+	ipGroupRepo  repository.IPGroupRepository
+	auditLogRepo repository.AuditLogRepository
+	fetcher      *Fetcher
+	logger       zerolog.Logger
+}
+
+// NewSyncer 创建同步器
+func NewSyncer(
+	feedRepo repository.ThreatFeedRepository,
+	ipGroupRepo repository.IPGroupRepository,
+	auditLogRepo repository.AuditLogRepository,
+	fetcher *Fetcher,
+) *Syncer {
+	return &Syncer{
+		feedRepo:     feedRepo,
+		ipGroupRepo:  ipGroupRepo,
+		auditLogRepo: auditLogRepo,
+		fetcher:      fetcher,
+		logger:       config.GetServiceLogger("threatfeed"),
+	}
+}
+
+// Sync 拉取情报源并同步到IP组，同步结果写入 feed.Status 并保存
+// 拉取或解析失败时IP组保留上一次成功同步的条目，只记录失败原因
+func (s *Syncer) Sync(ctx context.Context, feed *model.ThreatFeed, now time.Time) error {
+	feed.Status.LastFetchAt = &now
+	feed.Status.NextFetchAt = now.Add(time.Duration(feed.RefreshInterval) * time.Second)
+
+	syncErr := s.sync(ctx, feed, now)
+	if syncErr != nil {
+		feed.Status.LastError = syncErr.Error()
+		feed.Status.LastErrorAt = &now
+		feed.Status.ConsecutiveFailures++
+		s.logger.Warn().Err(syncErr).
+			Str("feed", feed.Name).
+			Str("source", sourceForLog(feed.Source)).
+			Int("consecutiveFailures", feed.Status.ConsecutiveFailures).
+			Msg("威胁情报源同步失败，保留上一次同步的数据")
+	} else {
+		feed.Status.LastSuccessAt = &now
+		feed.Status.ConsecutiveFailures = 0
+	}
+
+	if err := s.feedRepo.UpdateThreatFeedSyncState(ctx, feed); err != nil {
+		return errors.Join(syncErr, fmt.Errorf("保存同步状态失败: %w", err))
+	}
+	return syncErr
+}
+
+func (s *Syncer) sync(ctx context.Context, feed *model.ThreatFeed, now time.Time) error {
+	ipGroup, created, err := s.ensureIPGroup(ctx, feed)
+	if err != nil {
+		return err
+	}
+
+	// IP组被重新创建时需要完整拉取
+	etag, lastModified := feed.Status.ETag, feed.Status.LastModified
+	if created {
+		etag, lastModified = "", ""
+	}
+
+	fetched, err := s.fetcher.Fetch(ctx, feed.Source, etag, lastModified)
+	if err != nil {
+		return err
+	}
+	if fetched.NotModified {
+		feed.Status.ItemCount = len(ipGroup.Items)
+		s.logger.Debug().Str("feed", feed.Name).Msg("威胁情报源内容未变化")
+		return nil
+	}
+
+	parsed, err := Parse(fetched.Data, ParseOptions{
+		Format:    feed.Format,
+		CSVColumn: feed.CSVColumn,
+		CSVHeader: feed.CSVHeader,
+	})
+	if err != nil {
+		return err
+	}
+	if len(parsed.Prefixes) == 0 {
+		return fmt.Errorf("%w（无效行 %d）", ErrNoValidEntries, parsed.InvalidLines)
+	}
+
+	collapsed := network.CollapsePrefixes(parsed.Prefixes)
+	items := make([]string, 0, len(collapsed))
+	for _, prefix := range collapsed {
+		items = append(items, network.FormatPrefix(prefix))
+	}
+
+	diff := &model.ThreatFeedDiff{
+		SyncedAt:     now,
+		ValidEntries: len(parsed.Prefixes),
+		InvalidLines: parsed.InvalidLines,
+	}
+	diff.Added, diff.AddedCount = diffItems(items, ipGroup.Items)
+	diff.Removed, diff.RemovedCount = diffItems(ipGroup.Items, items)
+
+	if diff.AddedCount > 0 || diff.RemovedCount > 0 || len(ipGroup.Expirations) > 0 {
+		ipGroup.Items = items
+		ipGroup.Expirations = nil // 情报源管理的IP组不使用条目过期时间
+		if err := s.ipGroupRepo.UpdateIPGroup(ctx, ipGroup); err != nil {
+			return fmt.Errorf("更新IP组失败: %w", err)
+		}
+		s.recordAuditLog(ctx, feed, ipGroup, diff, now)
+	}
+
+	feed.Status.ETag = fetched.ETag
+	feed.Status.LastModified = fetched.LastModified
+	feed.Status.ItemCount = len(items)
+	feed.Status.LastDiff = diff
+
+	s.logger.Info().
+		Str("feed", feed.Name).
+		Str("ipGroup", ipGroup.Name).
+		Int("items", len(items)).
+		Int("added", diff.AddedCount).
+		Int("removed", diff.RemovedCount).
+		Int("invalidLines", diff.InvalidLines).
+		Msg("威胁情报源同步成功")
+	return nil
+}
+
+// ensureIPGroup 获取情报源同步的IP组，IP组已被删除时按原名称重新创建
+func (s *Syncer) ensureIPGroup(ctx context.Context, feed *model.ThreatFeed) (*pkgmodel.IPGroup, bool, error) {
+	ipGroup, err := s.ipGroupRepo.GetIPGroupByID(ctx, feed.IPGroupID)
+	if err == nil {
+		feed.IPGroupName = ipGroup.Name
+		return ipGroup, false, nil
+	}
+	if !errors.Is(err, repository.ErrIPGroupNotFound) {
+		return nil, false, fmt.Errorf("获取IP组失败: %w", err)
+	}
+
+	exists, err := s.ipGroupRepo.CheckIPGroupNameExists(ctx, feed.IPGroupName, feed.IPGroupID)
+	if err != nil {
+		return nil, false, fmt.Errorf("检查IP组名称失败: %w", err)
+	}
+	if exists {
+		return nil, false, fmt.Errorf("IP组已被删除，且名称 %s 已被其他IP组使用", feed.IPGroupName)
+	}
+
+	ipGroup = &pkgmodel.IPGroup{Name: feed.IPGroupName, Items: []string{}}
+	if err := s.ipGroupRepo.CreateIPGroup(ctx, ipGroup); err != nil {
+		return nil, false, fmt.Errorf("重新创建IP组失败: %w", err)
+	}
+	feed.IPGroupID = ipGroup.ID
+
+	s.logger.Warn().Str("feed", feed.Name).Str("ipGroup", ipGroup.Name).Msg("情报源的IP组已被删除，已重新创建")
+	return ipGroup, true, nil
+}
+
+// recordAuditLog 写入IP组变更的审计日志，失败只记录日志
+func (s *Syncer) recordAuditLog(ctx context.Context, feed *model.ThreatFeed, ipGroup *pkgmodel.IPGroup, diff *model.ThreatFeedDiff, now time.Time) {
+	err := s.auditLogRepo.CreateAuditLog(ctx, &model.AuditLog{
+		Action:       model.AuditActionUpdate,
+		ResourceType: model.AuditResourceIPGroup,
+		ResourceID:   ipGroup.ID.Hex(),
+		ResourceName: ipGroup.Name,
+		Operator:     model.AuditOperatorSystem,
+		Detail: map[string]any{
+			"threatFeed":   feed.Name,
+			"addedCount":   diff.AddedCount,
+			"removedCount": diff.RemovedCount,
+		},
+		CreatedAt: now,
+	})
+	if err != nil {
+		s.logger.Error().Err(err).Str("feed", feed.Name).Msg("写入威胁情报源同步审计日志失败")
+	}
+}
+
+// diffItems 返回在 a 中但不在 b 中的条目（最多 maxDiffSample 条）及其总数
+func diffItems(a, b []string) ([]string, int) {
+	set := make(map[string]struct{}, len(b))
+	for _, item := range b {
+		set[item] = struct{}{}
+	}
+
+	var diff []string
+	count := 0
+	for _, item := range a {
+		if _, ok := set[item]; ok {
+			continue
+		}
+		count++
+		if len(diff) < maxDiffSample {
+			diff = append(diff, item)
+		}
+	}
+	return diff, count
+}
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"context"
+	"errors"
+
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/HUAHUAI23/RuiQi/server/config"
+	"github.com/HUAHUAI23/RuiQi/server/dto"
+	"github.com/HUAHUAI23/RuiQi/server/repository"
+	"github.com/rs/zerolog"
+	"go.mongodb.org/mongo-driver/v2/bson"
+)
+
+var (
+	ErrTrapPathNotFound   = errors.New("蜜罐陷阱路径不存在")
+	ErrTrapPathNameExists = errors.New("蜜罐陷阱路径名称已存在")
+)
+
+// TrapPathService 蜜罐陷阱路径服务接口
+type TrapPathService interface {
+	CreateTrapPath(ctx context.Context, req *dto.TrapPathCreateRequest) (*model.TrapPath, error)
+	GetTrapPaths(ctx context.Context, req *dto.TrapPathListRequest) (*dto.TrapPathListResponse, error)
+	GetTrapPathByID(ctx context.Context, id bson.ObjectID) (*model.TrapPath, error)
+	UpdateTrapPath(ctx context.Context, id bson.ObjectID, req *dto.TrapPathUpdateRequest) (*model.TrapPath, error)
+	DeleteTrapPath(ctx context.Context, id bson.ObjectID) error
+}
+
+// TrapPathServiceImpl 蜜罐陷阱路径服务实现
+type TrapPathServiceImpl struct {
+	trapRepo repository.TrapPathRepository
+	siteRepo repository.SiteRepository
+	logger   zerolog.Logger
+}
+
+// NewTrapPathService 创建蜜罐陷阱路径服务
+func NewTrapPathService(trapRepo repository.TrapPathRepository, siteRepo repository.SiteRepository) TrapPathService {
+	logger := config.GetServiceLogger("trappath")
+	return &TrapPathServiceImpl{
+		trapRepo: trapRepo,
+		siteRepo: siteRepo,
+		logger:   logger,
+	}
+}
+
+// CreateTrapPath 创建蜜罐陷阱路径
+func (s *TrapPathServiceImpl) CreateTrapPath(ctx context.Context, req *dto.TrapPathCreateRequest) (*model.TrapPath, error) {
+	exists, err := s.trapRepo.CheckTrapPathNameExists(ctx, req.Name, bson.NilObjectID)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrTrapPathNameExists
+	}
+
+	scope, err := buildSiteScope(ctx, s.siteRepo, req.Scope)
+	if err != nil {
+		return nil, err
+	}
+
+	matchType := model.TrapMatchExact
+	if req.MatchType != "" {
+		matchType = model.TrapMatchType(req.MatchType)
+	}
+	blockDuration := req.BlockDuration
+	if blockDuration == 0 {
+		blockDuration = model.DefaultTrapBlockDuration
+	}
+	enabled := true
+	if req.Enabled != nil {
+		enabled = *req.Enabled
+	}
+
+	trap := &model.TrapPath{
+		Name:          req.Name,
+		Path:          req.Path,
+		MatchType:     matchType,
+		Scope:         scope,
+		BlockDuration: blockDuration,
+		Decoy:         toTrapDecoy(req.Decoy),
+		Enabled:       enabled,
+	}
+
+	if err := s.trapRepo.CreateTrapPath(ctx, trap); err != nil {
+		s.logger.Error().Err(err).Str("name", req.Name).Msg("创建蜜罐陷阱路径失败")
+		return nil, err
+	}
+
+	s.logger.Info().Str("id", trap.ID.Hex()).Str("name", trap.Name).Str("path", trap.Path).Msg("蜜罐陷阱路径创建成功")
+	return trap, nil
+}
+
+// GetTrapPaths 获取蜜罐陷阱路径列表
+func (s *TrapPathServiceImpl) GetTrapPaths(ctx context.Context, req *dto.TrapPathListRequest) (*dto.TrapPathListResponse, error) {
+	page, size := int64(req.Page), int64(req.Size)
+	if page < 1 {
+		page = 1
+	}
+	if size < 1 {
+		size = 10
+	}
+
+	scopeFilter, err := buildSiteScopeFilter(ctx, s.siteRepo, req.SiteID, req.IncludeGlobal)
+	if err != nil {
+		return nil, err
+	}
+
+	traps, total, err := s.trapRepo.GetTrapPaths(ctx, page, size, scopeFilter)
+	if err != nil {
+		s.logger.Error().Err(err).Msg("获取蜜罐陷阱路径列表失败")
+		return nil, err
+	}
+
+	return &dto.TrapPathListResponse{
+		Total: total,
+		Items: traps,
+	}, nil
+}
+
+// GetTrapPathByID 根据ID获取蜜罐陷阱路径
+func (s *TrapPathServiceImpl) GetTrapPathByID(ctx context.Context, id bson.ObjectID) (*model.TrapPath, error) {
+	trap, err := s.trapRepo.GetTrapPathByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repository.ErrTrapPathNotFound) {
+			return nil, ErrTrapPathNotFound
+		}
+		s.logger.Error().Err(err).Str("id", id.Hex()).Msg("获取蜜罐陷阱路径失败")
+		return nil, err
+	}
+
+	return trap, nil
+}
+
+// UpdateTrapPath 更新蜜罐陷阱路径
+func (s *TrapPathServiceImpl) UpdateTrapPath(ctx context.Context, id bson.ObjectID, req *dto.TrapPathUpdateRequest) (*model.TrapPath, error) {
+	trap, err := s.GetTrapPathByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.Name != "" && req.Name != trap.Name {
+		exists, err := s.trapRepo.CheckTrapPathNameExists(ctx, req.Name, id)
+		if err != nil {
+			return nil, err
+		}
+		if exists {
+			return nil, ErrTrapPathNameExists
+		}
+		trap.Name = req.Name
+	}
+
+	if req.Path != "" {
+		trap.Path = req.Path
+	}
+	if req.MatchType != "" {
+		trap.MatchType = model.TrapMatchType(req.MatchType)
+	}
+	if req.Scope != nil {
+		scope, err := buildSiteScope(ctx, s.siteRepo, req.Scope)
+		if err != nil {
+			return nil, err
+		}
+		trap.Scope = scope
+	}
+	if req.BlockDuration != 0 {
+		trap.BlockDuration = req.BlockDuration
+	}
+	if req.RemoveDecoy {
+		trap.Decoy = nil
+	} else if req.Decoy != nil {
+		trap.Decoy = toTrapDecoy(req.Decoy)
+	}
+	if req.Enabled != nil {
+		trap.Enabled = *req.Enabled
+	}
+
+	if err := s.trapRepo.UpdateTrapPath(ctx, trap); err != nil {
+		if errors.Is(err, repository.ErrTrapPathNotFound) {
+			return nil, ErrTrapPathNotFound
+		}
+		s.logger.Error().Err(err).Str("id", id.Hex()).Msg("更新蜜罐陷阱路径失败")
+		return nil, err
+	}
+
+	s.logger.Info().Str("id", id.Hex()).Str("name", trap.Name).Msg("蜜罐陷阱路径更新成功")
+	return trap, nil
+}
+
+// DeleteTrapPath 删除蜜罐陷阱路径，已封禁的IP不受影响，到期后自动解封
+func (s *TrapPathServiceImpl) DeleteTrapPath(ctx context.Context, id bson.ObjectID) error {
+	if err := s.trapRepo.DeleteTrapPath(ctx, id); err != nil {
+		if errors.Is(err, repository.ErrTrapPathNotFound) {
+			return ErrTrapPathNotFound
+		}
+		s.logger.Error().Err(err).Str("id", id.Hex()).Msg("删除蜜罐陷阱路径失败")
+		return err
+	}
+
+	s.logger.Info().Str("id", id.Hex()).Msg("蜜罐陷阱路径删除成功")
+	return nil
+}
+
+// toTrapDecoy 将诱饵响应请求转换为模型
+func toTrapDecoy(req *dto.TrapDecoyRequest) *model.TrapDecoy {
+	if req == nil {
+		return nil
+	}
+	return &model.TrapDecoy{
+		StatusCode: req.StatusCode,
+		Body:       req.Body,
+	}
+}
@@ -0,0 +1,277 @@
+// Package certutil 解析和校验 PEM 格式的证书链和私钥
+package certutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/youmark/pkcs8"
+)
+
+// MinRSAKeySize 支持的最小 RSA 密钥长度
+const MinRSAKeySize = 2048
+
+var (
+	ErrNoCertificate      = errors.New("未找到 PEM 格式的证书")
+	ErrInvalidCertificate = errors.New("证书解析失败")
+	ErrNoPrivateKey       = errors.New("未找到 PEM 格式的私钥")
+	ErrInvalidPrivateKey  = errors.New("私钥解析失败")
+	ErrPassphraseRequired = errors.New("私钥已加密，需要提供密码")
+	ErrDecryptPrivateKey  = errors.New("私钥解密失败，请检查密码")
+	ErrLegacyEncryptedKey = errors.New("不支持传统 PEM 加密的私钥，请转换为加密的 PKCS#8 格式")
+	ErrUnsupportedKeyType = errors.New("不支持的密钥类型")
+	ErrKeyMismatch        = errors.New("私钥与证书不匹配")
+	ErrChainOrder         = errors.New("证书链顺序错误，应按站点证书、中间证书、根证书的顺序排列")
+	ErrChainBroken        = errors.New("证书链不连续")
+	ErrIncompleteChain    = errors.New("证书链不完整，缺少中间证书")
+)
+
+// Certificate 解析后的证书链和私钥
+type Certificate struct {
+	Leaf           *x509.Certificate   // 站点证书
+	Chain          []*x509.Certificate // 完整证书链，第一个为站点证书
+	CertificatePEM string              // 重新编码的证书链，去除了证书以外的内容
+	PrivateKeyPEM  string              // 未加密的私钥，加密的私钥解密后以 PKCS#8 格式保存
+	KeyType        string              // 密钥类型，如 RSA-2048、ECDSA-P256
+	NotBefore      time.Time           // 生效时间
+	NotAfter       time.Time           // 过期时间
+	IssuerName     string              // 颁发机构
+	Domains        []string            // 证书包含的域名和 IP 地址
+	FingerPrint    string              // 站点证书的 SHA-256 指纹
+}
+
+// Parse 解析证书链和私钥，校验证书链的顺序和完整性、密钥类型以及私钥与站点证书是否匹配
+// 私钥为加密的 PKCS#8 格式时使用 passphrase 解密
+func Parse(certPEM, keyPEM, passphrase string) (*Certificate, error) {
+	chain, err := parseChain(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	leaf := chain[0]
+
+	keyType, err := checkKeyType(leaf.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	if err := verifyChain(chain); err != nil {
+		return nil, err
+	}
+
+	key, normalizedKey, err := parsePrivateKey(keyPEM, passphrase)
+	if err != nil {
+		return nil, err
+	}
+	public, ok := key.Public().(interface{ Equal(crypto.PublicKey) bool })
+	if !ok || !public.Equal(leaf.PublicKey) {
+		return nil, ErrKeyMismatch
+	}
+
+	var encoded strings.Builder
+	for _, cert := range chain {
+		pem.Encode(&encoded, &pem.Block{Type: "CERTIFICATE", Bytes: cert.Raw})
+	}
+	return &Certificate{
+		Leaf:           leaf,
+		Chain:          chain,
+		CertificatePEM: encoded.String(),
+		PrivateKeyPEM:  normalizedKey,
+		KeyType:        keyType,
+		NotBefore:      leaf.NotBefore,
+		NotAfter:       leaf.NotAfter,
+		IssuerName:     IssuerName(leaf),
+		Domains:        Domains(leaf),
+		FingerPrint:    FingerPrint(leaf.Raw),
+	}, nil
+}
+
+// ParseLeaf 解析证书链中的站点证书，不校验证书链和私钥
+func ParseLeaf(certPEM string) (*x509.Certificate, error) {
+	chain, err := parseChain(certPEM)
+	if err != nil {
+		return nil, err
+	}
+	return chain[0], nil
+}
+
+// FingerPrint 返回证书的 SHA-256 指纹，格式为冒号分隔的大写十六进制
+func FingerPrint(der []byte) string {
+	sum := sha256.Sum256(der)
+	parts := make([]string, len(sum))
+	for i, b := range sum {
+		parts[i] = fmt.Sprintf("%02X", b)
+	}
+	return strings.Join(parts, ":")
+}
+
+// IssuerName 返回颁发机构的通用名称，为空时使用组织名称
+func IssuerName(cert *x509.Certificate) string {
+	if cert.Issuer.CommonName != "" {
+		return cert.Issuer.CommonName
+	}
+	if len(cert.Issuer.Organization) > 0 {
+		return cert.Issuer.Organization[0]
+	}
+	return ""
+}
+
+// Domains 返回证书 SAN 中的域名和 IP 地址，没有 SAN 时使用通用名称
+func Domains(cert *x509.Certificate) []string {
+	domains := make([]string, 0, len(cert.DNSNames)+len(cert.IPAddresses))
+	domains = append(domains, cert.DNSNames...)
+	for _, ip := range cert.IPAddresses {
+		domains = append(domains, ip.String())
+	}
+	if len(domains) == 0 && cert.Subject.CommonName != "" {
+		domains = append(domains, cert.Subject.CommonName)
+	}
+	return domains
+}
+
+// parseChain 解析证书链中的所有证书，忽略证书以外的 PEM 块
+func parseChain(data string) ([]*x509.Certificate, error) {
+	var chain []*x509.Certificate
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%w: 第 %d 个证书: %v", ErrInvalidCertificate, len(chain)+1, err)
+		}
+		chain = append(chain, cert)
+	}
+	if len(chain) == 0 {
+		return nil, ErrNoCertificate
+	}
+	return chain, nil
+}
+
+// verifyChain 校验每个证书都由下一个证书签发，最后一个证书为自签名的根证书或由系统信任的根证书签发
+func verifyChain(chain []*x509.Certificate) error {
+	for i := 0; i < len(chain)-1; i++ {
+		if chain[i].CheckSignatureFrom(chain[i+1]) == nil {
+			continue
+		}
+		if chain[i+1].CheckSignatureFrom(chain[i]) == nil {
+			return ErrChainOrder
+		}
+		for j, parent := range chain {
+			if j != i && j != i+1 && chain[i].CheckSignatureFrom(parent) == nil {
+				return ErrChainOrder
+			}
+		}
+		return fmt.Errorf("%w: 第 %d 个证书（%s）不是由第 %d 个证书（%s）签发的",
+			ErrChainBroken, i+1, chain[i].Subject.CommonName, i+2, chain[i+1].Subject.CommonName)
+	}
+
+	last := chain[len(chain)-1]
+	if isSelfSigned(last) {
+		return nil
+	}
+	// 使用站点证书的生效时间校验，过期的证书链仍然可以上传
+	_, err := last.Verify(x509.VerifyOptions{
+		CurrentTime: chain[0].NotBefore,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	var rootsErr x509.SystemRootsError
+	if err == nil || errors.As(err, &rootsErr) {
+		// 无法加载系统根证书时跳过完整性校验
+		return nil
+	}
+	return fmt.Errorf("%w: 未找到 %s 的签发证书", ErrIncompleteChain, last.Issuer.CommonName)
+}
+
+func isSelfSigned(cert *x509.Certificate) bool {
+	return cert.Subject.String() == cert.Issuer.String() && cert.CheckSignatureFrom(cert) == nil
+}
+
+// checkKeyType 返回证书的密钥类型，只支持不小于 2048 位的 RSA 和 P-256、P-384 曲线的 ECDSA
+func checkKeyType(public crypto.PublicKey) (string, error) {
+	switch key := public.(type) {
+	case *rsa.PublicKey:
+		if key.N.BitLen() < MinRSAKeySize {
+			return "", fmt.Errorf("%w: RSA 密钥长度 %d 小于 %d 位", ErrUnsupportedKeyType, key.N.BitLen(), MinRSAKeySize)
+		}
+		return fmt.Sprintf("RSA-%d", key.N.BitLen()), nil
+	case *ecdsa.PublicKey:
+		switch key.Curve {
+		case elliptic.P256():
+			return "ECDSA-P256", nil
+		case elliptic.P384():
+			return "ECDSA-P384", nil
+		}
+		return "", fmt.Errorf("%w: ECDSA 曲线 %s", ErrUnsupportedKeyType, key.Curve.Params().Name)
+	default:
+		return "", fmt.Errorf("%w: %T", ErrUnsupportedKeyType, public)
+	}
+}
+
+// parsePrivateKey 解析私钥，返回私钥和需要保存的未加密 PEM
+// 支持 PKCS#1、SEC 1 和 PKCS#8 格式，加密的 PKCS#8 私钥解密后重新编码为未加密的 PKCS#8
+func parsePrivateKey(data, passphrase string) (crypto.Signer, string, error) {
+	rest := []byte(data)
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			return nil, "", ErrNoPrivateKey
+		}
+
+		var (
+			key any
+			err error
+		)
+		switch block.Type {
+		case "RSA PRIVATE KEY", "EC PRIVATE KEY":
+			if block.Headers["Proc-Type"] != "" {
+				return nil, "", ErrLegacyEncryptedKey
+			}
+			if block.Type == "RSA PRIVATE KEY" {
+				key, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+			} else {
+				key, err = x509.ParseECPrivateKey(block.Bytes)
+			}
+		case "PRIVATE KEY":
+			key, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+		case "ENCRYPTED PRIVATE KEY":
+			if passphrase == "" {
+				return nil, "", ErrPassphraseRequired
+			}
+			if key, err = pkcs8.ParsePKCS8PrivateKey(block.Bytes, []byte(passphrase)); err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrDecryptPrivateKey, err)
+			}
+			der, err := x509.MarshalPKCS8PrivateKey(key)
+			if err != nil {
+				return nil, "", fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+			}
+			block = &pem.Block{Type: "PRIVATE KEY", Bytes: der}
+		default:
+			// 跳过 EC PARAMETERS 等非私钥内容
+			continue
+		}
+		if err != nil {
+			return nil, "", fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+		}
+
+		signer, ok := key.(crypto.Signer)
+		if !ok {
+			return nil, "", fmt.Errorf("%w: %T", ErrUnsupportedKeyType, key)
+		}
+		return signer, string(pem.EncodeToMemory(&pem.Block{Type: block.Type, Bytes: block.Bytes})), nil
+	}
+}
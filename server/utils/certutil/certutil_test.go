@@ -0,0 +1,356 @@
+package certutil
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net"
+	"regexp"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/youmark/pkcs8"
+)
+
+// testCert 测试生成的证书和私钥
+type testCert struct {
+	cert *x509.Certificate
+	key  crypto.Signer
+}
+
+// testCertOptions 生成测试证书的参数，parent 为空时生成自签名证书
+type testCertOptions struct {
+	commonName string
+	org        string
+	dnsNames   []string
+	ips        []string
+	isCA       bool
+	notBefore  time.Time
+	notAfter   time.Time
+	key        crypto.Signer
+	parent     *testCert
+}
+
+var testSerial int64
+
+func newTestKey(t *testing.T) crypto.Signer {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	return key
+}
+
+func newTestCert(t *testing.T, opts testCertOptions) *testCert {
+	t.Helper()
+	if opts.key == nil {
+		opts.key = newTestKey(t)
+	}
+	if opts.notBefore.IsZero() {
+		opts.notBefore = time.Now().Add(-time.Hour)
+	}
+	if opts.notAfter.IsZero() {
+		opts.notAfter = opts.notBefore.Add(90 * 24 * time.Hour)
+	}
+	testSerial++
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(testSerial),
+		Subject:               pkix.Name{CommonName: opts.commonName},
+		DNSNames:              opts.dnsNames,
+		NotBefore:             opts.notBefore,
+		NotAfter:              opts.notAfter,
+		BasicConstraintsValid: true,
+		IsCA:                  opts.isCA,
+		KeyUsage:              x509.KeyUsageDigitalSignature,
+	}
+	if opts.org != "" {
+		template.Subject.Organization = []string{opts.org}
+	}
+	if opts.isCA {
+		template.KeyUsage |= x509.KeyUsageCertSign
+	}
+	for _, ip := range opts.ips {
+		template.IPAddresses = append(template.IPAddresses, net.ParseIP(ip))
+	}
+
+	parent, parentKey := template, opts.key
+	if opts.parent != nil {
+		parent, parentKey = opts.parent.cert, opts.parent.key
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, opts.key.Public(), parentKey)
+	if err != nil {
+		t.Fatalf("create certificate: %v", err)
+	}
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	return &testCert{cert: cert, key: opts.key}
+}
+
+// testChain 根证书、中间证书和站点证书，站点证书有效期从 notBefore 开始
+type testChain struct {
+	root, intermediate, leaf *testCert
+}
+
+func newTestChain(t *testing.T, notBefore time.Time) testChain {
+	t.Helper()
+	notAfter := notBefore.Add(365 * 24 * time.Hour)
+	root := newTestCert(t, testCertOptions{commonName: "Test Root", isCA: true, notBefore: notBefore, notAfter: notAfter})
+	intermediate := newTestCert(t, testCertOptions{commonName: "Test Intermediate", isCA: true, notBefore: notBefore, notAfter: notAfter, parent: root})
+	leaf := newTestCert(t, testCertOptions{
+		commonName: "example.com",
+		dnsNames:   []string{"example.com", "*.example.com"},
+		ips:        []string{"10.0.0.1", "2001:db8::1"},
+		notBefore:  notBefore,
+		notAfter:   notBefore.Add(90 * 24 * time.Hour),
+		parent:     intermediate,
+	})
+	return testChain{root: root, intermediate: intermediate, leaf: leaf}
+}
+
+func encodeCerts(certs ...*testCert) string {
+	var b strings.Builder
+	for _, c := range certs {
+		pem.Encode(&b, &pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw})
+	}
+	return b.String()
+}
+
+func encodePKCS8Key(t *testing.T, key crypto.Signer) string {
+	t.Helper()
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		t.Fatalf("marshal key: %v", err)
+	}
+	return string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der}))
+}
+
+// TestParse 测试证书链和私钥的校验，包括私钥不匹配、证书链顺序错误、证书链不连续和过期的证书
+func TestParse(t *testing.T) {
+	now := time.Now()
+	chain := newTestChain(t, now.Add(-time.Hour))
+	expired := newTestChain(t, now.AddDate(-2, 0, 0))
+	unrelated := newTestChain(t, now.Add(-time.Hour))
+	key := encodePKCS8Key(t, chain.leaf.key)
+
+	for _, tt := range []struct {
+		name    string
+		certPEM string
+		keyPEM  string
+		wantErr error
+		chain   int
+	}{
+		{"完整证书链", encodeCerts(chain.leaf, chain.intermediate, chain.root), key, nil, 3},
+		{"忽略证书以外的内容", "leading text\n" + encodeCerts(chain.leaf, chain.intermediate, chain.root) + "trailing text\n", key, nil, 3},
+		{"过期的证书链", encodeCerts(expired.leaf, expired.intermediate, expired.root), encodePKCS8Key(t, expired.leaf.key), nil, 3},
+		{"私钥与证书不匹配", encodeCerts(chain.leaf, chain.intermediate, chain.root), encodePKCS8Key(t, unrelated.leaf.key), ErrKeyMismatch, 0},
+		{"私钥为中间证书的私钥", encodeCerts(chain.leaf, chain.intermediate, chain.root), encodePKCS8Key(t, chain.intermediate.key), ErrKeyMismatch, 0},
+		{"中间证书在站点证书之前", encodeCerts(chain.intermediate, chain.leaf, chain.root), encodePKCS8Key(t, chain.intermediate.key), ErrChainOrder, 0},
+		{"根证书在中间证书之前", encodeCerts(chain.leaf, chain.root, chain.intermediate), key, ErrChainOrder, 0},
+		{"倒序", encodeCerts(chain.root, chain.intermediate, chain.leaf), encodePKCS8Key(t, chain.root.key), ErrChainOrder, 0},
+		{"证书链不连续", encodeCerts(chain.leaf, unrelated.intermediate, unrelated.root), key, ErrChainBroken, 0},
+		{"没有证书", "", key, ErrNoCertificate, 0},
+		{"证书内容无效", string(pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: []byte("bad")})), key, ErrInvalidCertificate, 0},
+		{"没有私钥", encodeCerts(chain.leaf, chain.intermediate, chain.root), "", ErrNoPrivateKey, 0},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.certPEM, tt.keyPEM, "")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(got.Chain) != tt.chain || !got.Leaf.Equal(got.Chain[0]) {
+				t.Errorf("chain length = %d, want %d", len(got.Chain), tt.chain)
+			}
+			if strings.Contains(got.CertificatePEM, "text") || strings.Count(got.CertificatePEM, "BEGIN CERTIFICATE") != tt.chain {
+				t.Errorf("CertificatePEM = %s", got.CertificatePEM)
+			}
+			if got.KeyType != "ECDSA-P256" || got.IssuerName != "Test Intermediate" {
+				t.Errorf("KeyType = %s, IssuerName = %s", got.KeyType, got.IssuerName)
+			}
+			if !got.NotBefore.Equal(got.Leaf.NotBefore) || !got.NotAfter.Equal(got.Leaf.NotAfter) {
+				t.Errorf("validity = %s - %s", got.NotBefore, got.NotAfter)
+			}
+			if got.FingerPrint != FingerPrint(got.Leaf.Raw) {
+				t.Errorf("FingerPrint = %s", got.FingerPrint)
+			}
+		})
+	}
+
+	// 证书链缺少根证书且签发证书不受系统信任，没有系统根证书时跳过完整性校验
+	if pool, err := x509.SystemCertPool(); err == nil && !pool.Equal(x509.NewCertPool()) {
+		if _, err := Parse(encodeCerts(chain.leaf), key, ""); !errors.Is(err, ErrIncompleteChain) {
+			t.Errorf("Parse(leaf only) error = %v, want %v", err, ErrIncompleteChain)
+		}
+	}
+
+	// 过期的证书保留原来的有效期，由调用方判断是否过期
+	got, err := Parse(encodeCerts(expired.leaf, expired.intermediate, expired.root), encodePKCS8Key(t, expired.leaf.key), "")
+	if err != nil {
+		t.Fatalf("Parse(expired) error = %v", err)
+	}
+	if !got.NotAfter.Before(now) {
+		t.Errorf("expired NotAfter = %s, want before %s", got.NotAfter, now)
+	}
+}
+
+// TestParseDomains 测试从 SAN 中提取域名和 IP 地址，没有 SAN 时使用通用名称
+func TestParseDomains(t *testing.T) {
+	root := newTestCert(t, testCertOptions{commonName: "Test Root", isCA: true})
+	for _, tt := range []struct {
+		name     string
+		cn       string
+		dnsNames []string
+		ips      []string
+		want     []string
+	}{
+		{"域名和IP", "example.com", []string{"example.com", "*.example.com"}, []string{"10.0.0.1", "2001:db8::1"}, []string{"example.com", "*.example.com", "10.0.0.1", "2001:db8::1"}},
+		{"只有IP", "", nil, []string{"192.168.1.1"}, []string{"192.168.1.1"}},
+		{"SAN 优先于通用名称", "cn.example.com", []string{"san.example.com"}, nil, []string{"san.example.com"}},
+		{"没有 SAN", "cn.example.com", nil, nil, []string{"cn.example.com"}},
+		{"没有 SAN 和通用名称", "", nil, nil, []string{}},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestCert(t, testCertOptions{commonName: tt.cn, dnsNames: tt.dnsNames, ips: tt.ips, parent: root})
+			if got := Domains(c.cert); !slices.Equal(got, tt.want) {
+				t.Errorf("Domains() = %v, want %v", got, tt.want)
+			}
+
+			parsed, err := Parse(encodeCerts(c, root), encodePKCS8Key(t, c.key), "")
+			if err != nil {
+				t.Fatalf("Parse() error = %v", err)
+			}
+			if !slices.Equal(parsed.Domains, tt.want) {
+				t.Errorf("Parse().Domains = %v, want %v", parsed.Domains, tt.want)
+			}
+		})
+	}
+}
+
+// TestParsePrivateKey 测试各格式的私钥、加密的 PKCS#8 私钥和不支持的密钥类型
+func TestParsePrivateKey(t *testing.T) {
+	ecKey := newTestKey(t).(*ecdsa.PrivateKey)
+	rsaKey, err := rsa.GenerateKey(rand.Reader, MinRSAKeySize)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecDER, err := x509.MarshalECPrivateKey(ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encrypted, err := pkcs8.MarshalPrivateKey(ecKey, []byte("secret"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encode := func(block *pem.Block) string { return string(pem.EncodeToMemory(block)) }
+	root := newTestCert(t, testCertOptions{commonName: "Test Root", isCA: true})
+	ecCert := newTestCert(t, testCertOptions{commonName: "ec.example.com", key: ecKey, parent: root})
+	rsaCert := newTestCert(t, testCertOptions{commonName: "rsa.example.com", key: rsaKey, parent: root})
+
+	for _, tt := range []struct {
+		name       string
+		cert       *testCert
+		keyPEM     string
+		passphrase string
+		wantErr    error
+		keyType    string
+	}{
+		{"SEC 1", ecCert, encode(&pem.Block{Type: "EC PARAMETERS", Bytes: []byte{0x06}}) + encode(&pem.Block{Type: "EC PRIVATE KEY", Bytes: ecDER}), "", nil, "ECDSA-P256"},
+		{"PKCS#8", ecCert, encodePKCS8Key(t, ecKey), "", nil, "ECDSA-P256"},
+		{"PKCS#1", rsaCert, encode(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(rsaKey)}), "", nil, "RSA-2048"},
+		{"加密的 PKCS#8", ecCert, encode(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encrypted}), "secret", nil, "ECDSA-P256"},
+		{"缺少密码", ecCert, encode(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encrypted}), "", ErrPassphraseRequired, ""},
+		{"密码错误", ecCert, encode(&pem.Block{Type: "ENCRYPTED PRIVATE KEY", Bytes: encrypted}), "wrong", ErrDecryptPrivateKey, ""},
+		{"传统 PEM 加密", ecCert, encode(&pem.Block{Type: "EC PRIVATE KEY", Headers: map[string]string{"Proc-Type": "4,ENCRYPTED"}, Bytes: ecDER}), "", ErrLegacyEncryptedKey, ""},
+		{"私钥内容无效", ecCert, encode(&pem.Block{Type: "PRIVATE KEY", Bytes: []byte("bad")}), "", ErrInvalidPrivateKey, ""},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(encodeCerts(tt.cert, root), tt.keyPEM, tt.passphrase)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Parse() error = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if got.KeyType != tt.keyType {
+				t.Errorf("KeyType = %s, want %s", got.KeyType, tt.keyType)
+			}
+			// 保存的私钥不加密，可以直接再次解析
+			if strings.Contains(got.PrivateKeyPEM, "ENCRYPTED") {
+				t.Errorf("PrivateKeyPEM should not be encrypted:\n%s", got.PrivateKeyPEM)
+			}
+			if _, err := Parse(got.CertificatePEM, got.PrivateKeyPEM, ""); err != nil {
+				t.Errorf("Parse(normalized) error = %v", err)
+			}
+		})
+	}
+
+	weakRSA, err := rsa.GenerateKey(rand.Reader, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p224, err := ecdsa.GenerateKey(elliptic.P224(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, key := range map[string]crypto.Signer{"RSA-1024": weakRSA, "ECDSA-P224": p224} {
+		c := newTestCert(t, testCertOptions{commonName: "weak.example.com", key: key, parent: root})
+		if _, err := Parse(encodeCerts(c, root), encodePKCS8Key(t, key), ""); !errors.Is(err, ErrUnsupportedKeyType) {
+			t.Errorf("Parse(%s) error = %v, want %v", name, err, ErrUnsupportedKeyType)
+		}
+	}
+}
+
+// TestIssuerName 测试颁发机构没有通用名称时使用组织名称
+func TestIssuerName(t *testing.T) {
+	for _, tt := range []struct {
+		cn, org, want string
+	}{
+		{"Test CA", "Test Org", "Test CA"},
+		{"", "Test Org", "Test Org"},
+		{"", "", ""},
+	} {
+		issuer := newTestCert(t, testCertOptions{commonName: tt.cn, org: tt.org, isCA: true})
+		leaf := newTestCert(t, testCertOptions{commonName: "example.com", parent: issuer})
+		if got := IssuerName(leaf.cert); got != tt.want {
+			t.Errorf("IssuerName(cn=%q, org=%q) = %q, want %q", tt.cn, tt.org, got, tt.want)
+		}
+	}
+}
+
+// TestParseLeafAndFingerPrint 测试只解析站点证书和指纹格式
+func TestParseLeafAndFingerPrint(t *testing.T) {
+	chain := newTestChain(t, time.Now().Add(-time.Hour))
+	// 顺序错误的证书链也可以解析第一个证书
+	leaf, err := ParseLeaf(encodeCerts(chain.leaf, chain.root, chain.intermediate))
+	if err != nil {
+		t.Fatalf("ParseLeaf() error = %v", err)
+	}
+	if !leaf.Equal(chain.leaf.cert) {
+		t.Errorf("ParseLeaf() = %s, want %s", leaf.Subject, chain.leaf.cert.Subject)
+	}
+	if _, err := ParseLeaf("no certificate"); !errors.Is(err, ErrNoCertificate) {
+		t.Errorf("ParseLeaf() error = %v, want %v", err, ErrNoCertificate)
+	}
+
+	fingerPrint := FingerPrint(leaf.Raw)
+	if !regexp.MustCompile(`^([0-9A-F]{2}:){31}[0-9A-F]{2}$`).MatchString(fingerPrint) {
+		t.Errorf("FingerPrint() = %s", fingerPrint)
+	}
+	if fingerPrint == FingerPrint(chain.intermediate.cert.Raw) {
+		t.Error("different certificates should have different fingerprints")
+	}
+}
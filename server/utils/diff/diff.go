@@ -0,0 +1,166 @@
+// Package diff 生成文本的统一格式（unified）差异
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// MaxLines 参与比较的最大行数，超过时不逐行比较，避免 O(n*m) 的内存占用
+const MaxLines = 20000
+
+// op 差异操作
+type op struct {
+	kind byte // ' ' 相同，'-' 删除，'+' 新增
+	line string
+}
+
+// Unified 生成 from 到 to 的统一格式差异，context 为每处修改前后保留的上下文行数
+// 两段文本相同时返回空字符串
+func Unified(from, to, fromName, toName string, context int) string {
+	if from == to {
+		return ""
+	}
+
+	a := splitLines(from)
+	b := splitLines(to)
+	var ops []op
+	if len(a) > MaxLines || len(b) > MaxLines {
+		ops = make([]op, 0, len(a)+len(b))
+		for _, line := range a {
+			ops = append(ops, op{'-', line})
+		}
+		for _, line := range b {
+			ops = append(ops, op{'+', line})
+		}
+	} else {
+		ops = lcsOps(a, b)
+	}
+
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "--- %s\n+++ %s\n", fromName, toName)
+	writeHunks(&sb, ops, context)
+	return sb.String()
+}
+
+// splitLines 按行拆分文本，忽略结尾的换行符
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// lcsOps 基于最长公共子序列计算逐行的差异操作
+func lcsOps(a, b []string) []op {
+	n, m := len(a), len(b)
+	// lcs[i][j] 为 a[i:] 与 b[j:] 的最长公共子序列长度
+	lcs := make([][]int32, n+1)
+	for i := range lcs {
+		lcs[i] = make([]int32, m+1)
+	}
+	for i := n - 1; i >= 0; i-- {
+		for j := m - 1; j >= 0; j-- {
+			if a[i] == b[j] {
+				lcs[i][j] = lcs[i+1][j+1] + 1
+			} else {
+				lcs[i][j] = max(lcs[i+1][j], lcs[i][j+1])
+			}
+		}
+	}
+
+	ops := make([]op, 0, n+m)
+	i, j := 0, 0
+	for i < n && j < m {
+		switch {
+		case a[i] == b[j]:
+			ops = append(ops, op{' ', a[i]})
+			i++
+			j++
+		case lcs[i+1][j] >= lcs[i][j+1]:
+			ops = append(ops, op{'-', a[i]})
+			i++
+		default:
+			ops = append(ops, op{'+', b[j]})
+			j++
+		}
+	}
+	for ; i < n; i++ {
+		ops = append(ops, op{'-', a[i]})
+	}
+	for ; j < m; j++ {
+		ops = append(ops, op{'+', b[j]})
+	}
+	return ops
+}
+
+// writeHunks 将差异操作按修改块输出，每块前后保留 context 行上下文
+func writeHunks(sb *strings.Builder, ops []op, context int) {
+	// 每个操作对应的 from/to 行号（从 1 开始）
+	fromLine := make([]int, len(ops)+1)
+	toLine := make([]int, len(ops)+1)
+	fromLine[0], toLine[0] = 1, 1
+	for k, o := range ops {
+		fromLine[k+1], toLine[k+1] = fromLine[k], toLine[k]
+		if o.kind != '+' {
+			fromLine[k+1]++
+		}
+		if o.kind != '-' {
+			toLine[k+1]++
+		}
+	}
+
+	for k := 0; k < len(ops); {
+		if ops[k].kind == ' ' {
+			k++
+			continue
+		}
+
+		// 向前保留上下文，向后合并间隔不超过 2*context 的修改
+		start := max(k-context, 0)
+		end := k
+		for end < len(ops) {
+			if ops[end].kind != ' ' {
+				end++
+				continue
+			}
+			next := end
+			for next < len(ops) && ops[next].kind == ' ' {
+				next++
+			}
+			if next == len(ops) || next-end > 2*context {
+				end = min(end+context, len(ops))
+				break
+			}
+			end = next
+		}
+
+		fromCount, toCount := 0, 0
+		for _, o := range ops[start:end] {
+			if o.kind != '+' {
+				fromCount++
+			}
+			if o.kind != '-' {
+				toCount++
+			}
+		}
+		fmt.Fprintf(sb, "@@ -%s +%s @@\n", hunkRange(fromLine[start], fromCount), hunkRange(toLine[start], toCount))
+		for _, o := range ops[start:end] {
+			sb.WriteByte(o.kind)
+			sb.WriteString(o.line)
+			sb.WriteByte('\n')
+		}
+		k = end
+	}
+}
+
+// hunkRange 输出修改块的起始行和行数，行数为 0 时起始行为前一行
+func hunkRange(start, count int) string {
+	if count == 0 {
+		return fmt.Sprintf("%d,0", start-1)
+	}
+	if count == 1 {
+		return fmt.Sprintf("%d", start)
+	}
+	return fmt.Sprintf("%d,%d", start, count)
+}
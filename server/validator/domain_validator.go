@@ -4,6 +4,7 @@ package validator
 import (
 	"net"
 	"regexp"
+	"strings"
 
 	"github.com/go-playground/validator/v10"
 )
@@ -11,6 +12,7 @@ import (
 // 初始化字符串相关验证器
 func init() {
 	Register("domain", DomainOrIPValidator)
+	Register("host_pattern", HostPatternValidator)
 }
 
 // DomainOrIPValidator 验证字符串是否为有效的域名或IP地址
@@ -35,3 +37,22 @@ var DomainOrIPValidator validator.Func = func(fl validator.FieldLevel) bool {
 
 	return domainRegex.MatchString(value)
 }
+
+// HostPatternValidator 验证字符串是否为有效的主机名模式：域名、IP地址或 *.example.com 形式的通配符域名
+var HostPatternValidator validator.Func = func(fl validator.FieldLevel) bool {
+	value, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	// 通配符只允许出现在最左侧标签
+	if suffix, found := strings.CutPrefix(value, "*."); found {
+		value = suffix
+		if net.ParseIP(value) != nil {
+			return false
+		}
+	}
+
+	domainRegex := regexp.MustCompile(`^([a-zA-Z0-9]([a-zA-Z0-9\-]{0,61}[a-zA-Z0-9])?\.)+[a-zA-Z]{2,}$`)
+	return net.ParseIP(value) != nil || domainRegex.MatchString(value)
+}
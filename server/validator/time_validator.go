@@ -0,0 +1,23 @@
+// validators/time_validators.go
+package validator
+
+import (
+	"github.com/HUAHUAI23/RuiQi/pkg/model"
+	"github.com/go-playground/validator/v10"
+)
+
+// 初始化时间相关验证器
+func init() {
+	Register("clock", ClockValidator)
+}
+
+// ClockValidator 验证字符串是否为 HH:MM 格式的时间，允许 24:00
+var ClockValidator validator.Func = func(fl validator.FieldLevel) bool {
+	value, ok := fl.Field().Interface().(string)
+	if !ok {
+		return false
+	}
+
+	_, err := model.ParseClock(value)
+	return err == nil
+}